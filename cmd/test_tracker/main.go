@@ -14,10 +14,12 @@ import (
 )
 
 func main() {
-	// Load API key from environment or .env file
+	// Live smoke test against mainnet; the automated equivalent runs
+	// against internal/agents/agenttest.
 	apiKey := os.Getenv("HELIUS_API_KEY")
 	if apiKey == "" {
-		apiKey = "f38d1544-b8e7-494e-a946-cb7255143abf" // Fallback for testing
+		fmt.Println("HELIUS_API_KEY is not set")
+		os.Exit(1)
 	}
 
 	config := &common.Config{
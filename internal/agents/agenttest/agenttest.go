@@ -0,0 +1,790 @@
+// Package agenttest provides local stand-ins for the Solana JSON-RPC and
+// websocket endpoints and the Jupiter and Helius APIs, so trading agents
+// can be tested end-to-end without network access or real funds.
+//
+// An Env starts one httptest server per service. Env.Config returns a
+// common.Config pointing every agent endpoint at them. WhaleBuy simulates
+// a tracked wallet buying a token: the transaction becomes visible to
+// getTransaction and the Helius history, and subscribers to the wallet
+// receive a logsNotification.
+//
+// Transactions sent by an executor settle the swap quoted by the last
+// Jupiter /swap request for the signer, so the signer's on-chain history
+// (getSignaturesForAddress and getTransaction) reflects its trades.
+// WalletSell adds a sell made outside the agents.
+package agenttest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gorilla/websocket"
+	"github.com/speaker20/whaletown/internal/agents/common"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+// DefaultQuoteOutAmount is the token amount every Jupiter quote returns
+// unless Env.QuoteOutAmount is changed.
+const DefaultQuoteOutAmount = 1_000_000
+
+// DefaultSOLPrice is the SOL/USD price served unless Env.SetSOLPrice is
+// called.
+const DefaultSOLPrice = 150.0
+
+// baseFee is the per-signature fee charged to every transaction.
+const baseFee = 5000
+
+// jupiterLog is the log line that marks a transaction as a Jupiter swap.
+const jupiterLog = "Program " + copytrade.JupiterProgramID + " invoke [1]"
+
+// Env is a fake Solana/Jupiter/Helius environment.
+type Env struct {
+	RPC     *httptest.Server // Solana JSON-RPC
+	WS      *httptest.Server // Solana websocket (logsSubscribe)
+	Jupiter *httptest.Server // Jupiter /quote, /swap and /price
+	Helius  *httptest.Server // Helius /v0/addresses/{address}/transactions
+
+	mu             sync.Mutex
+	slot           uint64
+	blockTime      int64
+	quoteOutAmount uint64
+	solPrice       float64
+	priorityFee    uint64
+	failSends      bool
+	txs            map[string]*chainTx                      // signature -> transaction
+	signatures     map[string][]string                      // wallet -> signatures, newest first
+	pendingSwaps   map[string]pendingSwap                   // user -> last /swap built
+	history        map[string][]copytrade.HeliusTransaction // wallet -> newest first
+	sent           []SentTransaction
+	quotes         []Quote
+	subs           map[int]*subscription
+	nextSub        int
+}
+
+// chainTx is a transaction known to the fake chain: a swap between SOL
+// and a token by wallet.
+type chainTx struct {
+	slot      uint64
+	blockTime int64
+	wallet    string
+	sell      bool   // Token -> SOL instead of SOL -> token
+	mint      string // Empty if the transaction swaps nothing
+	amount    uint64 // Token base units
+	lamports  uint64 // SOL side of the swap
+	fee       uint64
+	failed    bool
+}
+
+// pendingSwap is a swap built by /swap and settled by sendTransaction.
+type pendingSwap struct {
+	inputMint  string
+	outputMint string
+	inAmount   uint64
+	outAmount  uint64
+}
+
+// SentTransaction is a transaction received via sendTransaction.
+type SentTransaction struct {
+	Signature string
+	FeePayer  string
+	Raw       []byte
+}
+
+// Quote is a quote request received by the Jupiter stand-in.
+type Quote struct {
+	InputMint   string
+	OutputMint  string
+	Amount      uint64
+	SlippageBps int
+}
+
+// subscription is a logsSubscribe registration on a websocket connection.
+type subscription struct {
+	id     int
+	wallet string
+	conn   *wsConn
+}
+
+// wsConn serializes writes to a websocket connection.
+type wsConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (c *wsConn) writeJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+// NewEnv starts the fake services. They are shut down when the test ends.
+func NewEnv(t testing.TB) *Env {
+	t.Helper()
+
+	e := &Env{
+		slot:           1000,
+		blockTime:      time.Now().Unix(),
+		quoteOutAmount: DefaultQuoteOutAmount,
+		solPrice:       DefaultSOLPrice,
+		txs:            make(map[string]*chainTx),
+		signatures:     make(map[string][]string),
+		pendingSwaps:   make(map[string]pendingSwap),
+		history:        make(map[string][]copytrade.HeliusTransaction),
+		subs:           make(map[int]*subscription),
+	}
+	e.RPC = httptest.NewServer(http.HandlerFunc(e.serveRPC))
+	e.WS = httptest.NewServer(http.HandlerFunc(e.serveWS))
+	e.Jupiter = httptest.NewServer(http.HandlerFunc(e.serveJupiter))
+	e.Helius = httptest.NewServer(http.HandlerFunc(e.serveHelius))
+
+	t.Cleanup(e.Close)
+	return e
+}
+
+// Close shuts down all fake services.
+func (e *Env) Close() {
+	e.WS.CloseClientConnections()
+	for _, s := range []*httptest.Server{e.RPC, e.WS, e.Jupiter, e.Helius} {
+		s.Close()
+	}
+}
+
+// Config returns an agent config pointing every endpoint at the fakes.
+func (e *Env) Config() *common.Config {
+	return &common.Config{
+		HeliusAPIKey:  "test-key",
+		HeliusAPIURL:  e.Helius.URL,
+		JupiterAPIURL: e.Jupiter.URL,
+		PriceAPIURL:   e.Jupiter.URL,
+		SolanaRPCURL:  e.RPC.URL,
+		SolanaWSURL:   "ws" + strings.TrimPrefix(e.WS.URL, "http"),
+	}
+}
+
+// NewWallet returns a fresh wallet address and its base58 private key.
+func NewWallet() (address, privateKey string) {
+	w := solana.NewWallet()
+	return w.PublicKey().String(), w.PrivateKey.String()
+}
+
+// NewMint returns a fresh token mint address.
+func NewMint() string {
+	return solana.NewWallet().PublicKey().String()
+}
+
+// SetQuoteOutAmount sets the token amount returned by Jupiter quotes.
+func (e *Env) SetQuoteOutAmount(amount uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.quoteOutAmount = amount
+}
+
+// SetSOLPrice sets the SOL/USD price served by /price.
+func (e *Env) SetSOLPrice(price float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.solPrice = price
+}
+
+// SetPriorityFee sets the priority fee charged to sent transactions on
+// top of the base fee.
+func (e *Env) SetPriorityFee(lamports uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.priorityFee = lamports
+}
+
+// SetFailSends makes sent transactions fail on-chain, so their signature
+// status reports an error.
+func (e *Env) SetFailSends(fail bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failSends = fail
+}
+
+// WhaleBuy simulates wallet buying amount of mint with SOL. It returns the
+// transaction signature and notifies subscribers of the wallet.
+func (e *Env) WhaleBuy(wallet, mint string, amount uint64) string {
+	sig := randomSignature()
+
+	e.mu.Lock()
+	tx := &chainTx{
+		wallet:   wallet,
+		mint:     mint,
+		amount:   amount,
+		lamports: 1_000_000_000,
+		fee:      baseFee,
+	}
+	e.recordLocked(sig, tx)
+	e.history[wallet] = append([]copytrade.HeliusTransaction{{
+		Signature: sig,
+		Timestamp: tx.blockTime,
+		Type:      "SWAP",
+		Source:    "JUPITER",
+		TokenTransfers: []copytrade.HeliusTokenTransfer{
+			{FromUserAccount: wallet, Mint: copytrade.WSOLMint, TokenSymbol: "SOL", TokenAmount: 1},
+			{ToUserAccount: wallet, Mint: mint, TokenAmount: float64(amount)},
+		},
+	}}, e.history[wallet]...)
+
+	var targets []*subscription
+	for _, s := range e.subs {
+		if s.wallet == wallet {
+			targets = append(targets, s)
+		}
+	}
+	slot := e.slot
+	e.mu.Unlock()
+
+	for _, s := range targets {
+		_ = s.conn.writeJSON(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "logsNotification",
+			"params": map[string]interface{}{
+				"result": map[string]interface{}{
+					"context": map[string]interface{}{"slot": slot},
+					"value": map[string]interface{}{
+						"signature": sig,
+						"err":       nil,
+						"logs":      []string{jupiterLog},
+					},
+				},
+				"subscription": s.id,
+			},
+		})
+	}
+	return sig
+}
+
+// WalletSell simulates wallet selling amount of mint for lamports of SOL
+// outside the agents, e.g. by hand. It returns the transaction signature.
+func (e *Env) WalletSell(wallet, mint string, amount, lamports uint64) string {
+	sig := randomSignature()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.recordLocked(sig, &chainTx{
+		wallet:   wallet,
+		sell:     true,
+		mint:     mint,
+		amount:   amount,
+		lamports: lamports,
+		fee:      baseFee,
+	})
+	return sig
+}
+
+// recordLocked adds tx to the chain in a new slot, with a block time
+// strictly after every earlier transaction. Callers hold e.mu.
+func (e *Env) recordLocked(sig string, tx *chainTx) {
+	e.slot++
+	if now := time.Now().Unix(); now > e.blockTime {
+		e.blockTime = now
+	} else {
+		e.blockTime++
+	}
+	tx.slot = e.slot
+	tx.blockTime = e.blockTime
+	e.txs[sig] = tx
+	e.signatures[tx.wallet] = append([]string{sig}, e.signatures[tx.wallet]...)
+}
+
+// Subscribers returns how many logsSubscribe registrations mention wallet.
+func (e *Env) Subscribers(wallet string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := 0
+	for _, s := range e.subs {
+		if s.wallet == wallet {
+			n++
+		}
+	}
+	return n
+}
+
+// WaitForSubscribers waits until at least n subscriptions mention wallet.
+func (e *Env) WaitForSubscribers(wallet string, n int, timeout time.Duration) bool {
+	return Eventually(timeout, func() bool { return e.Subscribers(wallet) >= n })
+}
+
+// Sent returns the transactions received via sendTransaction.
+func (e *Env) Sent() []SentTransaction {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]SentTransaction, len(e.sent))
+	copy(out, e.sent)
+	return out
+}
+
+// Quotes returns the Jupiter quote requests received.
+func (e *Env) Quotes() []Quote {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]Quote, len(e.quotes))
+	copy(out, e.quotes)
+	return out
+}
+
+// Eventually polls cond until it returns true or timeout elapses.
+func Eventually(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if cond() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// rpcRequest is a JSON-RPC 2.0 request.
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// serveRPC implements the subset of the Solana JSON-RPC API agents use.
+func (e *Env) serveRPC(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result interface{}
+	var rpcErr error
+	switch req.Method {
+	case "getTransaction":
+		result, rpcErr = e.rpcGetTransaction(req.Params)
+	case "sendTransaction":
+		result, rpcErr = e.rpcSendTransaction(req.Params)
+	case "getSignatureStatuses":
+		result, rpcErr = e.rpcGetSignatureStatuses(req.Params)
+	case "getSignaturesForAddress":
+		result, rpcErr = e.rpcGetSignaturesForAddress(req.Params)
+	case "getLatestBlockhash":
+		result = map[string]interface{}{
+			"context": map[string]interface{}{"slot": e.currentSlot()},
+			"value": map[string]interface{}{
+				"blockhash":            solana.Hash{1}.String(),
+				"lastValidBlockHeight": e.currentSlot() + 150,
+			},
+		}
+	default:
+		rpcErr = fmt.Errorf("method not found: %s", req.Method)
+	}
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if rpcErr != nil {
+		resp["error"] = map[string]interface{}{"code": -32601, "message": rpcErr.Error()}
+	} else {
+		resp["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (e *Env) currentSlot() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.slot
+}
+
+func (e *Env) rpcGetTransaction(params []json.RawMessage) (interface{}, error) {
+	var sig string
+	if len(params) == 0 || json.Unmarshal(params[0], &sig) != nil {
+		return nil, fmt.Errorf("invalid params")
+	}
+
+	e.mu.Lock()
+	tx, ok := e.txs[sig]
+	e.mu.Unlock()
+	if !ok || tx.mint == "" {
+		return nil, nil // Not found
+	}
+
+	// The wallet pays the fee from its native balance and swaps through
+	// wrapped SOL, so SOL moves show up as WSOL token balance changes.
+	const native = 10_000_000_000
+	amount := strconv.FormatUint(tx.amount, 10)
+	lamports := strconv.FormatUint(tx.lamports, 10)
+	pre := []interface{}{tokenBalance(1, tx.wallet, copytrade.WSOLMint, lamports)}
+	post := []interface{}{
+		tokenBalance(1, tx.wallet, copytrade.WSOLMint, "0"),
+		tokenBalance(2, tx.wallet, tx.mint, amount),
+	}
+	if tx.sell {
+		pre = []interface{}{tokenBalance(2, tx.wallet, tx.mint, amount)}
+		post = []interface{}{
+			tokenBalance(1, tx.wallet, copytrade.WSOLMint, lamports),
+			tokenBalance(2, tx.wallet, tx.mint, "0"),
+		}
+	}
+
+	var txErr interface{}
+	if tx.failed {
+		txErr = map[string]interface{}{"InstructionError": []interface{}{0, "Custom"}}
+	}
+	return map[string]interface{}{
+		"slot":        tx.slot,
+		"blockTime":   tx.blockTime,
+		"transaction": nil,
+		"version":     0,
+		"meta": map[string]interface{}{
+			"err":               txErr,
+			"fee":               tx.fee,
+			"preBalances":       []uint64{native},
+			"postBalances":      []uint64{native - tx.fee},
+			"preTokenBalances":  pre,
+			"postTokenBalances": post,
+			"logMessages":       []string{jupiterLog},
+		},
+	}, nil
+}
+
+func tokenBalance(index int, owner, mint, amount string) map[string]interface{} {
+	return map[string]interface{}{
+		"accountIndex": index,
+		"owner":        owner,
+		"mint":         mint,
+		"uiTokenAmount": map[string]interface{}{
+			"amount":         amount,
+			"decimals":       6,
+			"uiAmountString": amount,
+		},
+	}
+}
+
+func (e *Env) rpcSendTransaction(params []json.RawMessage) (interface{}, error) {
+	var encoded string
+	if len(params) == 0 || json.Unmarshal(params[0], &encoded) != nil {
+		return nil, fmt.Errorf("invalid params")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	tx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	if len(tx.Signatures) == 0 {
+		return nil, fmt.Errorf("transaction is not signed")
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	sig := tx.Signatures[0].String()
+	payer := tx.Message.AccountKeys[0].String()
+
+	e.mu.Lock()
+	ctx := &chainTx{
+		wallet: payer,
+		fee:    baseFee*uint64(len(tx.Signatures)) + e.priorityFee,
+		failed: e.failSends,
+	}
+	if swap, ok := e.pendingSwaps[payer]; ok {
+		delete(e.pendingSwaps, payer)
+		if swap.inputMint == copytrade.WSOLMint {
+			ctx.mint, ctx.amount, ctx.lamports = swap.outputMint, swap.outAmount, swap.inAmount
+		} else {
+			ctx.sell = true
+			ctx.mint, ctx.amount, ctx.lamports = swap.inputMint, swap.inAmount, swap.outAmount
+		}
+	}
+	e.recordLocked(sig, ctx)
+	e.sent = append(e.sent, SentTransaction{
+		Signature: sig,
+		FeePayer:  payer,
+		Raw:       raw,
+	})
+	e.mu.Unlock()
+	return sig, nil
+}
+
+func (e *Env) rpcGetSignatureStatuses(params []json.RawMessage) (interface{}, error) {
+	var sigs []string
+	if len(params) == 0 || json.Unmarshal(params[0], &sigs) != nil {
+		return nil, fmt.Errorf("invalid params")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	values := make([]interface{}, len(sigs))
+	for i, sig := range sigs {
+		tx, ok := e.txs[sig]
+		if !ok {
+			continue // null: unknown signature
+		}
+		var txErr interface{}
+		if tx.failed {
+			txErr = map[string]interface{}{"InstructionError": []interface{}{0, "Custom"}}
+		}
+		values[i] = map[string]interface{}{
+			"slot":               tx.slot,
+			"confirmations":      nil,
+			"err":                txErr,
+			"confirmationStatus": "confirmed",
+		}
+	}
+	return map[string]interface{}{
+		"context": map[string]interface{}{"slot": e.slot},
+		"value":   values,
+	}, nil
+}
+
+func (e *Env) rpcGetSignaturesForAddress(params []json.RawMessage) (interface{}, error) {
+	var address string
+	if len(params) == 0 || json.Unmarshal(params[0], &address) != nil {
+		return nil, fmt.Errorf("invalid params")
+	}
+	var opts struct {
+		Limit  int    `json:"limit"`
+		Before string `json:"before"`
+	}
+	if len(params) > 1 {
+		_ = json.Unmarshal(params[1], &opts)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 1000
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	sigs := e.signatures[address]
+	start := 0
+	if opts.Before != "" {
+		for i, sig := range sigs {
+			if sig == opts.Before {
+				start = i + 1
+				break
+			}
+		}
+	}
+	page := []interface{}{}
+	for _, sig := range sigs[start:] {
+		if len(page) == opts.Limit {
+			break
+		}
+		tx := e.txs[sig]
+		var txErr interface{}
+		if tx.failed {
+			txErr = map[string]interface{}{"InstructionError": []interface{}{0, "Custom"}}
+		}
+		page = append(page, map[string]interface{}{
+			"signature":          sig,
+			"slot":               tx.slot,
+			"blockTime":          tx.blockTime,
+			"err":                txErr,
+			"memo":               nil,
+			"confirmationStatus": "confirmed",
+		})
+	}
+	return page, nil
+}
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// serveWS accepts logsSubscribe requests and keeps the connection open for
+// notifications.
+func (e *Env) serveWS(w http.ResponseWriter, r *http.Request) {
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn := &wsConn{conn: c}
+	defer func() {
+		e.mu.Lock()
+		for id, s := range e.subs {
+			if s.conn == conn {
+				delete(e.subs, id)
+			}
+		}
+		e.mu.Unlock()
+		c.Close()
+	}()
+
+	for {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []struct {
+				Mentions []string `json:"mentions"`
+			} `json:"params"`
+		}
+		if err := c.ReadJSON(&req); err != nil {
+			return
+		}
+		if req.Method != "logsSubscribe" || len(req.Params) == 0 || len(req.Params[0].Mentions) == 0 {
+			_ = conn.writeJSON(map[string]interface{}{
+				"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]interface{}{"code": -32601, "message": "unsupported"},
+			})
+			continue
+		}
+
+		e.mu.Lock()
+		e.nextSub++
+		sub := &subscription{id: e.nextSub, wallet: req.Params[0].Mentions[0], conn: conn}
+		e.subs[sub.id] = sub
+		e.mu.Unlock()
+
+		_ = conn.writeJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": sub.id})
+	}
+}
+
+// serveJupiter implements /quote, /swap and /price.
+func (e *Env) serveJupiter(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/quote"):
+		q := r.URL.Query()
+		amount, _ := strconv.ParseUint(q.Get("amount"), 10, 64)
+		slippage, _ := strconv.Atoi(q.Get("slippageBps"))
+
+		e.mu.Lock()
+		e.quotes = append(e.quotes, Quote{
+			InputMint:   q.Get("inputMint"),
+			OutputMint:  q.Get("outputMint"),
+			Amount:      amount,
+			SlippageBps: slippage,
+		})
+		out := e.quoteOutAmount
+		e.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"inputMint":   q.Get("inputMint"),
+			"outputMint":  q.Get("outputMint"),
+			"inAmount":    q.Get("amount"),
+			"outAmount":   strconv.FormatUint(out, 10),
+			"slippageBps": slippage,
+		})
+
+	case strings.HasSuffix(r.URL.Path, "/swap"):
+		var req struct {
+			QuoteResponse json.RawMessage `json:"quoteResponse"`
+			UserPublicKey string          `json:"userPublicKey"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user, err := solana.PublicKeyFromBase58(req.UserPublicKey)
+		if err != nil {
+			http.Error(w, "invalid userPublicKey", http.StatusBadRequest)
+			return
+		}
+		var quote struct {
+			InputMint  string `json:"inputMint"`
+			OutputMint string `json:"outputMint"`
+			InAmount   string `json:"inAmount"`
+			OutAmount  string `json:"outAmount"`
+		}
+		_ = json.Unmarshal(req.QuoteResponse, &quote)
+		lamports, _ := strconv.ParseUint(quote.InAmount, 10, 64)
+		out, _ := strconv.ParseUint(quote.OutAmount, 10, 64)
+
+		e.mu.Lock()
+		e.pendingSwaps[user.String()] = pendingSwap{
+			inputMint:  quote.InputMint,
+			outputMint: quote.OutputMint,
+			inAmount:   lamports,
+			outAmount:  out,
+		}
+		e.mu.Unlock()
+
+		// An unsigned transaction paid for by the user, like Jupiter returns
+		ix := system.NewTransferInstruction(lamports, user, solana.NewWallet().PublicKey()).Build()
+		tx, err := solana.NewTransaction([]solana.Instruction{ix}, solana.Hash{1}, solana.TransactionPayer(user))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"swapTransaction":      base64.StdEncoding.EncodeToString(raw),
+			"lastValidBlockHeight": e.currentSlot() + 150,
+		})
+
+	case strings.HasSuffix(r.URL.Path, "/price"):
+		e.mu.Lock()
+		price := e.solPrice
+		e.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"SOL": map[string]interface{}{"id": copytrade.WSOLMint, "price": price},
+			},
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveHelius implements the parsed transaction history endpoint with
+// before/until/limit pagination.
+func (e *Env) serveHelius(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "v0" || parts[1] != "addresses" || parts[3] != "transactions" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.URL.Query().Get("api-key") == "" {
+		http.Error(w, "missing api-key", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	before, until := q.Get("before"), q.Get("until")
+
+	e.mu.Lock()
+	history := e.history[parts[2]]
+	start := 0
+	if before != "" {
+		for i, tx := range history {
+			if tx.Signature == before {
+				start = i + 1
+				break
+			}
+		}
+	}
+	page := []copytrade.HeliusTransaction{}
+	for _, tx := range history[start:] {
+		if tx.Signature == until || len(page) == limit {
+			break
+		}
+		page = append(page, tx)
+	}
+	e.mu.Unlock()
+
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+// randomSignature returns a random, well-formed transaction signature.
+func randomSignature() string {
+	var sig solana.Signature
+	_, _ = rand.Read(sig[:])
+	return sig.String()
+}
@@ -3,6 +3,15 @@ package common
 
 import (
 	"os"
+	"strings"
+)
+
+// Default API base URLs. Config fields override them so tests can point
+// agents at local stand-ins.
+const (
+	DefaultHeliusAPIURL  = "https://api.helius.xyz"
+	DefaultJupiterAPIURL = "https://quote-api.jup.ag/v6"
+	DefaultPriceAPIURL   = "https://price.jup.ag/v6"
 )
 
 // Config holds API keys and configuration for agents.
@@ -10,6 +19,15 @@ type Config struct {
 	// Helius API for parsed transactions (fallback)
 	HeliusAPIKey string
 
+	// Helius REST API base URL (default DefaultHeliusAPIURL)
+	HeliusAPIURL string
+
+	// Jupiter swap API base URL (default DefaultJupiterAPIURL)
+	JupiterAPIURL string
+
+	// Jupiter price API base URL (default DefaultPriceAPIURL)
+	PriceAPIURL string
+
 	// Custom Solana RPC (for higher rate limits)
 	// If set, uses this for RPC calls instead of public endpoints
 	SolanaRPCURL string
@@ -31,6 +49,9 @@ type Config struct {
 func DefaultConfig() *Config {
 	return &Config{
 		HeliusAPIKey:      os.Getenv("HELIUS_API_KEY"),
+		HeliusAPIURL:      os.Getenv("HELIUS_API_URL"),
+		JupiterAPIURL:     os.Getenv("JUPITER_API_URL"),
+		PriceAPIURL:       os.Getenv("JUPITER_PRICE_API_URL"),
 		SolanaRPCURL:      os.Getenv("SOLANA_RPC_URL"),
 		SolanaWSURL:       os.Getenv("SOLANA_WS_URL"),
 		SolanaPrivateKey:  os.Getenv("SOLANA_PRIVATE_KEY"),
@@ -45,6 +66,30 @@ func (c *Config) HasCustomRPC() bool {
 	return c.SolanaRPCURL != ""
 }
 
+// HeliusBaseURL returns the Helius REST API base URL.
+func (c *Config) HeliusBaseURL() string {
+	if c.HeliusAPIURL != "" {
+		return strings.TrimSuffix(c.HeliusAPIURL, "/")
+	}
+	return DefaultHeliusAPIURL
+}
+
+// JupiterBaseURL returns the Jupiter swap API base URL.
+func (c *Config) JupiterBaseURL() string {
+	if c.JupiterAPIURL != "" {
+		return strings.TrimSuffix(c.JupiterAPIURL, "/")
+	}
+	return DefaultJupiterAPIURL
+}
+
+// PriceBaseURL returns the Jupiter price API base URL.
+func (c *Config) PriceBaseURL() string {
+	if c.PriceAPIURL != "" {
+		return strings.TrimSuffix(c.PriceAPIURL, "/")
+	}
+	return DefaultPriceAPIURL
+}
+
 // HasWebSocket returns true if WebSocket URL is configured.
 func (c *Config) HasWebSocket() bool {
 	return c.SolanaWSURL != ""
@@ -0,0 +1,103 @@
+package copytrade
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/util"
+)
+
+// WalletCursor records the newest transaction already ingested for a wallet.
+// Polls fetch only transactions newer than Signature.
+type WalletCursor struct {
+	Signature string    `json:"signature"`
+	Timestamp time.Time `json:"timestamp"`  // Block time of Signature
+	UpdatedAt time.Time `json:"updated_at"` // When the cursor last advanced
+}
+
+// CursorStore persists per-wallet cursors so polling resumes where it
+// left off across restarts.
+type CursorStore struct {
+	path string
+
+	mu      sync.Mutex
+	cursors map[string]WalletCursor
+}
+
+// cursorFile is the on-disk format of the cursor store.
+type cursorFile struct {
+	Cursors map[string]WalletCursor `json:"cursors"`
+}
+
+// CursorsPath returns the path to the wallet cursor file.
+func CursorsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".whaletown/trade_cursors.json"
+	}
+	return filepath.Join(home, ".whaletown", "trade_cursors.json")
+}
+
+// LoadCursorStore loads cursors from path.
+// A missing file yields an empty store.
+func LoadCursorStore(path string) (*CursorStore, error) {
+	s := &CursorStore{
+		path:    path,
+		cursors: make(map[string]WalletCursor),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+
+	var f cursorFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return s, err
+	}
+	for addr, c := range f.Cursors {
+		s.cursors[addr] = c
+	}
+	return s, nil
+}
+
+// Get returns the cursor for a wallet, if one has been recorded.
+func (s *CursorStore) Get(address string) (WalletCursor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.cursors[address]
+	return c, ok
+}
+
+// Set records the cursor for a wallet. Call Save to persist it.
+func (s *CursorStore) Set(address string, c WalletCursor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[address] = c
+}
+
+// Save writes the cursors to disk atomically.
+// A store without a path (in-memory only) is a no-op.
+func (s *CursorStore) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	f := cursorFile{Cursors: make(map[string]WalletCursor, len(s.cursors))}
+	for addr, c := range s.cursors {
+		f.Cursors[addr] = c
+	}
+	s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(s.path, f)
+}
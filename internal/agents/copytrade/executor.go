@@ -17,6 +17,22 @@ import (
 	"github.com/speaker20/whaletown/internal/agents/common"
 )
 
+// WSOLMint is the wrapped SOL mint, the input side of every copy buy.
+const WSOLMint = "So11111111111111111111111111111111111111112"
+
+// DefaultBuyLamports is the size of a fast-lane copy buy
+// (0.005 SOL, ~$1 @ $200/SOL).
+const DefaultBuyLamports = 5000000
+
+// DefaultSlippageBps is the default maximum swap slippage.
+const DefaultSlippageBps = 50
+
+// ConfirmTimeout bounds how long Confirm waits for a transaction.
+var ConfirmTimeout = 60 * time.Second
+
+// confirmPollInterval is how often Confirm checks signature status.
+const confirmPollInterval = 500 * time.Millisecond
+
 // Executor handles trade execution via Jupiter.
 type Executor struct {
 	config     *common.Config
@@ -25,14 +41,19 @@ type Executor struct {
 	httpClient *http.Client
 }
 
-// NewExecutor creates a new trade executor.
+// NewExecutor creates a new trade executor signing with SOLANA_PRIVATE_KEY.
 func NewExecutor(config *common.Config) (*Executor, error) {
 	if config.SolanaPrivateKey == "" {
 		return nil, fmt.Errorf("SOLANA_PRIVATE_KEY is not set")
 	}
+	return NewExecutorWithKey(config, config.SolanaPrivateKey)
+}
 
+// NewExecutorWithKey creates a trade executor signing with the given
+// base58 private key, so each strategy instance can use its own wallet.
+func NewExecutorWithKey(config *common.Config, privateKey string) (*Executor, error) {
 	// Parse private key (Base58)
-	privKey, err := solana.PrivateKeyFromBase58(config.SolanaPrivateKey)
+	privKey, err := solana.PrivateKeyFromBase58(privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("invalid private key: %w", err)
 	}
@@ -50,31 +71,128 @@ func NewExecutor(config *common.Config) (*Executor, error) {
 	}, nil
 }
 
+// PublicKey returns the executor wallet's address.
+func (e *Executor) PublicKey() string {
+	return e.privateKey.PublicKey().String()
+}
+
 // ExecuteCopyBuy executes a buy order for the specified token.
-// It uses a fixed amount of SOL (~$1 @ $200/SOL = 0.005 SOL)
+// It uses a fixed amount of SOL (DefaultBuyLamports).
 func (e *Executor) ExecuteCopyBuy(tokenMint string) (string, error) {
+	result, err := e.ExecuteBuy(tokenMint, DefaultBuyLamports, DefaultSlippageBps)
+	if err != nil {
+		return "", err
+	}
+	return result.Signature, nil
+}
+
+// BuyResult describes a submitted buy.
+type BuyResult struct {
+	Signature  string
+	TokenMint  string
+	InLamports uint64 // SOL spent
+	OutAmount  uint64 // Token amount quoted, in base units
+}
+
+// ExecuteBuy swaps lamports of SOL into tokenMint via Jupiter.
+func (e *Executor) ExecuteBuy(tokenMint string, lamports uint64, slippageBps int) (*BuyResult, error) {
 	fmt.Printf("🚀 FAST LANE: Executing buy for %s\n", tokenMint)
 
-	// 0.005 SOL = 5000000 lamports
-	amount := 5000000
-	quote, err := e.getJupiterQuote("So11111111111111111111111111111111111111112", tokenMint, amount)
+	// 1. Get Quote
+	quote, err := e.getJupiterQuote(WSOLMint, tokenMint, lamports, slippageBps)
 	if err != nil {
-		return "", fmt.Errorf("jupiter quote failed: %w", err)
+		return nil, fmt.Errorf("jupiter quote failed: %w", err)
 	}
 
 	// 2. Get Swap Transaction
 	swapTx, err := e.getJupiterSwapTx(quote)
 	if err != nil {
-		return "", fmt.Errorf("jupiter swap build failed: %w", err)
+		return nil, fmt.Errorf("jupiter swap build failed: %w", err)
 	}
 
 	// 3. Sign and Send
 	sig, err := e.signAndSend(swapTx)
 	if err != nil {
-		return "", fmt.Errorf("sign/send failed: %w", err)
+		return nil, fmt.Errorf("sign/send failed: %w", err)
+	}
+
+	return &BuyResult{
+		Signature:  sig.String(),
+		TokenMint:  tokenMint,
+		InLamports: lamports,
+		OutAmount:  quoteOutAmount(quote),
+	}, nil
+}
+
+// QuoteValue returns what amount of tokenMint (in base units) would sell
+// for, in lamports. Used to mark open positions to market.
+func (e *Executor) QuoteValue(tokenMint string, amount uint64) (uint64, error) {
+	if amount == 0 {
+		return 0, nil
+	}
+	quote, err := e.getJupiterQuote(tokenMint, WSOLMint, amount, DefaultSlippageBps)
+	if err != nil {
+		return 0, err
+	}
+	return quoteOutAmount(quote), nil
+}
+
+// Confirm waits until a submitted transaction reaches confirmed
+// commitment, returning an error if it failed on-chain or was not
+// confirmed within ConfirmTimeout.
+func (e *Executor) Confirm(signature string) error {
+	sig, err := solana.SignatureFromBase58(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	deadline := time.Now().Add(ConfirmTimeout)
+	for {
+		out, err := e.rpcClient.GetSignatureStatuses(context.Background(), true, sig)
+		if err == nil && out != nil && len(out.Value) > 0 && out.Value[0] != nil {
+			status := out.Value[0]
+			if status.Err != nil {
+				return fmt.Errorf("transaction failed: %v", status.Err)
+			}
+			if status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed ||
+				status.ConfirmationStatus == rpc.ConfirmationStatusFinalized {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("transaction %s not confirmed after %s", signature, ConfirmTimeout)
+		}
+		time.Sleep(confirmPollInterval)
+	}
+}
+
+// SOLPriceUSD returns the current SOL/USD price from the Jupiter price
+// API. Fills record it so journals can report USD cost basis.
+func (e *Executor) SOLPriceUSD() (float64, error) {
+	resp, err := e.httpClient.Get(e.config.PriceBaseURL() + "/price?ids=SOL")
+	if err != nil {
+		return 0, err
 	}
+	defer resp.Body.Close()
 
-	return sig.String(), nil
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data map[string]struct {
+			Price float64 `json:"price"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	price, ok := result.Data["SOL"]
+	if !ok || price.Price <= 0 {
+		return 0, fmt.Errorf("no SOL price in response")
+	}
+	return price.Price, nil
 }
 
 // ExecutionResult holds the result of a copy buy execution.
@@ -86,6 +204,22 @@ type ExecutionResult struct {
 // ProcessSignal analyzes a transaction signature and executes a copy trade if applicable.
 // Returns the execution result if successful.
 func (e *Executor) ProcessSignal(signature solana.Signature) (*ExecutionResult, error) {
+	mint, err := e.DetectBuy(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	txSig, err := e.ExecuteCopyBuy(mint)
+	if err != nil {
+		return nil, fmt.Errorf("copy buy execution failed: %w", err)
+	}
+	fmt.Printf("✅ Copy Trade Executed! Sig: %s\n", txSig)
+	return &ExecutionResult{TokenMint: mint, TxHash: txSig}, nil
+}
+
+// DetectBuy fetches a transaction and returns the mint of the token whose
+// balance increased, i.e. what the whale bought.
+func (e *Executor) DetectBuy(signature solana.Signature) (string, error) {
 	ctx := context.Background()
 
 	// Fetch transaction
@@ -94,11 +228,11 @@ func (e *Executor) ProcessSignal(signature solana.Signature) (*ExecutionResult,
 		MaxSupportedTransactionVersion: func(v uint64) *uint64 { return &v }(0),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("fetch tx failed: %w", err)
+		return "", fmt.Errorf("fetch tx failed: %w", err)
 	}
 
 	if tx == nil || tx.Meta == nil {
-		return nil, fmt.Errorf("tx meta missing")
+		return "", fmt.Errorf("tx meta missing")
 	}
 
 	// Analyze balance changes to find what was bought
@@ -118,23 +252,29 @@ func (e *Executor) ProcessSignal(signature solana.Signature) (*ExecutionResult,
 
 		if postAmount > preAmount {
 			// Ignore WSOL
-			if balance.Mint.String() == "So11111111111111111111111111111111111111112" {
+			if balance.Mint.String() == WSOLMint {
 				continue
 			}
 
 			mint := balance.Mint.String()
 			fmt.Printf("🎯 Signal Identified: Whale bought %s\n", mint)
-
-			txSig, err := e.ExecuteCopyBuy(mint)
-			if err != nil {
-				return nil, fmt.Errorf("copy buy execution failed: %w", err)
-			}
-			fmt.Printf("✅ Copy Trade Executed! Sig: %s\n", txSig)
-			return &ExecutionResult{TokenMint: mint, TxHash: txSig}, nil
+			return mint, nil
 		}
 	}
 
-	return nil, fmt.Errorf("no buy signal detected in tx")
+	return "", fmt.Errorf("no buy signal detected in tx")
+}
+
+// quoteOutAmount extracts outAmount from a Jupiter quote response.
+func quoteOutAmount(quote json.RawMessage) uint64 {
+	var q struct {
+		OutAmount string `json:"outAmount"`
+	}
+	if err := json.Unmarshal(quote, &q); err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(q.OutAmount, 10, 64)
+	return n
 }
 
 // Internal Jupiter helpers
@@ -145,9 +285,9 @@ type jupiterQuoteResponse struct {
 	Raw json.RawMessage
 }
 
-func (e *Executor) getJupiterQuote(inputMint, outputMint string, amount int) (json.RawMessage, error) {
-	url := fmt.Sprintf("https://quote-api.jup.ag/v6/quote?inputMint=%s&outputMint=%s&amount=%d&slippageBps=50",
-		inputMint, outputMint, amount)
+func (e *Executor) getJupiterQuote(inputMint, outputMint string, amount uint64, slippageBps int) (json.RawMessage, error) {
+	url := fmt.Sprintf("%s/quote?inputMint=%s&outputMint=%s&amount=%d&slippageBps=%d",
+		e.config.JupiterBaseURL(), inputMint, outputMint, amount, slippageBps)
 
 	resp, err := e.httpClient.Get(url)
 	if err != nil {
@@ -176,7 +316,7 @@ func (e *Executor) getJupiterSwapTx(quoteResponse json.RawMessage) (string, erro
 	}
 
 	jsonBody, _ := json.Marshal(reqBody)
-	resp, err := e.httpClient.Post("https://quote-api.jup.ag/v6/swap", "application/json", bytes.NewBuffer(jsonBody))
+	resp, err := e.httpClient.Post(e.config.JupiterBaseURL()+"/swap", "application/json", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", err
 	}
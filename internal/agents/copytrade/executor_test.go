@@ -0,0 +1,81 @@
+package copytrade_test
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/speaker20/whaletown/internal/agents/agenttest"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+func TestExecutorProcessSignal(t *testing.T) {
+	env := agenttest.NewEnv(t)
+	_, key := agenttest.NewWallet()
+
+	config := env.Config()
+	config.SolanaPrivateKey = key
+	exec, err := copytrade.NewExecutor(config)
+	if err != nil {
+		t.Fatalf("NewExecutor: %v", err)
+	}
+
+	whale, _ := agenttest.NewWallet()
+	mint := agenttest.NewMint()
+	whaleTx := env.WhaleBuy(whale, mint, 1_000)
+
+	result, err := exec.ProcessSignal(solana.MustSignatureFromBase58(whaleTx))
+	if err != nil {
+		t.Fatalf("ProcessSignal: %v", err)
+	}
+	if result.TokenMint != mint {
+		t.Errorf("TokenMint = %s, want %s", result.TokenMint, mint)
+	}
+
+	sent := env.Sent()
+	if len(sent) != 1 || sent[0].Signature != result.TxHash {
+		t.Fatalf("sent = %+v, want one transaction %s", sent, result.TxHash)
+	}
+	if sent[0].FeePayer != exec.PublicKey() {
+		t.Errorf("fee payer = %s, want %s", sent[0].FeePayer, exec.PublicKey())
+	}
+	if q := env.Quotes(); len(q) != 1 || q[0].Amount != copytrade.DefaultBuyLamports {
+		t.Errorf("quotes = %+v", q)
+	}
+	if err := exec.Confirm(result.TxHash); err != nil {
+		t.Errorf("Confirm: %v", err)
+	}
+}
+
+func TestExecutorConfirmReportsFailure(t *testing.T) {
+	env := agenttest.NewEnv(t)
+	env.SetFailSends(true)
+	_, key := agenttest.NewWallet()
+
+	exec, err := copytrade.NewExecutorWithKey(env.Config(), key)
+	if err != nil {
+		t.Fatalf("NewExecutorWithKey: %v", err)
+	}
+	result, err := exec.ExecuteBuy(agenttest.NewMint(), 1_000_000, 50)
+	if err != nil {
+		t.Fatalf("ExecuteBuy: %v", err)
+	}
+	if err := exec.Confirm(result.Signature); err == nil {
+		t.Error("Confirm succeeded for a failed transaction")
+	}
+}
+
+func TestExecutorDetectBuyNoSignal(t *testing.T) {
+	env := agenttest.NewEnv(t)
+	_, key := agenttest.NewWallet()
+	exec, err := copytrade.NewExecutorWithKey(env.Config(), key)
+	if err != nil {
+		t.Fatalf("NewExecutorWithKey: %v", err)
+	}
+
+	// A whale "buying" wrapped SOL is not a signal
+	whale, _ := agenttest.NewWallet()
+	sig := env.WhaleBuy(whale, copytrade.WSOLMint, 1_000)
+	if _, err := exec.DetectBuy(solana.MustSignatureFromBase58(sig)); err == nil {
+		t.Error("DetectBuy found a signal in a WSOL-only transaction")
+	}
+}
@@ -0,0 +1,239 @@
+package copytrade
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/speaker20/whaletown/internal/agents/common"
+)
+
+// JupiterProgramID is the Jupiter v6 aggregator program.
+const JupiterProgramID = "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"
+
+// BaseFeeLamports is the fee charged per signature. Anything above it is
+// a priority fee.
+const BaseFeeLamports = 5000
+
+// historyPageSize is the number of signatures requested per page.
+const historyPageSize = 1000
+
+// WalletSwap is a token swap made by a wallet, reconstructed from the
+// transaction's balance changes.
+type WalletSwap struct {
+	Signature   string
+	Timestamp   time.Time
+	Slot        uint64
+	Wallet      string
+	Side        string // "buy" or "sell"
+	TokenMint   string
+	TokenAmount uint64 // Base units bought or sold
+	Decimals    uint8
+	SOLLamports uint64 // SOL spent (buy) or received (sell), excluding fees
+	FeeLamports uint64 // Total fee, including PriorityFeeLamports
+	PriorityFee uint64
+	Venue       string // "jupiter" or "unknown"
+}
+
+// HistoryClient reads a wallet's swaps from on-chain history.
+type HistoryClient struct {
+	rpcClient *rpc.Client
+}
+
+// NewHistoryClient creates a history client using the configured RPC.
+func NewHistoryClient(config *common.Config) *HistoryClient {
+	rpcURL := config.SolanaRPCURL
+	if rpcURL == "" {
+		rpcURL = rpc.MainNetBeta_RPC
+	}
+	return &HistoryClient{rpcClient: rpc.New(rpcURL)}
+}
+
+// WalletSwaps returns the successful swaps made by wallet since the given
+// time, oldest first. At most maxTx signatures are examined (0 = no limit).
+// Transactions that aren't a single-token swap against SOL are skipped.
+func (h *HistoryClient) WalletSwaps(wallet string, since time.Time, maxTx int) ([]WalletSwap, error) {
+	owner, err := solana.PublicKeyFromBase58(wallet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet %q: %w", wallet, err)
+	}
+	ctx := context.Background()
+
+	var sigs []*rpc.TransactionSignature
+	var before solana.Signature
+	for {
+		limit := historyPageSize
+		page, err := h.rpcClient.GetSignaturesForAddressWithOpts(ctx, owner, &rpc.GetSignaturesForAddressOpts{
+			Limit:      &limit,
+			Before:     before,
+			Commitment: rpc.CommitmentConfirmed,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fetching signatures for %s: %w", wallet, err)
+		}
+
+		done := len(page) < limit
+		for _, s := range page {
+			if s.BlockTime != nil && s.BlockTime.Time().Before(since) {
+				done = true
+				break
+			}
+			sigs = append(sigs, s)
+			if maxTx > 0 && len(sigs) >= maxTx {
+				done = true
+				break
+			}
+		}
+		if done || len(page) == 0 {
+			break
+		}
+		before = page[len(page)-1].Signature
+	}
+
+	swaps := []WalletSwap{}
+	// Signatures come newest first; walk backwards for oldest first
+	for i := len(sigs) - 1; i >= 0; i-- {
+		s := sigs[i]
+		if s.Err != nil {
+			continue
+		}
+		swap, err := h.fetchSwap(ctx, owner, s.Signature)
+		if err != nil {
+			return nil, err
+		}
+		if swap != nil {
+			swaps = append(swaps, *swap)
+		}
+	}
+	return swaps, nil
+}
+
+// fetchSwap reconstructs the swap in one transaction, or returns nil if
+// the transaction isn't a swap by owner.
+func (h *HistoryClient) fetchSwap(ctx context.Context, owner solana.PublicKey, sig solana.Signature) (*WalletSwap, error) {
+	tx, err := h.rpcClient.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		Commitment:                     rpc.CommitmentConfirmed,
+		MaxSupportedTransactionVersion: func(v uint64) *uint64 { return &v }(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching transaction %s: %w", sig, err)
+	}
+	if tx == nil || tx.Meta == nil || tx.Meta.Err != nil {
+		return nil, nil
+	}
+	return parseSwap(owner, sig.String(), tx), nil
+}
+
+// parseSwap derives a swap from balance changes of owner's accounts.
+func parseSwap(owner solana.PublicKey, sig string, tx *rpc.GetTransactionResult) *WalletSwap {
+	meta := tx.Meta
+
+	// The owner's native SOL account; the fee payer unless the message
+	// says otherwise.
+	ownerIndex := 0
+	numSigs := 1
+	if tx.Transaction != nil {
+		if decoded, err := tx.Transaction.GetTransaction(); err == nil && decoded != nil {
+			numSigs = int(decoded.Message.Header.NumRequiredSignatures)
+			ownerIndex = -1
+			for i, key := range decoded.Message.AccountKeys {
+				if key.Equals(owner) {
+					ownerIndex = i
+					break
+				}
+			}
+		}
+	}
+
+	var solDelta int64
+	if ownerIndex >= 0 && ownerIndex < len(meta.PreBalances) && ownerIndex < len(meta.PostBalances) {
+		solDelta = int64(meta.PostBalances[ownerIndex]) - int64(meta.PreBalances[ownerIndex])
+	}
+	paidFee := ownerIndex == 0
+	if paidFee {
+		solDelta += int64(meta.Fee) // Fees are reported separately
+	}
+
+	// Token balance changes for accounts owned by owner, by mint
+	deltas := make(map[string]int64)
+	decimals := make(map[string]uint8)
+	addBalance := func(balances []rpc.TokenBalance, sign int64) {
+		for _, b := range balances {
+			if b.Owner == nil || !b.Owner.Equals(owner) || b.UiTokenAmount == nil {
+				continue
+			}
+			amount, err := strconv.ParseInt(b.UiTokenAmount.Amount, 10, 64)
+			if err != nil {
+				continue
+			}
+			mint := b.Mint.String()
+			deltas[mint] += sign * amount
+			decimals[mint] = b.UiTokenAmount.Decimals
+		}
+	}
+	addBalance(meta.PreTokenBalances, -1)
+	addBalance(meta.PostTokenBalances, 1)
+
+	// Wrapped SOL held by the owner counts as SOL
+	solDelta += deltas[WSOLMint]
+	delete(deltas, WSOLMint)
+
+	var mint string
+	for m, d := range deltas {
+		if d == 0 {
+			continue
+		}
+		if mint != "" {
+			return nil // Multi-token transaction; not a simple swap
+		}
+		mint = m
+	}
+	if mint == "" {
+		return nil
+	}
+
+	swap := &WalletSwap{
+		Signature: sig,
+		Slot:      tx.Slot,
+		Wallet:    owner.String(),
+		TokenMint: mint,
+		Decimals:  decimals[mint],
+		Venue:     "unknown",
+	}
+	if tx.BlockTime != nil {
+		swap.Timestamp = tx.BlockTime.Time()
+	}
+	if paidFee {
+		swap.FeeLamports = meta.Fee
+		if base := uint64(BaseFeeLamports * numSigs); meta.Fee > base {
+			swap.PriorityFee = meta.Fee - base
+		}
+	}
+
+	d := deltas[mint]
+	switch {
+	case d > 0 && solDelta < 0:
+		swap.Side = "buy"
+		swap.TokenAmount = uint64(d)
+		swap.SOLLamports = uint64(-solDelta)
+	case d < 0 && solDelta > 0:
+		swap.Side = "sell"
+		swap.TokenAmount = uint64(-d)
+		swap.SOLLamports = uint64(solDelta)
+	default:
+		return nil // Transfer in or out, not a swap against SOL
+	}
+
+	for _, line := range meta.LogMessages {
+		if strings.Contains(line, JupiterProgramID) {
+			swap.Venue = "jupiter"
+			break
+		}
+	}
+	return swap
+}
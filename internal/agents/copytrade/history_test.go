@@ -0,0 +1,59 @@
+package copytrade_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/agents/agenttest"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+func TestHistoryClientWalletSwaps(t *testing.T) {
+	env := agenttest.NewEnv(t)
+	env.SetQuoteOutAmount(7_000)
+	env.SetPriorityFee(20_000)
+	wallet, key := agenttest.NewWallet()
+
+	exec, err := copytrade.NewExecutorWithKey(env.Config(), key)
+	if err != nil {
+		t.Fatalf("NewExecutorWithKey: %v", err)
+	}
+	mint := agenttest.NewMint()
+	buy, err := exec.ExecuteBuy(mint, 2_000_000, 50)
+	if err != nil {
+		t.Fatalf("ExecuteBuy: %v", err)
+	}
+	sell := env.WalletSell(wallet, mint, 7_000, 3_000_000)
+
+	// Failed transactions and other wallets' swaps are not included
+	env.SetFailSends(true)
+	if _, err := exec.ExecuteBuy(mint, 1_000_000, 50); err != nil {
+		t.Fatalf("ExecuteBuy: %v", err)
+	}
+	other, _ := agenttest.NewWallet()
+	env.WhaleBuy(other, mint, 1_000)
+
+	swaps, err := copytrade.NewHistoryClient(env.Config()).WalletSwaps(wallet, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("WalletSwaps: %v", err)
+	}
+	if len(swaps) != 2 {
+		t.Fatalf("got %d swaps, want 2: %+v", len(swaps), swaps)
+	}
+
+	b := swaps[0]
+	if b.Signature != buy.Signature || b.Side != "buy" || b.TokenMint != mint || b.TokenAmount != 7_000 || b.SOLLamports != 2_000_000 {
+		t.Errorf("buy = %+v", b)
+	}
+	if b.FeeLamports != 25_000 || b.PriorityFee != 20_000 || b.Venue != "jupiter" {
+		t.Errorf("buy fees/venue = %d/%d/%s", b.FeeLamports, b.PriorityFee, b.Venue)
+	}
+
+	s := swaps[1]
+	if s.Signature != sell || s.Side != "sell" || s.TokenAmount != 7_000 || s.SOLLamports != 3_000_000 {
+		t.Errorf("sell = %+v", s)
+	}
+	if !s.Timestamp.After(b.Timestamp) {
+		t.Errorf("swaps not oldest first: %v then %v", b.Timestamp, s.Timestamp)
+	}
+}
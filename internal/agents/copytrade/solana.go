@@ -6,29 +6,57 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/speaker20/whaletown/internal/agents/common"
 )
 
-// CacheDuration is how long to cache API results to avoid rate limits.
-const CacheDuration = 60 * time.Second
+const (
+	// pageLimit is the number of transactions requested per page.
+	pageLimit = 100
+
+	// initialPageLimit bounds the first fetch for a wallet with no cursor,
+	// so a new wallet doesn't pull its entire history.
+	initialPageLimit = 10
+
+	// MaxBackfillPages caps how far back a single poll pages to close a gap
+	// after downtime. Older transactions beyond the cap are skipped.
+	MaxBackfillPages = 10
+)
 
 // SolanaTracker monitors Solana whale wallets for swap transactions.
+// Each wallet has a persisted cursor (the newest signature ingested), so
+// every poll fetches exactly the transactions that are new since the last
+// one and merges them into a deduplicated trade store.
 type SolanaTracker struct {
 	config  *common.Config
 	wallets []common.TrackedWallet
 	client  *http.Client
 
-	// Cache to avoid rate limits
-	cacheMu    sync.RWMutex
-	cachedData []common.Trade
-	cacheTime  time.Time
+	cursors *CursorStore
+	store   *TradeStore
+
+	// pollMu serializes polls so concurrent callers don't fetch the same
+	// page twice or race on cursor updates.
+	pollMu sync.Mutex
 }
 
-// NewSolanaTracker creates a new Solana wallet tracker.
+// NewSolanaTracker creates a new Solana wallet tracker using the cursors
+// persisted at CursorsPath.
 func NewSolanaTracker(config *common.Config, wallets []common.TrackedWallet) *SolanaTracker {
+	cursors, err := LoadCursorStore(CursorsPath())
+	if err != nil {
+		fmt.Printf("⚠️  Failed to load trade cursors: %v\n", err)
+	}
+	return NewSolanaTrackerWithCursors(config, wallets, cursors)
+}
+
+// NewSolanaTrackerWithCursors creates a Solana wallet tracker with an
+// explicit cursor store.
+func NewSolanaTrackerWithCursors(config *common.Config, wallets []common.TrackedWallet, cursors *CursorStore) *SolanaTracker {
 	// Filter to only Solana wallets
 	solanaWallets := []common.TrackedWallet{}
 	for _, w := range wallets {
@@ -37,18 +65,25 @@ func NewSolanaTracker(config *common.Config, wallets []common.TrackedWallet) *So
 		}
 	}
 
+	if cursors == nil {
+		cursors = &CursorStore{cursors: make(map[string]WalletCursor)}
+	}
+
 	return &SolanaTracker{
 		config:  config,
 		wallets: solanaWallets,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		cursors: cursors,
+		store:   NewTradeStore(DefaultTradeStoreSize),
 	}
 }
 
 // HeliusTransaction represents a parsed transaction from Helius API.
 type HeliusTransaction struct {
 	Signature       string                 `json:"signature"`
+	Slot            uint64                 `json:"slot"`
 	Timestamp       int64                  `json:"timestamp"`
 	Type            string                 `json:"type"`
 	Description     string                 `json:"description"`
@@ -75,24 +110,35 @@ type HeliusNativeTransfer struct {
 	Amount          int64  `json:"amount"`
 }
 
-// FetchRecentTrades fetches recent swap transactions for all tracked wallets.
-// Results are cached for 60 seconds to avoid rate limits.
+// FetchRecentTrades polls for new transactions and returns the merged
+// trade history for all tracked wallets, newest first.
 func (t *SolanaTracker) FetchRecentTrades() ([]common.Trade, error) {
-	// Check cache first
-	t.cacheMu.RLock()
-	if time.Since(t.cacheTime) < CacheDuration && len(t.cachedData) > 0 {
-		cached := t.cachedData
-		t.cacheMu.RUnlock()
-		return cached, nil
+	if _, err := t.Poll(); err != nil {
+		return nil, err
 	}
-	t.cacheMu.RUnlock()
+	return t.store.Trades(), nil
+}
 
-	// Fetch fresh data
-	allTrades := []common.Trade{}
+// Trades returns the trades ingested so far without polling.
+func (t *SolanaTracker) Trades() []common.Trade {
+	return t.store.Trades()
+}
 
+// Poll fetches transactions newer than each wallet's cursor, merges them
+// into the trade store, advances and persists the cursors, and returns the
+// trades that were not seen before.
+//
+// Poll also serves as the backfill after downtime or a websocket
+// disconnect: it pages back until it reaches the cursor (up to
+// MaxBackfillPages), so no transactions are lost between polls.
+func (t *SolanaTracker) Poll() ([]common.Trade, error) {
+	t.pollMu.Lock()
+	defer t.pollMu.Unlock()
+
+	added := []common.Trade{}
 	for i, wallet := range t.wallets {
 		// Add delay between requests to avoid rate limits
-		if i > 0 {
+		if i > 0 && t.config.HeliusAPIKey != "" {
 			time.Sleep(500 * time.Millisecond)
 		}
 
@@ -102,33 +148,105 @@ func (t *SolanaTracker) FetchRecentTrades() ([]common.Trade, error) {
 			fmt.Printf("Error fetching trades for %s: %v\n", wallet.Alias, err)
 			continue
 		}
-		allTrades = append(allTrades, trades...)
+		added = append(added, t.store.Merge(trades)...)
 	}
 
-	// Update cache
-	t.cacheMu.Lock()
-	t.cachedData = allTrades
-	t.cacheTime = time.Now()
-	t.cacheMu.Unlock()
-
-	return allTrades, nil
+	if err := t.cursors.Save(); err != nil {
+		return added, fmt.Errorf("saving trade cursors: %w", err)
+	}
+	return added, nil
 }
 
-// fetchWalletTrades fetches transactions for a single wallet.
+// fetchWalletTrades fetches the transactions for a single wallet that are
+// newer than its cursor and advances the cursor.
 func (t *SolanaTracker) fetchWalletTrades(wallet common.TrackedWallet) ([]common.Trade, error) {
-	// Use Helius parsed transaction history API
-	url := fmt.Sprintf(
-		"https://api.helius.xyz/v0/addresses/%s/transactions?api-key=%s&limit=10",
-		wallet.Address,
-		t.config.HeliusAPIKey,
-	)
-
 	// If no API key, use a mock/demo mode
 	if t.config.HeliusAPIKey == "" {
 		return t.mockTrades(wallet), nil
 	}
 
-	resp, err := t.client.Get(url)
+	cursor, hasCursor := t.cursors.Get(wallet.Address)
+
+	limit := pageLimit
+	if !hasCursor {
+		limit = initialPageLimit
+	}
+
+	var txns []HeliusTransaction
+	before := ""
+	complete := true
+	for page := 0; page < MaxBackfillPages; page++ {
+		batch, err := t.fetchPage(wallet.Address, before, cursor.Signature, limit)
+		if err != nil {
+			if len(txns) == 0 {
+				return nil, err
+			}
+			// Keep what we have but leave the cursor where it is, so the
+			// gap is fetched again on the next poll. The store drops the
+			// duplicates.
+			fmt.Printf("⚠️  Partial backfill for %s: %v\n", wallet.Alias, err)
+			complete = false
+			break
+		}
+
+		reachedCursor := false
+		for _, tx := range batch {
+			if hasCursor && tx.Signature == cursor.Signature {
+				reachedCursor = true
+				break
+			}
+			txns = append(txns, tx)
+		}
+
+		// A short page means there is nothing older left before the
+		// cursor. Without a cursor, one page is all we want.
+		if reachedCursor || len(batch) < limit || !hasCursor {
+			break
+		}
+		before = batch[len(batch)-1].Signature
+
+		if page == MaxBackfillPages-1 {
+			fmt.Printf("⚠️  Backfill for %s hit %d pages; older transactions skipped\n",
+				wallet.Alias, MaxBackfillPages)
+		}
+	}
+
+	if complete && len(txns) > 0 {
+		// Helius returns newest first
+		newest := txns[0]
+		t.cursors.Set(wallet.Address, WalletCursor{
+			Signature: newest.Signature,
+			Timestamp: time.Unix(newest.Timestamp, 0),
+			UpdatedAt: time.Now(),
+		})
+	}
+
+	return t.parseTrades(wallet, txns), nil
+}
+
+// fetchPage fetches one page of parsed transactions for an address.
+// before and until are signatures bounding the page (exclusive); either
+// may be empty.
+func (t *SolanaTracker) fetchPage(address, before, until string, limit int) ([]HeliusTransaction, error) {
+	return FetchHeliusTransactions(t.client, t.config, address, before, until, limit)
+}
+
+// FetchHeliusTransactions fetches one page of a wallet's parsed
+// transaction history, newest first. before and until are optional
+// signatures bounding the page.
+func FetchHeliusTransactions(client *http.Client, config *common.Config, address, before, until string, limit int) ([]HeliusTransaction, error) {
+	q := url.Values{}
+	q.Set("api-key", config.HeliusAPIKey)
+	q.Set("limit", strconv.Itoa(limit))
+	if before != "" {
+		q.Set("before", before)
+	}
+	if until != "" {
+		q.Set("until", until)
+	}
+	reqURL := fmt.Sprintf("%s/v0/addresses/%s/transactions?%s", config.HeliusBaseURL(), address, q.Encode())
+
+	resp, err := client.Get(reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
 	}
@@ -143,8 +261,7 @@ func (t *SolanaTracker) fetchWalletTrades(wallet common.TrackedWallet) ([]common
 	if err := json.NewDecoder(resp.Body).Decode(&txns); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	return t.parseTrades(wallet, txns), nil
+	return txns, nil
 }
 
 // parseTrades converts Helius transactions to our Trade format.
@@ -0,0 +1,198 @@
+package copytrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/agents/common"
+)
+
+// fakeHelius serves a wallet's transaction history (newest first) with
+// Helius before/until/limit pagination semantics.
+type fakeHelius struct {
+	mu       sync.Mutex
+	history  []HeliusTransaction
+	requests int
+}
+
+func (f *fakeHelius) push(sig string, ts int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tx := HeliusTransaction{
+		Signature: sig,
+		Timestamp: ts,
+		Type:      "SWAP",
+		TokenTransfers: []HeliusTokenTransfer{
+			{Mint: "So11111111111111111111111111111111111111112", TokenSymbol: "SOL", TokenAmount: 1},
+			{Mint: "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263", TokenSymbol: "BONK", TokenAmount: 1000},
+		},
+	}
+	f.history = append([]HeliusTransaction{tx}, f.history...)
+}
+
+func (f *fakeHelius) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	before, until := q.Get("before"), q.Get("until")
+
+	start := 0
+	if before != "" {
+		for i, tx := range f.history {
+			if tx.Signature == before {
+				start = i + 1
+				break
+			}
+		}
+	}
+	page := []HeliusTransaction{}
+	for _, tx := range f.history[start:] {
+		if tx.Signature == until || len(page) == limit {
+			break
+		}
+		page = append(page, tx)
+	}
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func newTestTracker(t *testing.T, srv *httptest.Server, cursorPath string) *SolanaTracker {
+	t.Helper()
+	cursors, err := LoadCursorStore(cursorPath)
+	if err != nil {
+		t.Fatalf("LoadCursorStore: %v", err)
+	}
+	wallets := []common.TrackedWallet{{Address: "whale1", Alias: "Whale", Platform: "solana"}}
+	config := &common.Config{HeliusAPIKey: "test", HeliusAPIURL: srv.URL}
+	return NewSolanaTrackerWithCursors(config, wallets, cursors)
+}
+
+func TestSolanaTrackerPollIncremental(t *testing.T) {
+	helius := &fakeHelius{}
+	srv := httptest.NewServer(helius)
+	defer srv.Close()
+
+	base := time.Now().Add(-time.Hour).Unix()
+	for i := 0; i < 3; i++ {
+		helius.push(fmt.Sprintf("sig%03d", i), base+int64(i))
+	}
+
+	cursorPath := filepath.Join(t.TempDir(), "cursors.json")
+	tracker := newTestTracker(t, srv, cursorPath)
+
+	added, err := tracker.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(added) != 3 {
+		t.Fatalf("first poll added %d trades, want 3", len(added))
+	}
+
+	// Nothing new: no trades added
+	added, err = tracker.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(added) != 0 {
+		t.Errorf("idle poll added %d trades, want 0", len(added))
+	}
+
+	// A burst larger than one page is fully ingested
+	for i := 3; i < 3+pageLimit+25; i++ {
+		helius.push(fmt.Sprintf("sig%03d", i), base+int64(i))
+	}
+	added, err = tracker.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(added) != pageLimit+25 {
+		t.Errorf("burst poll added %d trades, want %d", len(added), pageLimit+25)
+	}
+
+	trades := tracker.Trades()
+	if len(trades) != pageLimit+28 {
+		t.Fatalf("store has %d trades, want %d", len(trades), pageLimit+28)
+	}
+	if trades[0].TxHash != fmt.Sprintf("sig%03d", pageLimit+27) {
+		t.Errorf("newest trade = %s, want sig%03d", trades[0].TxHash, pageLimit+27)
+	}
+}
+
+func TestSolanaTrackerCursorPersists(t *testing.T) {
+	helius := &fakeHelius{}
+	srv := httptest.NewServer(helius)
+	defer srv.Close()
+
+	base := time.Now().Add(-time.Hour).Unix()
+	helius.push("sigA", base)
+	helius.push("sigB", base+1)
+
+	cursorPath := filepath.Join(t.TempDir(), "cursors.json")
+	if _, err := newTestTracker(t, srv, cursorPath).Poll(); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	// Simulate downtime: new transactions arrive, then a fresh tracker
+	// starts from the persisted cursor.
+	helius.push("sigC", base+2)
+	helius.push("sigD", base+3)
+
+	tracker := newTestTracker(t, srv, cursorPath)
+	added, err := tracker.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("backfill added %d trades, want 2", len(added))
+	}
+	if added[0].TxHash != "sigD" || added[1].TxHash != "sigC" {
+		t.Errorf("backfill = %s, %s; want sigD, sigC", added[0].TxHash, added[1].TxHash)
+	}
+
+	c, ok := tracker.cursors.Get("whale1")
+	if !ok || c.Signature != "sigD" {
+		t.Errorf("cursor = %+v, want sigD", c)
+	}
+}
+
+func TestTradeStoreMergeDedup(t *testing.T) {
+	s := NewTradeStore(3)
+	now := time.Now()
+
+	added := s.Merge([]common.Trade{
+		{Wallet: "w1", TxHash: "a", Timestamp: now.Add(-3 * time.Minute)},
+		{Wallet: "w1", TxHash: "b", Timestamp: now.Add(-2 * time.Minute)},
+		{Wallet: "w2", TxHash: "b", Timestamp: now.Add(-2 * time.Minute)},
+		{Wallet: "w1", TxHash: "a", Timestamp: now.Add(-3 * time.Minute)},
+	})
+	if len(added) != 3 {
+		t.Fatalf("added %d trades, want 3", len(added))
+	}
+
+	added = s.Merge([]common.Trade{{Wallet: "w1", TxHash: "c", Timestamp: now}})
+	if len(added) != 1 {
+		t.Fatalf("added %d trades, want 1", len(added))
+	}
+
+	trades := s.Trades()
+	if len(trades) != 3 {
+		t.Fatalf("store has %d trades, want 3", len(trades))
+	}
+	if trades[0].TxHash != "c" {
+		t.Errorf("newest = %s, want c", trades[0].TxHash)
+	}
+	for _, tr := range trades {
+		if tr.TxHash == "a" {
+			t.Errorf("oldest trade was not evicted")
+		}
+	}
+}
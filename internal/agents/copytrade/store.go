@@ -0,0 +1,90 @@
+package copytrade
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/speaker20/whaletown/internal/agents/common"
+)
+
+// DefaultTradeStoreSize is the number of trades retained by a TradeStore.
+const DefaultTradeStoreSize = 500
+
+// TradeStore holds trades from all tracked wallets, newest first,
+// deduplicated by wallet and transaction signature.
+type TradeStore struct {
+	mu     sync.RWMutex
+	max    int
+	seen   map[string]struct{}
+	trades []common.Trade
+}
+
+// NewTradeStore creates a store retaining at most max trades.
+func NewTradeStore(max int) *TradeStore {
+	if max <= 0 {
+		max = DefaultTradeStoreSize
+	}
+	return &TradeStore{
+		max:  max,
+		seen: make(map[string]struct{}),
+	}
+}
+
+// tradeKey identifies a trade. One transaction can touch several tracked
+// wallets, so the wallet is part of the key.
+func tradeKey(t common.Trade) string {
+	return t.Wallet + "/" + t.TxHash
+}
+
+// Merge adds trades not already in the store and returns the ones added.
+func (s *TradeStore) Merge(trades []common.Trade) []common.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := []common.Trade{}
+	for _, t := range trades {
+		if t.TxHash == "" {
+			continue
+		}
+		key := tradeKey(t)
+		if _, ok := s.seen[key]; ok {
+			continue
+		}
+		s.seen[key] = struct{}{}
+		s.trades = append(s.trades, t)
+		added = append(added, t)
+	}
+	if len(added) == 0 {
+		return added
+	}
+
+	sort.SliceStable(s.trades, func(i, j int) bool {
+		return s.trades[i].Timestamp.After(s.trades[j].Timestamp)
+	})
+
+	// Evict the oldest beyond capacity
+	if len(s.trades) > s.max {
+		for _, t := range s.trades[s.max:] {
+			delete(s.seen, tradeKey(t))
+		}
+		s.trades = s.trades[:s.max]
+	}
+
+	return added
+}
+
+// Trades returns a copy of the stored trades, newest first.
+func (s *TradeStore) Trades() []common.Trade {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]common.Trade, len(s.trades))
+	copy(out, s.trades)
+	return out
+}
+
+// Len returns the number of stored trades.
+func (s *TradeStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.trades)
+}
@@ -23,6 +23,16 @@ type WebSocketListener struct {
 	mu      sync.Mutex
 	running bool
 	OnTrade TradeCallback
+
+	// Subscription bookkeeping so alerts carry the wallet that triggered
+	// them: request ID -> wallet, then subscription ID -> wallet.
+	pending       map[string]common.TrackedWallet
+	subscriptions map[int64]common.TrackedWallet
+
+	// OnReconnect is called after the connection is re-established.
+	// Notifications sent while disconnected are lost, so callers use it
+	// to backfill from the REST API.
+	OnReconnect func()
 }
 
 // NewWebSocketListener creates a listener for real-time wallet monitoring.
@@ -36,8 +46,10 @@ func NewWebSocketListener(config *common.Config, wallets []common.TrackedWallet)
 	}
 
 	return &WebSocketListener{
-		config:  config,
-		wallets: solanaWallets,
+		config:        config,
+		wallets:       solanaWallets,
+		pending:       make(map[string]common.TrackedWallet),
+		subscriptions: make(map[int64]common.TrackedWallet),
 	}
 }
 
@@ -112,10 +124,14 @@ func (l *WebSocketListener) pingLoop(ctx context.Context) {
 
 // subscribeToWallet sends a subscription request for a wallet.
 func (l *WebSocketListener) subscribeToWallet(wallet common.TrackedWallet) error {
+	l.mu.Lock()
+	l.pending[wallet.Address] = wallet
+	l.mu.Unlock()
+
 	// Solana logs subscription to monitor account activity
 	subRequest := map[string]interface{}{
 		"jsonrpc": "2.0",
-		"id":      wallet.Address[:8],
+		"id":      wallet.Address,
 		"method":  "logsSubscribe",
 		"params": []interface{}{
 			map[string]interface{}{
@@ -193,6 +209,8 @@ func (l *WebSocketListener) reconnect(ctx context.Context) error {
 
 	l.mu.Lock()
 	l.conn = conn
+	l.pending = make(map[string]common.TrackedWallet)
+	l.subscriptions = make(map[int64]common.TrackedWallet)
 	l.mu.Unlock()
 
 	// Re-subscribe to all wallets
@@ -205,6 +223,10 @@ func (l *WebSocketListener) reconnect(ctx context.Context) error {
 	}
 
 	fmt.Println("✅ WebSocket reconnected successfully")
+
+	if l.OnReconnect != nil {
+		go l.OnReconnect()
+	}
 	return nil
 }
 
@@ -218,7 +240,21 @@ func (l *WebSocketListener) handleMessage(message []byte) {
 	// Check if this is a notification (new transaction)
 	if method, ok := msg["method"].(string); ok && method == "logsNotification" {
 		l.handleLogsNotification(msg)
+		return
+	}
+
+	// Otherwise it may be a subscription confirmation: {"id": <request id>, "result": <subscription id>}
+	id, _ := msg["id"].(string)
+	subID, ok := msg["result"].(float64)
+	if id == "" || !ok {
+		return
 	}
+	l.mu.Lock()
+	if wallet, found := l.pending[id]; found {
+		delete(l.pending, id)
+		l.subscriptions[int64(subID)] = wallet
+	}
+	l.mu.Unlock()
 }
 
 // handleLogsNotification processes a logs notification.
@@ -254,6 +290,15 @@ func (l *WebSocketListener) handleLogsNotification(msg map[string]interface{}) {
 		Platform:    "solana",
 		WalletAlias: "Whale Alert",
 	}
+	if subID, ok := params["subscription"].(float64); ok {
+		l.mu.Lock()
+		wallet, found := l.subscriptions[int64(subID)]
+		l.mu.Unlock()
+		if found {
+			trade.Wallet = wallet.Address
+			trade.WalletAlias = wallet.Alias
+		}
+	}
 
 	if l.OnTrade != nil {
 		l.OnTrade(trade)
@@ -0,0 +1,107 @@
+package researcher
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/agents/common"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+// ActivitySource supplies the on-chain activity used for clustering.
+type ActivitySource interface {
+	WalletActivity(address string) (*WalletActivity, error)
+}
+
+// DefaultActivityPages is how many pages of history HeliusActivity reads
+// per wallet.
+const DefaultActivityPages = 5
+
+// activityPageSize is the number of transactions per Helius request.
+const activityPageSize = 100
+
+// HeliusActivity reads wallet activity from Helius parsed transaction
+// history. Only the most recent MaxPages pages are read, so FundedBy is
+// the earliest SOL sender within that window rather than necessarily the
+// wallet's original funder.
+type HeliusActivity struct {
+	config   *common.Config
+	client   *http.Client
+	MaxPages int
+}
+
+// NewHeliusActivity creates an activity source using the Helius API.
+func NewHeliusActivity(config *common.Config) *HeliusActivity {
+	return &HeliusActivity{
+		config:   config,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		MaxPages: DefaultActivityPages,
+	}
+}
+
+// WalletActivity returns the token entries, transfers and funding source
+// of address.
+func (h *HeliusActivity) WalletActivity(address string) (*WalletActivity, error) {
+	var txns []copytrade.HeliusTransaction
+	before := ""
+	for page := 0; page < h.MaxPages; page++ {
+		batch, err := copytrade.FetchHeliusTransactions(h.client, h.config, address, before, "", activityPageSize)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, batch...)
+		if len(batch) < activityPageSize {
+			break
+		}
+		before = batch[len(batch)-1].Signature
+	}
+	return activityFromTransactions(address, txns), nil
+}
+
+// activityFromTransactions derives activity from history, newest first.
+func activityFromTransactions(address string, txns []copytrade.HeliusTransaction) *WalletActivity {
+	a := &WalletActivity{Address: address}
+	entries := make(map[string]uint64)
+	var order []string
+
+	for _, tx := range txns {
+		if tx.Type == "SWAP" {
+			for _, t := range tx.TokenTransfers {
+				if t.ToUserAccount != address || t.Mint == copytrade.WSOLMint {
+					continue
+				}
+				// Walking newest to oldest, the last slot seen is the first buy
+				if _, ok := entries[t.Mint]; !ok {
+					order = append(order, t.Mint)
+				}
+				entries[t.Mint] = tx.Slot
+			}
+			continue
+		}
+
+		for _, t := range tx.NativeTransfers {
+			if t.Amount <= 0 || t.FromUserAccount == t.ToUserAccount {
+				continue
+			}
+			if t.ToUserAccount == address && t.FromUserAccount != "" {
+				a.FundedBy = t.FromUserAccount
+			}
+			if t.ToUserAccount == address || t.FromUserAccount == address {
+				a.Transfers = append(a.Transfers, Transfer{From: t.FromUserAccount, To: t.ToUserAccount})
+			}
+		}
+		for _, t := range tx.TokenTransfers {
+			if t.FromUserAccount == t.ToUserAccount {
+				continue
+			}
+			if t.ToUserAccount == address || t.FromUserAccount == address {
+				a.Transfers = append(a.Transfers, Transfer{From: t.FromUserAccount, To: t.ToUserAccount})
+			}
+		}
+	}
+
+	for _, mint := range order {
+		a.Entries = append(a.Entries, TokenEntry{Mint: mint, Slot: entries[mint]})
+	}
+	return a
+}
@@ -0,0 +1,398 @@
+package researcher
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WalletActivity is the on-chain activity used to relate wallets.
+type WalletActivity struct {
+	Address   string
+	FundedBy  string       // Sender of the earliest SOL received, if known
+	Entries   []TokenEntry // First buy of each token
+	Transfers []Transfer   // SOL and token transfers involving the wallet
+}
+
+// TokenEntry is a wallet's first buy of a token.
+type TokenEntry struct {
+	Mint string
+	Slot uint64
+}
+
+// Transfer moves SOL or tokens between two wallets.
+type Transfer struct {
+	From string
+	To   string
+}
+
+// ClusterConfig tunes how wallets are linked.
+type ClusterConfig struct {
+	// EntrySlots is how close two entries into the same token must be
+	// to count as shared (default 2).
+	EntrySlots uint64
+
+	// MinSharedEntries is how many shared entries link two wallets
+	// (default 3).
+	MinSharedEntries int
+
+	// MinTransfers is how many direct transfers link two wallets
+	// (default 1).
+	MinTransfers int
+
+	// CopyLagSlots is the largest lag, in slots, of a copy-bot behind
+	// its leader (default 5).
+	CopyLagSlots uint64
+
+	// MinCopies is how many of a leader's entries a wallet must trail
+	// to be flagged as a copy-bot (default 3).
+	MinCopies int
+
+	// CopyRatio is the fraction of shared entries that must trail the
+	// leader by 1..CopyLagSlots slots (default 0.8).
+	CopyRatio float64
+
+	// IgnoreFunders are funding sources shared by unrelated wallets,
+	// such as exchange hot wallets.
+	IgnoreFunders []string
+}
+
+// DefaultClusterConfig returns the default clustering thresholds.
+func DefaultClusterConfig() ClusterConfig {
+	return ClusterConfig{
+		EntrySlots:       2,
+		MinSharedEntries: 3,
+		MinTransfers:     1,
+		CopyLagSlots:     5,
+		MinCopies:        3,
+		CopyRatio:        0.8,
+	}
+}
+
+// withDefaults fills zero fields from DefaultClusterConfig.
+func (c ClusterConfig) withDefaults() ClusterConfig {
+	d := DefaultClusterConfig()
+	if c.EntrySlots == 0 {
+		c.EntrySlots = d.EntrySlots
+	}
+	if c.MinSharedEntries == 0 {
+		c.MinSharedEntries = d.MinSharedEntries
+	}
+	if c.MinTransfers == 0 {
+		c.MinTransfers = d.MinTransfers
+	}
+	if c.CopyLagSlots == 0 {
+		c.CopyLagSlots = d.CopyLagSlots
+	}
+	if c.MinCopies == 0 {
+		c.MinCopies = d.MinCopies
+	}
+	if c.CopyRatio == 0 {
+		c.CopyRatio = d.CopyRatio
+	}
+	return c
+}
+
+// Cluster is a group of wallets likely run by one operator.
+type Cluster struct {
+	ID      string   `json:"id"`
+	Leader  string   `json:"leader"`  // Highest-scoring member that isn't a copy-bot
+	Members []string `json:"members"` // Including the leader
+	Reasons []string `json:"reasons"` // Why members were linked
+}
+
+// CopyBot is a wallet that consistently enters tokens shortly after a
+// leader.
+type CopyBot struct {
+	Address string `json:"address"`
+	Leader  string `json:"leader"`
+	Copies  int    `json:"copies"`   // Leader entries trailed
+	LagSlot uint64 `json:"lag_slot"` // Median lag behind the leader
+}
+
+// ClusterResult is the outcome of clustering a set of wallets.
+type ClusterResult struct {
+	Clusters  []Cluster
+	CopyBots  []CopyBot
+	ClusterOf map[string]string // Address -> cluster ID, clustered wallets only
+}
+
+// ClusterWallets links wallets that share a funding source, transfer to
+// each other, or repeatedly enter the same tokens within a few slots,
+// and flags copy-bots trailing a leader. scores picks each cluster's
+// leader; ties go to the lowest address.
+func ClusterWallets(activity []WalletActivity, scores map[string]int, cfg ClusterConfig) *ClusterResult {
+	cfg = cfg.withDefaults()
+
+	uf := newUnionFind()
+	reasons := make(map[[2]string]string)
+	link := func(a, b, why string) {
+		if a == b {
+			return
+		}
+		uf.union(a, b)
+		key := [2]string{a, b}
+		if b < a {
+			key = [2]string{b, a}
+		}
+		if _, ok := reasons[key]; !ok {
+			reasons[key] = why
+		}
+	}
+
+	tracked := make(map[string]bool, len(activity))
+	for _, a := range activity {
+		tracked[a.Address] = true
+		uf.add(a.Address)
+	}
+
+	ignore := make(map[string]bool, len(cfg.IgnoreFunders))
+	for _, f := range cfg.IgnoreFunders {
+		ignore[f] = true
+	}
+
+	// Funding: a common funder, or funded by another tracked wallet
+	funded := make(map[string][]string)
+	for _, a := range activity {
+		if a.FundedBy == "" || ignore[a.FundedBy] {
+			continue
+		}
+		if tracked[a.FundedBy] {
+			link(a.FundedBy, a.Address, shortAddr(a.FundedBy)+" funded "+shortAddr(a.Address))
+		}
+		funded[a.FundedBy] = append(funded[a.FundedBy], a.Address)
+	}
+	for funder, wallets := range funded {
+		for _, w := range wallets[1:] {
+			link(wallets[0], w, "common funder "+shortAddr(funder))
+		}
+	}
+
+	// Transfer graph between tracked wallets
+	transfers := make(map[[2]string]int)
+	for _, a := range activity {
+		for _, t := range a.Transfers {
+			if t.From == t.To || !tracked[t.From] || !tracked[t.To] {
+				continue
+			}
+			key := [2]string{t.From, t.To}
+			if t.To < t.From {
+				key = [2]string{t.To, t.From}
+			}
+			transfers[key]++
+		}
+	}
+	for pair, n := range transfers {
+		// Each transfer appears in both wallets' activity
+		if (n+1)/2 >= cfg.MinTransfers {
+			link(pair[0], pair[1], "transfers")
+		}
+	}
+
+	// Entries: coordinated (same time) or copied (consistent lag)
+	var bots []CopyBot
+	isBot := make(map[string]bool)
+	for i := range activity {
+		for j := i + 1; j < len(activity); j++ {
+			a, b := &activity[i], &activity[j]
+			common, shared, lagsAB, lagsBA := compareEntries(a.Entries, b.Entries, cfg)
+			if shared >= cfg.MinSharedEntries {
+				link(a.Address, b.Address, fmt.Sprintf("%d shared entries", shared))
+			}
+			if bot, ok := copyBot(b.Address, a.Address, lagsAB, common, cfg); ok && !isBot[bot.Address] {
+				bots = append(bots, bot)
+				isBot[bot.Address] = true
+				link(a.Address, b.Address, "copies "+shortAddr(a.Address))
+			} else if bot, ok := copyBot(a.Address, b.Address, lagsBA, common, cfg); ok && !isBot[bot.Address] {
+				bots = append(bots, bot)
+				isBot[bot.Address] = true
+				link(a.Address, b.Address, "copies "+shortAddr(b.Address))
+			}
+		}
+	}
+
+	// Collect groups of two or more
+	groups := make(map[string][]string)
+	for _, a := range activity {
+		root := uf.find(a.Address)
+		groups[root] = append(groups[root], a.Address)
+	}
+
+	result := &ClusterResult{ClusterOf: make(map[string]string)}
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Strings(members)
+
+		leader := ""
+		for _, m := range members {
+			if isBot[m] {
+				continue
+			}
+			if leader == "" || scores[m] > scores[leader] {
+				leader = m
+			}
+		}
+		if leader == "" {
+			leader = members[0]
+		}
+
+		c := Cluster{ID: "cl-" + shortID(leader), Leader: leader, Members: members}
+		seen := make(map[string]bool)
+		for pair, why := range reasons {
+			if uf.find(pair[0]) == uf.find(leader) && !seen[why] {
+				seen[why] = true
+				c.Reasons = append(c.Reasons, why)
+			}
+		}
+		sort.Strings(c.Reasons)
+
+		for _, m := range members {
+			result.ClusterOf[m] = c.ID
+		}
+		result.Clusters = append(result.Clusters, c)
+	}
+	sort.Slice(result.Clusters, func(i, j int) bool { return result.Clusters[i].ID < result.Clusters[j].ID })
+	sort.Slice(bots, func(i, j int) bool { return bots[i].Address < bots[j].Address })
+	result.CopyBots = bots
+	return result
+}
+
+// compareEntries matches two wallets' entries by mint. It returns the
+// number of tokens both entered, how many of those entries were within
+// cfg.EntrySlots of each other, and the lags of entries where b trailed
+// a (lagsAB) or a trailed b (lagsBA) by 1..cfg.CopyLagSlots slots.
+func compareEntries(a, b []TokenEntry, cfg ClusterConfig) (common, shared int, lagsAB, lagsBA []uint64) {
+	first := make(map[string]uint64, len(a))
+	for _, e := range a {
+		if s, ok := first[e.Mint]; !ok || e.Slot < s {
+			first[e.Mint] = e.Slot
+		}
+	}
+	for _, e := range b {
+		sa, ok := first[e.Mint]
+		if !ok {
+			continue
+		}
+		common++
+		switch {
+		case e.Slot > sa && e.Slot-sa <= cfg.CopyLagSlots:
+			lagsAB = append(lagsAB, e.Slot-sa)
+		case sa > e.Slot && sa-e.Slot <= cfg.CopyLagSlots:
+			lagsBA = append(lagsBA, sa-e.Slot)
+		}
+		if diff(e.Slot, sa) <= cfg.EntrySlots {
+			shared++
+		}
+	}
+	return common, shared, lagsAB, lagsBA
+}
+
+// copyBot reports whether follower trails leader: at least MinCopies
+// entries lagging it, making up CopyRatio of their common entries.
+func copyBot(follower, leader string, lags []uint64, common int, cfg ClusterConfig) (CopyBot, bool) {
+	if len(lags) < cfg.MinCopies || common == 0 {
+		return CopyBot{}, false
+	}
+	if float64(len(lags))/float64(common) < cfg.CopyRatio {
+		return CopyBot{}, false
+	}
+	sorted := append([]uint64(nil), lags...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return CopyBot{
+		Address: follower,
+		Leader:  leader,
+		Copies:  len(lags),
+		LagSlot: sorted[len(sorted)/2],
+	}, true
+}
+
+// Dedupe keeps only the leader of each cluster, annotated with the
+// cluster ID and size. Copy-bots are dropped even if unclustered.
+// Order is preserved.
+func Dedupe(wallets []WalletEntry, result *ClusterResult) []WalletEntry {
+	if result == nil {
+		return wallets
+	}
+	leaders := make(map[string]Cluster, len(result.Clusters))
+	for _, c := range result.Clusters {
+		leaders[c.Leader] = c
+	}
+	bots := make(map[string]bool, len(result.CopyBots))
+	for _, b := range result.CopyBots {
+		bots[b.Address] = true
+	}
+
+	out := make([]WalletEntry, 0, len(wallets))
+	for _, w := range wallets {
+		if c, ok := leaders[w.Address]; ok {
+			w.ClusterID = c.ID
+			w.ClusterSize = len(c.Members)
+			out = append(out, w)
+			continue
+		}
+		if _, clustered := result.ClusterOf[w.Address]; clustered || bots[w.Address] {
+			continue
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+func diff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// shortAddr abbreviates an address for display.
+func shortAddr(addr string) string {
+	if len(addr) <= 10 {
+		return addr
+	}
+	return addr[:4] + "..." + addr[len(addr)-4:]
+}
+
+// shortID is the stable short form of an address used in cluster IDs.
+func shortID(addr string) string {
+	if len(addr) <= 6 {
+		return addr
+	}
+	return addr[:6]
+}
+
+// unionFind groups wallets into clusters.
+type unionFind struct {
+	parent map[string]string
+}
+
+func newUnionFind() *unionFind {
+	return &unionFind{parent: make(map[string]string)}
+}
+
+func (u *unionFind) add(x string) {
+	if _, ok := u.parent[x]; !ok {
+		u.parent[x] = x
+	}
+}
+
+func (u *unionFind) find(x string) string {
+	u.add(x)
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+func (u *unionFind) union(a, b string) {
+	ra, rb := u.find(a), u.find(b)
+	if ra == rb {
+		return
+	}
+	if rb < ra {
+		ra, rb = rb, ra
+	}
+	u.parent[rb] = ra
+}
@@ -0,0 +1,124 @@
+package researcher
+
+import (
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+// entries returns entries into mints m0..m(n-1) at base+i*100+lag.
+func entries(n int, base, lag uint64) []TokenEntry {
+	out := make([]TokenEntry, n)
+	for i := range out {
+		out[i] = TokenEntry{Mint: "m" + string(rune('a'+i)), Slot: base + uint64(i)*100 + lag}
+	}
+	return out
+}
+
+func TestClusterWalletsFundingAndTransfers(t *testing.T) {
+	activity := []WalletActivity{
+		{Address: "alpha", FundedBy: "operator"},
+		{Address: "beta", FundedBy: "operator"},
+		{Address: "gamma", Transfers: []Transfer{{From: "beta", To: "gamma"}}},
+		{Address: "delta", FundedBy: "binance"},
+		{Address: "eps", FundedBy: "binance"},
+	}
+	scores := map[string]int{"alpha": 70, "beta": 90, "gamma": 80}
+	cfg := ClusterConfig{IgnoreFunders: []string{"binance"}}
+
+	r := ClusterWallets(activity, scores, cfg)
+	if len(r.Clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1: %+v", len(r.Clusters), r.Clusters)
+	}
+	c := r.Clusters[0]
+	if c.Leader != "beta" || len(c.Members) != 3 || c.ID != "cl-beta" {
+		t.Errorf("cluster = %+v", c)
+	}
+	if _, ok := r.ClusterOf["delta"]; ok {
+		t.Error("wallets funded by an ignored funder should not cluster")
+	}
+}
+
+func TestClusterWalletsSharedEntries(t *testing.T) {
+	activity := []WalletActivity{
+		{Address: "a", Entries: entries(4, 1000, 0)},
+		{Address: "b", Entries: entries(4, 1000, 1)}, // Within EntrySlots: coordinated
+		{Address: "c", Entries: entries(2, 1000, 0)}, // Too few shared entries
+	}
+	r := ClusterWallets(activity, map[string]int{"a": 50, "b": 60}, ClusterConfig{CopyLagSlots: 1, MinCopies: 10})
+	if len(r.Clusters) != 1 || r.Clusters[0].Leader != "b" || len(r.Clusters[0].Members) != 2 {
+		t.Fatalf("clusters = %+v", r.Clusters)
+	}
+	if len(r.CopyBots) != 0 {
+		t.Errorf("copy bots = %+v, want none", r.CopyBots)
+	}
+}
+
+func TestClusterWalletsCopyBot(t *testing.T) {
+	leader := entries(5, 1000, 0)
+	// Trails the leader by 3-4 slots on every entry, plus one of its own
+	bot := entries(5, 1000, 3)
+	bot[1].Slot++
+	bot = append(bot, TokenEntry{Mint: "own", Slot: 5000})
+
+	activity := []WalletActivity{
+		{Address: "bot", Entries: bot},
+		{Address: "whale", Entries: leader},
+	}
+	// The bot scores higher, but the leader still leads the cluster
+	r := ClusterWallets(activity, map[string]int{"bot": 95, "whale": 80}, ClusterConfig{})
+	if len(r.CopyBots) != 1 {
+		t.Fatalf("copy bots = %+v, want 1", r.CopyBots)
+	}
+	b := r.CopyBots[0]
+	if b.Address != "bot" || b.Leader != "whale" || b.Copies != 5 || b.LagSlot != 3 {
+		t.Errorf("copy bot = %+v", b)
+	}
+	if len(r.Clusters) != 1 || r.Clusters[0].Leader != "whale" {
+		t.Fatalf("clusters = %+v", r.Clusters)
+	}
+
+	wallets := []WalletEntry{
+		{Address: "bot", Score: 95},
+		{Address: "whale", Score: 80},
+		{Address: "solo", Score: 70},
+	}
+	deduped := Dedupe(wallets, r)
+	if len(deduped) != 2 || deduped[0].Address != "whale" || deduped[1].Address != "solo" {
+		t.Fatalf("deduped = %+v", deduped)
+	}
+	if deduped[0].ClusterID != r.Clusters[0].ID || deduped[0].ClusterSize != 2 {
+		t.Errorf("leader cluster = %s/%d", deduped[0].ClusterID, deduped[0].ClusterSize)
+	}
+}
+
+func TestActivityFromTransactions(t *testing.T) {
+	const me = "me"
+	// Newest first
+	txns := []copytrade.HeliusTransaction{
+		{Type: "SWAP", Slot: 300, TokenTransfers: []copytrade.HeliusTokenTransfer{
+			{FromUserAccount: me, Mint: copytrade.WSOLMint},
+			{ToUserAccount: me, Mint: "BONK"},
+		}},
+		{Type: "TRANSFER", Slot: 250, NativeTransfers: []copytrade.HeliusNativeTransfer{
+			{FromUserAccount: me, ToUserAccount: "friend", Amount: 10},
+		}},
+		{Type: "SWAP", Slot: 200, TokenTransfers: []copytrade.HeliusTokenTransfer{
+			{ToUserAccount: me, Mint: "BONK"},
+		}},
+		{Type: "TRANSFER", Slot: 100, NativeTransfers: []copytrade.HeliusNativeTransfer{
+			{FromUserAccount: "funder", ToUserAccount: me, Amount: 1_000_000_000},
+		}},
+	}
+
+	a := activityFromTransactions(me, txns)
+	if a.FundedBy != "funder" {
+		t.Errorf("FundedBy = %q, want funder", a.FundedBy)
+	}
+	if len(a.Entries) != 1 || a.Entries[0].Mint != "BONK" || a.Entries[0].Slot != 200 {
+		t.Errorf("entries = %+v, want first BONK buy at slot 200", a.Entries)
+	}
+	if len(a.Transfers) != 2 {
+		t.Errorf("transfers = %+v, want 2", a.Transfers)
+	}
+}
@@ -16,6 +16,8 @@ import (
 type Watchlist struct {
 	UpdatedAt time.Time     `json:"updated_at"`
 	Wallets   []WalletEntry `json:"wallets"`
+	Clusters  []Cluster     `json:"clusters,omitempty"`  // Wallet groups, deduplicated to their leaders
+	CopyBots  []CopyBot     `json:"copy_bots,omitempty"` // Wallets dropped for trailing a leader
 }
 
 // WalletEntry represents a wallet in the watchlist.
@@ -28,6 +30,9 @@ type WalletEntry struct {
 	Trades   int     `json:"trades"`    // Number of trades tracked
 	Source   string  `json:"source"`    // "dune", "nansen", "manual"
 	Platform string  `json:"platform"`  // "solana", "polymarket"
+
+	ClusterID   string `json:"cluster_id,omitempty"`   // Set if the wallet leads a cluster
+	ClusterSize int    `json:"cluster_size,omitempty"` // Wallets in the cluster, including this one
 }
 
 // WatchlistPath returns the path to the watchlist file.
@@ -81,11 +86,15 @@ func SaveWatchlist(wl *Watchlist) error {
 func (wl *Watchlist) ToTrackedWallets() []common.TrackedWallet {
 	result := make([]common.TrackedWallet, len(wl.Wallets))
 	for i, w := range wl.Wallets {
+		notes := fmt.Sprintf("Score: %d, Win rate: %.0f%%", w.Score, w.WinRate*100)
+		if w.ClusterID != "" {
+			notes += fmt.Sprintf(", Cluster: %s (%d wallets)", w.ClusterID, w.ClusterSize)
+		}
 		result[i] = common.TrackedWallet{
 			Address:  w.Address,
 			Alias:    w.Alias,
 			Platform: w.Platform,
-			Notes:    fmt.Sprintf("Score: %d, Win rate: %.0f%%", w.Score, w.WinRate*100),
+			Notes:    notes,
 		}
 	}
 	return result
@@ -96,6 +105,11 @@ type Researcher struct {
 	stopCh   chan struct{}
 	interval time.Duration
 	OnUpdate func(*Watchlist) // Callback when watchlist updates
+
+	// Activity supplies on-chain activity for clustering. If nil,
+	// wallets are not clustered.
+	Activity ActivitySource
+	Cluster  ClusterConfig
 }
 
 // NewResearcher creates a new researcher agent.
@@ -148,6 +162,18 @@ func (r *Researcher) discover() {
 		Wallets:   wallets,
 	}
 
+	// Collapse sybils and copy-bots to one wallet per operator
+	if r.Activity != nil {
+		result := r.clusterWallets(wallets)
+		wl.Wallets = Dedupe(wallets, result)
+		wl.Clusters = result.Clusters
+		wl.CopyBots = result.CopyBots
+		if dropped := len(wallets) - len(wl.Wallets); dropped > 0 {
+			fmt.Printf("🔗 Researcher: %d clusters, dropped %d duplicate wallets (%d copy-bots)\n",
+				len(result.Clusters), dropped, len(result.CopyBots))
+		}
+	}
+
 	// Save to disk
 	if err := SaveWatchlist(wl); err != nil {
 		fmt.Printf("⚠️  Failed to save watchlist: %v\n", err)
@@ -161,6 +187,26 @@ func (r *Researcher) discover() {
 	}
 }
 
+// clusterWallets fetches activity for the Solana wallets and clusters
+// them. Wallets whose activity can't be fetched are left unclustered.
+func (r *Researcher) clusterWallets(wallets []WalletEntry) *ClusterResult {
+	scores := make(map[string]int, len(wallets))
+	var activity []WalletActivity
+	for _, w := range wallets {
+		if w.Platform != "solana" {
+			continue
+		}
+		scores[w.Address] = w.Score
+		a, err := r.Activity.WalletActivity(w.Address)
+		if err != nil {
+			fmt.Printf("⚠️  Researcher: activity for %s: %v\n", w.Alias, err)
+			continue
+		}
+		activity = append(activity, *a)
+	}
+	return ClusterWallets(activity, scores, r.Cluster)
+}
+
 // getKnownProfitableWallets returns curated list of known profitable wallets.
 // In production, this would query Dune Analytics or Nansen APIs.
 func (r *Researcher) getKnownProfitableWallets() []WalletEntry {
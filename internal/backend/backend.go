@@ -0,0 +1,120 @@
+// Package backend selects where agent sessions run.
+//
+// Every role starts, stops, nudges and inspects its agent through a
+// SessionBackend. tmux is the default; the built-in pty supervisor runs
+// agents headless, for CI runners and servers without tmux.
+package backend
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/pty"
+	"github.com/speaker20/whaletown/internal/tmux"
+)
+
+// Session backend names, as used in settings/config.json and
+// WT_SESSION_BACKEND.
+const (
+	Tmux = "tmux"
+	PTY  = "pty"
+)
+
+// EnvSessionBackend overrides the town's session_backend setting.
+const EnvSessionBackend = "WT_SESSION_BACKEND"
+
+// SessionBackend runs agent sessions: start and stop them, inject input,
+// capture output, check liveness, and keep per-session environment.
+//
+// The method set is that of *tmux.Tmux, which implements it directly.
+// Session names are the same across backends (see the session package).
+type SessionBackend interface {
+	// Lifecycle
+	NewSession(name, workDir string) error
+	NewSessionWithCommand(name, workDir, command string) error
+	EnsureSessionFresh(name, workDir string) error
+	KillSession(name string) error
+	KillSessionWithProcesses(name string) error
+	HasSession(name string) (bool, error)
+	ListSessions() ([]string, error)
+	IsAvailable() bool
+
+	// Input
+	SendKeys(session, keys string) error
+	SendKeysDebounced(session, keys string, debounceMs int) error
+	SendKeysRaw(session, keys string) error
+	NudgeSession(session, message string) error
+	AcceptBypassPermissionsWarning(session string) error
+
+	// Output
+	CapturePane(session string, lines int) (string, error)
+	CapturePaneLines(session string, lines int) ([]string, error)
+	AttachSession(session string) error
+
+	// Liveness
+	GetPaneCommand(session string) (string, error)
+	IsAgentRunning(session string, expectedPaneCommands ...string) bool
+	IsClaudeRunning(session string) bool
+	IsRuntimeRunning(session string, processNames []string) bool
+	WaitForCommand(session string, excludeCommands []string, timeout time.Duration) error
+	WaitForRuntimeReady(session string, rc *config.RuntimeConfig, timeout time.Duration) error
+	GetSessionInfo(name string) (*tmux.SessionInfo, error)
+
+	// Environment
+	SetEnvironment(session, key, value string) error
+	GetEnvironment(session, key string) (string, error)
+
+	// Presentation; backends without a status bar ignore these
+	ConfigureWhaleTownSession(session string, theme tmux.Theme, rig, worker, role string) error
+	SetPaneDiedHook(session, agentID string) error
+}
+
+var (
+	_ SessionBackend = (*tmux.Tmux)(nil)
+	_ SessionBackend = (*pty.Client)(nil)
+)
+
+// Name returns the session backend configured for a town: the
+// WT_SESSION_BACKEND environment variable, else session_backend in
+// settings/config.json, else tmux.
+func Name(townRoot string) string {
+	if name := strings.TrimSpace(os.Getenv(EnvSessionBackend)); name != "" {
+		return name
+	}
+	if townRoot != "" {
+		if settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); err == nil && settings.SessionBackend != "" {
+			return settings.SessionBackend
+		}
+	}
+	return Tmux
+}
+
+// Validate checks a session backend name.
+func Validate(name string) error {
+	switch name {
+	case Tmux, PTY:
+		return nil
+	}
+	return fmt.Errorf("unknown session backend %q (want %s or %s)", name, Tmux, PTY)
+}
+
+// New returns the session backend configured for a town. Unknown names
+// fall back to tmux.
+func New(townRoot string) SessionBackend {
+	return Resolve(townRoot, nil)
+}
+
+// Resolve is like New, but uses t when the town runs on tmux. This keeps
+// a remote tmux (e.g. an SSH connection's) in effect.
+func Resolve(townRoot string, t *tmux.Tmux) SessionBackend {
+	if Name(townRoot) == PTY {
+		return pty.NewClient(townRoot)
+	}
+	if t == nil {
+		t = tmux.NewTmux()
+	}
+	return t
+}
@@ -0,0 +1,45 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/pty"
+	"github.com/speaker20/whaletown/internal/tmux"
+)
+
+func TestName(t *testing.T) {
+	t.Setenv(EnvSessionBackend, "")
+	townRoot := t.TempDir()
+	if got := Name(townRoot); got != Tmux {
+		t.Errorf("default = %q, want tmux", got)
+	}
+
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{"type":"town-settings","version":1,"session_backend":"pty"}`
+	if err := os.WriteFile(filepath.Join(townRoot, "settings", "config.json"), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Name(townRoot); got != PTY {
+		t.Errorf("from settings = %q, want pty", got)
+	}
+	if _, ok := New(townRoot).(*pty.Client); !ok {
+		t.Error("New did not return the pty backend")
+	}
+
+	t.Setenv(EnvSessionBackend, "tmux")
+	if got := Name(townRoot); got != Tmux {
+		t.Errorf("with env override = %q, want tmux", got)
+	}
+	remote := tmux.NewTmux()
+	if Resolve(townRoot, remote) != SessionBackend(remote) {
+		t.Error("Resolve did not keep the given tmux")
+	}
+
+	if err := Validate("screen"); err == nil {
+		t.Error("unknown backend accepted")
+	}
+}
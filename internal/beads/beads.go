@@ -41,6 +41,8 @@ type Issue struct {
 	Blocks      []string `json:"blocks,omitempty"`
 	BlockedBy   []string `json:"blocked_by,omitempty"`
 	Labels      []string `json:"labels,omitempty"`
+	Pinned      bool     `json:"pinned,omitempty"`
+	Wisp        bool     `json:"wisp,omitempty"` // Ephemeral: not exported to JSONL
 
 	// Agent bead slots (type=agent only)
 	HookBead   string `json:"hook_bead,omitempty"`   // Current work attached to agent's hook
@@ -301,13 +301,13 @@ func (b *Beads) UpdateAgentState(id string, state string, hookBead *string) erro
 func (b *Beads) SetHookBead(agentBeadID, hookBeadID string) error {
 	// Set the hook using bd slot set
 	// This updates the hook_bead column directly in SQLite
-	_, err := b.run("slot", "set", agentBeadID, "hook", hookBeadID)
+	err := b.SetSlot(agentBeadID, SlotHook, hookBeadID)
 	if err != nil {
 		// If slot is already occupied, clear it first then retry
 		errStr := err.Error()
 		if strings.Contains(errStr, "already occupied") {
-			_, _ = b.run("slot", "clear", agentBeadID, "hook")
-			_, err = b.run("slot", "set", agentBeadID, "hook", hookBeadID)
+			_ = b.ClearSlot(agentBeadID, SlotHook)
+			err = b.SetSlot(agentBeadID, SlotHook, hookBeadID)
 		}
 		if err != nil {
 			return fmt.Errorf("setting hook: %w", err)
@@ -319,8 +319,7 @@ func (b *Beads) SetHookBead(agentBeadID, hookBeadID string) error {
 // ClearHookBead clears the hook_bead slot on an agent bead.
 // Used when work is complete or unslung.
 func (b *Beads) ClearHookBead(agentBeadID string) error {
-	_, err := b.run("slot", "clear", agentBeadID, "hook")
-	if err != nil {
+	if err := b.ClearSlot(agentBeadID, SlotHook); err != nil {
 		return fmt.Errorf("clearing hook: %w", err)
 	}
 	return nil
@@ -495,20 +494,5 @@ func (b *Beads) GetAgentBead(id string) (*Issue, *AgentFields, error) {
 // ListAgentBeads returns all agent beads in a single query.
 // Returns a map of agent bead ID to Issue.
 func (b *Beads) ListAgentBeads() (map[string]*Issue, error) {
-	out, err := b.run("list", "--label=gt:agent", "--json")
-	if err != nil {
-		return nil, err
-	}
-
-	var issues []*Issue
-	if err := json.Unmarshal(out, &issues); err != nil {
-		return nil, fmt.Errorf("parsing bd list output: %w", err)
-	}
-
-	result := make(map[string]*Issue, len(issues))
-	for _, issue := range issues {
-		result[issue.ID] = issue
-	}
-
-	return result, nil
+	return ListAgentBeadsIn(b)
 }
@@ -0,0 +1,194 @@
+// Package beads provides convoy bead fields and lifecycle helpers.
+package beads
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Convoy lifecycle states. Open and closed are bead statuses; abandoned
+// is a closed convoy that was given up on rather than completed.
+const (
+	ConvoyOpen      = "open"
+	ConvoyClosed    = "closed"
+	ConvoyAbandoned = "abandoned"
+)
+
+// ConvoyFields holds structured fields for convoy beads.
+// These are stored as "Key: value" lines after the summary line of the
+// description, e.g.:
+//
+//	Convoy tracking 3 issues
+//	Owner: mayor/
+//	Due: 2026-01-15T23:59:59Z
+type ConvoyFields struct {
+	Owner             string    // Who requested the convoy (gets completion notification)
+	Notify            string    // Additional subscriber
+	Molecule          string    // Associated molecule ID
+	DueAt             time.Time // Deadline (zero if none)
+	AbandonedAt       time.Time // When abandoned (zero if not abandoned)
+	AbandonReason     string    // Why it was abandoned
+	OverdueEscalation string    // Escalation bead raised when the deadline passed
+}
+
+// convoyFieldKeys lists the description keys ConvoyFields owns, in the
+// order they are written.
+var convoyFieldKeys = []string{"Owner", "Notify", "Molecule", "Due", "Abandoned", "Abandon-Reason", "Overdue-Escalation"}
+
+// ParseConvoyFields extracts convoy fields from a description.
+// Unknown lines and malformed timestamps are ignored.
+func ParseConvoyFields(description string) *ConvoyFields {
+	fields := &ConvoyFields{}
+	for _, line := range strings.Split(description, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Owner":
+			fields.Owner = value
+		case "Notify":
+			fields.Notify = value
+		case "Molecule":
+			fields.Molecule = value
+		case "Due":
+			fields.DueAt, _ = time.Parse(time.RFC3339, value)
+		case "Abandoned":
+			fields.AbandonedAt, _ = time.Parse(time.RFC3339, value)
+		case "Abandon-Reason":
+			fields.AbandonReason = value
+		case "Overdue-Escalation":
+			fields.OverdueEscalation = value
+		}
+	}
+	return fields
+}
+
+// SetConvoyFields rewrites the convoy field lines of a description,
+// keeping every other line. Empty fields are removed.
+func SetConvoyFields(description string, fields *ConvoyFields) string {
+	owned := make(map[string]bool, len(convoyFieldKeys))
+	for _, k := range convoyFieldKeys {
+		owned[k] = true
+	}
+
+	var lines []string
+	for _, line := range strings.Split(description, "\n") {
+		if key, _, ok := strings.Cut(line, ": "); ok && owned[key] {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, key+": "+strings.NewReplacer("\r", " ", "\n", " ").Replace(value))
+		}
+	}
+	add("Owner", fields.Owner)
+	add("Notify", fields.Notify)
+	add("Molecule", fields.Molecule)
+	add("Due", formatConvoyTime(fields.DueAt))
+	add("Abandoned", formatConvoyTime(fields.AbandonedAt))
+	add("Abandon-Reason", fields.AbandonReason)
+	add("Overdue-Escalation", fields.OverdueEscalation)
+	return strings.Join(lines, "\n")
+}
+
+func formatConvoyTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ConvoyState returns the lifecycle state of a convoy with the given bead
+// status: abandoned for a closed convoy with an abandon record, otherwise
+// the status itself.
+func (f *ConvoyFields) ConvoyState(status string) string {
+	if status == ConvoyClosed && !f.AbandonedAt.IsZero() {
+		return ConvoyAbandoned
+	}
+	return status
+}
+
+// IsOverdue reports whether a convoy with the given bead status has passed
+// its deadline. Closed convoys are never overdue.
+func (f *ConvoyFields) IsOverdue(status string, now time.Time) bool {
+	return status != ConvoyClosed && !f.DueAt.IsZero() && now.After(f.DueAt)
+}
+
+// ParseConvoyDue parses a convoy deadline. It accepts a date (the deadline
+// is the end of that day, local time), an RFC 3339 timestamp, or a
+// duration from now with d and w suffixes allowed ("36h", "3d", "2w").
+func ParseConvoyDue(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("invalid due date %q", s)
+		}
+		return now.Add(time.Duration(n) * unit), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid due date %q (use YYYY-MM-DD, RFC 3339, or a duration like 3d)", s)
+}
+
+// BurnDownPoint is one step of a convoy's burn-down: the number of tracked
+// issues completed and remaining as of a moment.
+type BurnDownPoint struct {
+	At        time.Time `json:"at"`
+	Completed int       `json:"completed"`
+	Remaining int       `json:"remaining"`
+}
+
+// ConvoyBurnDown builds a convoy's burn-down from its creation time, the
+// number of issues it tracks and the close times of the closed ones.
+// There is one point at creation and one per distinct close time. Issues
+// closed before the convoy existed, or with no known close time, count
+// as completed at creation.
+func ConvoyBurnDown(createdAt time.Time, total int, closedAt []time.Time) []BurnDownPoint {
+	times := make([]time.Time, len(closedAt))
+	for i, t := range closedAt {
+		if t.IsZero() || t.Before(createdAt) {
+			t = createdAt
+		}
+		times[i] = t
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	points := []BurnDownPoint{{At: createdAt, Remaining: total}}
+	for _, t := range times {
+		last := &points[len(points)-1]
+		if !t.Equal(last.At) {
+			points = append(points, BurnDownPoint{At: t, Completed: last.Completed, Remaining: last.Remaining})
+			last = &points[len(points)-1]
+		}
+		last.Completed++
+		last.Remaining--
+	}
+	return points
+}
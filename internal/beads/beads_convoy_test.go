@@ -0,0 +1,121 @@
+package beads
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvoyFieldsRoundTrip(t *testing.T) {
+	due := time.Date(2026, 1, 15, 23, 59, 59, 0, time.UTC)
+	desc := "Convoy tracking 2 issues\nOwner: mayor/\nNotify: ops/\nContext: release train"
+
+	fields := ParseConvoyFields(desc)
+	if fields.Owner != "mayor/" || fields.Notify != "ops/" || !fields.DueAt.IsZero() {
+		t.Fatalf("parsed %+v", fields)
+	}
+
+	fields.DueAt = due
+	fields.AbandonedAt = due.Add(time.Hour)
+	fields.AbandonReason = "superseded\nby v3"
+	desc = SetConvoyFields(desc, fields)
+
+	if !strings.HasPrefix(desc, "Convoy tracking 2 issues\nContext: release train\n") {
+		t.Errorf("other lines not kept:\n%s", desc)
+	}
+	if strings.Count(desc, "Owner: ") != 1 {
+		t.Errorf("owner duplicated:\n%s", desc)
+	}
+
+	got := ParseConvoyFields(desc)
+	if !got.DueAt.Equal(due) || !got.AbandonedAt.Equal(due.Add(time.Hour)) || got.AbandonReason != "superseded by v3" {
+		t.Errorf("round trip = %+v", got)
+	}
+
+	// Clearing a field removes its line
+	got.AbandonedAt, got.AbandonReason = time.Time{}, ""
+	if desc = SetConvoyFields(desc, got); strings.Contains(desc, "Abandon") {
+		t.Errorf("abandon lines not removed:\n%s", desc)
+	}
+}
+
+func TestConvoyStateAndOverdue(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	f := &ConvoyFields{DueAt: now.Add(-time.Hour)}
+
+	if !f.IsOverdue(ConvoyOpen, now) {
+		t.Error("open convoy past its deadline should be overdue")
+	}
+	if f.IsOverdue(ConvoyClosed, now) {
+		t.Error("closed convoy should not be overdue")
+	}
+	if (&ConvoyFields{}).IsOverdue(ConvoyOpen, now) {
+		t.Error("convoy without a deadline should not be overdue")
+	}
+
+	if s := f.ConvoyState(ConvoyClosed); s != ConvoyClosed {
+		t.Errorf("state = %s, want closed", s)
+	}
+	f.AbandonedAt = now
+	if s := f.ConvoyState(ConvoyClosed); s != ConvoyAbandoned {
+		t.Errorf("state = %s, want abandoned", s)
+	}
+	if s := f.ConvoyState(ConvoyOpen); s != ConvoyOpen {
+		t.Errorf("state = %s, want open", s)
+	}
+}
+
+func TestParseConvoyDue(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2026-01-15", want: time.Date(2026, 1, 15, 23, 59, 59, 0, time.UTC)},
+		{in: "2026-01-15T09:00:00Z", want: time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{in: "3d", want: now.Add(72 * time.Hour)},
+		{in: "2w", want: now.Add(14 * 24 * time.Hour)},
+		{in: "36h", want: now.Add(36 * time.Hour)},
+		{in: "0d", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "soon", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseConvoyDue(tt.in, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseConvoyDue(%q) should fail, got %v", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseConvoyDue(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestConvoyBurnDown(t *testing.T) {
+	created := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return created.Add(time.Duration(n) * 24 * time.Hour) }
+
+	points := ConvoyBurnDown(created, 4, []time.Time{day(3), {}, day(1), day(3)})
+	want := []BurnDownPoint{
+		{At: created, Completed: 1, Remaining: 3},
+		{At: day(1), Completed: 2, Remaining: 2},
+		{At: day(3), Completed: 4, Remaining: 0},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("points = %+v", points)
+	}
+	for i := range want {
+		if !points[i].At.Equal(want[i].At) || points[i].Completed != want[i].Completed || points[i].Remaining != want[i].Remaining {
+			t.Errorf("point %d = %+v, want %+v", i, points[i], want[i])
+		}
+	}
+
+	if points := ConvoyBurnDown(created, 2, nil); len(points) != 1 || points[0].Remaining != 2 {
+		t.Errorf("no closes = %+v", points)
+	}
+}
@@ -27,6 +27,51 @@ type EscalationFields struct {
 	ReescalationCount  int    // Number of times this has been re-escalated
 	LastReescalatedAt  string // When last re-escalated (empty if never)
 	LastReescalatedBy  string // Who last re-escalated (empty if never)
+	Deliveries         []EscalationDelivery // External notification outcomes, oldest first
+}
+
+// EscalationDelivery records the outcome of one external notification
+// action (email, sms, slack, webhook, log). Stored as a description line:
+//
+//	delivery: email:human sent attempts=1 at=2026-01-02T15:04:05Z
+//	delivery: slack failed attempts=3 at=2026-01-02T15:04:09Z error=503 Service Unavailable
+type EscalationDelivery struct {
+	Action   string // Route action, e.g. "email:human"
+	Status   string // sent, failed, skipped
+	Attempts int
+	At       string // ISO 8601 timestamp
+	Error    string // Last error (empty if sent)
+}
+
+// String formats the delivery as stored in the description.
+func (d EscalationDelivery) String() string {
+	s := fmt.Sprintf("%s %s attempts=%d at=%s", d.Action, d.Status, d.Attempts, d.At)
+	if d.Error != "" {
+		s += " error=" + strings.NewReplacer("\r", " ", "\n", " ").Replace(d.Error)
+	}
+	return s
+}
+
+// parseEscalationDelivery parses a delivery line value.
+func parseEscalationDelivery(value string) (EscalationDelivery, bool) {
+	var d EscalationDelivery
+	rest, errMsg, _ := strings.Cut(value, " error=")
+	d.Error = errMsg
+	parts := strings.Fields(rest)
+	if len(parts) < 2 {
+		return d, false
+	}
+	d.Action, d.Status = parts[0], parts[1]
+	for _, p := range parts[2:] {
+		k, v, _ := strings.Cut(p, "=")
+		switch k {
+		case "attempts":
+			d.Attempts, _ = strconv.Atoi(v)
+		case "at":
+			d.At = v
+		}
+	}
+	return d, true
 }
 
 // EscalationState constants for bead status tracking.
@@ -103,6 +148,10 @@ func FormatEscalationDescription(title string, fields *EscalationFields) string
 		lines = append(lines, "last_reescalated_by: null")
 	}
 
+	for _, d := range fields.Deliveries {
+		lines = append(lines, "delivery: "+d.String())
+	}
+
 	return strings.Join(lines, "\n")
 }
 
@@ -158,6 +207,10 @@ func ParseEscalationFields(description string) *EscalationFields {
 			fields.LastReescalatedAt = value
 		case "last_reescalated_by":
 			fields.LastReescalatedBy = value
+		case "delivery":
+			if d, ok := parseEscalationDelivery(value); ok {
+				fields.Deliveries = append(fields.Deliveries, d)
+			}
 		}
 	}
 
@@ -263,6 +316,36 @@ func (b *Beads) CloseEscalation(id, closedBy, reason string) error {
 	return err
 }
 
+// RecordEscalationDeliveries appends external notification outcomes to an
+// escalation bead. A failed delivery adds the "delivery:failed" label so
+// undelivered escalations can be found with bd list.
+func (b *Beads) RecordEscalationDeliveries(id string, deliveries []EscalationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	issue, err := b.Show(id)
+	if err != nil {
+		return err
+	}
+	if !HasLabel(issue, "gt:escalation") {
+		return fmt.Errorf("issue %s is not an escalation bead (missing gt:escalation label)", id)
+	}
+
+	fields := ParseEscalationFields(issue.Description)
+	fields.Deliveries = append(fields.Deliveries, deliveries...)
+	description := FormatEscalationDescription(issue.Title, fields)
+
+	opts := UpdateOptions{Description: &description}
+	for _, d := range deliveries {
+		if d.Status == "failed" {
+			opts.AddLabels = []string{"delivery:failed"}
+			break
+		}
+	}
+	return b.Update(id, opts)
+}
+
 // GetEscalationBead retrieves an escalation bead by ID.
 // Returns nil if not found.
 func (b *Beads) GetEscalationBead(id string) (*Issue, *EscalationFields, error) {
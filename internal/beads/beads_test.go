@@ -561,13 +561,15 @@ author: someone`,
 // TestMRFieldsRoundTrip tests that parse/format round-trips correctly.
 func TestMRFieldsRoundTrip(t *testing.T) {
 	original := &MRFields{
-		Branch:      "polecat/Nux/gt-xyz",
-		Target:      "main",
-		SourceIssue: "wt-xyz",
-		Worker:      "Nux",
-		Rig:         "whaletown",
-		MergeCommit: "abc123def789",
-		CloseReason: "merged",
+		Branch:       "polecat/Nux/gt-xyz",
+		Target:       "main",
+		SourceIssue:  "wt-xyz",
+		Worker:       "Nux",
+		Rig:          "whaletown",
+		MergeCommit:  "abc123def789",
+		CloseReason:  "merged",
+		TestArtifact: "/rig/.runtime/refinery/test-output/wt-mr1.log",
+		FailedTests:  "pkg.TestA, pkg.TestB",
 	}
 
 	// Format to string
@@ -187,6 +187,10 @@ type MRFields struct {
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
+
+	// Test failure details from the last failed merge attempt
+	TestArtifact string // Path to the captured test output
+	FailedTests  string // Comma-separated names of the failed tests
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -261,6 +265,12 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "convoy_created_at", "convoy-created-at", "convoycreatedat":
 			fields.ConvoyCreatedAt = value
 			hasFields = true
+		case "test_artifact", "test-artifact", "testartifact":
+			fields.TestArtifact = value
+			hasFields = true
+		case "failed_tests", "failed-tests", "failedtests":
+			fields.FailedTests = value
+			hasFields = true
 		}
 	}
 
@@ -325,6 +335,12 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConvoyCreatedAt != "" {
 		lines = append(lines, "convoy_created_at: "+fields.ConvoyCreatedAt)
 	}
+	if fields.TestArtifact != "" {
+		lines = append(lines, "test_artifact: "+fields.TestArtifact)
+	}
+	if fields.FailedTests != "" {
+		lines = append(lines, "failed_tests: "+fields.FailedTests)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -371,6 +387,12 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"convoy_created_at":  true,
 		"convoy-created-at":  true,
 		"convoycreatedat":    true,
+		"test_artifact":      true,
+		"test-artifact":      true,
+		"testartifact":       true,
+		"failed_tests":       true,
+		"failed-tests":       true,
+		"failedtests":        true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -0,0 +1,177 @@
+package beads
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/speaker20/whaletown/internal/beadsdb"
+)
+
+// Store is the beads storage Whale Town reads and writes through: issues,
+// labels, dependencies, agent slots and messages.
+//
+// Three implementations share it:
+//   - *Beads runs the bd CLI for every call.
+//   - *LocalStore reads the beads database directly and writes through bd.
+//   - *MemStore keeps everything in memory, so tests don't need bd.
+type Store interface {
+	// Issues
+	List(opts ListOptions) ([]*Issue, error)
+	Show(id string) (*Issue, error)
+	ShowMultiple(ids []string) (map[string]*Issue, error)
+	Create(opts CreateOptions) (*Issue, error)
+	CreateWithID(id string, opts CreateOptions) (*Issue, error)
+	Update(id string, opts UpdateOptions) error
+	Close(ids ...string) error
+	CloseWithReason(reason string, ids ...string) error
+
+	// Labels
+	AddLabel(id, label string) error
+	RemoveLabel(id, label string) error
+
+	// Dependencies (issue depends on dependsOn)
+	AddDependency(issue, dependsOn string) error
+	RemoveDependency(issue, dependsOn string) error
+
+	// Agent slots ("hook", "role")
+	SetSlot(id, slot, value string) error
+	ClearSlot(id, slot string) error
+
+	// Messages (issues of type "message")
+	ListMessages(opts MessageListOptions) ([]*Issue, error)
+}
+
+var (
+	_ Store = (*Beads)(nil)
+	_ Store = (*LocalStore)(nil)
+	_ Store = (*MemStore)(nil)
+)
+
+// MessageListOptions specifies filters for listing messages.
+type MessageListOptions struct {
+	Assignee string // recipient identity
+	Label    string // e.g. "cc:whaletown/witness"
+	Status   string // "open", "hooked", "closed", "all"; empty: not closed
+}
+
+// Agent slot names.
+const (
+	SlotHook = "hook"
+	SlotRole = "role"
+)
+
+// StoreEnv selects the Store returned by OpenStore: "cli" always runs bd,
+// anything else reads the database directly when it can.
+const StoreEnv = "WT_BEADS_STORE"
+
+// OpenStore returns the Store for the beads database of workDir: a
+// LocalStore when the database can be read directly, else the bd CLI.
+func OpenStore(workDir string) Store {
+	if s := LocalStoreFor(workDir, ""); s != nil {
+		return s
+	}
+	return New(workDir)
+}
+
+// localStores shares one LocalStore, and so one snapshot, per database
+// within a process.
+var (
+	localStoresMu sync.Mutex
+	localStores   = make(map[string]*LocalStore)
+)
+
+// LocalStoreFor returns the LocalStore for the database in beadsDir
+// (resolved from workDir when empty), or nil when it can't be read
+// directly: sqlite3 is missing, there is no beads.db, or StoreEnv asks for
+// the CLI. Calls for the same database share a store.
+func LocalStoreFor(workDir, beadsDir string) *LocalStore {
+	if os.Getenv(StoreEnv) == "cli" {
+		return nil
+	}
+	if !beadsdb.Available() {
+		return nil
+	}
+	if beadsDir == "" {
+		beadsDir = ResolveBeadsDir(workDir)
+	}
+	if _, err := os.Stat(filepath.Join(beadsDir, "beads.db")); err != nil {
+		return nil
+	}
+
+	localStoresMu.Lock()
+	defer localStoresMu.Unlock()
+	if s, ok := localStores[beadsDir]; ok {
+		return s
+	}
+	s := NewLocalStore(workDir, beadsDir)
+	localStores[beadsDir] = s
+	return s
+}
+
+// AddLabel adds a label to an issue.
+func (b *Beads) AddLabel(id, label string) error {
+	_, err := b.run("label", "add", id, label)
+	return err
+}
+
+// RemoveLabel removes a label from an issue.
+func (b *Beads) RemoveLabel(id, label string) error {
+	_, err := b.run("label", "remove", id, label)
+	return err
+}
+
+// SetSlot sets an agent bead's slot (e.g. SlotHook) to value.
+func (b *Beads) SetSlot(id, slot, value string) error {
+	_, err := b.run("slot", "set", id, slot, value)
+	return err
+}
+
+// ClearSlot empties an agent bead's slot.
+func (b *Beads) ClearSlot(id, slot string) error {
+	_, err := b.run("slot", "clear", id, slot)
+	return err
+}
+
+// ListMessages returns messages matching the given options.
+func (b *Beads) ListMessages(opts MessageListOptions) ([]*Issue, error) {
+	args := []string{"list", "--type", "message", "--json"}
+	if opts.Assignee != "" {
+		args = append(args, "--assignee", opts.Assignee)
+	}
+	if opts.Label != "" {
+		args = append(args, "--label", opts.Label)
+	}
+	if opts.Status != "" {
+		args = append(args, "--status", opts.Status)
+	}
+
+	out, err := b.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var issues []*Issue
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return nil, fmt.Errorf("parsing bd list output: %w", err)
+	}
+	return issues, nil
+}
+
+// ListAgentBeadsIn returns all open agent beads in s, keyed by ID.
+func ListAgentBeadsIn(s Store) (map[string]*Issue, error) {
+	issues, err := s.List(ListOptions{Label: "gt:agent", Priority: -1})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*Issue, len(issues))
+	for _, issue := range issues {
+		result[issue.ID] = issue
+	}
+	return result, nil
+}
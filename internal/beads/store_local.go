@@ -0,0 +1,395 @@
+package beads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/beadsdb"
+)
+
+// snapshotQuery reads every issue with its labels and outgoing
+// dependencies in one pass. issues.* keeps it working across bd schema
+// versions; columns Whale Town doesn't know are ignored.
+const snapshotQuery = `SELECT i.*,
+  (SELECT json_group_array(label) FROM labels WHERE issue_id = i.id) AS wt_labels,
+  (SELECT json_group_array(json_object('id', depends_on_id, 'type', type))
+     FROM dependencies WHERE issue_id = i.id) AS wt_deps
+FROM issues i`
+
+// LocalStore is a Store that reads the beads database directly instead of
+// running bd for every call.
+//
+// The first read loads the whole database with one sqlite3 query into a
+// snapshot; later reads are served from it until the database files change
+// on disk, when the next read reloads. IDs that aren't in the snapshot
+// (other rigs' prefixes, routed by bd) go to bd, as do all reads for a
+// while after the query fails (an unknown schema, a locked database).
+//
+// Writes always go through bd, so bd's validation, JSONL export and
+// daemon see them. By default a write drops the snapshot; with
+// SetWriteThrough the write is applied to the snapshot as well, so a
+// process that writes and then reads pays for one query, not two.
+type LocalStore struct {
+	cli    *Beads
+	dbPath string
+
+	mu           sync.Mutex
+	writeThrough bool
+	snap         *MemStore
+	stamp        dbStamp
+	broken       error     // last snapshot query failed: serve reads through bd
+	brokenAt     time.Time // until retryBroken has passed
+}
+
+// retryBroken is how long LocalStore serves reads through bd after the
+// snapshot query fails, before trying the database again.
+const retryBroken = time.Minute
+
+// dbStamp identifies a state of the database files on disk.
+type dbStamp struct {
+	db, wal fileStamp
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// NewLocalStore creates a LocalStore for the database in beadsDir, which is
+// resolved from workDir when empty.
+func NewLocalStore(workDir, beadsDir string) *LocalStore {
+	cli := New(workDir)
+	if beadsDir == "" {
+		beadsDir = ResolveBeadsDir(workDir)
+	} else {
+		cli = NewWithBeadsDir(workDir, beadsDir)
+	}
+	return &LocalStore{
+		cli:    cli,
+		dbPath: filepath.Join(beadsDir, "beads.db"),
+	}
+}
+
+// SetWriteThrough sets whether writes update the snapshot in place rather
+// than dropping it.
+func (s *LocalStore) SetWriteThrough(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeThrough = on
+}
+
+// CLI returns the bd-backed store LocalStore writes and falls back through.
+func (s *LocalStore) CLI() *Beads {
+	return s.cli
+}
+
+// Load reads the database into the snapshot if it isn't current, and
+// reports why when the database can't be read directly.
+func (s *LocalStore) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.snapshot()
+	return err
+}
+
+// snapshot returns the current snapshot, reloading it if the database has
+// changed. Callers hold s.mu.
+func (s *LocalStore) snapshot() (*MemStore, error) {
+	if s.broken != nil && time.Since(s.brokenAt) < retryBroken {
+		return nil, s.broken
+	}
+	s.broken = nil
+	stamp := s.statDB()
+	if s.snap != nil && stamp == s.stamp {
+		return s.snap, nil
+	}
+
+	snap, err := s.load()
+	if err != nil {
+		s.snap = nil
+		s.broken, s.brokenAt = err, time.Now()
+		return nil, err
+	}
+	s.snap, s.stamp = snap, stamp
+	return snap, nil
+}
+
+func (s *LocalStore) statDB() dbStamp {
+	return dbStamp{db: statFile(s.dbPath), wal: statFile(s.dbPath + "-wal")}
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}
+}
+
+// dbIssueRow is a row of snapshotQuery as printed by sqlite3 -json.
+type dbIssueRow struct {
+	ID          string      `json:"id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Status      string      `json:"status"`
+	Priority    int         `json:"priority"`
+	IssueType   string      `json:"issue_type"`
+	Assignee    string      `json:"assignee"`
+	CreatedAt   string      `json:"created_at"`
+	CreatedBy   string      `json:"created_by"`
+	UpdatedAt   string      `json:"updated_at"`
+	ClosedAt    string      `json:"closed_at"`
+	DeletedAt   string      `json:"deleted_at"`
+	HookBead    string      `json:"hook_bead"`
+	RoleBead    string      `json:"role_bead"`
+	AgentState  string      `json:"agent_state"`
+	Pinned      interface{} `json:"pinned"`
+	Ephemeral   interface{} `json:"ephemeral"`
+	Wisp        interface{} `json:"wisp"`
+	Labels      string      `json:"wt_labels"`
+	Deps        string      `json:"wt_deps"`
+}
+
+// load reads the whole database into a new MemStore.
+func (s *LocalStore) load() (*MemStore, error) {
+	var rows []dbIssueRow
+	if err := beadsdb.OpenFile(s.dbPath).Query(context.Background(), &rows, snapshotQuery, nil); err != nil {
+		return nil, err
+	}
+
+	snap := NewMemStore("")
+	for _, row := range rows {
+		if row.DeletedAt != "" || row.Status == "tombstone" {
+			continue
+		}
+		issue := &Issue{
+			ID:          row.ID,
+			Title:       row.Title,
+			Description: row.Description,
+			Status:      row.Status,
+			Priority:    row.Priority,
+			Type:        row.IssueType,
+			CreatedAt:   normalizeTime(row.CreatedAt),
+			CreatedBy:   row.CreatedBy,
+			UpdatedAt:   normalizeTime(row.UpdatedAt),
+			ClosedAt:    normalizeTime(row.ClosedAt),
+			Assignee:    row.Assignee,
+			HookBead:    row.HookBead,
+			RoleBead:    row.RoleBead,
+			AgentState:  row.AgentState,
+			Pinned:      truthy(row.Pinned),
+			Wisp:        truthy(row.Ephemeral) || truthy(row.Wisp),
+		}
+		if row.Labels != "" {
+			if err := json.Unmarshal([]byte(row.Labels), &issue.Labels); err != nil {
+				return nil, fmt.Errorf("parsing labels of %s: %w", row.ID, err)
+			}
+		}
+		snap.put(issue)
+
+		var deps []struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		}
+		if row.Deps != "" {
+			if err := json.Unmarshal([]byte(row.Deps), &deps); err != nil {
+				return nil, fmt.Errorf("parsing dependencies of %s: %w", row.ID, err)
+			}
+		}
+		for _, d := range deps {
+			snap.addDep(row.ID, d.ID, d.Type)
+		}
+	}
+	return snap, nil
+}
+
+// truthy reads a boolean column, stored by SQLite as an integer.
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v == "1" || v == "true"
+	}
+	return false
+}
+
+// normalizeTime converts a stored timestamp to RFC 3339, as bd prints it.
+// Unrecognized values are returned unchanged.
+func normalizeTime(v string) string {
+	if v == "" {
+		return ""
+	}
+	if t := beadsdb.ParseTime(v); !t.IsZero() {
+		return t.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+// read runs fn against the snapshot, or reports false when there is none.
+func (s *LocalStore) read(fn func(*MemStore)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap, err := s.snapshot()
+	if err != nil {
+		return false
+	}
+	fn(snap)
+	return true
+}
+
+// List returns issues matching the given options. An empty Status matches
+// every issue that isn't closed.
+func (s *LocalStore) List(opts ListOptions) ([]*Issue, error) {
+	var issues []*Issue
+	var err error
+	if s.read(func(m *MemStore) { issues, err = m.List(opts) }) {
+		return issues, err
+	}
+	return s.cli.List(opts)
+}
+
+// ListMessages returns messages matching the given options.
+func (s *LocalStore) ListMessages(opts MessageListOptions) ([]*Issue, error) {
+	var issues []*Issue
+	var err error
+	if s.read(func(m *MemStore) { issues, err = m.ListMessages(opts) }) {
+		return issues, err
+	}
+	return s.cli.ListMessages(opts)
+}
+
+// Show returns an issue. IDs not in this database are looked up with bd,
+// which follows prefix routes to other databases.
+func (s *LocalStore) Show(id string) (*Issue, error) {
+	var issue *Issue
+	var err error
+	if s.read(func(m *MemStore) { issue, err = m.Show(id) }) && !errors.Is(err, ErrNotFound) {
+		return issue, err
+	}
+	return s.cli.Show(id)
+}
+
+// ShowMultiple returns the issues with the given IDs. Missing IDs are not
+// included in the map.
+func (s *LocalStore) ShowMultiple(ids []string) (map[string]*Issue, error) {
+	var result map[string]*Issue
+	if !s.read(func(m *MemStore) { result, _ = m.ShowMultiple(ids) }) {
+		return s.cli.ShowMultiple(ids)
+	}
+	var missing []string
+	for _, id := range ids {
+		if _, ok := result[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		routed, err := s.cli.ShowMultiple(missing)
+		if err != nil {
+			return nil, err
+		}
+		for id, issue := range routed {
+			result[id] = issue
+		}
+	}
+	return result, nil
+}
+
+// write runs a bd write, then applies it to the snapshot with mirror when
+// write-through is on, or drops the snapshot.
+func (s *LocalStore) write(do func() error, mirror func(*MemStore)) error {
+	if err := do(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.writeThrough && s.snap != nil {
+		mirror(s.snap)
+		s.stamp = s.statDB()
+	} else {
+		s.snap = nil
+	}
+	return nil
+}
+
+// Create creates an issue through bd.
+func (s *LocalStore) Create(opts CreateOptions) (*Issue, error) {
+	var issue *Issue
+	err := s.write(func() (err error) {
+		issue, err = s.cli.Create(opts)
+		return err
+	}, func(m *MemStore) { m.put(issue) })
+	return issue, err
+}
+
+// CreateWithID creates an issue with a specific ID through bd.
+func (s *LocalStore) CreateWithID(id string, opts CreateOptions) (*Issue, error) {
+	var issue *Issue
+	err := s.write(func() (err error) {
+		issue, err = s.cli.CreateWithID(id, opts)
+		return err
+	}, func(m *MemStore) { m.put(issue) })
+	return issue, err
+}
+
+// Update updates an existing issue through bd.
+func (s *LocalStore) Update(id string, opts UpdateOptions) error {
+	return s.write(func() error { return s.cli.Update(id, opts) },
+		func(m *MemStore) { _ = m.Update(id, opts) })
+}
+
+// Close closes one or more issues through bd.
+func (s *LocalStore) Close(ids ...string) error {
+	return s.write(func() error { return s.cli.Close(ids...) },
+		func(m *MemStore) { _ = m.Close(ids...) })
+}
+
+// CloseWithReason closes one or more issues with a reason through bd.
+func (s *LocalStore) CloseWithReason(reason string, ids ...string) error {
+	return s.write(func() error { return s.cli.CloseWithReason(reason, ids...) },
+		func(m *MemStore) { _ = m.Close(ids...) })
+}
+
+// AddLabel adds a label to an issue through bd.
+func (s *LocalStore) AddLabel(id, label string) error {
+	return s.write(func() error { return s.cli.AddLabel(id, label) },
+		func(m *MemStore) { _ = m.AddLabel(id, label) })
+}
+
+// RemoveLabel removes a label from an issue through bd.
+func (s *LocalStore) RemoveLabel(id, label string) error {
+	return s.write(func() error { return s.cli.RemoveLabel(id, label) },
+		func(m *MemStore) { _ = m.RemoveLabel(id, label) })
+}
+
+// AddDependency adds a dependency through bd.
+func (s *LocalStore) AddDependency(issue, dependsOn string) error {
+	return s.write(func() error { return s.cli.AddDependency(issue, dependsOn) },
+		func(m *MemStore) { _ = m.AddDependency(issue, dependsOn) })
+}
+
+// RemoveDependency removes a dependency through bd.
+func (s *LocalStore) RemoveDependency(issue, dependsOn string) error {
+	return s.write(func() error { return s.cli.RemoveDependency(issue, dependsOn) },
+		func(m *MemStore) { _ = m.RemoveDependency(issue, dependsOn) })
+}
+
+// SetSlot sets an agent bead's slot through bd.
+func (s *LocalStore) SetSlot(id, slot, value string) error {
+	return s.write(func() error { return s.cli.SetSlot(id, slot, value) },
+		func(m *MemStore) { _ = m.SetSlot(id, slot, value) })
+}
+
+// ClearSlot empties an agent bead's slot through bd.
+func (s *LocalStore) ClearSlot(id, slot string) error {
+	return s.write(func() error { return s.cli.ClearSlot(id, slot) },
+		func(m *MemStore) { _ = m.ClearSlot(id, slot) })
+}
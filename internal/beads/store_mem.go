@@ -0,0 +1,433 @@
+package beads
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Dependency types, as stored by bd.
+const (
+	DepBlocks      = "blocks"
+	DepParentChild = "parent-child"
+	DepTracks      = "tracks"
+)
+
+// MemStore is an in-memory Store. Tests use it in place of bd; LocalStore
+// uses it to hold its snapshot of the database.
+//
+// Issues are kept without derived fields; reads fill in Parent, Children,
+// DependsOn, Blocks, BlockedBy and the dependency lists from the stored
+// dependencies, the way bd reports them.
+type MemStore struct {
+	mu     sync.Mutex
+	prefix string
+	next   int
+	issues map[string]*Issue
+	order  []string // insertion order, for stable listings
+	deps   []memDep
+	now    func() time.Time
+}
+
+type memDep struct {
+	issue, dependsOn, depType string
+}
+
+// NewMemStore creates an empty in-memory store. Created issues get IDs
+// like "<prefix>-1".
+func NewMemStore(prefix string) *MemStore {
+	return &MemStore{
+		prefix: prefix,
+		issues: make(map[string]*Issue),
+		now:    time.Now,
+	}
+}
+
+// Put stores a copy of issue as is, replacing any issue with the same ID.
+// Its DependsOn and Parent fields become dependencies. Tests use Put to
+// seed issues Create can't make, such as messages.
+func (s *MemStore) Put(issue *Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(issue)
+}
+
+// AddTypedDependency adds a dependency of the given type (DepTracks, ...).
+func (s *MemStore) AddTypedDependency(issue, dependsOn, depType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.issues[issue]; !ok {
+		return ErrNotFound
+	}
+	s.addDep(issue, dependsOn, depType)
+	return nil
+}
+
+func (s *MemStore) put(issue *Issue) {
+	stored := *issue
+	stored.Labels = append([]string(nil), issue.Labels...)
+	for _, dep := range issue.DependsOn {
+		s.addDep(issue.ID, dep, DepBlocks)
+	}
+	if issue.Parent != "" {
+		s.addDep(issue.ID, issue.Parent, DepParentChild)
+	}
+	stored.clearDerived()
+	if _, ok := s.issues[issue.ID]; !ok {
+		s.order = append(s.order, issue.ID)
+	}
+	s.issues[issue.ID] = &stored
+}
+
+func (s *MemStore) addDep(issue, dependsOn, depType string) {
+	for _, d := range s.deps {
+		if d.issue == issue && d.dependsOn == dependsOn && d.depType == depType {
+			return
+		}
+	}
+	s.deps = append(s.deps, memDep{issue, dependsOn, depType})
+}
+
+// clearDerived drops the fields MemStore computes on read.
+func (i *Issue) clearDerived() {
+	i.Parent = ""
+	i.Children = nil
+	i.DependsOn = nil
+	i.Blocks = nil
+	i.BlockedBy = nil
+	i.DependencyCount = 0
+	i.DependentCount = 0
+	i.BlockedByCount = 0
+	i.Dependencies = nil
+	i.Dependents = nil
+}
+
+// view returns a copy of the stored issue with its derived fields filled in.
+func (s *MemStore) view(stored *Issue) *Issue {
+	issue := *stored
+	issue.Labels = append([]string(nil), stored.Labels...)
+	for _, d := range s.deps {
+		switch {
+		case d.issue == issue.ID:
+			issue.DependencyCount++
+			target := s.issues[d.dependsOn]
+			issue.Dependencies = append(issue.Dependencies, depInfo(d.dependsOn, target, d.depType))
+			switch d.depType {
+			case DepParentChild:
+				issue.Parent = d.dependsOn
+			case DepBlocks:
+				issue.DependsOn = append(issue.DependsOn, d.dependsOn)
+				if target == nil || target.Status != "closed" {
+					issue.BlockedBy = append(issue.BlockedBy, d.dependsOn)
+				}
+			}
+		case d.dependsOn == issue.ID:
+			issue.DependentCount++
+			issue.Dependents = append(issue.Dependents, depInfo(d.issue, s.issues[d.issue], d.depType))
+			switch d.depType {
+			case DepParentChild:
+				issue.Children = append(issue.Children, d.issue)
+			case DepBlocks:
+				issue.Blocks = append(issue.Blocks, d.issue)
+			}
+		}
+	}
+	issue.BlockedByCount = len(issue.BlockedBy)
+	return &issue
+}
+
+func depInfo(id string, target *Issue, depType string) IssueDep {
+	dep := IssueDep{ID: id, DependencyType: depType}
+	if target != nil {
+		dep.Title = target.Title
+		dep.Status = target.Status
+		dep.Priority = target.Priority
+		dep.Type = target.Type
+	}
+	return dep
+}
+
+// List returns issues matching the given options, by priority and then
+// creation order. An empty Status matches every issue that isn't closed.
+func (s *MemStore) List(opts ListOptions) ([]*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	label := opts.Label
+	if label == "" && opts.Type != "" {
+		label = "gt:" + opts.Type
+	}
+	var result []*Issue
+	for _, id := range s.order {
+		issue := s.view(s.issues[id])
+		if !matchStatus(issue.Status, opts.Status) ||
+			(label != "" && !HasLabel(issue, label)) ||
+			(opts.Priority >= 0 && issue.Priority != opts.Priority) ||
+			(opts.Parent != "" && issue.Parent != opts.Parent) ||
+			(opts.Assignee != "" && issue.Assignee != opts.Assignee) ||
+			(opts.NoAssignee && issue.Assignee != "") {
+			continue
+		}
+		result = append(result, issue)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Priority < result[j].Priority })
+	return result, nil
+}
+
+// ListMessages returns messages matching the given options.
+func (s *MemStore) ListMessages(opts MessageListOptions) ([]*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []*Issue
+	for _, id := range s.order {
+		issue := s.issues[id]
+		if issue.Type != "message" || !matchStatus(issue.Status, opts.Status) ||
+			(opts.Assignee != "" && issue.Assignee != opts.Assignee) ||
+			(opts.Label != "" && !HasLabel(issue, opts.Label)) {
+			continue
+		}
+		result = append(result, s.view(issue))
+	}
+	return result, nil
+}
+
+func matchStatus(status, want string) bool {
+	switch want {
+	case "all":
+		return true
+	case "":
+		return status != "closed"
+	default:
+		return status == want
+	}
+}
+
+// Show returns an issue, or ErrNotFound.
+func (s *MemStore) Show(id string) (*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue, ok := s.issues[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.view(issue), nil
+}
+
+// ShowMultiple returns the issues with the given IDs. Missing IDs are not
+// included in the map.
+func (s *MemStore) ShowMultiple(ids []string) (map[string]*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]*Issue, len(ids))
+	for _, id := range ids {
+		if issue, ok := s.issues[id]; ok {
+			result[id] = s.view(issue)
+		}
+	}
+	return result, nil
+}
+
+// Create creates an issue with the next free ID.
+func (s *MemStore) Create(opts CreateOptions) (*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		s.next++
+		id := fmt.Sprintf("%s-%d", s.prefix, s.next)
+		if _, taken := s.issues[id]; !taken {
+			return s.create(id, opts), nil
+		}
+	}
+}
+
+// CreateWithID creates an issue with a specific ID.
+func (s *MemStore) CreateWithID(id string, opts CreateOptions) (*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, taken := s.issues[id]; taken {
+		return nil, fmt.Errorf("issue %s already exists", id)
+	}
+	return s.create(id, opts), nil
+}
+
+func (s *MemStore) create(id string, opts CreateOptions) *Issue {
+	now := s.now().UTC().Format(time.RFC3339Nano)
+	issue := &Issue{
+		ID:          id,
+		Title:       opts.Title,
+		Description: opts.Description,
+		Status:      "open",
+		Priority:    opts.Priority,
+		Type:        "task",
+		CreatedAt:   now,
+		CreatedBy:   opts.Actor,
+		UpdatedAt:   now,
+		Parent:      opts.Parent,
+		Wisp:        opts.Ephemeral,
+	}
+	if issue.Priority < 0 {
+		issue.Priority = 2
+	}
+	if opts.Type != "" {
+		issue.Labels = []string{"gt:" + opts.Type}
+	}
+	s.put(issue)
+	return s.view(s.issues[id])
+}
+
+// Update updates an existing issue.
+func (s *MemStore) Update(id string, opts UpdateOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue, ok := s.issues[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if opts.Title != nil {
+		issue.Title = *opts.Title
+	}
+	if opts.Status != nil {
+		issue.Status = *opts.Status
+	}
+	if opts.Priority != nil {
+		issue.Priority = *opts.Priority
+	}
+	if opts.Description != nil {
+		issue.Description = *opts.Description
+	}
+	if opts.Assignee != nil {
+		issue.Assignee = *opts.Assignee
+	}
+	if len(opts.SetLabels) > 0 {
+		issue.Labels = append([]string(nil), opts.SetLabels...)
+	} else {
+		for _, label := range opts.AddLabels {
+			issue.addLabel(label)
+		}
+		for _, label := range opts.RemoveLabels {
+			issue.removeLabel(label)
+		}
+	}
+	s.touch(issue)
+	return nil
+}
+
+// Close closes one or more issues.
+func (s *MemStore) Close(ids ...string) error {
+	return s.CloseWithReason("", ids...)
+}
+
+// CloseWithReason closes one or more issues. The reason isn't kept.
+func (s *MemStore) CloseWithReason(reason string, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := s.issues[id]; !ok {
+			return ErrNotFound
+		}
+	}
+	for _, id := range ids {
+		issue := s.issues[id]
+		issue.Status = "closed"
+		s.touch(issue)
+		issue.ClosedAt = issue.UpdatedAt
+	}
+	return nil
+}
+
+// AddLabel adds a label to an issue.
+func (s *MemStore) AddLabel(id, label string) error {
+	return s.modify(id, func(issue *Issue) error {
+		issue.addLabel(label)
+		return nil
+	})
+}
+
+// RemoveLabel removes a label from an issue.
+func (s *MemStore) RemoveLabel(id, label string) error {
+	return s.modify(id, func(issue *Issue) error {
+		issue.removeLabel(label)
+		return nil
+	})
+}
+
+// AddDependency adds a blocking dependency: issue depends on dependsOn.
+func (s *MemStore) AddDependency(issue, dependsOn string) error {
+	return s.AddTypedDependency(issue, dependsOn, DepBlocks)
+}
+
+// RemoveDependency removes every dependency of issue on dependsOn.
+func (s *MemStore) RemoveDependency(issue, dependsOn string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.deps[:0]
+	for _, d := range s.deps {
+		if d.issue != issue || d.dependsOn != dependsOn {
+			kept = append(kept, d)
+		}
+	}
+	s.deps = kept
+	return nil
+}
+
+// SetSlot sets an agent bead's slot to value.
+func (s *MemStore) SetSlot(id, slot, value string) error {
+	return s.modify(id, func(issue *Issue) error {
+		return issue.setSlot(slot, value)
+	})
+}
+
+// ClearSlot empties an agent bead's slot.
+func (s *MemStore) ClearSlot(id, slot string) error {
+	return s.modify(id, func(issue *Issue) error {
+		return issue.setSlot(slot, "")
+	})
+}
+
+func (s *MemStore) modify(id string, fn func(*Issue) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue, ok := s.issues[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := fn(issue); err != nil {
+		return err
+	}
+	s.touch(issue)
+	return nil
+}
+
+func (s *MemStore) touch(issue *Issue) {
+	issue.UpdatedAt = s.now().UTC().Format(time.RFC3339Nano)
+}
+
+func (i *Issue) addLabel(label string) {
+	if !HasLabel(i, label) {
+		i.Labels = append(i.Labels, label)
+	}
+}
+
+func (i *Issue) removeLabel(label string) {
+	kept := i.Labels[:0]
+	for _, l := range i.Labels {
+		if l != label {
+			kept = append(kept, l)
+		}
+	}
+	i.Labels = kept
+}
+
+func (i *Issue) setSlot(slot, value string) error {
+	switch slot {
+	case SlotHook:
+		i.HookBead = value
+	case SlotRole:
+		i.RoleBead = value
+	default:
+		return fmt.Errorf("unknown slot %q", slot)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package beads
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/beadsdb"
+)
+
+func TestMemStore_IssuesAndDependencies(t *testing.T) {
+	s := NewMemStore("wt")
+
+	epic, err := s.Create(CreateOptions{Title: "Epic", Type: "epic", Priority: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.Create(CreateOptions{Title: "Task", Parent: epic.ID, Priority: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocker, err := s.CreateWithID("wt-blocker", CreateOptions{Title: "Blocker", Priority: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateWithID("wt-blocker", CreateOptions{Title: "Again"}); err == nil {
+		t.Error("CreateWithID with a taken ID: want error")
+	}
+	if err := s.AddDependency(task.ID, blocker.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Show(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Parent != epic.ID || !reflect.DeepEqual(got.BlockedBy, []string{"wt-blocker"}) || got.BlockedByCount != 1 {
+		t.Errorf("task = parent %q, blocked by %v (%d)", got.Parent, got.BlockedBy, got.BlockedByCount)
+	}
+	if e, _ := s.Show(epic.ID); !reflect.DeepEqual(e.Children, []string{task.ID}) || !HasLabel(e, "gt:epic") {
+		t.Errorf("epic = children %v, labels %v", e.Children, e.Labels)
+	}
+
+	// Closing the blocker unblocks the task; closed issues drop out of the default listing
+	if err := s.CloseWithReason("done", blocker.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Show(task.ID); len(got.BlockedBy) != 0 || !reflect.DeepEqual(got.DependsOn, []string{"wt-blocker"}) {
+		t.Errorf("after close: blocked by %v, depends on %v", got.BlockedBy, got.DependsOn)
+	}
+	open, _ := s.List(ListOptions{Priority: -1})
+	if ids := issueIDs(open); !reflect.DeepEqual(ids, []string{epic.ID, task.ID}) {
+		t.Errorf("List() = %v, want epic then task", ids)
+	}
+	children, _ := s.List(ListOptions{Status: "all", Parent: epic.ID, Priority: -1})
+	if ids := issueIDs(children); !reflect.DeepEqual(ids, []string{task.ID}) {
+		t.Errorf("List(parent) = %v", ids)
+	}
+
+	if err := s.Close("wt-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Close(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemStore_LabelsSlotsAndMessages(t *testing.T) {
+	s := NewMemStore("hq")
+	s.Put(&Issue{ID: "hq-agent", Title: "witness", Status: "open", Type: "agent", Labels: []string{"gt:agent"}})
+	s.Put(&Issue{ID: "hq-m1", Status: "open", Type: "message", Assignee: "wt/witness", Labels: []string{"from:mayor/"}})
+	s.Put(&Issue{ID: "hq-m2", Status: "open", Type: "message", Assignee: "mayor/", Labels: []string{"cc:wt/witness"}})
+	s.Put(&Issue{ID: "hq-m3", Status: "closed", Type: "message", Assignee: "wt/witness"})
+
+	if err := s.SetSlot("hq-agent", SlotHook, "wt-work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetSlot("hq-agent", "pocket", "x"); err == nil {
+		t.Error("SetSlot(unknown slot): want error")
+	}
+	agents, err := ListAgentBeadsIn(s)
+	if err != nil || agents["hq-agent"] == nil || agents["hq-agent"].HookBead != "wt-work" {
+		t.Fatalf("ListAgentBeadsIn() = %v, %v", agents, err)
+	}
+	if err := s.ClearSlot("hq-agent", SlotHook); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.AddLabel("hq-m1", "read"); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.AddLabel("hq-m1", "read")
+	if err := s.Update("hq-m1", UpdateOptions{RemoveLabels: []string{"from:mayor/"}}); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := s.Show("hq-m1"); !reflect.DeepEqual(m.Labels, []string{"read"}) {
+		t.Errorf("labels = %v, want [read]", m.Labels)
+	}
+
+	inbox, _ := s.ListMessages(MessageListOptions{Assignee: "wt/witness", Status: "open"})
+	cc, _ := s.ListMessages(MessageListOptions{Label: "cc:wt/witness", Status: "open"})
+	all, _ := s.ListMessages(MessageListOptions{Assignee: "wt/witness", Status: "all"})
+	if !reflect.DeepEqual(issueIDs(inbox), []string{"hq-m1"}) ||
+		!reflect.DeepEqual(issueIDs(cc), []string{"hq-m2"}) ||
+		!reflect.DeepEqual(issueIDs(all), []string{"hq-m1", "hq-m3"}) {
+		t.Errorf("inbox %v, cc %v, all %v", issueIDs(inbox), issueIDs(cc), issueIDs(all))
+	}
+}
+
+// newTestDB creates a beads.db with the tables LocalStore reads.
+func newTestDB(t *testing.T) string {
+	t.Helper()
+	if !beadsdb.Available() {
+		t.Skip("sqlite3 not installed")
+	}
+	beadsDir := filepath.Join(t.TempDir(), ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	schema := `
+CREATE TABLE issues (id TEXT PRIMARY KEY, title TEXT, description TEXT, status TEXT, priority INTEGER,
+  issue_type TEXT, assignee TEXT, created_at TEXT, updated_at TEXT, closed_at TEXT, deleted_at TEXT,
+  hook_bead TEXT, pinned INTEGER DEFAULT 0, ephemeral INTEGER DEFAULT 0, extra TEXT);
+CREATE TABLE labels (issue_id TEXT, label TEXT);
+CREATE TABLE dependencies (issue_id TEXT, depends_on_id TEXT, type TEXT);
+INSERT INTO issues VALUES
+  ('wt-agent', 'witness', '', 'open', 2, 'agent', NULL, '2026-01-02 03:04:05', '2026-01-02 03:04:05', NULL, NULL, 'wt-task', 0, 0, 'x'),
+  ('wt-task', 'Task', 'do it', 'in_progress', 1, 'task', 'wt/nux', '2026-01-02T03:04:05Z', '2026-01-02T03:04:05Z', NULL, NULL, NULL, 0, 0, NULL),
+  ('wt-gone', 'Deleted', '', 'tombstone', 2, 'task', NULL, NULL, NULL, NULL, '2026-01-03', NULL, 0, 0, NULL),
+  ('wt-msg', 'Hello', 'body', 'open', 2, 'message', 'wt/witness', '2026-01-02T03:04:05Z', NULL, NULL, NULL, NULL, 1, 1, NULL);
+INSERT INTO labels VALUES ('wt-agent', 'gt:agent'), ('wt-msg', 'from:mayor/');
+INSERT INTO dependencies VALUES ('wt-task', 'wt-agent', 'blocks');
+`
+	if out, err := exec.Command(beadsdb.Command, filepath.Join(beadsDir, "beads.db"), schema).CombinedOutput(); err != nil {
+		t.Fatalf("creating test db: %v\n%s", err, out)
+	}
+	return beadsDir
+}
+
+func TestLocalStore_ReadsDatabase(t *testing.T) {
+	beadsDir := newTestDB(t)
+	s := NewLocalStore(filepath.Dir(beadsDir), beadsDir)
+
+	agents, err := ListAgentBeadsIn(s)
+	if err != nil {
+		t.Fatalf("ListAgentBeadsIn() error = %v", err)
+	}
+	agent := agents["wt-agent"]
+	if len(agents) != 1 || agent == nil || agent.HookBead != "wt-task" || agent.CreatedAt != "2026-01-02T03:04:05Z" {
+		t.Fatalf("agents = %+v", agents)
+	}
+
+	task, err := s.Show("wt-task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Assignee != "wt/nux" || !reflect.DeepEqual(task.BlockedBy, []string{"wt-agent"}) {
+		t.Errorf("task = %+v", task)
+	}
+	if _, err := s.Show("wt-gone"); err == nil {
+		t.Error("Show(tombstone): want error")
+	}
+
+	msgs, err := s.ListMessages(MessageListOptions{Assignee: "wt/witness", Status: "open"})
+	if err != nil || len(msgs) != 1 || !msgs[0].Pinned || !msgs[0].Wisp || !HasLabel(msgs[0], "from:mayor/") {
+		t.Errorf("ListMessages() = %+v, %v", msgs, err)
+	}
+}
+
+func TestLocalStore_WriteThrough(t *testing.T) {
+	beadsDir := newTestDB(t)
+
+	// bd accepts every write without touching the database, so the only
+	// way a write shows up in reads is through the snapshot.
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte("#!/bin/sh\necho ok\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	s := NewLocalStore(filepath.Dir(beadsDir), beadsDir)
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetWriteThrough(true)
+	if err := s.AddLabel("wt-task", "urgent"); err != nil {
+		t.Fatal(err)
+	}
+	if task, _ := s.Show("wt-task"); !HasLabel(task, "urgent") {
+		t.Errorf("write-through: labels = %v, want urgent", task.Labels)
+	}
+
+	s.SetWriteThrough(false)
+	if err := s.AddLabel("wt-task", "later"); err != nil {
+		t.Fatal(err)
+	}
+	if task, _ := s.Show("wt-task"); HasLabel(task, "urgent") || HasLabel(task, "later") {
+		t.Errorf("without write-through the snapshot reloads: labels = %v", task.Labels)
+	}
+}
+
+func issueIDs(issues []*Issue) []string {
+	ids := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	return ids
+}
@@ -0,0 +1,206 @@
+// Package beadsdb runs read-only queries against a beads database
+// (.beads/beads.db) for the dashboards and watchers that need more than
+// bd's CLI offers: joins across convoys, dependencies and agents, batched
+// into one query instead of a bd call per issue.
+//
+// Queries go through the sqlite3 binary. Values are never spliced into
+// SQL: they are bound to :name parameters, so an issue ID can't change
+// what a query does however it is spelled.
+package beadsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Command is the sqlite3 binary queries run with.
+var Command = "sqlite3"
+
+// DefaultTimeout bounds a query whose context has no deadline.
+const DefaultTimeout = 5 * time.Second
+
+// ErrNoDatabase is returned when the database file doesn't exist.
+var ErrNoDatabase = errors.New("beads database not found")
+
+// Params binds values to a query's :name placeholders. Values may be
+// strings, ints, bools, nil, or []string, which is bound as a JSON array
+// for use with json_each:
+//
+//	WHERE id IN (SELECT value FROM json_each(:ids))
+type Params map[string]interface{}
+
+var paramName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DB is a read-only handle on a beads database.
+type DB struct {
+	path string
+}
+
+// Open returns the database in a .beads directory.
+func Open(beadsDir string) *DB {
+	return &DB{path: filepath.Join(beadsDir, "beads.db")}
+}
+
+// OpenFile returns the database at path.
+func OpenFile(path string) *DB {
+	return &DB{path: path}
+}
+
+// Path returns the database file path.
+func (db *DB) Path() string {
+	return db.path
+}
+
+// Available reports whether the sqlite3 binary is installed.
+func Available() bool {
+	_, err := exec.LookPath(Command)
+	return err == nil
+}
+
+// Query runs one SELECT with params bound and decodes its rows into dest,
+// a pointer to a slice of structs whose JSON tags name the columns.
+// Integer columns decode into ints, text into strings; NULL leaves the
+// zero value.
+func (db *DB) Query(ctx context.Context, dest interface{}, query string, params Params) error {
+	if _, err := os.Stat(db.path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNoDatabase, db.path)
+		}
+		return err
+	}
+	script, err := buildScript(query, params)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, Command, "-readonly", "-json", db.path) //nolint:gosec // G204: the query arrives on stdin with bound parameters
+	cmd.Stdin = strings.NewReader(script)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var execErr *exec.Error
+		if errors.As(err, &execErr) {
+			return fmt.Errorf("querying %s: %s not installed", db.path, Command)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("querying %s: %s", db.path, msg)
+		}
+		return fmt.Errorf("querying %s: %w", db.path, err)
+	}
+
+	// sqlite3 prints nothing at all for an empty result
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) == 0 {
+		out = []byte("[]")
+	}
+	if err := json.Unmarshal(out, dest); err != nil {
+		return fmt.Errorf("parsing query result from %s: %w", db.path, err)
+	}
+	return nil
+}
+
+// buildScript returns the sqlite3 input for query: its parameters stored
+// in the shell's parameter table, which sqlite3 binds to each statement,
+// then the query itself.
+func buildScript(query string, params Params) (string, error) {
+	var b strings.Builder
+	b.WriteString(".bail on\n.timeout 2000\n")
+	if len(params) > 0 {
+		b.WriteString(".parameter init\n")
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !paramName.MatchString(name) {
+				return "", fmt.Errorf("invalid parameter name %q", name)
+			}
+			value, err := literal(params[name])
+			if err != nil {
+				return "", fmt.Errorf("parameter %s: %w", name, err)
+			}
+			fmt.Fprintf(&b, "INSERT INTO temp.sqlite_parameters(key, value) VALUES (':%s', %s);\n", name, value)
+		}
+	}
+	b.WriteString(strings.TrimRight(strings.TrimSpace(query), ";"))
+	b.WriteString(";\n")
+	return b.String(), nil
+}
+
+// literal returns the SQL literal storing v in the parameter table. Text
+// is written as a hex blob cast to text, so no value needs escaping.
+func literal(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return textLiteral(v), nil
+	case []string:
+		if v == nil {
+			v = []string{}
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return textLiteral(string(data)), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+func textLiteral(s string) string {
+	return "CAST(X'" + hex.EncodeToString([]byte(s)) + "' AS TEXT)"
+}
+
+// timeLayouts are the timestamp formats found in beads databases.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+}
+
+// ParseTime parses a timestamp as stored in a beads database. It returns
+// the zero time for empty or unrecognized values.
+func ParseTime(v string) time.Time {
+	if v == "" {
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,189 @@
+package beadsdb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureDB builds a database from testdata/<name>.sql.
+func fixtureDB(t *testing.T, name string) *DB {
+	t.Helper()
+	if !Available() {
+		t.Skip("sqlite3 not installed")
+	}
+	sql, err := os.ReadFile(filepath.Join("testdata", name+".sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	beadsDir := filepath.Join(t.TempDir(), ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	db := Open(beadsDir)
+	cmd := exec.Command(Command, db.Path())
+	cmd.Stdin = strings.NewReader(string(sql))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("loading fixture %s: %v\n%s", name, err, out)
+	}
+	return db
+}
+
+func TestQuery_BindsParameters(t *testing.T) {
+	db := fixtureDB(t, "town")
+	ctx := context.Background()
+
+	var rows []struct {
+		ID string `json:"id"`
+	}
+	// A value that would end the string literal and add a clause if it were spliced in
+	hostile := "x' OR '1'='1"
+	if err := db.Query(ctx, &rows, `SELECT id FROM issues WHERE id = :id`, Params{"id": hostile}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("hostile ID matched %d rows, want 0", len(rows))
+	}
+
+	if err := db.Query(ctx, &rows, `SELECT id FROM issues WHERE title = :title`, Params{"title": "It's quoted"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].ID != "hq-task3" {
+		t.Errorf("rows = %+v, want hq-task3", rows)
+	}
+
+	if err := db.Query(ctx, &rows, `SELECT id FROM issues WHERE id = :id`, Params{"id; DROP": "x"}); err == nil {
+		t.Error("invalid parameter name: want error")
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	db := fixtureDB(t, "town")
+	var rows []struct{}
+	if err := db.Query(context.Background(), &rows, `SELECT nope FROM issues`, nil); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("bad column: err = %v", err)
+	}
+	missing := Open(t.TempDir())
+	if err := missing.Query(context.Background(), &rows, `SELECT 1`, nil); !errors.Is(err, ErrNoDatabase) {
+		t.Errorf("missing database: err = %v, want ErrNoDatabase", err)
+	}
+}
+
+func TestConvoys(t *testing.T) {
+	db := fixtureDB(t, "town")
+	ctx := context.Background()
+
+	open, err := db.Convoys(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(open); !reflect.DeepEqual(got, []string{"hq-cv-auth", "hq-cv-docs"}) {
+		t.Errorf("Convoys(\"\") = %v", got)
+	}
+	if want := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC); !open[1].CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", open[1].CreatedAt, want)
+	}
+
+	closed, err := db.Convoys(ctx, "closed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(closed); !reflect.DeepEqual(got, []string{"hq-cv-old"}) {
+		t.Errorf("Convoys(closed) = %v", got)
+	}
+}
+
+func TestTrackedIssues(t *testing.T) {
+	db := fixtureDB(t, "town")
+
+	tracked, err := db.TrackedIssues(context.Background(), []string{"hq-cv-auth", "hq-cv-docs", "hq-cv-none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 2 {
+		t.Fatalf("tracked has %d convoys, want 2: %+v", len(tracked), tracked)
+	}
+
+	auth := tracked["hq-cv-auth"]
+	if len(auth) != 3 {
+		t.Fatalf("hq-cv-auth tracks %d issues, want 3", len(auth))
+	}
+	byID := make(map[string]Tracked)
+	for _, tr := range auth {
+		byID[tr.ID] = tr
+	}
+	if i := byID["hq-task1"].Issue; i == nil || i.Status != "closed" || i.ClosedAt.IsZero() {
+		t.Errorf("hq-task1 = %+v", i)
+	}
+	if i := byID["hq-task2"].Issue; i == nil || i.Assignee != "whaletown/polecats/toast" {
+		t.Errorf("hq-task2 = %+v", i)
+	}
+	if r := byID["wt-remote"]; r.Issue != nil || r.Ref != "external:whaletown:wt-remote" {
+		t.Errorf("wt-remote = %+v, want unresolved external ref", r)
+	}
+
+	// An external ref to an issue in this database resolves
+	if docs := tracked["hq-cv-docs"]; len(docs) != 1 || docs[0].ID != "hq-task3" || docs[0].Issue == nil {
+		t.Errorf("hq-cv-docs = %+v", docs)
+	}
+}
+
+func TestDependencyQueries(t *testing.T) {
+	db := fixtureDB(t, "town")
+	ctx := context.Background()
+
+	deps, err := db.Dependents(ctx, []string{"hq-task3", "hq-task1"}, "tracks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var convoys []string
+	for _, d := range deps {
+		convoys = append(convoys, d.IssueID)
+	}
+	if !reflect.DeepEqual(convoys, []string{"hq-cv-auth", "hq-cv-docs"}) {
+		t.Errorf("Dependents(tracks) = %+v", deps)
+	}
+
+	tracking, err := db.TrackingConvoys(ctx, "hq-task3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tracking, []string{"hq-cv-docs"}) {
+		t.Errorf("TrackingConvoys(hq-task3) = %v", tracking)
+	}
+
+	agents, err := db.AgentActivity(ctx, []string{"hq-task2", "hq-task3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 1 || agents[0].ID != "hq-agent-toast" || agents[0].LastActivity.IsZero() {
+		t.Errorf("AgentActivity() = %+v", agents)
+	}
+}
+
+func TestNormalizeRef(t *testing.T) {
+	for ref, want := range map[string]string{
+		"wt-abc":                  "wt-abc",
+		"external:whaletown:wt-1": "wt-1",
+		"external:broken":         "external:broken",
+		"hop://town/wt-1":         "hop://town/wt-1",
+	} {
+		if got := NormalizeRef(ref); got != want {
+			t.Errorf("NormalizeRef(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func ids(issues []*Issue) []string {
+	out := make([]string, 0, len(issues))
+	for _, i := range issues {
+		out = append(out, i.ID)
+	}
+	return out
+}
@@ -0,0 +1,224 @@
+package beadsdb
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Issue is an issue row.
+type Issue struct {
+	ID          string
+	Title       string
+	Description string
+	Status      string
+	Type        string
+	Priority    int
+	Assignee    string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ClosedAt    time.Time
+}
+
+// Tracked is an issue tracked by a convoy.
+type Tracked struct {
+	ConvoyID string
+	Ref      string // as stored, e.g. "external:whaletown:wt-abc"
+	ID       string // Ref without the external: prefix
+	Issue    *Issue // nil when the issue isn't in this database
+}
+
+// Dependency is a dependency row: IssueID depends on DependsOnID.
+type Dependency struct {
+	IssueID     string `json:"issue_id"`
+	DependsOnID string `json:"depends_on_id"`
+	Type        string `json:"type"`
+}
+
+// AgentActivity is an open agent bead with work on its hook.
+type AgentActivity struct {
+	ID           string
+	HookBead     string
+	LastActivity time.Time
+}
+
+// issueRow is an issue as sqlite3 -json prints it.
+type issueRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Type        string `json:"issue_type"`
+	Priority    int    `json:"priority"`
+	Assignee    string `json:"assignee"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	ClosedAt    string `json:"closed_at"`
+}
+
+func (r *issueRow) issue() *Issue {
+	return &Issue{
+		ID:          r.ID,
+		Title:       r.Title,
+		Description: r.Description,
+		Status:      r.Status,
+		Type:        r.Type,
+		Priority:    r.Priority,
+		Assignee:    r.Assignee,
+		CreatedAt:   ParseTime(r.CreatedAt),
+		UpdatedAt:   ParseTime(r.UpdatedAt),
+		ClosedAt:    ParseTime(r.ClosedAt),
+	}
+}
+
+const issueColumns = `i.id, i.title, i.description, i.status, i.issue_type, i.priority,
+	i.assignee, i.created_at, i.updated_at, i.closed_at`
+
+// refID is the SQL for a dependency target with any "external:<rig>:"
+// prefix removed (see NormalizeRef).
+const refID = `CASE WHEN d.depends_on_id LIKE 'external:%:%'
+	THEN substr(d.depends_on_id, instr(substr(d.depends_on_id, 10), ':') + 10)
+	ELSE d.depends_on_id END`
+
+// NormalizeRef returns the issue ID of a dependency target, dropping the
+// "external:<rig>:" prefix of cross-rig references.
+func NormalizeRef(ref string) string {
+	if strings.HasPrefix(ref, "external:") {
+		if parts := strings.SplitN(ref, ":", 3); len(parts) == 3 {
+			return parts[2]
+		}
+	}
+	return ref
+}
+
+// Convoys returns convoys with the given status, oldest first. An empty
+// status returns every convoy that isn't closed.
+func (db *DB) Convoys(ctx context.Context, status string) ([]*Issue, error) {
+	var rows []issueRow
+	err := db.Query(ctx, &rows, `SELECT `+issueColumns+` FROM issues i
+		WHERE i.issue_type = 'convoy' AND i.status != 'tombstone'
+		AND ((:status = '' AND i.status != 'closed') OR i.status = :status)
+		ORDER BY i.created_at, i.id`, Params{"status": status})
+	if err != nil {
+		return nil, err
+	}
+	convoys := make([]*Issue, 0, len(rows))
+	for i := range rows {
+		convoys = append(convoys, rows[i].issue())
+	}
+	return convoys, nil
+}
+
+// Issues returns the issues with the given IDs. IDs not in this database
+// are missing from the map.
+func (db *DB) Issues(ctx context.Context, ids []string) (map[string]*Issue, error) {
+	result := make(map[string]*Issue, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var rows []issueRow
+	err := db.Query(ctx, &rows, `SELECT `+issueColumns+` FROM issues i
+		WHERE i.id IN (SELECT value FROM json_each(:ids)) AND i.status != 'tombstone'`,
+		Params{"ids": ids})
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		result[rows[i].ID] = rows[i].issue()
+	}
+	return result, nil
+}
+
+// TrackedIssues returns the issues tracked by each of the given convoys,
+// with their details when they live in this database, in one query.
+func (db *DB) TrackedIssues(ctx context.Context, convoyIDs []string) (map[string][]Tracked, error) {
+	result := make(map[string][]Tracked, len(convoyIDs))
+	if len(convoyIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		ConvoyID string `json:"convoy_id"`
+		Ref      string `json:"ref"`
+		Found    int    `json:"found"`
+		issueRow
+	}
+	err := db.Query(ctx, &rows, `SELECT d.issue_id AS convoy_id, d.depends_on_id AS ref,
+		i.id IS NOT NULL AS found, `+issueColumns+`
+		FROM dependencies d
+		LEFT JOIN issues i ON i.id = `+refID+` AND i.status != 'tombstone'
+		WHERE d.type = 'tracks' AND d.issue_id IN (SELECT value FROM json_each(:convoys))
+		ORDER BY d.issue_id, d.depends_on_id`, Params{"convoys": convoyIDs})
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		row := &rows[i]
+		t := Tracked{ConvoyID: row.ConvoyID, Ref: row.Ref, ID: NormalizeRef(row.Ref)}
+		if row.Found != 0 {
+			t.Issue = row.issue()
+		}
+		result[row.ConvoyID] = append(result[row.ConvoyID], t)
+	}
+	return result, nil
+}
+
+// Dependents returns dependencies of the given type (any type when
+// empty) on the given issues, matching cross-rig references by their
+// issue ID.
+func (db *DB) Dependents(ctx context.Context, ids []string, depType string) ([]Dependency, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var deps []Dependency
+	err := db.Query(ctx, &deps, `SELECT DISTINCT d.issue_id, d.depends_on_id, d.type
+		FROM dependencies d
+		WHERE (:type = '' OR d.type = :type)
+		AND `+refID+` IN (SELECT value FROM json_each(:ids))
+		ORDER BY d.issue_id`, Params{"ids": ids, "type": depType})
+	return deps, err
+}
+
+// TrackingConvoys returns the IDs of the convoys tracking an issue,
+// directly or through a cross-rig reference.
+func (db *DB) TrackingConvoys(ctx context.Context, issueID string) ([]string, error) {
+	var rows []struct {
+		ID string `json:"id"`
+	}
+	err := db.Query(ctx, &rows, `SELECT DISTINCT d.issue_id AS id
+		FROM dependencies d
+		JOIN issues i ON i.id = d.issue_id AND i.issue_type = 'convoy'
+		WHERE d.type = 'tracks' AND `+refID+` = :id
+		ORDER BY d.issue_id`, Params{"id": issueID})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.ID)
+	}
+	return ids, nil
+}
+
+// AgentActivity returns the open agent beads hooked to any of the given
+// issues.
+func (db *DB) AgentActivity(ctx context.Context, hookBeads []string) ([]AgentActivity, error) {
+	if len(hookBeads) == 0 {
+		return nil, nil
+	}
+	var rows []struct {
+		ID           string `json:"id"`
+		HookBead     string `json:"hook_bead"`
+		LastActivity string `json:"last_activity"`
+	}
+	err := db.Query(ctx, &rows, `SELECT id, hook_bead, last_activity FROM issues
+		WHERE issue_type = 'agent' AND status = 'open'
+		AND hook_bead IN (SELECT value FROM json_each(:hooks))`, Params{"hooks": hookBeads})
+	if err != nil {
+		return nil, err
+	}
+	agents := make([]AgentActivity, 0, len(rows))
+	for _, r := range rows {
+		agents = append(agents, AgentActivity{ID: r.ID, HookBead: r.HookBead, LastActivity: ParseTime(r.LastActivity)})
+	}
+	return agents, nil
+}
@@ -0,0 +1,36 @@
+-- Fixture town database: the tables and columns of a bd database that
+-- beadsdb and beads.LocalStore read.
+CREATE TABLE issues (
+  id TEXT PRIMARY KEY,
+  title TEXT NOT NULL DEFAULT '',
+  description TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'open',
+  priority INTEGER NOT NULL DEFAULT 2,
+  issue_type TEXT NOT NULL DEFAULT 'task',
+  assignee TEXT,
+  created_at TEXT,
+  updated_at TEXT,
+  closed_at TEXT,
+  hook_bead TEXT,
+  last_activity TEXT
+);
+CREATE TABLE labels (issue_id TEXT NOT NULL, label TEXT NOT NULL);
+CREATE TABLE dependencies (issue_id TEXT NOT NULL, depends_on_id TEXT NOT NULL, type TEXT NOT NULL DEFAULT 'blocks');
+
+INSERT INTO issues (id, title, status, issue_type, assignee, created_at, updated_at, closed_at, hook_bead, last_activity) VALUES
+  ('hq-cv-auth', 'Auth rework', 'open', 'convoy', NULL, '2026-01-01T09:00:00Z', '2026-01-01T09:00:00Z', NULL, NULL, NULL),
+  ('hq-cv-docs', 'Docs pass', 'open', 'convoy', NULL, '2026-01-02 09:00:00', '2026-01-02 09:00:00', NULL, NULL, NULL),
+  ('hq-cv-old', 'Shipped', 'closed', 'convoy', NULL, '2025-12-01T09:00:00Z', '2025-12-05T09:00:00Z', '2025-12-05T09:00:00Z', NULL, NULL),
+  ('hq-cv-gone', 'Deleted', 'tombstone', 'convoy', NULL, '2025-11-01T09:00:00Z', NULL, NULL, NULL, NULL),
+  ('hq-task1', 'Login form', 'closed', 'task', 'whaletown/polecats/nux', '2026-01-01T09:00:00Z', '2026-01-03T10:00:00Z', '2026-01-03T10:00:00Z', NULL, NULL),
+  ('hq-task2', 'Token refresh', 'in_progress', 'task', 'whaletown/polecats/toast', '2026-01-01T09:00:00Z', '2026-01-04T11:00:00Z', NULL, NULL, NULL),
+  ('hq-task3', 'It''s quoted', 'open', 'task', NULL, '2026-01-02T09:00:00Z', NULL, NULL, NULL, NULL),
+  ('hq-agent-toast', 'toast', 'open', 'agent', NULL, '2026-01-01T09:00:00Z', NULL, NULL, 'hq-task2', '2026-01-04T11:30:00Z'),
+  ('hq-agent-idle', 'idle', 'closed', 'agent', NULL, '2026-01-01T09:00:00Z', NULL, NULL, 'hq-task3', NULL);
+
+INSERT INTO dependencies (issue_id, depends_on_id, type) VALUES
+  ('hq-cv-auth', 'hq-task1', 'tracks'),
+  ('hq-cv-auth', 'hq-task2', 'tracks'),
+  ('hq-cv-auth', 'external:whaletown:wt-remote', 'tracks'),
+  ('hq-cv-docs', 'external:hq:hq-task3', 'tracks'),
+  ('hq-task2', 'hq-task1', 'blocks');
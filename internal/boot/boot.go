@@ -11,8 +11,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/config"
-	"github.com/speaker20/whaletown/internal/tmux"
 )
 
 // SessionName is the tmux session name for Boot.
@@ -43,7 +43,7 @@ type Boot struct {
 	townRoot  string
 	bootDir   string // ~/gt/deacon/dogs/boot/
 	deaconDir string // ~/gt/deacon/
-	tmux      *tmux.Tmux
+	sessions  backend.SessionBackend
 	degraded  bool
 }
 
@@ -53,7 +53,7 @@ func New(townRoot string) *Boot {
 		townRoot:  townRoot,
 		bootDir:   filepath.Join(townRoot, "deacon", "dogs", "boot"),
 		deaconDir: filepath.Join(townRoot, "deacon"),
-		tmux:      tmux.NewTmux(),
+		sessions:  backend.New(townRoot),
 		degraded:  os.Getenv("WT_DEGRADED") == "true",
 	}
 }
@@ -81,7 +81,7 @@ func (b *Boot) IsRunning() bool {
 
 // IsSessionAlive checks if the Boot tmux session exists.
 func (b *Boot) IsSessionAlive() bool {
-	has, err := b.tmux.HasSession(SessionName)
+	has, err := b.sessions.HasSession(SessionName)
 	return err == nil && has
 }
 
@@ -141,7 +141,7 @@ func (b *Boot) LoadStatus() (*Status, error) {
 	return &status, nil
 }
 
-// Spawn starts Boot in a fresh tmux session.
+// Spawn starts Boot in a fresh agent session.
 // Boot runs the mol-boot-triage molecule and exits when done.
 // In degraded mode (no tmux), it runs in a subprocess.
 // The agentOverride parameter allows specifying an agent alias to use instead of the town default.
@@ -155,14 +155,14 @@ func (b *Boot) Spawn(agentOverride string) error {
 		return b.spawnDegraded()
 	}
 
-	return b.spawnTmux(agentOverride)
+	return b.spawnSession(agentOverride)
 }
 
-// spawnTmux spawns Boot in a tmux session.
-func (b *Boot) spawnTmux(agentOverride string) error {
+// spawnSession spawns Boot in a session on the town's session backend.
+func (b *Boot) spawnSession(agentOverride string) error {
 	// Kill any stale session first
 	if b.IsSessionAlive() {
-		_ = b.tmux.KillSession(SessionName)
+		_ = b.sessions.KillSession(SessionName)
 	}
 
 	// Ensure boot directory exists (it should have CLAUDE.md with Boot context)
@@ -185,7 +185,7 @@ func (b *Boot) spawnTmux(agentOverride string) error {
 
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/whaletown/issues/280
-	if err := b.tmux.NewSessionWithCommand(SessionName, b.bootDir, startCmd); err != nil {
+	if err := b.sessions.NewSessionWithCommand(SessionName, b.bootDir, startCmd); err != nil {
 		return fmt.Errorf("creating boot session: %w", err)
 	}
 
@@ -195,7 +195,7 @@ func (b *Boot) spawnTmux(agentOverride string) error {
 		TownRoot: b.townRoot,
 	})
 	for k, v := range envVars {
-		_ = b.tmux.SetEnvironment(SessionName, k, v)
+		_ = b.sessions.SetEnvironment(SessionName, k, v)
 	}
 
 	return nil
@@ -236,7 +236,7 @@ func (b *Boot) DeaconDir() string {
 	return b.deaconDir
 }
 
-// Tmux returns the tmux manager.
-func (b *Boot) Tmux() *tmux.Tmux {
-	return b.tmux
+// Sessions returns the session backend Boot and the Deacon run on.
+func (b *Boot) Sessions() backend.SessionBackend {
+	return b.sessions
 }
@@ -276,7 +276,7 @@ func runBootTriage(cmd *cobra.Command, args []string) error {
 // runDegradedTriage performs basic Deacon health check without AI reasoning.
 // This is a mechanical fallback when full Claude sessions aren't available.
 func runDegradedTriage(b *boot.Boot) (action, target string, err error) {
-	tm := b.Tmux()
+	tm := b.Sessions()
 
 	// Check if Deacon session exists
 	deaconSession := getDeaconSessionName()
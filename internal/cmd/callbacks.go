@@ -11,6 +11,7 @@ import (
 	"github.com/speaker20/whaletown/internal/mail"
 	"github.com/speaker20/whaletown/internal/style"
 	"github.com/speaker20/whaletown/internal/townlog"
+	"github.com/speaker20/whaletown/internal/witness"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
 
@@ -274,24 +275,12 @@ func classifyCallback(subject string) CallbackType {
 
 // handlePolecatDone processes a POLECAT_DONE callback.
 // These come from Witnesses forwarding polecat completion notices.
-func handlePolecatDone(townRoot string, msg *mail.Message, dryRun bool) (string, error) { //nolint:unparam // error return kept for consistency with callback interface
-	matches := patternPolecatDone.FindStringSubmatch(msg.Subject)
-	polecatName := ""
-	if len(matches) > 1 {
-		polecatName = matches[1]
-	}
-
-	// Extract info from body
-	var exitType, issueID string
-	for _, line := range strings.Split(msg.Body, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "Exit:") {
-			exitType = strings.TrimSpace(strings.TrimPrefix(line, "Exit:"))
-		}
-		if strings.HasPrefix(line, "Issue:") {
-			issueID = strings.TrimSpace(strings.TrimPrefix(line, "Issue:"))
-		}
+func handlePolecatDone(townRoot string, msg *mail.Message, dryRun bool) (string, error) {
+	done, err := witness.ParsePolecatDone(msg.Subject, msg.Body)
+	if err != nil {
+		return "", err
 	}
+	polecatName, exitType, issueID := done.PolecatName, done.Exit, done.IssueID
 
 	if dryRun {
 		return fmt.Sprintf("would log completion for %s (exit=%s, issue=%s)",
@@ -3,15 +3,15 @@ package cmd
 import (
 	"os"
 	"os/exec"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/git"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
 
 // DefaultAgentEmailDomain is the default domain for agent git emails.
-const DefaultAgentEmailDomain = "whaletown.local"
+const DefaultAgentEmailDomain = config.DefaultAgentEmailDomain
 
 var commitCmd = &cobra.Command{
 	Use:   "commit [flags] [-- git-commit-args...]",
@@ -58,10 +58,7 @@ func runCommit(cmd *cobra.Command, args []string) error {
 	domain := DefaultAgentEmailDomain
 	townRoot, err := workspace.FindFromCwd()
 	if err == nil && townRoot != "" {
-		settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
-		if err == nil && settings.AgentEmailDomain != "" {
-			domain = settings.AgentEmailDomain
-		}
+		domain = config.LoadAgentEmailDomain(townRoot)
 	}
 
 	// Convert identity to git-friendly email
@@ -78,13 +75,7 @@ func runCommit(cmd *cobra.Command, args []string) error {
 // "whaletown/crew/jack" → "whaletown.crew.jack@domain"
 // "mayor/" → "mayor@domain"
 func identityToEmail(identity, domain string) string {
-	// Remove trailing slash if present
-	identity = strings.TrimSuffix(identity, "/")
-
-	// Replace slashes with dots for email local part
-	localPart := strings.ReplaceAll(identity, "/", ".")
-
-	return localPart + "@" + domain
+	return git.AgentEmail(identity, domain)
 }
 
 // runGitCommit executes git commit with optional identity override.
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/config"
 	"github.com/speaker20/whaletown/internal/style"
 	"github.com/speaker20/whaletown/internal/workspace"
@@ -29,7 +30,8 @@ Commands:
   wt config agent get <name>         Show agent configuration
   wt config agent set <name> <cmd>   Set custom agent command
   wt config agent remove <name>      Remove custom agent
-  wt config default-agent [name]     Get or set default agent`,
+  wt config default-agent [name]     Get or set default agent
+  wt config session-backend [name]   Get or set where agent sessions run`,
 }
 
 // Agent subcommands
@@ -140,6 +142,26 @@ Examples:
 	RunE: runConfigAgentEmailDomain,
 }
 
+var configSessionBackendCmd = &cobra.Command{
+	Use:   "session-backend [tmux|pty]",
+	Short: "Get or set the session backend",
+	Long: `Get or set where agent sessions run.
+
+  tmux  Each agent runs in a tmux session (default)
+  pty   Agents run headless under the built-in pty supervisor, for
+        machines without tmux (see 'wt pty')
+
+The WT_SESSION_BACKEND environment variable overrides this setting.
+Restart running agents after changing it: sessions on the old backend
+are not moved.
+
+Examples:
+  wt config session-backend        # Show current backend
+  wt config session-backend pty    # Run agents headless`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigSessionBackend,
+}
+
 // Flags
 var (
 	configAgentListJSON bool
@@ -513,6 +535,48 @@ func runConfigAgentEmailDomain(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runConfigSessionBackend(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("finding town root: %w", err)
+	}
+
+	// Load town settings
+	settingsPath := config.TownSettingsPath(townRoot)
+	townSettings, err := config.LoadOrCreateTownSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("loading town settings: %w", err)
+	}
+
+	if len(args) == 0 {
+		// Show current backend
+		fmt.Printf("Session backend: %s\n", style.Bold.Render(backend.Name(townRoot)))
+		if env := os.Getenv(backend.EnvSessionBackend); env != "" {
+			fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf("(from %s; town setting: %q)", backend.EnvSessionBackend, townSettings.SessionBackend)))
+		}
+		return nil
+	}
+
+	// Set new backend
+	name := args[0]
+	if err := backend.Validate(name); err != nil {
+		return err
+	}
+	townSettings.SessionBackend = name
+	if name == backend.Tmux {
+		townSettings.SessionBackend = "" // The default
+	}
+
+	// Save settings
+	if err := config.SaveTownSettings(settingsPath, townSettings); err != nil {
+		return fmt.Errorf("saving town settings: %w", err)
+	}
+
+	fmt.Printf("Session backend set to '%s'\n", style.Bold.Render(name))
+	fmt.Println("Restart running agents to move them to the new backend.")
+	return nil
+}
+
 func init() {
 	// Add flags
 	configAgentListCmd.Flags().BoolVar(&configAgentListJSON, "json", false, "Output as JSON")
@@ -532,6 +596,7 @@ func init() {
 	configCmd.AddCommand(configAgentCmd)
 	configCmd.AddCommand(configDefaultAgentCmd)
 	configCmd.AddCommand(configAgentEmailDomainCmd)
+	configCmd.AddCommand(configSessionBackendCmd)
 
 	// Register with root
 	rootCmd.AddCommand(configCmd)
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/base32"
 	"encoding/json"
@@ -17,6 +18,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/speaker20/whaletown/internal/beads"
+	"github.com/speaker20/whaletown/internal/beadsdb"
+	"github.com/speaker20/whaletown/internal/federation"
 	"github.com/speaker20/whaletown/internal/style"
 	"github.com/speaker20/whaletown/internal/tui/convoy"
 	"github.com/speaker20/whaletown/internal/workspace"
@@ -61,18 +64,22 @@ func looksLikeIssueID(s string) bool {
 
 // Convoy command flags
 var (
-	convoyMolecule     string
-	convoyNotify       string
-	convoyOwner        string
-	convoyStatusJSON   bool
-	convoyListJSON     bool
-	convoyListStatus   string
-	convoyListAll      bool
-	convoyListTree     bool
-	convoyInteractive  bool
-	convoyStrandedJSON bool
-	convoyCloseReason  string
-	convoyCloseNotify  string
+	convoyMolecule      string
+	convoyNotify        string
+	convoyOwner         string
+	convoyDue           string
+	convoyStatusJSON    bool
+	convoyListJSON      bool
+	convoyListStatus    string
+	convoyListAll       bool
+	convoyListTree      bool
+	convoyInteractive   bool
+	convoyStrandedJSON  bool
+	convoyOverdue       bool
+	convoyCloseReason   string
+	convoyCloseNotify   string
+	convoyAbandonReason string
+	convoyAbandonNotify string
 )
 
 var convoyCmd = &cobra.Command{
@@ -111,6 +118,7 @@ COMMANDS:
   create    Create a convoy tracking specified issues
   add       Add issues to an existing convoy (reopens if closed)
   close     Close a convoy (manually, regardless of tracked issue status)
+  abandon   Give up on a convoy without completing it
   status    Show convoy progress, tracked issues, and active workers
   list      List convoys (the dashboard view)`,
 }
@@ -127,12 +135,19 @@ The --owner flag specifies who requested the convoy (receives completion
 notification by default). If not specified, defaults to created_by.
 The --notify flag adds additional subscribers beyond the owner.
 
+The --due flag sets a deadline: a date (end of that day), an RFC 3339
+timestamp, or a duration from now such as 36h, 3d or 2w. When an open
+convoy passes its deadline the daemon raises a high-severity escalation
+through the configured escalation routes.
+
 Examples:
   wt convoy create "Deploy v2.0" gt-abc bd-xyz
   wt convoy create "Release prep" gt-abc --notify           # defaults to mayor/
   wt convoy create "Release prep" gt-abc --notify ops/      # notify ops/
   wt convoy create "Feature rollout" gt-a gt-b --owner mayor/ --notify ops/
-  wt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release`,
+  wt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release
+  wt convoy create "Sprint work" gt-abc --due=2026-01-15
+  wt convoy create "Hotfix" gt-abc --due=36h --owner mayor/`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runConvoyCreate,
 }
@@ -143,7 +158,16 @@ var convoyStatusCmd = &cobra.Command{
 	Long: `Show detailed status for a convoy.
 
 Displays convoy metadata, tracked issues, and completion progress.
-Without an ID, shows status of all active convoys.`,
+Without an ID, shows status of all active convoys.
+
+With --json, the output includes the deadline, whether the convoy is
+overdue, its lifecycle state (open, closed or abandoned) and a burn-down:
+the number of tracked issues completed and remaining after each close.
+
+Examples:
+  wt convoy status hq-cv-abc
+  wt convoy status 1
+  wt convoy status hq-cv-abc --json`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runConvoyStatus,
 }
@@ -203,8 +227,11 @@ A convoy is "stranded" when:
 Use this to detect convoys that need feeding. The Deacon patrol runs this
 periodically and dispatches dogs to feed stranded convoys.
 
+With --overdue, lists open convoys that have passed their deadline instead.
+
 Examples:
   wt convoy stranded              # Show stranded convoys
+  wt convoy stranded --overdue    # Show convoys past their deadline
   wt convoy stranded --json       # Machine-readable output for automation`,
 	RunE: runConvoyStranded,
 }
@@ -229,12 +256,32 @@ Examples:
 	RunE: runConvoyClose,
 }
 
+var convoyAbandonCmd = &cobra.Command{
+	Use:   "abandon <convoy-id>",
+	Short: "Abandon a convoy without completing it",
+	Long: `Abandon a convoy: close it as given up on rather than landed.
+
+Abandoned is a terminal state distinct from completed. The convoy is closed
+and the reason is recorded, the owner and subscribers are told it was
+abandoned (not that it landed), and status and list show it as abandoned.
+Tracked issues are left as they are.
+
+Adding issues to an abandoned convoy reopens it, like any closed convoy.
+
+Examples:
+  wt convoy abandon hq-cv-abc --reason="superseded by hq-cv-xyz"
+  wt convoy abandon hq-cv-abc --reason="requirements dropped" --notify ops/`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConvoyAbandon,
+}
+
 func init() {
 	// Create flags
 	convoyCreateCmd.Flags().StringVar(&convoyMolecule, "molecule", "", "Associated molecule ID")
 	convoyCreateCmd.Flags().StringVar(&convoyOwner, "owner", "", "Owner who requested convoy (gets completion notification)")
 	convoyCreateCmd.Flags().StringVar(&convoyNotify, "notify", "", "Additional address to notify on completion (default: mayor/ if flag used without value)")
 	convoyCreateCmd.Flags().Lookup("notify").NoOptDefVal = "mayor/"
+	convoyCreateCmd.Flags().StringVar(&convoyDue, "due", "", "Deadline: YYYY-MM-DD, RFC 3339, or a duration like 3d")
 
 	// Status flags
 	convoyStatusCmd.Flags().BoolVar(&convoyStatusJSON, "json", false, "Output as JSON")
@@ -250,11 +297,17 @@ func init() {
 
 	// Stranded flags
 	convoyStrandedCmd.Flags().BoolVar(&convoyStrandedJSON, "json", false, "Output as JSON")
+	convoyStrandedCmd.Flags().BoolVar(&convoyOverdue, "overdue", false, "List convoys past their deadline")
 
 	// Close flags
 	convoyCloseCmd.Flags().StringVar(&convoyCloseReason, "reason", "", "Reason for closing the convoy")
 	convoyCloseCmd.Flags().StringVar(&convoyCloseNotify, "notify", "", "Agent to notify on close (e.g., mayor/)")
 
+	// Abandon flags
+	convoyAbandonCmd.Flags().StringVar(&convoyAbandonReason, "reason", "", "Why the convoy is being abandoned (required)")
+	convoyAbandonCmd.Flags().StringVar(&convoyAbandonNotify, "notify", "", "Additional address to notify (owner and subscribers are always notified)")
+	_ = convoyAbandonCmd.MarkFlagRequired("reason")
+
 	// Add subcommands
 	convoyCmd.AddCommand(convoyCreateCmd)
 	convoyCmd.AddCommand(convoyStatusCmd)
@@ -263,6 +316,7 @@ func init() {
 	convoyCmd.AddCommand(convoyCheckCmd)
 	convoyCmd.AddCommand(convoyStrandedCmd)
 	convoyCmd.AddCommand(convoyCloseCmd)
+	convoyCmd.AddCommand(convoyAbandonCmd)
 
 	rootCmd.AddCommand(convoyCmd)
 }
@@ -282,7 +336,7 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 
 	// If first arg looks like an issue ID (has beads prefix), treat all args as issues
 	// and auto-generate a name from the first issue's title
-	if looksLikeIssueID(name) {
+	if looksLikeIssueID(name) || federation.IsRef(name) {
 		trackedIssues = args // All args are issue IDs
 		// Get the first issue's title to use as convoy name
 		if details := getIssueDetails(args[0]); details != nil && details.Title != "" {
@@ -292,22 +346,29 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var dueAt time.Time
+	if convoyDue != "" {
+		var err error
+		if dueAt, err = beads.ParseConvoyDue(convoyDue, time.Now()); err != nil {
+			return err
+		}
+		if !dueAt.After(time.Now()) {
+			return fmt.Errorf("due date %s is in the past", dueAt.Format(time.RFC3339))
+		}
+	}
+
 	townBeads, err := getTownBeadsDir()
 	if err != nil {
 		return err
 	}
 
 	// Create convoy issue in town beads
-	description := fmt.Sprintf("Convoy tracking %d issues", len(trackedIssues))
-	if convoyOwner != "" {
-		description += fmt.Sprintf("\nOwner: %s", convoyOwner)
-	}
-	if convoyNotify != "" {
-		description += fmt.Sprintf("\nNotify: %s", convoyNotify)
-	}
-	if convoyMolecule != "" {
-		description += fmt.Sprintf("\nMolecule: %s", convoyMolecule)
-	}
+	description := beads.SetConvoyFields(fmt.Sprintf("Convoy tracking %d issues", len(trackedIssues)), &beads.ConvoyFields{
+		Owner:    convoyOwner,
+		Notify:   convoyNotify,
+		Molecule: convoyMolecule,
+		DueAt:    dueAt,
+	})
 
 	// Generate convoy ID with cv- prefix
 	convoyID := fmt.Sprintf("hq-cv-%s", generateShortID())
@@ -341,7 +402,7 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	trackedCount := 0
 	for _, issueID := range trackedIssues {
 		// Use --type=tracks for non-blocking tracking relation
-		depArgs := []string{"dep", "add", convoyID, issueID, "--type=tracks"}
+		depArgs := []string{"dep", "add", convoyID, trackingTarget(issueID), "--type=tracks"}
 		depCmd := exec.Command("bd", depArgs...)
 		depCmd.Dir = townBeads
 		var depStderr bytes.Buffer
@@ -374,6 +435,9 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if convoyMolecule != "" {
 		fmt.Printf("  Molecule: %s\n", convoyMolecule)
 	}
+	if !dueAt.IsZero() {
+		fmt.Printf("  Due:      %s\n", dueAt.Local().Format("2006-01-02 15:04 MST"))
+	}
 
 	fmt.Printf("\n  %s\n", style.Dim.Render("Convoy auto-closes when all tracked issues complete"))
 
@@ -401,10 +465,11 @@ func runConvoyAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	var convoys []struct {
-		ID     string `json:"id"`
-		Title  string `json:"title"`
-		Status string `json:"status"`
-		Type   string `json:"issue_type"`
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Status      string `json:"status"`
+		Type        string `json:"issue_type"`
+		Description string `json:"description"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
 		return fmt.Errorf("parsing convoy data: %w", err)
@@ -421,10 +486,14 @@ func runConvoyAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("'%s' is not a convoy (type: %s)", convoyID, convoy.Type)
 	}
 
-	// If convoy is closed, reopen it
+	// If convoy is closed, reopen it (an abandoned convoy is no longer abandoned)
 	reopened := false
 	if convoy.Status == "closed" {
 		reopenArgs := []string{"update", convoyID, "--status=open"}
+		if fields := beads.ParseConvoyFields(convoy.Description); !fields.AbandonedAt.IsZero() {
+			fields.AbandonedAt, fields.AbandonReason = time.Time{}, ""
+			reopenArgs = append(reopenArgs, "--description="+beads.SetConvoyFields(convoy.Description, fields))
+		}
 		reopenCmd := exec.Command("bd", reopenArgs...)
 		reopenCmd.Dir = townBeads
 		if err := reopenCmd.Run(); err != nil {
@@ -437,7 +506,7 @@ func runConvoyAdd(cmd *cobra.Command, args []string) error {
 	// Add 'tracks' relations for each issue
 	addedCount := 0
 	for _, issueID := range issuesToAdd {
-		depArgs := []string{"dep", "add", convoyID, issueID, "--type=tracks"}
+		depArgs := []string{"dep", "add", convoyID, trackingTarget(issueID), "--type=tracks"}
 		depCmd := exec.Command("bd", depArgs...)
 		depCmd.Dir = townBeads
 		var depStderr bytes.Buffer
@@ -581,6 +650,92 @@ func sendCloseNotification(addr, convoyID, title, reason string) {
 	}
 }
 
+func runConvoyAbandon(cmd *cobra.Command, args []string) error {
+	convoyID := args[0]
+	reason := strings.TrimSpace(convoyAbandonReason)
+	if reason == "" {
+		return fmt.Errorf("--reason is required")
+	}
+
+	townBeads, err := getTownBeadsDir()
+	if err != nil {
+		return err
+	}
+
+	showCmd := exec.Command("bd", "show", convoyID, "--json")
+	showCmd.Dir = townBeads
+	var stdout bytes.Buffer
+	showCmd.Stdout = &stdout
+
+	if err := showCmd.Run(); err != nil {
+		return fmt.Errorf("convoy '%s' not found", convoyID)
+	}
+
+	var convoys []struct {
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Status      string `json:"status"`
+		Type        string `json:"issue_type"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+		return fmt.Errorf("parsing convoy data: %w", err)
+	}
+	if len(convoys) == 0 {
+		return fmt.Errorf("convoy '%s' not found", convoyID)
+	}
+	convoy := convoys[0]
+	if convoy.Type != "convoy" {
+		return fmt.Errorf("'%s' is not a convoy (type: %s)", convoyID, convoy.Type)
+	}
+
+	fields := beads.ParseConvoyFields(convoy.Description)
+	switch fields.ConvoyState(convoy.Status) {
+	case beads.ConvoyAbandoned:
+		fmt.Printf("%s Convoy %s is already abandoned\n", style.Dim.Render("○"), convoyID)
+		return nil
+	case beads.ConvoyClosed:
+		return fmt.Errorf("convoy %s has already closed; reopen it with 'wt convoy add' first", convoyID)
+	}
+
+	// Record the abandonment, then close
+	fields.AbandonedAt = time.Now()
+	fields.AbandonReason = reason
+	updateCmd := exec.Command("bd", "update", convoyID, "--description="+beads.SetConvoyFields(convoy.Description, fields))
+	updateCmd.Dir = townBeads
+	if out, err := updateCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("recording abandonment: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+
+	closeCmd := exec.Command("bd", "close", convoyID, "-r", "Abandoned: "+reason)
+	closeCmd.Dir = townBeads
+	if err := closeCmd.Run(); err != nil {
+		return fmt.Errorf("closing convoy: %w", err)
+	}
+
+	fmt.Printf("%s Abandoned convoy 🚚 %s: %s\n", style.Bold.Render("✗"), convoyID, convoy.Title)
+	fmt.Printf("  Reason: %s\n", reason)
+
+	// Tell the owner and subscribers it won't land
+	notified := make(map[string]bool)
+	for _, addr := range []string{fields.Owner, fields.Notify, convoyAbandonNotify} {
+		if addr == "" || notified[addr] {
+			continue
+		}
+		notified[addr] = true
+		subject := fmt.Sprintf("🚚 Convoy abandoned: %s", convoy.Title)
+		body := fmt.Sprintf("Convoy %s was abandoned without completing.\n\nReason: %s", convoyID, reason)
+		mailCmd := exec.Command("wt", "mail", "send", addr, "-s", subject, "-m", body)
+		if err := mailCmd.Run(); err != nil {
+			style.PrintWarning("couldn't notify %s: %v", addr, err)
+		} else {
+			fmt.Printf("  Notified: %s\n", addr)
+		}
+	}
+
+	return nil
+}
+
 // strandedConvoyInfo holds info about a stranded convoy.
 type strandedConvoyInfo struct {
 	ID          string   `json:"id"`
@@ -602,6 +757,10 @@ func runConvoyStranded(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if convoyOverdue {
+		return showOverdueConvoys(townBeads)
+	}
+
 	stranded, err := findStrandedConvoys(townBeads)
 	if err != nil {
 		return err
@@ -636,6 +795,82 @@ func runConvoyStranded(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// overdueConvoyInfo holds info about a convoy past its deadline.
+type overdueConvoyInfo struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	DueAt      time.Time `json:"due_at"`
+	OverdueBy  string    `json:"overdue_by"`
+	Escalation string    `json:"escalation,omitempty"`
+}
+
+func showOverdueConvoys(townBeads string) error {
+	overdue, err := findOverdueConvoys(townBeads, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if convoyStrandedJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(overdue)
+	}
+
+	if len(overdue) == 0 {
+		fmt.Println("No overdue convoys.")
+		return nil
+	}
+
+	fmt.Printf("%s Found %d overdue convoy(s):\n\n", style.Warning.Render("⚠"), len(overdue))
+	for _, o := range overdue {
+		fmt.Printf("  🚚 %s: %s\n", o.ID, o.Title)
+		fmt.Printf("     Due %s (%s ago)\n", o.DueAt.Local().Format("2006-01-02 15:04"), o.OverdueBy)
+		if o.Escalation != "" {
+			fmt.Printf("     Escalated: %s\n", o.Escalation)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// findOverdueConvoys finds open convoys that have passed their deadline.
+func findOverdueConvoys(townBeads string, now time.Time) ([]overdueConvoyInfo, error) {
+	listCmd := exec.Command("bd", "list", "--type=convoy", "--status=open", "--json")
+	listCmd.Dir = townBeads
+	var stdout bytes.Buffer
+	listCmd.Stdout = &stdout
+
+	if err := listCmd.Run(); err != nil {
+		return nil, fmt.Errorf("listing convoys: %w", err)
+	}
+
+	var convoys []struct {
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Status      string `json:"status"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+		return nil, fmt.Errorf("parsing convoy list: %w", err)
+	}
+
+	var overdue []overdueConvoyInfo
+	for _, c := range convoys {
+		fields := beads.ParseConvoyFields(c.Description)
+		if !fields.IsOverdue(c.Status, now) {
+			continue
+		}
+		overdue = append(overdue, overdueConvoyInfo{
+			ID:         c.ID,
+			Title:      c.Title,
+			DueAt:      fields.DueAt,
+			OverdueBy:  formatWorkerAge(now.Sub(fields.DueAt)),
+			Escalation: fields.OverdueEscalation,
+		})
+	}
+	return overdue, nil
+}
+
 // findStrandedConvoys finds convoys with ready work but no workers.
 func findStrandedConvoys(townBeads string) ([]strandedConvoyInfo, error) {
 	var stranded []strandedConvoyInfo
@@ -924,30 +1159,52 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 
 	tracked := getTrackedIssues(townBeads, convoyID)
 
-	// Count completed
+	// Count completed, noting when each closed for the burn-down
 	completed := 0
+	var closedAt []time.Time
 	for _, t := range tracked {
 		if t.Status == "closed" {
 			completed++
+			closedAt = append(closedAt, parseBeadsTimestamp(t.ClosedAt))
 		}
 	}
 
+	fields := beads.ParseConvoyFields(convoy.Description)
+	state := fields.ConvoyState(convoy.Status)
+	overdue := fields.IsOverdue(convoy.Status, time.Now())
+
 	if convoyStatusJSON {
 		type jsonStatus struct {
-			ID        string             `json:"id"`
-			Title     string             `json:"title"`
-			Status    string             `json:"status"`
-			Tracked   []trackedIssueInfo `json:"tracked"`
-			Completed int                `json:"completed"`
-			Total     int                `json:"total"`
+			ID            string                `json:"id"`
+			Title         string                `json:"title"`
+			Status        string                `json:"status"`
+			State         string                `json:"state"`
+			Tracked       []trackedIssueInfo    `json:"tracked"`
+			Completed     int                   `json:"completed"`
+			Total         int                   `json:"total"`
+			DueAt         *time.Time            `json:"due_at,omitempty"`
+			Overdue       bool                  `json:"overdue"`
+			AbandonedAt   *time.Time            `json:"abandoned_at,omitempty"`
+			AbandonReason string                `json:"abandon_reason,omitempty"`
+			BurnDown      []beads.BurnDownPoint `json:"burn_down"`
 		}
 		out := jsonStatus{
-			ID:        convoy.ID,
-			Title:     convoy.Title,
-			Status:    convoy.Status,
-			Tracked:   tracked,
-			Completed: completed,
-			Total:     len(tracked),
+			ID:            convoy.ID,
+			Title:         convoy.Title,
+			Status:        convoy.Status,
+			State:         state,
+			Tracked:       tracked,
+			Completed:     completed,
+			Total:         len(tracked),
+			Overdue:       overdue,
+			AbandonReason: fields.AbandonReason,
+			BurnDown:      beads.ConvoyBurnDown(parseBeadsTimestamp(convoy.CreatedAt), len(tracked), closedAt),
+		}
+		if !fields.DueAt.IsZero() {
+			out.DueAt = &fields.DueAt
+		}
+		if !fields.AbandonedAt.IsZero() {
+			out.AbandonedAt = &fields.AbandonedAt
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -956,12 +1213,22 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 
 	// Human-readable output
 	fmt.Printf("🚚 %s %s\n\n", style.Bold.Render(convoy.ID+":"), convoy.Title)
-	fmt.Printf("  Status:    %s\n", formatConvoyStatus(convoy.Status))
+	fmt.Printf("  Status:    %s\n", formatConvoyStatus(state))
 	fmt.Printf("  Progress:  %d/%d completed\n", completed, len(tracked))
 	fmt.Printf("  Created:   %s\n", convoy.CreatedAt)
+	if !fields.DueAt.IsZero() {
+		due := fields.DueAt.Local().Format("2006-01-02 15:04 MST")
+		if overdue {
+			due += " " + style.Error.Render("(overdue "+formatWorkerAge(time.Since(fields.DueAt))+")")
+		}
+		fmt.Printf("  Due:       %s\n", due)
+	}
 	if convoy.ClosedAt != "" {
 		fmt.Printf("  Closed:    %s\n", convoy.ClosedAt)
 	}
+	if state == beads.ConvoyAbandoned {
+		fmt.Printf("  Abandoned: %s\n", fields.AbandonReason)
+	}
 
 	if len(tracked) > 0 {
 		fmt.Printf("\n  %s\n", style.Bold.Render("Tracked Issues:"))
@@ -1065,15 +1332,16 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("listing convoys: %w", err)
 	}
 
-	var convoys []struct {
-		ID        string `json:"id"`
-		Title     string `json:"title"`
-		Status    string `json:"status"`
-		CreatedAt string `json:"created_at"`
-	}
+	var convoys []convoyListItem
 	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
 		return fmt.Errorf("parsing convoy list: %w", err)
 	}
+	now := time.Now()
+	for i := range convoys {
+		fields := beads.ParseConvoyFields(convoys[i].Description)
+		convoys[i].State = fields.ConvoyState(convoys[i].Status)
+		convoys[i].Overdue = fields.IsOverdue(convoys[i].Status, now)
+	}
 
 	if convoyListJSON {
 		enc := json.NewEncoder(os.Stdout)
@@ -1094,7 +1362,10 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("%s\n\n", style.Bold.Render("Convoys"))
 	for i, c := range convoys {
-		status := formatConvoyStatus(c.Status)
+		status := formatConvoyStatus(c.State)
+		if c.Overdue {
+			status += " " + style.Error.Render("overdue")
+		}
 		fmt.Printf("  %d. 🚚 %s: %s %s\n", i+1, c.ID, c.Title, status)
 	}
 	fmt.Printf("\nUse 'wt convoy status <id>' or 'wt convoy status <n>' for detailed view.\n")
@@ -1102,13 +1373,19 @@ func runConvoyList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// convoyListItem is a convoy as listed by wt convoy list.
+type convoyListItem struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Status      string `json:"status"`
+	State       string `json:"state"` // open, closed or abandoned
+	Overdue     bool   `json:"overdue,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	Description string `json:"description,omitempty"`
+}
+
 // printConvoyTree displays convoys with their child issues in a tree format.
-func printConvoyTree(townBeads string, convoys []struct {
-	ID        string `json:"id"`
-	Title     string `json:"title"`
-	Status    string `json:"status"`
-	CreatedAt string `json:"created_at"`
-}) error {
+func printConvoyTree(townBeads string, convoys []convoyListItem) error {
 	for _, c := range convoys {
 		// Get tracked issues for this convoy
 		tracked := getTrackedIssues(townBeads, c.ID)
@@ -1163,6 +1440,8 @@ func formatConvoyStatus(status string) string {
 		return style.Warning.Render("●")
 	case "closed":
 		return style.Success.Render("✓")
+	case beads.ConvoyAbandoned:
+		return style.Dim.Render("✗ abandoned")
 	case "in_progress":
 		return style.Info.Render("→")
 	default:
@@ -1180,58 +1459,44 @@ type trackedIssueInfo struct {
 	Assignee  string `json:"assignee,omitempty"`   // Assigned agent (e.g., whaletown/polecats/goose)
 	Worker    string `json:"worker,omitempty"`     // Worker currently assigned (e.g., whaletown/nux)
 	WorkerAge string `json:"worker_age,omitempty"` // How long worker has been on this issue
+	ClosedAt  string `json:"closed_at,omitempty"`  // When the issue closed
 }
 
 // getTrackedIssues queries SQLite directly to get issues tracked by a convoy.
 // This is needed because bd dep list doesn't properly show cross-rig external dependencies.
 // Uses batched lookup to avoid N+1 subprocess calls.
 func getTrackedIssues(townBeads, convoyID string) []trackedIssueInfo {
-	dbPath := filepath.Join(townBeads, "beads.db")
-
-	// Query tracked dependencies from SQLite
-	// Escape single quotes to prevent SQL injection
-	safeConvoyID := strings.ReplaceAll(convoyID, "'", "''")
-	queryCmd := exec.Command("sqlite3", "-json", dbPath,
-		fmt.Sprintf(`SELECT depends_on_id, type FROM dependencies WHERE issue_id = '%s' AND type = 'tracks'`, safeConvoyID))
-
-	var stdout bytes.Buffer
-	queryCmd.Stdout = &stdout
-	if err := queryCmd.Run(); err != nil {
+	deps, err := beadsdb.Open(townBeads).TrackedIssues(context.Background(), []string{convoyID})
+	if err != nil {
 		return nil
 	}
 
-	var deps []struct {
-		DependsOnID string `json:"depends_on_id"`
-		Type        string `json:"type"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
-		return nil
+	// First pass: collect all issue IDs (external refs come back normalized)
+	issueIDs := make([]string, 0, len(deps[convoyID]))
+	idToDepType := make(map[string]string)
+	for _, t := range deps[convoyID] {
+		issueIDs = append(issueIDs, t.ID)
+		idToDepType[t.ID] = "tracks"
 	}
 
-	// First pass: collect all issue IDs (normalized from external refs)
-	issueIDs := make([]string, 0, len(deps))
-	idToDepType := make(map[string]string)
-	for _, dep := range deps {
-		issueID := dep.DependsOnID
-
-		// Handle external reference format: external:rig:issue-id
-		if strings.HasPrefix(issueID, "external:") {
-			parts := strings.SplitN(issueID, ":", 3)
-			if len(parts) == 3 {
-				issueID = parts[2] // Extract the actual issue ID
-			}
+	// Local issues come from one batch call; hop:// and beads:// references
+	// are looked up in the peer towns they name
+	var localIDs, remoteRefs []string
+	for _, id := range issueIDs {
+		if federation.IsRef(id) {
+			remoteRefs = append(remoteRefs, id)
+		} else {
+			localIDs = append(localIDs, id)
 		}
-
-		issueIDs = append(issueIDs, issueID)
-		idToDepType[issueID] = dep.Type
 	}
-
-	// Single batch call to get all issue details
-	detailsMap := getIssueDetailsBatch(issueIDs)
+	detailsMap := getIssueDetailsBatch(localIDs)
+	for ref, details := range getRemoteIssueDetails(filepath.Dir(townBeads), remoteRefs) {
+		detailsMap[ref] = details
+	}
 
 	// Get workers for these issues (only for non-closed issues)
-	openIssueIDs := make([]string, 0, len(issueIDs))
-	for _, id := range issueIDs {
+	openIssueIDs := make([]string, 0, len(localIDs))
+	for _, id := range localIDs {
 		if details, ok := detailsMap[id]; ok && details.Status != "closed" {
 			openIssueIDs = append(openIssueIDs, id)
 		}
@@ -1251,6 +1516,7 @@ func getTrackedIssues(townBeads, convoyID string) []trackedIssueInfo {
 			info.Status = details.Status
 			info.IssueType = details.IssueType
 			info.Assignee = details.Assignee
+			info.ClosedAt = details.ClosedAt
 		} else {
 			info.Title = "(external)"
 			info.Status = "unknown"
@@ -1275,6 +1541,7 @@ type issueDetails struct {
 	Status    string
 	IssueType string
 	Assignee  string
+	ClosedAt  string
 }
 
 // getIssueDetailsBatch fetches details for multiple issues in a single bd show call.
@@ -1311,6 +1578,7 @@ func getIssueDetailsBatch(issueIDs []string) map[string]*issueDetails {
 		Status    string `json:"status"`
 		IssueType string `json:"issue_type"`
 		Assignee  string `json:"assignee"`
+		ClosedAt  string `json:"closed_at"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
 		return result
@@ -1323,6 +1591,7 @@ func getIssueDetailsBatch(issueIDs []string) map[string]*issueDetails {
 			Status:    issue.Status,
 			IssueType: issue.IssueType,
 			Assignee:  issue.Assignee,
+			ClosedAt:  issue.ClosedAt,
 		}
 	}
 
@@ -1352,6 +1621,7 @@ func getIssueDetails(issueID string) *issueDetails {
 		Status    string `json:"status"`
 		IssueType string `json:"issue_type"`
 		Assignee  string `json:"assignee"`
+		ClosedAt  string `json:"closed_at"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil || len(issues) == 0 {
 		return nil
@@ -1363,7 +1633,46 @@ func getIssueDetails(issueID string) *issueDetails {
 		Status:    issues[0].Status,
 		IssueType: issues[0].IssueType,
 		Assignee:  issues[0].Assignee,
+		ClosedAt:  issues[0].ClosedAt,
+	}
+}
+
+// trackingTarget returns what a convoy's tracks dependency points at.
+// Federated references are stored as external refs so bd accepts them.
+func trackingTarget(issueID string) string {
+	if federation.IsRef(issueID) {
+		return "external:federation:" + issueID
 	}
+	return issueID
+}
+
+// getRemoteIssueDetails looks up federated references in peer towns.
+// References that can't be resolved are omitted from the map.
+func getRemoteIssueDetails(townRoot string, refs []string) map[string]*issueDetails {
+	result := make(map[string]*issueDetails)
+	if len(refs) == 0 {
+		return result
+	}
+	registry, err := federation.Load(townRoot)
+	if err != nil {
+		return result
+	}
+	defer func() { _ = registry.Close() }()
+
+	for _, ref := range refs {
+		issue, err := registry.Resolve(ref)
+		if err != nil {
+			continue
+		}
+		result[ref] = &issueDetails{
+			ID:        ref,
+			Title:     fmt.Sprintf("%s [%s]", issue.Title, issue.Peer),
+			Status:    issue.Status,
+			IssueType: issue.IssueType,
+			Assignee:  issue.Assignee,
+		}
+	}
+	return result
 }
 
 // workerInfo holds info about a worker assigned to an issue.
@@ -1391,12 +1700,12 @@ func getWorkersForIssues(issueIDs []string) map[string]*workerInfo {
 
 	// Discover rigs with beads databases
 	rigDirs, _ := filepath.Glob(filepath.Join(townRoot, "*", "polecats"))
-	var beadsDBS []string
+	var beadsDBS []*beadsdb.DB
 	for _, polecatsDir := range rigDirs {
 		rigDir := filepath.Dir(polecatsDir)
-		beadsDB := filepath.Join(rigDir, "mayor", "rig", ".beads", "beads.db")
-		if _, err := os.Stat(beadsDB); err == nil {
-			beadsDBS = append(beadsDBS, beadsDB)
+		db := beadsdb.Open(filepath.Join(rigDir, "mayor", "rig", ".beads"))
+		if _, err := os.Stat(db.Path()); err == nil {
+			beadsDBS = append(beadsDBS, db)
 		}
 	}
 
@@ -1404,50 +1713,21 @@ func getWorkersForIssues(issueIDs []string) map[string]*workerInfo {
 		return result
 	}
 
-	// Build the IN clause with properly escaped issue IDs
-	var quotedIDs []string
-	for _, id := range issueIDs {
-		safeID := strings.ReplaceAll(id, "'", "''")
-		quotedIDs = append(quotedIDs, fmt.Sprintf("'%s'", safeID))
-	}
-	inClause := strings.Join(quotedIDs, ", ")
-
-	// Batch query: fetch all matching agents in one query per rig
-	query := fmt.Sprintf(
-		`SELECT id, hook_bead, last_activity FROM issues WHERE issue_type = 'agent' AND status = 'open' AND hook_bead IN (%s)`,
-		inClause)
-
-	// Query all rigs in parallel
-	type rigResult struct {
-		agents []struct {
-			ID           string `json:"id"`
-			HookBead     string `json:"hook_bead"`
-			LastActivity string `json:"last_activity"`
-		}
-	}
-
-	resultChan := make(chan rigResult, len(beadsDBS))
+	// Batch query: fetch all matching agents in one query per rig, all rigs in parallel
+	resultChan := make(chan []beadsdb.AgentActivity, len(beadsDBS))
 	var wg sync.WaitGroup
 
 	for _, beadsDB := range beadsDBS {
 		wg.Add(1)
-		go func(db string) {
+		go func(db *beadsdb.DB) {
 			defer wg.Done()
 
-			queryCmd := exec.Command("sqlite3", "-json", db, query)
-			var stdout bytes.Buffer
-			queryCmd.Stdout = &stdout
-			if err := queryCmd.Run(); err != nil {
-				resultChan <- rigResult{}
-				return
-			}
-
-			var rr rigResult
-			if err := json.Unmarshal(stdout.Bytes(), &rr.agents); err != nil {
-				resultChan <- rigResult{}
+			agents, err := db.AgentActivity(context.Background(), issueIDs)
+			if err != nil {
+				resultChan <- nil
 				return
 			}
-			resultChan <- rr
+			resultChan <- agents
 		}(beadsDB)
 	}
 
@@ -1458,8 +1738,8 @@ func getWorkersForIssues(issueIDs []string) map[string]*workerInfo {
 	}()
 
 	// Collect results from all rigs
-	for rr := range resultChan {
-		for _, agent := range rr.agents {
+	for agents := range resultChan {
+		for _, agent := range agents {
 			// Skip if we already found a worker for this issue
 			if _, ok := result[agent.HookBead]; ok {
 				continue
@@ -1473,10 +1753,8 @@ func getWorkersForIssues(issueIDs []string) map[string]*workerInfo {
 
 			// Calculate age from last_activity
 			age := ""
-			if agent.LastActivity != "" {
-				if t, err := time.Parse(time.RFC3339, agent.LastActivity); err == nil {
-					age = formatWorkerAge(time.Since(t))
-				}
+			if !agent.LastActivity.IsZero() {
+				age = formatWorkerAge(time.Since(agent.LastActivity))
 			}
 
 			result[agent.HookBead] = &workerInfo{
@@ -7,7 +7,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -15,6 +14,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/speaker20/whaletown/internal/config"
 	"github.com/speaker20/whaletown/internal/constants"
+	"github.com/speaker20/whaletown/internal/costs"
+	"github.com/speaker20/whaletown/internal/runtime"
 	"github.com/speaker20/whaletown/internal/style"
 	"github.com/speaker20/whaletown/internal/tmux"
 	"github.com/speaker20/whaletown/internal/workspace"
@@ -26,6 +27,7 @@ var (
 	costsWeek    bool
 	costsByRole  bool
 	costsByRig   bool
+	costsByBead  bool
 	costsVerbose bool
 
 	// Record subcommand flags
@@ -44,22 +46,23 @@ var (
 var costsCmd = &cobra.Command{
 	Use:     "costs",
 	GroupID: GroupDiag,
-	Short:   "Show costs for running Claude sessions [DISABLED]",
-	Long: `Display costs for Claude Code sessions in Whale Town.
+	Short:   "Show token usage and costs for agent sessions",
+	Long: `Display token usage and costs for agent sessions in Whale Town.
 
-⚠️  COST TRACKING IS CURRENTLY DISABLED
+Costs are computed from the runtime's session transcripts, which record
+per-message token usage. Sessions are found through the session_start
+events logged by wt prime, and attributed to rig, role, worker and the
+bead last slung to the agent.
 
-Claude Code displays costs in the TUI status bar, which cannot be captured
-via tmux. All sessions will show $0.00 until Claude Code exposes cost data
-through an API or environment variable.
+Prices are per million tokens and default to Claude list prices. Override
+them, or point at other transcript directories, in settings/costs.json:
 
-What we need from Claude Code:
-  - Stop hook env var (e.g., $CLAUDE_SESSION_COST)
-  - Or queryable file/API endpoint
-
-See: GH#24, gt-7awfj
-
-The infrastructure remains in place and will work once cost data is available.
+  {
+    "type": "costs",
+    "version": 1,
+    "prices": {"claude-sonnet-4": {"input": 3, "output": 15, "cache_write": 3.75, "cache_read": 0.3}},
+    "transcript_dirs": ["~/.claude/projects"]
+  }
 
 Examples:
   wt costs              # Live costs from running sessions
@@ -67,6 +70,7 @@ Examples:
   wt costs --week       # This week's costs from digest beads + today's wisps
   wt costs --by-role    # Breakdown by role (polecat, witness, etc.)
   wt costs --by-rig     # Breakdown by rig
+  wt costs --by-bead    # Breakdown by bead (work item)
   wt costs --json       # Output as JSON
 
 Subcommands:
@@ -81,15 +85,20 @@ var costsRecordCmd = &cobra.Command{
 	Long: `Record the final cost of a session as an ephemeral wisp.
 
 This command is intended to be called from a Claude Code Stop hook.
-It captures the final cost from the tmux session and creates an ephemeral
-event that is NOT exported to JSONL (avoiding log-in-database pollution).
+It prices the session's transcript (from the hook input, --session, or
+the session ID in the environment) and creates an ephemeral event that is
+NOT exported to JSONL (avoiding log-in-database pollution).
+
+Transcript totals are cumulative, so when a session is recorded more than
+once only its latest record is counted.
 
 Session cost wisps are aggregated daily by 'wt costs digest' into a single
 permanent "Cost Report YYYY-MM-DD" bead for audit purposes.
 
 Examples:
   wt costs record --session gt-whaletown-toast
-  wt costs record --session gt-whaletown-toast --work-item gt-abc123`,
+  wt costs record --session gt-whaletown-toast --work-item gt-abc123
+  wt costs record --session $CLAUDE_SESSION_ID`,
 	RunE: runCostsRecord,
 }
 
@@ -140,11 +149,12 @@ func init() {
 	costsCmd.Flags().BoolVar(&costsWeek, "week", false, "Show this week's total from session events")
 	costsCmd.Flags().BoolVar(&costsByRole, "by-role", false, "Show breakdown by role")
 	costsCmd.Flags().BoolVar(&costsByRig, "by-rig", false, "Show breakdown by rig")
+	costsCmd.Flags().BoolVar(&costsByBead, "by-bead", false, "Show breakdown by bead (work item)")
 	costsCmd.Flags().BoolVarP(&costsVerbose, "verbose", "v", false, "Show debug output for failures")
 
 	// Add record subcommand
 	costsCmd.AddCommand(costsRecordCmd)
-	costsRecordCmd.Flags().StringVar(&recordSession, "session", "", "Tmux session name to record")
+	costsRecordCmd.Flags().StringVar(&recordSession, "session", "", "Tmux session name or runtime session ID to record")
 	costsRecordCmd.Flags().StringVar(&recordWorkItem, "work-item", "", "Work item ID (bead) for attribution")
 
 	// Add digest subcommand
@@ -160,41 +170,45 @@ func init() {
 
 // SessionCost represents cost info for a single session.
 type SessionCost struct {
-	Session string  `json:"session"`
-	Role    string  `json:"role"`
-	Rig     string  `json:"rig,omitempty"`
-	Worker  string  `json:"worker,omitempty"`
-	Cost    float64 `json:"cost_usd"`
-	Running bool    `json:"running"`
+	Session      string       `json:"session"`
+	Role         string       `json:"role"`
+	Rig          string       `json:"rig,omitempty"`
+	Worker       string       `json:"worker,omitempty"`
+	TranscriptID string       `json:"transcript_id,omitempty"`
+	WorkItem     string       `json:"work_item,omitempty"`
+	Tokens       *costs.Usage `json:"tokens,omitempty"`
+	Cost         float64      `json:"cost_usd"`
+	Running      bool         `json:"running"`
 }
 
 // CostEntry is a ledger entry for historical cost tracking.
 type CostEntry struct {
-	SessionID string    `json:"session_id"`
-	Role      string    `json:"role"`
-	Rig       string    `json:"rig,omitempty"`
-	Worker    string    `json:"worker,omitempty"`
-	CostUSD   float64   `json:"cost_usd"`
-	StartedAt time.Time `json:"started_at"`
-	EndedAt   time.Time `json:"ended_at"`
-	WorkItem  string    `json:"work_item,omitempty"`
+	SessionID    string       `json:"session_id"`
+	TranscriptID string       `json:"transcript_id,omitempty"`
+	Role         string       `json:"role"`
+	Rig          string       `json:"rig,omitempty"`
+	Worker       string       `json:"worker,omitempty"`
+	CostUSD      float64      `json:"cost_usd"`
+	Tokens       *costs.Usage `json:"tokens,omitempty"`
+	StartedAt    time.Time    `json:"started_at"`
+	EndedAt      time.Time    `json:"ended_at"`
+	WorkItem     string       `json:"work_item,omitempty"`
 }
 
 // CostsOutput is the JSON output structure.
 type CostsOutput struct {
 	Sessions []SessionCost      `json:"sessions,omitempty"`
 	Total    float64            `json:"total_usd"`
+	Tokens   *costs.Usage       `json:"tokens,omitempty"`
 	ByRole   map[string]float64 `json:"by_role,omitempty"`
 	ByRig    map[string]float64 `json:"by_rig,omitempty"`
+	ByBead   map[string]float64 `json:"by_bead,omitempty"`
 	Period   string             `json:"period,omitempty"`
 }
 
-// costRegex matches cost patterns like "$1.23" or "$12.34"
-var costRegex = regexp.MustCompile(`\$(\d+\.\d{2})`)
-
 func runCosts(cmd *cobra.Command, args []string) error {
 	// If querying ledger, use ledger functions
-	if costsToday || costsWeek || costsByRole || costsByRig {
+	if costsToday || costsWeek || costsByRole || costsByRig || costsByBead {
 		return runCostsFromLedger()
 	}
 
@@ -203,23 +217,40 @@ func runCosts(cmd *cobra.Command, args []string) error {
 }
 
 func runLiveCosts() error {
-	// Warn that cost tracking is disabled
-	fmt.Fprintf(os.Stderr, "%s Cost tracking is disabled - Claude Code does not expose session costs.\n",
-		style.Warning.Render("⚠"))
-	fmt.Fprintf(os.Stderr, "   All sessions will show $0.00. See: GH#24, gt-7awfj\n\n")
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	collector, err := costs.NewCollector(townRoot)
+	if err != nil {
+		return fmt.Errorf("loading costs config: %w", err)
+	}
+
+	// Latest session per agent, from session_start events
+	sessions, err := collector.Sessions(time.Time{})
+	if err != nil {
+		return fmt.Errorf("discovering sessions: %w", err)
+	}
+	latest := make(map[string]costs.Session)
+	for _, s := range sessions {
+		if _, ok := latest[s.Actor]; !ok {
+			latest[s.Actor] = s
+		}
+	}
 
 	t := tmux.NewTmux()
 
 	// Get all tmux sessions
-	sessions, err := t.ListSessions()
+	tmuxSessions, err := t.ListSessions()
 	if err != nil {
 		return fmt.Errorf("listing sessions: %w", err)
 	}
 
-	var costs []SessionCost
+	var sessionCosts []SessionCost
 	var total float64
+	var tokens costs.Usage
 
-	for _, session := range sessions {
+	for _, session := range tmuxSessions {
 		// Only process Whale Town sessions (start with "wt-")
 		if !strings.HasPrefix(session, constants.SessionPrefix) {
 			continue
@@ -227,51 +258,49 @@ func runLiveCosts() error {
 
 		// Parse session name to get role/rig/worker
 		role, rig, worker := parseSessionName(session)
-
-		// Capture pane content
-		content, err := t.CapturePaneAll(session)
-		if err != nil {
-			continue // Skip sessions we can't capture
-		}
-
-		// Extract cost from content
-		cost := extractCost(content)
-
-		// Check if an agent appears to be running
-		running := t.IsAgentRunning(session)
-
-		costs = append(costs, SessionCost{
+		sc := SessionCost{
 			Session: session,
 			Role:    role,
 			Rig:     rig,
 			Worker:  worker,
-			Cost:    cost,
-			Running: running,
-		})
-		total += cost
+			Running: t.IsAgentRunning(session),
+		}
+
+		if s, ok := latest[buildAgentPath(role, rig, worker)]; ok {
+			cost, err := collector.SessionCost(s)
+			if err != nil && costsVerbose {
+				fmt.Fprintf(os.Stderr, "[costs] %s: %v\n", session, err)
+			}
+			if cost != nil {
+				sc.TranscriptID = cost.ID
+				sc.WorkItem = cost.Bead
+				sc.Tokens = &cost.Usage
+				sc.Cost = cost.CostUSD
+				tokens.Add(cost.Usage)
+			}
+		}
+
+		sessionCosts = append(sessionCosts, sc)
+		total += sc.Cost
 	}
 
 	// Sort by session name
-	sort.Slice(costs, func(i, j int) bool {
-		return costs[i].Session < costs[j].Session
+	sort.Slice(sessionCosts, func(i, j int) bool {
+		return sessionCosts[i].Session < sessionCosts[j].Session
 	})
 
 	if costsJSON {
 		return outputCostsJSON(CostsOutput{
-			Sessions: costs,
+			Sessions: sessionCosts,
 			Total:    total,
+			Tokens:   &tokens,
 		})
 	}
 
-	return outputCostsHuman(costs, total)
+	return outputCostsHuman(sessionCosts, total)
 }
 
 func runCostsFromLedger() error {
-	// Warn that cost tracking is disabled
-	fmt.Fprintf(os.Stderr, "%s Cost tracking is disabled - Claude Code does not expose session costs.\n",
-		style.Warning.Render("⚠"))
-	fmt.Fprintf(os.Stderr, "   Historical data may show $0.00 for all sessions. See: GH#24, gt-7awfj\n\n")
-
 	now := time.Now()
 	var entries []CostEntry
 	var err error
@@ -300,6 +329,7 @@ func runCostsFromLedger() error {
 		entries = querySessionEvents()
 	}
 
+	entries = dedupeCostEntries(entries)
 	if len(entries) == 0 {
 		fmt.Println(style.Dim.Render("No cost data found. Costs are recorded when sessions end."))
 		return nil
@@ -307,8 +337,10 @@ func runCostsFromLedger() error {
 
 	// Calculate totals
 	var total float64
+	var tokens costs.Usage
 	byRole := make(map[string]float64)
 	byRig := make(map[string]float64)
+	byBead := make(map[string]float64)
 
 	for _, entry := range entries {
 		total += entry.CostUSD
@@ -316,11 +348,18 @@ func runCostsFromLedger() error {
 		if entry.Rig != "" {
 			byRig[entry.Rig] += entry.CostUSD
 		}
+		if entry.WorkItem != "" {
+			byBead[entry.WorkItem] += entry.CostUSD
+		}
+		if entry.Tokens != nil {
+			tokens.Add(*entry.Tokens)
+		}
 	}
 
 	// Build output
 	output := CostsOutput{
-		Total: total,
+		Total:  total,
+		Tokens: &tokens,
 	}
 
 	if costsByRole {
@@ -329,6 +368,9 @@ func runCostsFromLedger() error {
 	if costsByRig {
 		output.ByRig = byRig
 	}
+	if costsByBead {
+		output.ByBead = byBead
+	}
 
 	// Set period label
 	if costsToday {
@@ -358,12 +400,55 @@ type SessionEvent struct {
 
 // SessionPayload represents the JSON payload of a session event.
 type SessionPayload struct {
-	CostUSD   float64 `json:"cost_usd"`
-	SessionID string  `json:"session_id"`
-	Role      string  `json:"role"`
-	Rig       string  `json:"rig"`
-	Worker    string  `json:"worker"`
-	EndedAt   string  `json:"ended_at"`
+	CostUSD      float64      `json:"cost_usd"`
+	SessionID    string       `json:"session_id"`
+	TranscriptID string       `json:"transcript_id,omitempty"`
+	Role         string       `json:"role"`
+	Rig          string       `json:"rig"`
+	Worker       string       `json:"worker"`
+	Tokens       *costs.Usage `json:"tokens,omitempty"`
+	EndedAt      string       `json:"ended_at"`
+}
+
+// costEntry converts a session.ended payload to a ledger entry.
+func (p SessionPayload) costEntry(endedAt time.Time, workItem string) CostEntry {
+	return CostEntry{
+		SessionID:    p.SessionID,
+		TranscriptID: p.TranscriptID,
+		Role:         p.Role,
+		Rig:          p.Rig,
+		Worker:       p.Worker,
+		CostUSD:      p.CostUSD,
+		Tokens:       p.Tokens,
+		EndedAt:      endedAt,
+		WorkItem:     workItem,
+	}
+}
+
+// dedupeCostEntries keeps only the latest entry per transcript. Transcript
+// usage is cumulative, so a session recorded by several Stop hooks must be
+// counted once. Entries without a transcript ID are kept as-is.
+func dedupeCostEntries(entries []CostEntry) []CostEntry {
+	latest := make(map[string]int)
+	var out []CostEntry
+	for _, e := range entries {
+		if e.TranscriptID == "" {
+			out = append(out, e)
+			continue
+		}
+		if i, ok := latest[e.TranscriptID]; ok {
+			if e.EndedAt.After(out[i].EndedAt) {
+				if e.WorkItem == "" {
+					e.WorkItem = out[i].WorkItem
+				}
+				out[i] = e
+			}
+			continue
+		}
+		latest[e.TranscriptID] = len(out)
+		out = append(out, e)
+	}
+	return out
 }
 
 // EventListItem represents an event from bd list (minimal fields).
@@ -496,15 +581,7 @@ func querySessionEventsFromLocation(location string) ([]CostEntry, error) {
 			}
 		}
 
-		entries = append(entries, CostEntry{
-			SessionID: payload.SessionID,
-			Role:      payload.Role,
-			Rig:       payload.Rig,
-			Worker:    payload.Worker,
-			CostUSD:   payload.CostUSD,
-			EndedAt:   endedAt,
-			WorkItem:  event.Target,
-		})
+		entries = append(entries, payload.costEntry(endedAt, event.Target))
 	}
 
 	return entries, nil
@@ -635,33 +712,14 @@ func parseSessionName(session string) (role, rig, worker string) {
 	return constants.RolePolecat, rig, worker
 }
 
-// extractCost finds the most recent cost value in pane content.
-// Claude Code displays cost in the format "$X.XX" in the status area.
-func extractCost(content string) float64 {
-	matches := costRegex.FindAllStringSubmatch(content, -1)
-	if len(matches) == 0 {
-		return 0.0
-	}
-
-	// Get the last (most recent) match
-	lastMatch := matches[len(matches)-1]
-	if len(lastMatch) < 2 {
-		return 0.0
-	}
-
-	var cost float64
-	_, _ = fmt.Sscanf(lastMatch[1], "%f", &cost)
-	return cost
-}
-
 func outputCostsJSON(output CostsOutput) error {
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	return enc.Encode(output)
 }
 
-func outputCostsHuman(costs []SessionCost, total float64) error {
-	if len(costs) == 0 {
+func outputCostsHuman(sessionCosts []SessionCost, total float64) error {
+	if len(sessionCosts) == 0 {
 		fmt.Println(style.Dim.Render("No Whale Town sessions found"))
 		return nil
 	}
@@ -669,12 +727,12 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 	fmt.Printf("\n%s Live Session Costs\n\n", style.Bold.Render("💰"))
 
 	// Print table header
-	fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
-		"Session", "Role", "Rig/Worker", "Cost", "Status")
-	fmt.Println(strings.Repeat("─", 75))
+	fmt.Printf("%-25s %-10s %-15s %10s %10s %8s\n",
+		"Session", "Role", "Rig/Worker", "Tokens", "Cost", "Status")
+	fmt.Println(strings.Repeat("─", 86))
 
 	// Print each session
-	for _, c := range costs {
+	for _, c := range sessionCosts {
 		statusIcon := style.Success.Render("●")
 		if !c.Running {
 			statusIcon = style.Dim.Render("○")
@@ -689,16 +747,22 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 			}
 		}
 
-		fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
+		tokens := "-"
+		if c.Tokens != nil {
+			tokens = formatTokenCount(c.Tokens.Total())
+		}
+
+		fmt.Printf("%-25s %-10s %-15s %10s %10s %8s\n",
 			c.Session,
 			c.Role,
 			rigWorker,
+			tokens,
 			fmt.Sprintf("$%.2f", c.Cost),
 			statusIcon)
 	}
 
 	// Print total
-	fmt.Println(strings.Repeat("─", 75))
+	fmt.Println(strings.Repeat("─", 86))
 	fmt.Printf("%s %s\n", style.Bold.Render("Total:"), fmt.Sprintf("$%.2f", total))
 
 	return nil
@@ -714,6 +778,11 @@ func outputLedgerHuman(output CostsOutput, entries []CostEntry) error {
 
 	// Total
 	fmt.Printf("%s $%.2f\n", style.Bold.Render("Total:"), output.Total)
+	if output.Tokens != nil && output.Tokens.Total() > 0 {
+		fmt.Printf("%s %s in, %s out, %s cache write, %s cache read\n", style.Bold.Render("Tokens:"),
+			formatTokenCount(output.Tokens.InputTokens), formatTokenCount(output.Tokens.OutputTokens),
+			formatTokenCount(output.Tokens.CacheWriteTokens), formatTokenCount(output.Tokens.CacheReadTokens))
+	}
 
 	// By role breakdown
 	if output.ByRole != nil && len(output.ByRole) > 0 {
@@ -732,17 +801,60 @@ func outputLedgerHuman(output CostsOutput, entries []CostEntry) error {
 		}
 	}
 
+	// By bead breakdown
+	if len(output.ByBead) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("By Bead:"))
+		beadIDs := make([]string, 0, len(output.ByBead))
+		for id := range output.ByBead {
+			beadIDs = append(beadIDs, id)
+		}
+		sort.Slice(beadIDs, func(i, j int) bool { return output.ByBead[beadIDs[i]] > output.ByBead[beadIDs[j]] })
+		for _, id := range beadIDs {
+			fmt.Printf("  %-15s $%.2f\n", id, output.ByBead[id])
+		}
+	}
+
 	// Session count
 	fmt.Printf("\n%s %d sessions\n", style.Dim.Render("Entries:"), len(entries))
 
 	return nil
 }
 
-// runCostsRecord captures the final cost from a session and records it as a bead event.
+// formatTokenCount abbreviates a token count (e.g., 1.2M, 45.3k).
+func formatTokenCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// runCostsRecord prices a session's transcript and records it as a bead event.
 // This is called by the Claude Code Stop hook.
 func runCostsRecord(cmd *cobra.Command, args []string) error {
-	// Get session from flag or try to detect from environment
+	// The Stop hook passes the runtime session ID and transcript path on stdin
+	var transcriptID, transcriptPath string
+	if recordSession == "" {
+		if input := readStdinJSON(); input != nil {
+			transcriptID, transcriptPath = input.SessionID, input.TranscriptPath
+		}
+	}
+
+	// --session may be a runtime session ID rather than a tmux session name
 	session := recordSession
+	if session != "" && !strings.HasPrefix(session, constants.SessionPrefix) && !strings.HasPrefix(session, constants.HQSessionPrefix) {
+		transcriptID, session = session, ""
+	}
+	if transcriptID == "" {
+		transcriptID = runtime.SessionIDFromEnv()
+	}
+	if transcriptID == "" {
+		transcriptID = ReadPersistedSessionID()
+	}
+
 	if session == "" {
 		session = os.Getenv("WT_SESSION")
 	}
@@ -758,17 +870,51 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--session flag required (or set WT_SESSION env var, or WT_RIG/WT_ROLE)")
 	}
 
-	t := tmux.NewTmux()
+	// Find town root so bd can find the .beads database.
+	// The stop hook may run from a role subdirectory (e.g., mayor/) that
+	// doesn't have its own .beads, so we need to run bd from town root.
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("finding town root: %w", err)
+	}
+	if townRoot == "" {
+		return fmt.Errorf("not in a Whale Town workspace")
+	}
 
-	// Capture pane content
-	content, err := t.CapturePaneAll(session)
+	// Price the transcript. A session without one is recorded at zero cost.
+	var cost float64
+	var tokens *costs.Usage
+	collector, err := costs.NewCollector(townRoot)
 	if err != nil {
-		// Session may already be gone - that's OK, we'll record with zero cost
-		content = ""
+		return fmt.Errorf("loading costs config: %w", err)
+	}
+	if transcriptPath == "" {
+		transcriptPath = costs.FindTranscript(collector.TranscriptDirs, transcriptID)
+	}
+	if transcriptPath != "" {
+		sc, err := collector.Cost(costs.Session{ID: transcriptID}, transcriptPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: reading transcript %s: %v\n", transcriptPath, err)
+		} else {
+			cost, tokens, transcriptID = sc.CostUSD, &sc.Usage, sc.ID
+			if len(sc.Unpriced) > 0 {
+				fmt.Fprintf(os.Stderr, "warning: no price for %s (add it to settings/costs.json)\n", strings.Join(sc.Unpriced, ", "))
+			}
+		}
 	}
 
-	// Extract cost
-	cost := extractCost(content)
+	// Attribute to the bead slung to this session's agent
+	workItem := recordWorkItem
+	if workItem == "" && transcriptID != "" {
+		if sessions, err := collector.Sessions(time.Time{}); err == nil {
+			for _, s := range sessions {
+				if s.ID == transcriptID {
+					workItem = s.Bead
+					break
+				}
+			}
+		}
+	}
 
 	// Parse session name
 	role, rig, worker := parseSessionName(session)
@@ -778,8 +924,8 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 
 	// Build event title
 	title := fmt.Sprintf("Session ended: %s", session)
-	if recordWorkItem != "" {
-		title = fmt.Sprintf("Session: %s completed %s", session, recordWorkItem)
+	if workItem != "" {
+		title = fmt.Sprintf("Session: %s completed %s", session, workItem)
 	}
 
 	// Build payload JSON
@@ -795,6 +941,12 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	if worker != "" {
 		payload["worker"] = worker
 	}
+	if transcriptID != "" {
+		payload["transcript_id"] = transcriptID
+	}
+	if tokens != nil {
+		payload["tokens"] = tokens
+	}
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshaling payload: %w", err)
@@ -816,26 +968,15 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		"--silent",
 	}
 
-	// Add work item as event target if specified
-	if recordWorkItem != "" {
-		bdArgs = append(bdArgs, "--event-target="+recordWorkItem)
+	// Add work item as event target if known
+	if workItem != "" {
+		bdArgs = append(bdArgs, "--event-target="+workItem)
 	}
 
 	// NOTE: We intentionally don't use --rig flag here because it causes
 	// event fields (event_kind, actor, payload) to not be stored properly.
 	// The bd command will auto-detect the correct rig from cwd.
 
-	// Find town root so bd can find the .beads database.
-	// The stop hook may run from a role subdirectory (e.g., mayor/) that
-	// doesn't have its own .beads, so we need to run bd from town root.
-	townRoot, err := workspace.FindFromCwd()
-	if err != nil {
-		return fmt.Errorf("finding town root: %w", err)
-	}
-	if townRoot == "" {
-		return fmt.Errorf("not in a Whale Town workspace")
-	}
-
 	// Execute bd create from town root
 	bdCmd := exec.Command("bd", bdArgs...)
 	bdCmd.Dir = townRoot
@@ -857,10 +998,10 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output confirmation (silent if cost is zero and no work item)
-	if cost > 0 || recordWorkItem != "" {
+	if cost > 0 || workItem != "" {
 		fmt.Printf("%s Recorded $%.2f for %s (wisp: %s)", style.Success.Render("✓"), cost, session, wispID)
-		if recordWorkItem != "" {
-			fmt.Printf(" (work: %s)", recordWorkItem)
+		if workItem != "" {
+			fmt.Printf(" (work: %s)", workItem)
 		}
 		fmt.Println()
 	}
@@ -970,10 +1111,12 @@ func buildAgentPath(role, rig, worker string) string {
 type CostDigest struct {
 	Date         string             `json:"date"`
 	TotalUSD     float64            `json:"total_usd"`
+	Tokens       costs.Usage        `json:"tokens"`
 	SessionCount int                `json:"session_count"`
 	Sessions     []CostEntry        `json:"sessions"`
 	ByRole       map[string]float64 `json:"by_role"`
 	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+	ByBead       map[string]float64 `json:"by_bead,omitempty"`
 }
 
 // WispListOutput represents the JSON output from bd mol wisp list.
@@ -1019,27 +1162,36 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s No session cost wisps found for %s\n", style.Dim.Render("○"), dateStr)
 		return nil
 	}
+	entries := dedupeCostEntries(wisps)
 
 	// Build digest
 	digest := CostDigest{
 		Date:     dateStr,
-		Sessions: wisps,
+		Sessions: entries,
 		ByRole:   make(map[string]float64),
 		ByRig:    make(map[string]float64),
+		ByBead:   make(map[string]float64),
 	}
 
-	for _, w := range wisps {
+	for _, w := range entries {
 		digest.TotalUSD += w.CostUSD
 		digest.SessionCount++
 		digest.ByRole[w.Role] += w.CostUSD
 		if w.Rig != "" {
 			digest.ByRig[w.Rig] += w.CostUSD
 		}
+		if w.WorkItem != "" {
+			digest.ByBead[w.WorkItem] += w.CostUSD
+		}
+		if w.Tokens != nil {
+			digest.Tokens.Add(*w.Tokens)
+		}
 	}
 
 	if digestDryRun {
 		fmt.Printf("%s [DRY RUN] Would create Cost Report %s:\n", style.Bold.Render("📊"), dateStr)
 		fmt.Printf("  Total: $%.2f\n", digest.TotalUSD)
+		fmt.Printf("  Tokens: %s\n", formatTokenCount(digest.Tokens.Total()))
 		fmt.Printf("  Sessions: %d\n", digest.SessionCount)
 		fmt.Printf("  By Role:\n")
 		for role, cost := range digest.ByRole {
@@ -1147,15 +1299,7 @@ func querySessionCostWisps(targetDate time.Time) ([]CostEntry, error) {
 			continue
 		}
 
-		sessionCostWisps = append(sessionCostWisps, CostEntry{
-			SessionID: payload.SessionID,
-			Role:      payload.Role,
-			Rig:       payload.Rig,
-			Worker:    payload.Worker,
-			CostUSD:   payload.CostUSD,
-			EndedAt:   endedAt,
-			WorkItem:  event.Target,
-		})
+		sessionCostWisps = append(sessionCostWisps, payload.costEntry(endedAt, event.Target))
 	}
 
 	return sessionCostWisps, nil
@@ -1167,6 +1311,11 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 	var desc strings.Builder
 	desc.WriteString(fmt.Sprintf("Daily cost aggregate for %s.\n\n", digest.Date))
 	desc.WriteString(fmt.Sprintf("**Total:** $%.2f from %d sessions\n\n", digest.TotalUSD, digest.SessionCount))
+	if digest.Tokens.Total() > 0 {
+		desc.WriteString(fmt.Sprintf("**Tokens:** %s in, %s out, %s cache write, %s cache read\n\n",
+			formatTokenCount(digest.Tokens.InputTokens), formatTokenCount(digest.Tokens.OutputTokens),
+			formatTokenCount(digest.Tokens.CacheWriteTokens), formatTokenCount(digest.Tokens.CacheReadTokens)))
+	}
 
 	if len(digest.ByRole) > 0 {
 		desc.WriteString("## By Role\n")
@@ -1195,6 +1344,19 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 		desc.WriteString("\n")
 	}
 
+	if len(digest.ByBead) > 0 {
+		desc.WriteString("## By Bead\n")
+		beadIDs := make([]string, 0, len(digest.ByBead))
+		for id := range digest.ByBead {
+			beadIDs = append(beadIDs, id)
+		}
+		sort.Strings(beadIDs)
+		for _, id := range beadIDs {
+			desc.WriteString(fmt.Sprintf("- %s: $%.2f\n", id, digest.ByBead[id]))
+		}
+		desc.WriteString("\n")
+	}
+
 	// Build payload JSON with full session details
 	payloadJSON, err := json.Marshal(digest)
 	if err != nil {
@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestDeriveSessionName(t *testing.T) {
@@ -112,3 +113,25 @@ func TestDeriveSessionName(t *testing.T) {
 		})
 	}
 }
+
+func TestDedupeCostEntries(t *testing.T) {
+	t1 := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	entries := []CostEntry{
+		{SessionID: "wt-wt-toast", TranscriptID: "s1", CostUSD: 1, EndedAt: t1, WorkItem: "wt-abc"},
+		{SessionID: "wt-wt-toast", TranscriptID: "s1", CostUSD: 3, EndedAt: t1.Add(time.Hour)},
+		{SessionID: "wt-wt-toast", TranscriptID: "s1", CostUSD: 2, EndedAt: t1.Add(30 * time.Minute)},
+		{SessionID: "legacy", CostUSD: 5, EndedAt: t1},
+		{SessionID: "legacy", CostUSD: 5, EndedAt: t1},
+	}
+
+	got := dedupeCostEntries(entries)
+	if len(got) != 3 {
+		t.Fatalf("got %d entries, want 3: %+v", len(got), got)
+	}
+	if got[0].CostUSD != 3 || got[0].WorkItem != "wt-abc" {
+		t.Errorf("transcript entry = %+v, want latest cost with work item kept", got[0])
+	}
+	if got[1].SessionID != "legacy" || got[2].SessionID != "legacy" {
+		t.Errorf("entries without a transcript ID must be kept: %+v", got[1:])
+	}
+}
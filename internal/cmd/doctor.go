@@ -3,9 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/speaker20/whaletown/internal/doctor"
+	"github.com/speaker20/whaletown/internal/style"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
 
@@ -14,6 +16,8 @@ var (
 	doctorVerbose         bool
 	doctorRig             string
 	doctorRestartSessions bool
+	doctorDryRun          bool
+	doctorUndoForce       bool
 )
 
 var doctorCmd = &cobra.Command{
@@ -81,15 +85,40 @@ Patrol checks:
   - patrol-roles-have-prompts Verify role prompts exist
 
 Use --fix to attempt automatic fixes for issues that support it.
-Use --rig to check a specific rig instead of the entire workspace.`,
+Use --rig to check a specific rig instead of the entire workspace.
+
+Fix runs are journaled under .runtime/doctor/fixes/<run-id>/: the files
+and git refs each fix changed, with snapshots of the files before.
+Use --dry-run to see a diff of what --fix would change without keeping
+any of it, and 'wt doctor undo <run-id>' to revert a fix run.`,
 	RunE: runDoctor,
 }
 
+var doctorUndoCmd = &cobra.Command{
+	Use:   "undo [run-id]",
+	Short: "Revert the changes of a doctor --fix run",
+	Long: `Revert the changes of a 'wt doctor --fix' run from its journal.
+
+Files are restored from their snapshots (files the run created are
+removed) and git refs are moved back, newest change first. Fixes whose
+checks don't declare what they change are listed but can't be reverted.
+
+Undo refuses when something the run changed has changed again since;
+--force restores the snapshots anyway.
+
+Without a run ID, lists the recorded fix runs.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDoctorUndo,
+}
+
 func init() {
 	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Attempt to automatically fix issues")
 	doctorCmd.Flags().BoolVarP(&doctorVerbose, "verbose", "v", false, "Show detailed output")
 	doctorCmd.Flags().StringVar(&doctorRig, "rig", "", "Check specific rig only")
 	doctorCmd.Flags().BoolVar(&doctorRestartSessions, "restart-sessions", false, "Restart patrol sessions when fixing stale settings (use with --fix)")
+	doctorCmd.Flags().BoolVar(&doctorDryRun, "dry-run", false, "Show a diff of what --fix would change without changing anything")
+	doctorUndoCmd.Flags().BoolVar(&doctorUndoForce, "force", false, "Restore even if files or refs changed since the fix")
+	doctorCmd.AddCommand(doctorUndoCmd)
 	rootCmd.AddCommand(doctorCmd)
 }
 
@@ -189,15 +218,28 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 
 	// Run checks
 	var report *doctor.Report
-	if doctorFix {
+	var previews []*doctor.FixPreview
+	switch {
+	case doctorDryRun:
+		report, previews = d.Preview(ctx)
+	case doctorFix:
+		ctx.Journal = doctor.NewFixJournal(townRoot)
 		report = d.Fix(ctx)
-	} else {
+	default:
 		report = d.Run(ctx)
 	}
 
 	// Print report
 	report.Print(os.Stdout, doctorVerbose)
 
+	if doctorDryRun {
+		printFixPreviews(previews)
+	}
+	if ctx.Journal != nil && !ctx.Journal.Empty() {
+		fmt.Printf("\n%s Fix run %s journaled; revert with: wt doctor undo %s\n",
+			style.Dim.Render("↺"), ctx.Journal.ID, ctx.Journal.ID)
+	}
+
 	// Exit with error code if there are errors
 	if report.HasErrors() {
 		return fmt.Errorf("doctor found %d error(s)", report.Summary.Errors)
@@ -205,3 +247,83 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// printFixPreviews prints the changes a --dry-run found --fix would make.
+func printFixPreviews(previews []*doctor.FixPreview) {
+	fmt.Println()
+	if len(previews) == 0 {
+		fmt.Println("Dry run: nothing to fix")
+		return
+	}
+	fmt.Printf("%s\n", style.Bold.Render("Dry run: changes --fix would make"))
+	for _, p := range previews {
+		fmt.Printf("\n%s\n", style.Bold.Render(p.Check))
+		if p.Diff != "" {
+			fmt.Print(p.Diff)
+		}
+		for _, r := range p.Refs {
+			current := r.Before
+			if current == "" {
+				current = "(none)"
+			}
+			fmt.Printf("  %s %s in %s (now %s)\n", style.Dim.Render("moves"), r.Ref, r.Repo, current)
+		}
+		if p.Note != "" {
+			fmt.Printf("  %s\n", style.Dim.Render(p.Note))
+		}
+		if p.Err != nil {
+			fmt.Printf("  %s fix would fail: %v\n", style.Warning.Render("⚠"), p.Err)
+		} else if p.Diff == "" && len(p.Refs) == 0 && p.Note == "" {
+			fmt.Printf("  %s\n", style.Dim.Render("no file changes"))
+		}
+	}
+}
+
+func runDoctorUndo(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+
+	if len(args) == 0 {
+		journals, err := doctor.ListFixJournals(townRoot)
+		if err != nil {
+			return fmt.Errorf("listing fix runs: %w", err)
+		}
+		if len(journals) == 0 {
+			fmt.Println("No fix runs recorded")
+			return nil
+		}
+		for _, j := range journals {
+			checks := make([]string, 0, len(j.Entries))
+			for _, e := range j.Entries {
+				checks = append(checks, e.Check)
+			}
+			state := ""
+			if j.UndoneAt != nil {
+				state = style.Dim.Render(" (undone)")
+			}
+			fmt.Printf("%s  %s  %s%s\n", style.Bold.Render(j.ID), j.Started.Local().Format("2006-01-02 15:04"), strings.Join(checks, ", "), state)
+		}
+		return nil
+	}
+
+	j, err := doctor.LoadFixJournal(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+	result, err := j.Undo(doctorUndoForce)
+	if result != nil {
+		for _, r := range result.Restored {
+			fmt.Printf("%s Restored %s\n", style.Success.Render("✓"), r)
+		}
+		for _, c := range result.Skipped {
+			fmt.Printf("%s %s: changes weren't journaled, not reverted\n", style.Warning.Render("⚠"), c)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("undoing fix run %s: %w", j.ID, err)
+	}
+	fmt.Printf("%s Fix run %s undone\n", style.Bold.Render("✓"), j.ID)
+	return nil
+}
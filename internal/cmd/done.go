@@ -16,6 +16,7 @@ import (
 	"github.com/speaker20/whaletown/internal/style"
 	"github.com/speaker20/whaletown/internal/tmux"
 	"github.com/speaker20/whaletown/internal/townlog"
+	"github.com/speaker20/whaletown/internal/witness"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
 
@@ -407,24 +408,23 @@ func runDone(cmd *cobra.Command, args []string) error {
 	witnessAddr := fmt.Sprintf("%s/witness", rigName)
 
 	// Build notification body
-	var bodyLines []string
-	bodyLines = append(bodyLines, fmt.Sprintf("Exit: %s", exitType))
-	if issueID != "" {
-		bodyLines = append(bodyLines, fmt.Sprintf("Issue: %s", issueID))
-	}
-	if mrID != "" {
-		bodyLines = append(bodyLines, fmt.Sprintf("MR: %s", mrID))
-	}
-	if doneGate != "" {
-		bodyLines = append(bodyLines, fmt.Sprintf("Gate: %s", doneGate))
+	doneSubject, doneBody, err := witness.EncodePolecatDone(&witness.PolecatDonePayload{
+		PolecatName: polecatName,
+		Exit:        exitType,
+		IssueID:     issueID,
+		MRID:        mrID,
+		Gate:        doneGate,
+		Branch:      branch,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding completion notice: %w", err)
 	}
-	bodyLines = append(bodyLines, fmt.Sprintf("Branch: %s", branch))
 
 	doneNotification := &mail.Message{
 		To:      witnessAddr,
 		From:    sender,
-		Subject: fmt.Sprintf("POLECAT_DONE %s", polecatName),
-		Body:    strings.Join(bodyLines, "\n"),
+		Subject: doneSubject,
+		Body:    doneBody,
 	}
 
 	fmt.Printf("\nNotifying Witness...\n")
@@ -441,7 +441,7 @@ func runDone(cmd *cobra.Command, args []string) error {
 				To:      dispatcher,
 				From:    sender,
 				Subject: fmt.Sprintf("WORK_DONE: %s", issueID),
-				Body:    strings.Join(bodyLines, "\n"),
+				Body:    doneBody,
 			}
 			if err := townRouter.Send(dispatcherNotification); err != nil {
 				style.PrintWarning("could not notify dispatcher %s: %v", dispatcher, err)
@@ -16,6 +16,7 @@ var (
 	escalateStaleJSON   bool
 	escalateDryRun      bool
 	escalateCloseReason string
+	escalateRemindIn    string
 )
 
 var escalateCmd = &cobra.Command{
@@ -41,6 +42,11 @@ WORKFLOW:
   4. Recipient acknowledges with: wt escalate ack <id>
   5. After resolution: wt escalate close <id> --reason "fixed"
 
+REMINDERS:
+  --remind-in schedules a reminder to the routing targets. The daemon
+  delivers it when it falls due, unless the escalation has been closed
+  by then.
+
 CONFIGURATION:
   Routing is configured in ~/gt/settings/escalation.json:
   - routes: Map severity to action lists (bead, mail:mayor, email:human, sms:human)
@@ -52,6 +58,7 @@ Examples:
   wt escalate "Build failing" --severity critical --reason "CI blocked"
   wt escalate "Need API credentials" --severity high --source "plugin:rebuild-gt"
   wt escalate "Code review requested" --reason "PR #123 ready"
+  wt escalate "Flaky deploy" --severity low --remind-in 2h
   wt escalate list                          # Show open escalations
   wt escalate ack hq-abc123                 # Acknowledge
   wt escalate close hq-abc123 --reason "Fixed in commit abc"
@@ -141,6 +148,7 @@ func init() {
 	escalateCmd.Flags().StringVarP(&escalateReason, "reason", "r", "", "Detailed reason for escalation")
 	escalateCmd.Flags().StringVar(&escalateSource, "source", "", "Source identifier (e.g., plugin:rebuild-gt, patrol:deacon)")
 	escalateCmd.Flags().StringVar(&escalateRelatedBead, "related", "", "Related bead ID (task, bug, etc.)")
+	escalateCmd.Flags().StringVar(&escalateRemindIn, "remind-in", "", "Remind the targets after a delay if still unresolved (e.g. 2h, 1d)")
 	escalateCmd.Flags().BoolVar(&escalateJSON, "json", false, "Output as JSON")
 	escalateCmd.Flags().BoolVarP(&escalateDryRun, "dry-run", "n", false, "Show what would be done without executing")
 
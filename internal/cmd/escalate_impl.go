@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"github.com/speaker20/whaletown/internal/config"
 	"github.com/speaker20/whaletown/internal/events"
 	"github.com/speaker20/whaletown/internal/mail"
+	"github.com/speaker20/whaletown/internal/notify"
 	"github.com/speaker20/whaletown/internal/style"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
@@ -42,6 +44,13 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading escalation config: %w", err)
 	}
 
+	var remindIn time.Duration
+	if escalateRemindIn != "" {
+		if remindIn, err = mail.ParseDelay(escalateRemindIn); err != nil {
+			return fmt.Errorf("--remind-in: %w", err)
+		}
+	}
+
 	// Detect agent identity
 	agentID := detectSender()
 	if agentID == "" {
@@ -51,7 +60,7 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 	// Dry run mode
 	if escalateDryRun {
 		actions := escalationConfig.GetRouteForSeverity(severity)
-		targets := extractMailTargetsFromActions(actions)
+		targets := relayToOverseer(extractMailTargetsFromActions(actions), escalationConfig)
 		fmt.Printf("Would create escalation:\n")
 		fmt.Printf("  Severity: %s\n", severity)
 		fmt.Printf("  Description: %s\n", description)
@@ -63,6 +72,9 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Printf("  Actions: %s\n", strings.Join(actions, ", "))
 		fmt.Printf("  Mail targets: %s\n", strings.Join(targets, ", "))
+		if remindIn > 0 {
+			fmt.Printf("  Reminder: in %s unless closed\n", remindIn)
+		}
 		return nil
 	}
 
@@ -84,7 +96,7 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 
 	// Get routing actions for this severity
 	actions := escalationConfig.GetRouteForSeverity(severity)
-	targets := extractMailTargetsFromActions(actions)
+	targets := relayToOverseer(extractMailTargetsFromActions(actions), escalationConfig)
 
 	// Send mail to each target (actions with "mail:" prefix)
 	router := mail.NewRouter(townRoot)
@@ -114,8 +126,39 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Process external notification actions (email:, sms:, slack)
-	executeExternalActions(actions, escalationConfig, issue.ID, severity, description)
+	// Schedule a reminder; the daemon drops it if the escalation is closed by then
+	var remindAt time.Time
+	if remindIn > 0 {
+		remindAt = time.Now().Add(remindIn)
+		for _, target := range targets {
+			reminder := &mail.Message{
+				From:        agentID,
+				To:          target,
+				Subject:     fmt.Sprintf("Reminder: [%s] %s", strings.ToUpper(severity), description),
+				Body:        fmt.Sprintf("Escalation %s is still open after %s.\n\n", issue.ID, remindIn) + formatEscalationMailBody(issue.ID, severity, escalateReason, agentID, escalateRelatedBead),
+				Type:        mail.TypeTask,
+				Priority:    mail.PriorityHigh,
+				DeliverAt:   &remindAt,
+				ReminderFor: issue.ID,
+			}
+			if err := router.Send(reminder); err != nil {
+				style.PrintWarning("failed to schedule reminder for %s: %v", target, err)
+			}
+		}
+	}
+
+	// Deliver external notification actions (email:, sms:, slack, webhook, log)
+	deliveries := executeExternalActions(townRoot, bd, escalationConfig, actions, &notify.Message{
+		BeadID:   issue.ID,
+		Severity: severity,
+		Title:    description,
+		Reason:   escalateReason,
+		Source:   escalateSource,
+		From:     agentID,
+		Related:  escalateRelatedBead,
+		Body:     formatEscalationMailBody(issue.ID, severity, escalateReason, agentID, escalateRelatedBead),
+		Time:     time.Now(),
+	})
 
 	// Log to activity feed
 	payload := events.EscalationPayload(issue.ID, agentID, strings.Join(targets, ","), description)
@@ -134,9 +177,15 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 			"actions":  actions,
 			"targets":  targets,
 		}
+		if len(deliveries) > 0 {
+			result["deliveries"] = deliveries
+		}
 		if escalateSource != "" {
 			result["source"] = escalateSource
 		}
+		if !remindAt.IsZero() {
+			result["remind_at"] = remindAt.Format(time.RFC3339)
+		}
 		out, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Println(string(out))
 	} else {
@@ -147,6 +196,9 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  Source: %s\n", escalateSource)
 		}
 		fmt.Printf("  Routed to: %s\n", strings.Join(targets, ", "))
+		if !remindAt.IsZero() {
+			fmt.Printf("  Reminder: %s unless closed\n", remindAt.Format("2006-01-02 15:04"))
+		}
 	}
 
 	return nil
@@ -350,7 +402,7 @@ func runEscalateStale(cmd *cobra.Command, args []string) error {
 		// If not skipped, re-route to new severity targets
 		if !result.Skipped {
 			actions := escalationConfig.GetRouteForSeverity(result.NewSeverity)
-			targets := extractMailTargetsFromActions(actions)
+			targets := relayToOverseer(extractMailTargetsFromActions(actions), escalationConfig)
 
 			// Send mail to each target about the reescalation
 			for _, target := range targets {
@@ -379,6 +431,16 @@ func runEscalateStale(cmd *cobra.Command, args []string) error {
 				}
 			}
 
+			executeExternalActions(townRoot, bd, escalationConfig, actions, &notify.Message{
+				BeadID:   result.ID,
+				Severity: result.NewSeverity,
+				Title:    "Re-escalated: " + result.Title,
+				Reason:   fmt.Sprintf("unacknowledged, raised from %s (reescalation %d)", result.OldSeverity, result.ReescalationNum),
+				From:     reescalatedBy,
+				Body:     formatReescalationMailBody(result, reescalatedBy),
+				Time:     time.Now(),
+			})
+
 			// Log to activity feed
 			_ = events.LogFeed(events.TypeEscalationSent, reescalatedBy, map[string]interface{}{
 				"escalation_id":    result.ID,
@@ -538,41 +600,49 @@ func extractMailTargetsFromActions(actions []string) []string {
 	return targets
 }
 
-// executeExternalActions processes external notification actions (email:, sms:, slack).
-// For now, this logs warnings if contacts aren't configured - actual sending is future work.
-func executeExternalActions(actions []string, cfg *config.EscalationConfig, _, _, _ string) {
-	for _, action := range actions {
-		switch {
-		case strings.HasPrefix(action, "email:"):
-			if cfg.Contacts.HumanEmail == "" {
-				style.PrintWarning("email action '%s' skipped: contacts.human_email not configured in settings/escalation.json", action)
-			} else {
-				// TODO: Implement actual email sending
-				fmt.Printf("  📧 Would send email to %s (not yet implemented)\n", cfg.Contacts.HumanEmail)
-			}
-
-		case strings.HasPrefix(action, "sms:"):
-			if cfg.Contacts.HumanSMS == "" {
-				style.PrintWarning("sms action '%s' skipped: contacts.human_sms not configured in settings/escalation.json", action)
-			} else {
-				// TODO: Implement actual SMS sending
-				fmt.Printf("  📱 Would send SMS to %s (not yet implemented)\n", cfg.Contacts.HumanSMS)
-			}
-
-		case action == "slack":
-			if cfg.Contacts.SlackWebhook == "" {
-				style.PrintWarning("slack action skipped: contacts.slack_webhook not configured in settings/escalation.json")
-			} else {
-				// TODO: Implement actual Slack webhook posting
-				fmt.Printf("  💬 Would post to Slack (not yet implemented)\n")
-			}
+// relayToOverseer adds the overseer to the mail targets when the email
+// gateway relays escalations, so they go out by email with their thread.
+func relayToOverseer(targets []string, cfg *config.EscalationConfig) []string {
+	if !cfg.RelaysEscalations() {
+		return targets
+	}
+	for _, t := range targets {
+		if t == "overseer" || t == "@overseer" {
+			return targets
+		}
+	}
+	return append(targets, "overseer")
+}
 
-		case action == "log":
-			// Log action always succeeds - writes to escalation log file
-			// TODO: Implement actual log file writing
-			fmt.Printf("  📝 Logged to escalation log\n")
+// executeExternalActions delivers an escalation to the route's external
+// actions (email:, sms:, slack, webhook, log), retrying transient failures,
+// and records each delivery's outcome on the escalation bead.
+func executeExternalActions(townRoot string, bd *beads.Beads, cfg *config.EscalationConfig, actions []string, msg *notify.Message) []notify.Delivery {
+	deliveries := notify.NewDispatcher(townRoot, cfg).Dispatch(context.Background(), actions, msg)
+
+	records := make([]beads.EscalationDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		switch d.Status {
+		case notify.StatusSent:
+			fmt.Printf("  %s %s delivered to %s\n", style.Success.Render("✓"), d.Action, d.Target)
+		case notify.StatusSkipped:
+			style.PrintWarning("%s action skipped: %s in settings/escalation.json", d.Action, d.Error)
+		default:
+			style.PrintWarning("%s delivery failed after %d attempt(s): %s", d.Action, d.Attempts, d.Error)
 		}
+		records = append(records, beads.EscalationDelivery{
+			Action:   d.Action,
+			Status:   d.Status,
+			Attempts: d.Attempts,
+			At:       d.At.Format(time.RFC3339),
+			Error:    d.Error,
+		})
+	}
+
+	if err := bd.RecordEscalationDeliveries(msg.BeadID, records); err != nil {
+		style.PrintWarning("failed to record delivery status on %s: %v", msg.BeadID, err)
 	}
+	return deliveries
 }
 
 func formatEscalationMailBody(beadID, severity, reason, from, related string) string {
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/speaker20/whaletown/internal/federation"
+	"github.com/speaker20/whaletown/internal/style"
+	"github.com/speaker20/whaletown/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Federation command flags
+var (
+	federationKey  string
+	federationJSON bool
+)
+
+var federationCmd = &cobra.Command{
+	Use:     "federation",
+	GroupID: GroupWorkspace,
+	Short:   "Manage peer towns",
+	RunE:    requireSubcommand,
+	Long: `Manage the peer towns this town federates with.
+
+A peer is another town, on this machine or reachable over SSH. Once
+registered, its agents can be mailed as <peer>:<rig>/<role>, and hop://
+and beads:// references to its issues resolve in 'wt show' and convoy
+tracking.
+
+Peers are stored in mayor/federation.json.
+
+Commands:
+  wt federation add <name> <url>   Register a peer town
+  wt federation list               List peer towns
+  wt federation remove <name>      Unregister a peer town`,
+}
+
+var federationAddCmd = &cobra.Command{
+	Use:   "add <name> <url-or-ssh>",
+	Short: "Register a peer town",
+	Long: `Register a peer town under a name.
+
+The town is given as a path on this machine, a file:// URL, an ssh:// URL
+or an scp-style user@host:/path. The peer is checked for a mayor/town.json
+and its identity is recorded so hop:// references can name it by its
+town name.
+
+Register this town in the peer under this town's name (see mayor/town.json)
+so that replies to federated mail find their way back.
+
+Examples:
+  wt federation add acme ~/towns/acme
+  wt federation add acme file:///srv/towns/acme
+  wt federation add acme ssh://wt@acme.internal:2222/srv/wt --key ~/.ssh/id_ed25519
+  wt federation add acme wt@acme.internal:/srv/wt`,
+	Args: cobra.ExactArgs(2),
+	RunE: runFederationAdd,
+}
+
+var federationListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List peer towns",
+	RunE:  runFederationList,
+}
+
+var federationRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Unregister a peer town",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFederationRemove,
+}
+
+func init() {
+	federationAddCmd.Flags().StringVar(&federationKey, "key", "", "SSH private key path (default: ~/.ssh/id_*)")
+	federationListCmd.Flags().BoolVar(&federationJSON, "json", false, "Output as JSON")
+
+	federationCmd.AddCommand(federationAddCmd)
+	federationCmd.AddCommand(federationListCmd)
+	federationCmd.AddCommand(federationRemoveCmd)
+	rootCmd.AddCommand(federationCmd)
+}
+
+func runFederationAdd(cmd *cobra.Command, args []string) error {
+	name, rawURL := args[0], args[1]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+
+	// Relative paths are relative to where the command runs
+	if !strings.Contains(rawURL, ":") && !filepath.IsAbs(rawURL) {
+		if rawURL, err = filepath.Abs(rawURL); err != nil {
+			return fmt.Errorf("resolving path: %w", err)
+		}
+	}
+	peer, err := federation.ParsePeerURL(rawURL)
+	if err != nil {
+		return err
+	}
+	peer.Name = name
+	if federationKey != "" {
+		if peer.KeyPath, err = filepath.Abs(federationKey); err != nil {
+			return fmt.Errorf("resolving key path: %w", err)
+		}
+	}
+	if peer.Type == federation.PeerLocal && filepath.Clean(peer.Path) == filepath.Clean(townRoot) {
+		return fmt.Errorf("%s is this town", peer.Path)
+	}
+
+	registry, err := federation.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = registry.Close() }()
+	if err := registry.Add(peer); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Added peer town %s (%s)\n", style.SuccessPrefix, style.Bold.Render(name), peer.URL)
+	if peer.TownName != "" {
+		fmt.Printf("  Town:  %s\n", peer.TownName)
+	}
+	if peer.Owner != "" {
+		fmt.Printf("  Owner: %s\n", peer.Owner)
+	}
+	fmt.Printf("  Mail its agents as %s\n", style.Dim.Render(name+":<rig>/<role>"))
+	return nil
+}
+
+func runFederationList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	registry, err := federation.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	peers := registry.List()
+
+	if federationJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(peers)
+	}
+
+	if len(peers) == 0 {
+		fmt.Println("No peer towns. Add one with: wt federation add <name> <url>")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tURL\tTOWN\tOWNER")
+	for _, p := range peers {
+		town, owner := p.TownName, p.Owner
+		if town == "" {
+			town = "-"
+		}
+		if owner == "" {
+			owner = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.Type, p.URL, town, owner)
+	}
+	return w.Flush()
+}
+
+func runFederationRemove(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	registry, err := federation.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	if err := registry.Remove(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed peer town %s\n", style.SuccessPrefix, args[0])
+	return nil
+}
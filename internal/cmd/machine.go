@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/connection"
+	"github.com/speaker20/whaletown/internal/constants"
+	"github.com/speaker20/whaletown/internal/git"
+	"github.com/speaker20/whaletown/internal/rig"
+	"github.com/speaker20/whaletown/internal/style"
+	"github.com/speaker20/whaletown/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Machine command flags
+var (
+	machineHost     string
+	machineKey      string
+	machineTownPath string
+	machineHostKey  string
+	machineScanKey  bool
+	machineJSON     bool
+)
+
+var machineCmd = &cobra.Command{
+	Use:     "machine",
+	GroupID: GroupWorkspace,
+	Short:   "Manage machines that host rigs",
+	RunE:    requireSubcommand,
+	Long: `Manage the machines a town can run rigs on.
+
+A rig attached to a machine keeps its beads and configuration in the town,
+but its worktrees (polecats) and tmux sessions live on the machine and are
+driven over a single multiplexed SSH connection.
+
+Machines are stored in mayor/machines.json. "local" always exists.
+
+Commands:
+  wt machine add <name>            Register an SSH machine
+  wt machine list                  List machines
+  wt machine remove <name>         Unregister a machine
+  wt machine test <name>           Check connectivity and tools
+  wt machine attach <rig> <name>   Run a rig's worktrees on a machine`,
+}
+
+var machineAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Register an SSH machine",
+	Long: `Register a machine reachable over SSH.
+
+--town-path is where rigs live on the machine; a rig attached to it uses
+<town-path>/<rig>. The host key is checked against ~/.ssh/known_hosts
+unless pinned with --host-key, or scanned and pinned with --scan-host-key.
+
+Examples:
+  wt machine add buildbox --host ci@build1.internal --key ~/.ssh/id_ed25519 --town-path /srv/wt
+  wt machine add buildbox --host ci@10.0.0.5:2222 --town-path /srv/wt --scan-host-key`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMachineAdd,
+}
+
+var machineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List machines",
+	RunE:  runMachineList,
+}
+
+var machineRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Unregister a machine",
+	Long:  `Unregister a machine. Fails if a rig is still attached to it.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMachineRemove,
+}
+
+var machineTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Check connectivity and tools on a machine",
+	Long: `Connect to a machine and check that git and tmux are installed and
+the town path exists.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMachineTest,
+}
+
+var machineAttachCmd = &cobra.Command{
+	Use:   "attach <rig> <machine>",
+	Short: "Run a rig's worktrees and sessions on a machine",
+	Long: `Attach a rig to a machine. New polecats are created as worktrees on the
+machine and their sessions run in tmux there.
+
+If the machine has no repo for the rig yet, a bare clone of the rig's
+git URL is created at <town-path>/<rig>/.repo.git. Use "local" to move
+the rig back to this machine.
+
+Examples:
+  wt machine attach whaletown buildbox
+  wt machine attach whaletown local`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMachineAttach,
+}
+
+func init() {
+	machineAddCmd.Flags().StringVar(&machineHost, "host", "", "SSH destination: user@host[:port] (required)")
+	machineAddCmd.Flags().StringVar(&machineKey, "key", "", "SSH private key path (default: ~/.ssh/id_*)")
+	machineAddCmd.Flags().StringVar(&machineTownPath, "town-path", "", "Directory on the machine that holds rigs")
+	machineAddCmd.Flags().StringVar(&machineHostKey, "host-key", "", "Pinned host key in authorized_keys format")
+	machineAddCmd.Flags().BoolVar(&machineScanKey, "scan-host-key", false, "Pin the key the host presents now")
+	_ = machineAddCmd.MarkFlagRequired("host")
+
+	machineListCmd.Flags().BoolVar(&machineJSON, "json", false, "Output as JSON")
+
+	machineCmd.AddCommand(machineAddCmd)
+	machineCmd.AddCommand(machineListCmd)
+	machineCmd.AddCommand(machineRemoveCmd)
+	machineCmd.AddCommand(machineTestCmd)
+	machineCmd.AddCommand(machineAttachCmd)
+	rootCmd.AddCommand(machineCmd)
+}
+
+// loadMachineRegistry opens the town's machine registry.
+func loadMachineRegistry(townRoot string) (*connection.MachineRegistry, error) {
+	return connection.NewMachineRegistry(constants.MayorMachinesPath(townRoot))
+}
+
+func runMachineAdd(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	registry, err := loadMachineRegistry(townRoot)
+	if err != nil {
+		return err
+	}
+
+	hostKey := machineHostKey
+	if machineScanKey && hostKey == "" {
+		if hostKey, err = connection.ScanHostKey(machineHost); err != nil {
+			return err
+		}
+		fmt.Printf("Pinned host key: %s\n", style.Dim.Render(hostKey))
+	}
+
+	keyPath := machineKey
+	if keyPath != "" {
+		if keyPath, err = filepath.Abs(keyPath); err != nil {
+			return fmt.Errorf("resolving key path: %w", err)
+		}
+	}
+
+	m := &connection.Machine{
+		Name:     args[0],
+		Type:     "ssh",
+		Host:     machineHost,
+		KeyPath:  keyPath,
+		TownPath: strings.TrimRight(machineTownPath, "/"),
+		HostKey:  hostKey,
+	}
+	if m.Name == "local" {
+		return fmt.Errorf("\"local\" is reserved for this machine")
+	}
+	if _, _, err := connection.ParseSSHHost(m.Host); err != nil {
+		return err
+	}
+	if err := registry.Add(m); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Added machine %s (%s)\n", style.SuccessPrefix, style.Bold.Render(m.Name), m.Host)
+	fmt.Printf("  Check it with: wt machine test %s\n", m.Name)
+	return nil
+}
+
+func runMachineList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	registry, err := loadMachineRegistry(townRoot)
+	if err != nil {
+		return err
+	}
+
+	machines := registry.List()
+	sort.Slice(machines, func(i, j int) bool { return machines[i].Name < machines[j].Name })
+
+	// Rigs attached to each machine
+	rigsOn := make(map[string][]string)
+	if rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot)); err == nil {
+		for name, entry := range rigsConfig.Rigs {
+			machine := entry.Machine
+			if machine == "" {
+				machine = "local"
+			}
+			rigsOn[machine] = append(rigsOn[machine], name)
+		}
+	}
+	for _, rigs := range rigsOn {
+		sort.Strings(rigs)
+	}
+
+	if machineJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(machines)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tHOST\tTOWN PATH\tRIGS")
+	for _, m := range machines {
+		host, townPath := m.Host, m.TownPath
+		if host == "" {
+			host = "-"
+		}
+		if townPath == "" {
+			townPath = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.Name, m.Type, host, townPath, strings.Join(rigsOn[m.Name], ","))
+	}
+	return w.Flush()
+}
+
+func runMachineRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	registry, err := loadMachineRegistry(townRoot)
+	if err != nil {
+		return err
+	}
+
+	if rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot)); err == nil {
+		for rigName, entry := range rigsConfig.Rigs {
+			if entry.Machine == name {
+				return fmt.Errorf("rig %s is attached to %s (wt machine attach %s local)", rigName, name, rigName)
+			}
+		}
+	}
+
+	if err := registry.Remove(name); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed machine %s\n", style.SuccessPrefix, name)
+	return nil
+}
+
+func runMachineTest(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	registry, err := loadMachineRegistry(townRoot)
+	if err != nil {
+		return err
+	}
+	defer registry.Close()
+
+	m, err := registry.Get(name)
+	if err != nil {
+		return err
+	}
+	conn, err := registry.Connection(name)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	check := func(label string, out []byte, err error) {
+		if err != nil {
+			failed = true
+			fmt.Printf("%s %s: %v\n", style.ErrorPrefix, label, err)
+			return
+		}
+		fmt.Printf("%s %s: %s\n", style.SuccessPrefix, label, strings.TrimSpace(string(out)))
+	}
+
+	out, err := conn.Exec("uname", "-sr")
+	check("connect", out, err)
+	if err != nil {
+		return fmt.Errorf("machine %s is unreachable", name)
+	}
+	out, err = conn.Exec("git", "--version")
+	check("git", out, err)
+	out, err = conn.Exec("tmux", "-V")
+	check("tmux", out, err)
+
+	if m.TownPath != "" {
+		info, err := conn.Stat(m.TownPath)
+		switch {
+		case err != nil:
+			check("town path", nil, err)
+		case !info.IsDir():
+			check("town path", nil, fmt.Errorf("%s is not a directory", m.TownPath))
+		default:
+			check("town path", []byte(m.TownPath), nil)
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("machine %s failed checks", name)
+	}
+	return nil
+}
+
+func runMachineAttach(cmd *cobra.Command, args []string) error {
+	rigName, machineName := args[0], args[1]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+
+	rigsPath := constants.MayorRigsPath(townRoot)
+	rigsConfig, err := config.LoadRigsConfig(rigsPath)
+	if err != nil {
+		return fmt.Errorf("loading rigs config: %w", err)
+	}
+	entry, ok := rigsConfig.Rigs[rigName]
+	if !ok {
+		return fmt.Errorf("rig '%s' not found", rigName)
+	}
+
+	if machineName == "local" {
+		entry.Machine = ""
+	} else {
+		registry, err := loadMachineRegistry(townRoot)
+		if err != nil {
+			return err
+		}
+		defer registry.Close()
+
+		r, err := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot)).GetRig(rigName)
+		if err != nil {
+			return err
+		}
+		r.Machine = machineName
+		conn, root, err := rigConnection(townRoot, r)
+		if err != nil {
+			return err
+		}
+		if err := ensureRemoteRepo(conn, root, entry.GitURL); err != nil {
+			return err
+		}
+		entry.Machine = machineName
+	}
+
+	rigsConfig.Rigs[rigName] = entry
+	if err := config.SaveRigsConfig(rigsPath, rigsConfig); err != nil {
+		return fmt.Errorf("saving rigs config: %w", err)
+	}
+	fmt.Printf("%s Rig %s now runs on %s\n", style.SuccessPrefix, style.Bold.Render(rigName), machineName)
+	return nil
+}
+
+// ensureRemoteRepo makes sure root on conn has a repo base for polecat
+// worktrees, creating a bare clone of gitURL if it has neither .repo.git
+// nor mayor/rig.
+func ensureRemoteRepo(conn connection.Connection, root, gitURL string) error {
+	for _, p := range []string{root + "/.repo.git", root + "/mayor/rig"} {
+		if ok, err := conn.Exists(p); err != nil {
+			return fmt.Errorf("checking %s on %s: %w", p, conn.Name(), err)
+		} else if ok {
+			return nil
+		}
+	}
+
+	fmt.Printf("Cloning %s into %s:%s/.repo.git...\n", gitURL, conn.Name(), root)
+	if err := conn.MkdirAll(root+"/polecats", 0755); err != nil {
+		return fmt.Errorf("creating rig directory: %w", err)
+	}
+	bare := root + "/.repo.git"
+	if out, err := conn.Exec("git", "clone", "--bare", gitURL, bare); err != nil {
+		return fmt.Errorf("cloning on %s: %s", conn.Name(), strings.TrimSpace(string(out)))
+	}
+	// Bare clones don't map remote branches; worktrees start from origin/<branch>
+	if out, err := conn.Exec("git", "--git-dir="+bare, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return fmt.Errorf("configuring fetch refspec: %s", strings.TrimSpace(string(out)))
+	}
+	return conn.GitWithDir(bare, "").Fetch("origin")
+}
@@ -18,6 +18,9 @@ var (
 	mailNotify        bool
 	mailSendSelf      bool
 	mailCC            []string // CC recipients
+	mailSendAt        string
+	mailSendIn        string
+	mailSendTTL       string
 	mailInboxJSON     bool
 	mailReadJSON      bool
 	mailInboxUnread   bool
@@ -116,6 +119,15 @@ Priority levels:
 
 Use --urgent as shortcut for --priority 0.
 
+Scheduling:
+  --at <time>    Hold the message until a time (HH:MM, "YYYY-MM-DD HH:MM", RFC 3339)
+  --in <delay>   Hold the message for a while (30m, 2h, 3d)
+  --ttl <delay>  Archive the message if it is still unread after this long
+
+Scheduled mail stays out of the recipient's inbox until it is due; the
+daemon then delivers it and notifies the recipient. The TTL counts from
+delivery.
+
 Examples:
   wt mail send greenplace/Toast -s "Status check" -m "How's that bug fix going?"
   wt mail send mayor/ -s "Work complete" -m "Finished gt-abc"
@@ -125,7 +137,10 @@ Examples:
   wt mail send mayor/ -s "Re: Status" -m "Done" --reply-to msg-abc123
   wt mail send --self -s "Handoff" -m "Context for next session"
   wt mail send greenplace/Toast -s "Update" -m "Progress report" --cc overseer
-  wt mail send list:oncall -s "Alert" -m "System down"`,
+  wt mail send list:oncall -s "Alert" -m "System down"
+  wt mail send greenplace/witness -s "Check merge of gt-abc" --in 2h
+  wt mail send --self -s "Standup" -m "Post status" --at 09:30
+  wt mail send greenplace/Toast -s "FYI" -m "Deploy at 3" --ttl 4h`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runMailSend,
 }
@@ -428,6 +443,10 @@ func init() {
 	mailSendCmd.Flags().BoolVar(&mailPermanent, "permanent", false, "Send as permanent (not ephemeral, synced to remote)")
 	mailSendCmd.Flags().BoolVar(&mailSendSelf, "self", false, "Send to self (auto-detect from cwd)")
 	mailSendCmd.Flags().StringArrayVar(&mailCC, "cc", nil, "CC recipients (can be used multiple times)")
+	mailSendCmd.Flags().StringVar(&mailSendAt, "at", "", "Deliver at a time (HH:MM, \"YYYY-MM-DD HH:MM\", RFC 3339)")
+	mailSendCmd.Flags().StringVar(&mailSendIn, "in", "", "Deliver after a delay (e.g. 30m, 2h, 3d)")
+	mailSendCmd.Flags().StringVar(&mailSendTTL, "ttl", "", "Archive if still unread this long after delivery (e.g. 4h)")
+	mailSendCmd.MarkFlagsMutuallyExclusive("at", "in")
 	_ = mailSendCmd.MarkFlagRequired("subject") // cobra flags: error only at runtime if missing
 
 	// Inbox flags
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/mailgw"
+	"github.com/speaker20/whaletown/internal/style"
+	"github.com/speaker20/whaletown/internal/workspace"
+)
+
+// Gateway command flags
+var mailGatewayJSON bool
+
+var mailGatewayCmd = &cobra.Command{
+	Use:   "gateway",
+	Short: "Bridge the overseer's mail to email",
+	Long: `Bridge the overseer's mail to email.
+
+The email gateway sends mail addressed to the overseer to
+contacts.human_email through the escalation SMTP relay, and turns email
+replies read over IMAP into mail replies in the same thread. The daemon
+runs a pass on every heartbeat; 'wt mail gateway sync' runs one now.
+
+Configure it in settings/escalation.json:
+
+  "contacts": {"human_email": "me@example.com"},
+  "smtp": {"host": "smtp.example.com", "from": "town@example.com",
+           "username": "town@example.com", "password_env": "WT_SMTP_PASSWORD"},
+  "email_gateway": {
+    "enabled": true,
+    "escalations": true,
+    "imap": {"host": "imap.example.com", "username": "town@example.com",
+             "password_env": "WT_IMAP_PASSWORD"},
+    "allow_from": ["me@example.com"]
+  }
+
+Replies are matched to mail through their In-Reply-To and References
+headers and accepted only from allow_from (default: contacts.human_email).
+With "escalations", every escalation also mails the overseer, so it goes
+out by email; drop "email:human" from the routes to avoid duplicates.`,
+	RunE: requireSubcommand,
+}
+
+var mailGatewaySyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Send pending overseer mail and collect email replies",
+	Args:  cobra.NoArgs,
+	RunE:  runMailGatewaySync,
+}
+
+var mailGatewayStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the email gateway configuration",
+	Args:  cobra.NoArgs,
+	RunE:  runMailGatewayStatus,
+}
+
+func init() {
+	mailGatewaySyncCmd.Flags().BoolVar(&mailGatewayJSON, "json", false, "Output as JSON")
+	mailGatewayStatusCmd.Flags().BoolVar(&mailGatewayJSON, "json", false, "Output as JSON")
+
+	mailGatewayCmd.AddCommand(mailGatewaySyncCmd)
+	mailGatewayCmd.AddCommand(mailGatewayStatusCmd)
+
+	mailCmd.AddCommand(mailGatewayCmd)
+}
+
+func runMailGatewaySync(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	gw, err := mailgw.New(townRoot)
+	if err != nil {
+		if errors.Is(err, mailgw.ErrDisabled) {
+			return fmt.Errorf("%w (set email_gateway.enabled in settings/escalation.json)", err)
+		}
+		return err
+	}
+
+	result, syncErr := gw.Sync(context.Background())
+	if mailGatewayJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		return syncErr
+	}
+
+	if result != nil {
+		fmt.Printf("%s Emailed %d message(s) to %s\n", style.Bold.Render("📤"), len(result.Sent), strings.Join(gw.SMTP.To, ", "))
+		for _, id := range result.Sent {
+			fmt.Printf("  %s\n", id)
+		}
+		if gw.IMAP != nil {
+			fmt.Printf("%s Delivered %d email reply(ies)\n", style.Bold.Render("📥"), len(result.Replies))
+			for _, id := range result.Replies {
+				fmt.Printf("  reply to %s\n", id)
+			}
+		}
+		for _, r := range result.Rejected {
+			fmt.Printf("  %s\n", style.Dim.Render("rejected "+r))
+		}
+	}
+	return syncErr
+}
+
+func runMailGatewayStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	cfg, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(townRoot))
+	if err != nil {
+		return fmt.Errorf("loading escalation config: %w", err)
+	}
+
+	gw, gwErr := mailgw.FromConfig(townRoot, cfg)
+	status := map[string]interface{}{"enabled": gwErr == nil}
+	if gwErr != nil && !errors.Is(gwErr, mailgw.ErrDisabled) {
+		status["error"] = gwErr.Error()
+	}
+	if gw != nil {
+		status["to"] = gw.SMTP.To
+		port := gw.SMTP.Port
+		if port == 0 {
+			port = 587
+		}
+		status["smtp"] = fmt.Sprintf("%s:%d", gw.SMTP.Host, port)
+		status["allow_from"] = gw.AllowFrom
+		status["domain"] = gw.Domain
+		status["escalations"] = cfg.RelaysEscalations()
+		if gw.IMAP != nil {
+			mailbox := gw.IMAP.Mailbox
+			if mailbox == "" {
+				mailbox = "INBOX"
+			}
+			status["imap"] = fmt.Sprintf("%s %s (user %s)", gw.IMAP.Host, mailbox, gw.IMAP.Username)
+		}
+	}
+
+	if mailGatewayJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	}
+
+	if gw == nil {
+		if err, ok := status["error"]; ok {
+			fmt.Printf("Email gateway: %s (%s)\n", style.Bold.Render("misconfigured"), err)
+		} else {
+			fmt.Printf("Email gateway: %s\n", style.Dim.Render("disabled"))
+		}
+		return nil
+	}
+	fmt.Printf("Email gateway: %s\n", style.Bold.Render("enabled"))
+	fmt.Printf("  to:          %s\n", strings.Join(gw.SMTP.To, ", "))
+	fmt.Printf("  smtp:        %s\n", status["smtp"])
+	if gw.IMAP != nil {
+		fmt.Printf("  imap:        %s\n", status["imap"])
+	} else {
+		fmt.Printf("  imap:        %s\n", style.Dim.Render("not configured (send only)"))
+	}
+	allow := strings.Join(gw.AllowFrom, ", ")
+	if allow == "" {
+		allow = style.Dim.Render("nobody (replies are rejected)")
+	}
+	fmt.Printf("  allow from:  %s\n", allow)
+	fmt.Printf("  escalations: %v\n", cfg.RelaysEscalations())
+	return nil
+}
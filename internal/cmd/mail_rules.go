@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/speaker20/whaletown/internal/mail"
+	"github.com/speaker20/whaletown/internal/style"
+	"github.com/speaker20/whaletown/internal/workspace"
+)
+
+// Rules command flags
+var (
+	mailRulesJSON     bool
+	mailRulesIdentity string
+)
+
+var mailRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Show and test mailbox filter rules",
+	Long: `Show and test mailbox filter rules.
+
+Filter rules are set per mailbox under "mail_rules" in settings/config.json.
+They run when a message is sent, or when the inbox is next listed for mail
+that arrived otherwise (scheduled mail, mail from peer towns). Rules run
+once per message.
+
+  "mail_rules": {
+    "*/witness": [
+      {"name": "protocol", "match": {"subject": "^(POLECAT_DONE|MERGED|LIFECYCLE)"},
+       "actions": ["label:protocol", "mark-read"]}
+    ],
+    "mayor/": [
+      {"match": {"from": "*/witness", "priority": "urgent"}, "actions": ["escalate:high"]}
+    ]
+  }
+
+Match fields (all must match): from, subject (regex), type, priority, label.
+Actions: label:<name>, mark-read, pin, archive, forward:<address>,
+escalate[:<severity>], handle (pass to the mailbox's protocol handler).
+"stop": true skips later rules for a matched message.
+
+Examples:
+  wt mail rules list                  # Rules for your mailbox
+  wt mail rules list mayor/           # Rules for the mayor's mailbox
+  wt mail rules test hq-abc123        # What the rules would do to a message`,
+	RunE: requireSubcommand,
+}
+
+var mailRulesListCmd = &cobra.Command{
+	Use:   "list [address]",
+	Short: "List the rules that apply to a mailbox",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runMailRulesList,
+}
+
+var mailRulesTestCmd = &cobra.Command{
+	Use:   "test <message-id>",
+	Short: "Dry-run the rules on a message",
+	Long: `Show which rules match a message and what they would do, without
+changing anything.
+
+The rules are those of the message's recipient; use --identity to try
+another mailbox's rules.
+
+Examples:
+  wt mail rules test hq-abc123
+  wt mail rules test hq-abc123 --identity mayor/
+  wt mail rules test hq-abc123 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMailRulesTest,
+}
+
+func init() {
+	mailRulesListCmd.Flags().BoolVar(&mailRulesJSON, "json", false, "Output as JSON")
+	mailRulesTestCmd.Flags().BoolVar(&mailRulesJSON, "json", false, "Output as JSON")
+	mailRulesTestCmd.Flags().StringVar(&mailRulesIdentity, "identity", "", "Mailbox whose rules to apply (default: the recipient's)")
+
+	mailRulesCmd.AddCommand(mailRulesListCmd)
+	mailRulesCmd.AddCommand(mailRulesTestCmd)
+
+	mailCmd.AddCommand(mailRulesCmd)
+}
+
+func runMailRulesList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	address := detectSender()
+	if len(args) > 0 {
+		address = args[0]
+	}
+
+	rules, err := mail.LoadRules(townRoot, address)
+	if err != nil {
+		return err
+	}
+
+	if mailRulesJSON {
+		list := make([]interface{}, 0, len(rules.Rules))
+		for _, r := range rules.Rules {
+			list = append(list, r.MailRule)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+
+	if len(rules.Rules) == 0 {
+		fmt.Printf("No mail rules for %s.\n", address)
+		return nil
+	}
+	fmt.Printf("%s Mail rules for %s\n\n", style.Bold.Render("📋"), address)
+	for i, r := range rules.Rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		fmt.Printf("  %s\n", style.Bold.Render(name))
+		fmt.Printf("    match:   %s\n", describeRuleMatch(r))
+		fmt.Printf("    actions: %s\n", strings.Join(r.Actions, ", "))
+		if r.Stop {
+			fmt.Printf("    %s\n", style.Dim.Render("stops later rules"))
+		}
+	}
+	return nil
+}
+
+func runMailRulesTest(cmd *cobra.Command, args []string) error {
+	workDir, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+
+	router := mail.NewRouter(workDir)
+	mailbox, err := router.GetMailbox(detectSender())
+	if err != nil {
+		return fmt.Errorf("getting mailbox: %w", err)
+	}
+	msg, err := mailbox.Get(args[0])
+	if err != nil {
+		return fmt.Errorf("getting message: %w", err)
+	}
+
+	address := msg.To
+	if mailRulesIdentity != "" {
+		address = mailRulesIdentity
+	}
+	rules, err := mail.LoadRules(workDir, address)
+	if err != nil {
+		return err
+	}
+	outcome := rules.Evaluate(msg)
+
+	if mailRulesJSON {
+		result := map[string]interface{}{
+			"message":     msg.ID,
+			"mailbox":     address,
+			"rules":       len(rules.Rules),
+			"already_run": msg.RulesApplied(),
+			"outcome":     outcome,
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	fmt.Printf("Message %s: %q from %s\n", msg.ID, msg.Subject, msg.From)
+	fmt.Printf("Mailbox: %s (%d rule(s))\n\n", address, len(rules.Rules))
+	if outcome.Empty() {
+		fmt.Println("No rules match.")
+		return nil
+	}
+
+	fmt.Printf("Matched: %s\n", strings.Join(outcome.Matched, ", "))
+	fmt.Println("Would:")
+	for _, label := range outcome.Labels {
+		fmt.Printf("  label %s\n", label)
+	}
+	if outcome.MarkRead {
+		fmt.Println("  mark read")
+	}
+	if outcome.Pin {
+		fmt.Println("  pin")
+	}
+	for _, to := range outcome.Forward {
+		fmt.Printf("  forward to %s\n", to)
+	}
+	if outcome.Escalate != "" {
+		fmt.Printf("  escalate (%s)\n", outcome.Escalate)
+	}
+	if outcome.Handle {
+		fmt.Println("  pass to the protocol handler (archived if handled)")
+	}
+	if outcome.Archive {
+		fmt.Println("  archive")
+	}
+	fmt.Println()
+	if msg.RulesApplied() {
+		fmt.Printf("%s\n", style.Dim.Render("Rules have already run on this message; they won't run again."))
+	}
+	fmt.Printf("%s\n", style.Dim.Render("Dry run: nothing was changed."))
+	return nil
+}
+
+// describeRuleMatch renders a rule's conditions for display.
+func describeRuleMatch(r *mail.Rule) string {
+	var parts []string
+	m := r.Match
+	if m.From != "" {
+		parts = append(parts, "from="+m.From)
+	}
+	if m.Subject != "" {
+		parts = append(parts, fmt.Sprintf("subject=/%s/", m.Subject))
+	}
+	if m.Type != "" {
+		parts = append(parts, "type="+m.Type)
+	}
+	if m.Priority != "" {
+		parts = append(parts, "priority="+m.Priority)
+	}
+	if m.Label != "" {
+		parts = append(parts, "label="+m.Label)
+	}
+	if len(parts) == 0 {
+		return "every message"
+	}
+	return strings.Join(parts, " ")
+}
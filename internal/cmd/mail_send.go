@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/speaker20/whaletown/internal/beads"
@@ -88,6 +89,11 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 	// Set CC recipients
 	msg.CC = mailCC
 
+	// Schedule delivery and expiry
+	if err := applyMailSchedule(msg, time.Now()); err != nil {
+		return err
+	}
+
 	// Handle reply-to: auto-set type to reply and look up thread
 	if mailReplyTo != "" {
 		msg.ReplyTo = mailReplyTo
@@ -125,6 +131,7 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 		_ = events.LogFeed(events.TypeMail, from, events.MailPayload(to, mailSubject))
 		fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
 		fmt.Printf("  Subject: %s\n", mailSubject)
+		printMailSchedule(msg)
 		return nil
 	}
 
@@ -178,10 +185,55 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 	if msg.Type != mail.TypeNotification {
 		fmt.Printf("  Type: %s\n", msg.Type)
 	}
+	printMailSchedule(msg)
+
+	return nil
+}
 
+// applyMailSchedule sets a message's delivery and expiry times from the
+// --at, --in and --ttl flags. The TTL runs from delivery.
+func applyMailSchedule(msg *mail.Message, now time.Time) error {
+	deliver := now
+	switch {
+	case mailSendAt != "":
+		at, err := mail.ParseDeliverAt(mailSendAt, now)
+		if err != nil {
+			return fmt.Errorf("--at: %w", err)
+		}
+		if !at.After(now) {
+			return fmt.Errorf("--at %s is in the past", mailSendAt)
+		}
+		deliver = at
+		msg.DeliverAt = &at
+	case mailSendIn != "":
+		delay, err := mail.ParseDelay(mailSendIn)
+		if err != nil {
+			return fmt.Errorf("--in: %w", err)
+		}
+		deliver = now.Add(delay)
+		msg.DeliverAt = &deliver
+	}
+	if mailSendTTL != "" {
+		ttl, err := mail.ParseDelay(mailSendTTL)
+		if err != nil {
+			return fmt.Errorf("--ttl: %w", err)
+		}
+		expires := deliver.Add(ttl)
+		msg.ExpiresAt = &expires
+	}
 	return nil
 }
 
+// printMailSchedule prints a sent message's delivery and expiry times.
+func printMailSchedule(msg *mail.Message) {
+	if msg.DeliverAt != nil {
+		fmt.Printf("  Deliver at: %s\n", msg.DeliverAt.Local().Format("2006-01-02 15:04"))
+	}
+	if msg.ExpiresAt != nil {
+		fmt.Printf("  Expires at: %s\n", msg.ExpiresAt.Local().Format("2006-01-02 15:04"))
+	}
+}
+
 // generateThreadID creates a random thread ID for new message threads.
 func generateThreadID() string {
 	b := make([]byte, 6)
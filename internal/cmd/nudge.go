@@ -6,12 +6,12 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/beads"
 	"github.com/speaker20/whaletown/internal/config"
 	"github.com/speaker20/whaletown/internal/events"
 	"github.com/speaker20/whaletown/internal/session"
 	"github.com/speaker20/whaletown/internal/style"
-	"github.com/speaker20/whaletown/internal/tmux"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
 
@@ -122,7 +122,7 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	t := tmux.NewTmux()
+	t := backend.New(townRoot)
 
 	// Expand role shortcuts to session names
 	// These shortcuts let users type "mayor" instead of "wt-mayor"
@@ -310,7 +310,7 @@ func runNudgeChannel(channelName, message string) error {
 	}
 
 	// Send nudges
-	t := tmux.NewTmux()
+	t := backend.New(townRoot)
 	var succeeded, failed int
 	var failures []string
 
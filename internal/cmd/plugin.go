@@ -6,6 +6,8 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/speaker20/whaletown/internal/config"
@@ -23,6 +25,7 @@ var (
 	pluginRunDryRun   bool
 	pluginHistoryJSON bool
 	pluginHistoryLimit int
+	pluginDueJSON     bool
 )
 
 var pluginCmd = &cobra.Command{
@@ -47,6 +50,7 @@ GATE TYPES:
 Examples:
   wt plugin list                    # List all discovered plugins
   wt plugin show <name>             # Show plugin details
+  wt plugin due                     # Show which plugins are due
   wt plugin list --json             # JSON output`,
 	RunE: requireSubcommand,
 }
@@ -113,6 +117,23 @@ Examples:
 	RunE: runPluginHistory,
 }
 
+var pluginDueCmd = &cobra.Command{
+	Use:   "due",
+	Short: "Show which plugins are due and when they next fire",
+	Long: `Evaluate every plugin's gate and show whether it is due to run.
+
+Cooldown and cron gates show their next fire time. Condition gates run
+their check command (with a timeout), and event gates look for matching
+events in the town's event log since the plugin last ran.
+
+The daemon uses the same evaluation to dispatch due plugins to dogs.
+
+Examples:
+  wt plugin due
+  wt plugin due --json`,
+	RunE: runPluginDue,
+}
+
 func init() {
 	// List subcommand flags
 	pluginListCmd.Flags().BoolVar(&pluginListJSON, "json", false, "Output as JSON")
@@ -128,11 +149,15 @@ func init() {
 	pluginHistoryCmd.Flags().BoolVar(&pluginHistoryJSON, "json", false, "Output as JSON")
 	pluginHistoryCmd.Flags().IntVar(&pluginHistoryLimit, "limit", 10, "Maximum number of runs to show")
 
+	// Due subcommand flags
+	pluginDueCmd.Flags().BoolVar(&pluginDueJSON, "json", false, "Output as JSON")
+
 	// Add subcommands
 	pluginCmd.AddCommand(pluginListCmd)
 	pluginCmd.AddCommand(pluginShowCmd)
 	pluginCmd.AddCommand(pluginRunCmd)
 	pluginCmd.AddCommand(pluginHistoryCmd)
+	pluginCmd.AddCommand(pluginDueCmd)
 
 	rootCmd.AddCommand(pluginCmd)
 }
@@ -383,22 +408,17 @@ func runPluginRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Check gate status for cooldown gates
+	// Check gate status
 	gateOpen := true
 	gateReason := ""
-	if p.Gate != nil && p.Gate.Type == plugin.GateCooldown && !pluginRunForce {
-		recorder := plugin.NewRecorder(townRoot)
-		duration := p.Gate.Duration
-		if duration == "" {
-			duration = "1h" // default
-		}
-		count, err := recorder.CountRunsSince(p.Name, duration)
+	if p.Gate != nil && p.Gate.Type != plugin.GateManual && !pluginRunForce {
+		status, err := plugin.NewEvaluator(townRoot).Evaluate(p)
 		if err != nil {
 			// Log warning but continue
 			fmt.Fprintf(os.Stderr, "Warning: checking gate status: %v\n", err)
-		} else if count > 0 {
+		} else if !status.Due {
 			gateOpen = false
-			gateReason = fmt.Sprintf("ran %d time(s) within %s cooldown", count, duration)
+			gateReason = status.Reason
 		}
 	}
 
@@ -505,3 +525,66 @@ func runPluginHistory(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runPluginDue(cmd *cobra.Command, args []string) error {
+	scanner, townRoot, err := getPluginScanner()
+	if err != nil {
+		return err
+	}
+
+	plugins, err := scanner.DiscoverAll()
+	if err != nil {
+		return fmt.Errorf("discovering plugins: %w", err)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+
+	evaluator := plugin.NewEvaluator(townRoot)
+	statuses := make([]*plugin.GateStatus, 0, len(plugins))
+	for _, p := range plugins {
+		status, err := evaluator.Evaluate(p)
+		if err != nil {
+			status = &plugin.GateStatus{Plugin: p.Name, RigName: p.RigName, Reason: "error: " + err.Error()}
+			if p.Gate != nil {
+				status.Gate = p.Gate.Type
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	if pluginDueJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Printf("%s No plugins discovered\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PLUGIN\tGATE\tDUE\tLAST RUN\tNEXT FIRE\tREASON")
+	for _, st := range statuses {
+		name := st.Plugin
+		if st.RigName != "" {
+			name = st.RigName + "/" + st.Plugin
+		}
+		due := "no"
+		if st.Due {
+			due = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			name, st.Gate, due, formatPluginTime(st.LastRun), formatPluginTime(st.NextFire), st.Reason)
+	}
+	return tw.Flush()
+}
+
+// formatPluginTime formats an optional gate time in local time.
+func formatPluginTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
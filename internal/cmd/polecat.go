@@ -337,48 +337,51 @@ type PolecatListItem struct {
 
 // getPolecatManager creates a polecat manager for the given rig.
 func getPolecatManager(rigName string) (*polecat.Manager, *rig.Rig, error) {
-	_, r, err := getRig(rigName)
+	townRoot, r, err := getRig(rigName)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	polecatGit := git.NewGit(r.Path)
-	t := tmux.NewTmux()
-	mgr := polecat.NewManager(r, polecatGit, t)
+	mgr, _, err := rigPolecatManagers(townRoot, r)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	return mgr, r, nil
 }
 
 func runPolecatList(cmd *cobra.Command, args []string) error {
 	var rigs []*rig.Rig
+	var townRoot string
 
 	if polecatListAll {
 		// List all rigs
-		allRigs, _, err := getAllRigs()
+		allRigs, root, err := getAllRigs()
 		if err != nil {
 			return err
 		}
-		rigs = allRigs
+		rigs, townRoot = allRigs, root
 	} else {
 		// Need a rig name
 		if len(args) < 1 {
 			return fmt.Errorf("rig name required (or use --all)")
 		}
-		_, r, err := getPolecatManager(args[0])
+		root, r, err := getRig(args[0])
 		if err != nil {
 			return err
 		}
-		rigs = []*rig.Rig{r}
+		rigs, townRoot = []*rig.Rig{r}, root
 	}
 
 	// Collect polecats from all rigs
-	t := tmux.NewTmux()
 	var allPolecats []PolecatListItem
 
 	for _, r := range rigs {
-		polecatGit := git.NewGit(r.Path)
-		mgr := polecat.NewManager(r, polecatGit, t)
-		polecatMgr := polecat.NewSessionManager(t, r)
+		mgr, polecatMgr, err := rigPolecatManagers(townRoot, r)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to list polecats in %s: %v\n", r.Name, err)
+			continue
+		}
 
 		polecats, err := mgr.List()
 		if err != nil {
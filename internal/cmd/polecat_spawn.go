@@ -13,7 +13,6 @@ import (
 	"github.com/speaker20/whaletown/internal/polecat"
 	"github.com/speaker20/whaletown/internal/rig"
 	"github.com/speaker20/whaletown/internal/style"
-	"github.com/speaker20/whaletown/internal/tmux"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
 
@@ -22,8 +21,9 @@ type SpawnedPolecatInfo struct {
 	RigName     string // Rig name (e.g., "whaletown")
 	PolecatName string // Polecat name (e.g., "Toast")
 	ClonePath   string // Path to polecat's git worktree
+	BeadsDir    string // Where to run bd for this polecat (the worktree, or the rig for remote rigs)
 	SessionName string // Tmux session name (e.g., "wt-whaletown-p-Toast")
-	Pane        string // Tmux pane ID
+	Pane        string // Tmux pane ID; empty for polecats on other machines
 }
 
 // AgentID returns the agent identifier (e.g., "whaletown/polecats/Toast")
@@ -64,10 +64,17 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		return nil, fmt.Errorf("rig '%s' not found", rigName)
 	}
 
+	// Worktrees and sessions run where the rig lives (see wt machine)
+	conn, root, err := rigConnection(townRoot, r)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get polecat manager (with tmux for session-aware allocation)
-	polecatGit := git.NewGit(r.Path)
-	t := tmux.NewTmux()
+	polecatGit := conn.Git(root)
+	t := conn.Tmux()
 	polecatMgr := polecat.NewManager(r, polecatGit, t)
+	polecatMgr.SetConnection(conn, root)
 
 	// Allocate a new polecat name
 	polecatName, err := polecatMgr.AllocateName()
@@ -88,7 +95,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		// Stale state: polecat exists despite fresh name allocation - repair it
 		// Check for uncommitted work first
 		if !opts.Force {
-			pGit := git.NewGit(existingPolecat.ClonePath)
+			pGit := conn.Git(existingPolecat.ClonePath)
 			workStatus, checkErr := pGit.CheckUncommittedWork()
 			if checkErr == nil && !workStatus.Clean() {
 				return nil, fmt.Errorf("polecat '%s' has uncommitted work: %s\nUse --force to proceed anyway",
@@ -127,6 +134,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 
 	// Start session (reuse tmux from manager)
 	polecatSessMgr := polecat.NewSessionManager(t, r)
+	polecatSessMgr.SetConnection(conn, root)
 
 	// Check if already running
 	running, _ := polecatSessMgr.IsRunning(polecatName)
@@ -136,7 +144,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 			RuntimeConfigDir: claudeConfigDir,
 		}
 		if opts.Agent != "" {
-			cmd, err := config.BuildPolecatStartupCommandWithAgentOverride(rigName, polecatName, r.Path, "", opts.Agent)
+			cmd, err := config.BuildPolecatStartupCommandWithAgentOverride(rigName, polecatName, root, "", opts.Agent)
 			if err != nil {
 				return nil, err
 			}
@@ -147,11 +155,18 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		}
 	}
 
-	// Get session name and pane
+	// Get session name and pane. Panes on other machines can't be nudged
+	// with local tmux, so remote polecats pick up work via wt prime.
 	sessionName := polecatSessMgr.SessionName(polecatName)
-	pane, err := getSessionPane(sessionName)
-	if err != nil {
-		return nil, fmt.Errorf("getting pane for %s: %w", sessionName, err)
+	beadsDir := polecatObj.ClonePath
+	var pane string
+	if conn.IsLocal() {
+		pane, err = t.GetPaneID(sessionName)
+		if err != nil {
+			return nil, fmt.Errorf("getting pane for %s: %w", sessionName, err)
+		}
+	} else {
+		beadsDir = r.Path
 	}
 
 	fmt.Printf("%s Polecat %s spawned\n", style.Bold.Render("✓"), polecatName)
@@ -163,6 +178,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		RigName:     rigName,
 		PolecatName: polecatName,
 		ClonePath:   polecatObj.ClonePath,
+		BeadsDir:    beadsDir,
 		SessionName: sessionName,
 		Pane:        pane,
 	}, nil
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/backend"
+	"github.com/speaker20/whaletown/internal/pty"
+	"github.com/speaker20/whaletown/internal/style"
+	"github.com/speaker20/whaletown/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Pty command flags
+var (
+	ptyListJSON   bool
+	ptyScrollback int
+)
+
+var ptyCmd = &cobra.Command{
+	Use:     "pty",
+	GroupID: GroupServices,
+	Short:   "Manage headless agent sessions (pty backend)",
+	RunE:    requireSubcommand,
+	Long: `Manage agent sessions run by the built-in pty supervisor.
+
+Towns without tmux (CI runners, containers, servers) can run their agents
+headless by setting the session backend to pty:
+
+  settings/config.json:  "session_backend": "pty"
+  or per process:        WT_SESSION_BACKEND=pty
+
+The supervisor runs each agent in a pseudo-terminal and keeps its recent
+output in a ring buffer, so 'wt peek', nudges and health checks work as
+they do with tmux. It is started on demand and kept running by the
+daemon. Its socket and log are in daemon/.
+
+Commands:
+  wt pty list              List sessions
+  wt pty attach <session>  Join a session's terminal (Ctrl-\ detaches)`,
+}
+
+var ptyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pty sessions",
+	RunE:  runPtyList,
+}
+
+var ptyAttachCmd = &cobra.Command{
+	Use:   "attach <session>",
+	Short: "Attach to a pty session",
+	Long: `Join a pty session's terminal.
+
+Recent scrollback is replayed, then the session's output is streamed and
+your keystrokes are sent to it. Press Ctrl-\ to detach; the session keeps
+running.
+
+Examples:
+  wt pty attach hq-mayor
+  wt pty attach wt-whaletown-Toast`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPtyAttach,
+}
+
+var ptyServeCmd = &cobra.Command{
+	Use:    "serve",
+	Short:  "Run the pty supervisor (started automatically)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runPtyServe,
+}
+
+func init() {
+	ptyListCmd.Flags().BoolVar(&ptyListJSON, "json", false, "Output as JSON")
+	ptyServeCmd.Flags().IntVar(&ptyScrollback, "scrollback", pty.DefaultScrollback, "Bytes of output kept per session")
+
+	ptyCmd.AddCommand(ptyListCmd)
+	ptyCmd.AddCommand(ptyAttachCmd)
+	ptyCmd.AddCommand(ptyServeCmd)
+	rootCmd.AddCommand(ptyCmd)
+}
+
+func runPtyList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	sessions, err := pty.NewClient(townRoot).Sessions()
+	if err != nil {
+		return err
+	}
+
+	if ptyListJSON {
+		if sessions == nil {
+			sessions = []pty.SessionStatus{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sessions)
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println("No pty sessions.")
+		if name := backend.Name(townRoot); name != backend.PTY {
+			fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf("This town's session backend is %s.", name)))
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tPID\tCOMMAND\tSTARTED\tLAST OUTPUT\tATTACHED")
+	for _, s := range sessions {
+		command := s.ForegroundCommand
+		if command == "" {
+			command = "-"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s ago\t%d\n", s.Name, s.PID, command,
+			s.Created.Format("2006-01-02 15:04"), time.Since(s.Activity).Round(time.Second), s.Attached)
+	}
+	return w.Flush()
+}
+
+func runPtyAttach(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	return pty.NewClient(townRoot).AttachSession(args[0])
+}
+
+func runPtyServe(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	supervisor := pty.NewSupervisor(pty.SocketPath(townRoot), ptyScrollback, logger)
+	if err := supervisor.Listen(); err != nil {
+		return err
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		logger.Printf("Received %v, stopping sessions", sig)
+		_ = supervisor.Close()
+	}()
+
+	return supervisor.Serve()
+}
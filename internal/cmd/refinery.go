@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/speaker20/whaletown/internal/beads"
@@ -217,6 +218,54 @@ Examples:
 
 var refineryBlockedJSON bool
 
+var refineryTrainCmd = &cobra.Command{
+	Use:   "train [rig]",
+	Short: "Merge a batch of ready MRs as one speculative merge train",
+	Long: `Merge a batch of ready MRs as one speculative merge train.
+
+Takes the top-scoring ready MRs (up to merge_queue.max_concurrent, all with
+the same target), stacks them onto a temporary branch cut from the target,
+and runs the test command once. If the tests pass, the target is
+fast-forwarded to the train and every MR is closed as merged.
+
+If the tests fail, the train is bisected to find the first MR that breaks
+them. That MR is failed as usual (MERGE_FAILED to the witness); the others
+go back to the queue for the next train. MRs that conflict while stacking
+drop out of the train and get a conflict-resolution task.
+
+Examples:
+  wt refinery train
+  wt refinery train whaletown --max 8
+  wt refinery train --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryTrain,
+}
+
+var refineryFlakyCmd = &cobra.Command{
+	Use:   "flaky [rig]",
+	Short: "Show tests that failed and then passed on retry",
+	Long: `Show the rig's flaky-test history.
+
+When the refinery retries the test command (merge_queue.retry_flaky_tests)
+and a test that failed on one attempt passes on a later one, the test is
+recorded as flaky. Test names come from ` + "`go test -json`" + ` output or, if
+merge_queue.test_report is set, JUnit XML reports.
+
+Examples:
+  wt refinery flaky
+  wt refinery flaky whaletown --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryFlaky,
+}
+
+var refineryFlakyJSON bool
+
+var (
+	refineryTrainMax    int
+	refineryTrainDryRun bool
+	refineryTrainJSON   bool
+)
+
 func init() {
 	// Start flags
 	refineryStartCmd.Flags().BoolVar(&refineryForeground, "foreground", false, "Run in foreground (default: background)")
@@ -243,6 +292,13 @@ func init() {
 	// Blocked flags
 	refineryBlockedCmd.Flags().BoolVar(&refineryBlockedJSON, "json", false, "Output as JSON")
 
+	// Train flags
+	refineryTrainCmd.Flags().IntVar(&refineryTrainMax, "max", 0, "Maximum MRs in the train (default: merge_queue.max_concurrent)")
+	refineryTrainCmd.Flags().BoolVar(&refineryTrainDryRun, "dry-run", false, "Show which MRs would form the train")
+	refineryTrainCmd.Flags().BoolVar(&refineryTrainJSON, "json", false, "Output as JSON")
+
+	refineryFlakyCmd.Flags().BoolVar(&refineryFlakyJSON, "json", false, "Output as JSON")
+
 	// Add subcommands
 	refineryCmd.AddCommand(refineryStartCmd)
 	refineryCmd.AddCommand(refineryStopCmd)
@@ -255,6 +311,8 @@ func init() {
 	refineryCmd.AddCommand(refineryUnclaimedCmd)
 	refineryCmd.AddCommand(refineryReadyCmd)
 	refineryCmd.AddCommand(refineryBlockedCmd)
+	refineryCmd.AddCommand(refineryTrainCmd)
+	refineryCmd.AddCommand(refineryFlakyCmd)
 
 	rootCmd.AddCommand(refineryCmd)
 }
@@ -766,3 +824,128 @@ func runRefineryBlocked(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runRefineryTrain(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	max := eng.Config().MaxConcurrent
+	if refineryTrainMax > 0 {
+		max = refineryTrainMax
+	}
+
+	ready, err := eng.ListReadyMRs()
+	if err != nil {
+		return fmt.Errorf("listing ready MRs: %w", err)
+	}
+	for _, mr := range ready {
+		if mr.Target == "" {
+			mr.Target = eng.Config().TargetBranch
+		}
+	}
+	train := refinery.SelectTrain(ready, max, time.Now())
+
+	if refineryTrainDryRun {
+		if refineryTrainJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(train)
+		}
+		if len(train) == 0 {
+			fmt.Printf("  %s\n", style.Dim.Render("(none ready)"))
+			return nil
+		}
+		fmt.Printf("%s Would run a train of %d MR(s) onto %s for '%s':\n\n", style.Bold.Render("🚂"), len(train), train[0].Target, rigName)
+		for i, mr := range train {
+			fmt.Printf("  %d. [P%d] %s  %s\n", i+1, mr.Priority, mr.ID, mr.Branch)
+		}
+		return nil
+	}
+
+	if len(train) == 0 {
+		if refineryTrainJSON {
+			fmt.Println("{}")
+			return nil
+		}
+		fmt.Printf("%s No MRs ready for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+
+	if refineryTrainJSON {
+		eng.SetOutput(os.Stderr)
+	}
+	result := eng.ProcessTrain(cmd.Context(), train)
+
+	if refineryTrainJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	fmt.Println()
+	if result.Error != "" {
+		fmt.Printf("%s Train aborted: %s\n", style.Warning.Render("⚠"), result.Error)
+	}
+	merged := len(result.Merged())
+	fmt.Printf("%s Train onto %s: %d merged, %d test run(s)\n", style.Bold.Render("🚂"), result.Target, merged, result.TestRuns)
+	for _, car := range result.Cars {
+		switch {
+		case car.Result.Success:
+			sha := car.Result.MergeCommit
+			if len(sha) > 8 {
+				sha = sha[:8]
+			}
+			fmt.Printf("  %s %s %s\n", style.Success.Render("✓"), car.MR.ID, style.Dim.Render(sha))
+		case car.Requeued:
+			fmt.Printf("  %s %s %s\n", style.Dim.Render("↺"), car.MR.ID, style.Dim.Render("requeued"))
+		default:
+			fmt.Printf("  %s %s %s\n", style.Error.Render("✗"), car.MR.ID, car.Result.Error)
+		}
+	}
+	return nil
+}
+
+func runRefineryFlaky(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	history, err := refinery.LoadFlakyHistory(r.Path)
+	if err != nil {
+		return err
+	}
+	tests := history.Sorted()
+
+	if refineryFlakyJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tests)
+	}
+
+	if len(tests) == 0 {
+		fmt.Printf("%s No flaky tests recorded for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+	fmt.Printf("%s Flaky tests for '%s':\n\n", style.Bold.Render("⚠"), rigName)
+	for _, t := range tests {
+		fmt.Printf("  %3d×  %s  %s\n", t.Flakes, t.Name, style.Dim.Render("last "+t.LastSeen.Format("2006-01-02 15:04")))
+	}
+	return nil
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 
 	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/connection"
 	"github.com/speaker20/whaletown/internal/constants"
 	"github.com/speaker20/whaletown/internal/git"
+	"github.com/speaker20/whaletown/internal/polecat"
 	"github.com/speaker20/whaletown/internal/rig"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
@@ -34,3 +36,44 @@ func getRig(rigName string) (string, *rig.Rig, error) {
 
 	return townRoot, r, nil
 }
+
+// rigConnection returns the connection a rig's worktrees and sessions
+// run on, and the rig's directory on it. Local rigs get a local
+// connection and their own path.
+func rigConnection(townRoot string, r *rig.Rig) (connection.Connection, string, error) {
+	if r.Machine == "" || r.Machine == "local" {
+		return connection.NewLocalConnection(), r.Path, nil
+	}
+	registry, err := loadMachineRegistry(townRoot)
+	if err != nil {
+		return nil, "", err
+	}
+	m, err := registry.Get(r.Machine)
+	if err != nil {
+		return nil, "", fmt.Errorf("rig %s: %w", r.Name, err)
+	}
+	root := m.RigPath(r.Name)
+	if root == "" {
+		return nil, "", fmt.Errorf("machine %s has no town path (wt machine add %s --town-path ...)", m.Name, m.Name)
+	}
+	conn, err := registry.Connection(r.Machine)
+	if err != nil {
+		return nil, "", err
+	}
+	return conn, root, nil
+}
+
+// rigPolecatManagers returns polecat and session managers for a rig that
+// operate on the machine the rig lives on.
+func rigPolecatManagers(townRoot string, r *rig.Rig) (*polecat.Manager, *polecat.SessionManager, error) {
+	conn, root, err := rigConnection(townRoot, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	t := conn.Tmux()
+	mgr := polecat.NewManager(r, conn.Git(root), t)
+	mgr.SetConnection(conn, root)
+	sessMgr := polecat.NewSessionManager(t, r)
+	sessMgr.SetConnection(conn, root)
+	return mgr, sessMgr, nil
+}
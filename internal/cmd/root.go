@@ -33,6 +33,7 @@ var beadsExemptCommands = map[string]bool{
 	"dashboard":  true, // Allows running on Render without beads
 	"trader":     true, // Trading agents can run standalone
 	"dig":        true, // Data mining tool
+	"pty":        true, // Session supervisor runs wherever agents do
 }
 
 // Commands exempt from the town root branch warning.
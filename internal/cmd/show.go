@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"syscall"
 
+	"github.com/speaker20/whaletown/internal/federation"
+	"github.com/speaker20/whaletown/internal/style"
+	"github.com/speaker20/whaletown/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -23,12 +27,17 @@ Delegates to 'bd show' - all bd show flags are supported.
 Works with any bead prefix (gt-, bd-, hq-, etc.) and routes
 to the correct beads database automatically.
 
+A hop:// or beads:// reference is looked up in the peer town it names
+(see 'wt federation'). When the town has peers, references in the bead's
+description are listed after it with their remote status.
+
 Examples:
   wt show gt-abc123          # Show a whaletown issue
   wt show hq-xyz789          # Show a town-level bead (convoy, mail, etc.)
   wt show bd-def456          # Show a beads issue
   wt show gt-abc123 --json   # Output as JSON
-  wt show gt-abc123 -v       # Verbose output`,
+  wt show gt-abc123 -v       # Verbose output
+  wt show hop://acme.com/acme/backend/ac-123   # Show an issue in a peer town`,
 	DisableFlagParsing: true, // Pass all flags through to bd show
 	RunE:               runShow,
 }
@@ -38,7 +47,102 @@ func runShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("bead ID required\n\nUsage: wt show <bead-id> [flags]")
 	}
 
-	return execBdShow(args)
+	if federation.IsRef(args[0]) {
+		return showRemoteIssue(args[0], hasFlag(args, "--json"))
+	}
+
+	// Without peers there are no remote references to resolve
+	townRoot, _ := workspace.FindFromCwd()
+	if townRoot == "" || hasFlag(args, "--json") {
+		return execBdShow(args)
+	}
+	registry, err := federation.Load(townRoot)
+	if err != nil || len(registry.List()) == 0 {
+		return execBdShow(args)
+	}
+	defer func() { _ = registry.Close() }()
+
+	showCmd := exec.Command("bd", append([]string{"show"}, args...)...)
+	showCmd.Stdin, showCmd.Stdout, showCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := showCmd.Run(); err != nil {
+		return err
+	}
+	printRemoteRefs(registry, args[0])
+	return nil
+}
+
+// hasFlag reports whether flag appears in pass-through args.
+func hasFlag(args []string, flag string) bool {
+	for _, a := range args {
+		if a == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// showRemoteIssue prints an issue looked up through a federated reference.
+func showRemoteIssue(ref string, asJSON bool) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Whale Town workspace: %w", err)
+	}
+	registry, err := federation.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = registry.Close() }()
+
+	issue, err := registry.Resolve(ref)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issue)
+	}
+
+	fmt.Printf("%s %s: %s\n", formatConvoyStatus(issue.Status), style.Bold.Render(issue.ID), issue.Title)
+	fmt.Printf("  Peer:     %s\n", issue.Peer)
+	fmt.Printf("  Status:   %s\n", issue.Status)
+	if issue.IssueType != "" {
+		fmt.Printf("  Type:     %s\n", issue.IssueType)
+	}
+	if issue.Assignee != "" {
+		fmt.Printf("  Assignee: %s\n", issue.Assignee)
+	}
+	fmt.Printf("  Ref:      %s\n", style.Dim.Render(ref))
+	return nil
+}
+
+// printRemoteRefs lists the federated references in a bead's description
+// with their status in the peer town.
+func printRemoteRefs(registry *federation.Registry, beadID string) {
+	out, err := exec.Command("bd", "show", beadID, "--json").Output()
+	if err != nil {
+		return
+	}
+	var issues []struct {
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(out, &issues); err != nil || len(issues) == 0 {
+		return
+	}
+	refs := federation.FindRefs(issues[0].Description)
+	if len(refs) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Remote references"))
+	for _, ref := range refs {
+		issue, err := registry.Resolve(ref)
+		if err != nil {
+			fmt.Printf("  ? %s %s\n", ref, style.Dim.Render("("+err.Error()+")"))
+			continue
+		}
+		fmt.Printf("  %s %s [%s] %s %s\n", formatConvoyStatus(issue.Status), issue.ID, issue.Peer, issue.Title, style.Dim.Render(ref))
+	}
 }
 
 // execBdShow replaces the current process with 'bd show'.
@@ -244,7 +244,7 @@ func runSling(cmd *cobra.Command, args []string) error {
 				}
 				targetAgent = spawnInfo.AgentID()
 				targetPane = spawnInfo.Pane
-				hookWorkDir = spawnInfo.ClonePath // Run bd commands from polecat's worktree
+				hookWorkDir = spawnInfo.BeadsDir // Run bd commands from polecat's worktree
 
 				// Wake witness and refinery to monitor the new polecat
 				wakeRigAgents(rigName)
@@ -275,7 +275,7 @@ func runSling(cmd *cobra.Command, args []string) error {
 						}
 						targetAgent = spawnInfo.AgentID()
 						targetPane = spawnInfo.Pane
-						hookWorkDir = spawnInfo.ClonePath
+						hookWorkDir = spawnInfo.BeadsDir
 
 						// Wake witness and refinery to monitor the new polecat
 						wakeRigAgents(rigName)
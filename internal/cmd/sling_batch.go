@@ -74,7 +74,7 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 		}
 
 		targetAgent := spawnInfo.AgentID()
-		hookWorkDir := spawnInfo.ClonePath
+		hookWorkDir := spawnInfo.BeadsDir
 
 		// Auto-convoy: check if issue is already tracked
 		if !slingNoConvoy {
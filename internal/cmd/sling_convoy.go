@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base32"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/speaker20/whaletown/internal/beads"
+	"github.com/speaker20/whaletown/internal/beadsdb"
 	"github.com/speaker20/whaletown/internal/style"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
@@ -31,29 +33,11 @@ func isTrackedByConvoy(beadID string) string {
 
 	// Query town beads for any convoy that tracks this issue
 	// Convoys use "tracks" dependency type: convoy -> tracked issue
-	townBeads := filepath.Join(townRoot, ".beads")
-	dbPath := filepath.Join(townBeads, "beads.db")
-
-	// Query dependencies where this bead is being tracked
-	// Also check for external reference format: external:rig:issue-id
-	query := fmt.Sprintf(`
-		SELECT d.issue_id
-		FROM dependencies d
-		JOIN issues i ON d.issue_id = i.id
-		WHERE d.type = 'tracks'
-		AND i.issue_type = 'convoy'
-		AND (d.depends_on_id = '%s' OR d.depends_on_id LIKE '%%:%s')
-		LIMIT 1
-	`, beadID, beadID)
-
-	queryCmd := exec.Command("sqlite3", dbPath, query)
-	out, err := queryCmd.Output()
-	if err != nil {
+	convoyIDs, err := beadsdb.Open(filepath.Join(townRoot, ".beads")).TrackingConvoys(context.Background(), beadID)
+	if err != nil || len(convoyIDs) == 0 {
 		return ""
 	}
-
-	convoyID := strings.TrimSpace(string(out))
-	return convoyID
+	return convoyIDs[0]
 }
 
 // createAutoConvoy creates an auto-convoy for a single issue and tracks it.
@@ -236,8 +236,8 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 
 	// Fetch town-level agent beads (Mayor, Deacon) from town beads
 	townBeadsPath := beads.GetTownBeadsPath(townRoot)
-	townBeadsClient := beads.New(townBeadsPath)
-	townAgentBeads, _ := townBeadsClient.ListAgentBeads()
+	townBeadsClient := beads.OpenStore(townBeadsPath)
+	townAgentBeads, _ := beads.ListAgentBeadsIn(townBeadsClient)
 	for id, issue := range townAgentBeads {
 		allAgentBeads[id] = issue
 	}
@@ -266,8 +266,8 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 	// Fetch rig-level agent beads
 	for _, r := range rigs {
 		rigBeadsPath := filepath.Join(r.Path, "mayor", "rig")
-		rigBeads := beads.New(rigBeadsPath)
-		rigAgentBeads, _ := rigBeads.ListAgentBeads()
+		rigBeads := beads.OpenStore(rigBeadsPath)
+		rigAgentBeads, _ := beads.ListAgentBeadsIn(rigBeads)
 		if rigAgentBeads == nil {
 			continue
 		}
@@ -1137,8 +1137,8 @@ func getMQSummary(r *rig.Rig) *MQSummary {
 		return nil
 	}
 
-	// Create beads instance for the rig
-	b := beads.New(r.BeadsPath())
+	// Create beads store for the rig
+	b := beads.OpenStore(r.BeadsPath())
 
 	// Query for all open merge-request type issues
 	opts := beads.ListOptions{
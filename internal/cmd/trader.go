@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 	"text/tabwriter"
 	"time"
@@ -29,23 +30,42 @@ Available agents:
   copytrade   - Monitors whale wallets and copies their trades
   researcher  - Generates buy/sell signals from sentiment analysis
 
+Named strategies are defined in ~/.whaletown/strategies.json. Each one
+follows its own wallets with its own sizing, risk limits and signer:
+
+  {
+    "strategies": [
+      {"name": "memecoin-fast", "wallets": ["5fWk...", "9HCT...", "6kbw..."],
+       "size_sol": 0.01, "risk": {"max_daily_sol": 0.5}},
+      {"name": "conservative", "top_wallets": 10, "signal": "consensus",
+       "consensus": {"min_wallets": 3, "window": "15m"},
+       "signer_env": "CONSERVATIVE_KEY", "risk": {"max_open_positions": 5}}
+    ]
+  }
+
 Examples:
-  wt trader start copytrade    # Start the copy trade agent
-  wt trader stop copytrade     # Stop the agent
-  wt trader list               # List running agents
-  wt trader status             # Show current trades/signals`,
+  wt trader start copytrade       # Start the copy trade agent
+  wt trader start memecoin-fast   # Start a named strategy
+  wt trader start --all           # Start every configured strategy
+  wt trader stop copytrade        # Stop the agent
+  wt trader list                  # List agents and strategy stats/P&L
+  wt trader status                # Show current trades/signals
+  wt trader export --from 2025-01-01 --to 2025-12-31 > trades.csv`,
 }
 
 var traderStartCmd = &cobra.Command{
-	Use:   "start <agent>",
-	Short: "Start a trading agent",
-	Long: `Start a trading agent. Available agents:
+	Use:   "start <agent|strategy>...",
+	Short: "Start trading agents or named strategies",
+	Long: `Start trading agents or named strategies. Available agents:
   copytrade   - Monitors whale wallets via Helius API
   researcher  - Generates signals from sentiment (coming soon)
 
-The agent runs in the background and continuously monitors for trades.
+Any other name is looked up in the strategies file
+(~/.whaletown/strategies.json). Several strategies can be started at once
+and run concurrently; use --all to start every configured strategy.
+
+The agents run in the foreground until interrupted.
 Set HELIUS_API_KEY environment variable for live Solana data.`,
-	Args: cobra.ExactArgs(1),
 	RunE: runTraderStart,
 }
 
@@ -69,7 +89,8 @@ var traderStatusCmd = &cobra.Command{
 }
 
 var (
-	traderJSON bool
+	traderJSON     bool
+	traderStartAll bool
 )
 
 func init() {
@@ -79,39 +100,55 @@ func init() {
 	traderCmd.AddCommand(traderListCmd)
 	traderCmd.AddCommand(traderStatusCmd)
 
+	traderStartCmd.Flags().BoolVar(&traderStartAll, "all", false, "Start every strategy in the strategies file")
 	traderListCmd.Flags().BoolVar(&traderJSON, "json", false, "Output as JSON")
 	traderStatusCmd.Flags().BoolVar(&traderJSON, "json", false, "Output as JSON")
 }
 
 func runTraderStart(cmd *cobra.Command, args []string) error {
-	agentName := args[0]
-
-	var agentType trader.AgentType
-	switch agentName {
-	case "copytrade":
-		agentType = trader.AgentTypeCopyTrade
-	case "researcher":
-		agentType = trader.AgentTypeResearcher
-	default:
-		return fmt.Errorf("unknown agent: %s (available: copytrade, researcher)", agentName)
+	if len(args) == 0 && !traderStartAll {
+		return fmt.Errorf("specify an agent or strategy name, or --all")
 	}
 
-	if err := traderManager.Start(agentType); err != nil {
-		return err
+	var started []string
+	var strategies []string
+	for _, name := range args {
+		switch name {
+		case string(trader.AgentTypeCopyTrade), string(trader.AgentTypeResearcher):
+			if err := traderManager.Start(trader.AgentType(name)); err != nil {
+				return err
+			}
+			started = append(started, name)
+			fmt.Printf("🐋 Started %s agent\n", name)
+		default:
+			strategies = append(strategies, name)
+		}
 	}
 
-	fmt.Printf("🐋 Started %s agent\n", agentName)
-
-	if agentName == "copytrade" {
-		if os.Getenv("HELIUS_API_KEY") == "" {
-			fmt.Println("⚠️  HELIUS_API_KEY not set - using mock data")
-		} else {
-			fmt.Println("✅ Connected to Helius API for live Solana data")
+	if traderStartAll || len(strategies) > 0 {
+		names, err := traderManager.StartStrategies(trader.StrategiesPath(), strategies...)
+		for _, n := range names {
+			fmt.Printf("🐋 Started strategy %s\n", n)
 		}
+		started = append(started, names...)
+		if err != nil {
+			stopTraderAgents(started)
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no strategies configured in %s", trader.StrategiesPath())
+		}
+	}
+
+	if os.Getenv("HELIUS_API_KEY") == "" {
+		fmt.Println("⚠️  HELIUS_API_KEY not set - using mock data")
+	} else {
+		fmt.Println("✅ Connected to Helius API for live Solana data")
 	}
 
-	fmt.Println("\n📊 Agent running... Press Ctrl+C to stop")
-	fmt.Println("   Use 'wt dashboard' in another terminal to see trades\n")
+	fmt.Println("\n📊 Agents running... Press Ctrl+C to stop")
+	fmt.Println("   Use 'wt trader list' or 'wt dashboard' in another terminal to see stats")
+	fmt.Println()
 
 	// Block and show periodic status
 	ticker := time.NewTicker(30 * time.Second)
@@ -123,13 +160,15 @@ func runTraderStart(cmd *cobra.Command, args []string) error {
 	for {
 		select {
 		case <-sigCh:
-			fmt.Println("\n🛑 Stopping agent...")
-			traderManager.Stop(agentName)
+			fmt.Println("\n🛑 Stopping agents...")
+			stopTraderAgents(started)
 			return nil
 		case <-ticker.C:
-			agents := traderManager.List()
-			for _, a := range agents {
-				if a.Name == agentName {
+			for _, a := range traderManager.List() {
+				if a.Type == trader.AgentTypeStrategy {
+					fmt.Printf("   📈 %s: %d alerts, %d executions, P&L %+.4f SOL\n",
+						a.Name, a.Trades, a.Executions, a.PnLSOL)
+				} else {
 					fmt.Printf("   📈 %s: %d trades tracked\n", a.Name, a.Trades)
 				}
 			}
@@ -137,6 +176,13 @@ func runTraderStart(cmd *cobra.Command, args []string) error {
 	}
 }
 
+// stopTraderAgents stops the named agents, ignoring ones not running.
+func stopTraderAgents(names []string) {
+	for _, name := range names {
+		_ = traderManager.Stop(name)
+	}
+}
+
 func runTraderStop(cmd *cobra.Command, args []string) error {
 	agentName := args[0]
 
@@ -149,7 +195,10 @@ func runTraderStop(cmd *cobra.Command, args []string) error {
 }
 
 func runTraderList(cmd *cobra.Command, args []string) error {
-	agents := traderManager.List()
+	agents, err := collectTraderStatuses()
+	if err != nil {
+		return err
+	}
 
 	if traderJSON {
 		enc := json.NewEncoder(os.Stdout)
@@ -164,22 +213,67 @@ func runTraderList(cmd *cobra.Command, args []string) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tTYPE\tSTATUS\tTRADES\tSIGNALS\tSTARTED")
+	fmt.Fprintln(w, "NAME\tTYPE\tSTATUS\tWALLETS\tTRADES\tSIGNALS\tEXECS\tSPENT\tP&L\tSTARTED")
 	for _, a := range agents {
 		status := "stopped"
 		if a.Running {
 			status = "running"
 		}
+		if a.DryRun {
+			status += " (dry-run)"
+		}
 		started := ""
 		if !a.StartedAt.IsZero() {
 			started = a.StartedAt.Format("15:04:05")
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n",
-			a.Name, a.Type, status, a.Trades, a.Signals, started)
+		spent, pnl := "-", "-"
+		if a.Type == trader.AgentTypeStrategy {
+			spent = fmt.Sprintf("%.4f SOL", a.SpentSOL)
+			pnl = fmt.Sprintf("%+.4f SOL", a.PnLSOL)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			a.Name, a.Type, status, a.Wallets, a.Trades, a.Signals, a.Executions, spent, pnl, started)
 	}
 	return w.Flush()
 }
 
+// collectTraderStatuses merges agents running in this process with the
+// persisted state of strategy instances (which may run in other processes)
+// and configured strategies that have never run.
+func collectTraderStatuses() ([]trader.AgentStatus, error) {
+	byName := make(map[string]trader.AgentStatus)
+	for _, a := range traderManager.List() {
+		byName[a.Name] = a
+	}
+
+	states, err := trader.ListInstanceStates(trader.TraderDir())
+	if err != nil {
+		return nil, fmt.Errorf("reading strategy state: %w", err)
+	}
+	for _, s := range states {
+		if _, ok := byName[s.Name]; !ok {
+			byName[s.Name] = trader.StatusFromState(s)
+		}
+	}
+
+	strategies, err := trader.LoadStrategies(trader.StrategiesPath())
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range strategies {
+		if _, ok := byName[cfg.Name]; !ok {
+			byName[cfg.Name] = trader.AgentStatus{Name: cfg.Name, Type: trader.AgentTypeStrategy, DryRun: cfg.DryRun}
+		}
+	}
+
+	result := make([]trader.AgentStatus, 0, len(byName))
+	for _, a := range byName {
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
 func runTraderStatus(cmd *cobra.Command, args []string) error {
 	agents := traderManager.List()
 
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/agents/common"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+	"github.com/speaker20/whaletown/internal/trader"
+	"github.com/spf13/cobra"
+)
+
+var traderExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the trade journal (CSV/JSON) for tax and performance reporting",
+	Long: `Export a per-trade journal of every copy trade and manual trade.
+
+Fills recorded by strategies and the copytrade agent are reconciled with
+the executor wallets' on-chain history, so buys made outside the agents
+(e.g. the dashboard's /buy endpoint) and sells are included. Each row has
+the timestamp, signature, venue, mints and amounts, SOL and USD value,
+fees including priority fees, and the whale that triggered the trade.
+
+Sells carry cost basis and realized gain per lot. Lots are matched FIFO by
+default; with --method specific, --lots names a JSON file mapping sell
+signatures to the buy signatures they dispose of:
+
+  {"5sell...": ["3buy...", "4buy..."]}
+
+Sells without a selection fall back to FIFO.
+
+USD values use the SOL price recorded with each fill. Trades with no
+recorded price (manual trades, older fills) use --sol-usd if given and
+are left blank otherwise.
+
+--from and --to take a date (2006-01-02) or RFC3339 time. A date for --to
+includes that whole day.
+
+Examples:
+  wt trader export --from 2025-01-01 --to 2025-12-31 > trades.csv
+  wt trader export --format json -o trades.json
+  wt trader export --method specific --lots lots.json
+  wt trader export --no-chain                # Fills only, no RPC calls`,
+	RunE: runTraderExport,
+}
+
+var (
+	traderExportFrom    string
+	traderExportTo      string
+	traderExportFormat  string
+	traderExportMethod  string
+	traderExportLots    string
+	traderExportWallets []string
+	traderExportSOLUSD  float64
+	traderExportOutput  string
+	traderExportNoChain bool
+	traderExportMaxTx   int
+)
+
+func init() {
+	traderCmd.AddCommand(traderExportCmd)
+
+	traderExportCmd.Flags().StringVar(&traderExportFrom, "from", "", "Start date or time (inclusive)")
+	traderExportCmd.Flags().StringVar(&traderExportTo, "to", "", "End date (inclusive) or time (exclusive)")
+	traderExportCmd.Flags().StringVar(&traderExportFormat, "format", "csv", "Output format: csv or json")
+	traderExportCmd.Flags().StringVar(&traderExportMethod, "method", trader.LotFIFO, "Lot matching: fifo or specific")
+	traderExportCmd.Flags().StringVar(&traderExportLots, "lots", "", "Specific-ID lot selection file (JSON)")
+	traderExportCmd.Flags().StringSliceVar(&traderExportWallets, "wallet", nil, "Additional executor wallet to reconcile (repeatable)")
+	traderExportCmd.Flags().Float64Var(&traderExportSOLUSD, "sol-usd", 0, "SOL/USD price for trades without a recorded price")
+	traderExportCmd.Flags().StringVarP(&traderExportOutput, "output", "o", "", "Write to file instead of stdout")
+	traderExportCmd.Flags().BoolVar(&traderExportNoChain, "no-chain", false, "Skip on-chain reconciliation")
+	traderExportCmd.Flags().IntVar(&traderExportMaxTx, "max-tx", 1000, "Max transactions to read per wallet (0 = all)")
+}
+
+func runTraderExport(cmd *cobra.Command, args []string) error {
+	if traderExportFormat != "csv" && traderExportFormat != "json" {
+		return fmt.Errorf("unknown format %q (want csv or json)", traderExportFormat)
+	}
+	from, err := parseExportTime(traderExportFrom, false)
+	if err != nil {
+		return fmt.Errorf("--from: %w", err)
+	}
+	to, err := parseExportTime(traderExportTo, true)
+	if err != nil {
+		return fmt.Errorf("--to: %w", err)
+	}
+
+	opts := trader.JournalOptions{
+		Method:      traderExportMethod,
+		SOLPriceUSD: traderExportSOLUSD,
+		From:        from,
+		To:          to,
+	}
+	if traderExportLots != "" {
+		if traderExportMethod != trader.LotSpecific {
+			return fmt.Errorf("--lots requires --method %s", trader.LotSpecific)
+		}
+		if opts.SpecificLots, err = trader.LoadLotSelections(traderExportLots); err != nil {
+			return err
+		}
+	}
+
+	root := trader.TraderDir()
+	fills, err := trader.LoadAllFills(root)
+	if err != nil {
+		return fmt.Errorf("reading fills: %w", err)
+	}
+
+	var swaps []copytrade.WalletSwap
+	if !traderExportNoChain {
+		config := common.DefaultConfig()
+		extra := traderExportWallets
+		// The default executor wallet also serves /buy
+		if exec, err := copytrade.NewExecutor(config); err == nil {
+			extra = append(extra, exec.PublicKey())
+		}
+		wallets, err := trader.JournalWallets(root, fills, extra...)
+		if err != nil {
+			return fmt.Errorf("reading strategy state: %w", err)
+		}
+		if len(wallets) == 0 {
+			fmt.Fprintln(os.Stderr, "⚠️  No executor wallets known; exporting recorded fills only")
+		}
+		if swaps, err = trader.FetchWalletSwaps(config, wallets, traderExportMaxTx); err != nil {
+			return err
+		}
+	}
+
+	entries, err := trader.BuildJournal(fills, swaps, opts)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if traderExportOutput != "" {
+		f, err := os.Create(traderExportOutput)
+		if err != nil {
+			return fmt.Errorf("creating output: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if traderExportFormat == "json" {
+		err = trader.WriteJournalJSON(out, entries)
+	} else {
+		err = trader.WriteJournalCSV(out, entries)
+	}
+	if err != nil {
+		return fmt.Errorf("writing journal: %w", err)
+	}
+	if traderExportOutput != "" {
+		fmt.Fprintf(os.Stderr, "✅ Wrote %d trades to %s\n", len(entries), traderExportOutput)
+	}
+	return nil
+}
+
+// parseExportTime parses a date or RFC3339 time. An empty string is the
+// zero time. With endOfDay, a bare date means the start of the next day.
+func parseExportTime(s string, endOfDay bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (want 2006-01-02 or RFC3339)", s)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
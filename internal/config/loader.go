@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/speaker20/whaletown/internal/constants"
@@ -218,6 +219,9 @@ func validateRigSettings(c *RigSettings) error {
 // ErrInvalidOnConflict indicates an invalid on_conflict strategy.
 var ErrInvalidOnConflict = errors.New("invalid on_conflict strategy")
 
+// ErrInvalidMergeStrategy indicates an invalid merge_strategy.
+var ErrInvalidMergeStrategy = errors.New("invalid merge_strategy")
+
 // validateMergeQueueConfig validates a MergeQueueConfig.
 func validateMergeQueueConfig(c *MergeQueueConfig) error {
 	// Validate on_conflict strategy
@@ -226,6 +230,23 @@ func validateMergeQueueConfig(c *MergeQueueConfig) error {
 			ErrInvalidOnConflict, c.OnConflict, OnConflictAssignBack, OnConflictAutoRebase)
 	}
 
+	switch c.MergeStrategy {
+	case "", MergeStrategyNoFF, MergeStrategySquash, MergeStrategyRebaseAndFF:
+	default:
+		return fmt.Errorf("%w: got '%s', want '%s', '%s' or '%s'",
+			ErrInvalidMergeStrategy, c.MergeStrategy, MergeStrategyNoFF, MergeStrategySquash, MergeStrategyRebaseAndFF)
+	}
+	if c.TestReport != "" {
+		if _, err := filepath.Match(c.TestReport, ""); err != nil {
+			return fmt.Errorf("invalid test_report pattern: %w", err)
+		}
+	}
+	if c.CommitTemplate != "" {
+		if _, err := template.New("commit_template").Parse(c.CommitTemplate); err != nil {
+			return fmt.Errorf("invalid commit_template: %w", err)
+		}
+	}
+
 	// Validate poll_interval if specified
 	if c.PollInterval != "" {
 		if _, err := time.ParseDuration(c.PollInterval); err != nil {
@@ -240,6 +261,11 @@ func validateMergeQueueConfig(c *MergeQueueConfig) error {
 	if c.MaxConcurrent < 0 {
 		return fmt.Errorf("%w: max_concurrent must be non-negative", ErrMissingField)
 	}
+	if c.AutoRebase != nil {
+		if err := c.AutoRebase.Validate(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -757,6 +783,19 @@ func LoadOrCreateTownSettings(path string) (*TownSettings, error) {
 	return &settings, nil
 }
 
+// DefaultAgentEmailDomain is the default domain for agent git emails.
+const DefaultAgentEmailDomain = "whaletown.local"
+
+// LoadAgentEmailDomain returns the town's agent_email_domain setting, or
+// DefaultAgentEmailDomain if it is unset or the settings can't be read.
+func LoadAgentEmailDomain(townRoot string) string {
+	settings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot))
+	if err != nil || settings.AgentEmailDomain == "" {
+		return DefaultAgentEmailDomain
+	}
+	return settings.AgentEmailDomain
+}
+
 // SaveTownSettings saves town settings to a file.
 func SaveTownSettings(path string, settings *TownSettings) error {
 	if settings.Type != "town-settings" && settings.Type != "" {
@@ -1511,6 +1550,42 @@ func GetRigPrefix(townRoot, rigName string) string {
 	return strings.TrimSuffix(prefix, "-")
 }
 
+// CostsConfigPath returns the standard path for cost accounting config in a town.
+func CostsConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "costs.json")
+}
+
+// LoadCostsConfig loads and validates a cost accounting configuration file.
+// Returns an empty config (built-in prices only) if the file doesn't exist.
+func LoadCostsConfig(path string) (*CostsConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally, not from user input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &CostsConfig{Type: "costs", Version: CurrentCostsVersion}, nil
+		}
+		return nil, fmt.Errorf("reading costs config: %w", err)
+	}
+
+	var config CostsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing costs config: %w", err)
+	}
+
+	if config.Type != "costs" && config.Type != "" {
+		return nil, fmt.Errorf("%w: expected type 'costs', got '%s'", ErrInvalidType, config.Type)
+	}
+	if config.Version > CurrentCostsVersion {
+		return nil, fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, config.Version, CurrentCostsVersion)
+	}
+	for model, p := range config.Prices {
+		if p.Input < 0 || p.Output < 0 || p.CacheWrite < 0 || p.CacheRead < 0 {
+			return nil, fmt.Errorf("costs config: negative price for %s", model)
+		}
+	}
+
+	return &config, nil
+}
+
 // EscalationConfigPath returns the standard path for escalation config in a town.
 func EscalationConfigPath(townRoot string) string {
 	return filepath.Join(townRoot, "settings", "escalation.json")
@@ -1605,6 +1680,48 @@ func validateEscalationConfig(c *EscalationConfig) error {
 		return fmt.Errorf("%w: max_reescalations must be non-negative", ErrMissingField)
 	}
 
+	if c.SMTP != nil {
+		if c.SMTP.Host == "" || c.SMTP.From == "" {
+			return fmt.Errorf("%w: smtp requires host and from", ErrMissingField)
+		}
+		switch c.SMTP.StartTLS {
+		case "", "auto", "always", "never":
+		default:
+			return fmt.Errorf("invalid smtp.starttls '%s' (valid: auto, always, never)", c.SMTP.StartTLS)
+		}
+	}
+	if gw := c.EmailGateway; gw != nil && gw.Enabled {
+		if c.SMTP == nil {
+			return fmt.Errorf("%w: email_gateway requires smtp", ErrMissingField)
+		}
+		if gw.IMAP != nil {
+			if gw.IMAP.Host == "" || gw.IMAP.Username == "" {
+				return fmt.Errorf("%w: email_gateway.imap requires host and username", ErrMissingField)
+			}
+			switch gw.IMAP.TLS {
+			case "", "always", "never":
+			default:
+				return fmt.Errorf("invalid email_gateway.imap.tls '%s' (valid: always, never)", gw.IMAP.TLS)
+			}
+		}
+	}
+	if c.SMSGateway != nil && c.SMSGateway.URL == "" {
+		return fmt.Errorf("%w: sms_gateway requires url", ErrMissingField)
+	}
+	if c.Delivery != nil {
+		if c.Delivery.MaxAttempts < 0 {
+			return fmt.Errorf("%w: delivery.max_attempts must be non-negative", ErrMissingField)
+		}
+		for name, v := range map[string]string{"backoff": c.Delivery.Backoff, "timeout": c.Delivery.Timeout} {
+			if v == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(v); err != nil {
+				return fmt.Errorf("invalid delivery.%s: %w", name, err)
+			}
+		}
+	}
+
 	return nil
 }
 
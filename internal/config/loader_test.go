@@ -2603,3 +2603,59 @@ func TestBuildStartupCommandWithAgentOverride_IncludesGTRoot(t *testing.T) {
 		t.Errorf("expected WT_ROOT=%s in command, got: %q", townRoot, cmd)
 	}
 }
+
+func TestAutoRebaseConfigValidate(t *testing.T) {
+	t.Parallel()
+	if err := DefaultAutoRebaseConfig().Validate(); err != nil {
+		t.Errorf("default config invalid: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rule    ConflictRule
+		wantErr bool
+	}{
+		{"union", ConflictRule{Pattern: "go.sum", Strategy: ConflictUnion}, false},
+		{"dir prefix", ConflictRule{Pattern: "gen/**", Strategy: ConflictTarget}, false},
+		{"regenerate", ConflictRule{Pattern: "*.lock", Strategy: ConflictRegenerate, Command: "npm install"}, false},
+		{"regenerate without command", ConflictRule{Pattern: "*.lock", Strategy: ConflictRegenerate}, true},
+		{"missing pattern", ConflictRule{Strategy: ConflictBranch}, true},
+		{"bad pattern", ConflictRule{Pattern: "[", Strategy: ConflictTarget}, true},
+		{"bad strategy", ConflictRule{Pattern: "go.sum", Strategy: "ours"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &AutoRebaseConfig{Rules: []ConflictRule{tt.rule}}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMergeQueueConfig_MergeStrategy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		strategy string
+		template string
+		wantErr  bool
+	}{
+		{"default", "", "", false},
+		{"squash", MergeStrategySquash, "", false},
+		{"rebase-and-ff", MergeStrategyRebaseAndFF, "", false},
+		{"template", MergeStrategyNoFF, "{{.Title}} ({{.Issue}})", false},
+		{"bad strategy", "octopus", "", true},
+		{"bad template", MergeStrategyNoFF, "{{.Title", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultMergeQueueConfig()
+			cfg.MergeStrategy = tt.strategy
+			cfg.CommitTemplate = tt.template
+			if err := validateMergeQueueConfig(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateMergeQueueConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@
 package config
 
 import (
+	"fmt"
+	"path"
 	"path/filepath"
 	"os"
 	"strings"
@@ -61,6 +63,53 @@ type TownSettings struct {
 	// Agent addresses like "whaletown/crew/jack" become "whaletown.crew.jack@{domain}".
 	// Default: "whaletown.local"
 	AgentEmailDomain string `json:"agent_email_domain,omitempty"`
+
+	// SessionBackend is where agent sessions run: "tmux" or "pty" (the
+	// built-in headless supervisor, for machines without tmux).
+	// WT_SESSION_BACKEND overrides it.
+	// Default: "tmux"
+	SessionBackend string `json:"session_backend,omitempty"`
+
+	// MailRules are mailbox filter rules, keyed by mailbox address.
+	// Keys may use '*' for a path segment ("*/witness"); "*" alone
+	// applies to every mailbox. Rules run in order: "*" rules first,
+	// then the mailbox's own.
+	// Example: {"mayor/": [{"match": {"subject": "^POLECAT_DONE"}, "actions": ["label:protocol", "mark-read"]}]}
+	MailRules map[string][]*MailRule `json:"mail_rules,omitempty"`
+}
+
+// MailRule is a mailbox filter rule: every action runs on each message
+// that matches.
+type MailRule struct {
+	// Name identifies the rule in 'wt mail rules' output and escalations.
+	Name string `json:"name,omitempty"`
+
+	// Match selects messages. All set fields must match; an empty match
+	// selects every message.
+	Match MailRuleMatch `json:"match"`
+
+	// Actions to run on a match:
+	//   label:<name>       add a label
+	//   mark-read          mark as read
+	//   pin                pin (never auto-archived)
+	//   archive            archive
+	//   forward:<address>  send a copy to another address
+	//   escalate[:<sev>]   raise an escalation (default severity: medium)
+	//   handle             pass to the mailbox's protocol handler, and
+	//                      archive the message if it handled it
+	Actions []string `json:"actions"`
+
+	// Stop skips later rules for a message this rule matched.
+	Stop bool `json:"stop,omitempty"`
+}
+
+// MailRuleMatch holds the conditions of a MailRule.
+type MailRuleMatch struct {
+	From     string `json:"from,omitempty"`     // sender address; '*' matches a path segment
+	Subject  string `json:"subject,omitempty"`  // regular expression
+	Type     string `json:"type,omitempty"`     // task, scavenge, notification, reply
+	Priority string `json:"priority,omitempty"` // urgent, high, normal, low, or 0-4
+	Label    string `json:"label,omitempty"`    // the message carries this label
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -160,6 +209,7 @@ type RigEntry struct {
 	LocalRepo   string       `json:"local_repo,omitempty"`
 	AddedAt     time.Time    `json:"added_at"`
 	BeadsConfig *BeadsConfig `json:"beads,omitempty"`
+	Machine     string       `json:"machine,omitempty"` // Where worktrees and sessions run; empty = local
 }
 
 // BeadsConfig represents beads configuration for a rig.
@@ -644,6 +694,28 @@ type MergeQueueConfig struct {
 	// OnConflict specifies conflict resolution strategy: "assign_back" or "auto_rebase".
 	OnConflict string `json:"on_conflict"`
 
+	// AutoRebase tunes the auto_rebase conflict strategy.
+	AutoRebase *AutoRebaseConfig `json:"auto_rebase,omitempty"`
+
+	// MergeStrategy is how branches land on the target: "no-ff" (default,
+	// a merge commit), "squash" (one commit) or "rebase-and-ff" (the
+	// branch's commits rebased onto the target, linear history).
+	MergeStrategy string `json:"merge_strategy,omitempty"`
+
+	// CommitTemplate is a Go text/template for the commit created by
+	// no-ff and squash merges. Fields: .Branch, .Target, .MR, .Issue,
+	// .Title, .Description, .Polecat, .Rig, .Convoy, .ConvoyTitle.
+	// Default: "Merge {{.Branch}} into {{.Target}}{{if .Issue}} ({{.Issue}}){{end}}"
+	CommitTemplate string `json:"commit_template,omitempty"`
+
+	// CoAuthorTrailers adds a Co-authored-by trailer for each polecat whose
+	// work lands, using the town's agent_email_domain.
+	CoAuthorTrailers bool `json:"co_author_trailers,omitempty"`
+
+	// SignCommits signs the commits the refinery creates (git -S) with the
+	// refinery clone's user.signingkey.
+	SignCommits bool `json:"sign_commits,omitempty"`
+
 	// RunTests controls whether to run tests before merging.
 	RunTests bool `json:"run_tests"`
 
@@ -656,6 +728,11 @@ type MergeQueueConfig struct {
 	// RetryFlakyTests is the number of times to retry flaky tests.
 	RetryFlakyTests int `json:"retry_flaky_tests"`
 
+	// TestReport is a glob, relative to the worktree, of JUnit XML reports
+	// written by the test command (e.g., "build/test-results/*.xml").
+	// Without it, `go test -json` output is parsed when present.
+	TestReport string `json:"test_report,omitempty"`
+
 	// PollInterval is how often to poll for new merge requests (e.g., "30s").
 	PollInterval string `json:"poll_interval"`
 
@@ -669,6 +746,83 @@ const (
 	OnConflictAutoRebase = "auto_rebase"
 )
 
+// Merge strategy constants.
+const (
+	MergeStrategyNoFF        = "no-ff"
+	MergeStrategySquash      = "squash"
+	MergeStrategyRebaseAndFF = "rebase-and-ff"
+)
+
+// AutoRebaseConfig controls how the refinery rebases a conflicting branch
+// onto its target before falling back to assign_back.
+type AutoRebaseConfig struct {
+	// IgnoreWhitespace rebases with -X ignore-space-change so conflicts
+	// caused only by whitespace changes resolve themselves.
+	IgnoreWhitespace bool `json:"ignore_whitespace"`
+
+	// Rules resolve conflicts in matching files. The first matching rule
+	// wins; a conflict in a file no rule matches fails the rebase.
+	Rules []ConflictRule `json:"rules,omitempty"`
+}
+
+// ConflictRule resolves conflicts in files matching Pattern.
+//
+// Pattern is a path.Match glob tried against the full path and the base
+// name (e.g., "go.sum", "*.lock"), or a directory prefix ending in "/**"
+// (e.g., "gen/**").
+//
+// Strategy is one of:
+//   - "union": keep both sides' lines (go.sum and similar line sets)
+//   - "target": keep the target branch's version
+//   - "branch": keep the polecat branch's version
+//   - "regenerate": keep the target's version, then run Command in the
+//     worktree to rebuild the file (lockfiles, generated code)
+type ConflictRule struct {
+	Pattern  string `json:"pattern"`
+	Strategy string `json:"strategy"`
+	Command  string `json:"command,omitempty"`
+}
+
+// Conflict rule strategies.
+const (
+	ConflictUnion      = "union"
+	ConflictTarget     = "target"
+	ConflictBranch     = "branch"
+	ConflictRegenerate = "regenerate"
+)
+
+// DefaultAutoRebaseConfig ignores whitespace and unions go.sum.
+func DefaultAutoRebaseConfig() *AutoRebaseConfig {
+	return &AutoRebaseConfig{
+		IgnoreWhitespace: true,
+		Rules: []ConflictRule{
+			{Pattern: "go.sum", Strategy: ConflictUnion},
+		},
+	}
+}
+
+// Validate checks the rules' strategies and patterns.
+func (c *AutoRebaseConfig) Validate() error {
+	for i, r := range c.Rules {
+		if r.Pattern == "" {
+			return fmt.Errorf("%w: auto_rebase.rules[%d] has no pattern", ErrMissingField, i)
+		}
+		if _, err := path.Match(strings.TrimSuffix(r.Pattern, "/**"), ""); err != nil {
+			return fmt.Errorf("auto_rebase.rules[%d]: invalid pattern %q: %w", i, r.Pattern, err)
+		}
+		switch r.Strategy {
+		case ConflictUnion, ConflictTarget, ConflictBranch:
+		case ConflictRegenerate:
+			if r.Command == "" {
+				return fmt.Errorf("%w: auto_rebase.rules[%d] (%s) needs a command to regenerate", ErrMissingField, i, r.Pattern)
+			}
+		default:
+			return fmt.Errorf("auto_rebase.rules[%d]: invalid strategy %q (valid: union, target, branch, regenerate)", i, r.Strategy)
+		}
+	}
+	return nil
+}
+
 // DefaultMergeQueueConfig returns a MergeQueueConfig with sensible defaults.
 func DefaultMergeQueueConfig() *MergeQueueConfig {
 	return &MergeQueueConfig{
@@ -808,12 +962,26 @@ type EscalationConfig struct {
 	//   - "email:human" → Send email to contacts.human_email
 	//   - "sms:human"   → Send SMS to contacts.human_sms
 	//   - "slack"       → Post to contacts.slack_webhook
+	//   - "webhook"     → POST a JSON payload to contacts.webhook
 	//   - "log"         → Write to escalation log file
 	Routes map[string][]string `json:"routes"`
 
 	// Contacts contains contact information for external notification actions.
 	Contacts EscalationContacts `json:"contacts"`
 
+	// SMTP configures the mail server used by email actions.
+	SMTP *EscalationSMTP `json:"smtp,omitempty"`
+
+	// SMSGateway configures the HTTP gateway used by sms actions.
+	SMSGateway *EscalationSMSGateway `json:"sms_gateway,omitempty"`
+
+	// Delivery controls retries for external notification actions.
+	Delivery *EscalationDelivery `json:"delivery,omitempty"`
+
+	// EmailGateway bridges the overseer's mailbox to email. It sends
+	// through SMTP above and reads replies over IMAP.
+	EmailGateway *EmailGateway `json:"email_gateway,omitempty"`
+
 	// StaleThreshold is how long before an unacknowledged escalation
 	// is considered stale and gets re-escalated.
 	// Format: Go duration string (e.g., "4h", "30m", "24h")
@@ -830,8 +998,125 @@ type EscalationContacts struct {
 	HumanEmail   string `json:"human_email,omitempty"`   // email address for email:human action
 	HumanSMS     string `json:"human_sms,omitempty"`     // phone number for sms:human action
 	SlackWebhook string `json:"slack_webhook,omitempty"` // webhook URL for slack action
+	Webhook      string `json:"webhook,omitempty"`       // URL for webhook action (JSON POST)
 }
 
+// EscalationSMTP configures outgoing email for escalations.
+type EscalationSMTP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`     // Default: 587
+	From     string `json:"from"`               // Envelope and header sender
+	Username string `json:"username,omitempty"` // Enables PLAIN auth
+
+	// PasswordEnv names the environment variable holding the password,
+	// so the secret stays out of settings/.
+	PasswordEnv string `json:"password_env,omitempty"`
+
+	// StartTLS is "auto" (upgrade when offered, the default), "always"
+	// or "never".
+	StartTLS string `json:"starttls,omitempty"`
+}
+
+// EscalationSMSGateway sends SMS through an HTTP API. URL, header values
+// and Body are Go templates over .To, .Message, .Severity, .BeadID and
+// .Title, with "env" (read an environment variable), "json" (quote a
+// JSON string) and the builtin "urlquery" available.
+//
+// Example (Twilio):
+//
+//	"url": "https://api.twilio.com/2010-04-01/Accounts/{{env \"TWILIO_SID\"}}/Messages.json",
+//	"headers": {"Authorization": "Basic {{env \"TWILIO_BASIC_AUTH\"}}"},
+//	"content_type": "application/x-www-form-urlencoded",
+//	"body": "To={{urlquery .To}}&From=%2B15550100&Body={{urlquery .Message}}"
+type EscalationSMSGateway struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method,omitempty"`       // Default: POST
+	Headers     map[string]string `json:"headers,omitempty"`      // Values are templates
+	ContentType string            `json:"content_type,omitempty"` // Default: application/json
+	Body        string            `json:"body,omitempty"`         // Template; empty sends no body
+}
+
+// EscalationDelivery controls how external notifications are retried.
+type EscalationDelivery struct {
+	// MaxAttempts is the number of tries per action. Default: 3.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+
+	// Backoff is the wait before the first retry, doubling after each
+	// failed attempt. Format: Go duration string. Default: "2s".
+	Backoff string `json:"backoff,omitempty"`
+
+	// Timeout bounds each attempt. Format: Go duration string. Default: "30s".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// EmailGateway relays mail addressed to the overseer to
+// contacts.human_email and turns email replies back into mail replies.
+type EmailGateway struct {
+	Enabled bool `json:"enabled"`
+
+	// Escalations also copies escalation mail to the overseer, so
+	// escalations reach the gateway even when no route mails the overseer.
+	Escalations bool `json:"escalations,omitempty"`
+
+	// IMAP is the mailbox polled for replies. Without it the gateway only
+	// sends.
+	IMAP *EmailGatewayIMAP `json:"imap,omitempty"`
+
+	// AllowFrom lists the email addresses (or "@domain" suffixes) whose
+	// replies are accepted. Default: contacts.human_email.
+	AllowFrom []string `json:"allow_from,omitempty"`
+
+	// Domain is the right-hand side of the Message-IDs the gateway
+	// generates; replies are matched on it. Default: "whaletown.local".
+	Domain string `json:"domain,omitempty"`
+}
+
+// EmailGatewayIMAP configures the mailbox the email gateway reads replies from.
+type EmailGatewayIMAP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`    // Default: 993, or 143 with tls "never"
+	Username string `json:"username"`
+	Mailbox  string `json:"mailbox,omitempty"` // Default: INBOX
+
+	// PasswordEnv names the environment variable holding the password.
+	PasswordEnv string `json:"password_env,omitempty"`
+
+	// TLS is "always" (implicit TLS, the default) or "never".
+	TLS string `json:"tls,omitempty"`
+}
+
+// RelaysEscalations reports whether escalation mail should be copied to
+// the overseer for the email gateway.
+func (c *EscalationConfig) RelaysEscalations() bool {
+	return c != nil && c.EmailGateway != nil && c.EmailGateway.Enabled && c.EmailGateway.Escalations
+}
+
+// CostsConfig represents cost accounting configuration (settings/costs.json).
+// Prices here override the built-in model price table.
+type CostsConfig struct {
+	Type    string `json:"type"`    // "costs"
+	Version int    `json:"version"` // schema version
+
+	// Prices maps a model name prefix (e.g., "claude-sonnet-4") to its
+	// per-million-token prices. The longest matching prefix wins.
+	Prices map[string]ModelPrice `json:"prices,omitempty"`
+
+	// TranscriptDirs are searched for <session-id>.jsonl transcripts, one
+	// level deep. Default: $CLAUDE_CONFIG_DIR/projects or ~/.claude/projects.
+	TranscriptDirs []string `json:"transcript_dirs,omitempty"`
+}
+
+// ModelPrice is the USD price per million tokens for a model.
+type ModelPrice struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write"`
+	CacheRead  float64 `json:"cache_read"`
+}
+
+// CurrentCostsVersion is the current schema version for CostsConfig.
+const CurrentCostsVersion = 1
+
 // CurrentEscalationVersion is the current schema version for EscalationConfig.
 const CurrentEscalationVersion = 1
 
@@ -6,6 +6,9 @@ package connection
 import (
 	"io/fs"
 	"time"
+
+	"github.com/speaker20/whaletown/internal/git"
+	"github.com/speaker20/whaletown/internal/tmux"
 )
 
 // Connection abstracts file operations, command execution, and tmux management
@@ -75,6 +78,18 @@ type Connection interface {
 
 	// TmuxListSessions returns a list of all tmux session names.
 	TmuxListSessions() ([]string, error)
+
+	// Tmux returns a tmux wrapper that runs on this connection, for
+	// callers that need the full tmux API.
+	Tmux() *tmux.Tmux
+
+	// Git operations
+
+	// Git returns a git wrapper for workDir on this connection.
+	Git(workDir string) *git.Git
+
+	// GitWithDir returns a git wrapper for a bare repo on this connection.
+	GitWithDir(gitDir, workDir string) *git.Git
 }
 
 // FileInfo abstracts fs.FileInfo for use over remote connections.
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 
+	"github.com/speaker20/whaletown/internal/git"
 	"github.com/speaker20/whaletown/internal/tmux"
 )
 
@@ -185,5 +186,20 @@ func (c *LocalConnection) TmuxListSessions() ([]string, error) {
 	return c.tmux.ListSessions()
 }
 
+// Tmux returns the local tmux wrapper.
+func (c *LocalConnection) Tmux() *tmux.Tmux {
+	return c.tmux
+}
+
+// Git returns a git wrapper for workDir.
+func (c *LocalConnection) Git(workDir string) *git.Git {
+	return git.NewGit(workDir)
+}
+
+// GitWithDir returns a git wrapper for a bare repo.
+func (c *LocalConnection) GitWithDir(gitDir, workDir string) *git.Git {
+	return git.NewGitWithDir(gitDir, workDir)
+}
+
 // Verify LocalConnection implements Connection.
 var _ Connection = (*LocalConnection)(nil)
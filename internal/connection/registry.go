@@ -12,10 +12,20 @@ import (
 // Machine represents a managed machine in the federation.
 type Machine struct {
 	Name     string `json:"name"`
-	Type     string `json:"type"`      // "local", "ssh"
-	Host     string `json:"host"`      // for ssh: user@host
-	KeyPath  string `json:"key_path"`  // SSH private key path
-	TownPath string `json:"town_path"` // Path to town root on remote
+	Type     string `json:"type"`               // "local", "ssh"
+	Host     string `json:"host"`               // for ssh: user@host
+	KeyPath  string `json:"key_path"`           // SSH private key path
+	TownPath string `json:"town_path"`          // Path to town root on remote
+	HostKey  string `json:"host_key,omitempty"` // Pinned host key (authorized_keys format)
+}
+
+// RigPath returns where the named rig lives on this machine, or "" if
+// the machine has no town path.
+func (m *Machine) RigPath(rigName string) string {
+	if m.TownPath == "" {
+		return ""
+	}
+	return m.TownPath + "/" + rigName
 }
 
 // registryData is the JSON file structure.
@@ -28,6 +38,7 @@ type registryData struct {
 type MachineRegistry struct {
 	path     string
 	machines map[string]*Machine
+	conns    map[string]*SSHConnection // Open connections, reused across calls
 	mu       sync.RWMutex
 }
 
@@ -37,6 +48,7 @@ func NewMachineRegistry(configPath string) (*MachineRegistry, error) {
 	r := &MachineRegistry{
 		path:     configPath,
 		machines: make(map[string]*Machine),
+		conns:    make(map[string]*SSHConnection),
 	}
 
 	// Load existing config if present
@@ -132,6 +144,7 @@ func (r *MachineRegistry) Add(m *Machine) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.closeLocked(m.Name)
 	r.machines[m.Name] = m
 	return r.save()
 }
@@ -149,6 +162,7 @@ func (r *MachineRegistry) Remove(name string) error {
 		return fmt.Errorf("machine not found: %s", name)
 	}
 
+	r.closeLocked(name)
 	delete(r.machines, name)
 	return r.save()
 }
@@ -166,23 +180,57 @@ func (r *MachineRegistry) List() []*Machine {
 }
 
 // Connection returns a Connection for the named machine.
+// SSH connections are cached, so repeated calls share one SSH session.
 func (r *MachineRegistry) Connection(name string) (Connection, error) {
-	m, err := r.Get(name)
-	if err != nil {
-		return nil, err
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.machines[name]
+	if !ok {
+		return nil, fmt.Errorf("machine not found: %s", name)
 	}
 
 	switch m.Type {
 	case "local":
 		return NewLocalConnection(), nil
 	case "ssh":
-		// SSH connection not yet implemented
-		return nil, fmt.Errorf("ssh connections not yet implemented")
+		if c, ok := r.conns[name]; ok {
+			return c, nil
+		}
+		c, err := NewSSHConnection(m)
+		if err != nil {
+			return nil, fmt.Errorf("machine %s: %w", name, err)
+		}
+		r.conns[name] = c
+		return c, nil
 	default:
 		return nil, fmt.Errorf("unknown machine type: %s", m.Type)
 	}
 }
 
+// Close closes all open SSH connections.
+func (r *MachineRegistry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for name, c := range r.conns {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(r.conns, name)
+	}
+	return firstErr
+}
+
+// closeLocked drops the cached connection for a machine. Callers hold mu.
+func (r *MachineRegistry) closeLocked(name string) {
+	if c, ok := r.conns[name]; ok {
+		_ = c.Close()
+		delete(r.conns, name)
+	}
+}
+
 // LocalConnection returns the local connection.
 // This is a convenience method for the common case.
 func (r *MachineRegistry) LocalConnection() *LocalConnection {
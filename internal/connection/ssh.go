@@ -0,0 +1,579 @@
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/git"
+	"github.com/speaker20/whaletown/internal/tmux"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DefaultSSHPort is used when Machine.Host has no port.
+const DefaultSSHPort = "22"
+
+// sshDialTimeout bounds connection setup.
+const sshDialTimeout = 15 * time.Second
+
+// SSHConnection implements Connection over SSH.
+//
+// A single SSH client connection is kept open and each operation runs in
+// its own session (channel) on it, so concurrent operations share one
+// TCP connection and one handshake. If the connection drops it is
+// re-established on the next operation.
+//
+// File operations run POSIX shell commands on the remote host, so the
+// remote login shell must be sh-compatible.
+type SSHConnection struct {
+	machine *Machine
+	addr    string
+	config  *ssh.ClientConfig
+	tmux    *tmux.Tmux
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// NewSSHConnection creates an SSH connection for the machine. The
+// connection is established lazily on first use.
+//
+// Authentication uses the private key at m.KeyPath, or the default keys
+// in ~/.ssh if unset. The host key is checked against m.HostKey if
+// pinned, otherwise against ~/.ssh/known_hosts.
+func NewSSHConnection(m *Machine) (*SSHConnection, error) {
+	user, addr, err := ParseSSHHost(m.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	signers, err := loadSigners(m.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeyCallback, err := hostKeyCallback(m.HostKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &SSHConnection{
+		machine: m,
+		addr:    addr,
+		config: &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         sshDialTimeout,
+		},
+	}
+	c.tmux = tmux.NewTmuxWithRunner(c.runTmux)
+	return c, nil
+}
+
+// ParseSSHHost splits "user@host[:port]" into the user and a dialable
+// address. The user defaults to $USER and the port to 22.
+func ParseSSHHost(host string) (user, addr string, err error) {
+	if host == "" {
+		return "", "", fmt.Errorf("ssh host is required")
+	}
+	user = os.Getenv("USER")
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		user, host = host[:i], host[i+1:]
+	}
+	if user == "" {
+		return "", "", fmt.Errorf("ssh host %q has no user", host)
+	}
+	if _, _, splitErr := net.SplitHostPort(host); splitErr != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), DefaultSSHPort)
+	}
+	return user, host, nil
+}
+
+// loadSigners reads the private key at keyPath, or the default ~/.ssh
+// keys if keyPath is empty.
+func loadSigners(keyPath string) ([]ssh.Signer, error) {
+	paths := []string{keyPath}
+	if keyPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("finding home directory: %w", err)
+		}
+		paths = nil
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			paths = append(paths, filepath.Join(home, ".ssh", name))
+		}
+	}
+
+	var signers []ssh.Signer
+	for _, p := range paths {
+		data, err := os.ReadFile(p) //nolint:gosec // G304: key path is from machine config
+		if err != nil {
+			if keyPath == "" && os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("reading ssh key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing ssh key %s: %w", p, err)
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("no ssh key configured and none found in ~/.ssh")
+	}
+	return signers, nil
+}
+
+// hostKeyCallback pins hostKey (authorized_keys format) if set, and
+// otherwise verifies against ~/.ssh/known_hosts.
+func hostKeyCallback(hostKey string) (ssh.HostKeyCallback, error) {
+	if hostKey != "" {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+		if err != nil {
+			return nil, fmt.Errorf("parsing host key: %w", err)
+		}
+		return ssh.FixedHostKey(key), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("finding home directory: %w", err)
+	}
+	cb, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("loading known_hosts (or pin the key with --host-key): %w", err)
+	}
+	return cb, nil
+}
+
+// ScanHostKey connects to host without verifying its key and returns the
+// key it presents, in authorized_keys format. Used to pin a key when
+// adding a machine.
+func ScanHostKey(host string) (string, error) {
+	_, addr, err := ParseSSHHost(host)
+	if err != nil {
+		return "", err
+	}
+	var found ssh.PublicKey
+	config := &ssh.ClientConfig{
+		User: "wt-keyscan",
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			found = key
+			return errHostKeyScanned
+		},
+		Timeout: sshDialTimeout,
+	}
+	_, err = ssh.Dial("tcp", addr, config)
+	if found == nil {
+		return "", fmt.Errorf("scanning host key: %w", err)
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(found))), nil
+}
+
+var errHostKeyScanned = errors.New("host key scanned")
+
+// Name returns the machine name.
+func (c *SSHConnection) Name() string {
+	return c.machine.Name
+}
+
+// IsLocal returns false for SSH connections.
+func (c *SSHConnection) IsLocal() bool {
+	return false
+}
+
+// Close closes the underlying SSH connection. A later operation
+// reconnects.
+func (c *SSHConnection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.Close()
+	c.client = nil
+	return err
+}
+
+// connect returns the open client, dialing if needed.
+func (c *SSHConnection) connect() (*ssh.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	client, err := ssh.Dial("tcp", c.addr, c.config)
+	if err != nil {
+		return nil, &ConnectionError{Op: "connect", Machine: c.machine.Name, Err: err}
+	}
+	c.client = client
+	go func() {
+		// Forget the client once it's gone so the next call redials
+		_ = client.Wait()
+		c.mu.Lock()
+		if c.client == client {
+			c.client = nil
+		}
+		c.mu.Unlock()
+	}()
+	return client, nil
+}
+
+// session opens a new session on the shared client, reconnecting once if
+// the client turns out to be dead.
+func (c *SSHConnection) session() (*ssh.Session, error) {
+	for attempt := 0; ; attempt++ {
+		client, err := c.connect()
+		if err != nil {
+			return nil, err
+		}
+		sess, err := client.NewSession()
+		if err == nil {
+			return sess, nil
+		}
+		c.mu.Lock()
+		if c.client == client {
+			c.client = nil
+		}
+		c.mu.Unlock()
+		_ = client.Close()
+		if attempt > 0 {
+			return nil, &ConnectionError{Op: "session", Machine: c.machine.Name, Err: err}
+		}
+	}
+}
+
+// run runs a shell command line on the remote host.
+func (c *SSHConnection) run(stdin io.Reader, command string) (stdout, stderr []byte, err error) {
+	sess, err := c.session()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer sess.Close()
+
+	var outBuf, errBuf bytes.Buffer
+	sess.Stdin = stdin
+	sess.Stdout = &outBuf
+	sess.Stderr = &errBuf
+	err = sess.Run(command)
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
+
+// runFile runs a file operation and maps failures to connection errors.
+func (c *SSHConnection) runFile(op, path string, stdin io.Reader, command string) ([]byte, error) {
+	stdout, stderr, err := c.run(stdin, command)
+	if err != nil {
+		return nil, fileError(op, path, stderr, err)
+	}
+	return stdout, nil
+}
+
+// fileError maps a failed remote file command to NotFoundError or
+// PermissionError where the shell's message allows.
+func fileError(op, path string, stderr []byte, err error) error {
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) {
+		return err // Connection-level failure
+	}
+	msg := strings.TrimSpace(string(stderr))
+	switch {
+	case strings.Contains(msg, "No such file"):
+		return &NotFoundError{Path: path}
+	case strings.Contains(msg, "Permission denied"):
+		return &PermissionError{Path: path, Op: op}
+	case msg != "":
+		return fmt.Errorf("%s %s: %s", op, path, msg)
+	default:
+		return fmt.Errorf("%s %s: %w", op, path, err)
+	}
+}
+
+// ReadFile reads the named file.
+func (c *SSHConnection) ReadFile(path string) ([]byte, error) {
+	return c.runFile("read", path, nil, "cat -- "+shellQuote(path))
+}
+
+// WriteFile writes data to the named file, creating it if needed.
+func (c *SSHConnection) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	q := shellQuote(path)
+	cmd := fmt.Sprintf("cat > %s && chmod %o %s", q, perm.Perm(), q)
+	_, err := c.runFile("write", path, bytes.NewReader(data), cmd)
+	return err
+}
+
+// MkdirAll creates a directory and all parent directories.
+func (c *SSHConnection) MkdirAll(path string, perm fs.FileMode) error {
+	_, err := c.runFile("mkdir", path, nil, "mkdir -p -- "+shellQuote(path))
+	return err
+}
+
+// Remove removes the named file or empty directory. A missing path is
+// not an error.
+func (c *SSHConnection) Remove(path string) error {
+	q := shellQuote(path)
+	cmd := fmt.Sprintf("if [ -d %s ] && [ ! -L %s ]; then rmdir -- %s; else rm -f -- %s; fi", q, q, q, q)
+	_, err := c.runFile("remove", path, nil, cmd)
+	return err
+}
+
+// RemoveAll removes the named file or directory and any children.
+func (c *SSHConnection) RemoveAll(path string) error {
+	_, err := c.runFile("remove", path, nil, "rm -rf -- "+shellQuote(path))
+	return err
+}
+
+// Stat returns file info for the named file, following symlinks.
+func (c *SSHConnection) Stat(path string) (FileInfo, error) {
+	q := shellQuote(path)
+	// GNU stat first, then BSD (macOS) stat; both print size, the raw
+	// st_mode in hex, and mtime.
+	cmd := fmt.Sprintf("stat -L -c '%%s %%f %%Y' -- %s 2>/dev/null || stat -L -f '%%z %%Xp %%m' -- %s", q, q)
+	out, err := c.runFile("stat", path, nil, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseStat(path, string(out))
+}
+
+// parseStat parses "size hexmode mtime" as printed by Stat.
+func parseStat(path, out string) (FileInfo, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("stat %s: unexpected output %q", path, out)
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: size: %w", path, err)
+	}
+	raw, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: mode: %w", path, err)
+	}
+	mtime, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: mtime: %w", path, err)
+	}
+	mode := unixMode(uint32(raw))
+	return BasicFileInfo{
+		FileName:    filepath.Base(path),
+		FileSize:    size,
+		FileMode:    mode,
+		FileModTime: time.Unix(mtime, 0),
+		FileIsDir:   mode.IsDir(),
+	}, nil
+}
+
+// unixMode converts a raw st_mode to an fs.FileMode.
+func unixMode(raw uint32) fs.FileMode {
+	mode := fs.FileMode(raw & 0777)
+	switch raw & 0170000 {
+	case 0040000:
+		mode |= fs.ModeDir
+	case 0120000:
+		mode |= fs.ModeSymlink
+	case 0010000:
+		mode |= fs.ModeNamedPipe
+	case 0140000:
+		mode |= fs.ModeSocket
+	case 0020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0060000:
+		mode |= fs.ModeDevice
+	}
+	if raw&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if raw&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if raw&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// Glob returns the names of all files matching the pattern, expanded by
+// the remote shell.
+func (c *SSHConnection) Glob(pattern string) ([]string, error) {
+	cmd := fmt.Sprintf(`for f in %s; do if [ -e "$f" ] || [ -L "$f" ]; then printf '%%s\n' "$f"; fi; done`, globQuote(pattern))
+	out, err := c.runFile("glob", pattern, nil, cmd)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			matches = append(matches, line)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// Exists returns true if the path exists.
+func (c *SSHConnection) Exists(path string) (bool, error) {
+	_, stderr, err := c.run(nil, "test -e "+shellQuote(path))
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitStatus() == 1 && len(stderr) == 0 {
+		return false, nil
+	}
+	return false, fileError("stat", path, stderr, err)
+}
+
+// Exec runs a command and returns its combined output.
+func (c *SSHConnection) Exec(cmd string, args ...string) ([]byte, error) {
+	return c.combined(commandLine(cmd, args))
+}
+
+// ExecDir runs a command in the specified directory.
+func (c *SSHConnection) ExecDir(dir, cmd string, args ...string) ([]byte, error) {
+	return c.combined("cd " + shellQuote(dir) + " && " + commandLine(cmd, args))
+}
+
+// ExecEnv runs a command with additional environment variables.
+func (c *SSHConnection) ExecEnv(env map[string]string, cmd string, args ...string) ([]byte, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("env")
+	for _, k := range keys {
+		b.WriteString(" " + shellQuote(k+"="+env[k]))
+	}
+	b.WriteString(" " + commandLine(cmd, args))
+	return c.combined(b.String())
+}
+
+// combined runs a command line and returns stdout and stderr interleaved.
+func (c *SSHConnection) combined(command string) ([]byte, error) {
+	sess, err := c.session()
+	if err != nil {
+		return nil, err
+	}
+	defer sess.Close()
+	return sess.CombinedOutput(command)
+}
+
+// Tmux returns a tmux wrapper that runs tmux on the remote host.
+func (c *SSHConnection) Tmux() *tmux.Tmux {
+	return c.tmux
+}
+
+// Git returns a git wrapper for workDir on the remote host.
+func (c *SSHConnection) Git(workDir string) *git.Git {
+	return git.NewGitWithRunner(workDir, c.runGit)
+}
+
+// GitWithDir returns a git wrapper for a bare repo on the remote host.
+func (c *SSHConnection) GitWithDir(gitDir, workDir string) *git.Git {
+	return git.NewGitWithDirAndRunner(gitDir, workDir, c.runGit)
+}
+
+// runTmux is the tmux.Runner for this connection.
+func (c *SSHConnection) runTmux(args ...string) (string, string, error) {
+	stdout, stderr, err := c.run(nil, commandLine("tmux", args))
+	return string(stdout), string(stderr), err
+}
+
+// runGit is the git.Runner for this connection.
+func (c *SSHConnection) runGit(dir string, args ...string) (string, string, error) {
+	command := commandLine("git", args)
+	if dir != "" {
+		command = "cd " + shellQuote(dir) + " && " + command
+	}
+	stdout, stderr, err := c.run(nil, command)
+	return string(stdout), string(stderr), err
+}
+
+// TmuxNewSession creates a new tmux session.
+func (c *SSHConnection) TmuxNewSession(name, dir string) error {
+	return c.tmux.NewSession(name, dir)
+}
+
+// TmuxKillSession terminates a tmux session.
+func (c *SSHConnection) TmuxKillSession(name string) error {
+	return c.tmux.KillSession(name)
+}
+
+// TmuxSendKeys sends keys to a tmux session.
+func (c *SSHConnection) TmuxSendKeys(session, keys string) error {
+	return c.tmux.SendKeys(session, keys)
+}
+
+// TmuxCapturePane captures the last N lines from a tmux pane.
+func (c *SSHConnection) TmuxCapturePane(session string, lines int) (string, error) {
+	return c.tmux.CapturePane(session, lines)
+}
+
+// TmuxHasSession returns true if the session exists.
+func (c *SSHConnection) TmuxHasSession(name string) (bool, error) {
+	return c.tmux.HasSession(name)
+}
+
+// TmuxListSessions returns all tmux session names.
+func (c *SSHConnection) TmuxListSessions() ([]string, error) {
+	return c.tmux.ListSessions()
+}
+
+// commandLine quotes a command and its arguments for the remote shell.
+func commandLine(cmd string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(cmd))
+	for _, a := range args {
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, needsQuote) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func needsQuote(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./:=@+,%", r)
+}
+
+// globQuote escapes everything in pattern except the glob metacharacters
+// * ? [ ], so the remote shell expands the pattern but nothing else.
+func globQuote(pattern string) string {
+	var b strings.Builder
+	for _, r := range pattern {
+		if strings.ContainsRune("*?[]", r) || !needsQuote(r) {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteRune('\\')
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Verify SSHConnection implements Connection.
+var _ Connection = (*SSHConnection)(nil)
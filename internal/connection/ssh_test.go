@@ -0,0 +1,423 @@
+package connection
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testSSHServer is an in-process SSH server that runs exec requests with
+// the local sh.
+type testSSHServer struct {
+	addr    string
+	hostKey ssh.PublicKey
+	keyPath string // Client private key accepted by the server
+	conns   atomic.Int32
+}
+
+func startSSHServer(t *testing.T) *testSSHServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientPub, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	authorized, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &testSSHServer{
+		addr:    ln.Addr().String(),
+		hostKey: hostSigner.PublicKey(),
+		keyPath: keyPath,
+	}
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(nc, config)
+		}
+	}()
+	return s
+}
+
+func (s *testSSHServer) serve(nc net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(nc, config)
+	if err != nil {
+		nc.Close()
+		return
+	}
+	s.conns.Add(1)
+	go ssh.DiscardRequests(reqs)
+	for nch := range chans {
+		if nch.ChannelType() != "session" {
+			_ = nch.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		ch, chReqs, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		go serveSession(ch, chReqs)
+	}
+}
+
+func serveSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	for req := range reqs {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+
+		cmd := exec.Command("sh", "-c", payload.Command)
+		stdin, _ := cmd.StdinPipe()
+		cmd.Stdout = ch
+		cmd.Stderr = ch.Stderr()
+		status := uint32(0)
+		if err := cmd.Start(); err != nil {
+			status = 127
+		} else {
+			go func() {
+				_, _ = io.Copy(stdin, ch)
+				stdin.Close()
+			}()
+			if err := cmd.Wait(); err != nil {
+				status = 1
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					status = uint32(exitErr.ExitCode())
+				}
+			}
+		}
+		_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+func (s *testSSHServer) machine() *Machine {
+	return &Machine{
+		Name:     "build",
+		Type:     "ssh",
+		Host:     "tester@" + s.addr,
+		KeyPath:  s.keyPath,
+		TownPath: "/srv/wt",
+		HostKey:  string(ssh.MarshalAuthorizedKey(s.hostKey)),
+	}
+}
+
+func newTestSSHConnection(t *testing.T) (*SSHConnection, *testSSHServer) {
+	t.Helper()
+	srv := startSSHServer(t)
+	conn, err := NewSSHConnection(srv.machine())
+	if err != nil {
+		t.Fatalf("NewSSHConnection: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, srv
+}
+
+func TestSSHConnection_FileOps(t *testing.T) {
+	conn, _ := newTestSSHConnection(t)
+	dir := filepath.Join(t.TempDir(), "with space")
+
+	if err := conn.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	path := filepath.Join(dir, "it's.txt")
+	if err := conn.WriteFile(path, []byte("hello\n"), 0640); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	data, err := conn.ReadFile(path)
+	if err != nil || string(data) != "hello\n" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+
+	info, err := conn.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Name() != "it's.txt" || info.Size() != 6 || info.IsDir() || info.Mode().Perm() != 0640 {
+		t.Errorf("Stat = %+v", info)
+	}
+	local, _ := os.Stat(path)
+	if !info.ModTime().Equal(local.ModTime().Truncate(1e9)) {
+		t.Errorf("ModTime = %v, want %v", info.ModTime(), local.ModTime())
+	}
+	if info, err := conn.Stat(filepath.Join(dir, "sub")); err != nil || !info.IsDir() {
+		t.Errorf("Stat(dir) = %+v, %v", info, err)
+	}
+
+	matches, err := conn.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	want := []string{path, filepath.Join(dir, "sub")}
+	if strings.Join(matches, "|") != strings.Join(want, "|") {
+		t.Errorf("Glob = %v, want %v", matches, want)
+	}
+	if matches, err := conn.Glob(filepath.Join(dir, "*.none")); err != nil || len(matches) != 0 {
+		t.Errorf("Glob(no match) = %v, %v", matches, err)
+	}
+
+	if ok, err := conn.Exists(path); err != nil || !ok {
+		t.Errorf("Exists = %v, %v", ok, err)
+	}
+	if err := conn.Remove(path); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if ok, err := conn.Exists(path); err != nil || ok {
+		t.Errorf("Exists after Remove = %v, %v", ok, err)
+	}
+	if err := conn.Remove(path); err != nil {
+		t.Errorf("Remove of missing file: %v", err)
+	}
+
+	if err := conn.RemoveAll(dir); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("dir still exists: %v", err)
+	}
+}
+
+func TestSSHConnection_NotFound(t *testing.T) {
+	conn, _ := newTestSSHConnection(t)
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	var nf *NotFoundError
+	if _, err := conn.ReadFile(missing); !errors.As(err, &nf) {
+		t.Errorf("ReadFile error = %v, want NotFoundError", err)
+	}
+	if _, err := conn.Stat(missing); !errors.As(err, &nf) {
+		t.Errorf("Stat error = %v, want NotFoundError", err)
+	}
+}
+
+func TestSSHConnection_Exec(t *testing.T) {
+	conn, _ := newTestSSHConnection(t)
+
+	out, err := conn.Exec("echo", "it's", "two words")
+	if err != nil || string(out) != "it's two words\n" {
+		t.Errorf("Exec = %q, %v", out, err)
+	}
+
+	dir := t.TempDir()
+	out, err = conn.ExecDir(dir, "pwd")
+	if err != nil || strings.TrimSpace(string(out)) != dir {
+		t.Errorf("ExecDir = %q, %v", out, err)
+	}
+
+	out, err = conn.ExecEnv(map[string]string{"WT_A": "one two", "WT_B": "$HOME"}, "sh", "-c", `echo "$WT_A|$WT_B"`)
+	if err != nil || string(out) != "one two|$HOME\n" {
+		t.Errorf("ExecEnv = %q, %v", out, err)
+	}
+
+	out, err = conn.Exec("sh", "-c", "echo oops >&2; exit 3")
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 3 || string(out) != "oops\n" {
+		t.Errorf("Exec failure = %q, %v", out, err)
+	}
+}
+
+func TestSSHConnection_Multiplexed(t *testing.T) {
+	conn, srv := newTestSSHConnection(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			out, err := conn.Exec("echo", fmt.Sprint(i))
+			if err == nil && strings.TrimSpace(string(out)) != fmt.Sprint(i) {
+				err = fmt.Errorf("got %q", out)
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Exec: %v", err)
+		}
+	}
+	if n := srv.conns.Load(); n != 1 {
+		t.Errorf("server saw %d connections, want 1", n)
+	}
+
+	// A closed connection is re-established on next use
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := conn.Exec("true"); err != nil {
+		t.Fatalf("Exec after Close: %v", err)
+	}
+	if n := srv.conns.Load(); n != 2 {
+		t.Errorf("server saw %d connections, want 2", n)
+	}
+}
+
+func TestSSHConnection_HostKeyMismatch(t *testing.T) {
+	srv := startSSHServer(t)
+	other := startSSHServer(t)
+
+	m := srv.machine()
+	m.HostKey = string(ssh.MarshalAuthorizedKey(other.hostKey))
+	conn, err := NewSSHConnection(m)
+	if err != nil {
+		t.Fatalf("NewSSHConnection: %v", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec("true")
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) || connErr.Op != "connect" {
+		t.Errorf("Exec error = %v, want connect error", err)
+	}
+}
+
+func TestSSHConnection_Git(t *testing.T) {
+	conn, _ := newTestSSHConnection(t)
+	dir := t.TempDir()
+
+	if out, err := conn.ExecDir(dir, "git", "init", "-q"); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	if !conn.Git(dir).IsRepo() {
+		t.Error("IsRepo = false for remote repo")
+	}
+	if conn.Git(t.TempDir()).IsRepo() {
+		t.Error("IsRepo = true for empty dir")
+	}
+	if !conn.Tmux().IsRemote() {
+		t.Error("Tmux().IsRemote() = false")
+	}
+}
+
+func TestScanHostKey(t *testing.T) {
+	srv := startSSHServer(t)
+
+	key, err := ScanHostKey("anyone@" + srv.addr)
+	if err != nil {
+		t.Fatalf("ScanHostKey: %v", err)
+	}
+	if want := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(srv.hostKey))); key != want {
+		t.Errorf("key = %q, want %q", key, want)
+	}
+}
+
+func TestParseSSHHost(t *testing.T) {
+	tests := []struct {
+		host, user, addr string
+	}{
+		{"ci@build1", "ci", "build1:22"},
+		{"ci@build1:2222", "ci", "build1:2222"},
+		{"ci@[::1]:2222", "ci", "[::1]:2222"},
+		{"ci@::1", "ci", "[::1]:22"},
+	}
+	for _, tt := range tests {
+		user, addr, err := ParseSSHHost(tt.host)
+		if err != nil || user != tt.user || addr != tt.addr {
+			t.Errorf("ParseSSHHost(%q) = %q, %q, %v; want %q, %q", tt.host, user, addr, err, tt.user, tt.addr)
+		}
+	}
+	if _, _, err := ParseSSHHost(""); err == nil {
+		t.Error("ParseSSHHost(\"\") succeeded")
+	}
+}
+
+func TestMachineRegistry_SSHConnection(t *testing.T) {
+	srv := startSSHServer(t)
+	reg, err := NewMachineRegistry(filepath.Join(t.TempDir(), "machines.json"))
+	if err != nil {
+		t.Fatalf("NewMachineRegistry: %v", err)
+	}
+	defer reg.Close()
+
+	if err := reg.Add(srv.machine()); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	c1, err := reg.Connection("build")
+	if err != nil {
+		t.Fatalf("Connection: %v", err)
+	}
+	c2, _ := reg.Connection("build")
+	if c1 != c2 {
+		t.Error("Connection did not reuse the open SSH connection")
+	}
+	if c1.IsLocal() || c1.Name() != "build" {
+		t.Errorf("connection = %s (local=%v)", c1.Name(), c1.IsLocal())
+	}
+	if out, err := c1.Exec("echo", "ok"); err != nil || string(out) != "ok\n" {
+		t.Errorf("Exec = %q, %v", out, err)
+	}
+
+	// Reloaded registry keeps the pinned key and town path
+	reg2, err := NewMachineRegistry(reg.path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	m, err := reg2.Get("build")
+	if err != nil || m.HostKey == "" || m.RigPath("whaletown") != "/srv/wt/whaletown" {
+		t.Errorf("reloaded machine = %+v, %v", m, err)
+	}
+}
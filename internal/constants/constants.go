@@ -72,6 +72,12 @@ const (
 	// FileAccountsJSON is the accounts configuration file in mayor/.
 	FileAccountsJSON = "accounts.json"
 
+	// FileMachinesJSON is the machine registry file in mayor/.
+	FileMachinesJSON = "machines.json"
+
+	// FileFederationJSON is the peer town registry file in mayor/.
+	FileFederationJSON = "federation.json"
+
 	// FileHandoffMarker is the marker file indicating a handoff just occurred.
 	// Written by wt handoff before respawn, cleared by wt prime after detection.
 	// This prevents the handoff loop bug where agents re-run /handoff from context.
@@ -241,3 +247,13 @@ func RigSettingsPath(rigPath string) string {
 func MayorAccountsPath(townRoot string) string {
 	return townRoot + "/" + DirMayor + "/" + FileAccountsJSON
 }
+
+// MayorMachinesPath returns the path to mayor/machines.json within a town root.
+func MayorMachinesPath(townRoot string) string {
+	return townRoot + "/" + DirMayor + "/" + FileMachinesJSON
+}
+
+// MayorFederationPath returns the path to mayor/federation.json within a town root.
+func MayorFederationPath(townRoot string) string {
+	return townRoot + "/" + DirMayor + "/" + FileFederationJSON
+}
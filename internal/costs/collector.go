@@ -0,0 +1,262 @@
+package costs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/constants"
+	"github.com/speaker20/whaletown/internal/events"
+)
+
+// Session is an agent session discovered from the town's events log.
+type Session struct {
+	ID        string    `json:"session_id"`
+	Actor     string    `json:"actor"`
+	Role      string    `json:"role"`
+	Rig       string    `json:"rig,omitempty"`
+	Worker    string    `json:"worker,omitempty"`
+	Bead      string    `json:"bead,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// SessionCost is a session's token usage and cost.
+type SessionCost struct {
+	Session
+	Transcript string    `json:"transcript"`
+	Models     []string  `json:"models,omitempty"`
+	Usage      Usage     `json:"usage"`
+	CostUSD    float64   `json:"cost_usd"`
+	Unpriced   []string  `json:"unpriced_models,omitempty"`
+	EndedAt    time.Time `json:"ended_at"` // Last transcript activity
+}
+
+// Collector computes session costs from transcripts.
+type Collector struct {
+	// TownRoot locates the events log.
+	TownRoot string
+
+	// TranscriptDirs are searched for session transcripts.
+	TranscriptDirs []string
+
+	// Prices converts token usage to USD.
+	Prices PriceTable
+}
+
+// NewCollector creates a collector using the town's settings/costs.json
+// for price overrides and transcript locations.
+func NewCollector(townRoot string) (*Collector, error) {
+	cfg, err := config.LoadCostsConfig(config.CostsConfigPath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	dirs := cfg.TranscriptDirs
+	if len(dirs) == 0 {
+		dirs = DefaultTranscriptDirs()
+	}
+	return &Collector{
+		TownRoot:       townRoot,
+		TranscriptDirs: dirs,
+		Prices:         NewPriceTable(cfg.Prices),
+	}, nil
+}
+
+// Cost prices the transcript at path and attributes it to s.
+func (c *Collector) Cost(s Session, path string) (*SessionCost, error) {
+	tr, err := ReadTranscript(path)
+	if err != nil {
+		return nil, err
+	}
+	if s.ID == "" {
+		s.ID = tr.SessionID
+	}
+	if s.StartedAt.IsZero() {
+		s.StartedAt = tr.FirstAt
+	}
+	cost, unpriced := c.Prices.TranscriptCost(tr)
+	return &SessionCost{
+		Session:    s,
+		Transcript: path,
+		Models:     tr.Models(),
+		Usage:      tr.Usage(),
+		CostUSD:    cost,
+		Unpriced:   unpriced,
+		EndedAt:    tr.LastAt,
+	}, nil
+}
+
+// SessionCost finds and prices the transcript for s. Returns nil if the
+// session has no transcript (e.g. a non-Claude runtime or a fallback ID).
+func (c *Collector) SessionCost(s Session) (*SessionCost, error) {
+	path := FindTranscript(c.TranscriptDirs, s.ID)
+	if path == "" {
+		return nil, nil
+	}
+	return c.Cost(s, path)
+}
+
+// Collect prices every session started at or after since that has a
+// transcript, most recent first.
+func (c *Collector) Collect(since time.Time) ([]*SessionCost, error) {
+	sessions, err := c.Sessions(since)
+	if err != nil {
+		return nil, err
+	}
+	var out []*SessionCost
+	for _, s := range sessions {
+		sc, err := c.SessionCost(s)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: %w", s.ID, err)
+		}
+		if sc != nil {
+			out = append(out, sc)
+		}
+	}
+	return out, nil
+}
+
+// rawEvent is the subset of an events log entry we read.
+type rawEvent struct {
+	Timestamp string                 `json:"ts"`
+	Type      string                 `json:"type"`
+	Actor     string                 `json:"actor"`
+	Payload   map[string]interface{} `json:"payload"`
+}
+
+// Sessions lists sessions from session_start events at or after since,
+// most recent first. A session that restarted (resume, compaction) is
+// listed once, from its first start. Each session is attributed to the
+// bead most recently slung to its actor.
+func (c *Collector) Sessions(since time.Time) ([]Session, error) {
+	f, err := os.Open(filepath.Join(c.TownRoot, events.EventsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening events: %w", err)
+	}
+	defer f.Close()
+
+	type sling struct {
+		at   time.Time
+		bead string
+	}
+	byID := make(map[string]*Session)
+	slings := make(map[string][]sling) // target -> slings, in log order
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev rawEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		if ev.Type != events.TypeSessionStart && ev.Type != events.TypeSling {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, ev.Timestamp)
+		if err != nil {
+			continue
+		}
+
+		if ev.Type == events.TypeSling {
+			target, bead := payloadString(ev.Payload, "target"), payloadString(ev.Payload, "bead")
+			if target != "" && bead != "" {
+				slings[target] = append(slings[target], sling{at: ts, bead: bead})
+			}
+			continue
+		}
+
+		id := payloadString(ev.Payload, "session_id")
+		if id == "" {
+			continue
+		}
+		if s, ok := byID[id]; ok {
+			if ts.Before(s.StartedAt) {
+				s.StartedAt = ts
+			}
+			continue
+		}
+		actor := ev.Actor
+		if actor == "" {
+			actor = payloadString(ev.Payload, "role")
+		}
+		role, rig, worker := ParseActor(actor)
+		byID[id] = &Session{ID: id, Actor: actor, Role: role, Rig: rig, Worker: worker, StartedAt: ts}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading events: %w", err)
+	}
+
+	all := make([]*Session, 0, len(byID))
+	for _, s := range byID {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].StartedAt.Before(all[j].StartedAt) })
+
+	// Attribute beads: the latest sling to the actor by the time the
+	// session started, else the first one during the session (an agent
+	// slinging work to itself).
+	nextStart := make(map[string]time.Time)
+	for i := len(all) - 1; i >= 0; i-- {
+		s := all[i]
+		end, hasNext := nextStart[s.Actor]
+		for _, sl := range slings[s.Actor] {
+			if !sl.at.After(s.StartedAt) {
+				s.Bead = sl.bead
+				continue
+			}
+			if s.Bead == "" && (!hasNext || sl.at.Before(end)) {
+				s.Bead = sl.bead
+			}
+			break
+		}
+		nextStart[s.Actor] = s.StartedAt
+	}
+
+	var out []Session
+	for i := len(all) - 1; i >= 0; i-- {
+		if !all[i].StartedAt.Before(since) {
+			out = append(out, *all[i])
+		}
+	}
+	return out, nil
+}
+
+// ParseActor splits an agent identity into role, rig and worker.
+// Examples:
+//   - mayor -> mayor, "", ""
+//   - whaletown/witness -> witness, whaletown, ""
+//   - whaletown/polecats/toast -> polecat, whaletown, toast
+//   - whaletown/crew/joe -> crew, whaletown, joe
+//   - deacon/dogs/alpha -> dog, "", alpha
+func ParseActor(actor string) (role, rig, worker string) {
+	parts := strings.Split(strings.TrimSuffix(actor, "/"), "/")
+	switch {
+	case len(parts) == 1:
+		return parts[0], "", ""
+	case len(parts) == 3 && parts[0] == constants.RoleDeacon && parts[1] == "dogs":
+		return "dog", "", parts[2]
+	case len(parts) == 2:
+		return parts[1], parts[0], ""
+	case len(parts) == 3 && parts[1] == "polecats":
+		return constants.RolePolecat, parts[0], parts[2]
+	case len(parts) == 3 && parts[1] == constants.RoleCrew:
+		return constants.RoleCrew, parts[0], parts[2]
+	default:
+		return "unknown", parts[0], parts[len(parts)-1]
+	}
+}
+
+func payloadString(payload map[string]interface{}, key string) string {
+	if v, ok := payload[key].(string); ok {
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package costs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/events"
+)
+
+func writeEvents(t *testing.T, townRoot string, lines ...string) {
+	t.Helper()
+	data := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(townRoot, events.EventsFile), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollector_SessionsAttribution(t *testing.T) {
+	town := t.TempDir()
+	writeEvents(t, town,
+		`{"ts":"2026-03-10T09:00:00Z","type":"sling","actor":"mayor","payload":{"bead":"wt-old","target":"wt/polecats/toast"}}`,
+		`{"ts":"2026-03-10T09:59:00Z","type":"sling","actor":"mayor","payload":{"bead":"wt-abc","target":"wt/polecats/toast"}}`,
+		`{"ts":"2026-03-10T10:00:00Z","type":"session_start","actor":"wt/polecats/toast","payload":{"session_id":"s1","role":"wt/polecats/toast"}}`,
+		`{"ts":"2026-03-10T10:30:00Z","type":"session_start","actor":"wt/polecats/toast","payload":{"session_id":"s1","role":"wt/polecats/toast"}}`,
+		`{"ts":"2026-03-10T11:00:00Z","type":"session_start","actor":"wt/witness","payload":{"session_id":"s2","role":"wt/witness"}}`,
+		`{"ts":"2026-03-10T12:00:00Z","type":"sling","actor":"mayor","payload":{"bead":"wt-def","target":"wt/polecats/toast"}}`,
+		`{"ts":"2026-03-10T12:01:00Z","type":"session_start","actor":"wt/polecats/toast","payload":{"session_id":"s3","role":"wt/polecats/toast"}}`,
+		`garbage`,
+	)
+
+	c := &Collector{TownRoot: town}
+	sessions, err := c.Sessions(time.Time{})
+	if err != nil {
+		t.Fatalf("Sessions: %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("got %d sessions, want 3: %+v", len(sessions), sessions)
+	}
+
+	// Most recent first
+	s3, s2, s1 := sessions[0], sessions[1], sessions[2]
+	if s3.ID != "s3" || s3.Bead != "wt-def" || s3.Role != "polecat" || s3.Rig != "wt" || s3.Worker != "toast" {
+		t.Errorf("s3 = %+v", s3)
+	}
+	if s2.ID != "s2" || s2.Bead != "" || s2.Role != "witness" {
+		t.Errorf("s2 = %+v", s2)
+	}
+	if s1.ID != "s1" || s1.Bead != "wt-abc" || s1.StartedAt.Hour() != 10 || s1.StartedAt.Minute() != 0 {
+		t.Errorf("s1 = %+v (restarts keep the first start)", s1)
+	}
+
+	recent, err := c.Sessions(time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC))
+	if err != nil || len(recent) != 2 {
+		t.Errorf("Sessions(since) = %d, %v; want 2", len(recent), err)
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	town := t.TempDir()
+	transcripts := t.TempDir()
+	writeTranscript(t, transcripts, "-town-wt-polecats-toast", "s1", sampleTranscript)
+	writeEvents(t, town,
+		`{"ts":"2026-03-10T10:00:00Z","type":"session_start","actor":"wt/polecats/toast","payload":{"session_id":"s1"}}`,
+		`{"ts":"2026-03-10T11:00:00Z","type":"session_start","actor":"mayor","payload":{"session_id":"mayor-1234"}}`,
+	)
+
+	// Price overrides come from settings/costs.json
+	cfg := `{"type":"costs","version":1,"prices":{"claude-sonnet-4-5":{"input":1,"output":1,"cache_write":1,"cache_read":1}},"transcript_dirs":["` + transcripts + `"]}`
+	if err := os.MkdirAll(filepath.Join(town, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config.CostsConfigPath(town), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCollector(town)
+	if err != nil {
+		t.Fatalf("NewCollector: %v", err)
+	}
+	got, err := c.Collect(time.Time{})
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	// The mayor session has no transcript (fallback ID) and is skipped
+	if len(got) != 1 {
+		t.Fatalf("got %d costs, want 1", len(got))
+	}
+	sc := got[0]
+	if sc.ID != "s1" || sc.Role != "polecat" || sc.Usage.OutputTokens != 240 {
+		t.Errorf("cost = %+v", sc)
+	}
+	// sonnet at $1/M for 1210 tokens + haiku at list price
+	want := 1210.0/1e6 + (300*1+40*5+2000*0.10)/1e6
+	if !near(sc.CostUSD, want) {
+		t.Errorf("CostUSD = %v, want %v", sc.CostUSD, want)
+	}
+	if !sc.EndedAt.Equal(time.Date(2026, 3, 10, 10, 2, 0, 0, time.UTC)) {
+		t.Errorf("EndedAt = %s", sc.EndedAt)
+	}
+}
+
+func TestParseActor(t *testing.T) {
+	tests := []struct {
+		actor, role, rig, worker string
+	}{
+		{"mayor", "mayor", "", ""},
+		{"deacon", "deacon", "", ""},
+		{"wt/witness", "witness", "wt", ""},
+		{"wt/refinery", "refinery", "wt", ""},
+		{"wt/polecats/toast", "polecat", "wt", "toast"},
+		{"wt/crew/joe", "crew", "wt", "joe"},
+		{"deacon/dogs/alpha", "dog", "", "alpha"},
+	}
+	for _, tt := range tests {
+		role, rig, worker := ParseActor(tt.actor)
+		if role != tt.role || rig != tt.rig || worker != tt.worker {
+			t.Errorf("ParseActor(%q) = %q, %q, %q", tt.actor, role, rig, worker)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package costs
+
+import (
+	"strings"
+
+	"github.com/speaker20/whaletown/internal/config"
+)
+
+// PriceTable maps model name prefixes to per-million-token prices.
+type PriceTable map[string]config.ModelPrice
+
+// DefaultPrices returns list prices for Claude models, in USD per million
+// tokens. Cache writes are priced at the 5-minute TTL rate.
+func DefaultPrices() PriceTable {
+	opus := config.ModelPrice{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50}
+	sonnet := config.ModelPrice{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30}
+	return PriceTable{
+		"claude-opus-4-5":   {Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.50},
+		"claude-opus-4":     opus,
+		"claude-3-opus":     opus,
+		"claude-sonnet-4":   sonnet,
+		"claude-3-7-sonnet": sonnet,
+		"claude-3-5-sonnet": sonnet,
+		"claude-haiku-4-5":  {Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.10},
+		"claude-3-5-haiku":  {Input: 0.80, Output: 4, CacheWrite: 1, CacheRead: 0.08},
+		"claude-3-haiku":    {Input: 0.25, Output: 1.25, CacheWrite: 0.30, CacheRead: 0.03},
+	}
+}
+
+// NewPriceTable returns the default prices with overrides applied.
+func NewPriceTable(overrides map[string]config.ModelPrice) PriceTable {
+	t := DefaultPrices()
+	for model, p := range overrides {
+		t[model] = p
+	}
+	return t
+}
+
+// Lookup returns the price for the longest prefix of model in the table.
+func (t PriceTable) Lookup(model string) (config.ModelPrice, bool) {
+	best := ""
+	for prefix := range t {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return config.ModelPrice{}, false
+	}
+	return t[best], true
+}
+
+// Cost returns the USD cost of usage on model, and whether the model was
+// priced. Unpriced models cost zero.
+func (t PriceTable) Cost(model string, u Usage) (float64, bool) {
+	p, ok := t.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(u.InputTokens)*p.Input +
+		float64(u.OutputTokens)*p.Output +
+		float64(u.CacheWriteTokens)*p.CacheWrite +
+		float64(u.CacheReadTokens)*p.CacheRead) / 1e6, true
+}
+
+// TranscriptCost prices every model in a transcript. It returns the total
+// and the models that had no price.
+func (t PriceTable) TranscriptCost(tr *Transcript) (float64, []string) {
+	var total float64
+	var unpriced []string
+	for _, model := range tr.Models() {
+		cost, ok := t.Cost(model, *tr.ByModel[model])
+		if !ok {
+			unpriced = append(unpriced, model)
+			continue
+		}
+		total += cost
+	}
+	return total, unpriced
+}
@@ -0,0 +1,205 @@
+// Package costs computes token usage and cost for agent sessions from the
+// runtime's session transcripts.
+//
+// Claude Code writes one JSONL transcript per session under
+// ~/.claude/projects/<encoded-cwd>/<session-id>.jsonl. Each assistant
+// message records its model and token usage; summing those and applying a
+// price table gives the session's cost. Sessions are discovered from the
+// session_start events that wt prime logs, and attributed to a rig, role,
+// worker and bead from the event's actor and the town's sling events.
+package costs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Usage counts tokens by kind.
+type Usage struct {
+	InputTokens      int64 `json:"input_tokens"`
+	OutputTokens     int64 `json:"output_tokens"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+}
+
+// Add accumulates o into u.
+func (u *Usage) Add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheWriteTokens += o.CacheWriteTokens
+	u.CacheReadTokens += o.CacheReadTokens
+}
+
+// Total returns the number of tokens of all kinds.
+func (u Usage) Total() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheWriteTokens + u.CacheReadTokens
+}
+
+// Transcript is the usage recorded in one session transcript.
+type Transcript struct {
+	Path      string            `json:"path"`
+	SessionID string            `json:"session_id"`
+	ByModel   map[string]*Usage `json:"by_model"`
+	Messages  int               `json:"messages"`
+	FirstAt   time.Time         `json:"first_at"`
+	LastAt    time.Time         `json:"last_at"`
+}
+
+// Usage returns the transcript's usage summed across models.
+func (t *Transcript) Usage() Usage {
+	var u Usage
+	for _, mu := range t.ByModel {
+		u.Add(*mu)
+	}
+	return u
+}
+
+// Models returns the models used, sorted.
+func (t *Transcript) Models() []string {
+	models := make([]string, 0, len(t.ByModel))
+	for m := range t.ByModel {
+		models = append(models, m)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// transcriptLine is the subset of a transcript entry we read.
+type transcriptLine struct {
+	Type      string `json:"type"`
+	SessionID string `json:"sessionId"`
+	Timestamp string `json:"timestamp"`
+	Message   *struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// ReadTranscript sums the token usage of every assistant message in a
+// transcript. A message streamed as several entries shares one message ID
+// and is counted once, using its last entry.
+func ReadTranscript(path string) (*Transcript, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: transcript paths come from the runtime
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	type message struct {
+		model string
+		usage Usage
+	}
+	messages := make(map[string]message)
+	var order []string
+
+	t := &Transcript{Path: path, ByModel: make(map[string]*Usage)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	anon := 0
+	for scanner.Scan() {
+		var line transcriptLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue // Skip malformed lines
+		}
+		if t.SessionID == "" {
+			t.SessionID = line.SessionID
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, line.Timestamp); err == nil {
+			if t.FirstAt.IsZero() || ts.Before(t.FirstAt) {
+				t.FirstAt = ts
+			}
+			if ts.After(t.LastAt) {
+				t.LastAt = ts
+			}
+		}
+		if line.Type != "assistant" || line.Message == nil || line.Message.Usage == nil {
+			continue
+		}
+		if line.Message.Model == "<synthetic>" {
+			continue // Locally generated, not billed
+		}
+
+		id := line.Message.ID
+		if id == "" {
+			anon++
+			id = fmt.Sprintf("anon-%d", anon)
+		}
+		if _, seen := messages[id]; !seen {
+			order = append(order, id)
+		}
+		u := line.Message.Usage
+		messages[id] = message{
+			model: line.Message.Model,
+			usage: Usage{
+				InputTokens:      u.InputTokens,
+				OutputTokens:     u.OutputTokens,
+				CacheWriteTokens: u.CacheCreationInputTokens,
+				CacheReadTokens:  u.CacheReadInputTokens,
+			},
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading transcript %s: %w", path, err)
+	}
+
+	for _, id := range order {
+		m := messages[id]
+		mu := t.ByModel[m.model]
+		if mu == nil {
+			mu = &Usage{}
+			t.ByModel[m.model] = mu
+		}
+		mu.Add(m.usage)
+		t.Messages++
+	}
+	return t, nil
+}
+
+// DefaultTranscriptDirs returns where Claude Code keeps transcripts:
+// $CLAUDE_CONFIG_DIR/projects, or ~/.claude/projects.
+func DefaultTranscriptDirs() []string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return []string{filepath.Join(dir, "projects")}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, ".claude", "projects")}
+}
+
+// FindTranscript looks for <sessionID>.jsonl directly in, or one level
+// below, each of dirs. Returns "" if not found.
+func FindTranscript(dirs []string, sessionID string) string {
+	if sessionID == "" || filepath.Base(sessionID) != sessionID || strings.ContainsAny(sessionID, "*?[\\") {
+		return ""
+	}
+	name := sessionID + ".jsonl"
+	for _, dir := range dirs {
+		if p := filepath.Join(dir, name); fileExists(p) {
+			return p
+		}
+		matches, _ := filepath.Glob(filepath.Join(dir, "*", name))
+		if len(matches) > 0 {
+			return matches[0]
+		}
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,119 @@
+package costs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// A Claude Code transcript: one message streamed as two entries, a second
+// model, a synthetic message, and non-assistant lines.
+const sampleTranscript = `{"type":"summary","summary":"work"}
+{"type":"user","sessionId":"sess-1","timestamp":"2026-03-10T10:00:00.000Z","message":{"role":"user","content":"hi"}}
+{"type":"assistant","sessionId":"sess-1","timestamp":"2026-03-10T10:00:05.000Z","message":{"id":"msg_1","model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":1000,"cache_read_input_tokens":0}}}
+{"type":"assistant","sessionId":"sess-1","timestamp":"2026-03-10T10:00:06.000Z","message":{"id":"msg_1","model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":10,"output_tokens":200,"cache_creation_input_tokens":1000,"cache_read_input_tokens":0}}}
+not json
+{"type":"assistant","sessionId":"sess-1","timestamp":"2026-03-10T10:01:00.000Z","message":{"id":"msg_2","model":"claude-haiku-4-5-20251001","usage":{"input_tokens":300,"output_tokens":40,"cache_read_input_tokens":2000}}}
+{"type":"assistant","sessionId":"sess-1","timestamp":"2026-03-10T10:02:00.000Z","message":{"id":"msg_3","model":"<synthetic>","usage":{"input_tokens":0,"output_tokens":0}}}
+`
+
+func writeTranscript(t *testing.T, dir, project, sessionID, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, project, sessionID+".jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadTranscript(t *testing.T) {
+	path := writeTranscript(t, t.TempDir(), "-home-me-town", "sess-1", sampleTranscript)
+
+	tr, err := ReadTranscript(path)
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	if tr.SessionID != "sess-1" || tr.Messages != 2 {
+		t.Errorf("session=%q messages=%d, want sess-1, 2", tr.SessionID, tr.Messages)
+	}
+
+	sonnet := tr.ByModel["claude-sonnet-4-5-20250929"]
+	if sonnet == nil || *sonnet != (Usage{InputTokens: 10, OutputTokens: 200, CacheWriteTokens: 1000}) {
+		t.Errorf("sonnet usage = %+v (streamed message must count once, from its last entry)", sonnet)
+	}
+	want := Usage{InputTokens: 310, OutputTokens: 240, CacheWriteTokens: 1000, CacheReadTokens: 2000}
+	if got := tr.Usage(); got != want {
+		t.Errorf("Usage = %+v, want %+v", got, want)
+	}
+	if got := strings.Join(tr.Models(), ","); got != "claude-haiku-4-5-20251001,claude-sonnet-4-5-20250929" {
+		t.Errorf("Models = %s", got)
+	}
+	if tr.FirstAt.Minute() != 0 || tr.LastAt.Minute() != 2 {
+		t.Errorf("FirstAt=%s LastAt=%s", tr.FirstAt, tr.LastAt)
+	}
+}
+
+func TestFindTranscript(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTranscript(t, dir, "-home-me-town-mayor", "abc-123", "{}\n")
+
+	if got := FindTranscript([]string{t.TempDir(), dir}, "abc-123"); got != path {
+		t.Errorf("FindTranscript = %q, want %q", got, path)
+	}
+	for _, id := range []string{"", "missing", "../abc-123", "*"} {
+		if got := FindTranscript([]string{dir}, id); got != "" {
+			t.Errorf("FindTranscript(%q) = %q, want none", id, got)
+		}
+	}
+}
+
+func TestPriceTable(t *testing.T) {
+	prices := DefaultPrices()
+
+	// Longest prefix wins
+	p, ok := prices.Lookup("claude-opus-4-5-20251101")
+	if !ok || p.Input != 5 {
+		t.Errorf("opus 4.5 price = %+v, %v", p, ok)
+	}
+	p, ok = prices.Lookup("claude-opus-4-1-20250805")
+	if !ok || p.Input != 15 {
+		t.Errorf("opus 4.1 price = %+v, %v", p, ok)
+	}
+	if _, ok := prices.Lookup("gpt-5"); ok {
+		t.Error("unexpected price for unknown model")
+	}
+
+	// 1M input + 1M output on sonnet = $3 + $15
+	cost, ok := prices.Cost("claude-sonnet-4-20250514", Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000})
+	if !ok || cost != 18 {
+		t.Errorf("Cost = %v, %v; want 18", cost, ok)
+	}
+}
+
+func TestTranscriptCost_Overrides(t *testing.T) {
+	path := writeTranscript(t, t.TempDir(), "p", "sess-1", sampleTranscript)
+	tr, err := ReadTranscript(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := NewPriceTable(nil)
+	delete(table, "claude-haiku-4-5")
+	cost, unpriced := table.TranscriptCost(tr)
+	if len(unpriced) != 1 || unpriced[0] != "claude-haiku-4-5-20251001" {
+		t.Errorf("unpriced = %v", unpriced)
+	}
+	// sonnet: 10*3 + 200*15 + 1000*3.75 per million
+	if want := (10*3 + 200*15 + 1000*3.75) / 1e6; !near(cost, want) {
+		t.Errorf("cost = %v, want %v", cost, want)
+	}
+}
+
+func near(a, b float64) bool {
+	d := a - b
+	return d < 1e-12 && d > -1e-12
+}
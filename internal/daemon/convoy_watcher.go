@@ -11,17 +11,28 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/speaker20/whaletown/internal/beads"
+	"github.com/speaker20/whaletown/internal/beadsdb"
 )
 
+// overdueCheckInterval is how often the convoy watcher looks for convoys
+// that have passed their deadline.
+const overdueCheckInterval = 5 * time.Minute
+
 // ConvoyWatcher monitors bd activity for issue closes and triggers convoy completion checks.
 // When an issue closes, it checks if the issue is tracked by any convoy and runs the
 // completion check if all tracked issues are now closed.
+//
+// It also periodically looks for open convoys past their deadline and escalates
+// each one once through the town's escalation routes.
 type ConvoyWatcher struct {
 	townRoot string
 	ctx      context.Context
 	cancel   context.CancelFunc
 	wg       sync.WaitGroup
 	logger   func(format string, args ...interface{})
+	now      func() time.Time
 }
 
 // bdActivityEvent represents an event from bd activity --json.
@@ -43,13 +54,15 @@ func NewConvoyWatcher(townRoot string, logger func(format string, args ...interf
 		ctx:      ctx,
 		cancel:   cancel,
 		logger:   logger,
+		now:      time.Now,
 	}
 }
 
-// Start begins the convoy watcher goroutine.
+// Start begins the convoy watcher goroutines.
 func (w *ConvoyWatcher) Start() error {
-	w.wg.Add(1)
+	w.wg.Add(2)
 	go w.run()
+	go w.runOverdueChecks()
 	return nil
 }
 
@@ -152,69 +165,28 @@ func (w *ConvoyWatcher) processLine(line string) {
 
 // getTrackingConvoys returns convoy IDs that track the given issue.
 func (w *ConvoyWatcher) getTrackingConvoys(issueID string) []string {
-	townBeads := filepath.Join(w.townRoot, ".beads")
-	dbPath := filepath.Join(townBeads, "beads.db")
-
-	// Query for convoys that track this issue
-	// Handle both direct ID and external reference format
-	safeIssueID := strings.ReplaceAll(issueID, "'", "''")
-
-	// Query for dependencies where this issue is the target
-	// Convoys use "tracks" type: convoy -> tracked issue (depends_on_id)
-	query := fmt.Sprintf(`
-		SELECT DISTINCT issue_id FROM dependencies
-		WHERE type = 'tracks'
-		AND (depends_on_id = '%s' OR depends_on_id LIKE '%%:%s')
-	`, safeIssueID, safeIssueID)
-
-	queryCmd := exec.Command("sqlite3", "-json", dbPath, query)
-	var stdout bytes.Buffer
-	queryCmd.Stdout = &stdout
-
-	if err := queryCmd.Run(); err != nil {
-		return nil
-	}
-
-	var results []struct {
-		IssueID string `json:"issue_id"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+	convoyIDs, err := w.db().TrackingConvoys(w.ctx, issueID)
+	if err != nil {
 		return nil
 	}
-
-	convoyIDs := make([]string, 0, len(results))
-	for _, r := range results {
-		convoyIDs = append(convoyIDs, r.IssueID)
-	}
 	return convoyIDs
 }
 
+// db returns the town beads database.
+func (w *ConvoyWatcher) db() *beadsdb.DB {
+	return beadsdb.Open(filepath.Join(w.townRoot, ".beads"))
+}
+
 // checkConvoyCompletion checks if all issues tracked by a convoy are closed.
 // If so, runs wt convoy check to close the convoy.
 func (w *ConvoyWatcher) checkConvoyCompletion(convoyID string) {
-	townBeads := filepath.Join(w.townRoot, ".beads")
-	dbPath := filepath.Join(townBeads, "beads.db")
-
 	// First check if the convoy is still open
-	convoyQuery := fmt.Sprintf(`SELECT status FROM issues WHERE id = '%s'`,
-		strings.ReplaceAll(convoyID, "'", "''"))
-
-	queryCmd := exec.Command("sqlite3", "-json", dbPath, convoyQuery)
-	var stdout bytes.Buffer
-	queryCmd.Stdout = &stdout
-
-	if err := queryCmd.Run(); err != nil {
+	convoys, err := w.db().Issues(w.ctx, []string{convoyID})
+	if err != nil || convoys[convoyID] == nil {
 		return
 	}
 
-	var convoyStatus []struct {
-		Status string `json:"status"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &convoyStatus); err != nil || len(convoyStatus) == 0 {
-		return
-	}
-
-	if convoyStatus[0].Status == "closed" {
+	if convoys[convoyID].Status == "closed" {
 		return // Already closed
 	}
 
@@ -237,3 +209,90 @@ func (w *ConvoyWatcher) checkConvoyCompletion(convoyID string) {
 		w.logger("convoy watcher: %s", strings.TrimSpace(output))
 	}
 }
+
+// runOverdueChecks checks for overdue convoys at startup and then on a ticker.
+func (w *ConvoyWatcher) runOverdueChecks() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(overdueCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		w.checkOverdueConvoys()
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkOverdueConvoys escalates open convoys that have passed their deadline.
+// The escalation ID is recorded on the convoy so each convoy is escalated once.
+func (w *ConvoyWatcher) checkOverdueConvoys() {
+	townBeads := filepath.Join(w.townRoot, ".beads")
+
+	convoys, err := w.db().Convoys(w.ctx, "open")
+	if err != nil {
+		return
+	}
+
+	now := w.now()
+	for _, c := range convoys {
+		fields := beads.ParseConvoyFields(c.Description)
+		if !fields.IsOverdue(c.Status, now) || fields.OverdueEscalation != "" {
+			continue
+		}
+
+		w.logger("convoy watcher: %s is overdue (due %s), escalating", c.ID, fields.DueAt.Format(time.RFC3339))
+		escalationID, err := w.escalateOverdue(c.ID, c.Title, fields)
+		if err != nil {
+			w.logger("convoy watcher: escalating overdue convoy %s: %v", c.ID, err)
+			continue
+		}
+
+		fields.OverdueEscalation = escalationID
+		updateCmd := exec.Command("bd", "update", c.ID, "--description="+beads.SetConvoyFields(c.Description, fields))
+		updateCmd.Dir = townBeads
+		if out, err := updateCmd.CombinedOutput(); err != nil {
+			w.logger("convoy watcher: recording escalation %s on %s: %v: %s", escalationID, c.ID, err, strings.TrimSpace(string(out)))
+		}
+	}
+}
+
+// escalateOverdue raises a high-severity escalation for an overdue convoy via
+// wt escalate, so it follows the configured routes. Returns the escalation ID.
+func (w *ConvoyWatcher) escalateOverdue(convoyID, title string, fields *beads.ConvoyFields) (string, error) {
+	reason := fmt.Sprintf("Convoy %s was due %s and is still open.", convoyID, fields.DueAt.Format(time.RFC3339))
+	if fields.Owner != "" {
+		reason += " Owner: " + fields.Owner + "."
+	}
+
+	cmd := exec.Command("wt", "escalate", "Convoy overdue: "+title,
+		"--severity", "high",
+		"--reason", reason,
+		"--source", "convoy:"+convoyID,
+		"--related", convoyID,
+		"--json")
+	cmd.Dir = w.townRoot
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Delivery warnings are printed before the JSON object
+	out := stdout.String()
+	if i := strings.LastIndex(out, "\n{"); i >= 0 {
+		out = out[i+1:]
+	}
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil || result.ID == "" {
+		return "", fmt.Errorf("unexpected wt escalate output: %s", strings.TrimSpace(stdout.String()))
+	}
+	return result.ID, nil
+}
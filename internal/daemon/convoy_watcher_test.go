@@ -2,7 +2,14 @@ package daemon
 
 import (
 	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/beadsdb"
 )
 
 func TestBdActivityEventParsing(t *testing.T) {
@@ -87,3 +94,66 @@ func TestIsCloseEvent(t *testing.T) {
 		t.Error("should not detect create as close")
 	}
 }
+
+func TestCheckOverdueConvoys(t *testing.T) {
+	townRoot := t.TempDir()
+	bin := t.TempDir()
+	logDir := t.TempDir()
+	if !beadsdb.Available() {
+		t.Skip("sqlite3 not installed")
+	}
+	if err := os.MkdirAll(filepath.Join(townRoot, ".beads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Three open convoys: one past its deadline, one not yet due, and one
+	// overdue that has already been escalated. The closed one is ignored.
+	fixture := `CREATE TABLE issues (id TEXT PRIMARY KEY, title TEXT, description TEXT, status TEXT,
+  priority INTEGER, issue_type TEXT, assignee TEXT, created_at TEXT, updated_at TEXT, closed_at TEXT);
+INSERT INTO issues (id, title, status, issue_type, created_at, description) VALUES
+  ('hq-cv-late', 'Sprint work', 'open', 'convoy', '2026-01-01T00:00:00Z', 'Convoy tracking 2 issues' || char(10) || 'Owner: mayor/' || char(10) || 'Due: 2026-01-15T00:00:00Z'),
+  ('hq-cv-ok', 'Next sprint', 'open', 'convoy', '2026-01-02T00:00:00Z', 'Convoy tracking 1 issues' || char(10) || 'Due: 2026-02-01T00:00:00Z'),
+  ('hq-cv-done', 'Old work', 'open', 'convoy', '2026-01-03T00:00:00Z', 'Convoy tracking 1 issues' || char(10) || 'Due: 2026-01-01T00:00:00Z' || char(10) || 'Overdue-Escalation: hq-esc-old'),
+  ('hq-cv-shipped', 'Shipped', 'closed', 'convoy', '2025-12-01T00:00:00Z', 'Due: 2026-01-01T00:00:00Z');`
+	dbCmd := exec.Command(beadsdb.Command, filepath.Join(townRoot, ".beads", "beads.db"))
+	dbCmd.Stdin = strings.NewReader(fixture)
+	if out, err := dbCmd.CombinedOutput(); err != nil {
+		t.Fatalf("creating fixture database: %v\n%s", err, out)
+	}
+
+	stubs := map[string]string{
+		"wt": "#!/bin/sh\nprintf '%s\\n' \"$*\" >> " + filepath.Join(logDir, "wt") + "\necho 'Warning: email failed'\necho '{'\necho '  \"id\": \"hq-esc-1\"'\necho '}'\n",
+		"bd": "#!/bin/sh\nprintf '%s\\n' \"$*\" >> " + filepath.Join(logDir, "bd") + "\n",
+	}
+	for name, script := range stubs {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	w := NewConvoyWatcher(townRoot, func(string, ...interface{}) {})
+	w.now = func() time.Time { return time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC) }
+	w.checkOverdueConvoys()
+
+	wtLog, err := os.ReadFile(filepath.Join(logDir, "wt"))
+	if err != nil {
+		t.Fatalf("wt escalate was not run: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(wtLog)), "\n"); len(lines) != 1 {
+		t.Fatalf("escalations = %q, want one", lines)
+	}
+	for _, want := range []string{"escalate Convoy overdue: Sprint work", "--severity high", "--source convoy:hq-cv-late", "--related hq-cv-late"} {
+		if !strings.Contains(string(wtLog), want) {
+			t.Errorf("wt args %q missing %q", wtLog, want)
+		}
+	}
+
+	bdLog, err := os.ReadFile(filepath.Join(logDir, "bd"))
+	if err != nil {
+		t.Fatalf("escalation was not recorded: %v", err)
+	}
+	if !strings.Contains(string(bdLog), "update hq-cv-late") || !strings.Contains(string(bdLog), "Overdue-Escalation: hq-esc-1") {
+		t.Errorf("bd args = %q", bdLog)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gofrs/flock"
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/beads"
 	"github.com/speaker20/whaletown/internal/boot"
 	"github.com/speaker20/whaletown/internal/config"
@@ -40,7 +41,7 @@ import (
 type Daemon struct {
 	config       *Config
 	patrolConfig *DaemonPatrolConfig
-	tmux         *tmux.Tmux
+	sessions     backend.SessionBackend
 	logger       *log.Logger
 	ctx          context.Context
 	cancel       context.CancelFunc
@@ -96,7 +97,7 @@ func New(config *Config) (*Daemon, error) {
 	return &Daemon{
 		config:       config,
 		patrolConfig: patrolConfig,
-		tmux:         tmux.NewTmux(),
+		sessions:     backend.New(config.TownRoot),
 		logger:       logger,
 		ctx:          ctx,
 		cancel:       cancel,
@@ -274,6 +275,18 @@ func (d *Daemon) heartbeat(state *State) {
 	// This is a safety net - Deacon patrol also does this more frequently.
 	d.cleanupOrphanedProcesses()
 
+	// 13. Dispatch plugins whose gates are open (cron, cooldown, condition, event)
+	// Check patrol config - can be disabled in mayor/daemon.json
+	if IsPatrolEnabled(d.patrolConfig, "plugins") {
+		d.dispatchDuePlugins()
+	}
+
+	// 14. Release scheduled mail that is due and archive expired unread mail
+	d.processScheduledMail()
+
+	// 15. Relay the overseer's mail to email and email replies back (if enabled)
+	d.syncEmailGateway()
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -295,7 +308,7 @@ func (d *Daemon) getDeaconSessionName() string {
 // ensureBootRunning spawns Boot to triage the Deacon.
 // Boot is a fresh-each-tick watchdog that decides whether to start/wake/nudge
 // the Deacon, centralizing the "when to wake" decision in an agent.
-// In degraded mode (no tmux), falls back to mechanical checks.
+// In degraded mode (no session backend), falls back to mechanical checks.
 func (d *Daemon) ensureBootRunning() {
 	b := boot.New(d.config.TownRoot)
 
@@ -305,9 +318,10 @@ func (d *Daemon) ensureBootRunning() {
 		return
 	}
 
-	// Check for degraded mode
+	// Check for degraded mode. For the pty backend, IsAvailable also
+	// restarts the supervisor if it has died, so each heartbeat keeps it up.
 	degraded := os.Getenv("WT_DEGRADED") == "true"
-	if degraded || !d.tmux.IsAvailable() {
+	if degraded || !d.sessions.IsAvailable() {
 		// In degraded mode, run mechanical triage directly
 		d.logger.Println("Degraded mode: running mechanical Boot triage")
 		d.runDegradedBootTriage(b)
@@ -336,7 +350,7 @@ func (d *Daemon) runDegradedBootTriage(b *boot.Boot) {
 	}
 
 	// Simple check: is Deacon session alive?
-	hasDeacon, err := d.tmux.HasSession(d.getDeaconSessionName())
+	hasDeacon, err := d.sessions.HasSession(d.getDeaconSessionName())
 	if err != nil {
 		d.logger.Printf("Error checking Deacon session: %v", err)
 		status.LastAction = "error"
@@ -416,7 +430,7 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	sessionName := d.getDeaconSessionName()
 
 	// Check if session exists
-	hasSession, err := d.tmux.HasSession(sessionName)
+	hasSession, err := d.sessions.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking Deacon session: %v", err)
 		return
@@ -432,14 +446,14 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	if age > 30*time.Minute {
 		// Very stuck - restart the session
 		d.logger.Printf("Deacon stuck for %s - restarting session", age.Round(time.Minute))
-		if err := d.tmux.KillSession(sessionName); err != nil {
+		if err := d.sessions.KillSession(sessionName); err != nil {
 			d.logger.Printf("Error killing stuck Deacon: %v", err)
 		}
 		// ensureDeaconRunning will restart on next heartbeat
 	} else {
 		// Stuck but not critically - nudge to wake up
 		d.logger.Printf("Deacon stuck for %s - nudging session", age.Round(time.Minute))
-		if err := d.tmux.NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
+		if err := d.sessions.NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
 			d.logger.Printf("Error nudging stuck Deacon: %v", err)
 		}
 	}
@@ -800,7 +814,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	sessionName := fmt.Sprintf("wt-%s-%s", rigName, polecatName)
 
 	// Check if tmux session exists
-	sessionAlive, err := d.tmux.HasSession(sessionName)
+	sessionAlive, err := d.sessions.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking session %s: %v", sessionName, err)
 		return
@@ -925,7 +939,7 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 
 	// Create new tmux session
 	// Use EnsureSessionFresh to handle zombie sessions that exist but have dead Claude
-	if err := d.tmux.EnsureSessionFresh(sessionName, workDir); err != nil {
+	if err := d.sessions.EnsureSessionFresh(sessionName, workDir); err != nil {
 		return fmt.Errorf("creating session: %w", err)
 	}
 
@@ -940,30 +954,30 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 
 	// Set all env vars in tmux session (for debugging) and they'll also be exported to Claude
 	for k, v := range envVars {
-		_ = d.tmux.SetEnvironment(sessionName, k, v)
+		_ = d.sessions.SetEnvironment(sessionName, k, v)
 	}
 
 	// Apply theme
 	theme := tmux.AssignTheme(rigName)
-	_ = d.tmux.ConfigureWhaleTownSession(sessionName, theme, rigName, polecatName, "polecat")
+	_ = d.sessions.ConfigureWhaleTownSession(sessionName, theme, rigName, polecatName, "polecat")
 
 	// Set pane-died hook for future crash detection
 	agentID := fmt.Sprintf("%s/%s", rigName, polecatName)
-	_ = d.tmux.SetPaneDiedHook(sessionName, agentID)
+	_ = d.sessions.SetPaneDiedHook(sessionName, agentID)
 
 	// Launch Claude with environment exported inline
 	// Pass rigPath so rig agent settings are honored (not town-level defaults)
 	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
-	if err := d.tmux.SendKeys(sessionName, startCmd); err != nil {
+	if err := d.sessions.SendKeys(sessionName, startCmd); err != nil {
 		return fmt.Errorf("sending startup command: %w", err)
 	}
 
 	// Wait for Claude to start, then accept bypass permissions warning if it appears.
 	// This ensures automated restarts aren't blocked by the warning dialog.
-	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
+	if err := d.sessions.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
 		// Non-fatal - Claude might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	_ = d.sessions.AcceptBypassPermissionsWarning(sessionName)
 
 	return nil
 }
@@ -171,7 +171,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 	}
 
 	// Check if session exists (tmux detection still needed for lifecycle actions)
-	running, err := d.tmux.HasSession(sessionName)
+	running, err := d.sessions.HasSession(sessionName)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -179,7 +179,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 	switch request.Action {
 	case ActionShutdown:
 		if running {
-			if err := d.tmux.KillSession(sessionName); err != nil {
+			if err := d.sessions.KillSession(sessionName); err != nil {
 				return fmt.Errorf("killing session: %w", err)
 			}
 			d.logger.Printf("Killed session %s", sessionName)
@@ -189,7 +189,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 	case ActionCycle, ActionRestart:
 		if running {
 			// Kill the session first
-			if err := d.tmux.KillSession(sessionName); err != nil {
+			if err := d.sessions.KillSession(sessionName); err != nil {
 				return fmt.Errorf("killing session: %w", err)
 			}
 			d.logger.Printf("Killed session %s for restart", sessionName)
@@ -367,7 +367,7 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 
 	// Create session
 	// Use EnsureSessionFresh to handle zombie sessions that exist but have dead Claude
-	if err := d.tmux.EnsureSessionFresh(sessionName, workDir); err != nil {
+	if err := d.sessions.EnsureSessionFresh(sessionName, workDir); err != nil {
 		return fmt.Errorf("creating session: %w", err)
 	}
 
@@ -379,22 +379,22 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 
 	// Get and send startup command
 	startCmd := d.getStartCommand(config, parsed)
-	if err := d.tmux.SendKeys(sessionName, startCmd); err != nil {
+	if err := d.sessions.SendKeys(sessionName, startCmd); err != nil {
 		return fmt.Errorf("sending startup command: %w", err)
 	}
 
 	// Wait for Claude to start, then accept bypass permissions warning if it appears.
 	// This ensures automated role starts aren't blocked by the warning dialog.
-	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
+	if err := d.sessions.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
 		// Non-fatal - Claude might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	_ = d.sessions.AcceptBypassPermissionsWarning(sessionName)
 	time.Sleep(constants.ShutdownNotifyDelay)
 
 	// GUPP: Whale Town Universal Propulsion Principle
 	// Send startup nudge for predecessor discovery via /resume
 	recipient := identityToBDActor(identity)
-	_ = session.StartupNudge(d.tmux, sessionName, session.StartupNudgeConfig{
+	_ = session.StartupNudge(d.sessions, sessionName, session.StartupNudgeConfig{
 		Recipient: recipient,
 		Sender:    "deacon",
 		Topic:     "lifecycle-restart",
@@ -403,7 +403,7 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 	// Send propulsion nudge to trigger autonomous execution.
 	// Wait for beacon to be fully processed (needs to be separate prompt)
 	time.Sleep(2 * time.Second)
-	_ = d.tmux.NudgeSession(sessionName, session.PropulsionNudgeForRole(parsed.RoleType, workDir)) // Non-fatal
+	_ = d.sessions.NudgeSession(sessionName, session.PropulsionNudgeForRole(parsed.RoleType, workDir)) // Non-fatal
 
 	return nil
 }
@@ -526,14 +526,14 @@ func (d *Daemon) setSessionEnvironment(sessionName string, roleConfig *beads.Rol
 		TownRoot:  d.config.TownRoot,
 	})
 	for k, v := range envVars {
-		_ = d.tmux.SetEnvironment(sessionName, k, v)
+		_ = d.sessions.SetEnvironment(sessionName, k, v)
 	}
 
 	// Set any custom env vars from role config (bead-defined overrides)
 	if roleConfig != nil {
 		for k, v := range roleConfig.EnvVars {
 			expanded := beads.ExpandRolePattern(v, d.config.TownRoot, parsed.RigName, parsed.AgentName, parsed.RoleType)
-			_ = d.tmux.SetEnvironment(sessionName, k, expanded)
+			_ = d.sessions.SetEnvironment(sessionName, k, expanded)
 		}
 	}
 }
@@ -542,10 +542,10 @@ func (d *Daemon) setSessionEnvironment(sessionName string, roleConfig *beads.Rol
 func (d *Daemon) applySessionTheme(sessionName string, parsed *ParsedIdentity) {
 	if parsed.RoleType == "mayor" {
 		theme := tmux.MayorTheme()
-		_ = d.tmux.ConfigureWhaleTownSession(sessionName, theme, "", "Mayor", "coordinator")
+		_ = d.sessions.ConfigureWhaleTownSession(sessionName, theme, "", "Mayor", "coordinator")
 	} else if parsed.RigName != "" {
 		theme := tmux.AssignTheme(parsed.RigName)
-		_ = d.tmux.ConfigureWhaleTownSession(sessionName, theme, parsed.RigName, parsed.RoleType, parsed.RoleType)
+		_ = d.sessions.ConfigureWhaleTownSession(sessionName, theme, parsed.RigName, parsed.RoleType, parsed.RoleType)
 	}
 }
 
@@ -839,7 +839,7 @@ func (d *Daemon) checkRigGUPPViolations(rigName string) {
 		sessionName := fmt.Sprintf("wt-%s-%s", rigName, polecatName)
 
 		// Check if tmux session exists and Claude is running
-		if d.tmux.IsClaudeRunning(sessionName) {
+		if d.sessions.IsClaudeRunning(sessionName) {
 			// Session is alive - check if it's been stuck too long
 			updatedAt, err := time.Parse(time.RFC3339, agent.UpdatedAt)
 			if err != nil {
@@ -931,7 +931,7 @@ func (d *Daemon) checkRigOrphanedWork(rigName string) {
 		sessionName := fmt.Sprintf("wt-%s-%s", rigName, polecatName)
 
 		// Session running = not orphaned (work is being processed)
-		if d.tmux.IsClaudeRunning(sessionName) {
+		if d.sessions.IsClaudeRunning(sessionName) {
 			continue
 		}
 
@@ -0,0 +1,39 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/mailgw"
+)
+
+// syncEmailGateway emails the overseer's new mail and turns their email
+// replies into mail, when the email gateway is enabled.
+func (d *Daemon) syncEmailGateway() {
+	gw, err := mailgw.New(d.config.TownRoot)
+	if err != nil {
+		if !errors.Is(err, mailgw.ErrDisabled) {
+			d.logger.Printf("Warning: email gateway: %v", err)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	result, err := gw.Sync(ctx)
+	if result != nil {
+		if n := len(result.Sent); n > 0 {
+			d.logger.Printf("Emailed %d message(s) to the overseer: %v", n, result.Sent)
+		}
+		if n := len(result.Replies); n > 0 {
+			d.logger.Printf("Delivered %d email reply(ies) from the overseer to: %v", n, result.Replies)
+		}
+		if n := len(result.Rejected); n > 0 {
+			d.logger.Printf("Rejected %d email(s): %v", n, result.Rejected)
+		}
+	}
+	if err != nil {
+		d.logger.Printf("Warning: %v", err)
+	}
+}
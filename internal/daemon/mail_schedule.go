@@ -0,0 +1,28 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/speaker20/whaletown/internal/mail"
+)
+
+// processScheduledMail releases scheduled mail that has fallen due,
+// notifying each recipient, and archives unread mail that has expired.
+func (d *Daemon) processScheduledMail() {
+	router := mail.NewRouterWithTownRoot(d.config.TownRoot, d.config.TownRoot)
+	result, err := router.ProcessScheduled(time.Now())
+	if result != nil {
+		if n := len(result.Released); n > 0 {
+			d.logger.Printf("Released %d scheduled message(s): %v", n, result.Released)
+		}
+		if n := len(result.Dropped); n > 0 {
+			d.logger.Printf("Dropped %d reminder(s) for closed beads: %v", n, result.Dropped)
+		}
+		if n := len(result.Expired); n > 0 {
+			d.logger.Printf("Archived %d expired unread message(s): %v", n, result.Expired)
+		}
+	}
+	if err != nil {
+		d.logger.Printf("Warning: scheduled mail pass: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"bytes"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/constants"
+	"github.com/speaker20/whaletown/internal/dog"
+	"github.com/speaker20/whaletown/internal/plugin"
+)
+
+// dispatchDuePlugins evaluates every plugin's gate and dispatches the due
+// ones to dogs via wt dog dispatch. A plugin a dog is still working on is
+// not dispatched again; its run is recorded when the dog finishes, which
+// closes the gate until it next fires.
+func (d *Daemon) dispatchDuePlugins() {
+	rigNames := d.getKnownRigs()
+	sort.Strings(rigNames)
+
+	plugins, err := plugin.NewScanner(d.config.TownRoot, rigNames).DiscoverAll()
+	if err != nil {
+		d.logger.Printf("Plugin scan failed: %v", err)
+		return
+	}
+	if len(plugins) == 0 {
+		return
+	}
+
+	inFlight := d.pluginsInFlight()
+	evaluator := plugin.NewEvaluator(d.config.TownRoot)
+
+	for _, p := range plugins {
+		if p.Gate == nil || p.Gate.Type == plugin.GateManual {
+			continue
+		}
+		if inFlight["plugin:"+p.Name] {
+			continue
+		}
+
+		status, err := evaluator.Evaluate(p)
+		if err != nil {
+			d.logger.Printf("Plugin %s: gate evaluation failed: %v", p.Name, err)
+			continue
+		}
+		if !status.Due {
+			continue
+		}
+
+		d.logger.Printf("Plugin %s due (%s), dispatching to a dog", p.Name, status.Reason)
+		args := []string{"dog", "dispatch", "--plugin", p.Name, "--create"}
+		if p.RigName != "" {
+			args = append(args, "--rig", p.RigName)
+		}
+		cmd := exec.Command("wt", args...) //nolint:gosec // G204: args are constructed internally
+		cmd.Dir = d.config.TownRoot
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			d.logger.Printf("Plugin %s: dispatch failed: %v: %s", p.Name, err, strings.TrimSpace(stderr.String()))
+			continue
+		}
+		inFlight["plugin:"+p.Name] = true
+	}
+}
+
+// pluginsInFlight returns the work assignments of dogs that are working.
+func (d *Daemon) pluginsInFlight() map[string]bool {
+	inFlight := make(map[string]bool)
+
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(d.config.TownRoot))
+	if err != nil {
+		rigsConfig = &config.RigsConfig{Rigs: make(map[string]config.RigEntry)}
+	}
+	dogs, err := dog.NewManager(d.config.TownRoot, rigsConfig).List()
+	if err != nil {
+		return inFlight
+	}
+	for _, dg := range dogs {
+		if dg.State == dog.StateWorking && dg.Work != "" {
+			inFlight[dg.Work] = true
+		}
+	}
+	return inFlight
+}
@@ -113,6 +113,7 @@ type PatrolsConfig struct {
 	Refinery *PatrolConfig `json:"refinery,omitempty"`
 	Witness  *PatrolConfig `json:"witness,omitempty"`
 	Deacon   *PatrolConfig `json:"deacon,omitempty"`
+	Plugins  *PatrolConfig `json:"plugins,omitempty"`
 }
 
 // DaemonPatrolConfig is the structure of mayor/daemon.json.
@@ -164,6 +165,10 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 		if config.Patrols.Deacon != nil {
 			return config.Patrols.Deacon.Enabled
 		}
+	case "plugins":
+		if config.Patrols.Plugins != nil {
+			return config.Patrols.Plugins.Enabled
+		}
 	}
 	return true // Default: enabled
 }
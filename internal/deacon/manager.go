@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/claude"
 	"github.com/speaker20/whaletown/internal/config"
 	"github.com/speaker20/whaletown/internal/constants"
@@ -52,7 +53,7 @@ func (m *Manager) deaconDir() string {
 // agentOverride allows specifying an alternate agent alias (e.g., for testing).
 // Restarts are handled by daemon via ensureDeaconRunning on each heartbeat.
 func (m *Manager) Start(agentOverride string) error {
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot)
 	sessionID := m.SessionName()
 
 	// Check if session already exists
@@ -136,7 +137,7 @@ func (m *Manager) Start(agentOverride string) error {
 
 // Stop stops the deacon session.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot)
 	sessionID := m.SessionName()
 
 	// Check if session exists
@@ -162,13 +163,13 @@ func (m *Manager) Stop() error {
 
 // IsRunning checks if the deacon session is active.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot)
 	return t.HasSession(m.SessionName())
 }
 
 // Status returns information about the deacon session.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot)
 	sessionID := m.SessionName()
 
 	running, err := t.HasSession(sessionID)
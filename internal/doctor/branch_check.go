@@ -115,6 +115,17 @@ func (c *BranchCheck) Fix(ctx *CheckContext) error {
 	return lastErr
 }
 
+// FixTargets returns the main branch and HEAD of each clone Fix switches
+// to main and pulls. HEAD comes last so undo checks out the old branch
+// before moving main back.
+func (c *BranchCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	targets := make([]FixTarget, 0, 2*len(c.offMainDirs))
+	for _, dir := range c.offMainDirs {
+		targets = append(targets, RefTarget(dir, "refs/heads/main"), RefTarget(dir, "HEAD"))
+	}
+	return targets
+}
+
 // findPersistentRoleDirs finds all directories that should be on main:
 // - <rig>/crew/*
 // - <rig>/witness/rig (if exists)
@@ -0,0 +1,169 @@
+package doctor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// maxDiffCells bounds the line-matching table; files whose line counts
+// multiply past it are shown as a whole-file replacement.
+const maxDiffCells = 4_000_000
+
+// diffLine is one line of a diff: ' ' unchanged, '-' removed, '+' added.
+type diffLine struct {
+	kind byte
+	text string // including its newline, if it has one
+}
+
+// unifiedDiff returns a unified diff turning before into after, labelled
+// with name. existedBefore and existsAfter mark file creation and
+// deletion, which diff against /dev/null. It returns "" when nothing
+// changed.
+func unifiedDiff(name string, before, after []byte, existedBefore, existsAfter bool) string {
+	if existedBefore == existsAfter && bytes.Equal(before, after) {
+		return ""
+	}
+
+	from, to := "a/"+name, "b/"+name
+	if !existedBefore {
+		from = "/dev/null"
+	}
+	if !existsAfter {
+		to = "/dev/null"
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	// Line numbers in before and after at each diff line
+	aLine := make([]int, len(lines))
+	bLine := make([]int, len(lines))
+	a, b := 1, 1
+	for i, l := range lines {
+		aLine[i], bLine[i] = a, b
+		if l.kind != '+' {
+			a++
+		}
+		if l.kind != '-' {
+			b++
+		}
+	}
+
+	// Show every change with its context; overlapping context merges hunks
+	show := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.kind == ' ' {
+			continue
+		}
+		for j := max(0, i-diffContext); j <= min(len(lines)-1, i+diffContext); j++ {
+			show[j] = true
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		if !show[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(lines) && show[i] {
+			i++
+		}
+		hunk := lines[start:i]
+
+		var aCount, bCount int
+		for _, l := range hunk {
+			if l.kind != '+' {
+				aCount++
+			}
+			if l.kind != '-' {
+				bCount++
+			}
+		}
+		aStart, bStart := aLine[start], bLine[start]
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, l := range hunk {
+			out.WriteByte(l.kind)
+			if strings.HasSuffix(l.text, "\n") {
+				out.WriteString(l.text)
+			} else {
+				out.WriteString(l.text + "\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return out.String()
+}
+
+// splitLines splits data into lines, each keeping its newline.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines matches a against b by longest common subsequence.
+func diffLines(a, b []string) []diffLine {
+	if len(a)*len(b) > maxDiffCells {
+		lines := make([]diffLine, 0, len(a)+len(b))
+		for _, l := range a {
+			lines = append(lines, diffLine{'-', l})
+		}
+		for _, l := range b {
+			lines = append(lines, diffLine{'+', l})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the common subsequence length of a[i:] and b[j:]
+	cols := len(b) + 1
+	lcs := make([]int, (len(a)+1)*cols)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*cols+j] = lcs[(i+1)*cols+j+1] + 1
+			} else {
+				lcs[i*cols+j] = max(lcs[(i+1)*cols+j], lcs[i*cols+j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[(i+1)*cols+j] >= lcs[i*cols+j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
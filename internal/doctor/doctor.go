@@ -1,5 +1,11 @@
 package doctor
 
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
 // Doctor manages and executes health checks.
 type Doctor struct {
 	checks []Check
@@ -37,49 +43,46 @@ func (d *Doctor) Run(ctx *CheckContext) *Report {
 	report := NewReport()
 
 	for _, check := range d.checks {
-		result := check.Run(ctx)
-		// Ensure check name is populated
-		if result.Name == "" {
-			result.Name = check.Name()
-		}
-		// Set category from check if available
-		if cg, ok := check.(categoryGetter); ok && result.Category == "" {
-			result.Category = cg.Category()
-		}
-		report.Add(result)
+		report.Add(runCheck(ctx, check))
 	}
 
 	return report
 }
 
+// runCheck runs a check, filling in its name and category.
+func runCheck(ctx *CheckContext, check Check) *CheckResult {
+	result := check.Run(ctx)
+	// Ensure check name is populated
+	if result.Name == "" {
+		result.Name = check.Name()
+	}
+	// Set category from check if available
+	if cg, ok := check.(categoryGetter); ok && result.Category == "" {
+		result.Category = cg.Category()
+	}
+	return result
+}
+
 // Fix runs all checks with auto-fix enabled where possible.
 // It first runs the check, then if it fails and can be fixed, attempts the fix.
+// When ctx.Journal is set, each fix is recorded in it.
 func (d *Doctor) Fix(ctx *CheckContext) *Report {
 	report := NewReport()
 
 	for _, check := range d.checks {
-		result := check.Run(ctx)
-		if result.Name == "" {
-			result.Name = check.Name()
-		}
-		// Set category from check if available
-		if cg, ok := check.(categoryGetter); ok && result.Category == "" {
-			result.Category = cg.Category()
-		}
+		result := runCheck(ctx, check)
 
 		// Attempt fix if check failed and is fixable
 		if result.Status != StatusOK && check.CanFix() {
-			err := check.Fix(ctx)
+			var err error
+			if ctx.Journal != nil {
+				err = ctx.Journal.Fix(ctx, check)
+			} else {
+				err = check.Fix(ctx)
+			}
 			if err == nil {
 				// Re-run check to verify fix worked
-				result = check.Run(ctx)
-				if result.Name == "" {
-					result.Name = check.Name()
-				}
-				// Set category again after re-run
-				if cg, ok := check.(categoryGetter); ok && result.Category == "" {
-					result.Category = cg.Category()
-				}
+				result = runCheck(ctx, check)
 				// Update message to indicate fix was applied
 				if result.Status == StatusOK {
 					result.Message = result.Message + " (fixed)"
@@ -96,6 +99,88 @@ func (d *Doctor) Fix(ctx *CheckContext) *Report {
 	return report
 }
 
+// FixPreview is what one check's fix would change, from a dry run.
+type FixPreview struct {
+	Check string
+	Diff  string      // Unified diff of the file changes
+	Refs  []RefChange // Refs the fix would move, with their current values in Before
+	Note  string      // Why the fix wasn't previewed, if it wasn't
+	Err   error       // The fix's error in the dry run
+}
+
+// Preview runs all checks and previews the fixes --fix would apply.
+//
+// Only fixes that declare their targets (FixTargeter) and change nothing
+// but files are run: each runs against the real files, its changes are
+// diffed, and the files are put back. Fixes that move git refs list the
+// refs instead, and other fixes are listed without a preview.
+func (d *Doctor) Preview(ctx *CheckContext) (*Report, []*FixPreview) {
+	report := NewReport()
+	var previews []*FixPreview
+
+	// A dry run never restarts sessions
+	dryCtx := *ctx
+	dryCtx.RestartSessions = false
+	dryCtx.Journal = nil
+
+	for _, check := range d.checks {
+		result := runCheck(&dryCtx, check)
+		if result.Status != StatusOK && check.CanFix() {
+			previews = append(previews, previewFix(&dryCtx, check))
+		}
+		report.Add(result)
+	}
+
+	return report, previews
+}
+
+// previewFix runs a file-only fix and reverts it, returning its diff.
+func previewFix(ctx *CheckContext, check Check) *FixPreview {
+	p := &FixPreview{Check: check.Name()}
+	targeter, ok := check.(FixTargeter)
+	if !ok {
+		p.Note = "fix doesn't declare what it changes, so it can't be previewed"
+		return p
+	}
+
+	var files []fileState
+	for _, t := range targeter.FixTargets(ctx) {
+		if t.Ref != "" {
+			p.Refs = append(p.Refs, RefChange{Repo: t.Repo, Ref: t.Ref, Before: readRef(t.Repo, t.Ref)})
+		} else {
+			files = append(files, readFileState(t.Path))
+		}
+	}
+	if len(p.Refs) > 0 {
+		p.Note = "fix moves git refs, so it isn't run in a dry run"
+		return p
+	}
+
+	p.Err = check.Fix(ctx)
+
+	var diff strings.Builder
+	for _, before := range files {
+		after := readFileState(before.path)
+		if before.equal(after) {
+			continue
+		}
+		diff.WriteString(unifiedDiff(displayPath(ctx.TownRoot, before.path), before.data, after.data, before.exists, after.exists))
+		if err := before.restore(); err != nil && p.Err == nil {
+			p.Err = fmt.Errorf("restoring %s after dry run: %w", before.path, err)
+		}
+	}
+	p.Diff = diff.String()
+	return p
+}
+
+// displayPath returns path relative to the town root when it is inside it.
+func displayPath(townRoot, path string) string {
+	if rel, err := filepath.Rel(townRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
 // BaseCheck provides a base implementation for checks that don't support auto-fix.
 // Embed this in custom checks to get default CanFix() and Fix() implementations.
 type BaseCheck struct {
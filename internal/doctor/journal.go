@@ -0,0 +1,429 @@
+package doctor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/constants"
+)
+
+// FixTarget is a file or git ref that a check's Fix may change.
+type FixTarget struct {
+	Path string // File path, for file targets
+	Repo string // Repository directory, for ref targets
+	Ref  string // Ref name, e.g. HEAD or refs/heads/main
+}
+
+// FileTarget returns a target for the file at path.
+func FileTarget(path string) FixTarget {
+	return FixTarget{Path: path}
+}
+
+// RefTarget returns a target for a git ref in the repository at repo.
+func RefTarget(repo, ref string) FixTarget {
+	return FixTarget{Repo: repo, Ref: ref}
+}
+
+// FixTargeter is implemented by fixable checks that can list, after Run,
+// everything their Fix will change. Their fixes are snapshotted in the fix
+// journal so they can be previewed with --dry-run and reverted with
+// wt doctor undo. Fixes of other checks are recorded as untracked.
+type FixTargeter interface {
+	FixTargets(ctx *CheckContext) []FixTarget
+}
+
+// ErrJournalDrift is returned by Undo when something a fix changed has
+// changed again since.
+var ErrJournalDrift = errors.New("changed since the fix")
+
+// FixJournal records what a wt doctor --fix run changed, with snapshots of
+// the files as they were before, under .runtime/doctor/fixes/<id>/.
+type FixJournal struct {
+	ID       string      `json:"id"`
+	TownRoot string      `json:"town_root"`
+	Started  time.Time   `json:"started"`
+	Entries  []*FixEntry `json:"entries"`
+	UndoneAt *time.Time  `json:"undone_at,omitempty"`
+
+	dir   string
+	saved int // snapshots written so far
+}
+
+// FixEntry is one check's fix.
+type FixEntry struct {
+	Check     string       `json:"check"`
+	Files     []FileChange `json:"files,omitempty"`
+	Refs      []RefChange  `json:"refs,omitempty"`
+	Untracked bool         `json:"untracked,omitempty"` // the check doesn't declare its targets
+	Error     string       `json:"error,omitempty"`
+}
+
+// FileChange is a file a fix created, changed or removed.
+type FileChange struct {
+	Path        string      `json:"path"`
+	Existed     bool        `json:"existed"`
+	Mode        os.FileMode `json:"mode,omitempty"`
+	Snapshot    string      `json:"snapshot,omitempty"` // copy of the old content, relative to the run directory
+	After       string      `json:"after"`              // sha256 of the new content, "" when the fix removed it
+	CreatedDirs []string    `json:"created_dirs,omitempty"`
+}
+
+// RefChange is a git ref a fix moved. Values are commit IDs, "ref: <name>"
+// for a symbolic HEAD, or "" when the ref doesn't exist.
+type RefChange struct {
+	Repo   string `json:"repo"`
+	Ref    string `json:"ref"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// FixJournalDir returns the directory fix journals are kept in.
+func FixJournalDir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "doctor", "fixes")
+}
+
+// NewFixJournal starts a journal for a fix run. Its ID is the start time,
+// suffixed when another run started in the same second. Nothing is
+// written until the first fix is recorded.
+func NewFixJournal(townRoot string) *FixJournal {
+	now := time.Now()
+	base := now.Format("20060102-150405")
+	id := base
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(FixJournalDir(townRoot), id)); os.IsNotExist(err) {
+			break
+		}
+		id = base + "-" + strconv.Itoa(n)
+	}
+	return &FixJournal{ID: id, TownRoot: townRoot, Started: now, dir: filepath.Join(FixJournalDir(townRoot), id)}
+}
+
+// LoadFixJournal reads the journal of run id.
+func LoadFixJournal(townRoot, id string) (*FixJournal, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return nil, fmt.Errorf("invalid fix run ID %q", id)
+	}
+	dir := filepath.Join(FixJournalDir(townRoot), id)
+	data, err := os.ReadFile(filepath.Join(dir, "journal.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no fix run %q", id)
+		}
+		return nil, err
+	}
+	var j FixJournal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("parsing fix journal %s: %w", id, err)
+	}
+	j.dir = dir
+	return &j, nil
+}
+
+// ListFixJournals returns the town's fix journals, newest first.
+func ListFixJournals(townRoot string) ([]*FixJournal, error) {
+	entries, err := os.ReadDir(FixJournalDir(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var journals []*FixJournal
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if j, err := LoadFixJournal(townRoot, e.Name()); err == nil {
+			journals = append(journals, j)
+		}
+	}
+	sort.Slice(journals, func(a, b int) bool {
+		return journals[a].Started.After(journals[b].Started)
+	})
+	return journals, nil
+}
+
+// Dir returns the journal's directory.
+func (j *FixJournal) Dir() string {
+	return j.dir
+}
+
+// Empty reports whether the run fixed nothing.
+func (j *FixJournal) Empty() bool {
+	return len(j.Entries) == 0
+}
+
+// Fix runs check's Fix, recording the files and refs it declares with
+// their state before and after.
+func (j *FixJournal) Fix(ctx *CheckContext, check Check) error {
+	entry := &FixEntry{Check: check.Name()}
+	targeter, ok := check.(FixTargeter)
+	if !ok {
+		entry.Untracked = true
+		err := check.Fix(ctx)
+		j.record(entry, err)
+		return err
+	}
+
+	targets := targeter.FixTargets(ctx)
+	files := make([]fileState, 0, len(targets))
+	refs := make([]RefChange, 0, len(targets))
+	for _, t := range targets {
+		if t.Ref != "" {
+			refs = append(refs, RefChange{Repo: t.Repo, Ref: t.Ref, Before: readRef(t.Repo, t.Ref)})
+		} else {
+			files = append(files, readFileState(t.Path))
+		}
+	}
+
+	fixErr := check.Fix(ctx)
+
+	for _, before := range files {
+		after := readFileState(before.path)
+		if before.equal(after) {
+			continue
+		}
+		change := FileChange{
+			Path:        before.path,
+			Existed:     before.exists,
+			Mode:        before.mode,
+			After:       after.sum(),
+			CreatedDirs: before.missingDirs,
+		}
+		if before.exists {
+			j.saved++
+			change.Snapshot = filepath.Join("snapshots", strconv.Itoa(j.saved))
+			if err := writeSnapshot(filepath.Join(j.dir, change.Snapshot), before.data); err != nil {
+				return fmt.Errorf("saving snapshot of %s: %w", before.path, err)
+			}
+		}
+		entry.Files = append(entry.Files, change)
+	}
+	for _, r := range refs {
+		r.After = readRef(r.Repo, r.Ref)
+		if r.After != r.Before {
+			entry.Refs = append(entry.Refs, r)
+		}
+	}
+
+	j.record(entry, fixErr)
+	return fixErr
+}
+
+// record adds entry and saves the journal, so a run that dies part way
+// still leaves a journal of what it changed.
+func (j *FixJournal) record(entry *FixEntry, fixErr error) {
+	if fixErr != nil {
+		entry.Error = fixErr.Error()
+	}
+	j.Entries = append(j.Entries, entry)
+	_ = j.Save()
+}
+
+// Save writes the journal.
+func (j *FixJournal) Save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return fmt.Errorf("creating fix journal: %w", err)
+	}
+	return os.WriteFile(filepath.Join(j.dir, "journal.json"), data, 0644)
+}
+
+// UndoResult reports what Undo did.
+type UndoResult struct {
+	Restored []string // files and refs put back
+	Skipped  []string // fixes that weren't journaled and can't be undone
+}
+
+// Undo restores what the run changed, newest change first. Unless force
+// is set it refuses, with ErrJournalDrift, when anything the run changed
+// has been changed again since.
+func (j *FixJournal) Undo(force bool) (*UndoResult, error) {
+	if j.UndoneAt != nil {
+		return nil, fmt.Errorf("fix run %s was already undone at %s", j.ID, j.UndoneAt.Format(time.RFC3339))
+	}
+
+	if !force {
+		var drifted []string
+		for _, e := range j.Entries {
+			for _, f := range e.Files {
+				if readFileState(f.Path).sum() != f.After {
+					drifted = append(drifted, f.Path)
+				}
+			}
+			for _, r := range e.Refs {
+				if readRef(r.Repo, r.Ref) != r.After {
+					drifted = append(drifted, r.Repo+" "+r.Ref)
+				}
+			}
+		}
+		if len(drifted) > 0 {
+			return nil, fmt.Errorf("%w: %s (use --force to restore anyway)", ErrJournalDrift, strings.Join(drifted, ", "))
+		}
+	}
+
+	result := &UndoResult{}
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		e := j.Entries[i]
+		if e.Untracked {
+			result.Skipped = append(result.Skipped, e.Check)
+			continue
+		}
+		for k := len(e.Refs) - 1; k >= 0; k-- {
+			r := e.Refs[k]
+			if err := restoreRef(r.Repo, r.Ref, r.Before); err != nil {
+				return result, fmt.Errorf("restoring %s in %s: %w", r.Ref, r.Repo, err)
+			}
+			result.Restored = append(result.Restored, r.Repo+" "+r.Ref)
+		}
+		for k := len(e.Files) - 1; k >= 0; k-- {
+			f := e.Files[k]
+			if err := j.restoreFile(f); err != nil {
+				return result, fmt.Errorf("restoring %s: %w", f.Path, err)
+			}
+			result.Restored = append(result.Restored, f.Path)
+		}
+	}
+
+	now := time.Now()
+	j.UndoneAt = &now
+	return result, j.Save()
+}
+
+// restoreFile puts a file back as it was before the fix.
+func (j *FixJournal) restoreFile(f FileChange) error {
+	s := fileState{path: f.Path, exists: f.Existed, mode: f.Mode, missingDirs: f.CreatedDirs}
+	if f.Existed {
+		data, err := os.ReadFile(filepath.Join(j.dir, f.Snapshot))
+		if err != nil {
+			return fmt.Errorf("reading snapshot: %w", err)
+		}
+		s.data = data
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			return err
+		}
+	}
+	return s.restore()
+}
+
+// fileState is a file's content at one point in a fix.
+type fileState struct {
+	path        string
+	exists      bool
+	mode        os.FileMode
+	data        []byte
+	missingDirs []string // parent directories that don't exist, deepest first
+}
+
+func readFileState(path string) fileState {
+	s := fileState{path: path}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			if _, err := os.Stat(dir); err == nil || dir == filepath.Dir(dir) {
+				break
+			}
+			s.missingDirs = append(s.missingDirs, dir)
+		}
+		return s
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	s.exists, s.mode, s.data = true, info.Mode(), data
+	return s
+}
+
+func (s fileState) equal(o fileState) bool {
+	return s.exists == o.exists && s.mode == o.mode && bytes.Equal(s.data, o.data)
+}
+
+// sum returns the sha256 of the content, or "" when the file is absent.
+func (s fileState) sum() string {
+	if !s.exists {
+		return ""
+	}
+	h := sha256.Sum256(s.data)
+	return hex.EncodeToString(h[:])
+}
+
+// restore puts the file back to this state.
+func (s fileState) restore() error {
+	if !s.exists {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		removeEmptyDirs(s.missingDirs)
+		return nil
+	}
+	if err := os.WriteFile(s.path, s.data, s.mode.Perm()); err != nil {
+		return err
+	}
+	return os.Chmod(s.path, s.mode.Perm())
+}
+
+// removeEmptyDirs removes directories a fix created, deepest first,
+// leaving any that now hold something else.
+func removeEmptyDirs(dirs []string) {
+	for _, dir := range dirs {
+		_ = os.Remove(dir)
+	}
+}
+
+func writeSnapshot(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// readRef returns a ref's value: a commit ID, "ref: <name>" for a
+// symbolic HEAD, or "" when it doesn't exist.
+func readRef(repo, ref string) string {
+	if ref == "HEAD" {
+		if out, err := exec.Command("git", "-C", repo, "symbolic-ref", "-q", "HEAD").Output(); err == nil {
+			return "ref: " + strings.TrimSpace(string(out))
+		}
+	}
+	out, err := exec.Command("git", "-C", repo, "rev-parse", "--verify", "-q", ref).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// restoreRef sets a ref back to value. HEAD is restored by checking out
+// the branch or commit it pointed at.
+func restoreRef(repo, ref, value string) error {
+	var args []string
+	switch {
+	case ref == "HEAD" && strings.HasPrefix(value, "ref: "):
+		args = []string{"checkout", strings.TrimPrefix(strings.TrimPrefix(value, "ref: "), "refs/heads/")}
+	case ref == "HEAD":
+		args = []string{"checkout", "--detach", value}
+	case value == "":
+		args = []string{"update-ref", "-d", ref}
+	default:
+		args = []string{"update-ref", ref, value}
+	}
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
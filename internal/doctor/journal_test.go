@@ -0,0 +1,264 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fileFixCheck is a fixable check whose Fix writes and removes files.
+type fileFixCheck struct {
+	FixableCheck
+	write  map[string]string
+	remove []string
+	fixed  bool
+}
+
+func newFileFixCheck(name string) *fileFixCheck {
+	return &fileFixCheck{
+		FixableCheck: FixableCheck{BaseCheck: BaseCheck{CheckName: name}},
+		write:        make(map[string]string),
+	}
+}
+
+func (c *fileFixCheck) Run(ctx *CheckContext) *CheckResult {
+	if c.fixed {
+		return &CheckResult{Name: c.Name(), Status: StatusOK}
+	}
+	return &CheckResult{Name: c.Name(), Status: StatusWarning}
+}
+
+func (c *fileFixCheck) Fix(ctx *CheckContext) error {
+	for path, content := range c.write {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	for _, path := range c.remove {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	c.fixed = true
+	return nil
+}
+
+func (c *fileFixCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	var targets []FixTarget
+	for path := range c.write {
+		targets = append(targets, FileTarget(path))
+	}
+	for _, path := range c.remove {
+		targets = append(targets, FileTarget(path))
+	}
+	return targets
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	after := "a\nb\nc\nD\ne\nf\ng\nh\ni\n"
+	want := `--- a/cfg.txt
++++ b/cfg.txt
+@@ -1,8 +1,9 @@
+ a
+ b
+ c
+-d
++D
+ e
+ f
+ g
+ h
++i
+`
+	if got := unifiedDiff("cfg.txt", []byte(before), []byte(after), true, true); got != want {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	created := unifiedDiff("new.txt", nil, []byte("x"), false, true)
+	if !strings.HasPrefix(created, "--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1,1 @@\n+x\n\\ No newline at end of file\n") {
+		t.Errorf("created file diff =\n%s", created)
+	}
+	if got := unifiedDiff("same.txt", []byte("x\n"), []byte("x\n"), true, true); got != "" {
+		t.Errorf("unchanged diff = %q, want empty", got)
+	}
+}
+
+func TestFixJournal_FixAndUndo(t *testing.T) {
+	town := t.TempDir()
+	config := filepath.Join(town, "mayor", "rigs.json")
+	stale := filepath.Join(town, "rig", ".beads", "routes.jsonl")
+	hook := filepath.Join(town, ".git", "hooks", "pre-checkout")
+	writeTestFile(t, config, `{"rigs":{"gone":{}}}`)
+	writeTestFile(t, stale, "{}\n")
+
+	check := newFileFixCheck("files")
+	check.write[config] = `{"rigs":{}}`
+	check.write[hook] = "#!/bin/sh\n"
+	check.remove = []string{stale}
+
+	untracked := newMockCheck("untracked", StatusWarning)
+	untracked.fixable = true
+
+	ctx := &CheckContext{TownRoot: town, Journal: NewFixJournal(town)}
+	d := NewDoctor()
+	d.RegisterAll(check, untracked)
+	report := d.Fix(ctx)
+	if report.Summary.OK != 2 {
+		t.Fatalf("fix report = %+v", report.Summary)
+	}
+
+	j, err := LoadFixJournal(town, ctx.Journal.ID)
+	if err != nil {
+		t.Fatalf("LoadFixJournal() error = %v", err)
+	}
+	if len(j.Entries) != 2 || len(j.Entries[0].Files) != 3 || !j.Entries[1].Untracked {
+		t.Fatalf("journal entries = %+v", j.Entries)
+	}
+
+	result, err := j.Undo(false)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if len(result.Restored) != 3 || len(result.Skipped) != 1 || result.Skipped[0] != "untracked" {
+		t.Errorf("undo result = %+v", result)
+	}
+	if got := readTestFile(t, config); got != `{"rigs":{"gone":{}}}` {
+		t.Errorf("rigs.json = %q, want the original", got)
+	}
+	if got := readTestFile(t, stale); got != "{}\n" {
+		t.Errorf("removed file = %q, want it restored", got)
+	}
+	if _, err := os.Stat(filepath.Join(town, ".git")); !os.IsNotExist(err) {
+		t.Errorf("created hook and its directories should be removed, stat err = %v", err)
+	}
+
+	if _, err := j.Undo(false); err == nil {
+		t.Error("second Undo(): want error")
+	}
+}
+
+func TestFixJournal_UndoRefusesDrift(t *testing.T) {
+	town := t.TempDir()
+	config := filepath.Join(town, "mayor", "rigs.json")
+	writeTestFile(t, config, "old")
+
+	check := newFileFixCheck("files")
+	check.write[config] = "fixed"
+	j := NewFixJournal(town)
+	if err := j.Fix(&CheckContext{TownRoot: town}, check); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFile(t, config, "edited by hand")
+	if _, err := j.Undo(false); !errors.Is(err, ErrJournalDrift) {
+		t.Fatalf("Undo() error = %v, want ErrJournalDrift", err)
+	}
+	if got := readTestFile(t, config); got != "edited by hand" {
+		t.Errorf("refused undo changed the file: %q", got)
+	}
+
+	if _, err := j.Undo(true); err != nil {
+		t.Fatalf("Undo(force) error = %v", err)
+	}
+	if got := readTestFile(t, config); got != "old" {
+		t.Errorf("forced undo: file = %q, want old", got)
+	}
+}
+
+func TestDoctorPreview_RevertsFiles(t *testing.T) {
+	town := t.TempDir()
+	config := filepath.Join(town, "mayor", "rigs.json")
+	writeTestFile(t, config, "one\ntwo\n")
+
+	check := newFileFixCheck("files")
+	check.write[config] = "one\nthree\n"
+	other := newMockCheck("other", StatusWarning)
+	other.fixable = true
+
+	d := NewDoctor()
+	d.RegisterAll(check, other)
+	report, previews := d.Preview(&CheckContext{TownRoot: town})
+
+	if report.Summary.Warnings != 2 || len(previews) != 2 {
+		t.Fatalf("report = %+v, previews = %d", report.Summary, len(previews))
+	}
+	if want := "--- a/mayor/rigs.json\n+++ b/mayor/rigs.json\n@@ -1,2 +1,2 @@\n one\n-two\n+three\n"; previews[0].Diff != want {
+		t.Errorf("diff =\n%s\nwant\n%s", previews[0].Diff, want)
+	}
+	if got := readTestFile(t, config); got != "one\ntwo\n" {
+		t.Errorf("dry run left %q", got)
+	}
+	if previews[1].Note == "" || other.fixCount != 0 {
+		t.Errorf("undeclared fix: note %q, ran %d times", previews[1].Note, other.fixCount)
+	}
+	if journals, _ := ListFixJournals(town); len(journals) != 0 {
+		t.Errorf("dry run wrote %d journals", len(journals))
+	}
+}
+
+func TestFixJournal_RefUndo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	town := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", town}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	git("checkout", "-q", "-b", "stray")
+
+	check := NewTownRootBranchCheck()
+	ctx := &CheckContext{TownRoot: town}
+	if result := check.Run(ctx); result.Status != StatusError {
+		t.Fatalf("Run() = %+v", result)
+	}
+	j := NewFixJournal(town)
+	if err := j.Fix(ctx, check); err != nil {
+		t.Fatalf("Fix() error = %v", err)
+	}
+	if len(j.Entries[0].Refs) != 1 || j.Entries[0].Refs[0].Before != "ref: refs/heads/stray" {
+		t.Fatalf("refs = %+v", j.Entries[0].Refs)
+	}
+	if got := readRef(town, "HEAD"); got != "ref: refs/heads/main" {
+		t.Fatalf("after fix HEAD = %q", got)
+	}
+
+	if _, err := j.Undo(false); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if got := readRef(town, "HEAD"); got != "ref: refs/heads/stray" {
+		t.Errorf("after undo HEAD = %q, want stray", got)
+	}
+}
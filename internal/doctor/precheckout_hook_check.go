@@ -160,3 +160,8 @@ func (c *PreCheckoutHookCheck) Fix(ctx *CheckContext) error {
 
 	return nil
 }
+
+// FixTargets returns the hook file Fix installs.
+func (c *PreCheckoutHookCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	return []FixTarget{FileTarget(filepath.Join(ctx.TownRoot, ".git", "hooks", "pre-checkout"))}
+}
@@ -237,6 +237,14 @@ func (c *GitExcludeConfiguredCheck) Fix(ctx *CheckContext) error {
 	return nil
 }
 
+// FixTargets returns the exclude file Fix appends to.
+func (c *GitExcludeConfiguredCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	if c.excludePath == "" {
+		return nil
+	}
+	return []FixTarget{FileTarget(c.excludePath)}
+}
+
 // HooksPathConfiguredCheck verifies all clones have core.hooksPath set to .githooks.
 // This ensures the pre-push hook blocks pushes to invalid branches (no internal PRs).
 type HooksPathConfiguredCheck struct {
@@ -1198,6 +1206,14 @@ func (c *BareRepoRefspecCheck) Fix(ctx *CheckContext) error {
 	return nil
 }
 
+// FixTargets returns the bare repo config Fix edits.
+func (c *BareRepoRefspecCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	if ctx.RigName == "" {
+		return nil
+	}
+	return []FixTarget{FileTarget(filepath.Join(ctx.RigPath(), ".repo.git", "config"))}
+}
+
 // RigChecks returns all rig-level health checks.
 func RigChecks() []Check {
 	return []Check{
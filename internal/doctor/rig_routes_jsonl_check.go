@@ -120,6 +120,15 @@ func (c *RigRoutesJSONLCheck) Fix(ctx *CheckContext) error {
 	return nil
 }
 
+// FixTargets returns the rig routes files Fix deletes.
+func (c *RigRoutesJSONLCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	targets := make([]FixTarget, 0, len(c.affectedRigs))
+	for _, info := range c.affectedRigs {
+		targets = append(targets, FileTarget(info.routesPath))
+	}
+	return targets
+}
+
 // findRigDirectories finds all rig directories in the town.
 func (c *RigRoutesJSONLCheck) findRigDirectories(townRoot string) []string {
 	var rigDirs []string
@@ -306,3 +306,8 @@ func (c *RoutesCheck) Fix(ctx *CheckContext) error {
 
 	return nil
 }
+
+// FixTargets returns the town routes file Fix rewrites.
+func (c *RoutesCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	return []FixTarget{FileTarget(filepath.Join(ctx.TownRoot, ".beads", beads.RoutesFileName))}
+}
@@ -116,3 +116,8 @@ func (c *TownRootBranchCheck) Fix(ctx *CheckContext) error {
 
 	return nil
 }
+
+// FixTargets returns the town root HEAD, which Fix moves to main.
+func (c *TownRootBranchCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	return []FixTarget{RefTarget(ctx.TownRoot, "HEAD")}
+}
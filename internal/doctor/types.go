@@ -64,6 +64,8 @@ type CheckContext struct {
 	RigName         string // Rig name (empty for town-level checks)
 	Verbose         bool   // Enable verbose output
 	RestartSessions bool   // Restart patrol sessions when fixing (requires explicit --restart-sessions flag)
+
+	Journal *FixJournal // Records fixes for wt doctor undo (nil: fixes aren't journaled)
 }
 
 // RigPath returns the full path to the rig directory.
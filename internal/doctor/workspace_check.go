@@ -178,6 +178,11 @@ func (c *RigsRegistryExistsCheck) Fix(ctx *CheckContext) error {
 	return os.WriteFile(rigsPath, data, 0644)
 }
 
+// FixTargets returns the registry file Fix creates.
+func (c *RigsRegistryExistsCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	return []FixTarget{FileTarget(filepath.Join(ctx.TownRoot, "mayor", "rigs.json"))}
+}
+
 // RigsRegistryValidCheck verifies mayor/rigs.json is valid and rigs exist.
 type RigsRegistryValidCheck struct {
 	FixableCheck
@@ -313,6 +318,11 @@ func (c *RigsRegistryValidCheck) Fix(ctx *CheckContext) error {
 	return os.WriteFile(rigsPath, newData, 0644)
 }
 
+// FixTargets returns the registry file Fix rewrites.
+func (c *RigsRegistryValidCheck) FixTargets(ctx *CheckContext) []FixTarget {
+	return []FixTarget{FileTarget(filepath.Join(ctx.TownRoot, "mayor", "rigs.json"))}
+}
+
 // MayorExistsCheck verifies the mayor/ directory structure.
 type MayorExistsCheck struct {
 	BaseCheck
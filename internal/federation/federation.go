@@ -0,0 +1,381 @@
+// Package federation lets towns reference and message each other.
+//
+// A town registers peer towns by name in mayor/federation.json. A peer is
+// reached through a connection.Connection: a local connection for a town on
+// this machine, or SSH for a town elsewhere. Over that connection the
+// registry delivers mail into the peer's beads and looks up issues named by
+// hop:// and beads:// references.
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/connection"
+	"github.com/speaker20/whaletown/internal/constants"
+)
+
+// Peer transport types.
+const (
+	PeerLocal = "local" // Town on this machine
+	PeerSSH   = "ssh"   // Town reached over SSH
+)
+
+// reservedNames can't be peer names because they already mean something
+// before the colon in a mail address.
+var reservedNames = map[string]bool{
+	"list":     true,
+	"queue":    true,
+	"announce": true,
+	"channel":  true,
+	"group":    true,
+	"local":    true,
+}
+
+// Peer is another town this town federates with.
+type Peer struct {
+	Name    string    `json:"name"`
+	URL     string    `json:"url"`                // As given to wt federation add
+	Type    string    `json:"type"`               // "local" or "ssh"
+	Host    string    `json:"host,omitempty"`     // For ssh: user@host[:port]
+	Path    string    `json:"path"`               // Town root on the peer
+	KeyPath string    `json:"key_path,omitempty"` // SSH private key path
+	AddedAt time.Time `json:"added_at"`
+
+	// Identity of the peer town, read from its mayor/town.json when added.
+	TownName   string `json:"town_name,omitempty"`
+	Owner      string `json:"owner,omitempty"`
+	PublicName string `json:"public_name,omitempty"`
+}
+
+// ParsePeerURL parses where a peer town lives. Accepted forms:
+//   - /abs/path or file:///abs/path          town on this machine
+//   - ssh://user@host[:port]/path            town over SSH
+//   - user@host:/path                        scp-style SSH
+func ParsePeerURL(raw string) (*Peer, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("empty peer URL")
+	}
+
+	p := &Peer{URL: raw}
+	switch {
+	case strings.HasPrefix(raw, "file://"):
+		p.Type, p.Path = PeerLocal, strings.TrimPrefix(raw, "file://")
+	case strings.HasPrefix(raw, "ssh://"):
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", raw, err)
+		}
+		p.Type, p.Host, p.Path = PeerSSH, u.Host, u.Path
+		if u.User != nil {
+			p.Host = u.User.Username() + "@" + u.Host
+		}
+	case strings.HasPrefix(raw, "/"):
+		p.Type, p.Path = PeerLocal, raw
+	default:
+		host, path, ok := strings.Cut(raw, ":")
+		if !ok || host == "" {
+			return nil, fmt.Errorf("peer URL %q must be an absolute path, file://, ssh:// or user@host:/path", raw)
+		}
+		p.Type, p.Host, p.Path = PeerSSH, host, path
+	}
+
+	if p.Type == PeerSSH && p.Host == "" {
+		return nil, fmt.Errorf("peer URL %q has no host", raw)
+	}
+	if !strings.HasPrefix(p.Path, "/") {
+		return nil, fmt.Errorf("peer URL %q needs an absolute town path", raw)
+	}
+	p.Path = strings.TrimRight(p.Path, "/")
+	if p.Path == "" {
+		p.Path = "/"
+	}
+	return p, nil
+}
+
+// ValidatePeerName checks that name can be used before the colon in
+// peer:rig/role mail addresses.
+func ValidatePeerName(name string) error {
+	if name == "" {
+		return fmt.Errorf("peer name is required")
+	}
+	if reservedNames[name] {
+		return fmt.Errorf("%q is reserved and can't name a peer", name)
+	}
+	if strings.ContainsAny(name, ":/@ \t") {
+		return fmt.Errorf("peer name %q can't contain ':', '/', '@' or spaces", name)
+	}
+	return nil
+}
+
+// registryData is the JSON file structure.
+type registryData struct {
+	Version int              `json:"version"`
+	Peers   map[string]*Peer `json:"peers"`
+}
+
+// Registry holds a town's peers and the connections used to reach them.
+type Registry struct {
+	townRoot string
+	peers    map[string]*Peer
+	conns    map[string]connection.Connection // Open connections, reused across calls
+	mu       sync.Mutex
+}
+
+// Load reads the peer registry of the town at townRoot. A town with no
+// mayor/federation.json has no peers.
+func Load(townRoot string) (*Registry, error) {
+	r := &Registry{
+		townRoot: townRoot,
+		peers:    make(map[string]*Peer),
+		conns:    make(map[string]connection.Connection),
+	}
+
+	data, err := os.ReadFile(constants.MayorFederationPath(townRoot)) //nolint:gosec // G304: path is constructed internally
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading federation registry: %w", err)
+	}
+
+	var rd registryData
+	if err := json.Unmarshal(data, &rd); err != nil {
+		return nil, fmt.Errorf("parsing federation registry: %w", err)
+	}
+	for name, p := range rd.Peers {
+		p.Name = name
+		r.peers[name] = p
+	}
+	return r, nil
+}
+
+// save writes the registry to disk.
+func (r *Registry) save() error {
+	data, err := json.MarshalIndent(registryData{Version: 1, Peers: r.peers}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling federation registry: %w", err)
+	}
+	path := constants.MayorFederationPath(r.townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating mayor directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil { //nolint:gosec // G306: registry is not secret
+		return fmt.Errorf("writing federation registry: %w", err)
+	}
+	return nil
+}
+
+// Get returns a peer by name.
+func (r *Registry) Get(name string) (*Peer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.peers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown peer town: %s", name)
+	}
+	return p, nil
+}
+
+// Has reports whether name is a registered peer.
+func (r *Registry) Has(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.peers[name]
+	return ok
+}
+
+// List returns all peers sorted by name.
+func (r *Registry) List() []*Peer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	peers := make([]*Peer, 0, len(r.peers))
+	for _, p := range r.peers {
+		peers = append(peers, p)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Name < peers[j].Name })
+	return peers
+}
+
+// Add registers a peer, first connecting to it and reading its town
+// identity. Re-adding a name replaces the old entry.
+func (r *Registry) Add(p *Peer) error {
+	if err := ValidatePeerName(p.Name); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closeLocked(p.Name)
+	town, err := r.readTownLocked(p)
+	if err != nil {
+		r.closeLocked(p.Name)
+		return err
+	}
+	p.TownName, p.Owner, p.PublicName = town.Name, town.Owner, town.PublicName
+	if p.AddedAt.IsZero() {
+		p.AddedAt = time.Now()
+	}
+	r.peers[p.Name] = p
+	return r.save()
+}
+
+// Remove unregisters a peer.
+func (r *Registry) Remove(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.peers[name]; !ok {
+		return fmt.Errorf("unknown peer town: %s", name)
+	}
+	r.closeLocked(name)
+	delete(r.peers, name)
+	return r.save()
+}
+
+// Connection returns the connection to a peer, opening it on first use.
+func (r *Registry) Connection(name string) (connection.Connection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.peers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown peer town: %s", name)
+	}
+	return r.connLocked(p)
+}
+
+// connLocked returns a cached or new connection to p. Callers hold mu.
+func (r *Registry) connLocked(p *Peer) (connection.Connection, error) {
+	if c, ok := r.conns[p.Name]; ok {
+		return c, nil
+	}
+
+	var conn connection.Connection
+	switch p.Type {
+	case PeerLocal:
+		conn = connection.NewLocalConnection()
+	case PeerSSH:
+		c, err := connection.NewSSHConnection(&connection.Machine{
+			Name:     "peer:" + p.Name,
+			Type:     "ssh",
+			Host:     p.Host,
+			KeyPath:  p.KeyPath,
+			TownPath: p.Path,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("peer %s: %w", p.Name, err)
+		}
+		conn = c
+	default:
+		return nil, fmt.Errorf("peer %s: unknown type %q", p.Name, p.Type)
+	}
+	r.conns[p.Name] = conn
+	return conn, nil
+}
+
+// readTownLocked reads the peer's mayor/town.json. Callers hold mu.
+func (r *Registry) readTownLocked(p *Peer) (*config.TownConfig, error) {
+	conn, err := r.connLocked(p)
+	if err != nil {
+		return nil, err
+	}
+	path := p.Path + "/" + constants.DirMayor + "/" + constants.FileTownJSON
+	data, err := conn.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("peer %s: %s is not a town (reading %s: %w)", p.Name, p.URL, path, err)
+	}
+	var town config.TownConfig
+	if err := json.Unmarshal(data, &town); err != nil {
+		return nil, fmt.Errorf("peer %s: parsing town.json: %w", p.Name, err)
+	}
+	return &town, nil
+}
+
+// Close closes all open peer connections.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name := range r.conns {
+		r.closeLocked(name)
+	}
+	return nil
+}
+
+// closeLocked drops the cached connection for a peer. Callers hold mu.
+func (r *Registry) closeLocked(name string) {
+	if c, ok := r.conns[name]; ok {
+		if ssh, ok := c.(*connection.SSHConnection); ok {
+			_ = ssh.Close()
+		}
+		delete(r.conns, name)
+	}
+}
+
+// Bd runs a bd command against the peer's town beads and returns its
+// output. BEADS_DIR is set explicitly so a BEADS_DIR inherited from the
+// local town can't redirect the command.
+func (r *Registry) Bd(peer string, args ...string) ([]byte, error) {
+	p, err := r.Get(peer)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := r.Connection(peer)
+	if err != nil {
+		return nil, err
+	}
+	beadsDir := p.Path + "/" + constants.DirBeads
+	cmdArgs := append([]string{"BEADS_DIR=" + beadsDir, "bd"}, args...)
+	out, err := conn.ExecDir(p.Path, "env", cmdArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("peer %s: bd %s: %s", peer, args[0], strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// Nudge tells a session in the peer town about new mail. It is best
+// effort: a peer without the session, or without tmux, is not an error.
+func (r *Registry) Nudge(peer, session, message string) {
+	conn, err := r.Connection(peer)
+	if err != nil {
+		return
+	}
+	if ok, err := conn.TmuxHasSession(session); err != nil || !ok {
+		return
+	}
+	_ = conn.Tmux().NudgeSession(session, message)
+}
+
+// SplitAddress splits a peer-qualified mail address ("peer:rig/role")
+// into the peer name and the address inside the peer town. ok is false
+// for addresses without a peer prefix, including list:, queue:,
+// announce:, channel: and group: addresses.
+func SplitAddress(address string) (peer, rest string, ok bool) {
+	peer, rest, found := strings.Cut(address, ":")
+	if !found || peer == "" || rest == "" || reservedNames[peer] || strings.ContainsAny(peer, "/@") {
+		return "", "", false
+	}
+	return peer, rest, true
+}
+
+// LocalTownName returns the name this town goes by in peer addresses: the
+// name in mayor/town.json, or the town directory's name.
+func LocalTownName(townRoot string) string {
+	if town, err := config.LoadTownConfig(filepath.Join(townRoot, constants.DirMayor, constants.FileTownJSON)); err == nil && town.Name != "" {
+		return town.Name
+	}
+	return filepath.Base(townRoot)
+}
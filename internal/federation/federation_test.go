@@ -0,0 +1,239 @@
+package federation
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePeerURL(t *testing.T) {
+	tests := []struct {
+		raw      string
+		wantType string
+		wantHost string
+		wantPath string
+		wantErr  bool
+	}{
+		{raw: "/srv/towns/acme/", wantType: PeerLocal, wantPath: "/srv/towns/acme"},
+		{raw: "file:///srv/towns/acme", wantType: PeerLocal, wantPath: "/srv/towns/acme"},
+		{raw: "ssh://wt@acme.internal:2222/srv/wt", wantType: PeerSSH, wantHost: "wt@acme.internal:2222", wantPath: "/srv/wt"},
+		{raw: "wt@acme.internal:/srv/wt", wantType: PeerSSH, wantHost: "wt@acme.internal", wantPath: "/srv/wt"},
+		{raw: "towns/acme", wantErr: true},
+		{raw: "wt@acme.internal:srv/wt", wantErr: true},
+		{raw: "ssh:///srv/wt", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		p, err := ParsePeerURL(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePeerURL(%q) should fail, got %+v", tt.raw, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePeerURL(%q): %v", tt.raw, err)
+			continue
+		}
+		if p.Type != tt.wantType || p.Host != tt.wantHost || p.Path != tt.wantPath {
+			t.Errorf("ParsePeerURL(%q) = %s %q %q, want %s %q %q", tt.raw, p.Type, p.Host, p.Path, tt.wantType, tt.wantHost, tt.wantPath)
+		}
+	}
+}
+
+func TestSplitAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		wantPeer string
+		wantRest string
+		wantOK   bool
+	}{
+		{"acme:backend/witness", "acme", "backend/witness", true},
+		{"acme:mayor/", "acme", "mayor/", true},
+		{"backend/witness", "", "", false},
+		{"list:oncall", "", "", false},
+		{"queue:work", "", "", false},
+		{"channel:alerts", "", "", false},
+		{"@town", "", "", false},
+		{"acme:", "", "", false},
+	}
+	for _, tt := range tests {
+		peer, rest, ok := SplitAddress(tt.address)
+		if peer != tt.wantPeer || rest != tt.wantRest || ok != tt.wantOK {
+			t.Errorf("SplitAddress(%q) = %q, %q, %v", tt.address, peer, rest, ok)
+		}
+	}
+
+	if err := ValidatePeerName("queue"); err == nil {
+		t.Error("reserved name accepted")
+	}
+	if err := ValidatePeerName("a:b"); err == nil {
+		t.Error("name with a colon accepted")
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	ref, err := ParseRef("hop://steve@example.com/main-town/greenplace/gp-xyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Entity != "steve@example.com" || ref.Chain != "main-town" || ref.Rig != "greenplace" || ref.ID != "gp-xyz" {
+		t.Errorf("hop ref = %+v", ref)
+	}
+
+	ref, err = ParseRef("hop://acme.com/eng/ac-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Entity != "acme.com" || ref.Chain != "eng" || ref.Rig != "" || ref.ID != "ac-123" {
+		t.Errorf("hop ref without rig = %+v", ref)
+	}
+
+	ref, err = ParseRef("beads://github/acme/backend/ac-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Platform != "github" || ref.Org != "acme" || ref.Repo != "backend" || ref.ID != "ac-123" {
+		t.Errorf("beads ref = %+v", ref)
+	}
+
+	for _, bad := range []string{"hop://acme.com/ac-123", "beads://github/acme/ac-123", "ftp://a/b/c", "gp-xyz", "hop://a//b/c"} {
+		if _, err := ParseRef(bad); err == nil {
+			t.Errorf("ParseRef(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFindRefs(t *testing.T) {
+	text := `Blocked on hop://acme.com/eng/ac-123 (API) and beads://github/acme/backend/ac-9.
+See also hop://acme.com/eng/ac-123, which is the same thing.`
+	want := []string{"hop://acme.com/eng/ac-123", "beads://github/acme/backend/ac-9"}
+	if got := FindRefs(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindRefs = %v, want %v", got, want)
+	}
+}
+
+func TestRepoMatches(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://github.com/acme/backend.git", true},
+		{"git@github.com:acme/backend.git", true},
+		{"ssh://git@github.com/Acme/Backend", true},
+		{"https://gitlab.com/acme/backend", false},
+		{"https://github.com/acme/frontend", false},
+		{"/local/path/backend", false},
+	}
+	for _, tt := range tests {
+		if got := repoMatches(tt.url, "github", "acme", "backend"); got != tt.want {
+			t.Errorf("repoMatches(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+// newTown creates a town with the given mayor/town.json name and owner.
+func newTown(t *testing.T, name, owner string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	town := `{"type":"town","version":1,"name":"` + name + `","owner":"` + owner + `"}`
+	if err := os.WriteFile(filepath.Join(root, "mayor", "town.json"), []byte(town), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// stubBd puts a bd on PATH that answers show with an issue whose status
+// is read from $BEADS_DIR/status, so each town answers differently.
+func stubBd(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	script := `#!/bin/sh
+[ "$1" = "--no-daemon" ] && shift
+if [ "$1" = "show" ]; then
+  echo "[{\"id\":\"$2\",\"title\":\"Remote work\",\"status\":\"$(cat "$BEADS_DIR/status")\"}]"
+  exit 0
+fi
+exit 1
+`
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRegistryResolveAcrossTowns(t *testing.T) {
+	stubBd(t)
+	home := newTown(t, "home", "")
+	acme := newTown(t, "acme-main", "ops@acme.com")
+	if err := os.MkdirAll(filepath.Join(acme, ".beads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(acme, ".beads", "status"), []byte("in_progress"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rigs := `{"version":1,"rigs":{"backend":{"git_url":"git@github.com:acme/backend.git"}}}`
+	if err := os.WriteFile(filepath.Join(acme, "mayor", "rigs.json"), []byte(rigs), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := Load(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := ParsePeerURL(acme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer.Name = "acme"
+	if err := registry.Add(peer); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add(&Peer{Name: "nowhere", Type: PeerLocal, Path: t.TempDir()}); err == nil {
+		t.Error("a directory without mayor/town.json was accepted as a peer")
+	}
+
+	// The registry persists, with the peer's identity
+	registry, err = Load(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = registry.Close() }()
+	got, err := registry.Get("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TownName != "acme-main" || got.Owner != "ops@acme.com" || got.Path != acme {
+		t.Errorf("peer = %+v", got)
+	}
+
+	for _, ref := range []string{
+		"hop://acme.com/acme-main/backend/ac-123", // town name, owner's domain
+		"hop://ops@acme.com/acme/ac-123",          // registered name, owner
+		"beads://github/acme/backend/ac-123",      // rig's repository
+	} {
+		issue, err := registry.Resolve(ref)
+		if err != nil {
+			t.Errorf("Resolve(%s): %v", ref, err)
+			continue
+		}
+		if issue.Peer != "acme" || issue.ID != "ac-123" || issue.Status != "in_progress" {
+			t.Errorf("Resolve(%s) = %+v", ref, issue)
+		}
+	}
+
+	for _, ref := range []string{
+		"hop://other.org/acme-main/ac-123",  // wrong owner
+		"hop://acme.com/elsewhere/ac-123",   // unknown chain
+		"beads://github/acme/frontend/ac-1", // no rig for the repo
+	} {
+		if _, err := registry.Resolve(ref); err == nil || !strings.Contains(err.Error(), "no peer town") {
+			t.Errorf("Resolve(%s) error = %v", ref, err)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/constants"
+)
+
+// Reference schemes.
+const (
+	SchemeHop   = "hop"   // hop://entity/chain[/rig]/issue-id
+	SchemeBeads = "beads" // beads://platform/org/repo/issue-id
+)
+
+// refPattern finds references in free text such as bead descriptions.
+var refPattern = regexp.MustCompile(`\b(?:hop|beads)://[^\s<>()\[\]"'` + "`" + `,;]+`)
+
+// Ref is a parsed cross-town work unit reference.
+type Ref struct {
+	Raw    string
+	Scheme string
+
+	// hop:// fields
+	Entity string
+	Chain  string
+	Rig    string // Optional
+
+	// beads:// fields
+	Platform string
+	Org      string
+	Repo     string
+
+	ID string // Issue ID inside the remote town
+}
+
+// IsRef reports whether s is a hop:// or beads:// reference.
+func IsRef(s string) bool {
+	return strings.HasPrefix(s, SchemeHop+"://") || strings.HasPrefix(s, SchemeBeads+"://")
+}
+
+// ParseRef parses a hop:// or beads:// reference.
+func ParseRef(s string) (*Ref, error) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok {
+		return nil, fmt.Errorf("not a federated reference: %s", s)
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf("empty path segment in %s", s)
+		}
+	}
+
+	ref := &Ref{Raw: s, Scheme: scheme}
+	switch scheme {
+	case SchemeHop:
+		switch len(parts) {
+		case 3:
+			ref.Entity, ref.Chain, ref.ID = parts[0], parts[1], parts[2]
+		case 4:
+			ref.Entity, ref.Chain, ref.Rig, ref.ID = parts[0], parts[1], parts[2], parts[3]
+		default:
+			return nil, fmt.Errorf("hop reference %s must be hop://entity/chain[/rig]/issue-id", s)
+		}
+	case SchemeBeads:
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("beads reference %s must be beads://platform/org/repo/issue-id", s)
+		}
+		ref.Platform, ref.Org, ref.Repo, ref.ID = parts[0], parts[1], parts[2], parts[3]
+	default:
+		return nil, fmt.Errorf("unknown reference scheme %q in %s", scheme, s)
+	}
+	return ref, nil
+}
+
+// FindRefs returns the distinct references in text, in order of appearance.
+func FindRefs(text string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, m := range refPattern.FindAllString(text, -1) {
+		m = strings.TrimRight(m, ".:!?")
+		if !seen[m] {
+			seen[m] = true
+			refs = append(refs, m)
+		}
+	}
+	return refs
+}
+
+// RemoteIssue is an issue looked up in a peer town.
+type RemoteIssue struct {
+	Ref       string `json:"ref"`
+	Peer      string `json:"peer"`
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	IssueType string `json:"issue_type,omitempty"`
+	Assignee  string `json:"assignee,omitempty"`
+}
+
+// Resolve finds the peer town a reference points at and fetches the issue
+// from it.
+func (r *Registry) Resolve(s string) (*RemoteIssue, error) {
+	ref, err := ParseRef(s)
+	if err != nil {
+		return nil, err
+	}
+	peer, err := r.PeerFor(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := r.Bd(peer.Name, "--no-daemon", "show", ref.ID, "--json")
+	if err != nil {
+		return nil, err
+	}
+	var issues []struct {
+		ID        string `json:"id"`
+		Title     string `json:"title"`
+		Status    string `json:"status"`
+		IssueType string `json:"issue_type"`
+		Assignee  string `json:"assignee"`
+	}
+	if err := json.Unmarshal(jsonPayload(out), &issues); err != nil || len(issues) == 0 {
+		return nil, fmt.Errorf("peer %s: issue %s not found", peer.Name, ref.ID)
+	}
+	return &RemoteIssue{
+		Ref:       s,
+		Peer:      peer.Name,
+		ID:        issues[0].ID,
+		Title:     issues[0].Title,
+		Status:    issues[0].Status,
+		IssueType: issues[0].IssueType,
+		Assignee:  issues[0].Assignee,
+	}, nil
+}
+
+// PeerFor returns the peer a reference points at.
+//
+// A hop:// chain names a peer by its registered name, its town name or its
+// public name; when the peer's owner is known the entity must match it.
+// A beads:// reference names a repository, so it points at the peer with
+// a rig cloned from platform/org/repo.
+func (r *Registry) PeerFor(ref *Ref) (*Peer, error) {
+	for _, p := range r.List() {
+		switch ref.Scheme {
+		case SchemeHop:
+			if (ref.Chain == p.Name || ref.Chain == p.TownName || ref.Chain == p.PublicName) && entityMatches(ref.Entity, p.Owner) {
+				return p, nil
+			}
+		case SchemeBeads:
+			if r.hostsRepo(p, ref) {
+				return p, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no peer town for %s (see wt federation list)", ref.Raw)
+}
+
+// entityMatches reports whether a hop:// entity names a town's owner.
+// Either may be a bare domain or an email address within it.
+func entityMatches(entity, owner string) bool {
+	if owner == "" || entity == owner {
+		return true
+	}
+	return strings.HasSuffix(owner, "@"+entity)
+}
+
+// hostsRepo reports whether one of the peer's rigs is cloned from the
+// repository a beads:// reference names.
+func (r *Registry) hostsRepo(p *Peer, ref *Ref) bool {
+	conn, err := r.Connection(p.Name)
+	if err != nil {
+		return false
+	}
+	data, err := conn.ReadFile(p.Path + "/" + constants.DirMayor + "/" + constants.FileRigsJSON)
+	if err != nil {
+		return false
+	}
+	var rigs config.RigsConfig
+	if err := json.Unmarshal(data, &rigs); err != nil {
+		return false
+	}
+	for _, entry := range rigs.Rigs {
+		if repoMatches(entry.GitURL, ref.Platform, ref.Org, ref.Repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// repoMatches reports whether gitURL is platform/org/repo. The platform
+// may be the full host ("github.com") or its first label ("github").
+func repoMatches(gitURL, platform, org, repo string) bool {
+	u := gitURL
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = rest
+	}
+	if i := strings.Index(u, "@"); i >= 0 && i < strings.IndexAny(u+"/", ":/") {
+		u = u[i+1:]
+	}
+	u = strings.Replace(u, ":", "/", 1) // scp-style git@host:org/repo
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+
+	parts := strings.Split(u, "/")
+	if len(parts) < 3 {
+		return false
+	}
+	host, gotOrg, gotRepo := parts[0], parts[len(parts)-2], parts[len(parts)-1]
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+	hostMatches := strings.EqualFold(host, platform) || strings.HasPrefix(strings.ToLower(host), strings.ToLower(platform)+".")
+	return hostMatches && strings.EqualFold(gotOrg, org) && strings.EqualFold(gotRepo, repo)
+}
+
+// jsonPayload skips anything a remote command printed before its JSON.
+func jsonPayload(out []byte) []byte {
+	for i, b := range out {
+		if b == '[' || b == '{' {
+			return out[i:]
+		}
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultMergeMessageTemplate is the merge commit message used when a rig
+// doesn't configure commit_template.
+const DefaultMergeMessageTemplate = "Merge {{.Branch}} into {{.Target}}{{if .Issue}} ({{.Issue}}){{end}}"
+
+// CommitMessageData is the data available to merge commit message
+// templates (merge_queue.commit_template).
+type CommitMessageData struct {
+	Branch      string // Source branch (e.g., "polecat/nux/wt-abc")
+	Target      string // Target branch (e.g., "main")
+	MR          string // Merge request bead ID
+	Issue       string // Source issue bead ID
+	Title       string // Source issue title
+	Description string // Source issue description
+	Polecat     string // Agent identity (e.g., "whaletown/polecats/nux")
+	Rig         string
+	Convoy      string // Convoy ID, if the work is tracked by one
+	ConvoyTitle string
+}
+
+// RenderCommitMessage executes a text/template against data. An empty
+// template uses DefaultMergeMessageTemplate.
+func RenderCommitMessage(tmpl string, data CommitMessageData) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultMergeMessageTemplate
+	}
+	t, err := template.New("commit").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing commit template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering commit template: %w", err)
+	}
+	msg := strings.TrimSpace(buf.String())
+	if msg == "" {
+		return "", fmt.Errorf("commit template rendered an empty message")
+	}
+	return msg, nil
+}
+
+// ValidateCommitTemplate checks that tmpl parses and only references
+// CommitMessageData fields.
+func ValidateCommitTemplate(tmpl string) error {
+	sample := CommitMessageData{
+		Branch: "polecat/nux", Target: "main", MR: "wt-mr", Issue: "wt-abc",
+		Title: "Title", Description: "Description", Polecat: "rig/polecats/nux",
+		Rig: "rig", Convoy: "hq-cv", ConvoyTitle: "Convoy",
+	}
+	_, err := RenderCommitMessage(tmpl, sample)
+	return err
+}
+
+// AgentEmail converts a Whale Town identity to a git email address.
+// "whaletown/crew/jack" → "whaletown.crew.jack@domain"
+func AgentEmail(identity, domain string) string {
+	identity = strings.TrimSuffix(identity, "/")
+	return strings.ReplaceAll(identity, "/", ".") + "@" + domain
+}
+
+// CoAuthorTrailer returns a Co-authored-by trailer for an agent identity.
+func CoAuthorTrailer(identity, domain string) string {
+	identity = strings.TrimSuffix(identity, "/")
+	return fmt.Sprintf("Co-authored-by: %s <%s>", identity, AgentEmail(identity, domain))
+}
+
+// AppendTrailers adds trailer lines to a commit message, separated from
+// the body by a blank line. Trailers already present are not repeated.
+func AppendTrailers(msg string, trailers ...string) string {
+	var add []string
+	for _, t := range trailers {
+		if t == "" || strings.Contains(msg, t) {
+			continue
+		}
+		dup := false
+		for _, a := range add {
+			if a == t {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			add = append(add, t)
+		}
+	}
+	if len(add) == 0 {
+		return msg
+	}
+	return strings.TrimRight(msg, "\n") + "\n\n" + strings.Join(add, "\n")
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderCommitMessage(t *testing.T) {
+	data := CommitMessageData{
+		Branch:      "polecat/nux",
+		Target:      "main",
+		Issue:       "wt-abc",
+		Title:       "Fix login redirect",
+		Description: "Users bounced back to /login.",
+		Polecat:     "whaletown/polecats/nux",
+		Convoy:      "hq-cv-1",
+		ConvoyTitle: "Auth cleanup",
+	}
+
+	got, err := RenderCommitMessage("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Merge polecat/nux into main (wt-abc)"; got != want {
+		t.Errorf("default = %q, want %q", got, want)
+	}
+
+	tmpl := "{{.Title}} ({{.Issue}})\n\n{{.Description}}\n{{if .Convoy}}\nConvoy: {{.ConvoyTitle}} [{{.Convoy}}]{{end}}"
+	got, err = RenderCommitMessage(tmpl, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Fix login redirect (wt-abc)\n\nUsers bounced back to /login.\n\nConvoy: Auth cleanup [hq-cv-1]"
+	if got != want {
+		t.Errorf("custom = %q, want %q", got, want)
+	}
+
+	if _, err := RenderCommitMessage("{{.Nope}}", data); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if _, err := RenderCommitMessage("{{if .Convoy}}x{{end}}", CommitMessageData{}); err == nil {
+		t.Error("expected an error for an empty message")
+	}
+}
+
+func TestValidateCommitTemplate(t *testing.T) {
+	if err := ValidateCommitTemplate("{{.Title}}"); err != nil {
+		t.Errorf("valid template rejected: %v", err)
+	}
+	for _, tmpl := range []string{"{{.Title", "{{.Author}}"} {
+		if err := ValidateCommitTemplate(tmpl); err == nil {
+			t.Errorf("ValidateCommitTemplate(%q) should fail", tmpl)
+		}
+	}
+}
+
+func TestAppendTrailers(t *testing.T) {
+	nux := CoAuthorTrailer("whaletown/polecats/nux", "example.com")
+	if want := "Co-authored-by: whaletown/polecats/nux <whaletown.polecats.nux@example.com>"; nux != want {
+		t.Errorf("CoAuthorTrailer = %q, want %q", nux, want)
+	}
+
+	got := AppendTrailers("Subject\n", nux, nux, "")
+	if want := "Subject\n\n" + nux; got != want {
+		t.Errorf("AppendTrailers = %q, want %q", got, want)
+	}
+	if again := AppendTrailers(got, nux); again != got {
+		t.Errorf("trailer repeated: %q", again)
+	}
+	if got := AppendTrailers("Subject"); got != "Subject" {
+		t.Errorf("no trailers: %q", got)
+	}
+	if !strings.HasPrefix(AgentEmail("mayor/", "x.io"), "mayor@") {
+		t.Error("AgentEmail should drop the trailing slash")
+	}
+}
@@ -33,10 +33,15 @@ func (e *GitError) Unwrap() error {
 	return e.Err
 }
 
+// Runner runs a git command in dir somewhere other than the local machine
+// (e.g., over SSH) and returns its raw stdout and stderr.
+type Runner func(dir string, args ...string) (stdout, stderr string, err error)
+
 // Git wraps git operations for a working directory.
 type Git struct {
 	workDir string
 	gitDir  string // Optional: explicit git directory (for bare repos)
+	runner  Runner // Optional: runs commands remotely instead of exec
 }
 
 // NewGit creates a new Git wrapper for the given directory.
@@ -51,6 +56,18 @@ func NewGitWithDir(gitDir, workDir string) *Git {
 	return &Git{gitDir: gitDir, workDir: workDir}
 }
 
+// NewGitWithRunner creates a Git wrapper whose commands run through r.
+// Only operations built on the common run path are supported; clones
+// and merge checks still run locally.
+func NewGitWithRunner(workDir string, r Runner) *Git {
+	return &Git{workDir: workDir, runner: r}
+}
+
+// NewGitWithDirAndRunner is NewGitWithDir with commands run through r.
+func NewGitWithDirAndRunner(gitDir, workDir string, r Runner) *Git {
+	return &Git{gitDir: gitDir, workDir: workDir, runner: r}
+}
+
 // WorkDir returns the working directory for this Git instance.
 func (g *Git) WorkDir() string {
 	return g.workDir
@@ -69,6 +86,14 @@ func (g *Git) run(args ...string) (string, error) {
 		args = append([]string{"--git-dir=" + g.gitDir}, args...)
 	}
 
+	if g.runner != nil {
+		stdout, stderr, err := g.runner(g.workDir, args...)
+		if err != nil {
+			return "", g.wrapError(err, stdout, stderr, args)
+		}
+		return strings.TrimSpace(stdout), nil
+	}
+
 	cmd := exec.Command("git", args...)
 	if g.workDir != "" {
 		cmd.Dir = g.workDir
@@ -399,6 +424,86 @@ func (g *Git) MergeNoFF(branch, message string) error {
 	return err
 }
 
+// MergeFFOnly fast-forwards the current branch to the given branch,
+// failing if a fast-forward isn't possible.
+func (g *Git) MergeFFOnly(branch string) error {
+	_, err := g.run("merge", "--ff-only", branch)
+	return err
+}
+
+// CommitOptions controls the commit created by a merge.
+type CommitOptions struct {
+	Message string
+	Sign    bool // GPG/SSH-sign with the configured user.signingkey (-S)
+}
+
+func (o CommitOptions) signArgs() []string {
+	if o.Sign {
+		return []string{"-S"}
+	}
+	return nil
+}
+
+// MergeNoFFWith merges the given branch with --no-ff, creating a merge
+// commit with the given options.
+func (g *Git) MergeNoFFWith(branch string, opts CommitOptions) error {
+	args := append([]string{"merge", "--no-ff", "-m", opts.Message}, opts.signArgs()...)
+	_, err := g.run(append(args, branch)...)
+	return err
+}
+
+// MergeSquash squashes the given branch into a single commit on the
+// current branch. On a conflict the index is left conflicted for
+// inspection; clean up with ResetHard (a squash has no merge to abort).
+func (g *Git) MergeSquash(branch string, opts CommitOptions) error {
+	if _, err := g.run("merge", "--squash", branch); err != nil {
+		return err
+	}
+	args := append([]string{"commit", "-m", opts.Message}, opts.signArgs()...)
+	_, err := g.run(args...)
+	return err
+}
+
+// RebaseFF rebases a copy of branch onto the current branch and
+// fast-forwards the current branch to it, producing linear history. The
+// source branch itself is not moved. On a rebase conflict the rebase is
+// aborted, the current branch checked out again, and the conflicting files
+// of the failing commit returned with the error.
+func (g *Git) RebaseFF(branch string, sign bool) ([]string, error) {
+	current, err := g.CurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.run("checkout", "--detach", branch); err != nil {
+		return nil, err
+	}
+	args := []string{"-c", "core.editor=true", "rebase"}
+	if sign {
+		args = append(args, "--gpg-sign")
+	}
+	if _, err := g.run(append(args, current)...); err != nil {
+		conflicts, _ := g.GetConflictingFiles()
+		_ = g.AbortRebase()
+		_ = g.Checkout(current)
+		return conflicts, err
+	}
+	head, err := g.Rev("HEAD")
+	if err != nil {
+		_ = g.Checkout(current)
+		return nil, err
+	}
+	if err := g.Checkout(current); err != nil {
+		return nil, err
+	}
+	return nil, g.MergeFFOnly(head)
+}
+
+// ResetHard discards all changes to tracked files and the index.
+func (g *Git) ResetHard(ref string) error {
+	_, err := g.run("reset", "--hard", ref)
+	return err
+}
+
 // DeleteRemoteBranch deletes a branch on the remote.
 func (g *Git) DeleteRemoteBranch(remote, branch string) error {
 	_, err := g.run("push", remote, "--delete", branch)
@@ -504,6 +609,67 @@ func (g *Git) AbortRebase() error {
 	return err
 }
 
+// RebaseOptions tunes RebaseWith and RebaseContinue.
+type RebaseOptions struct {
+	// StrategyOptions are passed as -X (e.g., "ignore-space-change").
+	StrategyOptions []string
+
+	// AttributesFile, if set, is used as core.attributesFile so merge
+	// drivers (e.g., "go.sum merge=union") apply during the rebase.
+	AttributesFile string
+}
+
+// rebaseArgs builds a rebase command line. The editor is disabled so
+// continuing never blocks on a commit message prompt.
+func (o RebaseOptions) rebaseArgs(args ...string) []string {
+	out := []string{"-c", "core.editor=true"}
+	if o.AttributesFile != "" {
+		out = append(out, "-c", "core.attributesFile="+o.AttributesFile)
+	}
+	return append(append(out, "rebase"), args...)
+}
+
+// RebaseWith rebases the current branch (or detached HEAD) onto the given ref.
+func (g *Git) RebaseWith(onto string, opts RebaseOptions) error {
+	var args []string
+	for _, x := range opts.StrategyOptions {
+		args = append(args, "-X", x)
+	}
+	_, err := g.run(opts.rebaseArgs(append(args, onto)...)...)
+	return err
+}
+
+// RebaseContinue continues a rebase after conflicts have been staged.
+func (g *Git) RebaseContinue(opts RebaseOptions) error {
+	_, err := g.run(opts.rebaseArgs("--continue")...)
+	return err
+}
+
+// RebaseSkip skips the current commit of a rebase in progress.
+func (g *Git) RebaseSkip(opts RebaseOptions) error {
+	_, err := g.run(opts.rebaseArgs("--skip")...)
+	return err
+}
+
+// CheckoutConflictSide resolves a conflicted path by taking one side:
+// ours (during a rebase, the upstream being rebased onto) or theirs (the
+// commit being replayed).
+func (g *Git) CheckoutConflictSide(path string, ours bool) error {
+	side := "--theirs"
+	if ours {
+		side = "--ours"
+	}
+	_, err := g.run("checkout", side, "--", path)
+	return err
+}
+
+// PushForceWithLease pushes ref to the remote branch, overwriting it only
+// if the remote branch is still at expect.
+func (g *Git) PushForceWithLease(remote, ref, branch, expect string) error {
+	_, err := g.run("push", "--force-with-lease="+branch+":"+expect, remote, ref+":refs/heads/"+branch)
+	return err
+}
+
 // CreateBranch creates a new branch.
 func (g *Git) CreateBranch(name string) error {
 	_, err := g.run("branch", name)
@@ -603,7 +769,7 @@ func (g *Git) WorktreeAdd(path, branch string) error {
 	if _, err := g.run("worktree", "add", "-b", branch, path); err != nil {
 		return err
 	}
-	return ConfigureSparseCheckout(path)
+	return g.configureSparseCheckout(path)
 }
 
 // WorktreeAddFromRef creates a new worktree at the given path with a new branch
@@ -613,7 +779,7 @@ func (g *Git) WorktreeAddFromRef(path, branch, startPoint string) error {
 	if _, err := g.run("worktree", "add", "-b", branch, path, startPoint); err != nil {
 		return err
 	}
-	return ConfigureSparseCheckout(path)
+	return g.configureSparseCheckout(path)
 }
 
 // WorktreeAddDetached creates a new worktree at the given path with a detached HEAD.
@@ -622,7 +788,7 @@ func (g *Git) WorktreeAddDetached(path, ref string) error {
 	if _, err := g.run("worktree", "add", "--detach", path, ref); err != nil {
 		return err
 	}
-	return ConfigureSparseCheckout(path)
+	return g.configureSparseCheckout(path)
 }
 
 // WorktreeAddExisting creates a new worktree at the given path for an existing branch.
@@ -631,7 +797,7 @@ func (g *Git) WorktreeAddExisting(path, branch string) error {
 	if _, err := g.run("worktree", "add", path, branch); err != nil {
 		return err
 	}
-	return ConfigureSparseCheckout(path)
+	return g.configureSparseCheckout(path)
 }
 
 // WorktreeAddExistingForce creates a new worktree even if the branch is already checked out elsewhere.
@@ -641,7 +807,7 @@ func (g *Git) WorktreeAddExistingForce(path, branch string) error {
 	if _, err := g.run("worktree", "add", "--force", path, branch); err != nil {
 		return err
 	}
-	return ConfigureSparseCheckout(path)
+	return g.configureSparseCheckout(path)
 }
 
 // ConfigureSparseCheckout sets up sparse checkout for a clone or worktree to exclude .claude/.
@@ -706,6 +872,27 @@ func ConfigureSparseCheckout(repoPath string) error {
 	return nil
 }
 
+// configureSparseCheckout is ConfigureSparseCheckout for a worktree this
+// Git created. Remote worktrees can't have the patterns file written
+// directly, so the patterns are passed to sparse-checkout as arguments.
+func (g *Git) configureSparseCheckout(repoPath string) error {
+	if g.runner == nil {
+		return ConfigureSparseCheckout(repoPath)
+	}
+	wt := &Git{workDir: repoPath, runner: g.runner}
+	if _, err := wt.run("config", "core.sparseCheckout", "true"); err != nil {
+		return fmt.Errorf("enabling sparse checkout: %w", err)
+	}
+	args := []string{"sparse-checkout", "set", "--no-cone", "/*"}
+	for _, f := range ExcludedContextFiles {
+		args = append(args, "!/"+f)
+	}
+	if _, err := wt.run(args...); err != nil {
+		return fmt.Errorf("applying sparse checkout: %w", err)
+	}
+	return nil
+}
+
 // ExcludedContextFiles lists all Claude context files that should be excluded by sparse checkout.
 var ExcludedContextFiles = []string{
 	".claude",
@@ -31,6 +31,10 @@ type Mailbox struct {
 	beadsDir string // explicit .beads directory path (set via BEADS_DIR)
 	path     string // for legacy JSONL mode (crew workers)
 	legacy   bool   // true = use JSONL files, false = use beads
+	handler  ProtocolHandler // for the "handle" mail rule action
+
+	store        beads.Store // reads messages without running bd; nil: run bd
+	storeChecked bool
 }
 
 // NewMailbox creates a mailbox for the given JSONL path (legacy mode).
@@ -73,6 +77,33 @@ func NewMailboxWithBeadsDir(address, workDir, beadsDir string) *Mailbox {
 	}
 }
 
+// SetProtocolHandler sets the handler that mail rules with the "handle"
+// action pass messages to.
+func (m *Mailbox) SetProtocolHandler(h ProtocolHandler) {
+	m.handler = h
+}
+
+// SetStore sets the beads store the mailbox reads messages from, in place
+// of running bd (tests use a beads.MemStore). By default a mailbox reads
+// the database directly when beads.LocalStoreFor allows it.
+func (m *Mailbox) SetStore(s beads.Store) {
+	m.store = s
+	m.storeChecked = true
+}
+
+// readStore returns the store to read messages from, or nil to run bd.
+func (m *Mailbox) readStore() beads.Store {
+	if !m.storeChecked {
+		m.storeChecked = true
+		if m.beadsDir != "" {
+			if s := beads.LocalStoreFor(m.workDir, m.beadsDir); s != nil && s.Load() == nil {
+				m.store = s
+			}
+		}
+	}
+	return m.store
+}
+
 // Identity returns the beads identity for this mailbox.
 func (m *Mailbox) Identity() string {
 	return m.identity
@@ -99,6 +130,18 @@ func (m *Mailbox) listBeads() ([]*Message, error) {
 		return nil, err
 	}
 
+	// Hide mail that isn't due yet, and unread mail that has gone stale
+	// but hasn't been archived by the daemon yet
+	now := timeNow()
+	visible := messages[:0]
+	for _, msg := range messages {
+		if msg.IsScheduled(now) || (msg.IsExpired(now) && !msg.Read) {
+			continue
+		}
+		visible = append(visible, msg)
+	}
+	messages = m.filterOnList(visible)
+
 	// Sort by timestamp (newest first)
 	sort.Slice(messages, func(i, j int) bool {
 		return messages[i].Timestamp.After(messages[j].Timestamp)
@@ -107,6 +150,75 @@ func (m *Mailbox) listBeads() ([]*Message, error) {
 	return messages, nil
 }
 
+// filterOnList runs the mailbox rules on messages they haven't run on
+// yet: mail that arrived scheduled, from a peer town, or before the
+// rules existed. Rule actions are best-effort. Messages a rule archives
+// are dropped from the list; the others are marked filtered so their
+// actions run only once.
+func (m *Mailbox) filterOnList(messages []*Message) []*Message {
+	if rulesDisabled() {
+		return messages
+	}
+	townRoot := detectTownRoot(m.workDir)
+	address := identityToAddress(m.identity)
+	rules, err := LoadRules(townRoot, address)
+	if err != nil || len(rules.Rules) == 0 {
+		return messages
+	}
+
+	var router *Router
+	kept := messages[:0]
+	for _, msg := range messages {
+		if msg.filtered {
+			kept = append(kept, msg)
+			continue
+		}
+		outcome := rules.Evaluate(msg)
+		if outcome.Empty() {
+			kept = append(kept, msg)
+			continue
+		}
+
+		for _, label := range outcome.Labels {
+			if m.addLabel(msg.ID, label) == nil {
+				msg.Labels = append(msg.Labels, label)
+			}
+		}
+		if outcome.Pin && !msg.Pinned && m.addLabel(msg.ID, LabelPinned) == nil {
+			msg.Pinned = true
+		}
+		if outcome.MarkRead && !msg.Read && m.addLabel(msg.ID, LabelRead) == nil {
+			msg.Read = true
+		}
+		if router == nil {
+			router = NewRouterWithTownRoot(townRoot, townRoot)
+		}
+		handled := applySideEffects(outcome, msg, address, townRoot, router, m.handler)
+		if (outcome.Archive || handled) && m.closeInDir(msg.ID, m.beadsDir) == nil {
+			continue
+		}
+		_ = m.addLabel(msg.ID, LabelFiltered)
+		msg.filtered = true
+		kept = append(kept, msg)
+	}
+	return kept
+}
+
+// MarkEmailed records that the email gateway has sent a message, so it
+// isn't sent again.
+func (m *Mailbox) MarkEmailed(id string) error {
+	if m.legacy {
+		return errors.New("email gateway requires a beads mailbox")
+	}
+	return m.addLabel(id, LabelEmailed)
+}
+
+// addLabel adds a label to a message bead.
+func (m *Mailbox) addLabel(id, label string) error {
+	_, err := runBdCommand([]string{"label", "add", id, label}, m.workDir, m.beadsDir)
+	return err
+}
+
 // listFromDir queries messages from a beads directory.
 // Returns messages where identity is the assignee OR a CC recipient.
 // Includes both open and hooked messages (hooked = auto-assigned handoff mail).
@@ -178,8 +290,28 @@ func (m *Mailbox) identityVariants() []string {
 	return variants
 }
 
-// queryMessages runs a bd list query with the given filter flag and value.
+// queryMessages lists messages matching a bd list filter flag and value,
+// from the read store when there is one.
 func (m *Mailbox) queryMessages(beadsDir, filterFlag, filterValue, status string) ([]*Message, error) {
+	if store := m.readStore(); store != nil && beadsDir == m.beadsDir {
+		opts := beads.MessageListOptions{Status: status}
+		if filterFlag == "--label" {
+			opts.Label = filterValue
+		} else {
+			opts.Assignee = filterValue
+		}
+		issues, err := store.ListMessages(opts)
+		if err != nil {
+			return nil, err
+		}
+		var messages []*Message
+		for _, issue := range issues {
+			bm := beadsMessageFromIssue(issue)
+			messages = append(messages, bm.ToMessage())
+		}
+		return messages, nil
+	}
+
 	args := []string{"list",
 		"--type", "message",
 		filterFlag, filterValue,
@@ -271,6 +403,25 @@ func (m *Mailbox) Get(id string) (*Message, error) {
 	return m.getBeads(id)
 }
 
+// beadsMessageFromIssue converts an issue read through a beads.Store.
+func beadsMessageFromIssue(issue *beads.Issue) BeadsMessage {
+	bm := BeadsMessage{
+		ID:          issue.ID,
+		Title:       issue.Title,
+		Description: issue.Description,
+		Assignee:    issue.Assignee,
+		Priority:    issue.Priority,
+		Status:      issue.Status,
+		Labels:      issue.Labels,
+		Pinned:      issue.Pinned,
+		Wisp:        issue.Wisp,
+	}
+	if t, err := time.Parse(time.RFC3339Nano, issue.CreatedAt); err == nil {
+		bm.CreatedAt = t
+	}
+	return bm
+}
+
 func (m *Mailbox) getBeads(id string) (*Message, error) {
 	// Single DB query - wisps and persistent messages in same store
 	return m.getFromDir(id, m.beadsDir)
@@ -278,6 +429,18 @@ func (m *Mailbox) getBeads(id string) (*Message, error) {
 
 // getFromDir retrieves a message from a beads directory.
 func (m *Mailbox) getFromDir(id, beadsDir string) (*Message, error) {
+	if store := m.readStore(); store != nil && beadsDir == m.beadsDir {
+		issue, err := store.Show(id)
+		if err != nil {
+			if errors.Is(err, beads.ErrNotFound) {
+				return nil, ErrMessageNotFound
+			}
+			return nil, err
+		}
+		bm := beadsMessageFromIssue(issue)
+		return bm.ToMessage(), nil
+	}
+
 	args := []string{"show", id, "--json"}
 
 	stdout, err := runBdCommand(args, m.workDir, beadsDir)
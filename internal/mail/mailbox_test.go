@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/speaker20/whaletown/internal/beads"
 )
 
 func TestNewMailbox(t *testing.T) {
@@ -507,3 +509,37 @@ func TestMailboxLegacyMarkReadTwice(t *testing.T) {
 	}
 }
 
+func TestMailboxBeadsReadsFromStore(t *testing.T) {
+	// No bd on PATH: every read must come from the store
+	t.Setenv("PATH", t.TempDir())
+
+	store := beads.NewMemStore("hq")
+	store.Put(&beads.Issue{ID: "hq-1", Title: "Direct", Description: "hi", Status: "open", Type: "message",
+		Assignee: "whaletown/witness", Priority: 1, CreatedAt: "2026-01-02T03:04:05Z", Labels: []string{"from:mayor/", "thread:t-1"}})
+	store.Put(&beads.Issue{ID: "hq-2", Title: "Copied", Status: "open", Type: "message",
+		Assignee: "mayor/", CreatedAt: "2026-01-02T03:05:05Z", Labels: []string{"from:deacon/", "cc:whaletown/witness"}})
+	store.Put(&beads.Issue{ID: "hq-3", Title: "Old", Status: "closed", Type: "message", Assignee: "whaletown/witness"})
+
+	dir := t.TempDir()
+	m := NewMailboxWithBeadsDir("whaletown/witness", dir, dir)
+	m.SetStore(store)
+
+	msgs, err := m.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("List() returned %d messages, want 2", len(msgs))
+	}
+
+	msg, err := m.Get("hq-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if msg.From != "mayor/" || msg.ThreadID != "t-1" || msg.Priority != PriorityHigh || msg.Timestamp.IsZero() {
+		t.Errorf("Get() = %+v", msg)
+	}
+	if _, err := m.Get("hq-missing"); err != ErrMessageNotFound {
+		t.Errorf("Get(missing) error = %v, want ErrMessageNotFound", err)
+	}
+}
@@ -0,0 +1,102 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Protocol messages (MERGED, POLECAT_DONE, ...) carry their data twice: as
+// human-readable text, and as a payload line at the end of the body:
+//
+//	wt-payload: {"type":"MERGED","version":1,"payload":{"branch":"polecat/nux/gt-abc",...}}
+//
+// The payload line is one line of JSON, so fields containing newlines and
+// edits to the human text can't break it. Readers use the payload when
+// present and fall back to the text for mail sent before it existed.
+
+// PayloadMarker starts the payload line of a protocol message body.
+const PayloadMarker = "wt-payload: "
+
+// PayloadVersion is the payload schema version written by this build.
+// Readers reject payloads with a newer version.
+const PayloadVersion = 1
+
+// ErrPayloadVersion is returned for a payload newer than PayloadVersion.
+var ErrPayloadVersion = errors.New("unsupported payload version")
+
+// Envelope is the typed, versioned payload of a protocol message.
+type Envelope struct {
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// AttachPayload appends a payload line for payload to body, replacing any
+// payload line body already has.
+func AttachPayload(body, msgType string, payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encoding %s payload: %w", msgType, err)
+	}
+	line, err := json.Marshal(Envelope{Type: msgType, Version: PayloadVersion, Payload: data})
+	if err != nil {
+		return "", fmt.Errorf("encoding %s payload: %w", msgType, err)
+	}
+	text := strings.TrimRight(StripPayload(body), "\n")
+	if text != "" {
+		text += "\n\n"
+	}
+	return text + PayloadMarker + string(line) + "\n", nil
+}
+
+// ReadEnvelope returns the payload of a message body, or nil if the body
+// has no payload line. A malformed payload, or one from a newer schema
+// version, is an error.
+func ReadEnvelope(body string) (*Envelope, error) {
+	line, ok := payloadLine(body)
+	if !ok {
+		return nil, nil
+	}
+	var env Envelope
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, PayloadMarker)), &env); err != nil {
+		return nil, fmt.Errorf("parsing payload: %w", err)
+	}
+	if env.Type == "" || env.Version < 1 {
+		return nil, fmt.Errorf("parsing payload: missing type or version")
+	}
+	if env.Version > PayloadVersion {
+		return nil, fmt.Errorf("%w %d for %s (max %d)", ErrPayloadVersion, env.Version, env.Type, PayloadVersion)
+	}
+	return &env, nil
+}
+
+// Decode unmarshals the payload into v.
+func (e *Envelope) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("decoding %s payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// StripPayload returns body without its payload line.
+func StripPayload(body string) string {
+	line, ok := payloadLine(body)
+	if !ok {
+		return body
+	}
+	i := strings.LastIndex(body, line)
+	return strings.TrimRight(body[:i], "\n") + body[i+len(line):]
+}
+
+// payloadLine finds the last payload line in body.
+func payloadLine(body string) (string, bool) {
+	lines := strings.Split(body, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], PayloadMarker) {
+			return strings.TrimRight(lines[i], "\r"), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+package mail
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAttachPayload_RoundTrip(t *testing.T) {
+	type merged struct {
+		Branch string `json:"branch"`
+		Error  string `json:"error"`
+	}
+	in := merged{Branch: "polecat/nux", Error: "line one\nError: injected"}
+
+	body, err := AttachPayload("Branch: polecat/nux", "MERGED", in)
+	if err != nil {
+		t.Fatalf("AttachPayload: %v", err)
+	}
+	if !strings.HasPrefix(body, "Branch: polecat/nux\n\n"+PayloadMarker) {
+		t.Errorf("body = %q, want text then payload line", body)
+	}
+
+	env, err := ReadEnvelope(body)
+	if err != nil || env == nil {
+		t.Fatalf("ReadEnvelope = %v, %v", env, err)
+	}
+	if env.Type != "MERGED" || env.Version != PayloadVersion {
+		t.Errorf("envelope = %s v%d, want MERGED v%d", env.Type, env.Version, PayloadVersion)
+	}
+	var out merged
+	if err := env.Decode(&out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if out != in {
+		t.Errorf("Decode = %+v, want %+v", out, in)
+	}
+
+	if got := StripPayload(body); got != "Branch: polecat/nux\n" {
+		t.Errorf("StripPayload = %q", got)
+	}
+}
+
+func TestAttachPayload_ReplacesExisting(t *testing.T) {
+	body, _ := AttachPayload("text", "A", map[string]int{"n": 1})
+	body, _ = AttachPayload(body, "B", map[string]int{"n": 2})
+
+	if strings.Count(body, PayloadMarker) != 1 {
+		t.Fatalf("body has %d payload lines, want 1:\n%s", strings.Count(body, PayloadMarker), body)
+	}
+	env, err := ReadEnvelope(body)
+	if err != nil || env == nil || env.Type != "B" {
+		t.Errorf("ReadEnvelope = %+v, %v; want type B", env, err)
+	}
+}
+
+func TestReadEnvelope(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantNil bool
+		wantErr error
+	}{
+		{"legacy body", "Branch: x\nIssue: wt-1", true, nil},
+		{"malformed", "text\n\n" + PayloadMarker + "{not json", true, errors.New("")},
+		{"missing type", "text\n\n" + PayloadMarker + `{"version":1,"payload":{}}`, true, errors.New("")},
+		{"newer version", "text\n\n" + PayloadMarker + `{"type":"MERGED","version":99,"payload":{}}`, true, ErrPayloadVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := ReadEnvelope(tt.body)
+			if (env == nil) != tt.wantNil {
+				t.Errorf("envelope = %+v", env)
+			}
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr != nil)
+			}
+			if errors.Is(tt.wantErr, ErrPayloadVersion) && !errors.Is(err, ErrPayloadVersion) {
+				t.Errorf("err = %v, want ErrPayloadVersion", err)
+			}
+		})
+	}
+}
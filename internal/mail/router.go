@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/beads"
 	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/federation"
 	"github.com/speaker20/whaletown/internal/session"
-	"github.com/speaker20/whaletown/internal/tmux"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
 
@@ -30,7 +32,8 @@ var ErrUnknownAnnounce = errors.New("unknown announce channel")
 type Router struct {
 	workDir  string // fallback directory to run bd commands in
 	townRoot string // town root directory (e.g., ~/gt)
-	tmux     *tmux.Tmux
+	sessions backend.SessionBackend
+	handler  ProtocolHandler // for the "handle" mail rule action
 }
 
 // NewRouter creates a new mail router.
@@ -43,7 +46,7 @@ func NewRouter(workDir string) *Router {
 	return &Router{
 		workDir:  workDir,
 		townRoot: townRoot,
-		tmux:     tmux.NewTmux(),
+		sessions: backend.New(townRoot),
 	}
 }
 
@@ -52,10 +55,16 @@ func NewRouterWithTownRoot(workDir, townRoot string) *Router {
 	return &Router{
 		workDir:  workDir,
 		townRoot: townRoot,
-		tmux:     tmux.NewTmux(),
+		sessions: backend.New(townRoot),
 	}
 }
 
+// SetProtocolHandler sets the handler that mail rules with the "handle"
+// action pass messages to.
+func (r *Router) SetProtocolHandler(h ProtocolHandler) {
+	r.handler = h
+}
+
 // isListAddress returns true if the address uses list:name syntax.
 func isListAddress(address string) bool {
 	return strings.HasPrefix(address, "list:")
@@ -498,6 +507,8 @@ func (r *Router) shouldBeWisp(msg *Message) bool {
 // Supports fan-out for:
 // - Mailing lists (list:name) - fans out to all list members
 // - @group addresses - resolves and fans out to matching agents
+// Supports federated delivery for:
+// - Peer towns (peer:rig/role) - delivered through the peer's transport
 // Supports single-copy delivery for:
 // - Queues (queue:name) - stores single message for worker claiming
 // - Announces (announce:name) - bulletin board, no claiming, retention-limited
@@ -527,6 +538,11 @@ func (r *Router) Send(msg *Message) error {
 		return r.sendToGroup(msg)
 	}
 
+	// Check for peer:rig/role address - deliver in a federated town
+	if peer, address, ok := federation.SplitAddress(msg.To); ok {
+		return r.sendToPeer(peer, address, msg)
+	}
+
 	// Single recipient - send directly
 	return r.sendToSingle(msg)
 }
@@ -567,7 +583,80 @@ func (r *Router) sendToGroup(msg *Message) error {
 }
 
 // sendToSingle sends a message to a single recipient.
+// The recipient's mailbox rules run first (see filterOnSend).
 func (r *Router) sendToSingle(msg *Message) error {
+	beadsDir := r.resolveBeadsDir(msg.To)
+	stored, outcome := r.filterOnSend(msg)
+
+	args := r.createArgs(stored)
+	if outcome.Archive || outcome.Handle {
+		args = append(args, "--json") // need the ID to archive it
+	}
+	out, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	if err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+
+	if !outcome.Empty() {
+		var created struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(out, &created)
+		stored.ID = created.ID
+
+		// Rule actions are best-effort, like notification: the message is
+		// delivered either way ('wt mail rules test' shows what would run)
+		handled := applySideEffects(outcome, stored, msg.To, r.townRoot, r, r.handler)
+		if (outcome.Archive || handled) && stored.ID != "" {
+			_, _ = runBdCommand([]string{"close", stored.ID, "--reason", "archived by mail rule"}, filepath.Dir(beadsDir), beadsDir)
+		}
+		if outcome.Archive || handled || outcome.MarkRead {
+			return nil // nothing for the recipient to be told about
+		}
+	}
+
+	// Notify recipient if they have an active session (best-effort notification)
+	// Skip notification for self-mail (handoffs to future-self don't need present-self notified)
+	// Scheduled mail is announced when the daemon releases it
+	if !isSelfMail(msg.From, msg.To) && !msg.IsScheduled(timeNow()) {
+		_ = r.notifyRecipient(msg)
+	}
+
+	return nil
+}
+
+// filterOnSend runs the recipient's mailbox rules on an outgoing message.
+// It returns the message to store, with rule labels and read/pin markers
+// applied, and the rules' outcome. Scheduled mail is filtered when it
+// reaches the inbox instead, and nothing is filtered when the town has
+// no rules for the recipient or EnvSkipRules is set.
+func (r *Router) filterOnSend(msg *Message) (*Message, *RuleOutcome) {
+	none := &RuleOutcome{}
+	if rulesDisabled() || msg.IsScheduled(timeNow()) {
+		return msg, none
+	}
+	rules, err := LoadRules(r.townRoot, msg.To)
+	if err != nil || len(rules.Rules) == 0 {
+		return msg, none
+	}
+	outcome := rules.Evaluate(msg)
+	if outcome.Empty() {
+		return msg, outcome
+	}
+
+	stored := *msg
+	stored.Labels = append(append([]string{}, msg.Labels...), outcome.Labels...)
+	stored.Labels = append(stored.Labels, LabelFiltered)
+	if outcome.MarkRead {
+		stored.Labels = append(stored.Labels, LabelRead)
+	}
+	stored.Pinned = msg.Pinned || outcome.Pin
+	return &stored, outcome
+}
+
+// createArgs builds the bd create command that stores msg as a message bead:
+// bd create <subject> --type=message --assignee=<recipient> -d <body>
+func (r *Router) createArgs(msg *Message) []string {
 	// Convert addresses to beads identities
 	toIdentity := addressToIdentity(msg.To)
 
@@ -585,8 +674,22 @@ func (r *Router) sendToSingle(msg *Message) error {
 		ccIdentity := addressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	// Scheduling labels; "scheduled" marks mail the daemon has yet to release
+	if msg.IsScheduled(timeNow()) {
+		labels = append(labels, LabelScheduled, "deliver-at:"+msg.DeliverAt.UTC().Format(time.RFC3339))
+	}
+	if msg.ExpiresAt != nil {
+		labels = append(labels, LabelExpiring, "expires-at:"+msg.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if msg.ReminderFor != "" {
+		labels = append(labels, "reminder-for:"+msg.ReminderFor)
+	}
+	// Free-form tags and markers
+	labels = append(labels, msg.Labels...)
+	if msg.Pinned {
+		labels = append(labels, LabelPinned)
+	}
 
-	// Build command: bd create <subject> --type=message --assignee=<recipient> -d <body>
 	args := []string{"create", msg.Subject,
 		"--type", "message",
 		"--assignee", toIdentity,
@@ -610,18 +713,39 @@ func (r *Router) sendToSingle(msg *Message) error {
 		args = append(args, "--ephemeral")
 	}
 
-	beadsDir := r.resolveBeadsDir(msg.To)
-	_, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	return args
+}
+
+// sendToPeer delivers a message addressed to peer:rig/role through the
+// peer town's transport. The message is stored in the peer's beads with
+// the sender qualified by this town's name, so a reply addressed to the
+// From field routes back here if the peer has registered this town under
+// that name.
+func (r *Router) sendToPeer(peer, address string, msg *Message) error {
+	registry, err := federation.Load(r.townRoot)
 	if err != nil {
-		return fmt.Errorf("sending message: %w", err)
+		return err
+	}
+	defer func() { _ = registry.Close() }()
+	if !registry.Has(peer) {
+		return fmt.Errorf("unknown peer town %q in address %s (see wt federation list)", peer, msg.To)
 	}
 
-	// Notify recipient if they have an active session (best-effort notification)
-	// Skip notification for self-mail (handoffs to future-self don't need present-self notified)
-	if !isSelfMail(msg.From, msg.To) {
-		_ = r.notifyRecipient(msg)
+	remote := *msg
+	remote.To = address
+	if _, _, qualified := federation.SplitAddress(remote.From); !qualified {
+		remote.From = federation.LocalTownName(r.townRoot) + ":" + remote.From
 	}
+	remote.CC = nil // CC identities name agents in this town, not the peer
 
+	if _, err := registry.Bd(peer, r.createArgs(&remote)...); err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+
+	// Scheduled mail is announced by the peer's daemon when it falls due
+	if session := addressToSessionID(address); session != "" && !remote.IsScheduled(timeNow()) {
+		registry.Nudge(peer, session, fmt.Sprintf("📬 You have new mail from %s. Subject: %s. Run 'wt mail inbox' to read.", remote.From, remote.Subject))
+	}
 	return nil
 }
 
@@ -961,7 +1085,7 @@ func (r *Router) GetMailbox(address string) (*Mailbox, error) {
 	return NewMailboxFromAddress(address, workDir), nil
 }
 
-// notifyRecipient sends a notification to a recipient's tmux session.
+// notifyRecipient sends a notification to a recipient's agent session.
 // Uses NudgeSession to add the notification to the agent's conversation history.
 // Supports mayor/, rig/polecat, and rig/refinery addresses.
 func (r *Router) notifyRecipient(msg *Message) error {
@@ -971,14 +1095,14 @@ func (r *Router) notifyRecipient(msg *Message) error {
 	}
 
 	// Check if session exists
-	hasSession, err := r.tmux.HasSession(sessionID)
+	hasSession, err := r.sessions.HasSession(sessionID)
 	if err != nil || !hasSession {
 		return nil // No active session, skip notification
 	}
 
 	// Send notification to the agent's conversation history
 	notification := fmt.Sprintf("📬 You have new mail from %s. Subject: %s. Run 'wt mail inbox' to read.", msg.From, msg.Subject)
-	return r.tmux.NudgeSession(sessionID, notification)
+	return r.sessions.NudgeSession(sessionID, notification)
 }
 
 // addressToSessionID converts a mail address to a tmux session ID.
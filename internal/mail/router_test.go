@@ -3,7 +3,10 @@ package mail
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/speaker20/whaletown/internal/federation"
 )
 
 func TestDetectTownRoot(t *testing.T) {
@@ -813,3 +816,63 @@ func TestExpandAnnounceNoTownRoot(t *testing.T) {
 		t.Errorf("expandAnnounce error = %v, want containing 'no town root'", err)
 	}
 }
+
+func TestSendToPeerTown(t *testing.T) {
+	// Two towns on one machine; bd records where each create lands
+	newTown := func(name string) string {
+		root := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(root, "mayor"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		town := `{"type":"town","version":1,"name":"` + name + `"}`
+		if err := os.WriteFile(filepath.Join(root, "mayor", "town.json"), []byte(town), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return root
+	}
+	home, acme := newTown("home"), newTown("acme")
+
+	bin := t.TempDir()
+	logPath := filepath.Join(bin, "bd.log")
+	script := "#!/bin/sh\necho \"$BEADS_DIR|$*\" >> " + logPath + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	registry, err := federation.Load(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add(&federation.Peer{Name: "acme", Type: federation.PeerLocal, Path: acme}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouterWithTownRoot(home, home)
+	msg := &Message{From: "whaletown/crew/joe", To: "acme:backend/witness", Subject: "API change", Body: "v2 lands Friday"}
+	if err := r.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, filepath.Join(acme, ".beads")+"|create API change") {
+		t.Errorf("message not stored in the peer's beads: %s", line)
+	}
+	for _, want := range []string{"--assignee backend/witness", "from:home:whaletown/crew/joe", "--actor home:whaletown/crew/joe"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("bd call missing %q: %s", want, line)
+		}
+	}
+	if msg.To != "acme:backend/witness" || msg.From != "whaletown/crew/joe" {
+		t.Errorf("Send modified the caller's message: %+v", msg)
+	}
+
+	msg = &Message{From: "mayor/", To: "nowhere:backend/witness", Subject: "x"}
+	if err := r.Send(msg); err == nil || !strings.Contains(err.Error(), "unknown peer town") {
+		t.Errorf("unknown peer error = %v", err)
+	}
+}
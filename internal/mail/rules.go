@@ -0,0 +1,360 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/speaker20/whaletown/internal/config"
+)
+
+// Marker labels kept by the mail system itself.
+const (
+	LabelRead      = "read"
+	LabelPinned    = "pinned"
+	LabelFiltered  = "filtered"  // mailbox rules have run on the message
+	LabelForwarded = "forwarded" // the message is a rule-forwarded copy
+	LabelEmailed   = "emailed"   // the email gateway has sent the message
+)
+
+// isMarkerLabel reports whether a label is one of the mail system's own
+// markers rather than a free-form tag.
+func isMarkerLabel(label string) bool {
+	switch label {
+	case LabelRead, LabelPinned, LabelFiltered, LabelForwarded, LabelEmailed, LabelScheduled, LabelExpiring:
+		return true
+	}
+	return false
+}
+
+// Mail rule actions (see config.MailRule).
+const (
+	RuleActionLabel    = "label"
+	RuleActionMarkRead = "mark-read"
+	RuleActionPin      = "pin"
+	RuleActionArchive  = "archive"
+	RuleActionForward  = "forward"
+	RuleActionEscalate = "escalate"
+	RuleActionHandle   = "handle"
+)
+
+// EnvSkipRules disables mailbox rules for a process. It is set for the
+// 'wt escalate' runs that rules start, so an escalation's own mail can't
+// trigger another escalation.
+const EnvSkipRules = "WT_MAIL_SKIP_RULES"
+
+// ProtocolHandler processes protocol messages (MERGED, MERGE_READY, ...).
+// *protocol.HandlerRegistry implements it.
+type ProtocolHandler interface {
+	ProcessProtocolMessage(msg *Message) (bool, error)
+}
+
+// Rule is a compiled mailbox filter rule.
+type Rule struct {
+	*config.MailRule
+	subject *regexp.Regexp
+}
+
+// RuleSet holds the filter rules that apply to one mailbox.
+type RuleSet struct {
+	Address string
+	Rules   []*Rule
+}
+
+// LoadRules returns the rules from the town settings that apply to the
+// mailbox at address: the "*" rules, then those under keys matching the
+// address. A town without rules yields an empty set.
+func LoadRules(townRoot, address string) (*RuleSet, error) {
+	set := &RuleSet{Address: address}
+	if townRoot == "" {
+		return set, nil
+	}
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading mail rules: %w", err)
+	}
+	if len(settings.MailRules) == 0 {
+		return set, nil
+	}
+
+	addr := strings.TrimSuffix(address, "/")
+	var keys []string
+	if _, ok := settings.MailRules["*"]; ok {
+		keys = append(keys, "*")
+	}
+	for key := range settings.MailRules {
+		if key != "*" && matchPattern(strings.TrimSuffix(key, "/"), addr) {
+			keys = append(keys, key)
+		}
+	}
+	sortRuleKeys(keys)
+
+	for _, key := range keys {
+		for i, rule := range settings.MailRules[key] {
+			compiled, err := CompileRule(rule)
+			if err != nil {
+				return nil, fmt.Errorf("mail_rules[%q][%d]: %w", key, i, err)
+			}
+			set.Rules = append(set.Rules, compiled)
+		}
+	}
+	return set, nil
+}
+
+// sortRuleKeys orders rule keys "*" first, then wildcard keys, then exact
+// addresses, so the most specific rules run last.
+func sortRuleKeys(keys []string) {
+	rank := func(key string) int {
+		switch {
+		case key == "*":
+			return 0
+		case strings.Contains(key, "*"):
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if rank(keys[i]) != rank(keys[j]) {
+			return rank(keys[i]) < rank(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// CompileRule validates a rule and compiles its subject pattern.
+func CompileRule(rule *config.MailRule) (*Rule, error) {
+	if rule == nil {
+		return nil, fmt.Errorf("empty rule")
+	}
+	compiled := &Rule{MailRule: rule}
+	if rule.Match.Subject != "" {
+		re, err := regexp.Compile(rule.Match.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subject pattern: %w", err)
+		}
+		compiled.subject = re
+	}
+	if rule.Match.Priority != "" {
+		if _, ok := parseRulePriority(rule.Match.Priority); !ok {
+			return nil, fmt.Errorf("invalid priority %q", rule.Match.Priority)
+		}
+	}
+	if len(rule.Actions) == 0 {
+		return nil, fmt.Errorf("rule has no actions")
+	}
+	for _, action := range rule.Actions {
+		if err := validateRuleAction(action); err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
+
+func validateRuleAction(action string) error {
+	name, arg, _ := strings.Cut(action, ":")
+	switch name {
+	case RuleActionLabel, RuleActionForward:
+		if arg == "" {
+			return fmt.Errorf("action %q needs an argument (%s:<value>)", action, name)
+		}
+		if name == RuleActionLabel && (strings.Contains(arg, ":") || strings.Contains(arg, ",") || isMarkerLabel(arg)) {
+			return fmt.Errorf("action %q: label must be a plain tag", action)
+		}
+	case RuleActionEscalate:
+		if arg != "" && !config.IsValidSeverity(arg) {
+			return fmt.Errorf("action %q: invalid severity", action)
+		}
+	case RuleActionMarkRead, RuleActionPin, RuleActionArchive, RuleActionHandle:
+		if arg != "" {
+			return fmt.Errorf("action %q takes no argument", action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+	return nil
+}
+
+// parseRulePriority maps a rule's priority condition to a Priority.
+func parseRulePriority(s string) (Priority, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > 4 {
+			return "", false
+		}
+		return PriorityFromInt(n), true
+	}
+	switch p := Priority(strings.ToLower(s)); p {
+	case PriorityUrgent, PriorityHigh, PriorityNormal, PriorityLow:
+		return p, true
+	}
+	return "", false
+}
+
+// Matches reports whether msg meets all of the rule's conditions.
+func (r *Rule) Matches(msg *Message) bool {
+	m := r.Match
+	if m.From != "" && !matchPattern(strings.TrimSuffix(m.From, "/"), strings.TrimSuffix(msg.From, "/")) {
+		return false
+	}
+	if r.subject != nil && !r.subject.MatchString(msg.Subject) {
+		return false
+	}
+	if m.Type != "" && !strings.EqualFold(m.Type, string(msg.Type)) {
+		return false
+	}
+	if m.Priority != "" {
+		if p, _ := parseRulePriority(m.Priority); p != msg.Priority {
+			return false
+		}
+	}
+	if m.Label != "" && !containsLabel(msg.Labels, m.Label) {
+		return false
+	}
+	return true
+}
+
+// RuleOutcome is the combined effect of the rules a message matched.
+type RuleOutcome struct {
+	Matched  []string `json:"matched,omitempty"` // names (or positions) of matching rules
+	Labels   []string `json:"labels,omitempty"`
+	MarkRead bool     `json:"mark_read,omitempty"`
+	Pin      bool     `json:"pin,omitempty"`
+	Archive  bool     `json:"archive,omitempty"`
+	Forward  []string `json:"forward,omitempty"`
+	Escalate string   `json:"escalate,omitempty"` // severity, empty for none
+	Handle   bool     `json:"handle,omitempty"`
+
+	escalateRule string
+}
+
+// Empty reports whether no rule matched.
+func (o *RuleOutcome) Empty() bool {
+	return len(o.Matched) == 0
+}
+
+// Evaluate runs the rules against msg without side effects.
+func (s *RuleSet) Evaluate(msg *Message) *RuleOutcome {
+	out := &RuleOutcome{}
+	for i, rule := range s.Rules {
+		if !rule.Matches(msg) {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		out.Matched = append(out.Matched, name)
+		for _, action := range rule.Actions {
+			kind, arg, _ := strings.Cut(action, ":")
+			switch kind {
+			case RuleActionLabel:
+				if !containsLabel(out.Labels, arg) && !containsLabel(msg.Labels, arg) {
+					out.Labels = append(out.Labels, arg)
+				}
+			case RuleActionMarkRead:
+				out.MarkRead = true
+			case RuleActionPin:
+				out.Pin = true
+			case RuleActionArchive:
+				out.Archive = true
+			case RuleActionForward:
+				// Forwarded copies are not forwarded again
+				if !containsLabel(msg.Labels, LabelForwarded) && !containsLabel(out.Forward, arg) {
+					out.Forward = append(out.Forward, arg)
+				}
+			case RuleActionEscalate:
+				if arg == "" {
+					arg = config.SeverityMedium
+				}
+				if out.Escalate == "" || severityRank(arg) > severityRank(out.Escalate) {
+					out.Escalate = arg
+					out.escalateRule = name
+				}
+			case RuleActionHandle:
+				out.Handle = true
+			}
+		}
+		if rule.Stop {
+			break
+		}
+	}
+	return out
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case config.SeverityCritical:
+		return 3
+	case config.SeverityHigh:
+		return 2
+	case config.SeverityMedium:
+		return 1
+	}
+	return 0
+}
+
+// rulesDisabled reports whether this process runs without mailbox rules.
+func rulesDisabled() bool {
+	return os.Getenv(EnvSkipRules) != ""
+}
+
+// forwardCopy builds the copy of msg that a forward action sends.
+func forwardCopy(msg *Message, to, mailbox string) *Message {
+	return &Message{
+		From:     msg.From,
+		To:       to,
+		Subject:  msg.Subject,
+		Body:     fmt.Sprintf("[Forwarded from %s by mail rule]\n\n%s", mailbox, msg.Body),
+		Priority: msg.Priority,
+		Type:     msg.Type,
+		ThreadID: msg.ThreadID,
+		Labels:   []string{LabelForwarded},
+	}
+}
+
+// runEscalation raises an escalation for msg via 'wt escalate'. It is a
+// variable so tests can replace it.
+var runEscalation = func(townRoot, severity, rule string, msg *Message) error {
+	args := []string{"escalate", msg.Subject,
+		"--severity", severity,
+		"--source", "mail-rule:" + rule,
+		"--reason", fmt.Sprintf("Mail %s from %s matched rule %s", msg.ID, msg.From, rule),
+	}
+	cmd := exec.Command("wt", args...) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = townRoot
+	cmd.Env = append(os.Environ(), EnvSkipRules+"=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("escalating: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// applySideEffects runs the actions of an outcome that reach beyond the
+// message itself: forwards, escalations and the protocol handler. They
+// are best-effort. It reports whether the handler handled the message.
+func applySideEffects(out *RuleOutcome, msg *Message, mailbox, townRoot string, router *Router, handler ProtocolHandler) bool {
+	for _, to := range out.Forward {
+		_ = router.Send(forwardCopy(msg, to, mailbox))
+	}
+	if out.Escalate != "" {
+		_ = runEscalation(townRoot, out.Escalate, out.escalateRule, msg)
+	}
+	if out.Handle && handler != nil {
+		handled, err := handler.ProcessProtocolMessage(msg)
+		return handled && err == nil
+	}
+	return false
+}
+
+// containsLabel reports whether labels holds label.
+func containsLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/config"
+)
+
+// writeMailRules writes town settings holding rules into a new town root.
+func writeMailRules(t *testing.T, rules map[string][]*config.MailRule) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "mayor", "town.json"), []byte(`{"type":"town","version":1,"name":"test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	settings := config.NewTownSettings()
+	settings.MailRules = rules
+	if err := config.SaveTownSettings(config.TownSettingsPath(root), settings); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestCompileRule(t *testing.T) {
+	valid := []*config.MailRule{
+		{Actions: []string{"archive"}},
+		{Match: config.MailRuleMatch{Subject: "^MERGED", Priority: "high"}, Actions: []string{"label:protocol", "mark-read"}},
+		{Match: config.MailRuleMatch{Priority: "0"}, Actions: []string{"escalate:critical", "forward:mayor/", "pin", "handle"}},
+		{Actions: []string{"escalate"}},
+	}
+	for i, rule := range valid {
+		if _, err := CompileRule(rule); err != nil {
+			t.Errorf("valid rule %d: %v", i, err)
+		}
+	}
+
+	invalid := []*config.MailRule{
+		nil,
+		{},
+		{Match: config.MailRuleMatch{Subject: "("}, Actions: []string{"archive"}},
+		{Match: config.MailRuleMatch{Priority: "9"}, Actions: []string{"archive"}},
+		{Actions: []string{"delete"}},
+		{Actions: []string{"label:"}},
+		{Actions: []string{"label:from:x"}},
+		{Actions: []string{"label:read"}},
+		{Actions: []string{"forward"}},
+		{Actions: []string{"escalate:urgent"}},
+		{Actions: []string{"archive:now"}},
+	}
+	for i, rule := range invalid {
+		if _, err := CompileRule(rule); err == nil {
+			t.Errorf("invalid rule %d should not compile: %+v", i, rule)
+		}
+	}
+}
+
+func TestRuleSetEvaluate(t *testing.T) {
+	compile := func(rule *config.MailRule) *Rule {
+		t.Helper()
+		r, err := CompileRule(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	set := &RuleSet{Address: "mayor/", Rules: []*Rule{
+		compile(&config.MailRule{Name: "protocol", Match: config.MailRuleMatch{Subject: "^(POLECAT_DONE|MERGED)"}, Actions: []string{"label:protocol", "mark-read"}}),
+		compile(&config.MailRule{Name: "witness-urgent", Match: config.MailRuleMatch{From: "*/witness", Priority: "urgent"}, Actions: []string{"escalate", "pin"}}),
+		compile(&config.MailRule{Name: "tagged", Match: config.MailRuleMatch{Label: "protocol"}, Actions: []string{"archive"}, Stop: true}),
+		compile(&config.MailRule{Name: "after-stop", Actions: []string{"forward:deacon/"}}),
+	}}
+
+	out := set.Evaluate(&Message{From: "greenplace/polecats/Toast", Subject: "POLECAT_DONE Toast", Priority: PriorityNormal})
+	if strings.Join(out.Matched, ",") != "protocol,after-stop" {
+		t.Errorf("Matched = %v", out.Matched)
+	}
+	if !out.MarkRead || strings.Join(out.Labels, ",") != "protocol" || out.Archive {
+		t.Errorf("outcome = %+v", out)
+	}
+	if strings.Join(out.Forward, ",") != "deacon/" {
+		t.Errorf("Forward = %v", out.Forward)
+	}
+
+	// A label already on the message matches the label rule, which stops
+	out = set.Evaluate(&Message{From: "greenplace/witness", Subject: "Help", Priority: PriorityUrgent, Labels: []string{"protocol"}})
+	if strings.Join(out.Matched, ",") != "witness-urgent,tagged" {
+		t.Errorf("Matched = %v", out.Matched)
+	}
+	if out.Escalate != config.SeverityMedium || !out.Pin || !out.Archive || len(out.Forward) != 0 {
+		t.Errorf("outcome = %+v", out)
+	}
+
+	// Forwarded copies are not forwarded again
+	out = set.Evaluate(&Message{From: "mayor/", Subject: "hi", Labels: []string{LabelForwarded}})
+	if len(out.Forward) != 0 {
+		t.Errorf("forwarded copy forwarded again: %v", out.Forward)
+	}
+
+	if out := set.Evaluate(&Message{From: "greenplace/witness", Subject: "Help", Priority: PriorityHigh}); out.Escalate != "" {
+		t.Errorf("priority mismatch should not escalate: %+v", out)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	root := writeMailRules(t, map[string][]*config.MailRule{
+		"greenplace/witness": {{Name: "exact", Actions: []string{"pin"}}},
+		"*/witness":          {{Name: "wildcard", Actions: []string{"mark-read"}}},
+		"*":                  {{Name: "all", Actions: []string{"label:seen"}}},
+		"mayor/":             {{Name: "mayor", Actions: []string{"archive"}}},
+	})
+
+	set, err := LoadRules(root, "greenplace/witness")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range set.Rules {
+		names = append(names, r.Name)
+	}
+	if strings.Join(names, ",") != "all,wildcard,exact" {
+		t.Errorf("rules = %v, want all,wildcard,exact", names)
+	}
+
+	set, err = LoadRules(root, "mayor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Rules) != 2 || set.Rules[1].Name != "mayor" {
+		t.Errorf("mayor rules = %d", len(set.Rules))
+	}
+
+	if set, err := LoadRules(t.TempDir(), "mayor/"); err != nil || len(set.Rules) != 0 {
+		t.Errorf("town without settings: %v, %v", set, err)
+	}
+
+	bad := writeMailRules(t, map[string][]*config.MailRule{"mayor/": {{Actions: []string{"explode"}}}})
+	if _, err := LoadRules(bad, "mayor/"); err == nil || !strings.Contains(err.Error(), `mail_rules["mayor/"][0]`) {
+		t.Errorf("bad rule error = %v", err)
+	}
+}
+
+// stubBd installs a bd that logs its arguments and answers create with an
+// issue ID and list with listJSON.
+func stubBd(t *testing.T, listJSON string) string {
+	t.Helper()
+	bin := t.TempDir()
+	logPath := filepath.Join(bin, "bd.log")
+	if err := os.WriteFile(filepath.Join(bin, "list.json"), []byte(listJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := `#!/bin/sh
+echo "$*" >> ` + logPath + `
+case "$1" in
+  create) echo '{"id":"hq-new"}' ;;
+  list) cat ` + filepath.Join(bin, "list.json") + ` ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSendAppliesRules(t *testing.T) {
+	root := writeMailRules(t, map[string][]*config.MailRule{
+		"*/witness": {
+			{Match: config.MailRuleMatch{Subject: "^MERGED"}, Actions: []string{"label:protocol", "archive"}},
+			{Match: config.MailRuleMatch{Subject: "^LIFECYCLE"}, Actions: []string{"mark-read", "forward:deacon/"}},
+		},
+	})
+	logPath := stubBd(t, "[]")
+	r := NewRouterWithTownRoot(root, root)
+
+	if err := r.Send(&Message{From: "greenplace/refinery", To: "greenplace/witness", Subject: "MERGED Toast"}); err != nil {
+		t.Fatal(err)
+	}
+	log := readLog(t, logPath)
+	if !strings.Contains(log, "protocol") || !strings.Contains(log, LabelFiltered) || !strings.Contains(log, "--json") {
+		t.Errorf("create should carry rule labels and request JSON:\n%s", log)
+	}
+	if !strings.Contains(log, "close hq-new") {
+		t.Errorf("archived message not closed:\n%s", log)
+	}
+
+	if err := os.Remove(logPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Send(&Message{From: "greenplace/polecats/Toast", To: "greenplace/witness", Subject: "LIFECYCLE cycle"}); err != nil {
+		t.Fatal(err)
+	}
+	// The forwarded body spans lines, so split on bd calls
+	calls := strings.Split(strings.TrimPrefix(readLog(t, logPath), "create "), "\ncreate ")
+	if len(calls) != 2 {
+		t.Fatalf("want the message and one forwarded copy, got %d calls", len(calls))
+	}
+	if !strings.Contains(calls[0], ",read") || strings.Contains(calls[0], "close") {
+		t.Errorf("message should be stored read: %s", calls[0])
+	}
+	if !strings.Contains(calls[1], "--assignee deacon/") || !strings.Contains(calls[1], LabelForwarded) {
+		t.Errorf("forwarded copy: %s", calls[1])
+	}
+
+	// Mail to a mailbox without rules is stored untouched
+	if err := os.Remove(logPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Send(&Message{From: "mayor/", To: "greenplace/refinery", Subject: "MERGED Toast"}); err != nil {
+		t.Fatal(err)
+	}
+	if log := readLog(t, logPath); strings.Contains(log, LabelFiltered) || strings.Contains(log, "--json") {
+		t.Errorf("unfiltered send changed:\n%s", log)
+	}
+}
+
+func TestListAppliesRules(t *testing.T) {
+	root := writeMailRules(t, map[string][]*config.MailRule{
+		"mayor/": {
+			{Match: config.MailRuleMatch{Subject: "^POLECAT_DONE"}, Actions: []string{"archive"}},
+			{Match: config.MailRuleMatch{From: "overseer"}, Actions: []string{"pin", "label:human"}},
+		},
+	})
+	logPath := stubBd(t, `[
+{"id":"hq-done","title":"POLECAT_DONE Toast","assignee":"mayor/","status":"open","labels":["from:greenplace/polecats/Toast"]},
+{"id":"hq-human","title":"Plans","assignee":"mayor/","status":"open","labels":["from:overseer"]},
+{"id":"hq-seen","title":"POLECAT_DONE Nux","assignee":"mayor/","status":"open","labels":["from:greenplace/polecats/Nux","filtered"]},
+{"id":"hq-plain","title":"Hello","assignee":"mayor/","status":"open","labels":["from:deacon/"]}
+]`)
+
+	m := NewMailboxFromAddress("mayor/", root)
+	msgs, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]*Message{}
+	for _, msg := range msgs {
+		ids[msg.ID] = msg
+	}
+	if ids["hq-done"] != nil {
+		t.Error("archived message still listed")
+	}
+	if human := ids["hq-human"]; human == nil || !human.Pinned || !containsLabel(human.Labels, "human") || !human.RulesApplied() {
+		t.Errorf("hq-human = %+v", human)
+	}
+	if ids["hq-seen"] == nil || ids["hq-plain"] == nil {
+		t.Errorf("messages missing: %v", ids)
+	}
+
+	log := readLog(t, logPath)
+	for _, want := range []string{"close hq-done", "label add hq-human pinned", "label add hq-human human", "label add hq-human filtered"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("bd log missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "hq-seen") {
+		t.Errorf("already-filtered message was touched:\n%s", log)
+	}
+	if strings.Contains(log, "label add hq-plain") {
+		t.Errorf("unmatched message was labelled:\n%s", log)
+	}
+
+	t.Setenv(EnvSkipRules, "1")
+	if err := os.Remove(logPath); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err = m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 4 || strings.Contains(readLog(t, logPath), "label add") {
+		t.Errorf("rules ran with %s set", EnvSkipRules)
+	}
+}
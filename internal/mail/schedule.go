@@ -0,0 +1,162 @@
+package mail
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Labels that mark messages the daemon's scheduling pass has to revisit.
+const (
+	// LabelScheduled marks a message held back until its deliver-at time.
+	// The daemon removes it when it releases the message.
+	LabelScheduled = "scheduled"
+
+	// LabelExpiring marks a message with an expires-at time.
+	LabelExpiring = "expiring"
+)
+
+// ParseDeliverAt parses a delivery time: an RFC 3339 timestamp, a local
+// "YYYY-MM-DD HH:MM", or a local "HH:MM" (the next time the clock shows
+// it).
+func ParseDeliverAt(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use HH:MM, \"YYYY-MM-DD HH:MM\" or RFC 3339)", s)
+}
+
+// ParseDelay parses a positive duration, allowing a d suffix for days
+// ("90m", "2h", "3d").
+func ParseDelay(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid duration %q (use e.g. 30m, 2h or 3d)", s)
+}
+
+// ScheduleResult reports what a scheduling pass did, by message ID.
+type ScheduleResult struct {
+	Released []string `json:"released,omitempty"` // due messages delivered
+	Dropped  []string `json:"dropped,omitempty"`  // reminders whose bead was closed
+	Expired  []string `json:"expired,omitempty"`  // unread messages archived
+}
+
+// ProcessScheduled releases scheduled messages that are due as of now and
+// notifies their recipients, and archives unread messages that have
+// expired. A due reminder whose bead is already closed is archived
+// unread instead of delivered. Pinned messages never expire.
+//
+// Failures on individual messages are collected; the pass carries on.
+func (r *Router) ProcessScheduled(now time.Time) (*ScheduleResult, error) {
+	beadsDir := r.resolveBeadsDir("")
+	workDir := filepath.Dir(beadsDir)
+	result := &ScheduleResult{}
+	var errs []string
+
+	scheduled, err := listByLabel(LabelScheduled, workDir, beadsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, bm := range scheduled {
+		msg := bm.ToMessage()
+		if msg.IsScheduled(now) {
+			continue
+		}
+		if msg.ReminderFor != "" && beadClosed(msg.ReminderFor, workDir, beadsDir) {
+			if _, err := runBdCommand([]string{"close", msg.ID, "--reason", "reminder target " + msg.ReminderFor + " closed"}, workDir, beadsDir); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", msg.ID, err))
+				continue
+			}
+			result.Dropped = append(result.Dropped, msg.ID)
+			continue
+		}
+		if _, err := runBdCommand([]string{"label", "remove", msg.ID, LabelScheduled}, workDir, beadsDir); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", msg.ID, err))
+			continue
+		}
+		// Unlike at send time, self-mail is announced: it is a reminder
+		_ = r.notifyRecipient(msg)
+		result.Released = append(result.Released, msg.ID)
+	}
+
+	expiring, err := listByLabel(LabelExpiring, workDir, beadsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, bm := range expiring {
+		msg := bm.ToMessage()
+		if !msg.IsExpired(now) || msg.Read || bm.Pinned {
+			continue
+		}
+		if _, err := runBdCommand([]string{"close", msg.ID, "--reason", "expired unread"}, workDir, beadsDir); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", msg.ID, err))
+			continue
+		}
+		result.Expired = append(result.Expired, msg.ID)
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("scheduling pass: %s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// listByLabel returns the open messages carrying a label.
+func listByLabel(label, workDir, beadsDir string) ([]*BeadsMessage, error) {
+	stdout, err := runBdCommand([]string{"list",
+		"--type", "message",
+		"--label", label,
+		"--status", "open",
+		"--json",
+	}, workDir, beadsDir)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s messages: %w", label, err)
+	}
+	if len(stdout) == 0 || string(stdout) == "null" {
+		return nil, nil
+	}
+	var msgs []*BeadsMessage
+	if err := json.Unmarshal(stdout, &msgs); err != nil {
+		return nil, fmt.Errorf("parsing %s messages: %w", label, err)
+	}
+	return msgs, nil
+}
+
+// beadClosed reports whether a bead is closed. A bead that cannot be
+// found counts as closed, so reminders about deleted work are dropped.
+func beadClosed(id, workDir, beadsDir string) bool {
+	stdout, err := runBdCommand([]string{"show", id, "--json"}, workDir, beadsDir)
+	if err != nil {
+		if bdErr, ok := err.(*bdError); ok && bdErr.ContainsError("not found") {
+			return true
+		}
+		return false
+	}
+	var issues []struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(stdout, &issues); err != nil || len(issues) == 0 {
+		return false
+	}
+	return issues[0].Status == "closed"
+}
@@ -0,0 +1,184 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDeliverAt(t *testing.T) {
+	loc := time.FixedZone("test", 2*3600)
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, loc)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-03-11T08:00:00Z", time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC)},
+		{"2026-03-12 09:15", time.Date(2026, 3, 12, 9, 15, 0, 0, loc)},
+		{"16:30", time.Date(2026, 3, 10, 16, 30, 0, 0, loc)},
+		{"09:00", time.Date(2026, 3, 11, 9, 0, 0, 0, loc)}, // already past today
+		{"14:00", time.Date(2026, 3, 11, 14, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDeliverAt(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseDeliverAt(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDeliverAt(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "tomorrow", "25:00", "2h"} {
+		if _, err := ParseDeliverAt(bad, now); err == nil {
+			t.Errorf("ParseDeliverAt(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseDelay(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30m":   30 * time.Minute,
+		"2h":    2 * time.Hour,
+		"1h30m": 90 * time.Minute,
+		"3d":    72 * time.Hour,
+	}
+	for in, want := range tests {
+		got, err := ParseDelay(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDelay(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "0s", "-1h", "0d", "xd", "soon"} {
+		if _, err := ParseDelay(bad); err == nil {
+			t.Errorf("ParseDelay(%q) should fail", bad)
+		}
+	}
+}
+
+func TestScheduleLabelsRoundTrip(t *testing.T) {
+	deliver := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	expires := deliver.Add(4 * time.Hour)
+	msg := &Message{
+		From:        "greenplace/Toast",
+		To:          "greenplace/witness",
+		Subject:     "Check merge",
+		DeliverAt:   &deliver,
+		ExpiresAt:   &expires,
+		ReminderFor: "gt-abc",
+	}
+
+	r := &Router{}
+	args := r.createArgs(msg)
+	var labels []string
+	for i, a := range args {
+		if a == "--labels" {
+			labels = strings.Split(args[i+1], ",")
+		}
+	}
+	for _, want := range []string{LabelScheduled, LabelExpiring, "deliver-at:" + deliver.Format(time.RFC3339), "expires-at:" + expires.Format(time.RFC3339), "reminder-for:gt-abc"} {
+		if !containsLabel(labels, want) {
+			t.Errorf("labels %v missing %q", labels, want)
+		}
+	}
+
+	got := (&BeadsMessage{ID: "hq-1", Labels: labels}).ToMessage()
+	if got.DeliverAt == nil || !got.DeliverAt.Equal(deliver) {
+		t.Errorf("DeliverAt = %v, want %v", got.DeliverAt, deliver)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(expires) {
+		t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, expires)
+	}
+	if got.ReminderFor != "gt-abc" {
+		t.Errorf("ReminderFor = %q", got.ReminderFor)
+	}
+	if !got.IsScheduled(time.Now()) || got.IsScheduled(deliver) {
+		t.Error("IsScheduled should hold until deliver-at")
+	}
+	if got.IsExpired(deliver) || !got.IsExpired(expires) {
+		t.Error("IsExpired should start at expires-at")
+	}
+
+	// Mail due now is not marked scheduled
+	past := time.Now().Add(-time.Minute)
+	msg = &Message{From: "mayor/", To: "greenplace/witness", Subject: "x", DeliverAt: &past}
+	if strings.Contains(strings.Join(r.createArgs(msg), " "), LabelScheduled) {
+		t.Error("mail already due should not be labelled scheduled")
+	}
+}
+
+func TestProcessScheduled(t *testing.T) {
+	town := t.TempDir()
+	bin := t.TempDir()
+	logPath := filepath.Join(bin, "bd.log")
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour).Format(time.RFC3339)
+	future := now.Add(time.Hour).Format(time.RFC3339)
+
+	scheduled := `[
+{"id":"hq-due","title":"due","assignee":"greenplace/witness","status":"open","labels":["from:mayor/","scheduled","deliver-at:` + past + `"]},
+{"id":"hq-later","title":"later","assignee":"greenplace/witness","status":"open","labels":["from:mayor/","scheduled","deliver-at:` + future + `"]},
+{"id":"hq-moot","title":"moot","assignee":"mayor/","status":"open","labels":["from:mayor/","scheduled","deliver-at:` + past + `","reminder-for:hq-esc1"]}
+]`
+	expiring := `[
+{"id":"hq-stale","title":"stale","assignee":"greenplace/witness","status":"open","labels":["from:mayor/","expiring","expires-at:` + past + `"]},
+{"id":"hq-read","title":"read","assignee":"greenplace/witness","status":"open","labels":["from:mayor/","expiring","read","expires-at:` + past + `"]},
+{"id":"hq-pinned","title":"pinned","assignee":"greenplace/witness","status":"open","pinned":true,"labels":["from:mayor/","expiring","expires-at:` + past + `"]},
+{"id":"hq-fresh","title":"fresh","assignee":"greenplace/witness","status":"open","labels":["from:mayor/","expiring","expires-at:` + future + `"]}
+]`
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("scheduled.json", scheduled)
+	writeFile("expiring.json", expiring)
+	script := `#!/bin/sh
+echo "$*" >> ` + logPath + `
+case "$*" in
+  *"--label scheduled"*) cat ` + filepath.Join(bin, "scheduled.json") + ` ;;
+  *"--label expiring"*) cat ` + filepath.Join(bin, "expiring.json") + ` ;;
+  "show hq-esc1 --json") echo '[{"id":"hq-esc1","status":"closed"}]' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	r := NewRouterWithTownRoot(town, town)
+	result, err := r.ProcessScheduled(now)
+	if err != nil {
+		t.Fatalf("ProcessScheduled: %v", err)
+	}
+	if strings.Join(result.Released, ",") != "hq-due" {
+		t.Errorf("Released = %v, want [hq-due]", result.Released)
+	}
+	if strings.Join(result.Dropped, ",") != "hq-moot" {
+		t.Errorf("Dropped = %v, want [hq-moot]", result.Dropped)
+	}
+	if strings.Join(result.Expired, ",") != "hq-stale" {
+		t.Errorf("Expired = %v, want [hq-stale]", result.Expired)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, want := range []string{"label remove hq-due scheduled", "close hq-moot", "close hq-stale"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("bd log missing %q:\n%s", want, log)
+		}
+	}
+	for _, unwanted := range []string{"hq-later", "close hq-read", "close hq-pinned", "hq-fresh"} {
+		if strings.Contains(log, unwanted) {
+			t.Errorf("bd log should not mention %q:\n%s", unwanted, log)
+		}
+	}
+}
@@ -123,6 +123,26 @@ type Message struct {
 	// ClaimedAt is when the queue message was claimed.
 	// Only set for queue messages after claiming.
 	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+
+	// DeliverAt holds the message back until the given time: it stays out
+	// of the recipient's inbox, and the recipient is notified when the
+	// daemon releases it. Nil means deliver immediately.
+	DeliverAt *time.Time `json:"deliver_at,omitempty"`
+
+	// ExpiresAt is when the message goes stale. Unread messages are
+	// archived by the daemon once they expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// ReminderFor makes a scheduled message conditional on a bead: if the
+	// bead is closed by the time the message is due, it is dropped
+	// instead of delivered.
+	ReminderFor string `json:"reminder_for,omitempty"`
+
+	// Labels are free-form tags, e.g. added by mailbox filter rules.
+	Labels []string `json:"labels,omitempty"`
+
+	filtered bool // mailbox rules have already run on the message
+	emailed  bool // the email gateway has sent the message out
 }
 
 // NewMessage creates a new message with a generated ID and thread ID.
@@ -241,9 +261,35 @@ func (m *Message) Validate() error {
 		return fmt.Errorf("claimed_at is only valid for queue messages")
 	}
 
+	if m.DeliverAt != nil && m.ExpiresAt != nil && !m.ExpiresAt.After(*m.DeliverAt) {
+		return fmt.Errorf("expires_at must be after deliver_at")
+	}
+
 	return nil
 }
 
+// IsScheduled reports whether the message is held back for later delivery
+// as of now.
+func (m *Message) IsScheduled(now time.Time) bool {
+	return m.DeliverAt != nil && now.Before(*m.DeliverAt)
+}
+
+// RulesApplied reports whether mailbox rules have already run on the
+// message.
+func (m *Message) RulesApplied() bool {
+	return m.filtered
+}
+
+// Emailed reports whether the email gateway has already sent the message.
+func (m *Message) Emailed() bool {
+	return m.emailed
+}
+
+// IsExpired reports whether the message has passed its expiry as of now.
+func (m *Message) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
 // generateID creates a random message ID.
 // Falls back to time-based ID if crypto/rand fails (extremely rare).
 func generateID() string {
@@ -276,7 +322,7 @@ type BeadsMessage struct {
 	Priority    int       `json:"priority"`    // 0=urgent, 1=high, 2=normal, 3=low
 	Status      string    `json:"status"`      // open=unread, closed=read
 	CreatedAt   time.Time `json:"created_at"`
-	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, queue:X, channel:X, claimed-by:X, claimed-at:X)
+	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, queue:X, channel:X, claimed-by:X, claimed-at:X, deliver-at:X, expires-at:X, reminder-for:X)
 	Pinned      bool      `json:"pinned,omitempty"`
 	Wisp        bool      `json:"wisp,omitempty"` // Ephemeral message (filtered from JSONL export)
 
@@ -290,6 +336,10 @@ type BeadsMessage struct {
 	channel   string     // Channel name (for broadcast messages)
 	claimedBy string     // Who claimed the queue message
 	claimedAt *time.Time // When the queue message was claimed
+	deliverAt *time.Time // When a scheduled message is due
+	expiresAt *time.Time // When the message expires
+	reminder  string     // Bead a scheduled reminder is conditional on
+	tags      []string   // Free-form labels
 }
 
 // ParseLabels extracts metadata from the labels array.
@@ -316,6 +366,18 @@ func (bm *BeadsMessage) ParseLabels() {
 			if t, err := time.Parse(time.RFC3339, ts); err == nil {
 				bm.claimedAt = &t
 			}
+		} else if strings.HasPrefix(label, "deliver-at:") {
+			if t, err := time.Parse(time.RFC3339, strings.TrimPrefix(label, "deliver-at:")); err == nil {
+				bm.deliverAt = &t
+			}
+		} else if strings.HasPrefix(label, "expires-at:") {
+			if t, err := time.Parse(time.RFC3339, strings.TrimPrefix(label, "expires-at:")); err == nil {
+				bm.expiresAt = &t
+			}
+		} else if strings.HasPrefix(label, "reminder-for:") {
+			bm.reminder = strings.TrimPrefix(label, "reminder-for:")
+		} else if !strings.Contains(label, ":") && !isMarkerLabel(label) {
+			bm.tags = append(bm.tags, label)
 		}
 	}
 }
@@ -378,12 +440,21 @@ func (bm *BeadsMessage) ToMessage() *Message {
 		Type:      msgType,
 		ThreadID:  bm.threadID,
 		ReplyTo:   bm.replyTo,
+		Pinned:    bm.Pinned || bm.HasLabel(LabelPinned),
 		Wisp:      bm.Wisp,
 		CC:        ccAddrs,
 		Queue:     bm.queue,
 		Channel:   bm.channel,
 		ClaimedBy: bm.claimedBy,
 		ClaimedAt: bm.claimedAt,
+		DeliverAt: bm.deliverAt,
+		ExpiresAt: bm.expiresAt,
+
+		ReminderFor: bm.reminder,
+		Labels:      bm.tags,
+
+		filtered: bm.HasLabel(LabelFiltered),
+		emailed:  bm.HasLabel(LabelEmailed),
 	}
 }
 
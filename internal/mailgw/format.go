@@ -0,0 +1,202 @@
+package mailgw
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/mail"
+)
+
+// messageID renders a mail ID or thread ID as an email Message-ID.
+func messageID(id, domain string) string {
+	return "<" + id + "@" + domain + ">"
+}
+
+// formatEmail renders msg as an RFC 5322 email from sender to recipient.
+// Message-ID carries the mail ID; References starts with the thread ID,
+// so mail clients group a Whale Town thread into one conversation, and
+// replies can be traced back to the message they answer.
+func formatEmail(msg *mail.Message, sender, recipient, domain string) []byte {
+	at := msg.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	var b strings.Builder
+	header := func(k, v string) {
+		// Strip line breaks so values can't inject headers
+		v = strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+	from := netmail.Address{Name: msg.From + " (Whale Town)", Address: sender}
+	header("From", from.String())
+	header("To", recipient)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", at.Format(time.RFC1123Z))
+	header("Message-ID", messageID(msg.ID, domain))
+
+	var refs []string
+	if msg.ThreadID != "" {
+		refs = append(refs, messageID(msg.ThreadID, domain))
+	}
+	if msg.ReplyTo != "" {
+		header("In-Reply-To", messageID(msg.ReplyTo, domain))
+		refs = append(refs, messageID(msg.ReplyTo, domain))
+	}
+	if len(refs) > 0 {
+		header("References", strings.Join(refs, " "))
+	}
+	header("X-Whaletown-Mail", msg.ID)
+	header("X-Whaletown-From", msg.From)
+	if msg.ThreadID != "" {
+		header("X-Whaletown-Thread", msg.ThreadID)
+	}
+	if msg.Priority == mail.PriorityUrgent || msg.Priority == mail.PriorityHigh {
+		header("Importance", "high")
+	}
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+
+	body := msg.Body
+	if body == "" {
+		body = msg.Subject
+	}
+	body += fmt.Sprintf("\n\n-- \nWhale Town mail %s from %s. Reply to this email to answer in the town.\n", msg.ID, msg.From)
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// referencedIDs returns the local parts of the Message-IDs under domain
+// that an email answers, most direct first: In-Reply-To, then References
+// from last to first.
+func referencedIDs(h netmail.Header, domain string) []string {
+	var refs []string
+	refs = append(refs, parseMsgIDs(h.Get("In-Reply-To"))...)
+	all := parseMsgIDs(h.Get("References"))
+	for i := len(all) - 1; i >= 0; i-- {
+		refs = append(refs, all[i])
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		local, host, ok := strings.Cut(ref, "@")
+		if !ok || !strings.EqualFold(host, domain) || local == "" || seen[local] {
+			continue
+		}
+		seen[local] = true
+		ids = append(ids, local)
+	}
+	return ids
+}
+
+// parseMsgIDs extracts the <...> identifiers from a header value.
+func parseMsgIDs(v string) []string {
+	var ids []string
+	for {
+		open := strings.IndexByte(v, '<')
+		if open < 0 {
+			return ids
+		}
+		end := strings.IndexByte(v[open:], '>')
+		if end < 0 {
+			return ids
+		}
+		ids = append(ids, v[open+1:open+end])
+		v = v[open+end+1:]
+	}
+}
+
+// isThreadID reports whether a referenced ID is a thread rather than a message.
+func isThreadID(id string) bool {
+	return strings.HasPrefix(id, "thread-")
+}
+
+// plainText returns the text/plain content of an email, decoding
+// transfer encodings and descending into multipart bodies.
+func plainText(h netmail.Header, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		// No or malformed Content-Type: RFC 2045 defaults to text/plain
+		mediaType = "text/plain"
+	}
+	body = decodeTransfer(h.Get("Content-Transfer-Encoding"), body)
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return "", fmt.Errorf("no text/plain part")
+			}
+			if err != nil {
+				return "", err
+			}
+			text, err := plainText(netmail.Header(part.Header), part)
+			if err == nil {
+				return text, nil
+			}
+		}
+	case mediaType == "text/plain":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("unsupported content type %s", mediaType)
+}
+
+// decodeTransfer undoes a Content-Transfer-Encoding. multipart.Reader
+// already decodes quoted-printable parts and drops the header.
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// quoteIntro matches the line mail clients put above quoted text.
+var quoteIntro = regexp.MustCompile(`^(On .+wrote:|-+ ?Original Message ?-+|From: .+)$`)
+
+// stripQuoted keeps the new text of a reply: everything above the quoted
+// original, the signature separator or the gateway footer.
+func stripQuoted(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, ">") || line == "-- " || quoteIntro.MatchString(trimmed) {
+			lines = lines[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// replySubject derives the mail subject of a reply to original.
+func replySubject(original *mail.Message) string {
+	if strings.HasPrefix(strings.ToLower(original.Subject), "re:") {
+		return original.Subject
+	}
+	return "Re: " + original.Subject
+}
+
+// readEmail parses a raw email.
+func readEmail(raw []byte) (*netmail.Message, error) {
+	return netmail.ReadMessage(bytes.NewReader(raw))
+}
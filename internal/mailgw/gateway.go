@@ -0,0 +1,317 @@
+// Package mailgw bridges the overseer's Whale Town mailbox to email.
+//
+// Mail addressed to the overseer goes out through the escalation SMTP
+// relay to contacts.human_email, with Message-ID and References headers
+// derived from the mail and thread IDs. Replies are read back over IMAP
+// and turned into mail replies in the same thread, but only from
+// allowlisted senders.
+package mailgw
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/mail"
+	"github.com/speaker20/whaletown/internal/notify"
+)
+
+// OverseerAddress is the mail address of the human overseer.
+const OverseerAddress = "overseer"
+
+// DefaultDomain is the Message-ID domain when email_gateway.domain is unset.
+const DefaultDomain = "whaletown.local"
+
+// ErrDisabled is returned by New when the gateway isn't enabled in
+// settings/escalation.json.
+var ErrDisabled = errors.New("email gateway not enabled")
+
+// Result reports what a gateway pass did.
+type Result struct {
+	Sent     []string `json:"sent,omitempty"`     // mail IDs emailed to the overseer
+	Replies  []string `json:"replies,omitempty"`  // mail IDs answered by email replies
+	Rejected []string `json:"rejected,omitempty"` // "uid <n>: reason" for emails not turned into mail
+}
+
+// Gateway relays the overseer's mail to email and back.
+type Gateway struct {
+	TownRoot  string
+	SMTP      *notify.SMTPNotifier
+	IMAP      *config.EmailGatewayIMAP // nil: send only
+	Password  string                   // IMAP password
+	AllowFrom []string
+	Domain    string
+
+	// TLSConfig overrides the IMAP TLS configuration (tests).
+	TLSConfig *tls.Config
+
+	Router  *mail.Router
+	Mailbox *mail.Mailbox // the overseer's
+}
+
+// New builds the gateway from the town's escalation settings. It returns
+// ErrDisabled when email_gateway is missing or not enabled.
+func New(townRoot string) (*Gateway, error) {
+	cfg, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading escalation config: %w", err)
+	}
+	return FromConfig(townRoot, cfg)
+}
+
+// FromConfig builds the gateway from an escalation config.
+func FromConfig(townRoot string, cfg *config.EscalationConfig) (*Gateway, error) {
+	gw := cfg.EmailGateway
+	if gw == nil || !gw.Enabled {
+		return nil, ErrDisabled
+	}
+	n, err := notify.NewDispatcher(townRoot, cfg).ForAction("email:human")
+	if err != nil {
+		return nil, fmt.Errorf("email gateway: %w", err)
+	}
+
+	g := &Gateway{
+		TownRoot:  townRoot,
+		SMTP:      n.(*notify.SMTPNotifier),
+		IMAP:      gw.IMAP,
+		AllowFrom: gw.AllowFrom,
+		Domain:    gw.Domain,
+		Router:    mail.NewRouterWithTownRoot(townRoot, townRoot),
+	}
+	if len(g.AllowFrom) == 0 && cfg.Contacts.HumanEmail != "" {
+		g.AllowFrom = []string{cfg.Contacts.HumanEmail}
+	}
+	if g.Domain == "" {
+		g.Domain = DefaultDomain
+	}
+	if g.IMAP != nil && g.IMAP.PasswordEnv != "" {
+		g.Password = os.Getenv(g.IMAP.PasswordEnv)
+	}
+	g.Mailbox, err = g.Router.GetMailbox(OverseerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Sync sends pending overseer mail, then, with IMAP configured, turns new
+// email replies into mail. Failures on individual messages are collected;
+// the pass carries on.
+func (g *Gateway) Sync(ctx context.Context) (*Result, error) {
+	result := &Result{}
+	var errs []string
+	if err := g.SendPending(ctx, result); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if g.IMAP != nil {
+		if err := g.PollReplies(ctx, result); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return result, fmt.Errorf("email gateway: %s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// SendPending emails the overseer's unread mail that hasn't been emailed
+// yet and labels each message sent. Mail the overseer has already read in
+// the town is not sent.
+func (g *Gateway) SendPending(ctx context.Context, result *Result) error {
+	msgs, err := g.Mailbox.List()
+	if err != nil {
+		return fmt.Errorf("listing overseer mail: %w", err)
+	}
+	recipient := strings.Join(g.SMTP.To, ", ")
+
+	var errs []string
+	for _, msg := range msgs {
+		if msg.Read || msg.Emailed() {
+			continue
+		}
+		if err := g.SMTP.SendRaw(ctx, formatEmail(msg, g.SMTP.From, recipient, g.Domain)); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", msg.ID, err))
+			continue
+		}
+		if err := g.Mailbox.MarkEmailed(msg.ID); err != nil {
+			// Sent but not recorded: it will go out again next pass
+			errs = append(errs, fmt.Sprintf("%s: marking emailed: %v", msg.ID, err))
+		}
+		result.Sent = append(result.Sent, msg.ID)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("sending: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// PollReplies reads unseen email from the IMAP mailbox and turns each
+// allowlisted reply to a gateway email into a mail reply from the
+// overseer. Emails that can't become mail are marked seen and reported
+// as rejected; emails whose reply fails to send stay unseen for the next
+// pass.
+func (g *Gateway) PollReplies(ctx context.Context, result *Result) error {
+	if g.IMAP == nil {
+		return nil
+	}
+	c, err := g.dialIMAP(ctx)
+	if err != nil {
+		return fmt.Errorf("imap: %w", err)
+	}
+	defer c.Close()
+
+	if err := c.Login(g.IMAP.Username, g.Password); err != nil {
+		return fmt.Errorf("imap: %w", err)
+	}
+	mailbox := g.IMAP.Mailbox
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	if err := c.Select(mailbox); err != nil {
+		return fmt.Errorf("imap: %w", err)
+	}
+	uids, err := c.SearchUnseen()
+	if err != nil {
+		return fmt.Errorf("imap: %w", err)
+	}
+
+	var errs []string
+	for _, uid := range uids {
+		raw, err := c.Fetch(uid)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		reply, reason := g.replyFromEmail(raw)
+		if reply != nil {
+			if err := g.Router.Send(reply); err != nil {
+				errs = append(errs, fmt.Sprintf("uid %d: sending reply: %v", uid, err))
+				continue
+			}
+			result.Replies = append(result.Replies, reply.ReplyTo)
+		} else {
+			result.Rejected = append(result.Rejected, fmt.Sprintf("uid %d: %s", uid, reason))
+		}
+		if err := c.MarkSeen(uid); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	_ = c.Logout()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("imap: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (g *Gateway) dialIMAP(ctx context.Context) (*imapClient, error) {
+	useTLS := g.IMAP.TLS != "never"
+	port := g.IMAP.Port
+	if port == 0 {
+		port = 993
+		if !useTLS {
+			port = 143
+		}
+	}
+	cfg := g.TLSConfig
+	if cfg == nil {
+		cfg = &tls.Config{ServerName: g.IMAP.Host, MinVersion: tls.VersionTLS12}
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+	}
+	return dialIMAP(ctx, net.JoinHostPort(g.IMAP.Host, strconv.Itoa(port)), useTLS, cfg)
+}
+
+// replyFromEmail builds the mail reply for a raw email, or returns why
+// the email is rejected.
+func (g *Gateway) replyFromEmail(raw []byte) (*mail.Message, string) {
+	email, err := readEmail(raw)
+	if err != nil {
+		return nil, "unparseable: " + err.Error()
+	}
+	from, err := netmail.ParseAddress(email.Header.Get("From"))
+	if err != nil {
+		return nil, "no valid From address"
+	}
+	if !g.allowed(from.Address) {
+		return nil, "sender " + from.Address + " not allowed"
+	}
+
+	original := g.findOriginal(referencedIDs(email.Header, g.Domain))
+	if original == nil {
+		return nil, "not a reply to gateway mail"
+	}
+
+	text, err := plainText(email.Header, email.Body)
+	if err != nil {
+		return nil, "no readable body: " + err.Error()
+	}
+	body := stripQuoted(text)
+	if body == "" {
+		return nil, "empty reply"
+	}
+
+	reply := mail.NewReplyMessage(OverseerAddress, original.From, replySubject(original), body, original)
+	if original.Priority == mail.PriorityUrgent || original.Priority == mail.PriorityHigh {
+		reply.Priority = original.Priority
+	}
+	return reply, ""
+}
+
+// findOriginal returns the overseer's message that an email answers: the
+// first referenced mail ID that resolves to mail for the overseer, or
+// else the newest mail for the overseer in a referenced thread.
+func (g *Gateway) findOriginal(ids []string) *mail.Message {
+	for _, id := range ids {
+		if isThreadID(id) {
+			continue
+		}
+		if msg, err := g.Mailbox.Get(id); err == nil && msg.To == OverseerAddress {
+			return msg
+		}
+	}
+	for _, id := range ids {
+		if !isThreadID(id) {
+			continue
+		}
+		thread, err := g.Mailbox.ListByThread(id)
+		if err != nil {
+			continue
+		}
+		for i := len(thread) - 1; i >= 0; i-- {
+			if thread[i].To == OverseerAddress {
+				return thread[i]
+			}
+		}
+	}
+	return nil
+}
+
+// allowed reports whether replies from addr are accepted. Entries are
+// full addresses or "@domain" suffixes; with no entries nothing is
+// accepted.
+func (g *Gateway) allowed(addr string) bool {
+	addr = strings.ToLower(addr)
+	for _, entry := range g.AllowFrom {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if strings.HasPrefix(entry, "@") {
+			if strings.HasSuffix(addr, entry) {
+				return true
+			}
+		} else if entry == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,417 @@
+package mailgw
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/mail"
+)
+
+// smtpStandIn is a minimal SMTP server that records each message.
+type smtpStandIn struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	messages []string
+	rcpts    []string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpStandIn{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.handle(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "RCPT TO"):
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.TrimSpace(line[len("RCPT TO:"):]))
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// imapStandIn is a minimal IMAP server holding one mailbox of messages
+// with UIDs 1..n.
+type imapStandIn struct {
+	ln       net.Listener
+	user     string
+	password string
+
+	mu       sync.Mutex
+	messages []string
+	seen     map[int]bool
+}
+
+func newIMAPStandIn(t *testing.T, user, password string, messages ...string) *imapStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &imapStandIn{ln: ln, user: user, password: password, messages: messages, seen: make(map[int]bool)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.handle(conn)
+		}
+	}()
+	return s
+}
+
+func (s *imapStandIn) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *imapStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	write := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+	write("* OK IMAP4rev1 stand-in ready")
+	authed := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, rest, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			write("%s BAD empty command", tag)
+			continue
+		}
+		s.mu.Lock()
+		switch cmd := strings.ToUpper(fields[0]); {
+		case cmd == "LOGIN":
+			if rest == fmt.Sprintf("LOGIN %q %q", s.user, s.password) {
+				authed = true
+				write("%s OK logged in", tag)
+			} else {
+				write("%s NO bad credentials", tag)
+			}
+		case !authed:
+			write("%s NO log in first", tag)
+		case cmd == "SELECT":
+			write("* %d EXISTS", len(s.messages))
+			write("%s OK [READ-WRITE] selected", tag)
+		case cmd == "UID" && len(fields) >= 3 && strings.EqualFold(fields[1], "SEARCH"):
+			var uids []string
+			for i := range s.messages {
+				if !s.seen[i+1] {
+					uids = append(uids, strconv.Itoa(i+1))
+				}
+			}
+			write("* SEARCH %s", strings.Join(uids, " "))
+			write("%s OK search done", tag)
+		case cmd == "UID" && len(fields) >= 3 && strings.EqualFold(fields[1], "FETCH"):
+			uid, _ := strconv.Atoi(fields[2])
+			if uid < 1 || uid > len(s.messages) {
+				write("%s NO no such message", tag)
+				break
+			}
+			raw := s.messages[uid-1]
+			write("* %d FETCH (UID %d BODY[] {%d}", uid, uid, len(raw))
+			_, _ = io.WriteString(conn, raw)
+			write(")")
+			write("%s OK fetch done", tag)
+		case cmd == "UID" && len(fields) >= 3 && strings.EqualFold(fields[1], "STORE"):
+			uid, _ := strconv.Atoi(fields[2])
+			s.seen[uid] = true
+			write("%s OK store done", tag)
+		case cmd == "LOGOUT":
+			write("* BYE")
+			write("%s OK bye", tag)
+			s.mu.Unlock()
+			return
+		default:
+			write("%s BAD unknown command", tag)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// stubBd installs a bd that logs its arguments, serves the overseer's
+// mail and thread from files, and answers show and create.
+func stubBd(t *testing.T, inbox, thread, show string) string {
+	t.Helper()
+	bin := t.TempDir()
+	logPath := filepath.Join(bin, "bd.log")
+	for name, content := range map[string]string{"inbox.json": inbox, "thread.json": thread, "show.json": show} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := `#!/bin/sh
+echo "$*" >> ` + logPath + `
+case "$*" in
+  *"--assignee overseer --status open"*) cat ` + filepath.Join(bin, "inbox.json") + ` ;;
+  list*) echo '[]' ;;
+  "show hq-1 --json") cat ` + filepath.Join(bin, "show.json") + ` ;;
+  "show "*) echo 'Error: issue not found' >&2; exit 1 ;;
+  "message thread thread-abc --json") cat ` + filepath.Join(bin, "thread.json") + ` ;;
+  create*) echo '{"id":"hq-new"}' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func email(headers, body string) string {
+	return strings.ReplaceAll(headers, "\n", "\r\n") + "\r\n" + strings.ReplaceAll(body, "\n", "\r\n")
+}
+
+func TestGatewaySync(t *testing.T) {
+	town := t.TempDir()
+	hq1 := `{"id":"hq-1","title":"Merge queue stuck","description":"Refinery can't rebase.","assignee":"overseer","status":"open","priority":1,"labels":["from:greenplace/witness","thread:thread-abc"]}`
+	inbox := `[` + hq1 + `,
+{"id":"hq-2","title":"Already read","assignee":"overseer","status":"open","labels":["from:mayor/","read"]},
+{"id":"hq-3","title":"Already emailed","assignee":"overseer","status":"open","labels":["from:mayor/","emailed"]}]`
+	logPath := stubBd(t, inbox, "["+hq1+"]", "["+hq1+"]")
+
+	smtpSrv := newSMTPStandIn(t)
+	imapSrv := newIMAPStandIn(t, "town@example.com", "s3cret",
+		// A direct reply with quoted text
+		email(`From: Me <me@example.com>
+To: town@example.com
+Subject: Re: Merge queue stuck
+In-Reply-To: <hq-1@whaletown.local>
+References: <thread-abc@whaletown.local> <hq-1@whaletown.local>
+`, `Skip the rebase and merge by hand.
+
+On Tue, 10 Mar 2026, greenplace/witness (Whale Town) wrote:
+> Refinery can't rebase.
+`),
+		// Not on the allowlist
+		email(`From: mallory@example.org
+Subject: Re: Merge queue stuck
+In-Reply-To: <hq-1@whaletown.local>
+`, "Delete everything.\n"),
+		// Not a reply to gateway mail
+		email(`From: me@example.com
+Subject: Lunch?
+`, "Noon?\n"),
+		// Thread reference only, multipart with a quoted-printable part
+		email(`From: ME@example.com
+Subject: Re: Merge queue stuck
+References: <thread-abc@whaletown.local>
+MIME-Version: 1.0
+Content-Type: multipart/alternative; boundary="b1"
+`, `--b1
+Content-Type: text/plain; charset=UTF-8
+Content-Transfer-Encoding: quoted-printable
+
+Also close the convoy =E2=9C=94
+--b1
+Content-Type: text/html; charset=UTF-8
+
+<p>Also close the convoy</p>
+--b1--
+`),
+	)
+
+	settings := fmt.Sprintf(`{
+  "type": "escalation", "version": 1,
+  "contacts": {"human_email": "me@example.com"},
+  "smtp": {"host": "127.0.0.1", "port": %d, "from": "town@example.com", "starttls": "never"},
+  "email_gateway": {
+    "enabled": true,
+    "imap": {"host": "127.0.0.1", "port": %d, "username": "town@example.com", "password_env": "TEST_IMAP_PASSWORD", "tls": "never"}
+  }
+}`, smtpSrv.ln.Addr().(*net.TCPAddr).Port, imapSrv.port())
+	if err := os.MkdirAll(filepath.Join(town, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, "settings", "escalation.json"), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_IMAP_PASSWORD", "s3cret")
+
+	gw, err := New(town)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	result, err := gw.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// Outbound: only the unread, unsent message, with thread headers
+	if strings.Join(result.Sent, ",") != "hq-1" {
+		t.Errorf("Sent = %v, want [hq-1]", result.Sent)
+	}
+	smtpSrv.mu.Lock()
+	if len(smtpSrv.messages) != 1 {
+		t.Fatalf("SMTP got %d messages, want 1", len(smtpSrv.messages))
+	}
+	if len(smtpSrv.rcpts) != 1 || smtpSrv.rcpts[0] != "<me@example.com>" {
+		t.Errorf("rcpts = %v", smtpSrv.rcpts)
+	}
+	sent := smtpSrv.messages[0]
+	smtpSrv.mu.Unlock()
+	for _, want := range []string{
+		"Subject: Merge queue stuck\r\n",
+		"Message-ID: <hq-1@whaletown.local>\r\n",
+		"References: <thread-abc@whaletown.local>\r\n",
+		"X-Whaletown-From: greenplace/witness\r\n",
+		"Importance: high\r\n",
+		"\r\nRefinery can't rebase.\r\n",
+	} {
+		if !strings.Contains(sent, want) {
+			t.Errorf("email missing %q:\n%s", want, sent)
+		}
+	}
+
+	// Inbound: two replies in the thread, two rejections
+	if strings.Join(result.Replies, ",") != "hq-1,hq-1" {
+		t.Errorf("Replies = %v, want [hq-1 hq-1]", result.Replies)
+	}
+	if len(result.Rejected) != 2 ||
+		!strings.Contains(result.Rejected[0], "uid 2: sender mallory@example.org not allowed") ||
+		!strings.Contains(result.Rejected[1], "uid 3: not a reply") {
+		t.Errorf("Rejected = %v", result.Rejected)
+	}
+	imapSrv.mu.Lock()
+	for uid := 1; uid <= 4; uid++ {
+		if !imapSrv.seen[uid] {
+			t.Errorf("uid %d not marked seen", uid)
+		}
+	}
+	imapSrv.mu.Unlock()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	if !strings.Contains(log, "label add hq-1 emailed") {
+		t.Errorf("hq-1 not labelled emailed:\n%s", log)
+	}
+	for _, unwanted := range []string{"hq-2 emailed", "hq-3 emailed", "Delete everything", "Noon?", "> Refinery"} {
+		if strings.Contains(log, unwanted) {
+			t.Errorf("bd log should not contain %q:\n%s", unwanted, log)
+		}
+	}
+	creates := strings.Split(log, "\ncreate ")[1:]
+	if len(creates) != 2 {
+		t.Fatalf("got %d creates, want 2:\n%s", len(creates), log)
+	}
+	for _, want := range []string{"Re: Merge queue stuck", "--assignee greenplace/witness", "from:overseer", "thread:thread-abc", "reply-to:hq-1", "Skip the rebase and merge by hand."} {
+		if !strings.Contains(creates[0], want) {
+			t.Errorf("first reply missing %q: %s", want, creates[0])
+		}
+	}
+	if !strings.Contains(creates[1], "Also close the convoy ✔") {
+		t.Errorf("second reply body not decoded: %s", creates[1])
+	}
+}
+
+func TestFromConfigDisabled(t *testing.T) {
+	town := t.TempDir()
+	if _, err := New(town); err != ErrDisabled {
+		t.Errorf("New without settings = %v, want ErrDisabled", err)
+	}
+}
+
+func TestFormatEmailReply(t *testing.T) {
+	msg := &mail.Message{
+		ID:       "hq-9",
+		From:     "mayor/",
+		To:       "overseer",
+		Subject:  "Re: Plan\r\nBcc: evil@example.org",
+		Body:     "Done.",
+		ThreadID: "thread-abc",
+		ReplyTo:  "hq-8",
+	}
+	out := string(formatEmail(msg, "town@example.com", "me@example.com", "wt.example.com"))
+	for _, want := range []string{
+		"Subject: =?utf-8?q?Re:_Plan=0D=0ABcc:_evil@example.org?=\r\n",
+		"In-Reply-To: <hq-8@wt.example.com>\r\n",
+		"References: <thread-abc@wt.example.com> <hq-8@wt.example.com>\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("email missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\r\nBcc:") {
+		t.Errorf("header injection got through:\n%s", out)
+	}
+}
+
+func TestStripQuoted(t *testing.T) {
+	tests := map[string]string{
+		"Yes.\n\nOn Mon, Bob wrote:\n> Ship?":          "Yes.",
+		"Yes.\r\n> quoted\r\n":                         "Yes.",
+		"Fine by me.\n\n-----Original Message-----\nX": "Fine by me.",
+		"Ok\n-- \nsig":                                 "Ok",
+		"> only quoted":                                "",
+	}
+	for in, want := range tests {
+		if got := stripQuoted(in); got != want {
+			t.Errorf("stripQuoted(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package mailgw
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// imapClient speaks the small subset of IMAP4rev1 (RFC 3501) the gateway
+// needs: LOGIN, SELECT, UID SEARCH, UID FETCH, UID STORE and LOGOUT.
+type imapClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapResponse is one untagged response line with its literals inlined
+// as placeholders and collected in order.
+type imapResponse struct {
+	text     string
+	literals [][]byte
+}
+
+// dialIMAP connects and reads the server greeting. With useTLS the
+// connection is TLS from the start (port 993).
+func dialIMAP(ctx context.Context, addr string, useTLS bool, tlsConfig *tls.Config) (*imapClient, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if useTLS {
+		tc := tls.Client(conn, tlsConfig)
+		if err := tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tc
+	}
+	c := &imapClient{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected greeting: %s", strings.TrimSpace(greeting))
+	}
+	return c, nil
+}
+
+// Close drops the connection without logging out.
+func (c *imapClient) Close() error {
+	return c.conn.Close()
+}
+
+// cmd sends a command and returns its untagged responses. A tagged reply
+// other than OK is an error.
+func (c *imapClient) cmd(command string) ([]imapResponse, error) {
+	c.tag++
+	tag := "a" + strconv.Itoa(c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, command); err != nil {
+		return nil, err
+	}
+
+	var untagged []imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if rest, ok := strings.CutPrefix(resp.text, tag+" "); ok {
+			if !strings.HasPrefix(strings.ToUpper(rest), "OK") {
+				verb, _, _ := strings.Cut(command, " ")
+				return nil, fmt.Errorf("imap %s: %s", verb, rest)
+			}
+			return untagged, nil
+		}
+		if strings.HasPrefix(resp.text, "*") {
+			untagged = append(untagged, resp)
+		}
+		// Continuation requests ("+") aren't expected for these commands
+	}
+}
+
+// readResponse reads one response line, following {n} literals.
+func (c *imapClient) readResponse() (imapResponse, error) {
+	var resp imapResponse
+	var b strings.Builder
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return resp, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		n, ok := literalSize(line)
+		if !ok {
+			b.WriteString(line)
+			resp.text = b.String()
+			return resp, nil
+		}
+		b.WriteString(line[:strings.LastIndexByte(line, '{')])
+		b.WriteString("{}")
+		lit := make([]byte, n)
+		if _, err := io.ReadFull(c.r, lit); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, lit)
+	}
+}
+
+// literalSize parses a trailing "{n}" literal marker.
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	open := strings.LastIndexByte(line, '{')
+	if open < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(line[open+1 : len(line)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// Login authenticates with LOGIN.
+func (c *imapClient) Login(username, password string) error {
+	_, err := c.cmd("LOGIN " + imapQuote(username) + " " + imapQuote(password))
+	return err
+}
+
+// Select opens a mailbox read-write.
+func (c *imapClient) Select(mailbox string) error {
+	_, err := c.cmd("SELECT " + imapQuote(mailbox))
+	return err
+}
+
+// SearchUnseen returns the UIDs of messages without the \Seen flag.
+func (c *imapClient) SearchUnseen() ([]uint32, error) {
+	resps, err := c.cmd("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+	var uids []uint32
+	for _, r := range resps {
+		fields := strings.Fields(r.text)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "SEARCH") {
+			continue
+		}
+		for _, f := range fields[2:] {
+			if uid, err := strconv.ParseUint(f, 10, 32); err == nil {
+				uids = append(uids, uint32(uid))
+			}
+		}
+	}
+	return uids, nil
+}
+
+// Fetch returns the full raw message without setting \Seen.
+func (c *imapClient) Fetch(uid uint32) ([]byte, error) {
+	resps, err := c.cmd(fmt.Sprintf("UID FETCH %d BODY.PEEK[]", uid))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resps {
+		if strings.Contains(strings.ToUpper(r.text), "FETCH") && len(r.literals) > 0 {
+			return r.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("imap FETCH: no body for UID %d", uid)
+}
+
+// MarkSeen sets the \Seen flag.
+func (c *imapClient) MarkSeen(uid uint32) error {
+	_, err := c.cmd(fmt.Sprintf("UID STORE %d +FLAGS.SILENT (\\Seen)", uid))
+	return err
+}
+
+// Logout ends the session and closes the connection.
+func (c *imapClient) Logout() error {
+	_, err := c.cmd("LOGOUT")
+	closeErr := c.conn.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// imapQuote renders s as an IMAP quoted string.
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", "").Replace(s) + `"`
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/claude"
 	"github.com/speaker20/whaletown/internal/config"
 	"github.com/speaker20/whaletown/internal/constants"
@@ -51,7 +52,7 @@ func (m *Manager) mayorDir() string {
 // Start starts the mayor session.
 // agentOverride optionally specifies a different agent alias to use.
 func (m *Manager) Start(agentOverride string) error {
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot)
 	sessionID := m.SessionName()
 
 	// Check if session already exists
@@ -134,7 +135,7 @@ func (m *Manager) Start(agentOverride string) error {
 
 // Stop stops the mayor session.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot)
 	sessionID := m.SessionName()
 
 	// Check if session exists
@@ -160,13 +161,13 @@ func (m *Manager) Stop() error {
 
 // IsRunning checks if the mayor session is active.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot)
 	return t.HasSession(m.SessionName())
 }
 
 // Status returns information about the mayor session.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot)
 	sessionID := m.SessionName()
 
 	running, err := t.HasSession(sessionID)
@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/config"
+)
+
+// ErrNotConfigured is returned by ForAction when an action's channel or
+// contact is missing from settings/escalation.json.
+var ErrNotConfigured = errors.New("not configured")
+
+// Dispatcher delivers escalations to the external actions of a route.
+type Dispatcher struct {
+	TownRoot string
+	Config   *config.EscalationConfig
+	Policy   RetryPolicy
+	Client   *http.Client // Default: http.DefaultClient
+	Now      func() time.Time
+}
+
+// NewDispatcher creates a dispatcher using cfg's contacts, channels and
+// delivery settings.
+func NewDispatcher(townRoot string, cfg *config.EscalationConfig) *Dispatcher {
+	return &Dispatcher{TownRoot: townRoot, Config: cfg, Policy: PolicyFromConfig(cfg.Delivery)}
+}
+
+// PolicyFromConfig fills a RetryPolicy from settings, using
+// DefaultRetryPolicy for unset fields. Durations are validated on load.
+func PolicyFromConfig(d *config.EscalationDelivery) RetryPolicy {
+	p := DefaultRetryPolicy
+	if d == nil {
+		return p
+	}
+	if d.MaxAttempts > 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if v, err := time.ParseDuration(d.Backoff); err == nil {
+		p.Backoff = v
+	}
+	if v, err := time.ParseDuration(d.Timeout); err == nil {
+		p.Timeout = v
+	}
+	return p
+}
+
+// IsExternal reports whether an action is delivered outside Whale Town.
+func IsExternal(action string) bool {
+	return strings.HasPrefix(action, "email:") || strings.HasPrefix(action, "sms:") ||
+		action == "slack" || action == "webhook" || action == "log"
+}
+
+// ForAction builds the notifier for a route action. Actions that are not
+// external (bead, mail:) return nil, nil. Missing settings return an
+// error wrapping ErrNotConfigured.
+//
+// email:<who> and sms:<who> use contacts.human_email / contacts.human_sms
+// for "human"; any other value is taken as the address or number itself.
+func (d *Dispatcher) ForAction(action string) (Notifier, error) {
+	cfg := d.Config
+	switch {
+	case strings.HasPrefix(action, "email:"):
+		to := strings.TrimPrefix(action, "email:")
+		if to == "human" || !strings.Contains(to, "@") {
+			to = cfg.Contacts.HumanEmail
+		}
+		if to == "" {
+			return nil, fmt.Errorf("contacts.human_email %w", ErrNotConfigured)
+		}
+		if cfg.SMTP == nil {
+			return nil, fmt.Errorf("smtp %w", ErrNotConfigured)
+		}
+		n := &SMTPNotifier{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			From:     cfg.SMTP.From,
+			To:       []string{to},
+			Username: cfg.SMTP.Username,
+			StartTLS: cfg.SMTP.StartTLS,
+		}
+		if cfg.SMTP.PasswordEnv != "" {
+			n.Password = os.Getenv(cfg.SMTP.PasswordEnv)
+		}
+		return n, nil
+
+	case strings.HasPrefix(action, "sms:"):
+		to := strings.TrimPrefix(action, "sms:")
+		if to == "human" || strings.TrimLeft(to, "+0123456789 -") != "" {
+			to = cfg.Contacts.HumanSMS
+		}
+		if to == "" {
+			return nil, fmt.Errorf("contacts.human_sms %w", ErrNotConfigured)
+		}
+		gw := cfg.SMSGateway
+		if gw == nil {
+			return nil, fmt.Errorf("sms_gateway %w", ErrNotConfigured)
+		}
+		n, err := NewSMSGatewayNotifier(to, gw.Method, gw.URL, gw.ContentType, gw.Body, gw.Headers)
+		if err != nil {
+			return nil, err
+		}
+		n.Client = d.Client
+		return n, nil
+
+	case action == "slack":
+		if cfg.Contacts.SlackWebhook == "" {
+			return nil, fmt.Errorf("contacts.slack_webhook %w", ErrNotConfigured)
+		}
+		return &SlackNotifier{WebhookURL: cfg.Contacts.SlackWebhook, Client: d.Client}, nil
+
+	case action == "webhook":
+		if cfg.Contacts.Webhook == "" {
+			return nil, fmt.Errorf("contacts.webhook %w", ErrNotConfigured)
+		}
+		return &WebhookNotifier{URL: cfg.Contacts.Webhook, Client: d.Client}, nil
+
+	case action == "log":
+		return &LogNotifier{Path: EscalationLogPath(d.TownRoot)}, nil
+	}
+	return nil, nil
+}
+
+// Dispatch delivers msg for each external action in order and returns one
+// Delivery per external action. Unconfigured actions are skipped, not failed.
+func (d *Dispatcher) Dispatch(ctx context.Context, actions []string, msg *Message) []Delivery {
+	var out []Delivery
+	for _, action := range actions {
+		if !IsExternal(action) {
+			continue
+		}
+		del := Delivery{Action: action, Channel: channelOf(action)}
+
+		n, err := d.ForAction(action)
+		if err != nil {
+			del.Error = err.Error()
+			del.Status = StatusFailed
+			if errors.Is(err, ErrNotConfigured) {
+				del.Status = StatusSkipped
+			}
+			del.At = d.now()
+			out = append(out, del)
+			continue
+		}
+
+		del.Target = n.Target()
+		attempts, err := Deliver(ctx, n, msg, d.Policy)
+		del.Attempts = attempts
+		del.At = d.now()
+		if err != nil {
+			del.Status = StatusFailed
+			del.Error = err.Error()
+		} else {
+			del.Status = StatusSent
+		}
+		out = append(out, del)
+	}
+	return out
+}
+
+func (d *Dispatcher) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+func channelOf(action string) string {
+	channel, _, _ := strings.Cut(action, ":")
+	return channel
+}
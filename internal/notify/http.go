@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SlackNotifier posts to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client // Default: http.DefaultClient
+}
+
+// Channel implements Notifier.
+func (s *SlackNotifier) Channel() string { return "slack" }
+
+// Target implements Notifier. The webhook URL is a secret, so only its
+// host is reported.
+func (s *SlackNotifier) Target() string { return redactURL(s.WebhookURL) }
+
+// Notify implements Notifier.
+func (s *SlackNotifier) Notify(ctx context.Context, msg *Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n", msg.Subject())
+	fmt.Fprintf(&b, "Escalation `%s` from %s", msg.BeadID, msg.From)
+	if msg.Reason != "" {
+		fmt.Fprintf(&b, "\n>%s", msg.Reason)
+	}
+	if msg.Related != "" {
+		fmt.Fprintf(&b, "\nRelated: `%s`", msg.Related)
+	}
+	fmt.Fprintf(&b, "\nAcknowledge with `wt escalate ack %s`", msg.BeadID)
+
+	payload, err := json.Marshal(map[string]string{"text": b.String()})
+	if err != nil {
+		return Permanent(err)
+	}
+	return doHTTP(ctx, s.Client, http.MethodPost, s.WebhookURL, "application/json", nil, payload)
+}
+
+// WebhookNotifier POSTs the message as JSON to a URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client // Default: http.DefaultClient
+}
+
+// Channel implements Notifier.
+func (w *WebhookNotifier) Channel() string { return "webhook" }
+
+// Target implements Notifier.
+func (w *WebhookNotifier) Target() string { return redactURL(w.URL) }
+
+// Notify implements Notifier.
+func (w *WebhookNotifier) Notify(ctx context.Context, msg *Message) error {
+	payload, err := json.Marshal(struct {
+		Event string `json:"event"`
+		*Message
+	}{Event: "escalation", Message: msg})
+	if err != nil {
+		return Permanent(err)
+	}
+	return doHTTP(ctx, w.Client, http.MethodPost, w.URL, "application/json", nil, payload)
+}
+
+// doHTTP sends a request and classifies the response: 2xx succeeds, 408,
+// 429 and 5xx are retryable, other statuses are permanent failures.
+func doHTTP(ctx context.Context, client *http.Client, method, rawURL, contentType string, headers map[string]string, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, r)
+	if err != nil {
+		return Permanent(fmt.Errorf("building request: %w", err))
+	}
+	if body != nil && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return err
+	default:
+		return Permanent(err)
+	}
+}
+
+// redactURL reduces a URL to scheme and host, since webhook paths and
+// queries usually embed credentials.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "(invalid url)"
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogNotifier appends escalations to a log file, one line each.
+type LogNotifier struct {
+	Path string
+}
+
+// EscalationLogPath returns the town's escalation log.
+func EscalationLogPath(townRoot string) string {
+	return filepath.Join(townRoot, "logs", "escalations.log")
+}
+
+// Channel implements Notifier.
+func (l *LogNotifier) Channel() string { return "log" }
+
+// Target implements Notifier.
+func (l *LogNotifier) Target() string { return l.Path }
+
+// Notify implements Notifier.
+// Format: 2026-01-02 15:04:05 [high] hq-abc12 whaletown/witness: Refinery stuck
+func (l *LogNotifier) Notify(_ context.Context, msg *Message) error {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0755); err != nil {
+		return Permanent(fmt.Errorf("creating log directory: %w", err))
+	}
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return Permanent(fmt.Errorf("opening escalation log: %w", err))
+	}
+	defer f.Close()
+
+	at := msg.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	line := fmt.Sprintf("%s [%s] %s %s: %s", at.Format("2006-01-02 15:04:05"), msg.Severity, msg.BeadID, msg.From, msg.Title)
+	if msg.Reason != "" {
+		line += " (" + msg.Reason + ")"
+	}
+	line = strings.NewReplacer("\r", " ", "\n", " ").Replace(line)
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("writing escalation log: %w", err)
+	}
+	return nil
+}
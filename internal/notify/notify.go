@@ -0,0 +1,133 @@
+// Package notify delivers escalations to channels outside Whale Town:
+// email over SMTP, Slack incoming webhooks, generic JSON webhooks, SMS
+// through an HTTP gateway, and the town's escalation log.
+//
+// Each channel is a Notifier. Deliver retries transient failures with
+// exponential backoff; errors marked Permanent (a rejected recipient, an
+// HTTP 4xx) fail immediately. A Dispatcher maps escalation route actions
+// ("email:human", "slack", ...) to notifiers using settings/escalation.json
+// and reports a Delivery per action for recording on the escalation bead.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Message is an escalation to deliver.
+type Message struct {
+	BeadID   string    `json:"bead_id"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Reason   string    `json:"reason,omitempty"`
+	Source   string    `json:"source,omitempty"`
+	From     string    `json:"from"`
+	Related  string    `json:"related_bead,omitempty"`
+	Body     string    `json:"-"` // Plain-text body for email and logs
+	Time     time.Time `json:"timestamp"`
+}
+
+// Subject returns a one-line summary, e.g. "[HIGH] Refinery stuck".
+func (m *Message) Subject() string {
+	return fmt.Sprintf("[%s] %s", strings.ToUpper(m.Severity), m.Title)
+}
+
+// Notifier delivers a message over one channel.
+type Notifier interface {
+	// Channel names the transport ("email", "slack", "webhook", "sms", "log").
+	Channel() string
+
+	// Target describes where messages go, for delivery records.
+	Target() string
+
+	// Notify makes one delivery attempt.
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so Deliver does not retry it.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// RetryPolicy controls delivery attempts.
+type RetryPolicy struct {
+	MaxAttempts int           // Total tries, at least 1
+	Backoff     time.Duration // Wait before the first retry, doubled after each
+	Timeout     time.Duration // Per-attempt bound; 0 for none
+}
+
+// DefaultRetryPolicy is used when settings/escalation.json has no delivery block.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Backoff: 2 * time.Second, Timeout: 30 * time.Second}
+
+// Deliver sends msg through n, retrying transient failures. It returns
+// the number of attempts made and the last error.
+func Deliver(ctx context.Context, n Notifier, msg *Message, policy RetryPolicy) (int, error) {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := policy.Backoff
+
+	var err error
+	for i := 1; i <= attempts; i++ {
+		err = notifyOnce(ctx, n, msg, policy.Timeout)
+		if err == nil {
+			return i, nil
+		}
+		if IsPermanent(err) || i == attempts {
+			return i, err
+		}
+		select {
+		case <-ctx.Done():
+			return i, fmt.Errorf("%w (gave up: %v)", err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return attempts, err
+}
+
+func notifyOnce(ctx context.Context, n Notifier, msg *Message, timeout time.Duration) error {
+	if timeout <= 0 {
+		return n.Notify(ctx, msg)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return n.Notify(ctx, msg)
+}
+
+// Delivery statuses.
+const (
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped" // Channel not configured
+)
+
+// Delivery records the outcome of one route action.
+type Delivery struct {
+	Action   string    `json:"action"`
+	Channel  string    `json:"channel"`
+	Target   string    `json:"target,omitempty"`
+	Status   string    `json:"status"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error,omitempty"`
+	At       time.Time `json:"at"`
+}
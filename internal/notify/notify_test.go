@@ -0,0 +1,362 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/config"
+)
+
+var testMsg = &Message{
+	BeadID:   "hq-abc12",
+	Severity: "high",
+	Title:    "Refinery stuck",
+	Reason:   "merge queue blocked for 2h",
+	From:     "whaletown/witness",
+	Body:     "Escalation ID: hq-abc12\n.leading dot\nend",
+	Time:     time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+}
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Timeout: 5 * time.Second}
+
+// smtpStandIn is a minimal SMTP server. Replies to MAIL FROM come from
+// mailReplies in order, then "250 OK".
+type smtpStandIn struct {
+	ln          net.Listener
+	mailReplies []string
+
+	mu       sync.Mutex
+	messages []string
+	rcpts    []string
+}
+
+func newSMTPStandIn(t *testing.T, mailReplies ...string) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpStandIn{ln: ln, mailReplies: mailReplies}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM"):
+			s.mu.Lock()
+			r := "250 OK"
+			if len(s.mailReplies) > 0 {
+				r, s.mailReplies = s.mailReplies[0], s.mailReplies[1:]
+			}
+			s.mu.Unlock()
+			reply(r)
+		case strings.HasPrefix(cmd, "RCPT TO"):
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.TrimSpace(line[len("RCPT TO:"):]))
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	srv := newSMTPStandIn(t)
+	n := &SMTPNotifier{Host: "127.0.0.1", Port: srv.port(), From: "wt@example.com", To: []string{"ops@example.com"}}
+
+	attempts, err := Deliver(context.Background(), n, testMsg, fastRetry)
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(srv.messages))
+	}
+	if len(srv.rcpts) != 1 || srv.rcpts[0] != "<ops@example.com>" {
+		t.Errorf("rcpts = %v", srv.rcpts)
+	}
+	m := srv.messages[0]
+	for _, want := range []string{
+		"Subject: [HIGH] Refinery stuck\r\n",
+		"X-Whaletown-Escalation: hq-abc12\r\n",
+		"\r\n..leading dot\r\n", // dot-stuffed on the wire
+	} {
+		if !strings.Contains(m, want) {
+			t.Errorf("message missing %q:\n%s", want, m)
+		}
+	}
+}
+
+func TestSMTPNotifierRetriesTransientReply(t *testing.T) {
+	srv := newSMTPStandIn(t, "421 try again later")
+	n := &SMTPNotifier{Host: "127.0.0.1", Port: srv.port(), From: "wt@example.com", To: []string{"ops@example.com"}}
+
+	attempts, err := Deliver(context.Background(), n, testMsg, fastRetry)
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestSMTPNotifierPermanentReply(t *testing.T) {
+	srv := newSMTPStandIn(t, "550 sender rejected")
+	n := &SMTPNotifier{Host: "127.0.0.1", Port: srv.port(), From: "wt@example.com", To: []string{"ops@example.com"}}
+
+	attempts, err := Deliver(context.Background(), n, testMsg, fastRetry)
+	if err == nil || !IsPermanent(err) {
+		t.Fatalf("err = %v, want permanent", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestSMTPNotifierRequiresStartTLS(t *testing.T) {
+	srv := newSMTPStandIn(t)
+	n := &SMTPNotifier{Host: "127.0.0.1", Port: srv.port(), From: "wt@example.com", To: []string{"ops@example.com"}, StartTLS: StartTLSAlways}
+
+	if _, err := Deliver(context.Background(), n, testMsg, fastRetry); err == nil || !IsPermanent(err) {
+		t.Fatalf("err = %v, want permanent STARTTLS failure", err)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	n := &WebhookNotifier{URL: srv.URL + "/hooks/secret-token"}
+	if _, err := Deliver(context.Background(), n, testMsg, fastRetry); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if got["bead_id"] != "hq-abc12" || got["severity"] != "high" || got["event"] != "escalation" {
+		t.Errorf("payload = %v", got)
+	}
+	if strings.Contains(n.Target(), "secret-token") {
+		t.Errorf("Target() leaks the URL path: %s", n.Target())
+	}
+}
+
+func TestHTTPRetryClassification(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"recovers from 503", []int{503, 200}, 2, false},
+		{"retries 429", []int{429, 429, 204}, 3, false},
+		{"gives up after max attempts", []int{500, 500, 500, 200}, 3, true},
+		{"no retry on 404", []int{404, 200}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := atomic.AddInt32(&calls, 1) - 1
+				w.WriteHeader(tt.statuses[i])
+			}))
+			defer srv.Close()
+
+			attempts, err := Deliver(context.Background(), &SlackNotifier{WebhookURL: srv.URL}, testMsg, fastRetry)
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSMSGatewayNotifier(t *testing.T) {
+	t.Setenv("WT_TEST_SMS_TOKEN", "s3cret")
+	var gotBody, gotAuth, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		gotAuth = r.Header.Get("Authorization")
+		gotQuery = r.URL.Query().Get("to")
+	}))
+	defer srv.Close()
+
+	n, err := NewSMSGatewayNotifier("+15550100", "", srv.URL+"/send?to={{urlquery .To}}", "",
+		`{"to": {{json .To}}, "text": {{json .Message}}}`,
+		map[string]string{"Authorization": `Bearer {{env "WT_TEST_SMS_TOKEN"}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Deliver(context.Background(), n, testMsg, fastRetry); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	var body struct{ To, Text string }
+	if err := json.Unmarshal([]byte(gotBody), &body); err != nil {
+		t.Fatalf("body %q: %v", gotBody, err)
+	}
+	if body.To != "+15550100" || body.Text != "[HIGH] Refinery stuck (hq-abc12)" {
+		t.Errorf("body = %+v", body)
+	}
+	if gotAuth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotQuery != "+15550100" {
+		t.Errorf("to query = %q", gotQuery)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	var webhookCalls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&webhookCalls, 1)
+	}))
+	defer srv.Close()
+	smtpSrv := newSMTPStandIn(t)
+
+	townRoot := t.TempDir()
+	cfg := config.NewEscalationConfig()
+	cfg.Contacts.HumanEmail = "human@example.com"
+	cfg.Contacts.Webhook = srv.URL
+	cfg.SMTP = &config.EscalationSMTP{Host: "127.0.0.1", Port: smtpSrv.port(), From: "wt@example.com"}
+
+	d := NewDispatcher(townRoot, cfg)
+	d.Policy = fastRetry
+	actions := []string{"bead", "mail:mayor", "email:human", "sms:human", "slack", "webhook", "log"}
+	deliveries := d.Dispatch(context.Background(), actions, testMsg)
+
+	want := map[string]string{
+		"email:human": StatusSent,
+		"sms:human":   StatusSkipped,
+		"slack":       StatusSkipped,
+		"webhook":     StatusSent,
+		"log":         StatusSent,
+	}
+	if len(deliveries) != len(want) {
+		t.Fatalf("got %d deliveries, want %d: %+v", len(deliveries), len(want), deliveries)
+	}
+	for _, del := range deliveries {
+		if del.Status != want[del.Action] {
+			t.Errorf("%s: status = %s (%s), want %s", del.Action, del.Status, del.Error, want[del.Action])
+		}
+		if del.Status == StatusSkipped && !strings.Contains(del.Error, "not configured") {
+			t.Errorf("%s: skip reason = %q", del.Action, del.Error)
+		}
+	}
+	if webhookCalls != 1 {
+		t.Errorf("webhook calls = %d, want 1", webhookCalls)
+	}
+
+	data, err := os.ReadFile(filepath.Join(townRoot, "logs", "escalations.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "[high] hq-abc12 whaletown/witness: Refinery stuck") {
+		t.Errorf("log = %q", data)
+	}
+}
+
+func TestForActionLiteralContacts(t *testing.T) {
+	cfg := config.NewEscalationConfig()
+	cfg.SMTP = &config.EscalationSMTP{Host: "smtp.example.com", From: "wt@example.com"}
+	cfg.SMSGateway = &config.EscalationSMSGateway{URL: "https://sms.example.com"}
+	d := NewDispatcher(t.TempDir(), cfg)
+
+	n, err := d.ForAction("email:oncall@example.com")
+	if err != nil || n.Target() != "oncall@example.com" {
+		t.Errorf("email: target = %v, err = %v", n, err)
+	}
+	n, err = d.ForAction("sms:+1 555 0100")
+	if err != nil || n.Target() != "+1 555 0100" {
+		t.Errorf("sms: target = %v, err = %v", n, err)
+	}
+	if _, err := d.ForAction("email:human"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("email:human without contact: err = %v", err)
+	}
+	if n, err := d.ForAction("mail:mayor"); n != nil || err != nil {
+		t.Errorf("mail:mayor should not be external")
+	}
+}
+
+func TestPolicyFromConfig(t *testing.T) {
+	p := PolicyFromConfig(&config.EscalationDelivery{MaxAttempts: 5, Backoff: "100ms"})
+	if p.MaxAttempts != 5 || p.Backoff != 100*time.Millisecond || p.Timeout != DefaultRetryPolicy.Timeout {
+		t.Errorf("policy = %+v", p)
+	}
+	if got := PolicyFromConfig(nil); got != DefaultRetryPolicy {
+		t.Errorf("nil config: %+v", got)
+	}
+}
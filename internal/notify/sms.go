@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// smsLimit is the length messages are truncated to; most gateways split
+// or reject longer bodies.
+const smsLimit = 320
+
+// SMSData is the data available to SMS gateway templates.
+type SMSData struct {
+	To       string
+	Message  string
+	Severity string
+	BeadID   string
+	Title    string
+}
+
+// SMSGatewayNotifier sends SMS through an HTTP API described by templates.
+type SMSGatewayNotifier struct {
+	To          string
+	Method      string
+	ContentType string
+	Client      *http.Client // Default: http.DefaultClient
+
+	url     *template.Template
+	body    *template.Template
+	headers map[string]*template.Template
+}
+
+// smsFuncs are the functions available to gateway templates.
+var smsFuncs = template.FuncMap{
+	"env": os.Getenv,
+	"json": func(s string) (string, error) {
+		b, err := json.Marshal(s)
+		return string(b), err
+	},
+}
+
+// NewSMSGatewayNotifier parses the gateway templates. Method defaults to
+// POST and contentType to application/json.
+func NewSMSGatewayNotifier(to, method, rawURL, contentType, body string, headers map[string]string) (*SMSGatewayNotifier, error) {
+	n := &SMSGatewayNotifier{
+		To:          to,
+		Method:      method,
+		ContentType: contentType,
+		headers:     make(map[string]*template.Template, len(headers)),
+	}
+	if n.Method == "" {
+		n.Method = http.MethodPost
+	}
+	if n.ContentType == "" {
+		n.ContentType = "application/json"
+	}
+
+	var err error
+	if n.url, err = template.New("url").Funcs(smsFuncs).Parse(rawURL); err != nil {
+		return nil, fmt.Errorf("sms gateway url: %w", err)
+	}
+	if body != "" {
+		if n.body, err = template.New("body").Funcs(smsFuncs).Parse(body); err != nil {
+			return nil, fmt.Errorf("sms gateway body: %w", err)
+		}
+	}
+	for k, v := range headers {
+		t, err := template.New(k).Funcs(smsFuncs).Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("sms gateway header %s: %w", k, err)
+		}
+		n.headers[k] = t
+	}
+	return n, nil
+}
+
+// Channel implements Notifier.
+func (n *SMSGatewayNotifier) Channel() string { return "sms" }
+
+// Target implements Notifier.
+func (n *SMSGatewayNotifier) Target() string { return n.To }
+
+// Notify implements Notifier.
+func (n *SMSGatewayNotifier) Notify(ctx context.Context, msg *Message) error {
+	text := fmt.Sprintf("%s (%s)", msg.Subject(), msg.BeadID)
+	if len(text) > smsLimit {
+		text = text[:smsLimit-3] + "..."
+	}
+	data := SMSData{To: n.To, Message: text, Severity: msg.Severity, BeadID: msg.BeadID, Title: msg.Title}
+
+	rawURL, err := render(n.url, data)
+	if err != nil {
+		return Permanent(err)
+	}
+	var body []byte
+	if n.body != nil {
+		s, err := render(n.body, data)
+		if err != nil {
+			return Permanent(err)
+		}
+		body = []byte(s)
+	}
+	headers := make(map[string]string, len(n.headers))
+	for k, t := range n.headers {
+		v, err := render(t, data)
+		if err != nil {
+			return Permanent(err)
+		}
+		headers[k] = v
+	}
+	return doHTTP(ctx, n.Client, n.Method, strings.TrimSpace(rawURL), n.ContentType, headers, body)
+}
+
+func render(t *template.Template, data SMSData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("sms gateway %s template: %w", t.Name(), err)
+	}
+	return buf.String(), nil
+}
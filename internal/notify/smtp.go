@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StartTLS modes for SMTPNotifier.
+const (
+	StartTLSAuto   = "auto"   // Upgrade when the server offers it
+	StartTLSAlways = "always" // Fail if the server doesn't offer it
+	StartTLSNever  = "never"  // Stay in plaintext
+)
+
+// SMTPNotifier sends email through an SMTP relay.
+type SMTPNotifier struct {
+	Host     string
+	Port     int // Default: 587
+	From     string
+	To       []string
+	Username string // Empty disables auth
+	Password string
+	StartTLS string // StartTLSAuto when empty
+
+	// TLSConfig overrides the STARTTLS configuration (tests).
+	TLSConfig *tls.Config
+}
+
+// Channel implements Notifier.
+func (s *SMTPNotifier) Channel() string { return "email" }
+
+// Target implements Notifier.
+func (s *SMTPNotifier) Target() string { return strings.Join(s.To, ",") }
+
+// Notify implements Notifier. 5xx replies are permanent failures; 4xx
+// replies and connection errors are retried.
+func (s *SMTPNotifier) Notify(ctx context.Context, msg *Message) error {
+	return s.SendRaw(ctx, s.format(msg))
+}
+
+// SendRaw sends an already formatted RFC 5322 message to the recipients,
+// with the same error classification as Notify.
+func (s *SMTPNotifier) SendRaw(ctx context.Context, data []byte) error {
+	if len(s.To) == 0 {
+		return Permanent(errors.New("no recipients"))
+	}
+	port := s.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(port))
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return classifySMTP(err)
+	}
+	defer c.Close()
+
+	if err := s.send(c, data); err != nil {
+		return classifySMTP(err)
+	}
+	return nil
+}
+
+func (s *SMTPNotifier) send(c *smtp.Client, data []byte) error {
+	if err := c.Hello("localhost"); err != nil {
+		return err
+	}
+
+	mode := s.StartTLS
+	if mode == "" {
+		mode = StartTLSAuto
+	}
+	if mode != StartTLSNever {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			cfg := s.TLSConfig
+			if cfg == nil {
+				cfg = &tls.Config{ServerName: s.Host, MinVersion: tls.VersionTLS12}
+			}
+			if err := c.StartTLS(cfg); err != nil {
+				return err
+			}
+		} else if mode == StartTLSAlways {
+			return Permanent(fmt.Errorf("%s does not offer STARTTLS", s.Host))
+		}
+	}
+
+	if s.Username != "" {
+		// PlainAuth refuses to send credentials unencrypted except to localhost
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return Permanent(fmt.Errorf("auth: %w", err))
+		}
+	}
+
+	if err := c.Mail(s.From); err != nil {
+		return err
+	}
+	for _, to := range s.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("rcpt %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// format renders the RFC 5322 message.
+func (s *SMTPNotifier) format(msg *Message) []byte {
+	at := msg.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	var b strings.Builder
+	header := func(k, v string) {
+		// Strip line breaks so values can't inject headers
+		v = strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+	header("From", s.From)
+	header("To", strings.Join(s.To, ", "))
+	header("Subject", msg.Subject())
+	header("Date", at.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("X-Whaletown-Escalation", msg.BeadID)
+	header("X-Whaletown-Severity", msg.Severity)
+	b.WriteString("\r\n")
+
+	body := msg.Body
+	if body == "" {
+		body = msg.Title
+	}
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// classifySMTP marks 5xx replies permanent.
+func classifySMTP(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	utc := func(s string) time.Time {
+		ts, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	tests := []struct {
+		expr string
+		from string
+		want string
+	}{
+		{"TZ=UTC 0 9 * * *", "2026-03-10 08:59", "2026-03-10 09:00"},
+		{"TZ=UTC 0 9 * * *", "2026-03-10 09:00", "2026-03-11 09:00"},
+		{"TZ=UTC */15 * * * *", "2026-03-10 10:07", "2026-03-10 10:15"},
+		{"TZ=UTC 10-50/20 * * * *", "2026-03-10 10:31", "2026-03-10 10:50"},
+		{"TZ=UTC 0 0 * * mon-fri", "2026-03-13 12:00", "2026-03-16 00:00"}, // Fri -> Mon
+		{"TZ=UTC 0 0 * * 7", "2026-03-10 00:00", "2026-03-15 00:00"},       // 7 is Sunday
+		{"TZ=UTC 0 0 1 jan,jul *", "2026-03-10 00:00", "2026-07-01 00:00"},
+		{"TZ=UTC 0 0 13 * fri", "2026-03-10 00:00", "2026-03-13 00:00"}, // DOM or DOW
+		{"TZ=UTC 0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"TZ=UTC @hourly", "2026-03-10 10:07", "2026-03-10 11:00"},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		got := s.Next(utc(tt.from))
+		if !got.Equal(utc(tt.want)) {
+			t.Errorf("%q from %s = %s, want %s", tt.expr, tt.from, got.UTC().Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+}
+
+func TestParseCron_TimeZone(t *testing.T) {
+	s, err := ParseCron("CRON_TZ=America/New_York 0 9 * * *")
+	if err != nil {
+		t.Skipf("tz database unavailable: %v", err)
+	}
+	// 9:00 EDT is 13:00 UTC in July
+	from := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 7, 1, 13, 0, 0, 0, time.UTC)
+	if got := s.Next(from); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got.UTC(), want)
+	}
+
+	// 2:30 doesn't exist on the spring-forward day; the next match is a day later
+	s, _ = ParseCron("CRON_TZ=America/New_York 30 2 * * *")
+	from = time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC) // 00:00 EST
+	got := s.Next(from).In(s.Location())
+	if got.Day() != 9 || got.Hour() != 2 || got.Minute() != 30 {
+		t.Errorf("Next over DST gap = %s", got)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * * funday",
+		"@fortnightly",
+		"TZ=Nowhere/Special * * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestCronSchedule_NeverFires(t *testing.T) {
+	s, err := ParseCron("TZ=UTC 0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next = %s, want zero", got)
+	}
+}
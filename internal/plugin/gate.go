@@ -0,0 +1,322 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/events"
+)
+
+// DefaultCooldown is used by cooldown gates that don't set a duration.
+const DefaultCooldown = "1h"
+
+// DefaultConditionTimeout bounds how long a condition gate's check may run.
+const DefaultConditionTimeout = 30 * time.Second
+
+// DefaultLookback is how far back cron and event gates look for fire
+// times and events when a plugin has never run.
+const DefaultLookback = time.Hour
+
+// eventAliases maps friendly event gate names to event types.
+var eventAliases = map[string]string{
+	"startup": events.TypeBoot,
+}
+
+// RunHistory reports when a plugin last ran. *Recorder implements it.
+type RunHistory interface {
+	GetLastRun(pluginName string) (*PluginRunBead, error)
+}
+
+// GateStatus is the result of evaluating a plugin's gate.
+type GateStatus struct {
+	Plugin   string     `json:"plugin"`
+	RigName  string     `json:"rig_name,omitempty"`
+	Gate     GateType   `json:"gate"`
+	Due      bool       `json:"due"`
+	Reason   string     `json:"reason"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+	NextFire *time.Time `json:"next_fire,omitempty"` // Next scheduled time, for cooldown and cron gates
+}
+
+// Evaluator decides whether plugins are due to run.
+type Evaluator struct {
+	// History supplies last-run times.
+	History RunHistory
+
+	// EventsPath is the events log matched by event gates.
+	EventsPath string
+
+	// ConditionTimeout bounds condition checks (default DefaultConditionTimeout).
+	ConditionTimeout time.Duration
+
+	// Lookback applies to never-run cron and event gates (default DefaultLookback).
+	Lookback time.Duration
+
+	// Now returns the current time (default time.Now).
+	Now func() time.Time
+}
+
+// NewEvaluator creates an evaluator backed by the town's run ledger and
+// events log.
+func NewEvaluator(townRoot string) *Evaluator {
+	return &Evaluator{
+		History:    NewRecorder(townRoot),
+		EventsPath: filepath.Join(townRoot, events.EventsFile),
+	}
+}
+
+// Evaluate checks a plugin's gate. Plugins without a gate are manual.
+// Cooldown, condition and event gates also honor Gate.Duration as a
+// minimum interval between runs when it is set.
+func (e *Evaluator) Evaluate(p *Plugin) (*GateStatus, error) {
+	status := &GateStatus{Plugin: p.Name, RigName: p.RigName, Gate: GateManual}
+	if p.Gate == nil || p.Gate.Type == GateManual {
+		status.Reason = "manual gate, run explicitly"
+		return status, nil
+	}
+	status.Gate = p.Gate.Type
+
+	var lastRun time.Time
+	if e.History != nil {
+		run, err := e.History.GetLastRun(p.Name)
+		if err != nil {
+			return nil, fmt.Errorf("getting last run of %s: %w", p.Name, err)
+		}
+		if run != nil && !run.CreatedAt.IsZero() {
+			lastRun = run.CreatedAt
+			status.LastRun = &lastRun
+		}
+	}
+
+	now := e.now()
+	switch p.Gate.Type {
+	case GateCooldown:
+		return e.evalCooldown(p.Gate, status, lastRun, now)
+	case GateCron:
+		return e.evalCron(p.Gate, status, lastRun, now)
+	case GateCondition:
+		if ok, err := e.cooledDown(p.Gate, status, lastRun, now); !ok || err != nil {
+			return status, err
+		}
+		return e.evalCondition(p, status)
+	case GateEvent:
+		if ok, err := e.cooledDown(p.Gate, status, lastRun, now); !ok || err != nil {
+			return status, err
+		}
+		return e.evalEvent(p.Gate, status, lastRun, now)
+	default:
+		return nil, fmt.Errorf("plugin %s: unknown gate type %q", p.Name, p.Gate.Type)
+	}
+}
+
+func (e *Evaluator) evalCooldown(g *Gate, status *GateStatus, lastRun, now time.Time) (*GateStatus, error) {
+	duration := g.Duration
+	if duration == "" {
+		duration = DefaultCooldown
+	}
+	d, err := ParseGateDuration(duration)
+	if err != nil {
+		return nil, fmt.Errorf("cooldown duration: %w", err)
+	}
+
+	if lastRun.IsZero() {
+		status.Due = true
+		status.Reason = "never run"
+		status.NextFire = &now
+		return status, nil
+	}
+
+	next := lastRun.Add(d)
+	status.NextFire = &next
+	if now.Before(next) {
+		status.Reason = fmt.Sprintf("cooling down (%s)", duration)
+		return status, nil
+	}
+	status.Due = true
+	status.Reason = fmt.Sprintf("%s cooldown elapsed", duration)
+	return status, nil
+}
+
+func (e *Evaluator) evalCron(g *Gate, status *GateStatus, lastRun, now time.Time) (*GateStatus, error) {
+	sched, err := ParseCron(g.Schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	since := lastRun
+	if since.IsZero() {
+		since = now.Add(-e.lookback())
+	}
+
+	// A fire time between the last run and now makes the plugin due;
+	// missed fires collapse into one run.
+	if fire := sched.Next(since); !fire.IsZero() && !fire.After(now) {
+		status.Due = true
+		status.Reason = "scheduled " + fire.Format(time.RFC3339)
+		status.NextFire = &fire
+		return status, nil
+	}
+
+	next := sched.Next(now)
+	if next.IsZero() {
+		status.Reason = "schedule never fires"
+		return status, nil
+	}
+	status.NextFire = &next
+	status.Reason = "waiting for schedule"
+	return status, nil
+}
+
+func (e *Evaluator) evalCondition(p *Plugin, status *GateStatus) (*GateStatus, error) {
+	if p.Gate.Check == "" {
+		return nil, fmt.Errorf("plugin %s: condition gate has no check", p.Name)
+	}
+
+	timeout := e.ConditionTimeout
+	if timeout == 0 {
+		timeout = DefaultConditionTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.Gate.Check) //nolint:gosec // G204: check comes from the plugin definition
+	cmd.Dir = p.Path
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		status.Reason = fmt.Sprintf("check timed out after %s", timeout)
+	case err == nil:
+		status.Due = true
+		status.Reason = "check passed"
+	default:
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			status.Reason = fmt.Sprintf("check exited %d", exitErr.ExitCode())
+		} else {
+			status.Reason = fmt.Sprintf("check failed: %v", err)
+		}
+	}
+	return status, nil
+}
+
+func (e *Evaluator) evalEvent(g *Gate, status *GateStatus, lastRun, now time.Time) (*GateStatus, error) {
+	if g.On == "" {
+		return nil, fmt.Errorf("event gate has no 'on' type")
+	}
+	eventType := g.On
+	if alias, ok := eventAliases[eventType]; ok {
+		eventType = alias
+	}
+
+	since := lastRun
+	if since.IsZero() {
+		since = now.Add(-e.lookback())
+	}
+
+	at, err := e.lastEvent(eventType, since)
+	if err != nil {
+		return nil, err
+	}
+	if at.IsZero() {
+		status.Reason = fmt.Sprintf("waiting for %s event", eventType)
+		return status, nil
+	}
+	status.Due = true
+	status.Reason = fmt.Sprintf("%s event at %s", eventType, at.Format(time.RFC3339))
+	return status, nil
+}
+
+// cooledDown enforces an optional Gate.Duration minimum interval. It
+// fills status and returns false while the plugin is still cooling down.
+func (e *Evaluator) cooledDown(g *Gate, status *GateStatus, lastRun, now time.Time) (bool, error) {
+	if g.Duration == "" || lastRun.IsZero() {
+		return true, nil
+	}
+	d, err := ParseGateDuration(g.Duration)
+	if err != nil {
+		return false, fmt.Errorf("gate duration: %w", err)
+	}
+	if next := lastRun.Add(d); now.Before(next) {
+		status.NextFire = &next
+		status.Reason = fmt.Sprintf("cooling down (%s)", g.Duration)
+		return false, nil
+	}
+	return true, nil
+}
+
+// lastEvent returns the time of the latest event of the given type after
+// since, or the zero time if there is none.
+func (e *Evaluator) lastEvent(eventType string, since time.Time) (time.Time, error) {
+	if e.EventsPath == "" {
+		return time.Time{}, nil
+	}
+	f, err := os.Open(e.EventsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil // No events yet
+		}
+		return time.Time{}, fmt.Errorf("opening events: %w", err)
+	}
+	defer f.Close()
+
+	var latest time.Time
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !strings.Contains(string(line), eventType) {
+			continue
+		}
+		var ev events.Event
+		if err := json.Unmarshal(line, &ev); err != nil || ev.Type != eventType {
+			continue // Skip malformed or other events
+		}
+		ts, err := time.Parse(time.RFC3339, ev.Timestamp)
+		if err != nil || !ts.After(since) {
+			continue
+		}
+		if ts.After(latest) {
+			latest = ts
+		}
+	}
+	return latest, scanner.Err()
+}
+
+func (e *Evaluator) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+func (e *Evaluator) lookback() time.Duration {
+	if e.Lookback > 0 {
+		return e.Lookback
+	}
+	return DefaultLookback
+}
+
+// ParseGateDuration parses a gate duration, accepting a "d" suffix for
+// days in addition to time.ParseDuration units.
+func ParseGateDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		if _, err := fmt.Sscanf(days, "%d", &n); err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,217 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/events"
+)
+
+type fakeHistory map[string]time.Time
+
+func (h fakeHistory) GetLastRun(name string) (*PluginRunBead, error) {
+	ts, ok := h[name]
+	if !ok {
+		return nil, nil
+	}
+	return &PluginRunBead{ID: "wisp-" + name, CreatedAt: ts}, nil
+}
+
+var gateNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestEvaluator(history fakeHistory) *Evaluator {
+	return &Evaluator{History: history, Now: func() time.Time { return gateNow }}
+}
+
+func TestEvaluate_Manual(t *testing.T) {
+	e := newTestEvaluator(fakeHistory{})
+	for _, p := range []*Plugin{
+		{Name: "nogate"},
+		{Name: "manual", Gate: &Gate{Type: GateManual}},
+	} {
+		st, err := e.Evaluate(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Due || st.Gate != GateManual {
+			t.Errorf("%s: %+v", p.Name, st)
+		}
+	}
+}
+
+func TestEvaluate_Cooldown(t *testing.T) {
+	e := newTestEvaluator(fakeHistory{
+		"recent": gateNow.Add(-30 * time.Minute),
+		"old":    gateNow.Add(-2 * 24 * time.Hour),
+	})
+	tests := []struct {
+		name     string
+		duration string
+		due      bool
+	}{
+		{"never", "1h", true},
+		{"recent", "1h", false},
+		{"recent", "", false}, // Default 1h
+		{"recent", "10m", true},
+		{"old", "1d", true},
+		{"old", "7d", false},
+	}
+	for _, tt := range tests {
+		st, err := e.Evaluate(&Plugin{Name: tt.name, Gate: &Gate{Type: GateCooldown, Duration: tt.duration}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Due != tt.due {
+			t.Errorf("%s/%s: due = %v, want %v (%s)", tt.name, tt.duration, st.Due, tt.due, st.Reason)
+		}
+		if st.NextFire == nil {
+			t.Errorf("%s/%s: no next fire time", tt.name, tt.duration)
+		}
+	}
+
+	if _, err := e.Evaluate(&Plugin{Name: "bad", Gate: &Gate{Type: GateCooldown, Duration: "soon"}}); err == nil {
+		t.Error("expected error for bad duration")
+	}
+}
+
+func TestEvaluate_Cron(t *testing.T) {
+	e := newTestEvaluator(fakeHistory{
+		"ran-today":     time.Date(2026, 3, 10, 9, 1, 0, 0, time.UTC),
+		"ran-yesterday": time.Date(2026, 3, 9, 9, 1, 0, 0, time.UTC),
+	})
+	daily := &Gate{Type: GateCron, Schedule: "TZ=UTC 0 9 * * *"}
+
+	st, _ := e.Evaluate(&Plugin{Name: "ran-today", Gate: daily})
+	if st.Due {
+		t.Errorf("ran-today due: %s", st.Reason)
+	}
+	if want := time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC); st.NextFire == nil || !st.NextFire.Equal(want) {
+		t.Errorf("next fire = %v, want %s", st.NextFire, want)
+	}
+
+	st, _ = e.Evaluate(&Plugin{Name: "ran-yesterday", Gate: daily})
+	if !st.Due {
+		t.Errorf("ran-yesterday not due: %s", st.Reason)
+	}
+
+	// Never run: only fires that fall within the lookback count
+	st, _ = e.Evaluate(&Plugin{Name: "never", Gate: daily})
+	if st.Due {
+		t.Errorf("never-run daily plugin due outside lookback: %s", st.Reason)
+	}
+	st, _ = e.Evaluate(&Plugin{Name: "never", Gate: &Gate{Type: GateCron, Schedule: "TZ=UTC 30 11 * * *"}})
+	if !st.Due {
+		t.Errorf("never-run plugin not due within lookback: %s", st.Reason)
+	}
+
+	if _, err := e.Evaluate(&Plugin{Name: "bad", Gate: &Gate{Type: GateCron, Schedule: "whenever"}}); err == nil {
+		t.Error("expected error for bad schedule")
+	}
+}
+
+func TestEvaluate_Condition(t *testing.T) {
+	e := newTestEvaluator(fakeHistory{"cooling": gateNow.Add(-time.Minute)})
+	e.ConditionTimeout = 200 * time.Millisecond
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "flag"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		gate   Gate
+		due    bool
+		reason string
+	}{
+		{"pass", Gate{Check: "test -f flag"}, true, "check passed"},
+		{"fail", Gate{Check: "exit 3"}, false, "exited 3"},
+		{"slow", Gate{Check: "sleep 5"}, false, "timed out"},
+		{"cooling", Gate{Check: "true", Duration: "1h"}, false, "cooling down"},
+	}
+	for _, tt := range tests {
+		tt.gate.Type = GateCondition
+		start := time.Now()
+		st, err := e.Evaluate(&Plugin{Name: tt.name, Path: dir, Gate: &tt.gate})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if st.Due != tt.due || !strings.Contains(st.Reason, tt.reason) {
+			t.Errorf("%s: due=%v reason=%q, want due=%v reason~%q", tt.name, st.Due, st.Reason, tt.due, tt.reason)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("%s: took %s", tt.name, elapsed)
+		}
+	}
+}
+
+func TestEvaluate_Event(t *testing.T) {
+	eventsPath := filepath.Join(t.TempDir(), events.EventsFile)
+	var lines []string
+	for _, ev := range []events.Event{
+		{Type: events.TypeBoot, Timestamp: gateNow.Add(-10 * time.Minute).Format(time.RFC3339)},
+		{Type: events.TypeMerged, Timestamp: gateNow.Add(-3 * time.Hour).Format(time.RFC3339)},
+	} {
+		data, _ := json.Marshal(ev)
+		lines = append(lines, string(data))
+	}
+	lines = append(lines, "not json")
+	if err := os.WriteFile(eventsPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := newTestEvaluator(fakeHistory{
+		"after-boot": gateNow.Add(-5 * time.Minute),
+		"old-run":    gateNow.Add(-4 * time.Hour),
+	})
+	e.EventsPath = eventsPath
+
+	tests := []struct {
+		name string
+		on   string
+		due  bool
+	}{
+		{"never", "startup", true},       // Alias for boot, within lookback
+		{"after-boot", "startup", false}, // Already ran since
+		{"old-run", events.TypeMerged, true},
+		{"never", events.TypeMerged, false}, // Outside lookback
+		{"never", events.TypeSling, false},
+	}
+	for _, tt := range tests {
+		st, err := e.Evaluate(&Plugin{Name: tt.name, Gate: &Gate{Type: GateEvent, On: tt.on}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Due != tt.due {
+			t.Errorf("%s on %s: due = %v, want %v (%s)", tt.name, tt.on, st.Due, tt.due, st.Reason)
+		}
+	}
+
+	// Missing events log is not an error
+	e.EventsPath = filepath.Join(t.TempDir(), "missing.jsonl")
+	if st, err := e.Evaluate(&Plugin{Name: "never", Gate: &Gate{Type: GateEvent, On: "startup"}}); err != nil || st.Due {
+		t.Errorf("missing log: due=%v err=%v", st != nil && st.Due, err)
+	}
+}
+
+func TestParseGateDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90s": 90 * time.Second,
+		"1h":  time.Hour,
+		"2d":  48 * time.Hour,
+	}
+	for in, want := range tests {
+		got, err := ParseGateDuration(in)
+		if err != nil || got != want {
+			t.Errorf("ParseGateDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "xd", "1w"} {
+		if _, err := ParseGateDuration(in); err == nil {
+			t.Errorf("ParseGateDuration(%q) succeeded", in)
+		}
+	}
+}
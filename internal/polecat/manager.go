@@ -5,7 +5,6 @@ package polecat
 import (
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
@@ -14,6 +13,7 @@ import (
 
 	"github.com/speaker20/whaletown/internal/beads"
 	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/connection"
 	"github.com/speaker20/whaletown/internal/git"
 	"github.com/speaker20/whaletown/internal/rig"
 	"github.com/speaker20/whaletown/internal/tmux"
@@ -49,6 +49,12 @@ type Manager struct {
 	beads    *beads.Beads
 	namePool *NamePool
 	tmux     *tmux.Tmux
+
+	// conn runs worktree file and git operations; root is the rig
+	// directory on that connection. Beads and rig settings always stay
+	// in the town's copy of the rig at rig.Path.
+	conn connection.Connection
+	root string
 }
 
 // NewManager creates a new polecat manager.
@@ -85,6 +91,37 @@ func NewManager(r *rig.Rig, g *git.Git, t *tmux.Tmux) *Manager {
 		beads:    beads.NewWithBeadsDir(beadsPath, resolvedBeads),
 		namePool: pool,
 		tmux:     t,
+		conn:     connection.NewLocalConnection(),
+		root:     r.Path,
+	}
+}
+
+// SetConnection routes worktree operations through conn, with the rig's
+// worktrees under root on that connection. Used for rigs hosted on
+// another machine.
+func (m *Manager) SetConnection(conn connection.Connection, root string) {
+	m.conn = conn
+	m.root = root
+}
+
+// isDir reports whether path is a directory on the manager's connection.
+func (m *Manager) isDir(path string) bool {
+	info, err := m.conn.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// copyAgentsMD copies AGENTS.md from mayor/rig into a worktree that
+// doesn't have one (e.g., stale fetch, local-only file).
+func (m *Manager) copyAgentsMD(clonePath string) {
+	agentsMDPath := filepath.Join(clonePath, "AGENTS.md")
+	if ok, err := m.conn.Exists(agentsMDPath); err != nil || ok {
+		return
+	}
+	srcPath := filepath.Join(m.root, "mayor", "rig", "AGENTS.md")
+	if srcData, readErr := m.conn.ReadFile(srcPath); readErr == nil {
+		if writeErr := m.conn.WriteFile(agentsMDPath, srcData, 0644); writeErr != nil {
+			fmt.Printf("Warning: could not copy AGENTS.md: %v\n", writeErr)
+		}
 	}
 }
 
@@ -169,24 +206,24 @@ func (m *Manager) checkCleanupStatus(name string, status CleanupStatus, force bo
 // The bare repo architecture allows all worktrees (refinery, polecats) to share branch visibility.
 func (m *Manager) repoBase() (*git.Git, error) {
 	// First check for shared bare repo (new architecture)
-	bareRepoPath := filepath.Join(m.rig.Path, ".repo.git")
-	if info, err := os.Stat(bareRepoPath); err == nil && info.IsDir() {
+	bareRepoPath := filepath.Join(m.root, ".repo.git")
+	if m.isDir(bareRepoPath) {
 		// Bare repo exists - use it
-		return git.NewGitWithDir(bareRepoPath, ""), nil
+		return m.conn.GitWithDir(bareRepoPath, ""), nil
 	}
 
 	// Fall back to mayor/rig (legacy architecture)
-	mayorPath := filepath.Join(m.rig.Path, "mayor", "rig")
-	if _, err := os.Stat(mayorPath); os.IsNotExist(err) {
+	mayorPath := filepath.Join(m.root, "mayor", "rig")
+	if ok, _ := m.conn.Exists(mayorPath); !ok {
 		return nil, fmt.Errorf("no repo base found (neither .repo.git nor mayor/rig exists)")
 	}
-	return git.NewGit(mayorPath), nil
+	return m.conn.Git(mayorPath), nil
 }
 
 // polecatDir returns the parent directory for a polecat.
 // This is polecats/<name>/ - the polecat's home directory.
 func (m *Manager) polecatDir(name string) string {
-	return filepath.Join(m.rig.Path, "polecats", name)
+	return filepath.Join(m.root, "polecats", name)
 }
 
 // clonePath returns the path where the git worktree lives.
@@ -194,17 +231,16 @@ func (m *Manager) polecatDir(name string) string {
 // Falls back to old structure: polecats/<name>/ for backward compatibility.
 func (m *Manager) clonePath(name string) string {
 	// New structure: polecats/<name>/<rigname>/
-	newPath := filepath.Join(m.rig.Path, "polecats", name, m.rig.Name)
-	if info, err := os.Stat(newPath); err == nil && info.IsDir() {
+	newPath := filepath.Join(m.root, "polecats", name, m.rig.Name)
+	if m.isDir(newPath) {
 		return newPath
 	}
 
 	// Old structure: polecats/<name>/ (backward compat)
-	oldPath := filepath.Join(m.rig.Path, "polecats", name)
-	if info, err := os.Stat(oldPath); err == nil && info.IsDir() {
+	oldPath := filepath.Join(m.root, "polecats", name)
+	if m.isDir(oldPath) {
 		// Check if this is actually a git worktree (has .git file or dir)
-		gitPath := filepath.Join(oldPath, ".git")
-		if _, err := os.Stat(gitPath); err == nil {
+		if ok, _ := m.conn.Exists(filepath.Join(oldPath, ".git")); ok {
 			return oldPath
 		}
 	}
@@ -215,8 +251,8 @@ func (m *Manager) clonePath(name string) string {
 
 // exists checks if a polecat exists.
 func (m *Manager) exists(name string) bool {
-	_, err := os.Stat(m.polecatDir(name))
-	return err == nil
+	ok, _ := m.conn.Exists(m.polecatDir(name))
+	return ok
 }
 
 // AddOptions configures polecat creation.
@@ -263,7 +299,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	}
 
 	// Create polecat directory (polecats/<name>/)
-	if err := os.MkdirAll(polecatDir, 0755); err != nil {
+	if err := m.conn.MkdirAll(polecatDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating polecat dir: %w", err)
 	}
 
@@ -296,49 +332,45 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 
 	// Ensure AGENTS.md exists - critical for polecats to "land the plane"
 	// Fall back to copy from mayor/rig if not in git (e.g., stale fetch, local-only file)
-	agentsMDPath := filepath.Join(clonePath, "AGENTS.md")
-	if _, err := os.Stat(agentsMDPath); os.IsNotExist(err) {
-		srcPath := filepath.Join(m.rig.Path, "mayor", "rig", "AGENTS.md")
-		if srcData, readErr := os.ReadFile(srcPath); readErr == nil {
-			if writeErr := os.WriteFile(agentsMDPath, srcData, 0644); writeErr != nil {
-				fmt.Printf("Warning: could not copy AGENTS.md: %v\n", writeErr)
-			}
-		}
-	}
+	m.copyAgentsMD(clonePath)
 
 	// NOTE: We intentionally do NOT write to CLAUDE.md here.
 	// Whale Town context is injected ephemerally via SessionStart hook (gt prime).
 	// Writing to CLAUDE.md would overwrite project instructions and could leak
 	// Whale Town internals into the project repo if merged.
 
-	// Set up shared beads: polecat uses rig's .beads via redirect file.
-	// This eliminates git sync overhead - all polecats share one database.
-	if err := m.setupSharedBeads(clonePath); err != nil {
-		// Non-fatal - polecat can still work with local beads
-		// Log warning but don't fail the spawn
-		fmt.Printf("Warning: could not set up shared beads: %v\n", err)
-	}
+	// Beads redirect, PRIME.md, overlay and setup hooks work on local
+	// paths in the town, so they are skipped for rigs on other machines.
+	if m.conn.IsLocal() {
+		// Set up shared beads: polecat uses rig's .beads via redirect file.
+		// This eliminates git sync overhead - all polecats share one database.
+		if err := m.setupSharedBeads(clonePath); err != nil {
+			// Non-fatal - polecat can still work with local beads
+			// Log warning but don't fail the spawn
+			fmt.Printf("Warning: could not set up shared beads: %v\n", err)
+		}
 
-	// Provision PRIME.md with Whale Town context for this worker.
-	// This is the fallback if SessionStart hook fails - ensures polecats
-	// always have GUPP and essential Whale Town context.
-	if err := beads.ProvisionPrimeMDForWorktree(clonePath); err != nil {
-		// Non-fatal - polecat can still work via hook, warn but don't fail
-		fmt.Printf("Warning: could not provision PRIME.md: %v\n", err)
-	}
+		// Provision PRIME.md with Whale Town context for this worker.
+		// This is the fallback if SessionStart hook fails - ensures polecats
+		// always have GUPP and essential Whale Town context.
+		if err := beads.ProvisionPrimeMDForWorktree(clonePath); err != nil {
+			// Non-fatal - polecat can still work via hook, warn but don't fail
+			fmt.Printf("Warning: could not provision PRIME.md: %v\n", err)
+		}
 
-	// Copy overlay files from .runtime/overlay/ to polecat root.
-	// This allows services to have .env and other config files at their root.
-	if err := rig.CopyOverlay(m.rig.Path, clonePath); err != nil {
-		// Non-fatal - log warning but continue
-		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
-	}
+		// Copy overlay files from .runtime/overlay/ to polecat root.
+		// This allows services to have .env and other config files at their root.
+		if err := rig.CopyOverlay(m.rig.Path, clonePath); err != nil {
+			// Non-fatal - log warning but continue
+			fmt.Printf("Warning: could not copy overlay files: %v\n", err)
+		}
 
-	// Run setup hooks from .runtime/setup-hooks/.
-	// These hooks can inject local git config, copy secrets, or perform other setup tasks.
-	if err := rig.RunSetupHooks(m.rig.Path, clonePath); err != nil {
-		// Non-fatal - log warning but continue
-		fmt.Printf("Warning: could not run setup hooks: %v\n", err)
+		// Run setup hooks from .runtime/setup-hooks/.
+		// These hooks can inject local git config, copy secrets, or perform other setup tasks.
+		if err := rig.RunSetupHooks(m.rig.Path, clonePath); err != nil {
+			// Non-fatal - log warning but continue
+			fmt.Printf("Warning: could not run setup hooks: %v\n", err)
+		}
 	}
 
 	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by wt install.
@@ -413,7 +445,7 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear bool) error {
 			}
 		} else {
 			// Fallback path: Check git directly (for polecats that haven't reported yet)
-			polecatGit := git.NewGit(clonePath)
+			polecatGit := m.conn.Git(clonePath)
 			status, err := polecatGit.CheckUncommittedWork()
 			if err == nil && !status.Clean() {
 				// For backward compatibility: force only bypasses uncommitted changes, not stashes/unpushed
@@ -434,32 +466,32 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear bool) error {
 	if err != nil {
 		// Best-effort: try to prune stale worktree entries from both possible repo locations.
 		// This handles edge cases where the repo base is corrupted but worktree entries exist.
-		bareRepoPath := filepath.Join(m.rig.Path, ".repo.git")
-		if info, statErr := os.Stat(bareRepoPath); statErr == nil && info.IsDir() {
-			bareGit := git.NewGitWithDir(bareRepoPath, "")
+		bareRepoPath := filepath.Join(m.root, ".repo.git")
+		if m.isDir(bareRepoPath) {
+			bareGit := m.conn.GitWithDir(bareRepoPath, "")
 			_ = bareGit.WorktreePrune()
 		}
-		mayorRigPath := filepath.Join(m.rig.Path, "mayor", "rig")
-		if info, statErr := os.Stat(mayorRigPath); statErr == nil && info.IsDir() {
-			mayorGit := git.NewGit(mayorRigPath)
+		mayorRigPath := filepath.Join(m.root, "mayor", "rig")
+		if m.isDir(mayorRigPath) {
+			mayorGit := m.conn.Git(mayorRigPath)
 			_ = mayorGit.WorktreePrune()
 		}
 		// Fall back to direct removal if repo base not found
-		return os.RemoveAll(polecatDir)
+		return m.conn.RemoveAll(polecatDir)
 	}
 
 	// Try to remove as a worktree first (use force flag for worktree removal too)
 	if err := repoGit.WorktreeRemove(clonePath, force); err != nil {
 		// Fall back to direct removal if worktree removal fails
 		// (e.g., if this is an old-style clone, not a worktree)
-		if removeErr := os.RemoveAll(clonePath); removeErr != nil {
+		if removeErr := m.conn.RemoveAll(clonePath); removeErr != nil {
 			return fmt.Errorf("removing clone path: %w", removeErr)
 		}
 	} else {
 		// GT-1L3MY9: git worktree remove may leave untracked directories behind.
 		// Clean up any leftover files (overlay files, .beads/, setup hook outputs, etc.)
 		// Use RemoveAll to handle non-empty directories with untracked files.
-		_ = os.RemoveAll(clonePath)
+		_ = m.conn.RemoveAll(clonePath)
 	}
 
 	// Also remove the parent polecat directory
@@ -467,7 +499,7 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear bool) error {
 	if polecatDir != clonePath {
 		// GT-1L3MY9: Clean up any orphaned files at polecat level.
 		// Use RemoveAll to handle non-empty directories with leftover files.
-		_ = os.RemoveAll(polecatDir)
+		_ = m.conn.RemoveAll(polecatDir)
 	}
 
 	// Prune any stale worktree entries (non-fatal: cleanup only)
@@ -542,7 +574,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 
 	// Get the old clone path (may be old or new structure)
 	oldClonePath := m.clonePath(name)
-	polecatGit := git.NewGit(oldClonePath)
+	polecatGit := m.conn.Git(oldClonePath)
 
 	// New clone path uses new structure
 	polecatDir := m.polecatDir(name)
@@ -575,7 +607,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	// Remove the old worktree (use force for git worktree removal)
 	if err := repoGit.WorktreeRemove(oldClonePath, true); err != nil {
 		// Fall back to direct removal
-		if removeErr := os.RemoveAll(oldClonePath); removeErr != nil {
+		if removeErr := m.conn.RemoveAll(oldClonePath); removeErr != nil {
 			return nil, fmt.Errorf("removing old clone path: %w", removeErr)
 		}
 	}
@@ -587,7 +619,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	_ = repoGit.Fetch("origin")
 
 	// Ensure polecat directory exists for new structure
-	if err := os.MkdirAll(polecatDir, 0755); err != nil {
+	if err := m.conn.MkdirAll(polecatDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating polecat dir: %w", err)
 	}
 
@@ -616,27 +648,22 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 
 	// Ensure AGENTS.md exists - critical for polecats to "land the plane"
 	// Fall back to copy from mayor/rig if not in git (e.g., stale fetch, local-only file)
-	agentsMDPath := filepath.Join(newClonePath, "AGENTS.md")
-	if _, err := os.Stat(agentsMDPath); os.IsNotExist(err) {
-		srcPath := filepath.Join(m.rig.Path, "mayor", "rig", "AGENTS.md")
-		if srcData, readErr := os.ReadFile(srcPath); readErr == nil {
-			if writeErr := os.WriteFile(agentsMDPath, srcData, 0644); writeErr != nil {
-				fmt.Printf("Warning: could not copy AGENTS.md: %v\n", writeErr)
-			}
-		}
-	}
+	m.copyAgentsMD(newClonePath)
 
 	// NOTE: We intentionally do NOT write to CLAUDE.md here.
 	// Whale Town context is injected ephemerally via SessionStart hook (gt prime).
 
-	// Set up shared beads
-	if err := m.setupSharedBeads(newClonePath); err != nil {
-		fmt.Printf("Warning: could not set up shared beads: %v\n", err)
-	}
+	// Town-side provisioning; see AddWithOptions
+	if m.conn.IsLocal() {
+		// Set up shared beads
+		if err := m.setupSharedBeads(newClonePath); err != nil {
+			fmt.Printf("Warning: could not set up shared beads: %v\n", err)
+		}
 
-	// Copy overlay files from .runtime/overlay/ to polecat root.
-	if err := rig.CopyOverlay(m.rig.Path, newClonePath); err != nil {
-		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
+		// Copy overlay files from .runtime/overlay/ to polecat root.
+		if err := rig.CopyOverlay(m.rig.Path, newClonePath); err != nil {
+			fmt.Printf("Warning: could not copy overlay files: %v\n", err)
+		}
 	}
 
 	// NOTE: Slash commands inherited from town level - no per-workspace copies needed.
@@ -742,26 +769,21 @@ func (m *Manager) PoolStatus() (active int, names []string) {
 
 // List returns all polecats in the rig.
 func (m *Manager) List() ([]*Polecat, error) {
-	polecatsDir := filepath.Join(m.rig.Path, "polecats")
+	polecatsDir := filepath.Join(m.root, "polecats")
 
-	entries, err := os.ReadDir(polecatsDir)
+	entries, err := m.conn.Glob(filepath.Join(polecatsDir, "*"))
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
 		return nil, fmt.Errorf("reading polecats dir: %w", err)
 	}
 
 	var polecats []*Polecat
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-		if strings.HasPrefix(entry.Name(), ".") {
+		name := filepath.Base(entry)
+		if strings.HasPrefix(name, ".") || !m.isDir(entry) {
 			continue
 		}
 
-		polecat, err := m.Get(entry.Name())
+		polecat, err := m.Get(name)
 		if err != nil {
 			continue // Skip invalid polecats
 		}
@@ -892,7 +914,7 @@ func (m *Manager) loadFromBeads(name string) (*Polecat, error) {
 	clonePath := m.clonePath(name)
 
 	// Get actual branch from worktree (branches are now timestamped)
-	polecatGit := git.NewGit(clonePath)
+	polecatGit := m.conn.Git(clonePath)
 	branchName, err := polecatGit.CurrentBranch()
 	if err != nil {
 		// Fall back to old format if we can't read the branch
@@ -1034,10 +1056,10 @@ func (m *Manager) DetectStalePolecats(threshold int) ([]*StalenessInfo, error) {
 		// Check for active tmux session
 		// Session name follows pattern: gt-<rig>-<polecat>
 		sessionName := fmt.Sprintf("wt-%s-%s", m.rig.Name, p.Name)
-		info.HasActiveSession = checkTmuxSession(sessionName)
+		info.HasActiveSession = m.checkTmuxSession(sessionName)
 
 		// Check how far behind main
-		polecatGit := git.NewGit(p.ClonePath)
+		polecatGit := m.conn.Git(p.ClonePath)
 		info.CommitsBehind = countCommitsBehind(polecatGit, defaultBranch)
 
 		// Check for uncommitted work (excluding .beads/ files which are synced across worktrees)
@@ -1061,8 +1083,12 @@ func (m *Manager) DetectStalePolecats(threshold int) ([]*StalenessInfo, error) {
 	return results, nil
 }
 
-// checkTmuxSession checks if a tmux session exists.
-func checkTmuxSession(sessionName string) bool {
+// checkTmuxSession checks if a tmux session exists on the rig's machine.
+func (m *Manager) checkTmuxSession(sessionName string) bool {
+	if !m.conn.IsLocal() {
+		ok, _ := m.conn.TmuxHasSession(sessionName)
+		return ok
+	}
 	// Use has-session command which returns 0 if session exists
 	cmd := exec.Command("tmux", "has-session", "-t", sessionName) //nolint:gosec // G204: sessionName is constructed internally
 	return cmd.Run() == nil
@@ -11,7 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/connection"
 	"github.com/speaker20/whaletown/internal/constants"
 	"github.com/speaker20/whaletown/internal/rig"
 	"github.com/speaker20/whaletown/internal/runtime"
@@ -35,16 +37,48 @@ var (
 
 // SessionManager handles polecat session lifecycle.
 type SessionManager struct {
-	tmux *tmux.Tmux
-	rig  *rig.Rig
+	backend backend.SessionBackend
+	rig     *rig.Rig
+
+	// conn is where the polecat worktrees live, under root; see
+	// Manager.SetConnection.
+	conn connection.Connection
+	root string
 }
 
 // NewSessionManager creates a new polecat session manager for a rig.
+// Sessions run on t unless the town uses another session backend.
 func NewSessionManager(t *tmux.Tmux, r *rig.Rig) *SessionManager {
 	return &SessionManager{
-		tmux: t,
-		rig:  r,
+		backend: backend.Resolve(filepath.Dir(r.Path), t),
+		rig:     r,
+		conn:    connection.NewLocalConnection(),
+		root:    r.Path,
+	}
+}
+
+// SetConnection looks for polecat worktrees under root on conn. The
+// tmux wrapper passed to NewSessionManager should come from the same
+// connection (conn.Tmux()).
+func (m *SessionManager) SetConnection(conn connection.Connection, root string) {
+	m.conn = conn
+	m.root = root
+}
+
+// beadsDir returns where bd commands for a worktree run. Remote
+// worktrees aren't reachable locally, so their beads are reached through
+// the town's copy of the rig.
+func (m *SessionManager) beadsDir(workDir string) string {
+	if m.conn.IsLocal() {
+		return workDir
 	}
+	return m.rig.Path
+}
+
+// isDir reports whether path is a directory on the rig's connection.
+func (m *SessionManager) isDir(path string) bool {
+	info, err := m.conn.Stat(path)
+	return err == nil && info.IsDir()
 }
 
 // SessionStartOptions configures polecat session startup.
@@ -101,7 +135,7 @@ func (m *SessionManager) SessionName(polecat string) string {
 // polecatDir returns the parent directory for a polecat.
 // This is polecats/<name>/ - the polecat's home directory.
 func (m *SessionManager) polecatDir(polecat string) string {
-	return filepath.Join(m.rig.Path, "polecats", polecat)
+	return filepath.Join(m.root, "polecats", polecat)
 }
 
 // clonePath returns the path where the git worktree lives.
@@ -109,17 +143,16 @@ func (m *SessionManager) polecatDir(polecat string) string {
 // Falls back to old structure: polecats/<name>/ for backward compatibility.
 func (m *SessionManager) clonePath(polecat string) string {
 	// New structure: polecats/<name>/<rigname>/
-	newPath := filepath.Join(m.rig.Path, "polecats", polecat, m.rig.Name)
-	if info, err := os.Stat(newPath); err == nil && info.IsDir() {
+	newPath := filepath.Join(m.root, "polecats", polecat, m.rig.Name)
+	if m.isDir(newPath) {
 		return newPath
 	}
 
 	// Old structure: polecats/<name>/ (backward compat)
-	oldPath := filepath.Join(m.rig.Path, "polecats", polecat)
-	if info, err := os.Stat(oldPath); err == nil && info.IsDir() {
+	oldPath := filepath.Join(m.root, "polecats", polecat)
+	if m.isDir(oldPath) {
 		// Check if this is actually a git worktree (has .git file or dir)
-		gitPath := filepath.Join(oldPath, ".git")
-		if _, err := os.Stat(gitPath); err == nil {
+		if ok, _ := m.conn.Exists(filepath.Join(oldPath, ".git")); ok {
 			return oldPath
 		}
 	}
@@ -130,12 +163,7 @@ func (m *SessionManager) clonePath(polecat string) string {
 
 // hasPolecat checks if the polecat exists in this rig.
 func (m *SessionManager) hasPolecat(polecat string) bool {
-	polecatPath := m.polecatDir(polecat)
-	info, err := os.Stat(polecatPath)
-	if err != nil {
-		return false
-	}
-	return info.IsDir()
+	return m.isDir(m.polecatDir(polecat))
 }
 
 // Start creates and starts a new session for a polecat.
@@ -149,7 +177,7 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	// Check if session already exists
 	// Note: Orphan sessions are cleaned up by ReconcilePool during AllocateName,
 	// so by this point, any existing session should be legitimately in use.
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -166,7 +194,7 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	// Validate issue exists and isn't tombstoned BEFORE creating session.
 	// This prevents CPU spin loops from agents retrying work on invalid issues.
 	if opts.Issue != "" {
-		if err := m.validateIssue(opts.Issue, workDir); err != nil {
+		if err := m.validateIssue(opts.Issue, m.beadsDir(workDir)); err != nil {
 			return err
 		}
 	}
@@ -175,15 +203,18 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 
 	// Ensure runtime settings exist in polecats/ (not polecats/<name>/) so we don't
 	// write into the source repo. Runtime walks up the tree to find settings.
-	polecatsDir := filepath.Join(m.rig.Path, "polecats")
-	if err := runtime.EnsureSettingsForRole(polecatsDir, "polecat", runtimeConfig); err != nil {
-		return fmt.Errorf("ensuring runtime settings: %w", err)
+	// Machines hosting remote rigs keep their own runtime settings.
+	if m.conn.IsLocal() {
+		polecatsDir := filepath.Join(m.rig.Path, "polecats")
+		if err := runtime.EnsureSettingsForRole(polecatsDir, "polecat", runtimeConfig); err != nil {
+			return fmt.Errorf("ensuring runtime settings: %w", err)
+		}
 	}
 
 	// Build startup command first
 	command := opts.Command
 	if command == "" {
-		command = config.BuildPolecatStartupCommand(m.rig.Name, polecat, m.rig.Path, "")
+		command = config.BuildPolecatStartupCommand(m.rig.Name, polecat, m.root, "")
 	}
 	// Prepend runtime config dir env if needed
 	if runtimeConfig.Session != nil && runtimeConfig.Session.ConfigDirEnv != "" && opts.RuntimeConfigDir != "" {
@@ -192,13 +223,13 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/whaletown/issues/280
-	if err := m.tmux.NewSessionWithCommand(sessionID, workDir, command); err != nil {
+	if err := m.backend.NewSessionWithCommand(sessionID, workDir, command); err != nil {
 		return fmt.Errorf("creating session: %w", err)
 	}
 
 	// Set environment (non-fatal: session works without these)
 	// Use centralized AgentEnv for consistency across all role startup paths
-	townRoot := filepath.Dir(m.rig.Path)
+	townRoot := filepath.Dir(m.root)
 	envVars := config.AgentEnv(config.AgentEnvConfig{
 		Role:             "polecat",
 		Rig:              m.rig.Name,
@@ -208,38 +239,38 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		BeadsNoDaemon:    true,
 	})
 	for k, v := range envVars {
-		debugSession("SetEnvironment "+k, m.tmux.SetEnvironment(sessionID, k, v))
+		debugSession("SetEnvironment "+k, m.backend.SetEnvironment(sessionID, k, v))
 	}
 
 	// Hook the issue to the polecat if provided via --issue flag
 	if opts.Issue != "" {
 		agentID := fmt.Sprintf("%s/polecats/%s", m.rig.Name, polecat)
-		if err := m.hookIssue(opts.Issue, agentID, workDir); err != nil {
+		if err := m.hookIssue(opts.Issue, agentID, m.beadsDir(workDir)); err != nil {
 			fmt.Printf("Warning: could not hook issue %s: %v\n", opts.Issue, err)
 		}
 	}
 
 	// Apply theme (non-fatal)
 	theme := tmux.AssignTheme(m.rig.Name)
-	debugSession("ConfigureWhaleTownSession", m.tmux.ConfigureWhaleTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
+	debugSession("ConfigureWhaleTownSession", m.backend.ConfigureWhaleTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
 
 	// Set pane-died hook for crash detection (non-fatal)
 	agentID := fmt.Sprintf("%s/%s", m.rig.Name, polecat)
-	debugSession("SetPaneDiedHook", m.tmux.SetPaneDiedHook(sessionID, agentID))
+	debugSession("SetPaneDiedHook", m.backend.SetPaneDiedHook(sessionID, agentID))
 
 	// Wait for Claude to start (non-fatal)
-	debugSession("WaitForCommand", m.tmux.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
+	debugSession("WaitForCommand", m.backend.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
 
 	// Accept bypass permissions warning dialog if it appears
-	debugSession("AcceptBypassPermissionsWarning", m.tmux.AcceptBypassPermissionsWarning(sessionID))
+	debugSession("AcceptBypassPermissionsWarning", m.backend.AcceptBypassPermissionsWarning(sessionID))
 
 	// Wait for runtime to be fully ready at the prompt (not just started)
 	runtime.SleepForReadyDelay(runtimeConfig)
-	_ = runtime.RunStartupFallback(m.tmux, sessionID, "polecat", runtimeConfig)
+	_ = runtime.RunStartupFallback(m.backend, sessionID, "polecat", runtimeConfig)
 
 	// Inject startup nudge for predecessor discovery via /resume
 	address := fmt.Sprintf("%s/polecats/%s", m.rig.Name, polecat)
-	debugSession("StartupNudge", session.StartupNudge(m.tmux, sessionID, session.StartupNudgeConfig{
+	debugSession("StartupNudge", session.StartupNudge(m.backend, sessionID, session.StartupNudgeConfig{
 		Recipient: address,
 		Sender:    "witness",
 		Topic:     "assigned",
@@ -248,11 +279,11 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 
 	// GUPP: Send propulsion nudge to trigger autonomous work execution
 	time.Sleep(2 * time.Second)
-	debugSession("NudgeSession PropulsionNudge", m.tmux.NudgeSession(sessionID, session.PropulsionNudge()))
+	debugSession("NudgeSession PropulsionNudge", m.backend.NudgeSession(sessionID, session.PropulsionNudge()))
 
 	// Verify session survived startup - if the command crashed, the session may have died.
 	// Without this check, Start() would return success even if the pane died during initialization.
-	running, err = m.tmux.HasSession(sessionID)
+	running, err = m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("verifying session: %w", err)
 	}
@@ -267,7 +298,7 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 func (m *SessionManager) Stop(polecat string, force bool) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -277,19 +308,18 @@ func (m *SessionManager) Stop(polecat string, force bool) error {
 
 	// Sync beads before shutdown (non-fatal)
 	if !force {
-		polecatDir := m.polecatDir(polecat)
-		if err := m.syncBeads(polecatDir); err != nil {
+		if err := m.syncBeads(m.beadsDir(m.polecatDir(polecat))); err != nil {
 			fmt.Printf("Warning: beads sync failed: %v\n", err)
 		}
 	}
 
 	// Try graceful shutdown first
 	if !force {
-		_ = m.tmux.SendKeysRaw(sessionID, "C-c")
+		_ = m.backend.SendKeysRaw(sessionID, "C-c")
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	if err := m.tmux.KillSession(sessionID); err != nil {
+	if err := m.backend.KillSession(sessionID); err != nil {
 		return fmt.Errorf("killing session: %w", err)
 	}
 
@@ -306,14 +336,14 @@ func (m *SessionManager) syncBeads(workDir string) error {
 // IsRunning checks if a polecat session is active.
 func (m *SessionManager) IsRunning(polecat string) (bool, error) {
 	sessionID := m.SessionName(polecat)
-	return m.tmux.HasSession(sessionID)
+	return m.backend.HasSession(sessionID)
 }
 
 // Status returns detailed status for a polecat session.
 func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("checking session: %w", err)
 	}
@@ -329,7 +359,7 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 		return info, nil
 	}
 
-	tmuxInfo, err := m.tmux.GetSessionInfo(sessionID)
+	tmuxInfo, err := m.backend.GetSessionInfo(sessionID)
 	if err != nil {
 		return info, nil
 	}
@@ -364,7 +394,7 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 
 // List returns information about all polecat sessions for this rig.
 func (m *SessionManager) List() ([]SessionInfo, error) {
-	sessions, err := m.tmux.ListSessions()
+	sessions, err := m.backend.ListSessions()
 	if err != nil {
 		return nil, err
 	}
@@ -393,7 +423,7 @@ func (m *SessionManager) List() ([]SessionInfo, error) {
 func (m *SessionManager) Attach(polecat string) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -401,14 +431,14 @@ func (m *SessionManager) Attach(polecat string) error {
 		return ErrSessionNotFound
 	}
 
-	return m.tmux.AttachSession(sessionID)
+	return m.backend.AttachSession(sessionID)
 }
 
 // Capture returns the recent output from a polecat session.
 func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return "", fmt.Errorf("checking session: %w", err)
 	}
@@ -416,12 +446,12 @@ func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.backend.CapturePane(sessionID, lines)
 }
 
 // CaptureSession returns the recent output from a session by raw session ID.
 func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, error) {
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return "", fmt.Errorf("checking session: %w", err)
 	}
@@ -429,14 +459,14 @@ func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, er
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.backend.CapturePane(sessionID, lines)
 }
 
 // Inject sends a message to a polecat session.
 func (m *SessionManager) Inject(polecat, message string) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -449,7 +479,7 @@ func (m *SessionManager) Inject(polecat, message string) error {
 		debounceMs = 1500
 	}
 
-	return m.tmux.SendKeysDebounced(sessionID, message, debounceMs)
+	return m.backend.SendKeysDebounced(sessionID, message, debounceMs)
 }
 
 // StopAll terminates all polecat sessions for this rig.
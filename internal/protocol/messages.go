@@ -20,7 +20,7 @@ func NewMergeReadyMessage(rig, polecat, branch, issue string) *mail.Message {
 		Timestamp: time.Now(),
 	}
 
-	body := formatMergeReadyBody(payload)
+	body := EncodeBody(formatMergeReadyBody(payload), payload)
 
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/witness", rig),
@@ -60,7 +60,7 @@ func NewMergedMessage(rig, polecat, branch, issue, targetBranch, mergeCommit str
 		TargetBranch: targetBranch,
 	}
 
-	body := formatMergedBody(payload)
+	body := EncodeBody(formatMergedBody(payload), payload)
 
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/refinery", rig),
@@ -92,7 +92,7 @@ func formatMergedBody(p MergedPayload) string {
 // NewMergeFailedMessage creates a MERGE_FAILED protocol message.
 // Sent by Refinery to Witness when merge fails (tests, build, etc.).
 func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureType, errorMsg string) *mail.Message {
-	payload := MergeFailedPayload{
+	return NewMergeFailedMessageFromPayload(MergeFailedPayload{
 		Branch:       branch,
 		Issue:        issue,
 		Polecat:      polecat,
@@ -101,14 +101,21 @@ func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureTyp
 		FailureType:  failureType,
 		Error:        errorMsg,
 		TargetBranch: targetBranch,
-	}
+	})
+}
 
-	body := formatMergeFailedBody(payload)
+// NewMergeFailedMessageFromPayload creates a MERGE_FAILED protocol message
+// from a full payload, including test failure details.
+func NewMergeFailedMessageFromPayload(payload MergeFailedPayload) *mail.Message {
+	if payload.FailedAt.IsZero() {
+		payload.FailedAt = time.Now()
+	}
+	body := EncodeBody(formatMergeFailedBody(payload), payload)
 
 	msg := mail.NewMessage(
-		fmt.Sprintf("%s/refinery", rig),
-		fmt.Sprintf("%s/witness", rig),
-		fmt.Sprintf("MERGE_FAILED %s", polecat),
+		fmt.Sprintf("%s/refinery", payload.Rig),
+		fmt.Sprintf("%s/witness", payload.Rig),
+		fmt.Sprintf("MERGE_FAILED %s", payload.Polecat),
 		body,
 	)
 	msg.Priority = mail.PriorityHigh
@@ -128,9 +135,27 @@ func formatMergeFailedBody(p MergeFailedPayload) string {
 	sb.WriteString(fmt.Sprintf("Failed-At: %s\n", p.FailedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("Failure-Type: %s\n", p.FailureType))
 	sb.WriteString(fmt.Sprintf("Error: %s\n", p.Error))
+	if len(p.FailedTests) > 0 {
+		sb.WriteString(fmt.Sprintf("Failed-Tests: %s\n", strings.Join(p.FailedTests, ", ")))
+	}
+	if len(p.FlakyTests) > 0 {
+		sb.WriteString(fmt.Sprintf("Flaky-Tests: %s\n", strings.Join(p.FlakyTests, ", ")))
+	}
+	if p.Artifact != "" {
+		sb.WriteString(fmt.Sprintf("Artifact: %s\n", p.Artifact))
+	}
+	// The excerpt is multi-line, so it goes last
+	if p.LogExcerpt != "" {
+		sb.WriteString(logExcerptMarker + "\n")
+		sb.WriteString(p.LogExcerpt)
+		sb.WriteString("\n")
+	}
 	return sb.String()
 }
 
+// logExcerptMarker starts the multi-line log excerpt of a MERGE_FAILED body.
+const logExcerptMarker = "Log-Excerpt:"
+
 // NewReworkRequestMessage creates a REWORK_REQUEST protocol message.
 // Sent by Refinery to Witness when a branch needs rebasing due to conflicts.
 func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, conflictFiles []string) *mail.Message {
@@ -145,7 +170,7 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 		Instructions:  formatRebaseInstructions(targetBranch),
 	}
 
-	body := formatReworkRequestBody(payload)
+	body := EncodeBody(formatReworkRequestBody(payload), payload)
 
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/refinery", rig),
@@ -191,7 +216,9 @@ func formatRebaseInstructions(targetBranch string) string {
 The Refinery will retry the merge after rebase is complete.`, targetBranch, targetBranch)
 }
 
-// ParseMergeReadyPayload parses a MERGE_READY message body into a payload.
+// ParseMergeReadyPayload parses the "Key: value" text of a MERGE_READY body.
+// It reads mail sent before bodies carried a payload line; DecodeMergeReady
+// prefers the payload line.
 func ParseMergeReadyPayload(body string) *MergeReadyPayload {
 	return &MergeReadyPayload{
 		Branch:    parseField(body, "Branch"),
@@ -203,7 +230,8 @@ func ParseMergeReadyPayload(body string) *MergeReadyPayload {
 	}
 }
 
-// ParseMergedPayload parses a MERGED message body into a payload.
+// ParseMergedPayload parses the "Key: value" text of a MERGED body (see
+// DecodeMerged).
 func ParseMergedPayload(body string) *MergedPayload {
 	payload := &MergedPayload{
 		Branch:       parseField(body, "Branch"),
@@ -224,29 +252,49 @@ func ParseMergedPayload(body string) *MergedPayload {
 	return payload
 }
 
-// ParseMergeFailedPayload parses a MERGE_FAILED message body into a payload.
+// ParseMergeFailedPayload parses the "Key: value" text of a MERGE_FAILED
+// body (see DecodeMergeFailed).
 func ParseMergeFailedPayload(body string) *MergeFailedPayload {
+	body = mail.StripPayload(body)
+
+	// The log excerpt runs to the end of the body; fields come before it
+	header, excerpt := body, ""
+	if i := strings.Index(body, "\n"+logExcerptMarker+"\n"); i >= 0 {
+		header = body[:i]
+		excerpt = strings.TrimRight(body[i+len(logExcerptMarker)+2:], "\n")
+	}
+
 	payload := &MergeFailedPayload{
-		Branch:       parseField(body, "Branch"),
-		Issue:        parseField(body, "Issue"),
-		Polecat:      parseField(body, "Polecat"),
-		Rig:          parseField(body, "Rig"),
-		TargetBranch: parseField(body, "Target"),
-		FailureType:  parseField(body, "Failure-Type"),
-		Error:        parseField(body, "Error"),
+		Branch:       parseField(header, "Branch"),
+		Issue:        parseField(header, "Issue"),
+		Polecat:      parseField(header, "Polecat"),
+		Rig:          parseField(header, "Rig"),
+		TargetBranch: parseField(header, "Target"),
+		FailureType:  parseField(header, "Failure-Type"),
+		Error:        parseField(header, "Error"),
+		Artifact:     parseField(header, "Artifact"),
+		LogExcerpt:   excerpt,
 	}
 
 	// Parse timestamp
-	if ts := parseField(body, "Failed-At"); ts != "" {
+	if ts := parseField(header, "Failed-At"); ts != "" {
 		if t, err := time.Parse(time.RFC3339, ts); err == nil {
 			payload.FailedAt = t
 		}
 	}
 
+	if tests := parseField(header, "Failed-Tests"); tests != "" {
+		payload.FailedTests = strings.Split(tests, ", ")
+	}
+	if tests := parseField(header, "Flaky-Tests"); tests != "" {
+		payload.FlakyTests = strings.Split(tests, ", ")
+	}
+
 	return payload
 }
 
-// ParseReworkRequestPayload parses a REWORK_REQUEST message body into a payload.
+// ParseReworkRequestPayload parses the "Key: value" text of a
+// REWORK_REQUEST body (see DecodeReworkRequest).
 func ParseReworkRequestPayload(body string) *ReworkRequestPayload {
 	payload := &ReworkRequestPayload{
 		Branch:       parseField(body, "Branch"),
@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/speaker20/whaletown/internal/mail"
+)
+
+// Payload is implemented by the payload type of every protocol message.
+type Payload interface {
+	MessageType() MessageType
+}
+
+// MessageType implements Payload.
+func (MergeReadyPayload) MessageType() MessageType { return TypeMergeReady }
+
+// MessageType implements Payload.
+func (MergedPayload) MessageType() MessageType { return TypeMerged }
+
+// MessageType implements Payload.
+func (MergeFailedPayload) MessageType() MessageType { return TypeMergeFailed }
+
+// MessageType implements Payload.
+func (ReworkRequestPayload) MessageType() MessageType { return TypeReworkRequest }
+
+// EncodeBody returns the human-readable text followed by p's payload line.
+func EncodeBody(text string, p Payload) string {
+	body, err := mail.AttachPayload(text, string(p.MessageType()), p)
+	if err != nil {
+		// The payload types are plain structs; marshaling can't fail
+		return text
+	}
+	return body
+}
+
+// MessageTypeOf returns the protocol type of a message: the payload's
+// type when the body carries one, else the subject prefix. Returns empty
+// string for non-protocol messages.
+func MessageTypeOf(msg *mail.Message) MessageType {
+	if env, err := mail.ReadEnvelope(msg.Body); err == nil && env != nil {
+		if t := knownType(env.Type); t != "" {
+			return t
+		}
+	}
+	return ParseMessageType(msg.Subject)
+}
+
+// knownType maps a payload type name to its MessageType.
+func knownType(name string) MessageType {
+	switch t := MessageType(name); t {
+	case TypeMergeReady, TypeMerged, TypeMergeFailed, TypeReworkRequest:
+		return t
+	}
+	return ""
+}
+
+// DecodeMergeReady returns the payload of a MERGE_READY message.
+func DecodeMergeReady(msg *mail.Message) (*MergeReadyPayload, error) {
+	return decode(msg.Body, TypeMergeReady, ParseMergeReadyPayload)
+}
+
+// DecodeMerged returns the payload of a MERGED message.
+func DecodeMerged(msg *mail.Message) (*MergedPayload, error) {
+	return decode(msg.Body, TypeMerged, ParseMergedPayload)
+}
+
+// DecodeMergeFailed returns the payload of a MERGE_FAILED message.
+func DecodeMergeFailed(msg *mail.Message) (*MergeFailedPayload, error) {
+	return decode(msg.Body, TypeMergeFailed, ParseMergeFailedPayload)
+}
+
+// DecodeReworkRequest returns the payload of a REWORK_REQUEST message.
+func DecodeReworkRequest(msg *mail.Message) (*ReworkRequestPayload, error) {
+	return decode(msg.Body, TypeReworkRequest, ParseReworkRequestPayload)
+}
+
+// decode reads the payload line of body as want, or, for bodies without
+// one, parses the legacy "Key: value" text.
+func decode[T any](body string, want MessageType, legacy func(string) *T) (*T, error) {
+	env, err := mail.ReadEnvelope(body)
+	if err != nil {
+		return nil, err
+	}
+	if env == nil {
+		return legacy(body), nil
+	}
+	if env.Type != string(want) {
+		return nil, fmt.Errorf("payload is %s, want %s", env.Type, want)
+	}
+	var p T
+	if err := env.Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
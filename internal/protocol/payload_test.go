@@ -0,0 +1,65 @@
+package protocol
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/mail"
+)
+
+func TestMergeFailed_PayloadSurvivesMultilineError(t *testing.T) {
+	msg := NewMergeFailedMessage("wt-rig", "nux", "polecat/nux/wt-abc", "wt-abc", "main", "tests",
+		"FAIL: TestX\nBranch: wrong\nError: not the end")
+
+	if got := MessageTypeOf(msg); got != TypeMergeFailed {
+		t.Errorf("MessageTypeOf = %q, want %q", got, TypeMergeFailed)
+	}
+	p, err := DecodeMergeFailed(msg)
+	if err != nil {
+		t.Fatalf("DecodeMergeFailed: %v", err)
+	}
+	if p.Branch != "polecat/nux/wt-abc" || p.FailureType != "tests" {
+		t.Errorf("payload = %+v", p)
+	}
+	if !strings.Contains(p.Error, "Error: not the end") {
+		t.Errorf("Error = %q, want full multi-line error", p.Error)
+	}
+}
+
+func TestDecode_LegacyBody(t *testing.T) {
+	msg := &mail.Message{
+		Subject: "MERGED nux",
+		Body:    "Branch: polecat/nux\nIssue: wt-abc\nPolecat: nux\nRig: wt-rig\nTarget: main\nMerge-Commit: abc123\n",
+	}
+	p, err := DecodeMerged(msg)
+	if err != nil {
+		t.Fatalf("DecodeMerged: %v", err)
+	}
+	if p.Branch != "polecat/nux" || p.MergeCommit != "abc123" {
+		t.Errorf("payload = %+v", p)
+	}
+}
+
+func TestDecode_TypeMismatch(t *testing.T) {
+	msg := NewMergedMessage("wt-rig", "nux", "polecat/nux", "wt-abc", "main", "abc123")
+	if _, err := DecodeMergeFailed(msg); err == nil {
+		t.Error("DecodeMergeFailed of a MERGED payload: want error")
+	}
+}
+
+func TestHandlerRegistry_DispatchesOnPayloadType(t *testing.T) {
+	// The subject says MERGED; the payload says MERGE_FAILED and wins.
+	msg := NewMergeFailedMessage("wt-rig", "nux", "polecat/nux", "wt-abc", "main", "build", "boom")
+	msg.Subject = "MERGED nux"
+
+	var got MessageType
+	r := NewHandlerRegistry()
+	r.Register(TypeMerged, func(*mail.Message) error { got = TypeMerged; return nil })
+	r.Register(TypeMergeFailed, func(*mail.Message) error { got = TypeMergeFailed; return nil })
+	if err := r.Handle(msg); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if got != TypeMergeFailed {
+		t.Errorf("dispatched to %q, want %q", got, TypeMergeFailed)
+	}
+}
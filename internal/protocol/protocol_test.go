@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"bytes"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -136,6 +137,39 @@ func TestNewMergeFailedMessage(t *testing.T) {
 	}
 }
 
+func TestMergeFailedTestDetailsRoundTrip(t *testing.T) {
+	msg := NewMergeFailedMessageFromPayload(MergeFailedPayload{
+		Branch:       "polecat/nux/wt-abc",
+		Issue:        "wt-abc",
+		Polecat:      "nux",
+		Rig:          "whaletown",
+		FailureType:  "tests",
+		Error:        "tests failed after 2 attempt(s) (failed: pkg.TestA, pkg.TestB)",
+		TargetBranch: "main",
+		FailedTests:  []string{"pkg.TestA", "pkg.TestB"},
+		FlakyTests:   []string{"pkg.TestC"},
+		Artifact:     "/rig/.runtime/refinery/test-output/wt-mr1.log",
+		LogExcerpt:   "--- FAIL: pkg.TestA\nBranch: not a field\n",
+	})
+
+	payload := ParseMergeFailedPayload(msg.Body)
+	if !reflect.DeepEqual(payload.FailedTests, []string{"pkg.TestA", "pkg.TestB"}) {
+		t.Errorf("FailedTests = %v", payload.FailedTests)
+	}
+	if !reflect.DeepEqual(payload.FlakyTests, []string{"pkg.TestC"}) {
+		t.Errorf("FlakyTests = %v", payload.FlakyTests)
+	}
+	if payload.Artifact != "/rig/.runtime/refinery/test-output/wt-mr1.log" {
+		t.Errorf("Artifact = %q", payload.Artifact)
+	}
+	if payload.Branch != "polecat/nux/wt-abc" {
+		t.Errorf("excerpt leaked into fields: Branch = %q", payload.Branch)
+	}
+	if !strings.Contains(payload.LogExcerpt, "Branch: not a field") {
+		t.Errorf("LogExcerpt = %q", payload.LogExcerpt)
+	}
+}
+
 func TestNewReworkRequestMessage(t *testing.T) {
 	conflicts := []string{"file1.go", "file2.go"}
 	msg := NewReworkRequestMessage("whaletown", "nux", "polecat/nux/gt-abc", "wt-abc", "main", conflicts)
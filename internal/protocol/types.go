@@ -8,6 +8,11 @@
 //   - MERGED: Refinery → Witness (merge succeeded, cleanup ok)
 //   - MERGE_FAILED: Refinery → Witness (merge failed, needs rework)
 //   - REWORK_REQUEST: Refinery → Witness (rebase needed)
+//
+// Each message body carries the payload as a versioned JSON line after the
+// human-readable text (see mail.Envelope). Handlers dispatch on the
+// payload's type and decode it with the Decode* helpers, which fall back to
+// the "Key: value" text for mail sent before payload lines existed.
 package protocol
 
 import (
@@ -135,6 +140,19 @@ type MergeFailedPayload struct {
 
 	// TargetBranch is the branch we tried to merge into.
 	TargetBranch string `json:"target_branch"`
+
+	// FailedTests names the tests that failed, when the refinery could
+	// parse the test output.
+	FailedTests []string `json:"failed_tests,omitempty"`
+
+	// FlakyTests names tests that failed and then passed on retry.
+	FlakyTests []string `json:"flaky_tests,omitempty"`
+
+	// Artifact is the path of the captured test output.
+	Artifact string `json:"artifact,omitempty"`
+
+	// LogExcerpt is the failing tests' output (or the tail of the log).
+	LogExcerpt string `json:"log_excerpt,omitempty"`
 }
 
 // ReworkRequestPayload contains the data for a REWORK_REQUEST message.
@@ -150,6 +150,20 @@ Thank you for your contribution! Your worktree will be cleaned up shortly.`,
 
 // notifyPolecatFailed sends a merge failure notification to a polecat.
 func (h *DefaultWitnessHandler) notifyPolecatFailed(payload *MergeFailedPayload) error {
+	testInfo := ""
+	if len(payload.FailedTests) > 0 {
+		testInfo += "\nFailed tests:\n"
+		for _, t := range payload.FailedTests {
+			testInfo += fmt.Sprintf("  - %s\n", t)
+		}
+	}
+	if payload.Artifact != "" {
+		testInfo += fmt.Sprintf("\nFull test output: %s\n", payload.Artifact)
+	}
+	if payload.LogExcerpt != "" {
+		testInfo += fmt.Sprintf("\nLog excerpt:\n%s\n", payload.LogExcerpt)
+	}
+
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/witness", h.Rig),
 		fmt.Sprintf("%s/%s", h.Rig, payload.Polecat),
@@ -160,12 +174,13 @@ Branch: %s
 Issue: %s
 Failure: %s
 Error: %s
-
+%s
 Please fix the issue and resubmit your work with 'wt done'.`,
 			payload.Branch,
 			payload.Issue,
 			payload.FailureType,
 			payload.Error,
+			testInfo,
 		),
 	)
 	msg.Priority = mail.PriorityHigh
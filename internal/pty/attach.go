@@ -0,0 +1,88 @@
+package pty
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// DetachKey is the byte that detaches 'wt pty attach' from a session
+// (Ctrl-\).
+const DetachKey = 0x1c
+
+// AttachSession joins the current terminal to a session until the session
+// exits or the user presses Ctrl-\. The session's recent scrollback is
+// replayed first.
+func (c *Client) AttachSession(session string) error {
+	conn, err := net.DialTimeout("unix", c.socketPath, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("no pty supervisor running for this town")
+	}
+	defer func() { _ = conn.Close() }()
+
+	stdin := int(os.Stdin.Fd())
+	req := request{Op: opAttach, Name: session}
+	if cols, rows, err := term.GetSize(stdin); err == nil {
+		req.Rows, req.Cols = rows, cols
+	}
+	_, r, err := exchange(conn, req)
+	if err != nil {
+		return err
+	}
+
+	if term.IsTerminal(stdin) {
+		state, err := term.MakeRaw(stdin)
+		if err != nil {
+			return fmt.Errorf("setting raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(stdin, state) }()
+	}
+
+	// Session output runs until the supervisor closes the stream
+	exited := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(os.Stdout, r)
+		close(exited)
+	}()
+
+	detached := make(chan struct{})
+	go func() {
+		if copyUntilDetach(conn, os.Stdin) {
+			close(detached)
+		}
+	}()
+
+	select {
+	case <-exited:
+		fmt.Fprintf(os.Stdout, "\r\n[%s exited]\r\n", session)
+	case <-detached:
+		fmt.Fprintf(os.Stdout, "\r\n[detached from %s]\r\n", session)
+	}
+	return nil
+}
+
+// copyUntilDetach forwards input to the session until the detach key is
+// pressed (returning true) or input ends.
+func copyUntilDetach(w io.Writer, r io.Reader) bool {
+	buf := make([]byte, 1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if i := bytes.IndexByte(buf[:n], DetachKey); i >= 0 {
+				_, _ = w.Write(buf[:i])
+				return true
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return false
+			}
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
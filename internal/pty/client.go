@@ -0,0 +1,497 @@
+package pty
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/constants"
+	"github.com/speaker20/whaletown/internal/tmux"
+)
+
+// versionPattern matches Claude Code's version-number process title.
+var versionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+`)
+
+// nudgeLocks serializes nudges per session, as the tmux backend does.
+var nudgeLocks sync.Map
+
+// Client talks to a town's pty supervisor. It mirrors the *tmux.Tmux API
+// so it can stand in for tmux as a session backend: tmux key names are
+// translated, and pane queries are answered from the session's pty.
+//
+// Errors use the tmux sentinels (tmux.ErrNoServer, tmux.ErrSessionNotFound)
+// so callers need not care which backend they talk to.
+type Client struct {
+	townRoot   string
+	socketPath string
+}
+
+// NewClient returns a client for the supervisor of the town at townRoot.
+func NewClient(townRoot string) *Client {
+	return &Client{townRoot: townRoot, socketPath: SocketPath(townRoot)}
+}
+
+// call sends one request and reads the response.
+func (c *Client) call(req request) (*response, error) {
+	conn, err := net.DialTimeout("unix", c.socketPath, 2*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("pty %s: %w", req.Op, tmux.ErrNoServer)
+	}
+	defer func() { _ = conn.Close() }()
+
+	resp, _, err := exchange(conn, req)
+	return resp, err
+}
+
+// exchange writes a request on conn and reads its response, returning the
+// reader positioned after the response line.
+func exchange(conn net.Conn, req request) (*response, *bufio.Reader, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, nil, fmt.Errorf("pty %s: %w", req.Op, err)
+	}
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, nil, fmt.Errorf("pty %s: reading response: %w", req.Op, err)
+	}
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, nil, fmt.Errorf("pty %s: invalid response: %w", req.Op, err)
+	}
+	if !resp.OK {
+		if resp.NotFound {
+			return &resp, r, fmt.Errorf("pty %s: %w", req.Op, tmux.ErrSessionNotFound)
+		}
+		return &resp, r, fmt.Errorf("pty %s: %s", req.Op, resp.Error)
+	}
+	return &resp, r, nil
+}
+
+// Ping reports the supervisor's PID, or an error if it isn't running.
+func (c *Client) Ping() (int, error) {
+	resp, err := c.call(request{Op: opPing})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(resp.Value)
+}
+
+// EnsureServer starts the town's supervisor ('wt pty serve') in the
+// background if it isn't running, and waits for it to answer.
+func (c *Client) EnsureServer() error {
+	if _, err := c.Ping(); err == nil {
+		return nil
+	}
+	if !supported {
+		return errUnsupportedPlatform
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding executable: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.socketPath), 0755); err != nil {
+		return fmt.Errorf("creating daemon directory: %w", err)
+	}
+	logFile, err := os.OpenFile(LogPath(c.townRoot), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("opening supervisor log: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	cmd := exec.Command(exe, "pty", "serve") //nolint:gosec // G204: our own executable
+	cmd.Dir = c.townRoot
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	configureDetached(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting pty supervisor: %w", err)
+	}
+	go func() { _ = cmd.Wait() }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := c.Ping(); err == nil {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("pty supervisor did not start (see %s)", LogPath(c.townRoot))
+}
+
+// IsAvailable ensures the supervisor is running and reports whether it is.
+func (c *Client) IsAvailable() bool {
+	return c.EnsureServer() == nil
+}
+
+// NewSession creates a new session running the user's shell.
+func (c *Client) NewSession(name, workDir string) error {
+	return c.NewSessionWithCommand(name, workDir, "")
+}
+
+// NewSessionWithCommand creates a new session whose pty runs command as
+// its initial process.
+func (c *Client) NewSessionWithCommand(name, workDir, command string) error {
+	if err := c.EnsureServer(); err != nil {
+		return err
+	}
+	_, err := c.call(request{Op: opStart, Name: name, Dir: workDir, Command: command})
+	return err
+}
+
+// EnsureSessionFresh creates a session, first killing an existing one
+// whose agent has died.
+func (c *Client) EnsureSessionFresh(name, workDir string) error {
+	exists, err := c.HasSession(name)
+	if err != nil {
+		return fmt.Errorf("checking session: %w", err)
+	}
+	if exists {
+		if c.IsAgentRunning(name) {
+			return nil
+		}
+		if err := c.KillSession(name); err != nil {
+			return fmt.Errorf("killing zombie session: %w", err)
+		}
+	}
+	return c.NewSession(name, workDir)
+}
+
+// KillSession hangs up a session's terminal, killing its process group
+// if it doesn't exit.
+func (c *Client) KillSession(name string) error {
+	_, err := c.call(request{Op: opKill, Name: name})
+	return err
+}
+
+// KillSessionWithProcesses kills a session's whole process group without
+// waiting for it to handle the hangup.
+func (c *Client) KillSessionWithProcesses(name string) error {
+	_, err := c.call(request{Op: opKill, Name: name, Force: true})
+	return err
+}
+
+// HasSession checks if a session exists.
+func (c *Client) HasSession(name string) (bool, error) {
+	resp, err := c.call(request{Op: opHas, Name: name})
+	if err != nil {
+		if errors.Is(err, tmux.ErrNoServer) {
+			return false, nil
+		}
+		return false, err
+	}
+	return resp.Exists, nil
+}
+
+// ListSessions returns all session names.
+func (c *Client) ListSessions() ([]string, error) {
+	sessions, err := c.Sessions()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+// Sessions returns the status of every session, sorted by name.
+func (c *Client) Sessions() ([]SessionStatus, error) {
+	resp, err := c.call(request{Op: opList})
+	if err != nil {
+		if errors.Is(err, tmux.ErrNoServer) {
+			return nil, nil // No server = no sessions
+		}
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// Status returns the status of one session.
+func (c *Client) Status(name string) (*SessionStatus, error) {
+	resp, err := c.call(request{Op: opInfo, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Session, nil
+}
+
+// sendLiteral types text into a session.
+func (c *Client) sendLiteral(session, text string) error {
+	_, err := c.call(request{Op: opSend, Name: session, Keys: text, Literal: true})
+	return err
+}
+
+// SendKeys types text into a session and presses Enter.
+func (c *Client) SendKeys(session, keys string) error {
+	return c.SendKeysDebounced(session, keys, constants.DefaultDebounceMs)
+}
+
+// SendKeysDebounced types text, waits debounceMs, then presses Enter.
+func (c *Client) SendKeysDebounced(session, keys string, debounceMs int) error {
+	if err := c.sendLiteral(session, keys); err != nil {
+		return err
+	}
+	if debounceMs > 0 {
+		time.Sleep(time.Duration(debounceMs) * time.Millisecond)
+	}
+	return c.SendKeysRaw(session, "Enter")
+}
+
+// SendKeysRaw sends a tmux key name ("Enter", "C-c", "Down") or text
+// without pressing Enter.
+func (c *Client) SendKeysRaw(session, keys string) error {
+	_, err := c.call(request{Op: opSend, Name: session, Keys: keys})
+	return err
+}
+
+// NudgeSession sends a message to an agent session reliably, with the
+// same sequence as the tmux backend: text, pause, Escape, then Enter with
+// retries. Nudges to one session are serialized.
+func (c *Client) NudgeSession(session, message string) error {
+	actual, _ := nudgeLocks.LoadOrStore(session, &sync.Mutex{})
+	lock := actual.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := c.sendLiteral(session, message); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	_ = c.SendKeysRaw(session, "Escape")
+	time.Sleep(100 * time.Millisecond)
+
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		if lastErr = c.SendKeysRaw(session, "Enter"); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to send Enter after 3 attempts: %w", lastErr)
+}
+
+// AcceptBypassPermissionsWarning dismisses Claude Code's bypass
+// permissions warning if it is showing.
+func (c *Client) AcceptBypassPermissionsWarning(session string) error {
+	time.Sleep(1 * time.Second)
+	content, err := c.CapturePane(session, 30)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(content, "Bypass Permissions mode") {
+		return nil
+	}
+	if err := c.SendKeysRaw(session, "Down"); err != nil {
+		return err
+	}
+	time.Sleep(200 * time.Millisecond)
+	return c.SendKeysRaw(session, "Enter")
+}
+
+// CapturePane returns the last lines of a session's output as plain text.
+func (c *Client) CapturePane(session string, lines int) (string, error) {
+	resp, err := c.call(request{Op: opCapture, Name: session, Lines: lines})
+	if err != nil {
+		return "", err
+	}
+	return resp.Value, nil
+}
+
+// CapturePaneLines returns the last lines of a session's output.
+func (c *Client) CapturePaneLines(session string, lines int) ([]string, error) {
+	out, err := c.CapturePane(session, lines)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// SetEnvironment records a variable on the session. Like tmux's session
+// environment, it does not reach processes already running.
+func (c *Client) SetEnvironment(session, key, value string) error {
+	_, err := c.call(request{Op: opSetEnv, Name: session, Key: key, Value: value})
+	return err
+}
+
+// GetEnvironment returns a variable recorded on the session.
+func (c *Client) GetEnvironment(session, key string) (string, error) {
+	resp, err := c.call(request{Op: opGetEnv, Name: session, Key: key})
+	if err != nil {
+		return "", err
+	}
+	return resp.Value, nil
+}
+
+// ConfigureWhaleTownSession is a no-op: pty sessions have no status bar.
+func (c *Client) ConfigureWhaleTownSession(session string, theme tmux.Theme, rig, worker, role string) error {
+	return nil
+}
+
+// SetPaneDiedHook makes the supervisor run 'wt log crash' for the agent
+// if the session's process exits without being killed.
+func (c *Client) SetPaneDiedHook(session, agentID string) error {
+	_, err := c.call(request{Op: opHook, Name: session, Value: agentID})
+	return err
+}
+
+// GetPaneCommand returns the command in the foreground of a session's
+// terminal ("bash", "claude", "node").
+func (c *Client) GetPaneCommand(session string) (string, error) {
+	status, err := c.Status(session)
+	if err != nil {
+		return "", err
+	}
+	return status.ForegroundCommand, nil
+}
+
+// IsAgentRunning checks if an agent appears to be running in the session.
+// With expected commands, the foreground command must be one of them;
+// otherwise any non-shell command counts.
+func (c *Client) IsAgentRunning(session string, expectedPaneCommands ...string) bool {
+	cmd, err := c.GetPaneCommand(session)
+	if err != nil {
+		return false
+	}
+	if len(expectedPaneCommands) > 0 {
+		for _, expected := range expectedPaneCommands {
+			if expected != "" && cmd == expected {
+				return true
+			}
+		}
+		return false
+	}
+	return cmd != "" && !isShell(cmd)
+}
+
+// IsClaudeRunning checks if Claude appears to be running in the session,
+// either in the foreground or as a child of the session's shell.
+func (c *Client) IsClaudeRunning(session string) bool {
+	status, err := c.Status(session)
+	if err != nil {
+		return false
+	}
+	cmd := status.ForegroundCommand
+	if cmd == "node" || cmd == "claude" || versionPattern.MatchString(cmd) {
+		return true
+	}
+	if isShell(cmd) {
+		return hasChildNamed(status.ForegroundPID, "node", "claude")
+	}
+	return false
+}
+
+// IsRuntimeRunning checks if the foreground command is one of processNames.
+func (c *Client) IsRuntimeRunning(session string, processNames []string) bool {
+	if len(processNames) == 0 {
+		return false
+	}
+	return c.IsAgentRunning(session, processNames...)
+}
+
+// WaitForCommand polls until the session's foreground command is not one
+// of excludeCommands.
+func (c *Client) WaitForCommand(session string, excludeCommands []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		cmd, err := c.GetPaneCommand(session)
+		if err == nil && cmd != "" && !contains(excludeCommands, cmd) {
+			return nil
+		}
+		time.Sleep(constants.PollInterval)
+	}
+	return fmt.Errorf("timeout waiting for command (still running excluded command)")
+}
+
+// WaitForRuntimeReady polls until the runtime's prompt appears in the
+// session's output, or waits the runtime's fixed delay if it has no
+// prompt to look for. See tmux.WaitForRuntimeReady for when this
+// regex-based check is appropriate.
+func (c *Client) WaitForRuntimeReady(session string, rc *config.RuntimeConfig, timeout time.Duration) error {
+	if rc == nil || rc.Tmux == nil {
+		return nil
+	}
+	if rc.Tmux.ReadyPromptPrefix == "" {
+		if rc.Tmux.ReadyDelayMs > 0 {
+			time.Sleep(min(time.Duration(rc.Tmux.ReadyDelayMs)*time.Millisecond, timeout))
+		}
+		return nil
+	}
+
+	prefix := strings.TrimSpace(rc.Tmux.ReadyPromptPrefix)
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		lines, err := c.CapturePaneLines(session, 10)
+		if err == nil {
+			for _, line := range lines {
+				trimmed := strings.TrimSpace(line)
+				if strings.HasPrefix(trimmed, rc.Tmux.ReadyPromptPrefix) || (prefix != "" && trimmed == prefix) {
+					return nil
+				}
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for runtime prompt")
+}
+
+// GetSessionInfo returns information about a session in the tmux format.
+func (c *Client) GetSessionInfo(name string) (*tmux.SessionInfo, error) {
+	status, err := c.Status(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tmux.SessionInfo{
+		Name:     status.Name,
+		Windows:  1,
+		Created:  status.Created.Format(time.ANSIC),
+		Attached: status.Attached > 0,
+		Activity: strconv.FormatInt(status.Activity.Unix(), 10),
+	}, nil
+}
+
+func isShell(cmd string) bool {
+	return contains(constants.SupportedShells, cmd)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// hasChildNamed checks if a process has a direct child with one of names.
+func hasChildNamed(pid int, names ...string) bool {
+	out, err := exec.Command("pgrep", "-P", strconv.Itoa(pid), "-l").Output() //nolint:gosec // G204: pid is an integer
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		// Format: "PID name"
+		if fields := strings.Fields(line); len(fields) >= 2 && contains(names, fields[1]) {
+			return true
+		}
+	}
+	return false
+}
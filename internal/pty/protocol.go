@@ -0,0 +1,124 @@
+// Package pty provides a headless session backend: a supervisor process
+// that runs agent sessions in pseudo-terminals, keeps their recent output
+// in a ring buffer, and serves a client over a Unix socket.
+//
+// It lets agents run where tmux is unavailable (CI runners, containers,
+// bare servers). Sessions can be inspected with 'wt pty list' and joined
+// interactively with 'wt pty attach'.
+package pty
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// DefaultScrollback is the number of output bytes kept per session.
+const DefaultScrollback = 1 << 20
+
+// Default terminal size for new sessions. Agents render for a wide pane,
+// as they would in a tmux session on a desktop terminal.
+const (
+	defaultRows = 50
+	defaultCols = 200
+)
+
+// SocketPath returns the supervisor's socket path for a town.
+func SocketPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "pty.sock")
+}
+
+// LogPath returns the supervisor's log path for a town.
+func LogPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "pty.log")
+}
+
+// Supervisor operations. Each connection carries one request line and one
+// response line; after an attach response the connection becomes a raw
+// byte stream to and from the session's terminal.
+const (
+	opPing    = "ping"
+	opStart   = "start"
+	opKill    = "kill"
+	opHas     = "has"
+	opList    = "list"
+	opInfo    = "info"
+	opSend    = "send"
+	opCapture = "capture"
+	opSetEnv  = "setenv"
+	opGetEnv  = "getenv"
+	opHook    = "hook"
+	opAttach  = "attach"
+)
+
+// request is a client call to the supervisor.
+type request struct {
+	Op      string            `json:"op"`
+	Name    string            `json:"name,omitempty"`
+	Dir     string            `json:"dir,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Keys    string            `json:"keys,omitempty"`
+	Literal bool              `json:"literal,omitempty"`
+	Lines   int               `json:"lines,omitempty"`
+	Key     string            `json:"key,omitempty"`
+	Value   string            `json:"value,omitempty"`
+	Force   bool              `json:"force,omitempty"`
+	Rows    int               `json:"rows,omitempty"`
+	Cols    int               `json:"cols,omitempty"`
+}
+
+// response is the supervisor's answer to a request.
+type response struct {
+	OK       bool            `json:"ok"`
+	Error    string          `json:"error,omitempty"`
+	NotFound bool            `json:"not_found,omitempty"`
+	Exists   bool            `json:"exists,omitempty"`
+	Value    string          `json:"value,omitempty"`
+	Session  *SessionStatus  `json:"session,omitempty"`
+	Sessions []SessionStatus `json:"sessions,omitempty"`
+}
+
+// SessionStatus describes a supervised session.
+type SessionStatus struct {
+	Name              string    `json:"name"`
+	Command           string    `json:"command,omitempty"`
+	WorkDir           string    `json:"work_dir,omitempty"`
+	PID               int       `json:"pid"`
+	ForegroundPID     int       `json:"foreground_pid,omitempty"`
+	ForegroundCommand string    `json:"foreground_command,omitempty"` // Like tmux's pane_current_command
+	Created           time.Time `json:"created"`
+	Activity          time.Time `json:"activity"`
+	Attached          int       `json:"attached"`
+	OutputBytes       int64     `json:"output_bytes"`
+}
+
+// keyNames maps the tmux key names callers send to terminal input.
+var keyNames = map[string]string{
+	"Enter":  "\r",
+	"Escape": "\x1b",
+	"Tab":    "\t",
+	"Space":  " ",
+	"BSpace": "\x7f",
+	"Up":     "\x1b[A",
+	"Down":   "\x1b[B",
+	"Right":  "\x1b[C",
+	"Left":   "\x1b[D",
+}
+
+// translateKeys turns a tmux key name ("Enter", "Down", "C-c") into the
+// bytes a terminal would send. Anything else is sent as typed.
+func translateKeys(keys string) string {
+	if b, ok := keyNames[keys]; ok {
+		return b
+	}
+	if len(keys) == 3 && keys[:2] == "C-" {
+		c := keys[2]
+		switch {
+		case c >= 'a' && c <= 'z':
+			return string(c - 'a' + 1)
+		case c >= '@' && c <= '_':
+			return string(c - '@')
+		}
+	}
+	return keys
+}
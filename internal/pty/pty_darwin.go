@@ -0,0 +1,56 @@
+//go:build darwin
+
+package pty
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY allocates a pseudo-terminal pair.
+func openPTY() (master, tty *os.File, err error) {
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening /dev/ptmx: %w", err)
+	}
+	// The master stays in blocking mode: the poller doesn't support
+	// ptys on darwin.
+	master = os.NewFile(uintptr(fd), "/dev/ptmx")
+
+	if err := ioctl(fd, unix.TIOCPTYGRANT, 0); err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("granting pty: %w", err)
+	}
+	if err := ioctl(fd, unix.TIOCPTYUNLK, 0); err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("unlocking pty: %w", err)
+	}
+	buf := make([]byte, 128)
+	if err := ioctl(fd, unix.TIOCPTYGNAME, uintptr(unsafe.Pointer(&buf[0]))); err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("getting pty name: %w", err)
+	}
+
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[:i]
+	}
+	name := string(buf)
+	tty, err = os.OpenFile(name, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("opening %s: %w", name, err)
+	}
+	return master, tty, nil
+}
+
+func ioctl(fd int, req uint, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(req), arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
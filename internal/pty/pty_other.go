@@ -0,0 +1,35 @@
+//go:build !linux && !darwin
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+)
+
+// supported reports whether this platform can run the supervisor.
+const supported = false
+
+func openPTY() (master, tty *os.File, err error) {
+	return nil, nil, errUnsupportedPlatform
+}
+
+func setWinsize(f *os.File, rows, cols int) error {
+	return errUnsupportedPlatform
+}
+
+func foregroundPID(f *os.File) int {
+	return 0
+}
+
+func configureSession(cmd *exec.Cmd) {}
+
+func configureDetached(cmd *exec.Cmd) {}
+
+func signalSession(p *os.Process, force bool) error {
+	return p.Kill()
+}
+
+func processName(pid int) string {
+	return ""
+}
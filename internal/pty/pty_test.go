@@ -0,0 +1,79 @@
+package pty
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(8)
+	_, _ = r.Write([]byte("abc"))
+	if got := string(r.Bytes()); got != "abc" {
+		t.Errorf("Bytes = %q, want abc", got)
+	}
+
+	_, _ = r.Write([]byte("defgh")) // Exactly full
+	if got := string(r.Bytes()); got != "abcdefgh" {
+		t.Errorf("Bytes = %q, want abcdefgh", got)
+	}
+
+	_, _ = r.Write([]byte("ij")) // Wraps
+	if got := string(r.Bytes()); got != "cdefghij" {
+		t.Errorf("Bytes = %q, want cdefghij", got)
+	}
+
+	_, _ = r.Write([]byte("0123456789")) // Larger than the buffer
+	if got := string(r.Bytes()); got != "23456789" {
+		t.Errorf("Bytes = %q, want 23456789", got)
+	}
+	if r.Total() != 20 {
+		t.Errorf("Total = %d, want 20", r.Total())
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"lines", "one\r\ntwo\r\n", []string{"one", "two"}},
+		{"colors", "\x1b[1;32mok\x1b[0m done\r\n", []string{"ok done"}},
+		{"carriage return overwrites", "50%\r100%\r\n", []string{"100%"}},
+		{"erase line", "typing\r\x1b[Kdone\n", []string{"done"}},
+		{"backspace", "ab\bc\n", []string{"ac"}},
+		{"title sequence", "\x1b]0;claude\x07> \n", []string{">"}},
+		{"clear screen", "old\r\n\x1b[2J\x1b[Hnew\r\n", []string{"new"}},
+		{"no trailing newline", "$ ", []string{"$"}},
+	}
+	for _, tt := range tests {
+		if got := Render([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Render(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+
+	if got := lastLines([]string{"a", "b", "c", "", ""}, 2); got != "b\nc" {
+		t.Errorf("lastLines = %q", got)
+	}
+}
+
+func TestTranslateKeys(t *testing.T) {
+	tests := map[string]string{
+		"Enter":  "\r",
+		"Escape": "\x1b",
+		"Down":   "\x1b[B",
+		"C-c":    "\x03",
+		"C-u":    "\x15",
+		"C-[":    "\x1b",
+		"hello":  "hello",
+	}
+	for in, want := range tests {
+		if got := translateKeys(in); got != want {
+			t.Errorf("translateKeys(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if strings.Contains(translateKeys("C-cat"), "\x03") {
+		t.Error("text starting with C- was treated as a key")
+	}
+}
@@ -0,0 +1,82 @@
+//go:build linux || darwin
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// supported reports whether this platform can run the supervisor.
+const supported = true
+
+// setWinsize sets the terminal size of a pty.
+func setWinsize(f *os.File, rows, cols int) error {
+	return control(f, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: uint16(rows), Col: uint16(cols)})
+	})
+}
+
+// foregroundPID returns the foreground process group of a pty, which is
+// what tmux reports as a pane's current command.
+func foregroundPID(f *os.File) int {
+	pgrp := 0
+	_ = control(f, func(fd int) (err error) {
+		pgrp, err = unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+		return err
+	})
+	return pgrp
+}
+
+// control runs fn on a file's descriptor without switching it to
+// blocking mode, as File.Fd would.
+func control(f *os.File, fn func(fd int) error) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := rc.Control(func(fd uintptr) { fnErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// configureSession makes a command the leader of a new session with the
+// pty on its stdin as controlling terminal.
+func configureSession(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+}
+
+// configureDetached makes a command outlive the terminal that started it.
+func configureDetached(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// signalSession signals every process in a session's process group:
+// SIGHUP, as a terminal hangup would, or SIGKILL when forced.
+func signalSession(p *os.Process, force bool) error {
+	sig := unix.SIGHUP
+	if force {
+		sig = unix.SIGKILL
+	}
+	return unix.Kill(-p.Pid, sig)
+}
+
+// processName returns the command name of a process ("claude", "bash").
+func processName(pid int) string {
+	if pid <= 0 {
+		return ""
+	}
+	out, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output() //nolint:gosec // G204: pid is an integer
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(strings.TrimSpace(string(out)))
+}
@@ -0,0 +1,118 @@
+package pty
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Render turns raw terminal output into plain text lines, roughly as a
+// terminal would show it. Escape sequences are dropped, carriage returns
+// and backspaces overwrite, and erase-line and clear-screen are honoured.
+// Cursor addressing is not emulated, so full-screen programs render as
+// the sequence of lines they drew rather than their final screen.
+func Render(data []byte) []string {
+	var lines []string
+	var line []rune
+	col := 0
+
+	put := func(r rune) {
+		if col < len(line) {
+			line[col] = r
+		} else {
+			line = append(line, r)
+		}
+		col++
+	}
+
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b == 0x1b:
+			n, final, params := escapeSequence(data[i:])
+			switch final {
+			case 'K': // Erase in line
+				if params == "" || params == "0" {
+					line = line[:min(col, len(line))]
+				} else if params == "2" {
+					line, col = line[:0], 0
+				}
+			case 'J': // Erase in display
+				if params == "2" || params == "3" {
+					lines, line, col = nil, line[:0], 0
+				}
+			}
+			i += n
+			continue
+		case b == '\n':
+			lines = append(lines, strings.TrimRight(string(line), " "))
+			line, col = nil, 0
+		case b == '\r':
+			col = 0
+		case b == '\b':
+			if col > 0 {
+				col--
+			}
+		case b == '\t':
+			put(' ')
+			for col%8 != 0 {
+				put(' ')
+			}
+		case b < 0x20 || b == 0x7f:
+			// Other control characters don't print
+		default:
+			r, size := utf8.DecodeRune(data[i:])
+			put(r)
+			i += size
+			continue
+		}
+		i++
+	}
+	if len(line) > 0 {
+		lines = append(lines, strings.TrimRight(string(line), " "))
+	}
+	return lines
+}
+
+// escapeSequence measures the escape sequence at the start of data. It
+// returns its length and, for CSI sequences, the final byte and
+// parameters.
+func escapeSequence(data []byte) (n int, final byte, params string) {
+	if len(data) < 2 {
+		return len(data), 0, ""
+	}
+	switch data[1] {
+	case '[': // CSI: parameters and intermediates, then a final byte
+		for j := 2; j < len(data); j++ {
+			if data[j] >= 0x40 && data[j] <= 0x7e {
+				return j + 1, data[j], strings.TrimLeft(string(data[2:j]), "?")
+			}
+		}
+		return len(data), 0, ""
+	case ']', 'P', '_', '^': // OSC and other strings, ended by BEL or ST
+		for j := 2; j < len(data); j++ {
+			if data[j] == 0x07 {
+				return j + 1, 0, ""
+			}
+			if data[j] == 0x1b && j+1 < len(data) && data[j+1] == '\\' {
+				return j + 2, 0, ""
+			}
+		}
+		return len(data), 0, ""
+	case '(', ')', '*', '+', '#': // Character set designation
+		return min(3, len(data)), 0, ""
+	default:
+		return 2, 0, ""
+	}
+}
+
+// lastLines returns the last n lines of rendered output without trailing
+// blank lines, joined with newlines. n <= 0 returns everything.
+func lastLines(lines []string, n int) string {
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,68 @@
+package pty
+
+import "sync"
+
+// Ring is a fixed-size byte buffer that keeps the most recent output of a
+// session. Writes never block and never fail; once full, the oldest bytes
+// are overwritten.
+type Ring struct {
+	mu    sync.Mutex
+	buf   []byte
+	pos   int  // Next write position
+	full  bool // Whether buf has wrapped at least once
+	total int64
+}
+
+// NewRing creates a ring buffer holding up to size bytes.
+func NewRing(size int) *Ring {
+	if size <= 0 {
+		size = DefaultScrollback
+	}
+	return &Ring{buf: make([]byte, size)}
+}
+
+// Write appends p, discarding the oldest bytes if the buffer is full.
+func (r *Ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(p)
+	r.total += int64(n)
+	if n >= len(r.buf) {
+		copy(r.buf, p[n-len(r.buf):])
+		r.pos, r.full = 0, true
+		return n, nil
+	}
+
+	c := copy(r.buf[r.pos:], p)
+	if c < n {
+		copy(r.buf, p[c:])
+		r.full = true
+	}
+	if r.pos+n >= len(r.buf) {
+		r.full = true
+	}
+	r.pos = (r.pos + n) % len(r.buf)
+	return n, nil
+}
+
+// Bytes returns a copy of the buffered output, oldest first.
+func (r *Ring) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]byte(nil), r.buf[:r.pos]...)
+	}
+	out := make([]byte, 0, len(r.buf))
+	out = append(out, r.buf[r.pos:]...)
+	return append(out, r.buf[:r.pos]...)
+}
+
+// Total returns the number of bytes ever written, including those that
+// have since been overwritten.
+func (r *Ring) Total() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.total
+}
@@ -0,0 +1,504 @@
+package pty
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// errNotFound is returned for requests naming a session that doesn't exist.
+	errNotFound = errors.New("session not found")
+
+	errUnsupportedPlatform = errors.New("pty sessions are not supported on this platform")
+)
+
+// Supervisor runs sessions in pseudo-terminals and serves them to clients
+// over a Unix socket. Each session's process is started as the leader of
+// a new process session with the pty as its controlling terminal, and the
+// session is removed when that process exits, as tmux removes a pane.
+type Supervisor struct {
+	socketPath string
+	scrollback int
+	logger     *log.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*ptySession
+	listener net.Listener
+	closed   bool
+}
+
+// ptySession is one supervised process and its terminal.
+type ptySession struct {
+	name    string
+	command string
+	workDir string
+	created time.Time
+	cmd     *exec.Cmd
+	master  *os.File
+	out     *Ring
+	pumped  chan struct{} // Closed when the terminal has no more output
+	done    chan struct{} // Closed when the process has exited
+
+	mu       sync.Mutex
+	activity time.Time
+	env      map[string]string
+	agentID  string // For crash logging, set by the pane-died hook
+	killed   bool
+	clients  map[net.Conn]struct{}
+}
+
+// NewSupervisor creates a supervisor that will listen on socketPath and
+// keep scrollback bytes of output per session. A nil logger discards.
+func NewSupervisor(socketPath string, scrollback int, logger *log.Logger) *Supervisor {
+	if scrollback <= 0 {
+		scrollback = DefaultScrollback
+	}
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	return &Supervisor{
+		socketPath: socketPath,
+		scrollback: scrollback,
+		logger:     logger,
+		sessions:   make(map[string]*ptySession),
+	}
+}
+
+// Listen opens the supervisor's socket. A stale socket left by a
+// supervisor that died is replaced; a live one is an error.
+func (s *Supervisor) Listen() error {
+	if !supported {
+		return fmt.Errorf("starting pty supervisor: %w", errUnsupportedPlatform)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.socketPath), 0755); err != nil {
+		return fmt.Errorf("creating socket directory: %w", err)
+	}
+	if conn, err := net.DialTimeout("unix", s.socketPath, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("pty supervisor already running on %s", s.socketPath)
+	}
+	_ = os.Remove(s.socketPath)
+
+	l, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.socketPath, err)
+	}
+	_ = os.Chmod(s.socketPath, 0600)
+
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+	return nil
+}
+
+// Serve accepts client connections until Close is called.
+func (s *Supervisor) Serve() error {
+	s.mu.Lock()
+	l := s.listener
+	s.mu.Unlock()
+	if l == nil {
+		return errors.New("pty supervisor is not listening")
+	}
+	s.logger.Printf("pty supervisor listening on %s (PID %d)", s.socketPath, os.Getpid())
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close stops listening and kills every session.
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	l := s.listener
+	names := make([]string, 0, len(s.sessions))
+	for name := range s.sessions {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+
+	for _, name := range names {
+		_ = s.kill(name, true)
+	}
+	if l != nil {
+		_ = l.Close()
+		_ = os.Remove(s.socketPath)
+	}
+	return nil
+}
+
+// handle serves one connection: a request line, then a response line or,
+// for attach, a raw terminal stream.
+func (s *Supervisor) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		_ = writeResponse(conn, errorResponse(fmt.Errorf("invalid request: %w", err)))
+		return
+	}
+
+	if req.Op == opAttach {
+		s.attach(conn, r, req)
+		return
+	}
+	_ = writeResponse(conn, s.dispatch(req))
+}
+
+func writeResponse(w io.Writer, resp *response) error {
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func errorResponse(err error) *response {
+	return &response{Error: err.Error(), NotFound: errors.Is(err, errNotFound)}
+}
+
+// dispatch runs a request and builds its response.
+func (s *Supervisor) dispatch(req request) *response {
+	var err error
+	resp := &response{OK: true}
+
+	switch req.Op {
+	case opPing:
+		resp.Value = strconv.Itoa(os.Getpid())
+	case opStart:
+		err = s.start(req)
+	case opKill:
+		err = s.kill(req.Name, req.Force)
+	case opHas:
+		resp.Exists = s.get(req.Name) != nil
+	case opList:
+		resp.Sessions = s.list()
+	case opInfo:
+		var sess *ptySession
+		if sess, err = s.lookup(req.Name); err == nil {
+			status := sess.status()
+			resp.Session = &status
+		}
+	case opSend:
+		var sess *ptySession
+		if sess, err = s.lookup(req.Name); err == nil {
+			keys := req.Keys
+			if !req.Literal {
+				keys = translateKeys(keys)
+			}
+			_, err = sess.master.Write([]byte(keys))
+		}
+	case opCapture:
+		var sess *ptySession
+		if sess, err = s.lookup(req.Name); err == nil {
+			resp.Value = lastLines(Render(sess.out.Bytes()), req.Lines)
+		}
+	case opSetEnv, opGetEnv, opHook:
+		var sess *ptySession
+		if sess, err = s.lookup(req.Name); err == nil {
+			sess.mu.Lock()
+			switch req.Op {
+			case opSetEnv:
+				sess.env[req.Key] = req.Value
+			case opGetEnv:
+				value, ok := sess.env[req.Key]
+				if !ok {
+					err = fmt.Errorf("unknown variable: %s", req.Key)
+				}
+				resp.Value = value
+			case opHook:
+				sess.agentID = req.Value
+			}
+			sess.mu.Unlock()
+		}
+	default:
+		err = fmt.Errorf("unknown operation %q", req.Op)
+	}
+
+	if err != nil {
+		return errorResponse(err)
+	}
+	return resp
+}
+
+func (s *Supervisor) get(name string) *ptySession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[name]
+}
+
+func (s *Supervisor) lookup(name string) (*ptySession, error) {
+	if sess := s.get(name); sess != nil {
+		return sess, nil
+	}
+	return nil, fmt.Errorf("%w: %s", errNotFound, name)
+}
+
+func (s *Supervisor) list() []SessionStatus {
+	s.mu.Lock()
+	sessions := make([]*ptySession, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+
+	statuses := make([]SessionStatus, 0, len(sessions))
+	for _, sess := range sessions {
+		statuses = append(statuses, sess.status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// start runs a session's command in a new pty. An empty command starts
+// the user's shell.
+func (s *Supervisor) start(req request) error {
+	if req.Name == "" {
+		return errors.New("session name required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("pty supervisor is shutting down")
+	}
+	if _, ok := s.sessions[req.Name]; ok {
+		return fmt.Errorf("duplicate session: %s", req.Name)
+	}
+
+	master, tty, err := openPTY()
+	if err != nil {
+		return err
+	}
+	rows, cols := req.Rows, req.Cols
+	if rows <= 0 || cols <= 0 {
+		rows, cols = defaultRows, defaultCols
+	}
+	_ = setWinsize(master, rows, cols)
+
+	var cmd *exec.Cmd
+	if req.Command == "" {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		cmd = exec.Command(shell) //nolint:gosec // G204: the user's own shell
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", req.Command) //nolint:gosec // G204: commands come from the town's own managers
+	}
+	cmd.Dir = req.Dir
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	for k, v := range req.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	configureSession(cmd)
+
+	if err := cmd.Start(); err != nil {
+		_ = master.Close()
+		_ = tty.Close()
+		return fmt.Errorf("starting %s: %w", req.Name, err)
+	}
+	_ = tty.Close() // The child holds its own copy
+
+	now := time.Now()
+	sess := &ptySession{
+		name:     req.Name,
+		command:  req.Command,
+		workDir:  req.Dir,
+		created:  now,
+		cmd:      cmd,
+		master:   master,
+		out:      NewRing(s.scrollback),
+		pumped:   make(chan struct{}),
+		done:     make(chan struct{}),
+		activity: now,
+		env:      make(map[string]string),
+		clients:  make(map[net.Conn]struct{}),
+	}
+	s.sessions[req.Name] = sess
+	s.logger.Printf("started %s (PID %d): %s", req.Name, cmd.Process.Pid, req.Command)
+
+	go sess.pump()
+	go s.wait(sess)
+	return nil
+}
+
+// pump copies terminal output into the scrollback and to attached clients.
+func (p *ptySession) pump() {
+	defer close(p.pumped)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := p.master.Read(buf)
+		if n > 0 {
+			p.mu.Lock()
+			_, _ = p.out.Write(buf[:n])
+			p.activity = time.Now()
+			for conn := range p.clients {
+				_ = conn.SetWriteDeadline(time.Now().Add(time.Second))
+				if _, err := conn.Write(buf[:n]); err != nil {
+					_ = conn.Close()
+					delete(p.clients, conn)
+				}
+			}
+			p.mu.Unlock()
+		}
+		if err != nil {
+			return // EIO once every process holding the terminal is gone
+		}
+	}
+}
+
+// wait reaps a session's process, removes the session, and logs a crash
+// for agents that registered a pane-died hook.
+func (s *Supervisor) wait(sess *ptySession) {
+	err := sess.cmd.Wait()
+
+	// Let the pump drain what the process wrote before it exited;
+	// background children may keep the terminal open indefinitely.
+	select {
+	case <-sess.pumped:
+	case <-time.After(time.Second):
+	}
+	_ = sess.master.Close()
+
+	s.mu.Lock()
+	if s.sessions[sess.name] == sess {
+		delete(s.sessions, sess.name)
+	}
+	s.mu.Unlock()
+
+	sess.mu.Lock()
+	for conn := range sess.clients {
+		_ = conn.Close()
+	}
+	sess.clients = nil
+	killed, agentID := sess.killed, sess.agentID
+	sess.mu.Unlock()
+	close(sess.done)
+
+	code := sess.cmd.ProcessState.ExitCode()
+	s.logger.Printf("%s exited (code %d): %v", sess.name, code, err)
+	if !killed && agentID != "" {
+		crash := exec.Command("wt", "log", "crash", "--agent", agentID, "--session", sess.name, "--exit-code", strconv.Itoa(code)) //nolint:gosec // G204: arguments are not shell-interpreted
+		if err := crash.Run(); err != nil {
+			s.logger.Printf("logging crash of %s: %v", sess.name, err)
+		}
+	}
+}
+
+// kill ends a session. Its process group gets a hangup, as closing a tmux
+// session would deliver; when forced, or when the group ignores the
+// hangup, it is killed outright.
+func (s *Supervisor) kill(name string, force bool) error {
+	sess, err := s.lookup(name)
+	if err != nil {
+		return err
+	}
+	sess.mu.Lock()
+	sess.killed = true
+	sess.mu.Unlock()
+
+	grace := time.Second
+	if force {
+		grace = 100 * time.Millisecond
+	}
+	_ = signalSession(sess.cmd.Process, false)
+	select {
+	case <-sess.done:
+		return nil
+	case <-time.After(grace):
+	}
+	_ = signalSession(sess.cmd.Process, true)
+	select {
+	case <-sess.done:
+	case <-time.After(2 * time.Second):
+		return fmt.Errorf("session %s did not exit", name)
+	}
+	return nil
+}
+
+// status reports a session's state.
+func (p *ptySession) status() SessionStatus {
+	p.mu.Lock()
+	activity, attached := p.activity, len(p.clients)
+	p.mu.Unlock()
+
+	fg := foregroundPID(p.master)
+	if fg <= 0 {
+		fg = p.cmd.Process.Pid
+	}
+	return SessionStatus{
+		Name:              p.name,
+		Command:           p.command,
+		WorkDir:           p.workDir,
+		PID:               p.cmd.Process.Pid,
+		ForegroundPID:     fg,
+		ForegroundCommand: processName(fg),
+		Created:           p.created,
+		Activity:          activity,
+		Attached:          attached,
+		OutputBytes:       p.out.Total(),
+	}
+}
+
+// attach joins a client to a session's terminal. The client first gets
+// the scrollback, then live output; what it sends is typed into the
+// session. The stream ends when either side goes away.
+func (s *Supervisor) attach(conn net.Conn, r *bufio.Reader, req request) {
+	sess, err := s.lookup(req.Name)
+	if err != nil {
+		_ = writeResponse(conn, errorResponse(err))
+		return
+	}
+	if req.Rows > 0 && req.Cols > 0 {
+		_ = setWinsize(sess.master, req.Rows, req.Cols)
+	}
+
+	sess.mu.Lock()
+	if sess.clients == nil {
+		sess.mu.Unlock()
+		_ = writeResponse(conn, errorResponse(fmt.Errorf("%w: %s", errNotFound, req.Name)))
+		return
+	}
+	err = writeResponse(conn, &response{OK: true})
+	if err == nil {
+		_, err = conn.Write(sess.out.Bytes())
+	}
+	if err == nil {
+		sess.clients[conn] = struct{}{}
+	}
+	sess.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	_, _ = io.Copy(sess.master, r)
+
+	sess.mu.Lock()
+	delete(sess.clients, conn)
+	sess.mu.Unlock()
+}
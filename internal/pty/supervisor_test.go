@@ -0,0 +1,127 @@
+//go:build linux || darwin
+
+package pty
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/tmux"
+)
+
+// startSupervisor runs a supervisor for a temporary town and returns a
+// client for it.
+func startSupervisor(t *testing.T) *Client {
+	t.Helper()
+	townRoot := t.TempDir()
+	s := NewSupervisor(SocketPath(townRoot), 0, nil)
+	if err := s.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = s.Serve() }()
+	t.Cleanup(func() { _ = s.Close() })
+	return NewClient(townRoot)
+}
+
+// waitFor polls cond until it holds or a few seconds pass.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestSupervisorSessionLifecycle(t *testing.T) {
+	c := startSupervisor(t)
+	dir := t.TempDir()
+
+	// A line-echoing loop stands in for an agent
+	if err := c.NewSessionWithCommand("wt-test", dir, `echo "ready in $(pwd)"; while read line; do echo "got: $line"; done`); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.NewSessionWithCommand("wt-test", dir, "true"); err == nil {
+		t.Error("duplicate session was started")
+	}
+
+	if ok, err := c.HasSession("wt-test"); err != nil || !ok {
+		t.Fatalf("HasSession = %v, %v", ok, err)
+	}
+	if ok, _ := c.HasSession("wt-other"); ok {
+		t.Error("HasSession reported a session that doesn't exist")
+	}
+	names, err := c.ListSessions()
+	if err != nil || len(names) != 1 || names[0] != "wt-test" {
+		t.Errorf("ListSessions = %v, %v", names, err)
+	}
+
+	resolved, _ := filepath.EvalSymlinks(dir)
+	waitFor(t, "startup output", func() bool {
+		out, _ := c.CapturePane("wt-test", 10)
+		return strings.Contains(out, "ready in "+resolved) || strings.Contains(out, "ready in "+dir)
+	})
+
+	if err := c.SendKeys("wt-test", "hello pty"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "echoed input", func() bool {
+		out, _ := c.CapturePane("wt-test", 10)
+		return strings.Contains(out, "got: hello pty")
+	})
+
+	if err := c.SetEnvironment("wt-test", "WT_ROLE", "polecat"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.GetEnvironment("wt-test", "WT_ROLE"); err != nil || v != "polecat" {
+		t.Errorf("GetEnvironment = %q, %v", v, err)
+	}
+
+	info, err := c.GetSessionInfo("wt-test")
+	if err != nil || info.Name != "wt-test" || info.Attached {
+		t.Errorf("GetSessionInfo = %+v, %v", info, err)
+	}
+	if cmd, err := c.GetPaneCommand("wt-test"); err != nil || cmd == "" {
+		t.Errorf("GetPaneCommand = %q, %v", cmd, err)
+	}
+
+	if err := c.KillSession("wt-test"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := c.HasSession("wt-test"); ok {
+		t.Error("session still exists after KillSession")
+	}
+	if _, err := c.CapturePane("wt-test", 10); !errors.Is(err, tmux.ErrSessionNotFound) {
+		t.Errorf("CapturePane after kill = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestSupervisorRemovesExitedSessions(t *testing.T) {
+	c := startSupervisor(t)
+	if err := c.NewSessionWithCommand("wt-short", t.TempDir(), "echo bye"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "session to exit", func() bool {
+		ok, _ := c.HasSession("wt-short")
+		return !ok
+	})
+}
+
+func TestClientWithoutSupervisor(t *testing.T) {
+	c := NewClient(t.TempDir())
+	if ok, err := c.HasSession("wt-test"); ok || err != nil {
+		t.Errorf("HasSession = %v, %v; want false, nil", ok, err)
+	}
+	if names, err := c.ListSessions(); len(names) != 0 || err != nil {
+		t.Errorf("ListSessions = %v, %v", names, err)
+	}
+	if err := c.KillSession("wt-test"); !errors.Is(err, tmux.ErrNoServer) {
+		t.Errorf("KillSession = %v, want ErrNoServer", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/speaker20/whaletown/internal/beads"
+	"github.com/speaker20/whaletown/internal/config"
 	"github.com/speaker20/whaletown/internal/git"
 	"github.com/speaker20/whaletown/internal/mail"
 	"github.com/speaker20/whaletown/internal/protocol"
@@ -34,6 +35,23 @@ type MergeQueueConfig struct {
 	// OnConflict is the strategy for handling conflicts: "assign_back" or "auto_rebase".
 	OnConflict string `json:"on_conflict"`
 
+	// AutoRebase tunes the auto_rebase strategy (whitespace handling and
+	// per-file conflict rules).
+	AutoRebase *config.AutoRebaseConfig `json:"auto_rebase"`
+
+	// MergeStrategy is "no-ff", "squash" or "rebase-and-ff".
+	MergeStrategy string `json:"merge_strategy"`
+
+	// CommitTemplate renders merge and squash commit messages (see
+	// git.CommitMessageData). Empty uses git.DefaultMergeMessageTemplate.
+	CommitTemplate string `json:"commit_template"`
+
+	// CoAuthorTrailers credits the polecat with a Co-authored-by trailer.
+	CoAuthorTrailers bool `json:"co_author_trailers"`
+
+	// SignCommits signs the commits the refinery creates.
+	SignCommits bool `json:"sign_commits"`
+
 	// RunTests controls whether to run tests before merging.
 	RunTests bool `json:"run_tests"`
 
@@ -46,10 +64,16 @@ type MergeQueueConfig struct {
 	// RetryFlakyTests is the number of times to retry flaky tests.
 	RetryFlakyTests int `json:"retry_flaky_tests"`
 
+	// TestReport is a glob (relative to the worktree) of JUnit XML reports
+	// written by TestCommand. Without it, `go test -json` output is parsed
+	// when present.
+	TestReport string `json:"test_report"`
+
 	// PollInterval is how often to check for new MRs.
 	PollInterval time.Duration `json:"poll_interval"`
 
-	// MaxConcurrent is the maximum number of MRs to process concurrently.
+	// MaxConcurrent is the maximum number of MRs batched into one merge
+	// train (see ProcessTrain). 1 merges MRs one at a time.
 	MaxConcurrent int `json:"max_concurrent"`
 }
 
@@ -59,7 +83,9 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 		Enabled:              true,
 		TargetBranch:         "main",
 		IntegrationBranches:  true,
-		OnConflict:           "assign_back",
+		OnConflict:           config.OnConflictAssignBack,
+		AutoRebase:           config.DefaultAutoRebaseConfig(),
+		MergeStrategy:        config.MergeStrategyNoFF,
 		RunTests:             true,
 		TestCommand:          "",
 		DeleteMergedBranches: true,
@@ -163,16 +189,22 @@ func (e *Engineer) LoadConfig() error {
 	// Parse merge_queue section into our config struct
 	// We need special handling for poll_interval (string -> Duration)
 	var mqRaw struct {
-		Enabled              *bool   `json:"enabled"`
-		TargetBranch         *string `json:"target_branch"`
-		IntegrationBranches  *bool   `json:"integration_branches"`
-		OnConflict           *string `json:"on_conflict"`
-		RunTests             *bool   `json:"run_tests"`
-		TestCommand          *string `json:"test_command"`
-		DeleteMergedBranches *bool   `json:"delete_merged_branches"`
-		RetryFlakyTests      *int    `json:"retry_flaky_tests"`
-		PollInterval         *string `json:"poll_interval"`
-		MaxConcurrent        *int    `json:"max_concurrent"`
+		Enabled              *bool                    `json:"enabled"`
+		TargetBranch         *string                  `json:"target_branch"`
+		IntegrationBranches  *bool                    `json:"integration_branches"`
+		OnConflict           *string                  `json:"on_conflict"`
+		AutoRebase           *config.AutoRebaseConfig `json:"auto_rebase"`
+		MergeStrategy        *string                  `json:"merge_strategy"`
+		CommitTemplate       *string                  `json:"commit_template"`
+		CoAuthorTrailers     *bool                    `json:"co_author_trailers"`
+		SignCommits          *bool                    `json:"sign_commits"`
+		RunTests             *bool                    `json:"run_tests"`
+		TestCommand          *string                  `json:"test_command"`
+		DeleteMergedBranches *bool                    `json:"delete_merged_branches"`
+		RetryFlakyTests      *int                     `json:"retry_flaky_tests"`
+		TestReport           *string                  `json:"test_report"`
+		PollInterval         *string                  `json:"poll_interval"`
+		MaxConcurrent        *int                     `json:"max_concurrent"`
 	}
 
 	if err := json.Unmarshal(rawConfig.MergeQueue, &mqRaw); err != nil {
@@ -192,6 +224,33 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.OnConflict != nil {
 		e.config.OnConflict = *mqRaw.OnConflict
 	}
+	if mqRaw.AutoRebase != nil {
+		if err := mqRaw.AutoRebase.Validate(); err != nil {
+			return err
+		}
+		e.config.AutoRebase = mqRaw.AutoRebase
+	}
+	if mqRaw.MergeStrategy != nil {
+		switch *mqRaw.MergeStrategy {
+		case config.MergeStrategyNoFF, config.MergeStrategySquash, config.MergeStrategyRebaseAndFF:
+			e.config.MergeStrategy = *mqRaw.MergeStrategy
+		default:
+			return fmt.Errorf("invalid merge_strategy %q (valid: %s, %s, %s)", *mqRaw.MergeStrategy,
+				config.MergeStrategyNoFF, config.MergeStrategySquash, config.MergeStrategyRebaseAndFF)
+		}
+	}
+	if mqRaw.CommitTemplate != nil {
+		if err := git.ValidateCommitTemplate(*mqRaw.CommitTemplate); err != nil {
+			return fmt.Errorf("invalid commit_template: %w", err)
+		}
+		e.config.CommitTemplate = *mqRaw.CommitTemplate
+	}
+	if mqRaw.CoAuthorTrailers != nil {
+		e.config.CoAuthorTrailers = *mqRaw.CoAuthorTrailers
+	}
+	if mqRaw.SignCommits != nil {
+		e.config.SignCommits = *mqRaw.SignCommits
+	}
 	if mqRaw.RunTests != nil {
 		e.config.RunTests = *mqRaw.RunTests
 	}
@@ -204,6 +263,9 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.RetryFlakyTests != nil {
 		e.config.RetryFlakyTests = *mqRaw.RetryFlakyTests
 	}
+	if mqRaw.TestReport != nil {
+		e.config.TestReport = *mqRaw.TestReport
+	}
 	if mqRaw.MaxConcurrent != nil {
 		e.config.MaxConcurrent = *mqRaw.MaxConcurrent
 	}
@@ -218,6 +280,16 @@ func (e *Engineer) LoadConfig() error {
 	return nil
 }
 
+// LoadMergeQueueConfig returns a rig's merge queue configuration: the
+// defaults overlaid with the merge_queue section of its config.json.
+func LoadMergeQueueConfig(r *rig.Rig) (*MergeQueueConfig, error) {
+	e := NewEngineer(r)
+	if err := e.LoadConfig(); err != nil {
+		return nil, err
+	}
+	return e.config, nil
+}
+
 // Config returns the current merge queue configuration.
 func (e *Engineer) Config() *MergeQueueConfig {
 	return e.config
@@ -230,6 +302,7 @@ type ProcessResult struct {
 	Error       string
 	Conflict    bool
 	TestsFailed bool
+	Tests       *TestReport // Test run details, when tests ran
 }
 
 // ProcessMR processes a single merge request from a beads issue.
@@ -249,12 +322,21 @@ func (e *Engineer) ProcessMR(ctx context.Context, mr *beads.Issue) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Target: %s\n", mrFields.Target)
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mrFields.Worker)
 
-	return e.doMerge(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue)
+	return e.doMerge(ctx, &MRInfo{
+		ID:          mr.ID,
+		Branch:      mrFields.Branch,
+		Target:      mrFields.Target,
+		SourceIssue: mrFields.SourceIssue,
+		Worker:      mrFields.Worker,
+		Rig:         mrFields.Rig,
+		ConvoyID:    mrFields.ConvoyID,
+	})
 }
 
 // doMerge performs the actual git merge operation.
 // This is the core merge logic shared by ProcessMR and ProcessMRFromQueue.
-func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string) ProcessResult {
+func (e *Engineer) doMerge(ctx context.Context, mr *MRInfo) ProcessResult {
+	branch, target := mr.Branch, mr.Target
 	// Step 1: Verify source branch exists locally (shared .repo.git with polecats)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking local branch %s...\n", branch)
 	exists, err := e.git.BranchExists(branch)
@@ -313,23 +395,15 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 				Success:     false,
 				TestsFailed: true,
 				Error:       result.Error,
+				Tests:       result.Tests,
 			}
 		}
 		_, _ = fmt.Fprintln(e.output, "[Engineer] Tests passed")
 	}
 
-	// Step 5: Perform the actual merge
-	mergeMsg := fmt.Sprintf("Merge %s into %s", branch, target)
-	if sourceIssue != "" {
-		mergeMsg = fmt.Sprintf("Merge %s into %s (%s)", branch, target, sourceIssue)
-	}
-	_, _ = fmt.Fprintf(e.output, "[Engineer] Merging with message: %s\n", mergeMsg)
-	if err := e.git.MergeNoFF(branch, mergeMsg); err != nil {
-		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
-		// GetConflictingFiles() uses `git diff --diff-filter=U` which is proper.
-		conflicts, conflictErr := e.git.GetConflictingFiles()
-		if conflictErr == nil && len(conflicts) > 0 {
-			_ = e.git.AbortMerge()
+	// Step 5: Perform the actual merge using the configured strategy
+	if conflicts, err := e.mergeBranch(mr, target); err != nil {
+		if len(conflicts) > 0 {
 			return ProcessResult{
 				Success:  false,
 				Conflict: true,
@@ -367,8 +441,16 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	}
 }
 
-// runTests runs the configured test command and returns the result.
+// runTests runs the configured test command in the refinery worktree.
 func (e *Engineer) runTests(ctx context.Context) ProcessResult {
+	return e.runTestsIn(ctx, e.workDir)
+}
+
+// runTestsIn runs the configured test command in dir and returns the result.
+// Output is captured per attempt and parsed as `go test -json` or, if
+// test_report is set, JUnit XML, so the result carries which tests failed
+// and which only failed before a retry (flaky, recorded in the rig history).
+func (e *Engineer) runTestsIn(ctx context.Context, dir string) ProcessResult {
 	if e.config.TestCommand == "" {
 		return ProcessResult{Success: true}
 	}
@@ -379,23 +461,33 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 		maxRetries = 1
 	}
 
+	report := &TestReport{Command: e.config.TestCommand}
 	var lastErr error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		if attempt > 1 {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying tests (attempt %d/%d)...\n", attempt, maxRetries)
 		}
+		if e.config.TestReport != "" {
+			removeJUnitReports(dir, e.config.TestReport)
+		}
 
 		// Note: TestCommand comes from rig's config.json (trusted infrastructure config),
 		// not from PR branches. Shell execution is intentional for flexibility (pipes, etc).
 		cmd := exec.CommandContext(ctx, "sh", "-c", e.config.TestCommand) //nolint:gosec // G204: TestCommand is from trusted rig config
-		cmd.Dir = e.workDir
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
+		cmd.Dir = dir
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
 
 		err := cmd.Run()
+		result := &TestAttempt{Attempt: attempt, Passed: err == nil, Output: output.Bytes()}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		e.parseTestOutput(dir, result)
+		report.Attempts = append(report.Attempts, result)
 		if err == nil {
-			return ProcessResult{Success: true}
+			break
 		}
 		lastErr = err
 
@@ -404,14 +496,59 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 			return ProcessResult{
 				Success: false,
 				Error:   "test run canceled",
+				Tests:   report,
 			}
 		}
+		if failed := result.FailedTests(); len(failed) > 0 {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Attempt %d failed: %s\n", attempt, strings.Join(failed, ", "))
+		}
 	}
 
+	report.finish()
+	if len(report.Flaky) > 0 {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Flaky tests (failed, then passed on retry): %s\n", strings.Join(report.Flaky, ", "))
+		e.recordFlakyTests(report.Flaky)
+	}
+
+	if lastErr == nil || report.Attempts[len(report.Attempts)-1].Passed {
+		return ProcessResult{Success: true, Tests: report}
+	}
+	msg := fmt.Sprintf("tests failed after %d attempts: %v", maxRetries, lastErr)
+	if len(report.Failed) > 0 {
+		msg += fmt.Sprintf(" (failed: %s)", strings.Join(report.Failed, ", "))
+	}
 	return ProcessResult{
 		Success:     false,
 		TestsFailed: true,
-		Error:       fmt.Sprintf("tests failed after %d attempts: %v", maxRetries, lastErr),
+		Error:       msg,
+		Tests:       report,
+	}
+}
+
+// parseTestOutput fills in an attempt's test cases from JUnit reports
+// (if test_report is configured) or `go test -json` output.
+func (e *Engineer) parseTestOutput(dir string, a *TestAttempt) {
+	if e.config.TestReport != "" {
+		if cases, ok := readJUnitReports(dir, e.config.TestReport); ok {
+			a.Cases, a.Format = cases, TestFormatJUnit
+			return
+		}
+	}
+	if cases, ok := parseGoTestJSON(a.Output); ok {
+		a.Cases, a.Format = cases, TestFormatGoJSON
+	}
+}
+
+// recordFlakyTests adds flakes to the rig's flaky-test history.
+func (e *Engineer) recordFlakyTests(names []string) {
+	history, err := LoadFlakyHistory(e.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: loading flaky history: %v\n", err)
+		return
+	}
+	history.Record(names, time.Now())
+	if err := history.Save(e.rig.Path); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: saving flaky history: %v\n", err)
 	}
 }
 
@@ -503,7 +640,7 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Source: %s\n", mr.SourceIssue)
 
 	// Use the shared merge logic
-	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue)
+	return e.doMerge(ctx, mr)
 }
 
 // HandleMRInfoSuccess handles a successful merge from MRInfo.
@@ -584,6 +721,18 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 // For conflicts, creates a resolution task and blocks the MR until resolved.
 // This enables non-blocking delegation: the queue continues to the next MR.
 func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
+	// With on_conflict: auto_rebase, try rebasing the branch ourselves first.
+	// Only if that fails does the conflict go back to a polecat.
+	var attempt *RebaseAttempt
+	if result.Conflict && e.config.OnConflict == config.OnConflictAutoRebase {
+		attempt = e.AutoRebase(context.Background(), mr)
+		if attempt.Success {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Conflict on %s resolved by auto-rebase - MR remains in queue for retry\n", mr.ID)
+			return
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Auto-rebase of %s failed: %s - assigning back\n", mr.Branch, attempt.Error)
+	}
+
 	// Notify Witness of the failure so polecat can be alerted
 	// Determine failure type from result
 	failureType := "build"
@@ -592,7 +741,23 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	} else if result.TestsFailed {
 		failureType = "tests"
 	}
-	msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error)
+	payload := protocol.MergeFailedPayload{
+		Branch:       mr.Branch,
+		Issue:        mr.SourceIssue,
+		Polecat:      mr.Worker,
+		Rig:          e.rig.Name,
+		FailureType:  failureType,
+		Error:        result.Error,
+		TargetBranch: mr.Target,
+	}
+	if result.Tests != nil && result.TestsFailed {
+		e.attachTestResults(mr, result.Tests)
+		payload.FailedTests = result.Tests.Failed
+		payload.FlakyTests = result.Tests.Flaky
+		payload.Artifact = result.Tests.Artifact
+		payload.LogExcerpt = result.Tests.Excerpt
+	}
+	msg := protocol.NewMergeFailedMessageFromPayload(payload)
 	if err := e.router.Send(msg); err != nil {
 		fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
 	} else {
@@ -602,7 +767,7 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	// If this was a conflict, create a conflict-resolution task for dispatch
 	// and block the MR until the task is resolved (non-blocking delegation)
 	if result.Conflict {
-		taskID, err := e.createConflictResolutionTaskForMR(mr, result)
+		taskID, err := e.createConflictResolutionTaskForMR(mr, result, attempt)
 		if err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create conflict resolution task: %v\n", err)
 		} else if taskID != "" {
@@ -625,9 +790,36 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	}
 }
 
+// attachTestResults saves the full test output as an artifact and records
+// its path and the failed tests on the MR bead.
+func (e *Engineer) attachTestResults(mr *MRInfo, report *TestReport) {
+	if err := writeTestArtifact(e.rig.Path, mr.ID, report, time.Now()); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save test output: %v\n", err)
+		return
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Test output saved to %s\n", report.Artifact)
+
+	mrBead, err := e.beads.Show(mr.ID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch MR bead %s: %v\n", mr.ID, err)
+		return
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	mrFields.TestArtifact = report.Artifact
+	mrFields.FailedTests = strings.Join(report.Failed, ", ")
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record test results on MR %s: %v\n", mr.ID, err)
+	}
+}
+
 // createConflictResolutionTaskForMR creates a dispatchable task for resolving merge conflicts.
 // This task will be picked up by bd ready and can be slung to a fresh polecat (spawned on demand).
 // Returns the created task's ID for blocking the MR until resolution.
+// A failed auto-rebase attempt, if any, is attached to the description.
 //
 // Task format:
 //
@@ -642,7 +834,7 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 // This serializes conflict resolution - only one polecat can resolve conflicts at a time.
 // If the slot is already held, we skip creating the task and let the MR stay in queue.
 // When the current resolution completes and merges, the slot is released.
-func (e *Engineer) createConflictResolutionTaskForMR(mr *MRInfo, _ ProcessResult) (string, error) { // result unused but kept for future merge diagnostics
+func (e *Engineer) createConflictResolutionTaskForMR(mr *MRInfo, _ ProcessResult, attempt *RebaseAttempt) (string, error) { // result unused but kept for future merge diagnostics
 	// === MERGE SLOT GATE: Serialize conflict resolution ===
 	// Ensure merge slot exists (idempotent)
 	slotID, err := e.beads.MergeSlotEnsureExists()
@@ -719,6 +911,9 @@ The Refinery will automatically retry the merge after you force-push.`,
 		mr.Branch,
 		mr.Target,
 	)
+	if attempt != nil {
+		description += "\n\n" + attempt.Summary()
+	}
 
 	// Create the conflict resolution task
 	taskTitle := fmt.Sprintf("Resolve merge conflicts: %s", originalTitle)
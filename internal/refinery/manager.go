@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/beads"
 	"github.com/speaker20/whaletown/internal/config"
 	"github.com/speaker20/whaletown/internal/constants"
@@ -110,7 +111,7 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 		return err
 	}
 
-	t := tmux.NewTmux()
+	t := backend.New(filepath.Dir(m.rig.Path))
 	sessionID := m.SessionName()
 
 	if foreground {
@@ -263,7 +264,7 @@ func (m *Manager) Stop() error {
 	}
 
 	// Check if tmux session exists
-	t := tmux.NewTmux()
+	t := backend.New(filepath.Dir(m.rig.Path))
 	sessionID := m.SessionName()
 	sessionRunning, _ := t.HasSession(sessionID)
 
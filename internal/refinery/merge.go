@@ -0,0 +1,97 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains merge strategies and merge commit messages.
+
+package refinery
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/git"
+)
+
+// mergeBranch lands mr's branch on the checked-out branch (target or a
+// train branch) using the configured merge strategy. If the merge fails,
+// the worktree is cleaned up and any conflicting files are returned with
+// the error.
+func (e *Engineer) mergeBranch(mr *MRInfo, target string) ([]string, error) {
+	if e.config.MergeStrategy == config.MergeStrategyRebaseAndFF {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Rebasing %s onto %s and fast-forwarding...\n", mr.Branch, target)
+		return e.git.RebaseFF(mr.Branch, e.config.SignCommits)
+	}
+
+	opts := git.CommitOptions{Message: e.commitMessage(mr, target), Sign: e.config.SignCommits}
+	subject, _, _ := strings.Cut(opts.Message, "\n")
+	squash := e.config.MergeStrategy == config.MergeStrategySquash
+	var err error
+	if squash {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Squashing with message: %s\n", subject)
+		err = e.git.MergeSquash(mr.Branch, opts)
+	} else {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merging with message: %s\n", subject)
+		err = e.git.MergeNoFFWith(mr.Branch, opts)
+	}
+	if err != nil {
+		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
+		conflicts, _ := e.git.GetConflictingFiles()
+		if squash {
+			_ = e.git.ResetHard("HEAD") // A squash leaves no merge to abort
+		} else {
+			_ = e.git.AbortMerge()
+		}
+		return conflicts, err
+	}
+	return nil, nil
+}
+
+// commitMessage renders the merge commit message for mr from the rig's
+// commit_template, adding a Co-authored-by trailer for the polecat when
+// co_author_trailers is set. A broken template falls back to the default.
+func (e *Engineer) commitMessage(mr *MRInfo, target string) string {
+	data := git.CommitMessageData{
+		Branch:  mr.Branch,
+		Target:  target,
+		MR:      mr.ID,
+		Issue:   mr.SourceIssue,
+		Polecat: e.agentIdentity(mr.Worker),
+		Rig:     e.rig.Name,
+		Convoy:  mr.ConvoyID,
+	}
+	// Only a custom template can use bead titles; skip the lookups otherwise
+	if e.config.CommitTemplate != "" {
+		if mr.SourceIssue != "" {
+			if issue, err := e.beads.Show(mr.SourceIssue); err == nil && issue != nil {
+				data.Title = issue.Title
+				data.Description = issue.Description
+			}
+		}
+		if mr.ConvoyID != "" {
+			if convoy, err := e.beads.Show(mr.ConvoyID); err == nil && convoy != nil {
+				data.ConvoyTitle = convoy.Title
+			}
+		}
+	}
+
+	msg, err := git.RenderCommitMessage(e.config.CommitTemplate, data)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v (using default message)\n", err)
+		msg, _ = git.RenderCommitMessage("", data)
+	}
+	if e.config.CoAuthorTrailers && data.Polecat != "" {
+		domain := config.LoadAgentEmailDomain(filepath.Dir(e.rig.Path))
+		msg = git.AppendTrailers(msg, git.CoAuthorTrailer(data.Polecat, domain))
+	}
+	return msg
+}
+
+// agentIdentity expands an MR's worker to a full agent identity
+// ("nux" → "<rig>/polecats/nux"). Workers already in identity form are
+// returned as is.
+func (e *Engineer) agentIdentity(worker string) string {
+	if worker == "" || strings.Contains(worker, "/") {
+		return worker
+	}
+	return fmt.Sprintf("%s/polecats/%s", e.rig.Name, worker)
+}
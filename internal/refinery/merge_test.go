@@ -0,0 +1,118 @@
+package refinery
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/config"
+)
+
+// twoCommitBranch creates a polecat branch with two commits.
+func (r *trainRepo) twoCommitBranch(name string) *MRInfo {
+	r.t.Helper()
+	mr := r.branch(name, "one.txt", "1\n")
+	r.git(r.work, "checkout", name)
+	r.commit("two.txt", "2\n")
+	r.git(r.work, "checkout", "main")
+	mr.Worker = "nux"
+	mr.SourceIssue = "wt-abc"
+	return mr
+}
+
+func TestDoMerge_NoFFDefaultMessage(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mr := repo.twoCommitBranch("polecat/nux")
+
+	result := e.doMerge(context.Background(), mr)
+	if !result.Success {
+		t.Fatalf("merge failed: %s", result.Error)
+	}
+	if parents := repo.git(repo.origin, "log", "-1", "--format=%P", "main"); len(strings.Fields(parents)) != 2 {
+		t.Errorf("expected a merge commit, parents = %q", parents)
+	}
+	if msg := repo.git(repo.origin, "log", "-1", "--format=%B", "main"); msg != "Merge polecat/nux into main (wt-abc)" {
+		t.Errorf("message = %q", msg)
+	}
+}
+
+func TestDoMerge_SquashWithTemplateAndTrailer(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mr := repo.twoCommitBranch("polecat/nux")
+	e.config.MergeStrategy = config.MergeStrategySquash
+	e.config.CommitTemplate = "{{.Issue}}: work from {{.Polecat}} on {{.Rig}}"
+	e.config.CoAuthorTrailers = true
+
+	result := e.doMerge(context.Background(), mr)
+	if !result.Success {
+		t.Fatalf("merge failed: %s", result.Error)
+	}
+	if parents := repo.git(repo.origin, "log", "-1", "--format=%P", "main"); len(strings.Fields(parents)) != 1 {
+		t.Errorf("squash should create a single-parent commit, parents = %q", parents)
+	}
+	want := "wt-abc: work from testrig/polecats/nux on testrig\n\n" +
+		"Co-authored-by: testrig/polecats/nux <testrig.polecats.nux@" + config.DefaultAgentEmailDomain + ">"
+	if msg := repo.git(repo.origin, "log", "-1", "--format=%B", "main"); msg != want {
+		t.Errorf("message = %q, want %q", msg, want)
+	}
+	if files := repo.originFiles(); !strings.Contains(files, "one.txt") || !strings.Contains(files, "two.txt") {
+		t.Errorf("squash lost files: %s", files)
+	}
+}
+
+func TestDoMerge_RebaseAndFF(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mr := repo.twoCommitBranch("polecat/nux")
+	repo.commit("main.txt", "moved on\n") // main diverges from the branch
+	repo.git(repo.work, "push", "origin", "main")
+	branchHead := repo.git(repo.work, "rev-parse", mr.Branch)
+	e.config.MergeStrategy = config.MergeStrategyRebaseAndFF
+
+	result := e.doMerge(context.Background(), mr)
+	if !result.Success {
+		t.Fatalf("merge failed: %s", result.Error)
+	}
+	if merges := repo.git(repo.origin, "rev-list", "--merges", "main"); merges != "" {
+		t.Errorf("rebase-and-ff should leave linear history, found merges: %s", merges)
+	}
+	if subjects := repo.git(repo.origin, "log", "-3", "--format=%s", "main"); subjects != "add two.txt\nadd one.txt\nadd main.txt" {
+		t.Errorf("history = %q", subjects)
+	}
+	if after := repo.git(repo.work, "rev-parse", mr.Branch); after != branchHead {
+		t.Error("rebase-and-ff must not move the polecat branch")
+	}
+	if cur := repo.git(repo.work, "rev-parse", "--abbrev-ref", "HEAD"); cur != "main" {
+		t.Errorf("left on %s, want main", cur)
+	}
+}
+
+func TestDoMerge_SquashConflictCleansUp(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mr := repo.branch("polecat/a", "shared.txt", "from a\n")
+	repo.commit("shared.txt", "from main\n")
+	e.config.MergeStrategy = config.MergeStrategySquash
+	e.config.RunTests = false
+
+	// Skip the pre-merge conflict check to exercise the squash itself
+	repo.git(repo.work, "checkout", "main")
+	conflicts, err := e.mergeBranch(mr, "main")
+	if err == nil || len(conflicts) != 1 {
+		t.Fatalf("expected a conflict, got %v, %v", conflicts, err)
+	}
+	if status := repo.git(repo.work, "status", "--porcelain"); status != "" {
+		t.Errorf("worktree not clean after failed squash:\n%s", status)
+	}
+}
+
+func TestAgentIdentity(t *testing.T) {
+	_, e := newTrainRepo(t)
+	if got := e.agentIdentity("nux"); got != "testrig/polecats/nux" {
+		t.Errorf("agentIdentity(nux) = %q", got)
+	}
+	if got := e.agentIdentity("other/crew/jack"); got != "other/crew/jack" {
+		t.Errorf("agentIdentity(other/crew/jack) = %q", got)
+	}
+	if got := e.agentIdentity(""); got != "" {
+		t.Errorf("agentIdentity(\"\") = %q", got)
+	}
+}
@@ -0,0 +1,324 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains the auto_rebase conflict strategy.
+
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/git"
+)
+
+// maxRebaseRounds bounds conflict-resolution rounds (one per replayed
+// commit that conflicts) so a pathological branch can't loop forever.
+const maxRebaseRounds = 50
+
+// ResolvedConflict is a conflicted file that a rule resolved.
+type ResolvedConflict struct {
+	Path     string `json:"path"`
+	Strategy string `json:"strategy"`
+	Pattern  string `json:"pattern"`
+}
+
+// RebaseAttempt records an auto_rebase attempt, for the conflict task
+// when it fails.
+type RebaseAttempt struct {
+	Branch      string             `json:"branch"`
+	Onto        string             `json:"onto"`                   // Target SHA rebased onto
+	Commit      string             `json:"commit,omitempty"`       // Rebased head
+	Rounds      int                `json:"rounds"`                 // Conflict rounds resolved
+	Resolved    []ResolvedConflict `json:"resolved,omitempty"`     // Files resolved by rules
+	Unresolved  []string           `json:"unresolved,omitempty"`   // Files no rule matched
+	ConflictLog string             `json:"conflict_log,omitempty"` // Conflict markers of unresolved files
+	TestsFailed bool               `json:"tests_failed,omitempty"`
+	Success     bool               `json:"success"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// Summary formats the attempt as a markdown section for a conflict task.
+func (a *RebaseAttempt) Summary() string {
+	var b strings.Builder
+	b.WriteString("## Auto-rebase attempt\n")
+	onto := a.Onto
+	if len(onto) > 8 {
+		onto = onto[:8]
+	}
+	fmt.Fprintf(&b, "- Rebased %s onto %s\n", a.Branch, onto)
+	for _, r := range a.Resolved {
+		fmt.Fprintf(&b, "- Resolved %s (%s, rule %q)\n", r.Path, r.Strategy, r.Pattern)
+	}
+	if len(a.Unresolved) > 0 {
+		fmt.Fprintf(&b, "- Unresolved: %s\n", strings.Join(a.Unresolved, ", "))
+	}
+	if a.TestsFailed {
+		b.WriteString("- Rebase succeeded but tests failed on the result\n")
+	}
+	if a.Error != "" {
+		fmt.Fprintf(&b, "- Error: %s\n", a.Error)
+	}
+	if a.ConflictLog != "" {
+		b.WriteString("\n```diff\n")
+		b.WriteString(a.ConflictLog)
+		if !strings.HasSuffix(a.ConflictLog, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n")
+	}
+	return b.String()
+}
+
+// conflictLogLimit caps the conflict markers attached to a task.
+const conflictLogLimit = 4000
+
+// AutoRebase rebases the MR's branch onto its target in a scratch
+// worktree, resolving conflicts by the configured rules. If the rebase
+// completes and the tests pass on the result, the rebased branch is
+// force-pushed (with lease) and the local branch updated, so the MR can
+// merge on its next attempt. The refinery's own worktree is not touched.
+func (e *Engineer) AutoRebase(ctx context.Context, mr *MRInfo) *RebaseAttempt {
+	cfg := e.config.AutoRebase
+	if cfg == nil {
+		cfg = &config.AutoRebaseConfig{}
+	}
+	target := mr.Target
+	if target == "" {
+		target = e.config.TargetBranch
+	}
+	attempt := &RebaseAttempt{Branch: mr.Branch}
+
+	if err := e.git.FetchBranch("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fetch origin/%s: %v (continuing)\n", target, err)
+	}
+	onto, err := e.git.Rev("origin/" + target)
+	if err != nil {
+		if onto, err = e.git.Rev(target); err != nil {
+			attempt.Error = fmt.Sprintf("resolving %s: %v", target, err)
+			return attempt
+		}
+	}
+	attempt.Onto = onto
+	original, err := e.git.Rev(mr.Branch)
+	if err != nil {
+		attempt.Error = fmt.Sprintf("branch %s not found locally", mr.Branch)
+		return attempt
+	}
+
+	scratch, err := os.MkdirTemp("", "refinery-rebase-*")
+	if err != nil {
+		attempt.Error = fmt.Sprintf("creating scratch dir: %v", err)
+		return attempt
+	}
+	defer os.RemoveAll(scratch)
+	dir := filepath.Join(scratch, "worktree")
+	if err := e.git.WorktreeAddDetached(dir, original); err != nil {
+		attempt.Error = fmt.Sprintf("creating scratch worktree: %v", err)
+		return attempt
+	}
+	defer func() {
+		_ = e.git.WorktreeRemove(dir, true)
+	}()
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Auto-rebasing %s onto %s...\n", mr.Branch, target)
+	wt := git.NewGit(dir)
+	opts := git.RebaseOptions{}
+	if cfg.IgnoreWhitespace {
+		opts.StrategyOptions = []string{"ignore-space-change"}
+	}
+	if attrs := unionAttributes(cfg.Rules); attrs != "" {
+		opts.AttributesFile = filepath.Join(scratch, "attributes")
+		if err := os.WriteFile(opts.AttributesFile, []byte(attrs), 0644); err != nil { //nolint:gosec // G306: scratch file
+			attempt.Error = fmt.Sprintf("writing merge attributes: %v", err)
+			return attempt
+		}
+	}
+
+	if err := e.rebaseWithRules(ctx, wt, onto, opts, cfg.Rules, attempt); err != nil {
+		_ = wt.AbortRebase()
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	head, err := wt.Rev("HEAD")
+	if err != nil {
+		attempt.Error = fmt.Sprintf("resolving rebased head: %v", err)
+		return attempt
+	}
+	attempt.Commit = head
+
+	if e.config.RunTests && e.config.TestCommand != "" {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests on rebased %s...\n", mr.Branch)
+		if result := e.runTestsIn(ctx, dir); !result.Success {
+			attempt.TestsFailed = result.TestsFailed
+			attempt.Error = result.Error
+			return attempt
+		}
+	}
+
+	if err := wt.PushForceWithLease("origin", "HEAD", mr.Branch, original); err != nil {
+		attempt.Error = fmt.Sprintf("pushing rebased branch: %v", err)
+		return attempt
+	}
+	if err := e.git.ResetBranch(mr.Branch, head); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update local %s: %v\n", mr.Branch, err)
+	}
+
+	attempt.Success = true
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Auto-rebased %s (%d conflict round(s) resolved)\n", mr.Branch, attempt.Rounds)
+	return attempt
+}
+
+// rebaseWithRules runs the rebase, resolving each round of conflicts by
+// rule. Returns an error, with the rebase still in progress, when a
+// conflict can't be resolved.
+func (e *Engineer) rebaseWithRules(ctx context.Context, wt *git.Git, onto string, opts git.RebaseOptions, rules []config.ConflictRule, attempt *RebaseAttempt) error {
+	err := wt.RebaseWith(onto, opts)
+	skipped := false
+	for round := 0; err != nil; round++ {
+		if round >= maxRebaseRounds {
+			return fmt.Errorf("gave up after %d conflict rounds", maxRebaseRounds)
+		}
+		files, cerr := wt.GetConflictingFiles()
+		if cerr != nil {
+			return fmt.Errorf("listing conflicts: %v", cerr)
+		}
+		if len(files) == 0 {
+			// A resolution can leave a commit with nothing to replay; skip it
+			// once, but a failure with no conflicts after that is fatal.
+			if skipped {
+				return fmt.Errorf("rebase failed: %v", err)
+			}
+			skipped = true
+			err = wt.RebaseSkip(opts)
+			continue
+		}
+		skipped = false
+
+		attempt.Rounds++
+		var unresolved []string
+		for _, f := range files {
+			rule := matchConflictRule(rules, f)
+			if rule == nil || rule.Strategy == config.ConflictUnion {
+				// Union is applied by the merge driver; still conflicted means it can't
+				unresolved = append(unresolved, f)
+				continue
+			}
+			if rerr := e.applyConflictRule(ctx, wt, f, rule); rerr != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Rule %q failed on %s: %v\n", rule.Pattern, f, rerr)
+				unresolved = append(unresolved, f)
+				continue
+			}
+			attempt.Resolved = append(attempt.Resolved, ResolvedConflict{Path: f, Strategy: rule.Strategy, Pattern: rule.Pattern})
+		}
+		if len(unresolved) > 0 {
+			attempt.Unresolved = unresolved
+			attempt.ConflictLog = conflictMarkers(wt.WorkDir(), unresolved)
+			return fmt.Errorf("unresolvable conflicts in: %s", strings.Join(unresolved, ", "))
+		}
+		err = wt.RebaseContinue(opts)
+	}
+	return nil
+}
+
+// applyConflictRule resolves one conflicted file and stages it.
+func (e *Engineer) applyConflictRule(ctx context.Context, wt *git.Git, file string, rule *config.ConflictRule) error {
+	switch rule.Strategy {
+	case config.ConflictTarget:
+		if err := wt.CheckoutConflictSide(file, true); err != nil {
+			return err
+		}
+	case config.ConflictBranch:
+		if err := wt.CheckoutConflictSide(file, false); err != nil {
+			return err
+		}
+	case config.ConflictRegenerate:
+		if err := wt.CheckoutConflictSide(file, true); err != nil {
+			return err
+		}
+		// Command comes from the rig's config.json (trusted), like TestCommand
+		cmd := exec.CommandContext(ctx, "sh", "-c", rule.Command) //nolint:gosec // G204: command is from trusted rig config
+		cmd.Dir = wt.WorkDir()
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %v: %s", rule.Command, err, strings.TrimSpace(string(out)))
+		}
+	default:
+		return fmt.Errorf("unknown strategy %q", rule.Strategy)
+	}
+	return wt.Add(file)
+}
+
+// matchConflictRule returns the first rule matching file, or nil.
+func matchConflictRule(rules []config.ConflictRule, file string) *config.ConflictRule {
+	for i := range rules {
+		r := &rules[i]
+		if dir, ok := strings.CutSuffix(r.Pattern, "/**"); ok {
+			if strings.HasPrefix(file, dir+"/") {
+				return r
+			}
+			continue
+		}
+		if ok, _ := path.Match(r.Pattern, file); ok {
+			return r
+		}
+		if ok, _ := path.Match(r.Pattern, path.Base(file)); ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// unionAttributes renders gitattributes lines enabling git's built-in
+// union merge driver for union rules.
+func unionAttributes(rules []config.ConflictRule) string {
+	var b strings.Builder
+	for _, r := range rules {
+		if r.Strategy != config.ConflictUnion {
+			continue
+		}
+		pattern := r.Pattern
+		if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern // Match at any depth, like the rule itself
+		}
+		fmt.Fprintf(&b, "%s merge=union\n", pattern)
+	}
+	return b.String()
+}
+
+// conflictMarkers collects the conflicted hunks of files, for attaching
+// to the assign-back task.
+func conflictMarkers(dir string, files []string) string {
+	var b strings.Builder
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(dir, f)) //nolint:gosec // G304: path from git's conflict list
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "--- %s\n", f)
+		in := false
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "<<<<<<<") {
+				in = true
+			}
+			if in {
+				b.WriteString(line + "\n")
+			}
+			if strings.HasPrefix(line, ">>>>>>>") {
+				in = false
+			}
+		}
+		if b.Len() > conflictLogLimit {
+			break
+		}
+	}
+	out := b.String()
+	if len(out) > conflictLogLimit {
+		out = out[:conflictLogLimit] + "\n... (truncated)\n"
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+package refinery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/config"
+)
+
+// conflictBranch creates a polecat branch, pushed to origin, that edits
+// files one way, then moves main (locally and on origin) editing them
+// another way.
+func (r *trainRepo) conflictBranch(name string, branch, main map[string]string) *MRInfo {
+	r.t.Helper()
+	r.git(r.work, "checkout", "-b", name, "main")
+	for f, c := range branch {
+		r.commit(f, c)
+	}
+	r.git(r.work, "push", "origin", name)
+	r.git(r.work, "checkout", "main")
+	for f, c := range main {
+		r.commit(f, c)
+	}
+	r.git(r.work, "push", "origin", "main")
+	return &MRInfo{ID: "mr-" + strings.TrimPrefix(name, "polecat/"), Branch: name, Target: "main"}
+}
+
+func (r *trainRepo) show(ref, file string) string {
+	return r.git(r.work, "show", ref+":"+file)
+}
+
+func TestAutoRebase_ResolvesByRules(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	repo.commit("go.sum", "a v1 h1:aaa\n")
+	repo.commit("gen/api.go", "// generated v0\n")
+	repo.commit("main.go", "package main\n\nfunc main() {\n\tprintln(1)\n}\n")
+	repo.git(repo.work, "push", "origin", "main")
+
+	mr := repo.conflictBranch("polecat/a",
+		map[string]string{
+			"go.sum":     "a v1 h1:aaa\nb v1 h1:bbb\n",
+			"gen/api.go": "// generated v1 (branch)\n",
+			"main.go":    "package main\n\nfunc main() {\n\tprintln(1)\n}\n\nfunc helper() {}\n",
+		},
+		map[string]string{
+			"go.sum":     "a v1 h1:aaa\nc v1 h1:ccc\n",
+			"gen/api.go": "// generated v1 (main)\n",
+			"main.go":    "package main\n\nfunc main() {\n    println(1)\n}\n", // Reindented
+		})
+
+	e.config.AutoRebase = &config.AutoRebaseConfig{
+		IgnoreWhitespace: true,
+		Rules: []config.ConflictRule{
+			{Pattern: "go.sum", Strategy: config.ConflictUnion},
+			{Pattern: "gen/**", Strategy: config.ConflictRegenerate, Command: "echo '// regenerated' > gen/api.go"},
+		},
+	}
+	before := repo.git(repo.work, "rev-parse", mr.Branch)
+
+	attempt := e.AutoRebase(context.Background(), mr)
+	if !attempt.Success {
+		t.Fatalf("auto-rebase failed: %s\n%s", attempt.Error, attempt.Summary())
+	}
+
+	remote := repo.git(repo.origin, "rev-parse", mr.Branch)
+	if remote == before || remote != attempt.Commit {
+		t.Errorf("origin/%s = %s, want rebased %s", mr.Branch, remote, attempt.Commit)
+	}
+	if local := repo.git(repo.work, "rev-parse", mr.Branch); local != attempt.Commit {
+		t.Errorf("local %s = %s, want %s", mr.Branch, local, attempt.Commit)
+	}
+	if base := repo.git(repo.work, "merge-base", mr.Branch, "origin/main"); base != attempt.Onto {
+		t.Errorf("rebased branch is not on top of main")
+	}
+
+	gosum := repo.show(mr.Branch, "go.sum")
+	for _, line := range []string{"a v1", "b v1", "c v1"} {
+		if !strings.Contains(gosum, line) {
+			t.Errorf("go.sum missing %q after union:\n%s", line, gosum)
+		}
+	}
+	if got := repo.show(mr.Branch, "gen/api.go"); got != "// regenerated" {
+		t.Errorf("gen/api.go = %q, want regenerated", got)
+	}
+	if got := repo.show(mr.Branch, "main.go"); !strings.Contains(got, "helper") {
+		t.Errorf("main.go lost the branch change:\n%s", got)
+	}
+	if len(attempt.Resolved) != 1 || attempt.Resolved[0].Path != "gen/api.go" {
+		t.Errorf("Resolved = %+v, want gen/api.go", attempt.Resolved)
+	}
+}
+
+func TestAutoRebase_UnresolvedConflict(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mr := repo.conflictBranch("polecat/a",
+		map[string]string{"shared.txt": "from branch\n"},
+		map[string]string{"shared.txt": "from main\n"})
+	before := repo.git(repo.origin, "rev-parse", mr.Branch)
+
+	attempt := e.AutoRebase(context.Background(), mr)
+	if attempt.Success {
+		t.Fatal("expected auto-rebase to fail")
+	}
+	if len(attempt.Unresolved) != 1 || attempt.Unresolved[0] != "shared.txt" {
+		t.Errorf("Unresolved = %v, want [shared.txt]", attempt.Unresolved)
+	}
+	if !strings.Contains(attempt.ConflictLog, "from branch") || !strings.Contains(attempt.ConflictLog, "from main") {
+		t.Errorf("conflict log missing both sides:\n%s", attempt.ConflictLog)
+	}
+	if summary := attempt.Summary(); !strings.Contains(summary, "## Auto-rebase attempt") || !strings.Contains(summary, "Unresolved: shared.txt") {
+		t.Errorf("summary:\n%s", summary)
+	}
+	if after := repo.git(repo.origin, "rev-parse", mr.Branch); after != before {
+		t.Error("failed auto-rebase must not push")
+	}
+	if wts := repo.git(repo.work, "worktree", "list"); strings.Count(wts, "\n") != 0 {
+		t.Errorf("scratch worktree not removed:\n%s", wts)
+	}
+}
+
+func TestAutoRebase_TestsFailAfterRebase(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mr := repo.conflictBranch("polecat/a",
+		map[string]string{"CHANGELOG": "branch\n", "bad-x.txt": "x\n"},
+		map[string]string{"CHANGELOG": "main\n"})
+	e.config.AutoRebase = &config.AutoRebaseConfig{
+		Rules: []config.ConflictRule{{Pattern: "CHANGELOG", Strategy: config.ConflictTarget}},
+	}
+	before := repo.git(repo.origin, "rev-parse", mr.Branch)
+
+	attempt := e.AutoRebase(context.Background(), mr)
+	if attempt.Success || !attempt.TestsFailed {
+		t.Fatalf("expected tests to fail on the rebased branch, got %+v", attempt)
+	}
+	if after := repo.git(repo.origin, "rev-parse", mr.Branch); after != before {
+		t.Error("auto-rebase with failing tests must not push")
+	}
+}
+
+func TestMatchConflictRule(t *testing.T) {
+	rules := []config.ConflictRule{
+		{Pattern: "go.sum", Strategy: config.ConflictUnion},
+		{Pattern: "*.lock", Strategy: config.ConflictTarget},
+		{Pattern: "gen/**", Strategy: config.ConflictRegenerate},
+	}
+	tests := map[string]string{
+		"go.sum":          "go.sum",
+		"tools/go.sum":    "go.sum",
+		"web/yarn.lock":   "*.lock",
+		"gen/api/x.pb.go": "gen/**",
+		"main.go":         "",
+		"generated/x":     "",
+	}
+	for file, want := range tests {
+		got := ""
+		if r := matchConflictRule(rules, file); r != nil {
+			got = r.Pattern
+		}
+		if got != want {
+			t.Errorf("matchConflictRule(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestUnionAttributes(t *testing.T) {
+	got := unionAttributes([]config.ConflictRule{
+		{Pattern: "go.sum", Strategy: config.ConflictUnion},
+		{Pattern: "docs/CHANGES", Strategy: config.ConflictUnion},
+		{Pattern: "*.lock", Strategy: config.ConflictTarget},
+	})
+	want := "**/go.sum merge=union\ndocs/CHANGES merge=union\n"
+	if got != want {
+		t.Errorf("unionAttributes = %q, want %q", got, want)
+	}
+}
+
+func TestConflictMarkers(t *testing.T) {
+	dir := t.TempDir()
+	content := "keep\n<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> abc\ntail\n"
+	if err := os.WriteFile(filepath.Join(dir, "f.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := conflictMarkers(dir, []string{"f.txt", "missing.txt"})
+	want := "--- f.txt\n<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> abc\n"
+	if got != want {
+		t.Errorf("conflictMarkers = %q, want %q", got, want)
+	}
+}
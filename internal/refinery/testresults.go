@@ -0,0 +1,454 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains structured test result capture and flaky-test tracking.
+
+package refinery
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Test result formats.
+const (
+	TestFormatGoJSON = "go-test-json"
+	TestFormatJUnit  = "junit"
+)
+
+// excerptLimit caps the log excerpt sent in MERGE_FAILED.
+const excerptLimit = 3000
+
+// excerptTailLines is how much unstructured output is kept as an excerpt.
+const excerptTailLines = 40
+
+// TestCase is one test's outcome in an attempt.
+type TestCase struct {
+	Name   string `json:"name"` // "<package>.<Test>" or "<classname>.<name>"
+	Failed bool   `json:"failed"`
+	Output string `json:"output,omitempty"` // Failure output
+}
+
+// TestAttempt is one run of the test command.
+type TestAttempt struct {
+	Attempt int        `json:"attempt"`
+	Passed  bool       `json:"passed"`
+	Error   string     `json:"error,omitempty"`
+	Format  string     `json:"format,omitempty"` // Empty when the output wasn't structured
+	Cases   []TestCase `json:"-"`
+	Output  []byte     `json:"-"` // Combined stdout and stderr
+}
+
+// FailedTests returns the names of the attempt's failed tests.
+func (a *TestAttempt) FailedTests() []string {
+	var out []string
+	for _, c := range a.Cases {
+		if c.Failed {
+			out = append(out, c.Name)
+		}
+	}
+	return out
+}
+
+// TestReport is the outcome of running the test command, with retries.
+type TestReport struct {
+	Command  string         `json:"command"`
+	Attempts []*TestAttempt `json:"attempts"`
+
+	// Failed are the tests that failed on the final attempt.
+	Failed []string `json:"failed,omitempty"`
+
+	// Flaky are tests that failed on one attempt and passed on a later one.
+	Flaky []string `json:"flaky,omitempty"`
+
+	// Excerpt is the failure output of the failed tests, or the tail of the
+	// output when it wasn't structured.
+	Excerpt string `json:"excerpt,omitempty"`
+
+	// Artifact is the path of the saved full output, once written.
+	Artifact string `json:"artifact,omitempty"`
+}
+
+// finish computes Failed, Flaky and Excerpt from the attempts.
+func (r *TestReport) finish() {
+	if len(r.Attempts) == 0 {
+		return
+	}
+	last := r.Attempts[len(r.Attempts)-1]
+	if !last.Passed {
+		r.Failed = last.FailedTests()
+	}
+
+	// A test is flaky if it failed on some attempt but not the last, and the
+	// last attempt either passed outright or reported it as passing.
+	lastPassed := map[string]bool{}
+	for _, c := range last.Cases {
+		if !c.Failed {
+			lastPassed[c.Name] = true
+		}
+	}
+	seen := map[string]bool{}
+	for _, a := range r.Attempts[:len(r.Attempts)-1] {
+		for _, name := range a.FailedTests() {
+			if seen[name] || (!last.Passed && !lastPassed[name]) {
+				continue
+			}
+			seen[name] = true
+			r.Flaky = append(r.Flaky, name)
+		}
+	}
+	sort.Strings(r.Flaky)
+
+	if !last.Passed {
+		r.Excerpt = excerpt(last)
+	}
+}
+
+// excerpt extracts the most useful part of a failed attempt's output.
+func excerpt(a *TestAttempt) string {
+	var b strings.Builder
+	for _, c := range a.Cases {
+		if c.Failed && c.Output != "" {
+			fmt.Fprintf(&b, "--- FAIL: %s\n%s", c.Name, c.Output)
+			if !strings.HasSuffix(c.Output, "\n") {
+				b.WriteString("\n")
+			}
+		}
+	}
+	out := b.String()
+	if out == "" {
+		lines := strings.Split(strings.TrimRight(string(a.Output), "\n"), "\n")
+		if len(lines) > excerptTailLines {
+			lines = lines[len(lines)-excerptTailLines:]
+		}
+		out = strings.Join(lines, "\n")
+	}
+	if len(out) > excerptLimit {
+		out = out[:excerptLimit] + "\n... (truncated)"
+	}
+	return strings.TrimRight(out, "\n")
+}
+
+// Log renders the full output of every attempt, for the artifact.
+func (r *TestReport) Log() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Test command: %s\n", r.Command)
+	for _, a := range r.Attempts {
+		status := "passed"
+		if !a.Passed {
+			status = "failed"
+			if a.Error != "" {
+				status += ": " + a.Error
+			}
+		}
+		fmt.Fprintf(&b, "\n# Attempt %d (%s)\n", a.Attempt, status)
+		if failed := a.FailedTests(); len(failed) > 0 {
+			fmt.Fprintf(&b, "# Failed tests: %s\n", strings.Join(failed, ", "))
+		}
+		b.Write(a.Output)
+		if len(a.Output) > 0 && !bytes.HasSuffix(a.Output, []byte("\n")) {
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// testArtifactDir is where test output artifacts are kept for a rig.
+func testArtifactDir(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery", "test-output")
+}
+
+// writeTestArtifact saves the report's full output for an MR and sets
+// r.Artifact.
+func writeTestArtifact(rigPath, mrID string, r *TestReport, now time.Time) error {
+	dir := testArtifactDir(rigPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.log", mrID, now.UTC().Format("20060102T150405Z"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, r.Log(), 0644); err != nil { //nolint:gosec // G306: test logs are not secret
+		return err
+	}
+	r.Artifact = path
+	return nil
+}
+
+// parseGoTestJSON parses `go test -json` output. Returns false if the
+// output isn't test2json events. A failed package with no failed tests
+// (a build failure, a panic in TestMain) is reported as a case named
+// after the package.
+func parseGoTestJSON(output []byte) ([]TestCase, bool) {
+	type event struct {
+		Action  string
+		Package string
+		Test    string
+		Output  string
+	}
+
+	var order, pkgOrder []string
+	cases := map[string]*TestCase{}
+	pkgFailed := map[string]bool{}
+	pkgHasFailedTest := map[string]bool{}
+	outputs := map[string]*strings.Builder{}
+	structured := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var ev event
+		if err := json.Unmarshal(line, &ev); err != nil || ev.Action == "" {
+			continue
+		}
+		structured = true
+
+		name := ev.Package
+		if ev.Test != "" {
+			name = ev.Package + "." + ev.Test
+		}
+		switch ev.Action {
+		case "output":
+			if outputs[name] == nil {
+				outputs[name] = &strings.Builder{}
+			}
+			outputs[name].WriteString(ev.Output)
+		case "pass", "fail":
+			failed := ev.Action == "fail"
+			if ev.Test == "" {
+				if failed && !pkgFailed[name] {
+					pkgFailed[name] = true
+					pkgOrder = append(pkgOrder, name)
+				}
+				continue
+			}
+			c, ok := cases[name]
+			if !ok {
+				c = &TestCase{Name: name}
+				cases[name] = c
+				order = append(order, name)
+			}
+			c.Failed = failed
+			if failed {
+				pkgHasFailedTest[ev.Package] = true
+			}
+		}
+	}
+	if !structured {
+		return nil, false
+	}
+
+	var out []TestCase
+	for _, name := range order {
+		c := cases[name]
+		if c.Failed && outputs[name] != nil {
+			c.Output = outputs[name].String()
+		}
+		out = append(out, *c)
+	}
+	for _, pkg := range pkgOrder {
+		if pkgHasFailedTest[pkg] {
+			continue
+		}
+		c := TestCase{Name: pkg, Failed: true}
+		if outputs[pkg] != nil {
+			c.Output = outputs[pkg].String()
+		}
+		out = append(out, c)
+	}
+	return out, true
+}
+
+// junitSuites matches both <testsuites> and a bare <testsuite> root.
+type junitSuites struct {
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	Skipped   *struct{}     `xml:"skipped"`
+	SystemOut string        `xml:"system-out"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// parseJUnitXML parses a JUnit XML report.
+func parseJUnitXML(data []byte) ([]TestCase, error) {
+	var root junitSuites
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var out []TestCase
+	var walk func(cases []junitCase, suites []junitSuite, suiteName string)
+	walk = func(cases []junitCase, suites []junitSuite, suiteName string) {
+		for _, jc := range cases {
+			if jc.Skipped != nil {
+				continue
+			}
+			class := jc.Classname
+			if class == "" {
+				class = suiteName
+			}
+			name := jc.Name
+			if class != "" {
+				name = class + "." + jc.Name
+			}
+			c := TestCase{Name: name}
+			for _, p := range []*junitProblem{jc.Failure, jc.Error} {
+				if p == nil {
+					continue
+				}
+				c.Failed = true
+				text := strings.TrimSpace(p.Text)
+				if text == "" {
+					text = p.Message
+				}
+				c.Output += text + "\n"
+			}
+			if c.Failed && strings.TrimSpace(jc.SystemOut) != "" {
+				c.Output += strings.TrimSpace(jc.SystemOut) + "\n"
+			}
+			out = append(out, c)
+		}
+		for _, s := range suites {
+			walk(s.Cases, s.Suites, s.Name)
+		}
+	}
+	// xml.Unmarshal maps a bare <testsuite> root onto junitSuites too, so its
+	// cases land in root.Cases
+	walk(root.Cases, root.Suites, "")
+	return out, nil
+}
+
+// readJUnitReports parses every report matching pattern (relative to dir).
+func readJUnitReports(dir, pattern string) ([]TestCase, bool) {
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil || len(matches) == 0 {
+		return nil, false
+	}
+	var out []TestCase
+	found := false
+	for _, m := range matches {
+		data, err := os.ReadFile(m) //nolint:gosec // G304: path from trusted rig config
+		if err != nil {
+			continue
+		}
+		cases, err := parseJUnitXML(data)
+		if err != nil {
+			continue
+		}
+		found = true
+		out = append(out, cases...)
+	}
+	return out, found
+}
+
+// removeJUnitReports deletes stale reports so an attempt that crashes
+// before writing one isn't credited with the previous attempt's results.
+func removeJUnitReports(dir, pattern string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+	for _, m := range matches {
+		_ = os.Remove(m)
+	}
+}
+
+// FlakyTest is a test's entry in a rig's flaky-test history.
+type FlakyTest struct {
+	Name      string    `json:"name"`
+	Flakes    int       `json:"flakes"` // Times it failed and then passed on retry
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// FlakyHistory records the flaky tests seen by a rig's refinery.
+type FlakyHistory struct {
+	Tests map[string]*FlakyTest `json:"tests"`
+}
+
+// FlakyHistoryPath returns the flaky-test history file for a rig.
+func FlakyHistoryPath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery", "flaky-tests.json")
+}
+
+// LoadFlakyHistory reads a rig's flaky-test history. A missing file is an
+// empty history.
+func LoadFlakyHistory(rigPath string) (*FlakyHistory, error) {
+	h := &FlakyHistory{Tests: map[string]*FlakyTest{}}
+	data, err := os.ReadFile(FlakyHistoryPath(rigPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("parsing flaky history: %w", err)
+	}
+	if h.Tests == nil {
+		h.Tests = map[string]*FlakyTest{}
+	}
+	return h, nil
+}
+
+// Record counts one flake for each named test.
+func (h *FlakyHistory) Record(names []string, now time.Time) {
+	for _, name := range names {
+		t, ok := h.Tests[name]
+		if !ok {
+			t = &FlakyTest{Name: name, FirstSeen: now}
+			h.Tests[name] = t
+		}
+		t.Flakes++
+		t.LastSeen = now
+	}
+}
+
+// Sorted returns the tests, most flakes first.
+func (h *FlakyHistory) Sorted() []*FlakyTest {
+	out := make([]*FlakyTest, 0, len(h.Tests))
+	for _, t := range h.Tests {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Flakes != out[j].Flakes {
+			return out[i].Flakes > out[j].Flakes
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Save writes the history for a rig.
+func (h *FlakyHistory) Save(rigPath string) error {
+	path := FlakyHistoryPath(rigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644) //nolint:gosec // G306: not secret
+}
@@ -0,0 +1,232 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/rig"
+)
+
+// newTestEngineer returns an engineer for a rig at rigPath that runs tests
+// but has no git repository.
+func newTestEngineer(t *testing.T, rigPath string) *Engineer {
+	t.Helper()
+	e := NewEngineer(&rig.Rig{Name: "testrig", Path: rigPath})
+	e.SetOutput(&bytes.Buffer{})
+	e.config.RunTests = true
+	return e
+}
+
+const goTestJSONFailure = `{"Action":"start","Package":"example.com/app"}
+{"Action":"run","Package":"example.com/app","Test":"TestGood"}
+{"Action":"output","Package":"example.com/app","Test":"TestGood","Output":"=== RUN   TestGood\n"}
+{"Action":"pass","Package":"example.com/app","Test":"TestGood","Elapsed":0}
+{"Action":"run","Package":"example.com/app","Test":"TestBad"}
+{"Action":"output","Package":"example.com/app","Test":"TestBad","Output":"    app_test.go:12: got 1, want 2\n"}
+{"Action":"fail","Package":"example.com/app","Test":"TestBad","Elapsed":0}
+{"Action":"fail","Package":"example.com/app","Elapsed":0.1}
+# example.com/broken
+broken.go:3:1: syntax error
+{"Action":"output","Package":"example.com/broken","Output":"FAIL\texample.com/broken [build failed]\n"}
+{"Action":"fail","Package":"example.com/broken","Elapsed":0}
+`
+
+func TestParseGoTestJSON(t *testing.T) {
+	cases, ok := parseGoTestJSON([]byte(goTestJSONFailure))
+	if !ok {
+		t.Fatal("expected structured output")
+	}
+	got := map[string]TestCase{}
+	for _, c := range cases {
+		got[c.Name] = c
+	}
+	if c := got["example.com/app.TestGood"]; c.Failed {
+		t.Error("TestGood should pass")
+	}
+	bad := got["example.com/app.TestBad"]
+	if !bad.Failed || !strings.Contains(bad.Output, "got 1, want 2") {
+		t.Errorf("TestBad = %+v", bad)
+	}
+	if _, ok := got["example.com/app"]; ok {
+		t.Error("package with failed tests should not be reported as a case")
+	}
+	if c := got["example.com/broken"]; !c.Failed || !strings.Contains(c.Output, "build failed") {
+		t.Errorf("build failure = %+v", c)
+	}
+
+	if _, ok := parseGoTestJSON([]byte("--- FAIL: TestX\nFAIL\n")); ok {
+		t.Error("plain output should not parse as go test -json")
+	}
+}
+
+func TestParseJUnitXML(t *testing.T) {
+	suites := `<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="api">
+    <testcase classname="api.Users" name="create"/>
+    <testcase classname="api.Users" name="delete">
+      <failure message="expected 204">AssertionError: expected 204, got 500</failure>
+      <system-out>DELETE /users/1</system-out>
+    </testcase>
+    <testcase classname="api.Users" name="skipped"><skipped/></testcase>
+  </testsuite>
+</testsuites>`
+	cases, err := parseJUnitXML([]byte(suites))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 2 {
+		t.Fatalf("got %d cases, want 2: %+v", len(cases), cases)
+	}
+	del := cases[1]
+	if del.Name != "api.Users.delete" || !del.Failed || !strings.Contains(del.Output, "got 500") || !strings.Contains(del.Output, "DELETE /users/1") {
+		t.Errorf("delete = %+v", del)
+	}
+
+	bare := `<testsuite name="unit"><testcase name="adds"><error message="boom"/></testcase></testsuite>`
+	cases, err = parseJUnitXML([]byte(bare))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 1 || cases[0].Name != "adds" || !cases[0].Failed || strings.TrimSpace(cases[0].Output) != "boom" {
+		t.Errorf("bare testsuite = %+v", cases)
+	}
+}
+
+func TestTestReportFinish(t *testing.T) {
+	attempt := func(n int, passed bool, failed ...string) *TestAttempt {
+		a := &TestAttempt{Attempt: n, Passed: passed}
+		for _, name := range []string{"TestA", "TestB", "TestC"} {
+			c := TestCase{Name: name}
+			for _, f := range failed {
+				if f == name {
+					c.Failed = true
+					c.Output = name + " broke\n"
+				}
+			}
+			a.Cases = append(a.Cases, c)
+		}
+		return a
+	}
+
+	// Failed, then passed: everything that failed is flaky
+	r := &TestReport{Attempts: []*TestAttempt{attempt(1, false, "TestA"), attempt(2, true)}}
+	r.finish()
+	if !reflect.DeepEqual(r.Flaky, []string{"TestA"}) || r.Failed != nil || r.Excerpt != "" {
+		t.Errorf("passed on retry: %+v", r)
+	}
+
+	// TestB fails every time, TestA only once
+	r = &TestReport{Attempts: []*TestAttempt{attempt(1, false, "TestA", "TestB"), attempt(2, false, "TestB")}}
+	r.finish()
+	if !reflect.DeepEqual(r.Failed, []string{"TestB"}) || !reflect.DeepEqual(r.Flaky, []string{"TestA"}) {
+		t.Errorf("failed = %v, flaky = %v", r.Failed, r.Flaky)
+	}
+	if r.Excerpt != "--- FAIL: TestB\nTestB broke" {
+		t.Errorf("excerpt = %q", r.Excerpt)
+	}
+
+	// Unstructured output: the excerpt is the tail of the log
+	r = &TestReport{Attempts: []*TestAttempt{{Attempt: 1, Output: []byte(strings.Repeat("line\n", 100) + "boom\n")}}}
+	r.finish()
+	if lines := strings.Split(r.Excerpt, "\n"); len(lines) != excerptTailLines || lines[len(lines)-1] != "boom" {
+		t.Errorf("unstructured excerpt has %d lines, ends %q", len(lines), lines[len(lines)-1])
+	}
+}
+
+func TestRunTests_FlakyRecordedInHistory(t *testing.T) {
+	rigPath := t.TempDir()
+	e := newTestEngineer(t, rigPath)
+	marker := filepath.Join(t.TempDir(), "ran-once")
+	// Fails TestFlaky on the first run only
+	e.config.TestCommand = `if [ -f ` + marker + ` ]; then
+  echo '{"Action":"pass","Package":"p","Test":"TestFlaky"}'
+else
+  touch ` + marker + `
+  echo '{"Action":"fail","Package":"p","Test":"TestFlaky"}'
+  exit 1
+fi`
+	e.config.RetryFlakyTests = 2
+
+	result := e.runTestsIn(context.Background(), t.TempDir())
+	if !result.Success {
+		t.Fatalf("expected success on retry: %s", result.Error)
+	}
+	if result.Tests == nil || !reflect.DeepEqual(result.Tests.Flaky, []string{"p.TestFlaky"}) {
+		t.Fatalf("flaky = %+v", result.Tests)
+	}
+
+	history, err := LoadFlakyHistory(rigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft := history.Tests["p.TestFlaky"]; ft == nil || ft.Flakes != 1 {
+		t.Errorf("history = %+v", history.Tests)
+	}
+}
+
+func TestRunTests_FailureDetails(t *testing.T) {
+	rigPath := t.TempDir()
+	e := newTestEngineer(t, rigPath)
+	e.config.TestCommand = `printf '%s\n' '{"Action":"output","Package":"p","Test":"TestBroken","Output":"boom\n"}'
+echo '{"Action":"fail","Package":"p","Test":"TestBroken"}'
+exit 1`
+	e.config.RetryFlakyTests = 2
+
+	result := e.runTestsIn(context.Background(), t.TempDir())
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("expected a test failure, got %+v", result)
+	}
+	if !strings.Contains(result.Error, "failed: p.TestBroken") {
+		t.Errorf("error = %q", result.Error)
+	}
+	if len(result.Tests.Attempts) != 2 || result.Tests.Excerpt != "--- FAIL: p.TestBroken\nboom" {
+		t.Errorf("report = %+v", result.Tests)
+	}
+
+	if err := writeTestArtifact(rigPath, "wt-mr1", result.Tests, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	log, err := os.ReadFile(result.Tests.Artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Attempt 1 (failed", "# Attempt 2 (failed", "# Failed tests: p.TestBroken"} {
+		if !strings.Contains(string(log), want) {
+			t.Errorf("artifact missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestRunTests_JUnitReport(t *testing.T) {
+	e := newTestEngineer(t, t.TempDir())
+	dir := t.TempDir()
+	e.config.TestReport = "reports/*.xml"
+	e.config.TestCommand = `mkdir -p reports && echo '<testsuite><testcase classname="c" name="n"><failure>bad</failure></testcase></testsuite>' > reports/junit.xml && exit 1`
+	e.config.RetryFlakyTests = 1
+
+	result := e.runTestsIn(context.Background(), dir)
+	if result.Tests == nil || result.Tests.Attempts[0].Format != TestFormatJUnit {
+		t.Fatalf("expected JUnit results, got %+v", result.Tests)
+	}
+	if !reflect.DeepEqual(result.Tests.Failed, []string{"c.n"}) {
+		t.Errorf("failed = %v", result.Tests.Failed)
+	}
+}
+
+func TestFlakyHistorySorted(t *testing.T) {
+	h := &FlakyHistory{Tests: map[string]*FlakyTest{}}
+	now := time.Now()
+	h.Record([]string{"b", "a"}, now)
+	h.Record([]string{"b"}, now)
+	sorted := h.Sorted()
+	if len(sorted) != 2 || sorted[0].Name != "b" || sorted[0].Flakes != 2 || sorted[1].Name != "a" {
+		t.Errorf("sorted = %+v", sorted)
+	}
+}
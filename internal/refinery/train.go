@@ -0,0 +1,318 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains the speculative batched merge train.
+
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trainBranchPrefix names the temporary branches trains are built on.
+const trainBranchPrefix = "refinery/train-"
+
+// TrainCar is one MR in a merge train and its outcome.
+type TrainCar struct {
+	MR     *MRInfo       `json:"mr"`
+	Result ProcessResult `json:"result"`
+
+	// Requeued is set when the MR was not at fault for a failed train and
+	// goes back to the queue untouched.
+	Requeued bool `json:"requeued,omitempty"`
+}
+
+// TrainResult is the outcome of a merge train.
+type TrainResult struct {
+	Target   string      `json:"target"`
+	Cars     []*TrainCar `json:"cars"`
+	TestRuns int         `json:"test_runs"`       // Test command runs, including bisection
+	Bisected bool        `json:"bisected"`        // Whether the train failed and was bisected
+	Error    string      `json:"error,omitempty"` // Train-level failure (push rejected, canceled); all cars requeued
+}
+
+// Merged returns the cars that landed on the target.
+func (r *TrainResult) Merged() []*TrainCar {
+	var out []*TrainCar
+	for _, c := range r.Cars {
+		if c.Result.Success {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// SelectTrain picks up to max MRs for one train: the highest-scoring MR
+// and the next highest-scoring MRs with the same target, in score order.
+func SelectTrain(mrs []*MRInfo, max int, now time.Time) []*MRInfo {
+	if len(mrs) == 0 {
+		return nil
+	}
+	if max < 1 {
+		max = 1
+	}
+
+	sorted := make([]*MRInfo, len(mrs))
+	copy(sorted, mrs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := sorted[i].ScoreAt(now), sorted[j].ScoreAt(now)
+		if si != sj {
+			return si > sj
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	target := sorted[0].Target
+	var train []*MRInfo
+	for _, mr := range sorted {
+		if mr.Target != target {
+			continue
+		}
+		train = append(train, mr)
+		if len(train) == max {
+			break
+		}
+	}
+	return train
+}
+
+// ProcessTrain claims the given MRs, runs them as one merge train and
+// applies the outcome: merged MRs get HandleMRInfoSuccess, the culprit of
+// a failed train gets HandleMRInfoFailure, and everything else is
+// released back to the queue.
+func (e *Engineer) ProcessTrain(ctx context.Context, mrs []*MRInfo) *TrainResult {
+	workerID := e.rig.Name + "/refinery"
+	var claimed []*MRInfo
+	for _, mr := range mrs {
+		if err := e.ClaimMR(mr.ID, workerID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to claim %s, leaving it for the next train: %v\n", mr.ID, err)
+			continue
+		}
+		claimed = append(claimed, mr)
+	}
+
+	result := e.RunTrain(ctx, claimed)
+	for _, car := range result.Cars {
+		switch {
+		case car.Result.Success:
+			e.HandleMRInfoSuccess(car.MR, car.Result)
+			continue
+		case car.Requeued:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Requeued: %s\n", car.MR.ID)
+		default:
+			e.HandleMRInfoFailure(car.MR, car.Result)
+		}
+		if err := e.ReleaseMR(car.MR.ID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to release %s: %v\n", car.MR.ID, err)
+		}
+	}
+	return result
+}
+
+// RunTrain stacks the MRs, in order, onto a temporary branch cut from
+// their shared target, runs the tests once and, if they pass, pushes the
+// train to the target (a fast-forward). MRs that conflict or whose branch
+// is missing drop out while stacking. If the tests fail, the train is
+// bisected over its prefixes to find the first MR that breaks them; that
+// MR fails and the rest are requeued.
+//
+// RunTrain only touches git. ProcessTrain applies the outcome to beads.
+func (e *Engineer) RunTrain(ctx context.Context, mrs []*MRInfo) *TrainResult {
+	result := &TrainResult{}
+	if len(mrs) == 0 {
+		return result
+	}
+	target := mrs[0].Target
+	if target == "" {
+		target = e.config.TargetBranch
+	}
+	result.Target = target
+	for _, mr := range mrs {
+		result.Cars = append(result.Cars, &TrainCar{MR: mr})
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Building merge train of %d MR(s) onto %s\n", len(mrs), target)
+	if err := e.git.Checkout(target); err != nil {
+		return result.abort(fmt.Sprintf("failed to checkout target %s: %v", target, err))
+	}
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+	}
+	base, err := e.git.Rev("HEAD")
+	if err != nil {
+		return result.abort(fmt.Sprintf("failed to resolve %s: %v", target, err))
+	}
+
+	train := trainBranchPrefix + time.Now().Format("20060102-150405")
+	defer e.cleanupTrain(target, train)
+
+	stacked, err := e.buildTrain(train, target, base, result.Cars)
+	if err != nil {
+		return result.abort(err.Error())
+	}
+	if len(stacked) == 0 {
+		return result
+	}
+
+	if e.config.RunTests && e.config.TestCommand != "" {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests on train (%d MR(s)): %s\n", len(stacked), e.config.TestCommand)
+		tests := e.runTests(ctx)
+		result.TestRuns++
+		if !tests.Success {
+			if !tests.TestsFailed {
+				return result.abort(tests.Error)
+			}
+			return e.bisectTrain(ctx, result, train, target, base, stacked, tests)
+		}
+		_, _ = fmt.Fprintln(e.output, "[Engineer] Train tests passed")
+	}
+
+	// Push first: if origin moved since we pulled, the push is rejected as
+	// a non-fast-forward and the whole train is requeued against the new tip.
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing train to origin/%s...\n", target)
+	if err := e.git.Push("origin", train+":"+target, false); err != nil {
+		return result.abort(fmt.Sprintf("failed to push train to origin/%s: %v", target, err))
+	}
+	if err := e.git.Checkout(target); err == nil {
+		if err := e.git.MergeFFOnly(train); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fast-forward local %s: %v\n", target, err)
+		}
+	}
+
+	for _, car := range stacked {
+		car.Result.Success = true
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Train landed: %d MR(s) merged into %s\n", len(stacked), target)
+	return result
+}
+
+// buildTrain resets the train branch to base and merges cars onto it in
+// order. Cars that can't be merged get a failure result and are left out;
+// the rest get their merge commit and are returned. Leaves the train
+// branch checked out.
+func (e *Engineer) buildTrain(train, target, base string, cars []*TrainCar) ([]*TrainCar, error) {
+	// The train branch can't be reset while checked out
+	if err := e.git.Checkout(target); err != nil {
+		return nil, fmt.Errorf("failed to checkout target %s: %v", target, err)
+	}
+	if err := e.git.ResetBranch(train, base); err != nil {
+		return nil, fmt.Errorf("failed to create train branch: %v", err)
+	}
+	if err := e.git.Checkout(train); err != nil {
+		return nil, fmt.Errorf("failed to checkout train branch: %v", err)
+	}
+
+	var stacked []*TrainCar
+	for _, car := range cars {
+		mr := car.MR
+		exists, err := e.git.BranchExists(mr.Branch)
+		if err != nil || !exists {
+			car.Result = ProcessResult{Error: fmt.Sprintf("branch %s not found locally", mr.Branch)}
+			continue
+		}
+
+		if conflicts, err := e.mergeBranch(mr, target); err != nil {
+			if len(conflicts) > 0 {
+				car.Result = ProcessResult{
+					Conflict: true,
+					Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+				}
+			} else {
+				car.Result = ProcessResult{Error: fmt.Sprintf("merge failed: %v", err)}
+			}
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Dropped %s from train: %s\n", mr.ID, car.Result.Error)
+			continue
+		}
+
+		commit, err := e.git.Rev("HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get merge commit SHA: %v", err)
+		}
+		car.Result = ProcessResult{MergeCommit: commit}
+		stacked = append(stacked, car)
+	}
+	return stacked, nil
+}
+
+// bisectTrain finds the shortest failing prefix of a train whose full run
+// failed. The last MR of that prefix is the culprit; everything else in
+// the train is requeued.
+func (e *Engineer) bisectTrain(ctx context.Context, result *TrainResult, train, target, base string, stacked []*TrainCar, failed ProcessResult) *TrainResult {
+	lo, hi := 1, len(stacked) // The prefix of length hi is known to fail
+	if hi > 1 {
+		result.Bisected = true
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Train tests failed, bisecting %d MR(s)...\n", len(stacked))
+	}
+	for lo < hi {
+		mid := (lo + hi) / 2
+		prefix := make([]*TrainCar, mid)
+		copy(prefix, stacked[:mid])
+		rebuilt, err := e.buildTrain(train, target, base, prefix)
+		if err != nil {
+			return result.abort(err.Error())
+		}
+		if len(rebuilt) != mid {
+			return result.abort("train changed while bisecting")
+		}
+
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Bisect: testing first %d MR(s)\n", mid)
+		tests := e.runTests(ctx)
+		result.TestRuns++
+		switch {
+		case tests.Success:
+			lo = mid + 1
+		case tests.TestsFailed:
+			hi = mid
+			failed = tests
+		default:
+			return result.abort(tests.Error)
+		}
+	}
+
+	culprit := stacked[lo-1]
+	var ids []string
+	for _, car := range stacked[:lo-1] {
+		ids = append(ids, car.MR.ID)
+	}
+	msg := fmt.Sprintf("tests failed with %s on top of %s", culprit.MR.ID, target)
+	if len(ids) > 0 {
+		msg += " + " + strings.Join(ids, ", ")
+	}
+	culprit.Result = ProcessResult{TestsFailed: true, Error: msg + ": " + failed.Error, Tests: failed.Tests}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Culprit: %s (%s)\n", culprit.MR.ID, culprit.MR.Branch)
+
+	for _, car := range stacked {
+		if car != culprit {
+			car.Result = ProcessResult{}
+			car.Requeued = true
+		}
+	}
+	return result
+}
+
+// abort fails the train as a whole: every car not already dropped is
+// requeued.
+func (r *TrainResult) abort(reason string) *TrainResult {
+	r.Error = reason
+	for _, car := range r.Cars {
+		if car.Result.Error == "" {
+			car.Result = ProcessResult{}
+			car.Requeued = true
+		}
+	}
+	return r
+}
+
+// cleanupTrain leaves the worktree on the target and deletes the train branch.
+func (e *Engineer) cleanupTrain(target, train string) {
+	_ = e.git.AbortMerge()
+	if err := e.git.Checkout(target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to checkout %s after train: %v\n", target, err)
+		return
+	}
+	if exists, _ := e.git.BranchExists(train); exists {
+		_ = e.git.DeleteBranch(train, true)
+	}
+}
@@ -0,0 +1,223 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/rig"
+)
+
+func TestSelectTrain(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	hoursAgo := func(h int) time.Time { return now.Add(-time.Duration(h) * time.Hour) }
+	mrs := []*MRInfo{
+		{ID: "mr-low", Target: "main", Priority: 3, CreatedAt: hoursAgo(1)},
+		{ID: "mr-p0", Target: "main", Priority: 0, CreatedAt: hoursAgo(1)},
+		{ID: "mr-other", Target: "integration/epic", Priority: 1, CreatedAt: hoursAgo(1)},
+		{ID: "mr-p1", Target: "main", Priority: 1, CreatedAt: hoursAgo(2)},
+		{ID: "mr-p2", Target: "main", Priority: 2, CreatedAt: hoursAgo(1)},
+	}
+
+	got := SelectTrain(mrs, 3, now)
+	var ids []string
+	for _, mr := range got {
+		ids = append(ids, mr.ID)
+	}
+	if want := "mr-p0,mr-p1,mr-p2"; strings.Join(ids, ",") != want {
+		t.Errorf("SelectTrain = %v, want %s", ids, want)
+	}
+
+	if got := SelectTrain(mrs, 0, now); len(got) != 1 || got[0].ID != "mr-p0" {
+		t.Errorf("max 0 should select one MR, got %v", got)
+	}
+	if got := SelectTrain(nil, 3, now); got != nil {
+		t.Errorf("empty queue: got %v", got)
+	}
+}
+
+// trainRepo is a refinery clone with an origin remote, for driving real
+// merges.
+type trainRepo struct {
+	t      *testing.T
+	origin string
+	work   string
+}
+
+func newTrainRepo(t *testing.T) (*trainRepo, *Engineer) {
+	t.Helper()
+	rigPath := t.TempDir()
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	work := filepath.Join(rigPath, "refinery", "rig")
+
+	r := &trainRepo{t: t, origin: origin, work: work}
+	r.git("", "init", "--bare", "-b", "main", origin)
+	r.git("", "clone", origin, work)
+	r.git(work, "config", "user.email", "test@test.com")
+	r.git(work, "config", "user.name", "Test User")
+	r.git(work, "checkout", "-b", "main")
+	r.commit("README.md", "# test\n")
+	r.git(work, "push", "origin", "main")
+
+	e := NewEngineer(&rig.Rig{Name: "testrig", Path: rigPath})
+	e.SetOutput(&bytes.Buffer{})
+	e.config.RunTests = true
+	e.config.RetryFlakyTests = 1
+	// A branch "breaks the build" by adding a file named bad-*
+	e.config.TestCommand = "! ls bad-* >/dev/null 2>&1"
+	return r, e
+}
+
+func (r *trainRepo) git(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *trainRepo) commit(file, content string) {
+	r.t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(r.work, file)), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(r.work, file), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+	r.git(r.work, "add", file)
+	r.git(r.work, "commit", "-m", "add "+file)
+}
+
+// branch creates a polecat branch off main adding one file.
+func (r *trainRepo) branch(name, file, content string) *MRInfo {
+	r.t.Helper()
+	r.git(r.work, "checkout", "-b", name, "main")
+	r.commit(file, content)
+	r.git(r.work, "checkout", "main")
+	return &MRInfo{ID: "mr-" + strings.TrimPrefix(name, "polecat/"), Branch: name, Target: "main"}
+}
+
+func (r *trainRepo) originFiles() string {
+	return r.git(r.origin, "ls-tree", "--name-only", "main")
+}
+
+func TestRunTrain_LandsAllOnSuccess(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mrs := []*MRInfo{
+		repo.branch("polecat/a", "a.txt", "a\n"),
+		repo.branch("polecat/b", "b.txt", "b\n"),
+		repo.branch("polecat/c", "c.txt", "c\n"),
+	}
+
+	result := e.RunTrain(context.Background(), mrs)
+	if result.Error != "" {
+		t.Fatalf("train error: %s", result.Error)
+	}
+	if result.TestRuns != 1 {
+		t.Errorf("TestRuns = %d, want 1", result.TestRuns)
+	}
+	if len(result.Merged()) != 3 {
+		t.Fatalf("merged %d MRs, want 3", len(result.Merged()))
+	}
+	for _, car := range result.Cars {
+		if car.Result.MergeCommit == "" {
+			t.Errorf("%s has no merge commit", car.MR.ID)
+		}
+	}
+	files := repo.originFiles()
+	for _, f := range []string{"a.txt", "b.txt", "c.txt"} {
+		if !strings.Contains(files, f) {
+			t.Errorf("origin/main missing %s: %s", f, files)
+		}
+	}
+	if branches := repo.git(repo.work, "branch", "--list", trainBranchPrefix+"*"); branches != "" {
+		t.Errorf("train branch not cleaned up: %s", branches)
+	}
+}
+
+func TestRunTrain_BisectsCulprit(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mrs := []*MRInfo{
+		repo.branch("polecat/a", "a.txt", "a\n"),
+		repo.branch("polecat/b", "b.txt", "b\n"),
+		repo.branch("polecat/c", "bad-c.txt", "c\n"),
+		repo.branch("polecat/d", "d.txt", "d\n"),
+	}
+	before := repo.git(repo.origin, "rev-parse", "main")
+
+	result := e.RunTrain(context.Background(), mrs)
+	if !result.Bisected {
+		t.Error("expected train to be bisected")
+	}
+	// Full train, then prefixes of 2 and 3
+	if result.TestRuns != 3 {
+		t.Errorf("TestRuns = %d, want 3", result.TestRuns)
+	}
+	for _, car := range result.Cars {
+		switch car.MR.ID {
+		case "mr-c":
+			if !car.Result.TestsFailed || car.Requeued {
+				t.Errorf("culprit mr-c: result %+v requeued=%v", car.Result, car.Requeued)
+			}
+		default:
+			if !car.Requeued || car.Result.Success {
+				t.Errorf("%s should be requeued, got %+v", car.MR.ID, car.Result)
+			}
+		}
+	}
+	if after := repo.git(repo.origin, "rev-parse", "main"); after != before {
+		t.Error("failed train must not move origin/main")
+	}
+}
+
+func TestRunTrain_DropsConflicts(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mrs := []*MRInfo{
+		repo.branch("polecat/a", "shared.txt", "from a\n"),
+		repo.branch("polecat/b", "shared.txt", "from b\n"),
+		repo.branch("polecat/c", "c.txt", "c\n"),
+	}
+
+	result := e.RunTrain(context.Background(), mrs)
+	if result.Error != "" {
+		t.Fatalf("train error: %s", result.Error)
+	}
+	var merged []string
+	for _, car := range result.Merged() {
+		merged = append(merged, car.MR.ID)
+	}
+	if got := strings.Join(merged, ","); got != "mr-a,mr-c" {
+		t.Errorf("merged = %s, want mr-a,mr-c", got)
+	}
+	if b := result.Cars[1]; !b.Result.Conflict || b.Requeued {
+		t.Errorf("mr-b should fail with a conflict, got %+v", b.Result)
+	}
+}
+
+func TestRunTrain_RequeuesWhenOriginMoves(t *testing.T) {
+	repo, e := newTrainRepo(t)
+	mrs := []*MRInfo{repo.branch("polecat/a", "a.txt", "a\n")}
+
+	// Someone else pushes to main while the tests run
+	other := filepath.Join(t.TempDir(), "other")
+	repo.git("", "clone", repo.origin, other)
+	repo.git(other, "config", "user.email", "test@test.com")
+	repo.git(other, "config", "user.name", "Test User")
+	e.config.TestCommand = "cd " + other + " && echo x > x.txt && git add x.txt && git commit -qm x && git push -q origin main"
+
+	result := e.RunTrain(context.Background(), mrs)
+	if result.Error == "" {
+		t.Fatal("expected the push to be rejected")
+	}
+	if !result.Cars[0].Requeued {
+		t.Errorf("mr-a should be requeued, got %+v", result.Cars[0].Result)
+	}
+}
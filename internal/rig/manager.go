@@ -158,6 +158,7 @@ func (m *Manager) loadRig(name string, entry config.RigEntry) (*Rig, error) {
 		Path:      rigPath,
 		GitURL:    entry.GitURL,
 		LocalRepo: entry.LocalRepo,
+		Machine:   entry.Machine,
 		Config:    entry.BeadsConfig,
 	}
 
@@ -19,6 +19,10 @@ type Rig struct {
 	// LocalRepo is an optional local repository used for reference clones.
 	LocalRepo string `json:"local_repo,omitempty"`
 
+	// Machine is the registered machine (see wt machine) that hosts the
+	// rig's worktrees and sessions. Empty means the local machine.
+	Machine string `json:"machine,omitempty"`
+
 	// Config is the rig-level configuration.
 	Config *config.BeadsConfig `json:"config,omitempty"`
 
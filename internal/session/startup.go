@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/speaker20/whaletown/internal/tmux"
+	"github.com/speaker20/whaletown/internal/backend"
 )
 
 // StartupNudgeConfig configures a startup nudge message.
@@ -40,7 +40,7 @@ type StartupNudgeConfig struct {
 //
 // The message content doesn't trigger GUPP - CLAUDE.md and hooks handle that.
 // The metadata makes sessions identifiable in /resume.
-func StartupNudge(t *tmux.Tmux, session string, cfg StartupNudgeConfig) error {
+func StartupNudge(t backend.SessionBackend, session string, cfg StartupNudgeConfig) error {
 	message := FormatStartupNudge(cfg)
 	return t.NudgeSession(session, message)
 }
@@ -5,7 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
+
+	"github.com/speaker20/whaletown/internal/config"
+	"github.com/speaker20/whaletown/internal/git"
+	"github.com/speaker20/whaletown/internal/refinery"
 )
 
 // Integration branch errors
@@ -116,10 +121,20 @@ func (m *Manager) LandToMain(swarmID string) error {
 	// Pull latest (non-fatal: may fail if remote unreachable)
 	_ = m.gitRun("pull", "origin", swarm.TargetBranch)
 
-	// Merge integration branch
-	err = m.gitRun("merge", "--no-ff", "-m",
-		fmt.Sprintf("Land swarm %s", swarmID),
-		swarm.Integration)
+	// Land the integration branch using the rig's merge strategy
+	mq, err := refinery.LoadMergeQueueConfig(m.rig)
+	if err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	g := git.NewGit(m.gitDir)
+	switch mq.MergeStrategy {
+	case config.MergeStrategyRebaseAndFF:
+		_, err = g.RebaseFF(swarm.Integration, mq.SignCommits)
+	case config.MergeStrategySquash:
+		err = g.MergeSquash(swarm.Integration, m.landCommitOptions(swarm, mq))
+	default:
+		err = g.MergeNoFFWith(swarm.Integration, m.landCommitOptions(swarm, mq))
+	}
 	if err != nil {
 		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
 		conflicts, conflictErr := m.getConflictingFiles()
@@ -138,6 +153,46 @@ func (m *Manager) LandToMain(swarmID string) error {
 	return nil
 }
 
+// landCommitOptions builds the commit for landing a swarm from the rig's
+// commit_template ("Land swarm <id>" by default), crediting each worker
+// with a Co-authored-by trailer when co_author_trailers is set.
+func (m *Manager) landCommitOptions(swarm *Swarm, mq *refinery.MergeQueueConfig) git.CommitOptions {
+	msg := fmt.Sprintf("Land swarm %s", swarm.ID)
+	var identities []string
+	for _, w := range swarm.Workers {
+		identities = append(identities, fmt.Sprintf("%s/polecats/%s", m.rig.Name, w))
+	}
+
+	if mq.CommitTemplate != "" {
+		data := git.CommitMessageData{
+			Branch:      swarm.Integration,
+			Target:      swarm.TargetBranch,
+			Issue:       swarm.EpicID,
+			Title:       swarm.Title,
+			Description: swarm.Description,
+			Rig:         m.rig.Name,
+			Convoy:      swarm.ID,
+			ConvoyTitle: swarm.Title,
+		}
+		if len(identities) == 1 {
+			data.Polecat = identities[0]
+		}
+		if rendered, err := git.RenderCommitMessage(mq.CommitTemplate, data); err == nil {
+			msg = rendered
+		}
+	}
+
+	if mq.CoAuthorTrailers && len(identities) > 0 {
+		domain := config.LoadAgentEmailDomain(filepath.Dir(m.rig.Path))
+		var trailers []string
+		for _, id := range identities {
+			trailers = append(trailers, git.CoAuthorTrailer(id, domain))
+		}
+		msg = git.AppendTrailers(msg, trailers...)
+	}
+	return git.CommitOptions{Message: msg, Sign: mq.SignCommits}
+}
+
 // CleanupBranches removes all branches associated with a swarm.
 func (m *Manager) CleanupBranches(swarmID string) error {
 	swarm, err := m.LoadSwarm(swarmID)
@@ -54,12 +54,13 @@ func (m *Manager) LoadSwarm(epicID string) (*Swarm, error) {
 
 	// Parse the epic
 	var epic struct {
-		ID        string `json:"id"`
-		Title     string `json:"title"`
-		Status    string `json:"status"`
-		MolType   string `json:"mol_type"`
-		CreatedAt string `json:"created_at"`
-		UpdatedAt string `json:"updated_at"`
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Status      string `json:"status"`
+		MolType     string `json:"mol_type"`
+		CreatedAt   string `json:"created_at"`
+		UpdatedAt   string `json:"updated_at"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &epic); err != nil {
 		return nil, fmt.Errorf("parsing epic: %w", err)
@@ -84,6 +85,8 @@ func (m *Manager) LoadSwarm(epicID string) (*Swarm, error) {
 
 	swarm := &Swarm{
 		ID:           epicID,
+		Title:        epic.Title,
+		Description:  epic.Description,
 		RigName:      m.rig.Name,
 		EpicID:       epicID,
 		BaseCommit:   baseCommit,
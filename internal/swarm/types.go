@@ -42,6 +42,10 @@ type Swarm struct {
 	// ID is the unique swarm identifier (matches beads epic ID).
 	ID string `json:"id"`
 
+	// Title and Description are copied from the epic.
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
 	// RigName is the rig this swarm operates in.
 	RigName string `json:"rig_name"`
 
@@ -34,16 +34,41 @@ var (
 	ErrSessionNotFound = errors.New("session not found")
 )
 
+// Runner runs a tmux command somewhere other than the local machine
+// (e.g., over SSH) and returns its raw stdout and stderr.
+type Runner func(args ...string) (stdout, stderr string, err error)
+
 // Tmux wraps tmux operations.
-type Tmux struct{}
+type Tmux struct {
+	runner Runner // nil runs tmux locally
+}
 
 // NewTmux creates a new Tmux wrapper.
 func NewTmux() *Tmux {
 	return &Tmux{}
 }
 
+// NewTmuxWithRunner creates a Tmux wrapper that runs every tmux command
+// through r. Used to drive tmux on remote machines.
+func NewTmuxWithRunner(r Runner) *Tmux {
+	return &Tmux{runner: r}
+}
+
+// IsRemote returns true if tmux commands run through a Runner.
+func (t *Tmux) IsRemote() bool {
+	return t.runner != nil
+}
+
 // run executes a tmux command and returns stdout.
 func (t *Tmux) run(args ...string) (string, error) {
+	if t.runner != nil {
+		stdout, stderr, err := t.runner(args...)
+		if err != nil {
+			return "", t.wrapError(err, stderr, args)
+		}
+		return strings.TrimSpace(stdout), nil
+	}
+
 	cmd := exec.Command("tmux", args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -159,6 +184,12 @@ func (t *Tmux) KillSession(name string) error {
 //
 // This ensures Claude processes and all their children are properly terminated.
 func (t *Tmux) KillSessionWithProcesses(name string) error {
+	// Process cleanup uses local kill/pgrep; remote sessions rely on
+	// tmux sending SIGHUP to the pane when the session is killed.
+	if t.runner != nil {
+		return t.KillSession(name)
+	}
+
 	// Get the pane PID
 	pid, err := t.GetPanePID(name)
 	if err != nil {
@@ -243,6 +274,10 @@ func (t *Tmux) SetExitEmpty(on bool) error {
 
 // IsAvailable checks if tmux is installed and can be invoked.
 func (t *Tmux) IsAvailable() bool {
+	if t.runner != nil {
+		_, _, err := t.runner("-V")
+		return err == nil
+	}
 	cmd := exec.Command("tmux", "-V")
 	return cmd.Run() == nil
 }
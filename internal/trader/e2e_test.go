@@ -0,0 +1,211 @@
+package trader
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/agents/agenttest"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+// startE2EInstance starts a strategy against a fake environment and waits
+// for its websocket subscriptions.
+func startE2EInstance(t *testing.T, env *agenttest.Env, cfg StrategyConfig) (*Instance, string) {
+	t.Helper()
+
+	signer, key := agenttest.NewWallet()
+	keyFile := filepath.Join(t.TempDir(), "signer.key")
+	if err := os.WriteFile(keyFile, []byte(key+"\n"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	cfg.SignerKeyFile = keyFile
+
+	stateDir := t.TempDir()
+	inst, err := NewInstance(cfg, env.Config(), stateDir)
+	if err != nil {
+		t.Fatalf("NewInstance: %v", err)
+	}
+	inst.Start()
+	t.Cleanup(inst.Stop)
+
+	for _, w := range cfg.Wallets {
+		if !env.WaitForSubscribers(w, 1, 5*time.Second) {
+			t.Fatalf("listener never subscribed to %s", w)
+		}
+	}
+	return inst, signer
+}
+
+func TestE2E_WhaleAlertToExecution(t *testing.T) {
+	env := agenttest.NewEnv(t)
+	env.SetQuoteOutAmount(42_000)
+
+	whale, _ := agenttest.NewWallet()
+	mint := agenttest.NewMint()
+
+	inst, signer := startE2EInstance(t, env, StrategyConfig{
+		Name:        "e2e-fast",
+		Wallets:     []string{whale},
+		SizeSOL:     0.02,
+		SlippageBps: 100,
+	})
+
+	whaleTx := env.WhaleBuy(whale, mint, 5_000_000)
+
+	var fills []Fill
+	if !agenttest.Eventually(5*time.Second, func() bool {
+		fills, _ = LoadFills(inst.stateDir, "e2e-fast")
+		return len(fills) == 1
+	}) {
+		t.Fatalf("no fill recorded; sent=%d quotes=%d", len(env.Sent()), len(env.Quotes()))
+	}
+
+	// Quote: SOL -> the whale's token, sized by the strategy
+	quotes := env.Quotes()
+	if len(quotes) != 1 {
+		t.Fatalf("got %d quotes, want 1", len(quotes))
+	}
+	q := quotes[0]
+	if q.InputMint != copytrade.WSOLMint || q.OutputMint != mint || q.Amount != 20_000_000 || q.SlippageBps != 100 {
+		t.Errorf("quote = %+v", q)
+	}
+
+	// Sent: one transaction signed and paid for by the strategy's signer
+	sent := env.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("got %d sent transactions, want 1", len(sent))
+	}
+	if sent[0].FeePayer != signer {
+		t.Errorf("fee payer = %s, want signer %s", sent[0].FeePayer, signer)
+	}
+
+	// Recorded: fill links our transaction to the whale's
+	f := fills[0]
+	if f.Signature != sent[0].Signature || f.WhaleTx != whaleTx || f.WhaleWallet != whale {
+		t.Errorf("fill = %+v", f)
+	}
+	if f.TokenMint != mint || f.InLamports != 20_000_000 || f.OutAmount != 42_000 {
+		t.Errorf("fill amounts = %+v", f)
+	}
+
+	st := inst.Status()
+	if st.Alerts != 1 || st.Executions != 1 || st.Signer != signer {
+		t.Errorf("status alerts=%d executions=%d signer=%s", st.Alerts, st.Executions, st.Signer)
+	}
+	if pos := st.Positions[mint]; pos == nil || pos.Amount != 42_000 {
+		t.Errorf("position = %+v", pos)
+	}
+}
+
+func TestE2E_ConsensusWaitsForSecondWhale(t *testing.T) {
+	env := agenttest.NewEnv(t)
+
+	whale1, _ := agenttest.NewWallet()
+	whale2, _ := agenttest.NewWallet()
+	mint := agenttest.NewMint()
+
+	inst, _ := startE2EInstance(t, env, StrategyConfig{
+		Name:      "e2e-consensus",
+		Wallets:   []string{whale1, whale2},
+		Signal:    SignalConsensus,
+		Consensus: &ConsensusConfig{MinWallets: 2, Window: "1m"},
+	})
+
+	env.WhaleBuy(whale1, mint, 1_000)
+	if !agenttest.Eventually(5*time.Second, func() bool { return inst.Status().Alerts == 1 }) {
+		t.Fatal("first alert not received")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := len(env.Sent()); n != 0 {
+		t.Fatalf("executed after one whale: %d transactions sent", n)
+	}
+
+	env.WhaleBuy(whale2, mint, 1_000)
+	if !agenttest.Eventually(5*time.Second, func() bool { return len(env.Sent()) == 1 }) {
+		t.Fatal("no execution after second whale")
+	}
+	if !agenttest.Eventually(5*time.Second, func() bool { return inst.Status().Executions == 1 }) {
+		t.Errorf("executions = %d, want 1", inst.Status().Executions)
+	}
+}
+
+func TestE2E_FailedTransactionNotRecorded(t *testing.T) {
+	env := agenttest.NewEnv(t)
+	env.SetFailSends(true)
+
+	whale, _ := agenttest.NewWallet()
+	inst, _ := startE2EInstance(t, env, StrategyConfig{Name: "e2e-fail", Wallets: []string{whale}})
+
+	env.WhaleBuy(whale, agenttest.NewMint(), 1_000)
+
+	if !agenttest.Eventually(5*time.Second, func() bool { return inst.Status().Failures == 1 }) {
+		t.Fatalf("failures = %d, want 1", inst.Status().Failures)
+	}
+	st := inst.Status()
+	if st.Executions != 0 || len(st.Positions) != 0 || st.SpentLamports != 0 {
+		t.Errorf("failed buy was recorded: %+v", st)
+	}
+}
+
+func TestE2E_ExportReconcilesManualTrades(t *testing.T) {
+	env := agenttest.NewEnv(t)
+	env.SetQuoteOutAmount(42_000)
+	env.SetSOLPrice(180)
+
+	whale, _ := agenttest.NewWallet()
+	mint := agenttest.NewMint()
+	inst, signer := startE2EInstance(t, env, StrategyConfig{
+		Name:    "e2e-export",
+		Wallets: []string{whale},
+		SizeSOL: 0.02,
+	})
+
+	env.WhaleBuy(whale, mint, 5_000)
+	if !agenttest.Eventually(5*time.Second, func() bool { return inst.Status().Executions == 1 }) {
+		t.Fatal("no execution")
+	}
+	// Sold by hand: only visible on-chain
+	sell := env.WalletSell(signer, mint, 42_000, 30_000_000)
+
+	fills, err := LoadAllFills(inst.stateDir)
+	if err != nil {
+		t.Fatalf("LoadAllFills: %v", err)
+	}
+	wallets, err := JournalWallets(inst.stateDir, fills)
+	if err != nil {
+		t.Fatalf("JournalWallets: %v", err)
+	}
+	if len(wallets) != 1 || wallets[0] != signer {
+		t.Fatalf("wallets = %v, want [%s]", wallets, signer)
+	}
+	swaps, err := FetchWalletSwaps(env.Config(), wallets, 0)
+	if err != nil {
+		t.Fatalf("FetchWalletSwaps: %v", err)
+	}
+
+	entries, err := BuildJournal(fills, swaps, JournalOptions{})
+	if err != nil {
+		t.Fatalf("BuildJournal: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+
+	buy, s := entries[0], entries[1]
+	if buy.Source != "e2e-export" || buy.WhaleWallet != whale || !buy.OnChain || buy.SOLPriceUSD != 180 {
+		t.Errorf("buy = %+v", buy)
+	}
+	if buy.OutputAmount != 42_000 || buy.FeeLamports != 5_000 {
+		t.Errorf("buy amounts = %+v", buy)
+	}
+	if s.Signature != sell || s.Source != SourceManual || len(s.Lots) != 1 || s.Lots[0].BuySignature != buy.Signature {
+		t.Errorf("sell = %+v", s)
+	}
+	// 0.03 SOL - fee received for 0.02 SOL + fee
+	if want := 0.03 - 0.000005 - 0.020005; math.Abs(s.RealizedSOL-want) > 1e-9 {
+		t.Errorf("realized = %v, want %v", s.RealizedSOL, want)
+	}
+}
@@ -0,0 +1,445 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/speaker20/whaletown/internal/agents/common"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+// markInterval is how often open positions are re-quoted for P&L.
+const markInterval = 5 * time.Minute
+
+// buyer is the execution surface a strategy instance needs.
+// *copytrade.Executor implements it.
+type buyer interface {
+	PublicKey() string
+	DetectBuy(signature solana.Signature) (string, error)
+	ExecuteBuy(tokenMint string, lamports uint64, slippageBps int) (*copytrade.BuyResult, error)
+	Confirm(signature string) error
+	QuoteValue(tokenMint string, amount uint64) (uint64, error)
+	SOLPriceUSD() (float64, error)
+}
+
+// Instance is a running strategy: its own wallets, tracker, listener,
+// signer, risk limits and persisted stats.
+type Instance struct {
+	cfg      StrategyConfig
+	wallets  map[string]common.TrackedWallet
+	stateDir string
+
+	tracker  *copytrade.SolanaTracker
+	listener *copytrade.WebSocketListener
+	executor buyer
+
+	// OnTrade is called for whale alerts and executions.
+	OnTrade func(common.Trade)
+
+	// execMu serializes risk checks and executions so concurrent alerts
+	// can't both pass a limit that only one of them fits under.
+	execMu sync.Mutex
+
+	mu        sync.Mutex
+	state     *InstanceState
+	consensus map[string]map[string]time.Time // mint -> wallet -> last buy
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+}
+
+// newInstance builds an instance for cfg following wallets and executing
+// with exec. State is persisted under stateDir.
+func newInstance(cfg StrategyConfig, wallets []common.TrackedWallet, exec buyer, stateDir string) *Instance {
+	inst := &Instance{
+		cfg:       cfg,
+		wallets:   make(map[string]common.TrackedWallet, len(wallets)),
+		stateDir:  stateDir,
+		executor:  exec,
+		consensus: make(map[string]map[string]time.Time),
+		stopCh:    make(chan struct{}),
+	}
+	for _, w := range wallets {
+		inst.wallets[w.Address] = w
+	}
+
+	// Resume counters and positions from a previous run
+	state, err := LoadInstanceState(stateDir, cfg.Name)
+	if err != nil {
+		state = &InstanceState{Positions: make(map[string]*Position)}
+	}
+	state.Name = cfg.Name
+	state.Signal = cfg.Signal
+	state.Wallets = len(wallets)
+	state.DryRun = cfg.DryRun
+	state.PID = os.Getpid()
+	state.StartedAt = time.Now()
+	if exec != nil {
+		state.Signer = exec.PublicKey()
+	}
+	inst.state = state
+	return inst
+}
+
+// NewInstance creates a strategy instance from its config, resolving
+// wallets against the researcher watchlist and loading its signer.
+func NewInstance(cfg StrategyConfig, config *common.Config, stateDir string) (*Instance, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := cfg.SignerKey()
+	if err != nil {
+		return nil, fmt.Errorf("strategy %s signer: %w", cfg.Name, err)
+	}
+	exec, err := copytrade.NewExecutorWithKey(config, key)
+	if err != nil {
+		return nil, fmt.Errorf("strategy %s signer: %w", cfg.Name, err)
+	}
+
+	wallets := cfg.ResolveWallets(loadWatchlist())
+	if len(wallets) == 0 {
+		return nil, fmt.Errorf("strategy %s: no wallets to follow", cfg.Name)
+	}
+
+	inst := newInstance(cfg, wallets, exec, stateDir)
+
+	cursors, err := copytrade.LoadCursorStore(filepath.Join(instanceDir(stateDir, cfg.Name), "cursors.json"))
+	if err != nil {
+		fmt.Printf("⚠️  [%s] Failed to load trade cursors: %v\n", cfg.Name, err)
+	}
+	inst.tracker = copytrade.NewSolanaTrackerWithCursors(config, wallets, cursors)
+
+	if config.HasWebSocket() || config.HeliusAPIKey != "" {
+		inst.listener = copytrade.NewWebSocketListener(config, wallets)
+		inst.listener.OnTrade = inst.handleAlert
+		inst.listener.OnReconnect = inst.poll
+	}
+	return inst, nil
+}
+
+// Name returns the strategy name.
+func (i *Instance) Name() string {
+	return i.cfg.Name
+}
+
+// Start begins listening and polling in the background.
+func (i *Instance) Start() {
+	i.mu.Lock()
+	i.state.Running = true
+	i.mu.Unlock()
+	i.save()
+
+	if i.listener != nil {
+		go func() {
+			if err := i.listener.Start(context.Background()); err != nil {
+				fmt.Printf("⚠️  [%s] WebSocket error: %v\n", i.cfg.Name, err)
+			}
+		}()
+	}
+	go i.loop()
+}
+
+// Stop stops the instance and marks it stopped on disk.
+func (i *Instance) Stop() {
+	i.stopOnce.Do(func() {
+		close(i.stopCh)
+		if i.listener != nil {
+			i.listener.Stop()
+		}
+		i.mu.Lock()
+		i.state.Running = false
+		i.mu.Unlock()
+		i.save()
+	})
+}
+
+// Status returns a snapshot of the instance's state.
+func (i *Instance) Status() InstanceState {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	s := *i.state
+	s.Positions = make(map[string]*Position, len(i.state.Positions))
+	for k, p := range i.state.Positions {
+		cp := *p
+		s.Positions[k] = &cp
+	}
+	return s
+}
+
+// loop polls for backfill and re-marks positions until stopped.
+func (i *Instance) loop() {
+	pollTicker := time.NewTicker(30 * time.Second)
+	defer pollTicker.Stop()
+	markTicker := time.NewTicker(markInterval)
+	defer markTicker.Stop()
+
+	i.poll()
+	for {
+		select {
+		case <-i.stopCh:
+			return
+		case <-pollTicker.C:
+			i.poll()
+		case <-markTicker.C:
+			i.markPositions()
+		}
+	}
+}
+
+// poll ingests new transactions for the instance's wallets.
+func (i *Instance) poll() {
+	if i.tracker == nil {
+		return
+	}
+	if _, err := i.tracker.Poll(); err != nil {
+		fmt.Printf("⚠️  [%s] Trade poll failed: %v\n", i.cfg.Name, err)
+	}
+}
+
+// handleAlert processes a websocket alert for one of the followed wallets.
+func (i *Instance) handleAlert(trade common.Trade) {
+	if _, ok := i.wallets[trade.Wallet]; !ok && trade.Wallet != "" {
+		return
+	}
+
+	i.mu.Lock()
+	i.state.Alerts++
+	i.mu.Unlock()
+
+	trade.WalletAlias = fmt.Sprintf("%s [%s]", trade.WalletAlias, i.cfg.Name)
+	i.emit(trade)
+
+	if trade.TxHash == "" {
+		return
+	}
+	sig, err := solana.SignatureFromBase58(trade.TxHash)
+	if err != nil {
+		return
+	}
+
+	// Detect and execute off the websocket goroutine
+	go func() {
+		mint, err := i.executor.DetectBuy(sig)
+		if err != nil {
+			return // Not a buy
+		}
+		i.onBuy(trade.Wallet, mint, trade.TxHash)
+	}()
+}
+
+// onBuy handles a whale buying mint: applies the signal filter and risk
+// limits, then executes.
+func (i *Instance) onBuy(wallet, mint, whaleTx string) {
+	if !i.signalFires(wallet, mint, time.Now()) {
+		return
+	}
+
+	i.execMu.Lock()
+	defer i.execMu.Unlock()
+
+	i.mu.Lock()
+	i.state.Signals++
+	reason := i.checkRisk(mint, i.cfg.SizeLamports(), time.Now())
+	if reason != "" {
+		i.state.Skipped++
+	}
+	i.mu.Unlock()
+
+	if reason != "" {
+		fmt.Printf("🛑 [%s] Skipping %s: %s\n", i.cfg.Name, mint, reason)
+		i.save()
+		return
+	}
+
+	var result *copytrade.BuyResult
+	if i.cfg.DryRun {
+		result = &copytrade.BuyResult{TokenMint: mint, InLamports: i.cfg.SizeLamports()}
+	} else {
+		var err error
+		result, err = i.executor.ExecuteBuy(mint, i.cfg.SizeLamports(), i.cfg.SlippageBps)
+		if err == nil {
+			// Only confirmed buys count toward spending and positions
+			err = i.executor.Confirm(result.Signature)
+		}
+		if err != nil {
+			fmt.Printf("❌ [%s] Buy failed: %v\n", i.cfg.Name, err)
+			i.mu.Lock()
+			i.state.Failures++
+			i.mu.Unlock()
+			i.save()
+			return
+		}
+	}
+
+	i.recordFill(wallet, whaleTx, result, time.Now())
+
+	i.emit(common.Trade{
+		Type:        "Executed ✅",
+		TokenOut:    result.TokenMint,
+		TxHash:      result.Signature,
+		Timestamp:   time.Now(),
+		WalletAlias: i.cfg.Name,
+		Platform:    "solana",
+	})
+}
+
+// signalFires reports whether a buy of mint by wallet triggers the
+// strategy's signal. In consensus mode it fires once MinWallets distinct
+// wallets have bought within the window, then resets for that mint.
+func (i *Instance) signalFires(wallet, mint string, now time.Time) bool {
+	if i.cfg.Signal != SignalConsensus {
+		return true
+	}
+
+	window, _ := i.cfg.ConsensusWindow()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	buyers := i.consensus[mint]
+	if buyers == nil {
+		buyers = make(map[string]time.Time)
+		i.consensus[mint] = buyers
+	}
+	buyers[wallet] = now
+	for w, at := range buyers {
+		if now.Sub(at) > window {
+			delete(buyers, w)
+		}
+	}
+
+	if len(buyers) >= i.cfg.Consensus.MinWallets {
+		delete(i.consensus, mint)
+		return true
+	}
+	return false
+}
+
+// checkRisk returns why a buy of lamports into mint would breach the risk
+// limits, or "" if it is allowed. Caller must hold i.mu.
+func (i *Instance) checkRisk(mint string, lamports uint64, now time.Time) string {
+	limits := i.cfg.Risk
+	day := now.UTC().Format("2006-01-02")
+
+	daily := i.state.DailyLamports
+	if i.state.DailyDate != day {
+		daily = 0
+	}
+	if limits.MaxDailySOL > 0 && float64(daily+lamports) > limits.MaxDailySOL*lamportsPerSOL {
+		return fmt.Sprintf("daily limit %.4g SOL reached", limits.MaxDailySOL)
+	}
+
+	pos := i.state.Positions[mint]
+	if limits.MaxTokenSOL > 0 {
+		var cost uint64
+		if pos != nil {
+			cost = pos.CostLamports
+		}
+		if float64(cost+lamports) > limits.MaxTokenSOL*lamportsPerSOL {
+			return fmt.Sprintf("per-token limit %.4g SOL reached", limits.MaxTokenSOL)
+		}
+	}
+	if limits.MaxOpenPositions > 0 && pos == nil && len(i.state.Positions) >= limits.MaxOpenPositions {
+		return fmt.Sprintf("max %d open positions", limits.MaxOpenPositions)
+	}
+	return ""
+}
+
+// recordFill updates spending and positions and appends to the fill log.
+func (i *Instance) recordFill(wallet, whaleTx string, result *copytrade.BuyResult, now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+
+	i.mu.Lock()
+	i.state.Executions++
+	if !i.cfg.DryRun {
+		i.state.SpentLamports += result.InLamports
+		if i.state.DailyDate != day {
+			i.state.DailyDate = day
+			i.state.DailyLamports = 0
+		}
+		i.state.DailyLamports += result.InLamports
+
+		pos := i.state.Positions[result.TokenMint]
+		if pos == nil {
+			pos = &Position{Mint: result.TokenMint}
+			i.state.Positions[result.TokenMint] = pos
+		}
+		pos.Amount += result.OutAmount
+		pos.CostLamports += result.InLamports
+		pos.ValueLamports += result.InLamports // Marked at cost until re-quoted
+		pos.ValuedAt = now
+	}
+	i.mu.Unlock()
+
+	fill := Fill{
+		Timestamp:   now,
+		Strategy:    i.cfg.Name,
+		Signature:   result.Signature,
+		TokenMint:   result.TokenMint,
+		InLamports:  result.InLamports,
+		OutAmount:   result.OutAmount,
+		Whale:       i.wallets[wallet].Alias,
+		WhaleWallet: wallet,
+		WhaleTx:     whaleTx,
+		DryRun:      i.cfg.DryRun,
+	}
+	if !i.cfg.DryRun && i.executor != nil {
+		fill.Wallet = i.executor.PublicKey()
+		// Best effort: the journal falls back to --sol-usd without it
+		if price, err := i.executor.SOLPriceUSD(); err == nil {
+			fill.SOLPriceUSD = price
+		}
+	}
+	if err := AppendFill(i.stateDir, fill); err != nil {
+		fmt.Printf("⚠️  [%s] Failed to record fill: %v\n", i.cfg.Name, err)
+	}
+	i.save()
+}
+
+// markPositions re-quotes open positions to update unrealized P&L.
+func (i *Instance) markPositions() {
+	i.mu.Lock()
+	positions := make([]Position, 0, len(i.state.Positions))
+	for _, p := range i.state.Positions {
+		positions = append(positions, *p)
+	}
+	i.mu.Unlock()
+
+	for _, p := range positions {
+		value, err := i.executor.QuoteValue(p.Mint, p.Amount)
+		if err != nil {
+			continue // Keep the last mark
+		}
+		i.mu.Lock()
+		if pos := i.state.Positions[p.Mint]; pos != nil {
+			pos.ValueLamports = value
+			pos.ValuedAt = time.Now()
+		}
+		i.mu.Unlock()
+	}
+	i.save()
+}
+
+// save persists the instance state.
+func (i *Instance) save() {
+	i.mu.Lock()
+	i.state.UpdatedAt = time.Now()
+	i.mu.Unlock()
+
+	snapshot := i.Status()
+	if err := SaveInstanceState(i.stateDir, &snapshot); err != nil {
+		fmt.Printf("⚠️  [%s] Failed to save state: %v\n", i.cfg.Name, err)
+	}
+}
+
+// emit forwards a trade to the OnTrade callback.
+func (i *Instance) emit(trade common.Trade) {
+	if i.OnTrade != nil {
+		i.OnTrade(trade)
+	}
+}
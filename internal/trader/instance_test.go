@@ -0,0 +1,178 @@
+package trader
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/speaker20/whaletown/internal/agents/common"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+// fakeBuyer records buys instead of sending transactions.
+type fakeBuyer struct {
+	mu   sync.Mutex
+	buys []string
+	fail bool
+}
+
+func (f *fakeBuyer) PublicKey() string { return "signer" }
+
+func (f *fakeBuyer) DetectBuy(solana.Signature) (string, error) { return "", fmt.Errorf("unused") }
+
+func (f *fakeBuyer) ExecuteBuy(mint string, lamports uint64, _ int) (*copytrade.BuyResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, fmt.Errorf("rpc down")
+	}
+	f.buys = append(f.buys, mint)
+	return &copytrade.BuyResult{
+		Signature:  fmt.Sprintf("sig%d", len(f.buys)),
+		TokenMint:  mint,
+		InLamports: lamports,
+		OutAmount:  1000,
+	}, nil
+}
+
+func (f *fakeBuyer) Confirm(string) error { return nil }
+
+func (f *fakeBuyer) QuoteValue(string, uint64) (uint64, error) { return 15_000_000, nil }
+
+func (f *fakeBuyer) SOLPriceUSD() (float64, error) { return 150, nil }
+
+func newTestInstance(t *testing.T, cfg StrategyConfig) (*Instance, *fakeBuyer) {
+	t.Helper()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	wallets := []common.TrackedWallet{
+		{Address: "w1", Alias: "One", Platform: "solana"},
+		{Address: "w2", Alias: "Two", Platform: "solana"},
+		{Address: "w3", Alias: "Three", Platform: "solana"},
+	}
+	exec := &fakeBuyer{}
+	return newInstance(cfg, wallets, exec, t.TempDir()), exec
+}
+
+func TestInstanceFollowRecordsFill(t *testing.T) {
+	inst, exec := newTestInstance(t, StrategyConfig{Name: "fast", Wallets: []string{"w1"}, SizeSOL: 0.01})
+
+	inst.onBuy("w1", "MINT", "whaletx")
+
+	if len(exec.buys) != 1 {
+		t.Fatalf("executed %d buys, want 1", len(exec.buys))
+	}
+	st := inst.Status()
+	if st.Executions != 1 || st.SpentLamports != 10_000_000 {
+		t.Errorf("executions=%d spent=%d, want 1 and 10000000", st.Executions, st.SpentLamports)
+	}
+	pos := st.Positions["MINT"]
+	if pos == nil || pos.Amount != 1000 || pos.CostLamports != 10_000_000 {
+		t.Fatalf("position = %+v", pos)
+	}
+
+	// Re-marking updates P&L
+	inst.markPositions()
+	st = inst.Status()
+	if got := st.PnLSOL(); got < 0.0049 || got > 0.0051 {
+		t.Errorf("PnLSOL = %v, want 0.005", got)
+	}
+
+	fills, err := LoadFills(inst.stateDir, "fast")
+	if err != nil {
+		t.Fatalf("LoadFills: %v", err)
+	}
+	if len(fills) != 1 || fills[0].Whale != "One" || fills[0].WhaleTx != "whaletx" {
+		t.Errorf("fills = %+v", fills)
+	}
+
+	// State is visible to other processes
+	saved, err := LoadInstanceState(inst.stateDir, "fast")
+	if err != nil {
+		t.Fatalf("LoadInstanceState: %v", err)
+	}
+	if saved.Executions != 1 {
+		t.Errorf("saved executions = %d, want 1", saved.Executions)
+	}
+}
+
+func TestInstanceRiskLimits(t *testing.T) {
+	inst, exec := newTestInstance(t, StrategyConfig{
+		Name:    "capped",
+		Wallets: []string{"w1"},
+		SizeSOL: 0.01,
+		Risk:    RiskLimits{MaxDailySOL: 0.025, MaxTokenSOL: 0.015, MaxOpenPositions: 2},
+	})
+
+	inst.onBuy("w1", "A", "tx1") // ok
+	inst.onBuy("w1", "A", "tx2") // per-token cap
+	inst.onBuy("w1", "B", "tx3") // ok
+	inst.onBuy("w1", "C", "tx4") // daily cap
+
+	if fmt.Sprint(exec.buys) != "[A B]" {
+		t.Errorf("buys = %v, want [A B]", exec.buys)
+	}
+	st := inst.Status()
+	if st.Skipped != 2 {
+		t.Errorf("skipped = %d, want 2", st.Skipped)
+	}
+
+	// A new UTC day resets the daily budget, but the position cap holds
+	inst.mu.Lock()
+	inst.state.DailyDate = "2000-01-01"
+	reason := inst.checkRisk("C", 10_000_000, time.Now())
+	inst.mu.Unlock()
+	if reason == "" {
+		t.Error("expected open-position limit to block a third token")
+	}
+}
+
+func TestInstanceConsensus(t *testing.T) {
+	inst, exec := newTestInstance(t, StrategyConfig{
+		Name:       "conservative",
+		TopWallets: 3,
+		Signal:     SignalConsensus,
+		Consensus:  &ConsensusConfig{MinWallets: 2, Window: "10m"},
+	})
+
+	now := time.Now()
+	if inst.signalFires("w1", "A", now) {
+		t.Error("one buyer should not fire")
+	}
+	if inst.signalFires("w1", "A", now.Add(time.Minute)) {
+		t.Error("same buyer twice should not fire")
+	}
+	if !inst.signalFires("w2", "A", now.Add(2*time.Minute)) {
+		t.Error("two distinct buyers should fire")
+	}
+	if inst.signalFires("w3", "A", now.Add(3*time.Minute)) {
+		t.Error("consensus should reset after firing")
+	}
+
+	// Buys outside the window don't count
+	if inst.signalFires("w1", "B", now) {
+		t.Error("one buyer should not fire")
+	}
+	if inst.signalFires("w2", "B", now.Add(11*time.Minute)) {
+		t.Error("stale buyer should have expired")
+	}
+
+	if len(exec.buys) != 0 {
+		t.Errorf("signalFires should not execute, got %v", exec.buys)
+	}
+}
+
+func TestInstanceFailureCounted(t *testing.T) {
+	inst, exec := newTestInstance(t, StrategyConfig{Name: "flaky", Wallets: []string{"w1"}})
+	exec.fail = true
+
+	inst.onBuy("w1", "A", "tx")
+
+	st := inst.Status()
+	if st.Failures != 1 || st.Executions != 0 || len(st.Positions) != 0 {
+		t.Errorf("failures=%d executions=%d positions=%d", st.Failures, st.Executions, len(st.Positions))
+	}
+}
@@ -0,0 +1,456 @@
+package trader
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/agents/common"
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+// Lot matching methods for realized gains.
+const (
+	LotFIFO     = "fifo"     // Sells consume the oldest open buys first
+	LotSpecific = "specific" // Sells consume the buys named in a lot selection
+)
+
+// SourceManual marks journal entries found on-chain without a matching
+// fill, e.g. buys made through the dashboard's /buy endpoint.
+const SourceManual = "manual"
+
+// JournalEntry is one trade in the export journal.
+type JournalEntry struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Signature    string    `json:"signature"`
+	Side         string    `json:"side"`   // "buy" or "sell"
+	Source       string    `json:"source"` // Strategy name, "copytrade" or "manual"
+	Venue        string    `json:"venue"`
+	Wallet       string    `json:"wallet"`
+	InputMint    string    `json:"input_mint"`
+	InputAmount  uint64    `json:"input_amount"` // Base units (lamports for SOL)
+	OutputMint   string    `json:"output_mint"`
+	OutputAmount uint64    `json:"output_amount"`
+
+	SOL         float64 `json:"sol"`               // SOL spent or received, excluding fees
+	SOLPriceUSD float64 `json:"sol_usd,omitempty"` // 0 if unknown
+	ValueUSD    float64 `json:"value_usd,omitempty"`
+	FeeLamports uint64  `json:"fee_lamports"`
+	PriorityFee uint64  `json:"priority_fee_lamports"`
+
+	Whale       string `json:"whale,omitempty"`
+	WhaleWallet string `json:"whale_wallet,omitempty"`
+	WhaleTx     string `json:"whale_tx,omitempty"`
+	OnChain     bool   `json:"onchain"` // Seen in the wallet's on-chain history
+
+	// Sells only
+	LotMethod    string     `json:"lot_method,omitempty"`
+	Lots         []LotMatch `json:"lots,omitempty"`
+	CostBasisSOL float64    `json:"cost_basis_sol,omitempty"`
+	CostBasisUSD float64    `json:"cost_basis_usd,omitempty"`
+	ProceedsSOL  float64    `json:"proceeds_sol,omitempty"`
+	ProceedsUSD  float64    `json:"proceeds_usd,omitempty"`
+	RealizedSOL  float64    `json:"realized_sol,omitempty"`
+	RealizedUSD  float64    `json:"realized_usd,omitempty"`
+	Unmatched    uint64     `json:"unmatched_amount,omitempty"` // Sold with no open lot
+}
+
+// LotMatch is the part of a buy consumed by a sell.
+type LotMatch struct {
+	BuySignature string  `json:"buy_signature"`
+	Amount       uint64  `json:"amount"`
+	CostSOL      float64 `json:"cost_sol"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+}
+
+// JournalOptions controls how a journal is built.
+type JournalOptions struct {
+	Method string // LotFIFO (default) or LotSpecific
+
+	// SpecificLots maps a sell signature to the buy signatures it
+	// disposes of, in order. Sells without a selection fall back to FIFO.
+	SpecificLots map[string][]string
+
+	// SOLPriceUSD is used for entries whose fill recorded no price.
+	SOLPriceUSD float64
+
+	// From and To bound the exported entries to [From, To). Zero values
+	// are unbounded. Lots are matched over the full history regardless.
+	From, To time.Time
+}
+
+// lot is an open buy.
+type lot struct {
+	signature string
+	mint      string
+	remaining uint64
+	amount    uint64
+	costSOL   float64 // For the full amount, fees included
+	costUSD   float64 // 0 if the price is unknown
+}
+
+// BuildJournal merges fills with on-chain swaps from the executor wallets
+// and computes realized gains per lot. A swap with the same signature as
+// a fill is the same trade; swaps with no fill are manual trades. Dry-run
+// fills are excluded.
+func BuildJournal(fills []Fill, swaps []copytrade.WalletSwap, opts JournalOptions) ([]JournalEntry, error) {
+	method := opts.Method
+	if method == "" {
+		method = LotFIFO
+	}
+	if method != LotFIFO && method != LotSpecific {
+		return nil, fmt.Errorf("unknown lot method %q (want %s or %s)", method, LotFIFO, LotSpecific)
+	}
+
+	bySig := make(map[string]Fill, len(fills))
+	for _, f := range fills {
+		if !f.DryRun && f.Signature != "" {
+			bySig[f.Signature] = f
+		}
+	}
+
+	var entries []JournalEntry
+	seen := make(map[string]bool)
+	for _, s := range swaps {
+		if seen[s.Signature] {
+			continue
+		}
+		seen[s.Signature] = true
+
+		e := entryFromSwap(s)
+		if f, ok := bySig[s.Signature]; ok {
+			e.Source = f.Strategy
+			e.Whale = f.Whale
+			e.WhaleWallet = f.WhaleWallet
+			e.WhaleTx = f.WhaleTx
+			e.SOLPriceUSD = f.SOLPriceUSD
+		}
+		entries = append(entries, e)
+	}
+	// Fills not found on-chain: history unavailable, or not yet indexed
+	for _, f := range fills {
+		if f.DryRun || f.Signature == "" || seen[f.Signature] {
+			continue
+		}
+		seen[f.Signature] = true
+		entries = append(entries, entryFromFill(f))
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	for i := range entries {
+		e := &entries[i]
+		if e.SOLPriceUSD == 0 {
+			e.SOLPriceUSD = opts.SOLPriceUSD
+		}
+		e.ValueUSD = e.SOL * e.SOLPriceUSD
+	}
+
+	if err := matchLots(entries, method, opts.SpecificLots); err != nil {
+		return nil, err
+	}
+
+	out := entries[:0]
+	for _, e := range entries {
+		if !opts.From.IsZero() && e.Timestamp.Before(opts.From) {
+			continue
+		}
+		if !opts.To.IsZero() && !e.Timestamp.Before(opts.To) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// entryFromSwap builds a journal entry for an on-chain swap.
+func entryFromSwap(s copytrade.WalletSwap) JournalEntry {
+	e := JournalEntry{
+		Timestamp:   s.Timestamp,
+		Signature:   s.Signature,
+		Side:        s.Side,
+		Source:      SourceManual,
+		Venue:       s.Venue,
+		Wallet:      s.Wallet,
+		SOL:         float64(s.SOLLamports) / lamportsPerSOL,
+		FeeLamports: s.FeeLamports,
+		PriorityFee: s.PriorityFee,
+		OnChain:     true,
+	}
+	if s.Side == "buy" {
+		e.InputMint, e.InputAmount = copytrade.WSOLMint, s.SOLLamports
+		e.OutputMint, e.OutputAmount = s.TokenMint, s.TokenAmount
+	} else {
+		e.InputMint, e.InputAmount = s.TokenMint, s.TokenAmount
+		e.OutputMint, e.OutputAmount = copytrade.WSOLMint, s.SOLLamports
+	}
+	return e
+}
+
+// entryFromFill builds a journal entry from a fill alone. Fees are
+// unknown and the output amount is the quoted one.
+func entryFromFill(f Fill) JournalEntry {
+	return JournalEntry{
+		Timestamp:    f.Timestamp,
+		Signature:    f.Signature,
+		Side:         "buy",
+		Source:       f.Strategy,
+		Venue:        "jupiter",
+		Wallet:       f.Wallet,
+		InputMint:    copytrade.WSOLMint,
+		InputAmount:  f.InLamports,
+		OutputMint:   f.TokenMint,
+		OutputAmount: f.OutAmount,
+		SOL:          float64(f.InLamports) / lamportsPerSOL,
+		SOLPriceUSD:  f.SOLPriceUSD,
+		Whale:        f.Whale,
+		WhaleWallet:  f.WhaleWallet,
+		WhaleTx:      f.WhaleTx,
+	}
+}
+
+// matchLots opens a lot for each buy and fills in cost basis and realized
+// gains for each sell. Entries must be in time order. Lots are kept per
+// wallet and mint.
+func matchLots(entries []JournalEntry, method string, selections map[string][]string) error {
+	open := make(map[string][]*lot) // wallet/mint -> open lots, oldest first
+	bySig := make(map[string]*lot)
+
+	for i := range entries {
+		e := &entries[i]
+		fee := float64(e.FeeLamports) / lamportsPerSOL
+
+		if e.Side == "buy" {
+			l := &lot{
+				signature: e.Signature,
+				mint:      e.OutputMint,
+				remaining: e.OutputAmount,
+				amount:    e.OutputAmount,
+				costSOL:   e.SOL + fee,
+				costUSD:   (e.SOL + fee) * e.SOLPriceUSD,
+			}
+			key := e.Wallet + "/" + l.mint
+			open[key] = append(open[key], l)
+			bySig[l.signature] = l
+			continue
+		}
+
+		e.LotMethod = LotFIFO
+		e.ProceedsSOL = e.SOL - fee
+		e.ProceedsUSD = e.ProceedsSOL * e.SOLPriceUSD
+		want := e.InputAmount
+		key := e.Wallet + "/" + e.InputMint
+
+		take := func(l *lot) {
+			n := l.remaining
+			if n > want {
+				n = want
+			}
+			if n == 0 {
+				return
+			}
+			share := float64(n) / float64(l.amount)
+			m := LotMatch{
+				BuySignature: l.signature,
+				Amount:       n,
+				CostSOL:      l.costSOL * share,
+				CostUSD:      l.costUSD * share,
+			}
+			e.Lots = append(e.Lots, m)
+			e.CostBasisSOL += m.CostSOL
+			e.CostBasisUSD += m.CostUSD
+			l.remaining -= n
+			want -= n
+		}
+
+		if sel, ok := selections[e.Signature]; ok && method == LotSpecific {
+			e.LotMethod = LotSpecific
+			for _, sig := range sel {
+				l, ok := bySig[sig]
+				if !ok {
+					return fmt.Errorf("lot selection for %s: no earlier buy %s", e.Signature, sig)
+				}
+				if l.mint != e.InputMint {
+					return fmt.Errorf("lot selection for %s: buy %s is a different token", e.Signature, sig)
+				}
+				take(l)
+			}
+		}
+		for _, l := range open[key] {
+			if want == 0 {
+				break
+			}
+			take(l)
+		}
+
+		// Drop exhausted lots
+		remaining := open[key][:0]
+		for _, l := range open[key] {
+			if l.remaining > 0 {
+				remaining = append(remaining, l)
+			}
+		}
+		open[key] = remaining
+
+		e.Unmatched = want
+		e.RealizedSOL = e.ProceedsSOL - e.CostBasisSOL
+		e.RealizedUSD = e.ProceedsUSD - e.CostBasisUSD
+	}
+	return nil
+}
+
+// LoadLotSelections reads a specific-ID lot selection file: a JSON object
+// mapping each sell signature to the buy signatures it disposes of.
+func LoadLotSelections(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading lot selections: %w", err)
+	}
+	var sel map[string][]string
+	if err := json.Unmarshal(data, &sel); err != nil {
+		return nil, fmt.Errorf("parsing lot selections: %w", err)
+	}
+	return sel, nil
+}
+
+// LoadAllFills returns the fills of every strategy under root.
+func LoadAllFills(root string) ([]Fill, error) {
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var fills []Fill
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		f, err := LoadFills(root, d.Name())
+		if err != nil {
+			return nil, err
+		}
+		fills = append(fills, f...)
+	}
+	return fills, nil
+}
+
+// journalColumns is the CSV header.
+var journalColumns = []string{
+	"timestamp", "signature", "side", "source", "venue", "wallet",
+	"input_mint", "input_amount", "output_mint", "output_amount",
+	"sol", "sol_usd", "value_usd", "fee_lamports", "priority_fee_lamports",
+	"whale", "whale_wallet", "whale_tx", "onchain",
+	"lot_method", "lots", "cost_basis_sol", "cost_basis_usd",
+	"proceeds_sol", "proceeds_usd", "realized_sol", "realized_usd", "unmatched_amount",
+}
+
+// WriteJournalCSV writes entries as CSV with a header row. USD columns
+// are blank when the SOL price is unknown.
+func WriteJournalCSV(w io.Writer, entries []JournalEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(journalColumns); err != nil {
+		return err
+	}
+
+	sol := func(v float64) string { return strconv.FormatFloat(v, 'f', 9, 64) }
+	usd := func(e JournalEntry, v float64) string {
+		if e.SOLPriceUSD == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	u64 := func(v uint64) string { return strconv.FormatUint(v, 10) }
+
+	for _, e := range entries {
+		row := []string{
+			e.Timestamp.UTC().Format(time.RFC3339), e.Signature, e.Side, e.Source, e.Venue, e.Wallet,
+			e.InputMint, u64(e.InputAmount), e.OutputMint, u64(e.OutputAmount),
+			sol(e.SOL), usd(e, e.SOLPriceUSD), usd(e, e.ValueUSD), u64(e.FeeLamports), u64(e.PriorityFee),
+			e.Whale, e.WhaleWallet, e.WhaleTx, strconv.FormatBool(e.OnChain),
+		}
+		if e.Side == "sell" {
+			lots := make([]string, len(e.Lots))
+			for i, l := range e.Lots {
+				lots[i] = fmt.Sprintf("%s:%d", l.BuySignature, l.Amount)
+			}
+			row = append(row,
+				e.LotMethod, strings.Join(lots, ";"), sol(e.CostBasisSOL), usd(e, e.CostBasisUSD),
+				sol(e.ProceedsSOL), usd(e, e.ProceedsUSD), sol(e.RealizedSOL), usd(e, e.RealizedUSD), u64(e.Unmatched),
+			)
+		} else {
+			row = append(row, "", "", "", "", "", "", "", "", "")
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJournalJSON writes entries as an indented JSON array.
+func WriteJournalJSON(w io.Writer, entries []JournalEntry) error {
+	if entries == nil {
+		entries = []JournalEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// JournalWallets returns the executor wallets that appear in fills or
+// instance state under root, plus extra, deduplicated and sorted.
+func JournalWallets(root string, fills []Fill, extra ...string) ([]string, error) {
+	set := make(map[string]bool)
+	for _, f := range fills {
+		if f.Wallet != "" {
+			set[f.Wallet] = true
+		}
+	}
+	states, err := ListInstanceStates(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range states {
+		if s.Signer != "" {
+			set[s.Signer] = true
+		}
+	}
+	for _, w := range extra {
+		if w != "" {
+			set[w] = true
+		}
+	}
+
+	wallets := make([]string, 0, len(set))
+	for w := range set {
+		wallets = append(wallets, w)
+	}
+	sort.Strings(wallets)
+	return wallets, nil
+}
+
+// FetchWalletSwaps reads the swaps made by each wallet from on-chain
+// history, examining at most maxTx transactions per wallet.
+func FetchWalletSwaps(config *common.Config, wallets []string, maxTx int) ([]copytrade.WalletSwap, error) {
+	client := copytrade.NewHistoryClient(config)
+	var swaps []copytrade.WalletSwap
+	for _, w := range wallets {
+		s, err := client.WalletSwaps(w, time.Time{}, maxTx)
+		if err != nil {
+			return nil, fmt.Errorf("reconciling %s: %w", w, err)
+		}
+		swaps = append(swaps, s...)
+	}
+	return swaps, nil
+}
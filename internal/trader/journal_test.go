@@ -0,0 +1,153 @@
+package trader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/agents/copytrade"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+// journalHistory is two buys of the same token at different prices and
+// a sell of part of it.
+func journalHistory(t0 time.Time) ([]Fill, []copytrade.WalletSwap) {
+	fills := []Fill{
+		{Timestamp: t0, Strategy: "fast", Signature: "buy1", Wallet: "me", TokenMint: "M",
+			InLamports: 1_000_000_000, OutAmount: 100, Whale: "Whale", WhaleWallet: "w1", WhaleTx: "wtx", SOLPriceUSD: 100},
+		{Timestamp: t0.Add(time.Hour), Strategy: "fast", Signature: "dry", TokenMint: "M", DryRun: true},
+	}
+	swaps := []copytrade.WalletSwap{
+		{Signature: "buy1", Timestamp: t0, Wallet: "me", Side: "buy", TokenMint: "M",
+			TokenAmount: 100, SOLLamports: 1_000_000_000, Venue: "jupiter"},
+		{Signature: "buy2", Timestamp: t0.Add(time.Hour), Wallet: "me", Side: "buy", TokenMint: "M",
+			TokenAmount: 100, SOLLamports: 2_000_000_000, Venue: "jupiter"},
+		{Signature: "sell1", Timestamp: t0.Add(2 * time.Hour), Wallet: "me", Side: "sell", TokenMint: "M",
+			TokenAmount: 150, SOLLamports: 3_000_000_000, FeeLamports: 10_000, PriorityFee: 5_000, Venue: "jupiter"},
+	}
+	return fills, swaps
+}
+
+func TestBuildJournalFIFO(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fills, swaps := journalHistory(t0)
+
+	entries, err := BuildJournal(fills, swaps, JournalOptions{SOLPriceUSD: 200})
+	if err != nil {
+		t.Fatalf("BuildJournal: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3 (dry-run excluded)", len(entries))
+	}
+
+	// The fill enriches its on-chain swap; the other buy is manual
+	b1, b2 := entries[0], entries[1]
+	if b1.Source != "fast" || b1.Whale != "Whale" || b1.WhaleTx != "wtx" || !b1.OnChain || b1.SOLPriceUSD != 100 {
+		t.Errorf("buy1 = %+v", b1)
+	}
+	if b2.Source != SourceManual || b2.SOLPriceUSD != 200 {
+		t.Errorf("buy2 source=%s price=%v, want manual at fallback 200", b2.Source, b2.SOLPriceUSD)
+	}
+
+	// FIFO: all 100 of buy1 (1 SOL) + 50 of buy2 (1 SOL)
+	s := entries[2]
+	if s.LotMethod != LotFIFO || len(s.Lots) != 2 || s.Lots[0].BuySignature != "buy1" || s.Lots[1].Amount != 50 {
+		t.Fatalf("lots = %+v", s.Lots)
+	}
+	if !approx(s.CostBasisSOL, 2) || !approx(s.ProceedsSOL, 2.99999) || !approx(s.RealizedSOL, 0.99999) {
+		t.Errorf("cost=%v proceeds=%v realized=%v", s.CostBasisSOL, s.ProceedsSOL, s.RealizedSOL)
+	}
+	// USD cost uses each lot's own price: 1 SOL @ 100 + 1 SOL @ 200
+	if !approx(s.CostBasisUSD, 300) || !approx(s.RealizedUSD, 2.99999*200-300) {
+		t.Errorf("cost usd=%v realized usd=%v", s.CostBasisUSD, s.RealizedUSD)
+	}
+	if s.FeeLamports != 10_000 || s.PriorityFee != 5_000 {
+		t.Errorf("fees = %d/%d", s.FeeLamports, s.PriorityFee)
+	}
+}
+
+func TestBuildJournalSpecificID(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fills, swaps := journalHistory(t0)
+
+	entries, err := BuildJournal(fills, swaps, JournalOptions{
+		Method:       LotSpecific,
+		SpecificLots: map[string][]string{"sell1": {"buy2"}},
+	})
+	if err != nil {
+		t.Fatalf("BuildJournal: %v", err)
+	}
+
+	// All of buy2 (2 SOL), then FIFO for the remaining 50 from buy1 (0.5 SOL)
+	s := entries[2]
+	if s.LotMethod != LotSpecific || len(s.Lots) != 2 || s.Lots[0].BuySignature != "buy2" || s.Lots[1].BuySignature != "buy1" {
+		t.Fatalf("lots = %+v", s.Lots)
+	}
+	if !approx(s.CostBasisSOL, 2.5) {
+		t.Errorf("cost basis = %v, want 2.5", s.CostBasisSOL)
+	}
+
+	if _, err := BuildJournal(fills, swaps, JournalOptions{
+		Method:       LotSpecific,
+		SpecificLots: map[string][]string{"sell1": {"nope"}},
+	}); err == nil {
+		t.Error("expected error for unknown lot")
+	}
+}
+
+func TestBuildJournalDateRange(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fills, swaps := journalHistory(t0)
+
+	// Only the sell is in range, but its lots still come from earlier buys
+	entries, err := BuildJournal(fills, swaps, JournalOptions{From: t0.Add(90 * time.Minute), To: t0.Add(3 * time.Hour)})
+	if err != nil {
+		t.Fatalf("BuildJournal: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Signature != "sell1" || len(entries[0].Lots) != 2 {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
+
+func TestWriteJournalCSV(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fills, swaps := journalHistory(t0)
+	entries, err := BuildJournal(fills, swaps, JournalOptions{})
+	if err != nil {
+		t.Fatalf("BuildJournal: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJournalCSV(&buf, entries); err != nil {
+		t.Fatalf("WriteJournalCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(rows) != 4 || len(rows[0]) != len(journalColumns) {
+		t.Fatalf("got %d rows of %d columns", len(rows), len(rows[0]))
+	}
+
+	col := func(row []string, name string) string {
+		for i, c := range journalColumns {
+			if c == name {
+				return row[i]
+			}
+		}
+		t.Fatalf("no column %s", name)
+		return ""
+	}
+	if got := col(rows[1], "sol_usd"); got != "100.00" {
+		t.Errorf("buy1 sol_usd = %q, want 100.00", got)
+	}
+	if got := col(rows[2], "value_usd"); got != "" {
+		t.Errorf("manual buy without a price has value_usd %q, want blank", got)
+	}
+	if got := col(rows[3], "lots"); got != "buy1:100;buy2:50" {
+		t.Errorf("lots = %q", got)
+	}
+}
@@ -4,12 +4,10 @@ package trader
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
-	// Public Key: (derived from private key)
-	// (loaded from config)
-
 	"github.com/gagliardetto/solana-go"
 	"github.com/speaker20/whaletown/internal/agents/common"
 	"github.com/speaker20/whaletown/internal/agents/copytrade"
@@ -22,6 +20,7 @@ type AgentType string
 const (
 	AgentTypeCopyTrade  AgentType = "copytrade"
 	AgentTypeResearcher AgentType = "researcher"
+	AgentTypeStrategy   AgentType = "strategy" // Named instance from the strategies file
 )
 
 // AgentStatus represents the status of a trading agent.
@@ -33,6 +32,35 @@ type AgentStatus struct {
 	Trades    int       `json:"trades,omitempty"`  // Number of trades tracked
 	Signals   int       `json:"signals,omitempty"` // Number of signals generated
 	Wallets   int       `json:"wallets,omitempty"` // Number of wallets tracked
+
+	// Strategy instance stats
+	Executions int     `json:"executions,omitempty"`
+	Skipped    int     `json:"skipped,omitempty"`  // Blocked by risk limits
+	Failures   int     `json:"failures,omitempty"` // Failed executions
+	SpentSOL   float64 `json:"spent_sol,omitempty"`
+	ValueSOL   float64 `json:"value_sol,omitempty"` // Marked value of open positions
+	PnLSOL     float64 `json:"pnl_sol,omitempty"`   // Unrealized P&L of open positions
+	DryRun     bool    `json:"dry_run,omitempty"`
+}
+
+// StatusFromState converts a strategy instance's state to an AgentStatus.
+func StatusFromState(s *InstanceState) AgentStatus {
+	return AgentStatus{
+		Name:       s.Name,
+		Type:       AgentTypeStrategy,
+		Running:    s.IsRunning(),
+		StartedAt:  s.StartedAt,
+		Trades:     s.Alerts,
+		Signals:    s.Signals,
+		Wallets:    s.Wallets,
+		Executions: s.Executions,
+		Skipped:    s.Skipped,
+		Failures:   s.Failures,
+		SpentSOL:   s.SpentSOL(),
+		ValueSOL:   s.ValueSOL(),
+		PnLSOL:     s.PnLSOL(),
+		DryRun:     s.DryRun,
+	}
 }
 
 // Manager manages trading agent lifecycles.
@@ -41,6 +69,7 @@ type Manager struct {
 	agents     map[string]*runningAgent
 	config     *common.Config
 	researcher *researcher.Researcher
+	stateDir   string // Per-instance state for strategies
 
 	// Callback for real-time trades
 	OnTrade func(common.Trade)
@@ -53,14 +82,81 @@ type runningAgent struct {
 	polyTracker *copytrade.PolymarketTracker
 	wsListener  *copytrade.WebSocketListener
 	executor    *copytrade.Executor
+	instance    *Instance
 }
 
 // NewManager creates a new trading agent manager.
 func NewManager() *Manager {
 	return &Manager{
-		agents: make(map[string]*runningAgent),
-		config: common.DefaultConfig(),
+		agents:   make(map[string]*runningAgent),
+		config:   common.DefaultConfig(),
+		stateDir: TraderDir(),
+	}
+}
+
+// StartStrategy starts a named strategy instance. Instances run
+// concurrently, each with its own wallets, sizing, risk limits and signer.
+func (m *Manager) StartStrategy(cfg StrategyConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.agents[cfg.Name]; exists {
+		return fmt.Errorf("agent %s is already running", cfg.Name)
 	}
+
+	inst, err := NewInstance(cfg, m.config, m.stateDir)
+	if err != nil {
+		return err
+	}
+	inst.OnTrade = func(trade common.Trade) {
+		m.mu.RLock()
+		cb := m.OnTrade
+		m.mu.RUnlock()
+		if cb != nil {
+			cb(trade)
+		}
+	}
+
+	status := StatusFromState(&InstanceState{Name: cfg.Name})
+	status.Running = true
+	status.StartedAt = time.Now()
+	m.agents[cfg.Name] = &runningAgent{
+		status:   status,
+		stopCh:   make(chan struct{}),
+		instance: inst,
+	}
+	inst.Start()
+	return nil
+}
+
+// StartStrategies starts the named strategies from the strategies file,
+// or all of them if names is empty.
+func (m *Manager) StartStrategies(path string, names ...string) ([]string, error) {
+	strategies, err := LoadStrategies(path)
+	if err != nil {
+		return nil, err
+	}
+
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+
+	var started []string
+	for _, cfg := range strategies {
+		if len(names) > 0 && !want[cfg.Name] {
+			continue
+		}
+		delete(want, cfg.Name)
+		if err := m.StartStrategy(cfg); err != nil {
+			return started, err
+		}
+		started = append(started, cfg.Name)
+	}
+	for n := range want {
+		return started, fmt.Errorf("strategy %q not found in %s", n, path)
+	}
+	return started, nil
 }
 
 // Start starts a trading agent.
@@ -123,6 +219,24 @@ func (m *Manager) Start(agentType AgentType) error {
 								return
 							}
 
+							fill := Fill{
+								Timestamp:   time.Now(),
+								Strategy:    name,
+								Signature:   result.TxHash,
+								Wallet:      agent.executor.PublicKey(),
+								TokenMint:   result.TokenMint,
+								InLamports:  copytrade.DefaultBuyLamports,
+								Whale:       trade.WalletAlias,
+								WhaleWallet: trade.Wallet,
+								WhaleTx:     trade.TxHash,
+							}
+							if price, err := agent.executor.SOLPriceUSD(); err == nil {
+								fill.SOLPriceUSD = price
+							}
+							if err := AppendFill(m.stateDir, fill); err != nil {
+								fmt.Printf("⚠️  Failed to record fill: %v\n", err)
+							}
+
 							// Emit executed trade to dashboard
 							if cb != nil && result != nil {
 								execTrade := common.Trade{
@@ -144,6 +258,10 @@ func (m *Manager) Start(agentType AgentType) error {
 					cb(trade)
 				}
 			}
+			// Backfill transactions missed while the socket was down
+			listener.OnReconnect = func() {
+				m.fetchTrades(agent)
+			}
 			agent.wsListener = listener
 			// Start listener in background
 			go func() {
@@ -157,6 +275,9 @@ func (m *Manager) Start(agentType AgentType) error {
 
 	case AgentTypeResearcher:
 		m.researcher = researcher.NewResearcher(5 * time.Minute)
+		if m.config.HeliusAPIKey != "" {
+			m.researcher.Activity = researcher.NewHeliusActivity(m.config)
+		}
 		m.researcher.OnUpdate = func(wl *researcher.Watchlist) {
 			m.mu.Lock()
 			if a, ok := m.agents["researcher"]; ok {
@@ -175,6 +296,15 @@ func (m *Manager) Start(agentType AgentType) error {
 	return nil
 }
 
+// loadWatchlist loads the researcher watchlist, or nil if there is none.
+func loadWatchlist() *researcher.Watchlist {
+	wl, err := researcher.LoadWatchlist()
+	if err != nil {
+		return nil
+	}
+	return wl
+}
+
 // loadWallets loads wallets from watchlist or falls back to defaults.
 func (m *Manager) loadWallets() []common.TrackedWallet {
 	wl, err := researcher.LoadWatchlist()
@@ -198,6 +328,13 @@ func (m *Manager) Stop(name string) error {
 	}
 
 	close(agent.stopCh)
+	if agent.instance != nil {
+		agent.instance.Stop()
+	}
+	if m.researcher != nil && agent.status.Type == AgentTypeResearcher {
+		m.researcher.Stop()
+		m.researcher = nil
+	}
 	delete(m.agents, name)
 	return nil
 }
@@ -209,8 +346,16 @@ func (m *Manager) List() []AgentStatus {
 
 	result := make([]AgentStatus, 0, len(m.agents))
 	for _, agent := range m.agents {
+		if agent.instance != nil {
+			state := agent.instance.Status()
+			status := StatusFromState(&state)
+			status.Running = true
+			result = append(result, status)
+			continue
+		}
 		result = append(result, agent.status)
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
 	return result
 }
 
@@ -240,15 +385,16 @@ func (m *Manager) runCopyTradeLoop(agent *runningAgent) {
 	}
 }
 
-// fetchTrades fetches trades from trackers.
+// fetchTrades polls the tracker for transactions newer than each wallet's
+// cursor and updates the trade count.
 func (m *Manager) fetchTrades(agent *runningAgent) {
 	if agent.tracker != nil {
-		trades, err := agent.tracker.FetchRecentTrades()
-		if err == nil {
-			m.mu.Lock()
-			agent.status.Trades = len(trades)
-			m.mu.Unlock()
+		if _, err := agent.tracker.Poll(); err != nil {
+			fmt.Printf("⚠️  Trade poll failed: %v\n", err)
 		}
+		m.mu.Lock()
+		agent.status.Trades = len(agent.tracker.Trades())
+		m.mu.Unlock()
 	}
 }
 
@@ -0,0 +1,222 @@
+package trader
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/util"
+)
+
+// lamportsPerSOL converts between SOL and lamports.
+const lamportsPerSOL = 1_000_000_000
+
+// InstanceState is the persisted runtime state of a strategy instance.
+// It is written by the process running the instance and read by
+// `wt trader list` from any process.
+type InstanceState struct {
+	Name      string    `json:"name"`
+	Signal    string    `json:"signal"`
+	Wallets   int       `json:"wallets"`
+	Signer    string    `json:"signer,omitempty"` // Executor wallet address
+	DryRun    bool      `json:"dry_run,omitempty"`
+	PID       int       `json:"pid"`
+	Running   bool      `json:"running"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Alerts     int `json:"alerts"`     // Whale transactions seen
+	Signals    int `json:"signals"`    // Buys that passed the signal filter
+	Executions int `json:"executions"` // Buys submitted
+	Skipped    int `json:"skipped"`    // Signals blocked by risk limits
+	Failures   int `json:"failures"`   // Buys that errored
+
+	SpentLamports uint64 `json:"spent_lamports"`
+	DailyDate     string `json:"daily_date"` // UTC date of DailyLamports
+	DailyLamports uint64 `json:"daily_lamports"`
+
+	Positions map[string]*Position `json:"positions"`
+}
+
+// Position is the holding in one token bought by an instance.
+type Position struct {
+	Mint          string    `json:"mint"`
+	Amount        uint64    `json:"amount"`        // Token base units
+	CostLamports  uint64    `json:"cost_lamports"` // SOL spent acquiring Amount
+	ValueLamports uint64    `json:"value_lamports"`
+	ValuedAt      time.Time `json:"valued_at,omitempty"`
+}
+
+// Fill records one executed (or dry-run) copy buy.
+type Fill struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Strategy    string    `json:"strategy"`
+	Signature   string    `json:"signature"`
+	Wallet      string    `json:"wallet,omitempty"` // Executor wallet that signed
+	TokenMint   string    `json:"token_mint"`
+	InLamports  uint64    `json:"in_lamports"`
+	OutAmount   uint64    `json:"out_amount"`
+	Whale       string    `json:"whale"`             // Alias of the wallet that triggered the buy
+	WhaleWallet string    `json:"whale_wallet"`      // Address of that wallet
+	WhaleTx     string    `json:"whale_tx"`          // The whale's transaction
+	SOLPriceUSD float64   `json:"sol_usd,omitempty"` // SOL/USD at fill time
+	DryRun      bool      `json:"dry_run,omitempty"`
+}
+
+// SpentSOL returns total SOL spent.
+func (s *InstanceState) SpentSOL() float64 {
+	return float64(s.SpentLamports) / lamportsPerSOL
+}
+
+// CostSOL returns the cost basis of open positions in SOL.
+func (s *InstanceState) CostSOL() float64 {
+	var total uint64
+	for _, p := range s.Positions {
+		total += p.CostLamports
+	}
+	return float64(total) / lamportsPerSOL
+}
+
+// ValueSOL returns the last marked value of open positions in SOL.
+func (s *InstanceState) ValueSOL() float64 {
+	var total uint64
+	for _, p := range s.Positions {
+		total += p.ValueLamports
+	}
+	return float64(total) / lamportsPerSOL
+}
+
+// PnLSOL returns unrealized profit and loss of open positions in SOL.
+func (s *InstanceState) PnLSOL() float64 {
+	return s.ValueSOL() - s.CostSOL()
+}
+
+// IsRunning reports whether the process that owns the instance is alive.
+func (s *InstanceState) IsRunning() bool {
+	return s.Running && processExists(s.PID)
+}
+
+// TraderDir returns the directory holding per-instance trader state.
+func TraderDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".whaletown", "trader")
+	}
+	return filepath.Join(home, ".whaletown", "trader")
+}
+
+// instanceDir returns the state directory for a strategy instance.
+func instanceDir(root, name string) string {
+	return filepath.Join(root, name)
+}
+
+// LoadInstanceState loads the persisted state of an instance.
+func LoadInstanceState(root, name string) (*InstanceState, error) {
+	data, err := os.ReadFile(filepath.Join(instanceDir(root, name), "state.json"))
+	if err != nil {
+		return nil, err
+	}
+	var s InstanceState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing state for %s: %w", name, err)
+	}
+	if s.Positions == nil {
+		s.Positions = make(map[string]*Position)
+	}
+	return &s, nil
+}
+
+// SaveInstanceState writes the state of an instance atomically.
+func SaveInstanceState(root string, s *InstanceState) error {
+	dir := instanceDir(root, s.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(filepath.Join(dir, "state.json"), s)
+}
+
+// ListInstanceStates returns the persisted state of every instance under
+// root, sorted by name.
+func ListInstanceStates(root string) ([]*InstanceState, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var states []*InstanceState
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := LoadInstanceState(root, e.Name())
+		if err != nil {
+			continue // No state yet or unreadable
+		}
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states, nil
+}
+
+// AppendFill appends a fill to the instance's fill log (fills.jsonl).
+func AppendFill(root string, f Fill) error {
+	dir := instanceDir(root, f.Strategy)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, "fills.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: fills are not secret
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// LoadFills reads the fill log of an instance.
+func LoadFills(root, name string) ([]Fill, error) {
+	file, err := os.Open(filepath.Join(instanceDir(root, name), "fills.jsonl"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var fills []Fill
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var f Fill
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			continue // Skip malformed lines
+		}
+		fills = append(fills, f)
+	}
+	return fills, scanner.Err()
+}
+
+// processExists checks if a process with the given PID exists and is alive.
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,287 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/speaker20/whaletown/internal/agents/common"
+	"github.com/speaker20/whaletown/internal/agents/researcher"
+)
+
+// Signal modes for a strategy.
+const (
+	// SignalFollow copies every buy by any followed wallet.
+	SignalFollow = "follow"
+
+	// SignalConsensus buys only once several followed wallets have bought
+	// the same token within a time window.
+	SignalConsensus = "consensus"
+)
+
+// DefaultSizeSOL is the buy size used when a strategy doesn't set one
+// (~$1 @ $200/SOL).
+const DefaultSizeSOL = 0.005
+
+// DefaultSignerEnv is the environment variable holding the signer key
+// when a strategy doesn't name one.
+const DefaultSignerEnv = "SOLANA_PRIVATE_KEY"
+
+// StrategiesFile is the on-disk list of named strategy instances.
+type StrategiesFile struct {
+	Strategies []StrategyConfig `json:"strategies"`
+}
+
+// StrategyConfig defines one named strategy instance. Each instance has
+// its own wallet list, sizing, risk limits and signer, and runs
+// independently of the others.
+type StrategyConfig struct {
+	// Name identifies the instance (e.g., "memecoin-fast").
+	Name string `json:"name"`
+
+	// Wallets are explicit wallet addresses to follow.
+	Wallets []string `json:"wallets,omitempty"`
+
+	// TopWallets follows the N highest-scored Solana wallets from the
+	// researcher watchlist, in addition to Wallets.
+	TopWallets int `json:"top_wallets,omitempty"`
+
+	// SizeSOL is the amount of SOL spent per copy buy.
+	SizeSOL float64 `json:"size_sol,omitempty"`
+
+	// SlippageBps is the maximum slippage for swaps (default 50).
+	SlippageBps int `json:"slippage_bps,omitempty"`
+
+	// Signal is "follow" (default) or "consensus".
+	Signal string `json:"signal,omitempty"`
+
+	// Consensus configures the consensus signal mode.
+	Consensus *ConsensusConfig `json:"consensus,omitempty"`
+
+	// Risk limits spending for this instance.
+	Risk RiskLimits `json:"risk"`
+
+	// SignerEnv names the environment variable holding this instance's
+	// base58 private key (default SOLANA_PRIVATE_KEY).
+	SignerEnv string `json:"signer_env,omitempty"`
+
+	// SignerKeyFile is a file containing the base58 private key.
+	// Takes precedence over SignerEnv.
+	SignerKeyFile string `json:"signer_key_file,omitempty"`
+
+	// DryRun detects signals and applies risk limits without sending
+	// transactions.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// ConsensusConfig configures the consensus signal mode.
+type ConsensusConfig struct {
+	// MinWallets is how many distinct followed wallets must buy a token.
+	MinWallets int `json:"min_wallets"`
+
+	// Window is how close together the buys must be (e.g., "10m").
+	Window string `json:"window"`
+}
+
+// RiskLimits bound what a strategy instance may spend. Zero means no limit.
+type RiskLimits struct {
+	// MaxDailySOL caps total SOL spent per UTC day.
+	MaxDailySOL float64 `json:"max_daily_sol,omitempty"`
+
+	// MaxTokenSOL caps total SOL spent on any single token.
+	MaxTokenSOL float64 `json:"max_token_sol,omitempty"`
+
+	// MaxOpenPositions caps the number of distinct tokens held.
+	MaxOpenPositions int `json:"max_open_positions,omitempty"`
+}
+
+var strategyNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// StrategiesPath returns the path to the strategies config file.
+func StrategiesPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".whaletown/strategies.json"
+	}
+	return filepath.Join(home, ".whaletown", "strategies.json")
+}
+
+// LoadStrategies loads and validates the strategies file.
+// A missing file yields an empty list.
+func LoadStrategies(path string) ([]StrategyConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading strategies: %w", err)
+	}
+
+	var f StrategiesFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing strategies: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range f.Strategies {
+		s := &f.Strategies[i]
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("duplicate strategy name %q", s.Name)
+		}
+		seen[s.Name] = true
+	}
+	return f.Strategies, nil
+}
+
+// FindStrategy returns the named strategy from the strategies file.
+func FindStrategy(path, name string) (*StrategyConfig, error) {
+	strategies, err := LoadStrategies(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range strategies {
+		if strategies[i].Name == name {
+			return &strategies[i], nil
+		}
+	}
+	return nil, fmt.Errorf("strategy %q not found in %s", name, path)
+}
+
+// Validate checks the strategy config and fills in defaults.
+func (s *StrategyConfig) Validate() error {
+	if !strategyNameRe.MatchString(s.Name) {
+		return fmt.Errorf("invalid strategy name %q (use lowercase letters, digits, - and _)", s.Name)
+	}
+	if AgentType(s.Name) == AgentTypeCopyTrade || AgentType(s.Name) == AgentTypeResearcher {
+		return fmt.Errorf("strategy %q: name is reserved for a built-in agent", s.Name)
+	}
+	if len(s.Wallets) == 0 && s.TopWallets <= 0 {
+		return fmt.Errorf("strategy %q: set wallets or top_wallets", s.Name)
+	}
+	if s.SizeSOL < 0 {
+		return fmt.Errorf("strategy %q: size_sol must be positive", s.Name)
+	}
+	if s.SizeSOL == 0 {
+		s.SizeSOL = DefaultSizeSOL
+	}
+	if s.SlippageBps == 0 {
+		s.SlippageBps = 50
+	}
+	if s.SignerEnv == "" {
+		s.SignerEnv = DefaultSignerEnv
+	}
+
+	switch s.Signal {
+	case "":
+		s.Signal = SignalFollow
+	case SignalFollow:
+	case SignalConsensus:
+		if s.Consensus == nil || s.Consensus.MinWallets < 2 {
+			return fmt.Errorf("strategy %q: consensus.min_wallets must be at least 2", s.Name)
+		}
+		if _, err := s.ConsensusWindow(); err != nil {
+			return fmt.Errorf("strategy %q: %w", s.Name, err)
+		}
+	default:
+		return fmt.Errorf("strategy %q: unknown signal %q (want follow or consensus)", s.Name, s.Signal)
+	}
+	return nil
+}
+
+// ConsensusWindow returns the parsed consensus window (default 10m).
+func (s *StrategyConfig) ConsensusWindow() (time.Duration, error) {
+	if s.Consensus == nil || s.Consensus.Window == "" {
+		return 10 * time.Minute, nil
+	}
+	d, err := time.ParseDuration(s.Consensus.Window)
+	if err != nil {
+		return 0, fmt.Errorf("invalid consensus window %q: %w", s.Consensus.Window, err)
+	}
+	return d, nil
+}
+
+// SizeLamports returns the per-buy size in lamports.
+func (s *StrategyConfig) SizeLamports() uint64 {
+	return uint64(s.SizeSOL * lamportsPerSOL)
+}
+
+// SignerKey returns the base58 private key for this instance.
+func (s *StrategyConfig) SignerKey() (string, error) {
+	if s.SignerKeyFile != "" {
+		data, err := os.ReadFile(s.SignerKeyFile) //nolint:gosec // G304: path is user config
+		if err != nil {
+			return "", fmt.Errorf("reading signer key file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	key := os.Getenv(s.SignerEnv)
+	if key == "" {
+		return "", fmt.Errorf("%s is not set", s.SignerEnv)
+	}
+	return key, nil
+}
+
+// ResolveWallets returns the wallets this instance follows: the explicit
+// addresses plus the top-scored Solana wallets from the watchlist.
+func (s *StrategyConfig) ResolveWallets(wl *researcher.Watchlist) []common.TrackedWallet {
+	known := make(map[string]common.TrackedWallet)
+	for _, w := range common.DefaultTrackedWallets() {
+		known[w.Address] = w
+	}
+
+	var entries []researcher.WalletEntry
+	if wl != nil {
+		for _, w := range wl.Wallets {
+			known[w.Address] = common.TrackedWallet{Address: w.Address, Alias: w.Alias, Platform: w.Platform}
+			if w.Platform == "solana" {
+				entries = append(entries, w)
+			}
+		}
+	}
+
+	result := []common.TrackedWallet{}
+	seen := make(map[string]bool)
+	add := func(w common.TrackedWallet) {
+		if seen[w.Address] {
+			return
+		}
+		seen[w.Address] = true
+		result = append(result, w)
+	}
+
+	for _, addr := range s.Wallets {
+		w, ok := known[addr]
+		if !ok {
+			w = common.TrackedWallet{Address: addr, Alias: shortAddr(addr), Platform: "solana"}
+		}
+		w.Platform = "solana"
+		add(w)
+	}
+
+	if s.TopWallets > 0 {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Score > entries[j].Score
+		})
+		for i := 0; i < len(entries) && i < s.TopWallets; i++ {
+			add(known[entries[i].Address])
+		}
+	}
+
+	return result
+}
+
+// shortAddr returns a shortened address for display.
+func shortAddr(addr string) string {
+	if len(addr) <= 8 {
+		return addr
+	}
+	return addr[:4] + "..." + addr[len(addr)-4:]
+}
@@ -0,0 +1,119 @@
+package trader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/speaker20/whaletown/internal/agents/researcher"
+)
+
+func writeStrategies(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "strategies.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestLoadStrategies(t *testing.T) {
+	path := writeStrategies(t, `{
+  "strategies": [
+    {"name": "memecoin-fast", "wallets": ["a1", "a2", "a3"], "size_sol": 0.01},
+    {"name": "conservative", "top_wallets": 10, "signal": "consensus",
+     "consensus": {"min_wallets": 3, "window": "15m"}, "signer_env": "CONSERVATIVE_KEY"}
+  ]
+}`)
+
+	strategies, err := LoadStrategies(path)
+	if err != nil {
+		t.Fatalf("LoadStrategies: %v", err)
+	}
+	if len(strategies) != 2 {
+		t.Fatalf("got %d strategies, want 2", len(strategies))
+	}
+
+	fast := strategies[0]
+	if fast.Signal != SignalFollow {
+		t.Errorf("default signal = %q, want %q", fast.Signal, SignalFollow)
+	}
+	if fast.SizeLamports() != 10_000_000 {
+		t.Errorf("SizeLamports = %d, want 10000000", fast.SizeLamports())
+	}
+	if fast.SignerEnv != DefaultSignerEnv {
+		t.Errorf("SignerEnv = %q, want %q", fast.SignerEnv, DefaultSignerEnv)
+	}
+
+	cons := strategies[1]
+	if cons.SizeSOL != DefaultSizeSOL {
+		t.Errorf("default SizeSOL = %v, want %v", cons.SizeSOL, DefaultSizeSOL)
+	}
+	if w, _ := cons.ConsensusWindow(); w.Minutes() != 15 {
+		t.Errorf("ConsensusWindow = %v, want 15m", w)
+	}
+}
+
+func TestLoadStrategiesMissingFile(t *testing.T) {
+	strategies, err := LoadStrategies(filepath.Join(t.TempDir(), "nope.json"))
+	if err != nil {
+		t.Fatalf("LoadStrategies: %v", err)
+	}
+	if len(strategies) != 0 {
+		t.Errorf("got %d strategies, want 0", len(strategies))
+	}
+}
+
+func TestLoadStrategiesInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"duplicate", `{"strategies": [{"name": "a", "wallets": ["w"]}, {"name": "a", "wallets": ["w"]}]}`, "duplicate"},
+		{"no wallets", `{"strategies": [{"name": "a"}]}`, "wallets or top_wallets"},
+		{"bad name", `{"strategies": [{"name": "Bad Name", "wallets": ["w"]}]}`, "invalid strategy name"},
+		{"reserved", `{"strategies": [{"name": "copytrade", "wallets": ["w"]}]}`, "reserved"},
+		{"bad signal", `{"strategies": [{"name": "a", "wallets": ["w"], "signal": "vibes"}]}`, "unknown signal"},
+		{"consensus min", `{"strategies": [{"name": "a", "wallets": ["w"], "signal": "consensus", "consensus": {"min_wallets": 1}}]}`, "min_wallets"},
+		{"consensus window", `{"strategies": [{"name": "a", "wallets": ["w"], "signal": "consensus", "consensus": {"min_wallets": 2, "window": "soon"}}]}`, "window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadStrategies(writeStrategies(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveWallets(t *testing.T) {
+	wl := &researcher.Watchlist{Wallets: []researcher.WalletEntry{
+		{Address: "low", Alias: "Low", Score: 10, Platform: "solana"},
+		{Address: "high", Alias: "High", Score: 90, Platform: "solana"},
+		{Address: "poly", Alias: "Poly", Score: 99, Platform: "polymarket"},
+		{Address: "mid", Alias: "Mid", Score: 50, Platform: "solana"},
+	}}
+
+	cfg := StrategyConfig{Wallets: []string{"mid", "explicit"}, TopWallets: 2}
+	wallets := cfg.ResolveWallets(wl)
+
+	var got []string
+	for _, w := range wallets {
+		got = append(got, w.Address)
+		if w.Platform != "solana" {
+			t.Errorf("wallet %s platform = %q, want solana", w.Address, w.Platform)
+		}
+	}
+	// Explicit first, then top-scored Solana wallets without duplicates
+	want := "mid,explicit,high"
+	if strings.Join(got, ",") != want {
+		t.Errorf("wallets = %v, want %s", got, want)
+	}
+	if wallets[0].Alias != "Mid" {
+		t.Errorf("alias = %q, want alias from watchlist", wallets[0].Alias)
+	}
+}
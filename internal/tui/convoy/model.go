@@ -6,19 +6,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
-	"path/filepath"
-	"regexp"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-)
 
-// convoyIDPattern validates convoy IDs to prevent SQL injection.
-var convoyIDPattern = regexp.MustCompile(`^hq-[a-zA-Z0-9-]+$`)
+	"github.com/speaker20/whaletown/internal/beadsdb"
+)
 
 // subprocessTimeout is the timeout for bd and sqlite3 calls.
 const subprocessTimeout = 5 * time.Second
@@ -87,35 +83,32 @@ func loadConvoys(townBeads string) ([]ConvoyItem, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), subprocessTimeout)
 	defer cancel()
 
-	// Get list of open convoys
-	listArgs := []string{"list", "--type=convoy", "--json"}
-	listCmd := exec.CommandContext(ctx, "bd", listArgs...)
-	listCmd.Dir = townBeads
-	var stdout bytes.Buffer
-	listCmd.Stdout = &stdout
-
-	if err := listCmd.Run(); err != nil {
-		return nil, fmt.Errorf("listing convoys: %w", err)
+	rawConvoys, err := listConvoys(ctx, townBeads)
+	if err != nil {
+		return nil, err
 	}
 
-	var rawConvoys []struct {
-		ID     string `json:"id"`
-		Title  string `json:"title"`
-		Status string `json:"status"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &rawConvoys); err != nil {
-		return nil, fmt.Errorf("parsing convoy list: %w", err)
+	ids := make([]string, len(rawConvoys))
+	for i, rc := range rawConvoys {
+		ids[i] = rc.ID
 	}
+	tracked := loadTrackedIssues(ctx, townBeads, ids)
 
 	convoys := make([]ConvoyItem, 0, len(rawConvoys))
 	for _, rc := range rawConvoys {
-		issues, completed, total := loadTrackedIssues(townBeads, rc.ID)
+		issues := tracked[rc.ID]
+		completed := 0
+		for _, issue := range issues {
+			if issue.Status == "closed" {
+				completed++
+			}
+		}
 		convoys = append(convoys, ConvoyItem{
 			ID:       rc.ID,
 			Title:    rc.Title,
 			Status:   rc.Status,
 			Issues:   issues,
-			Progress: fmt.Sprintf("%d/%d", completed, total),
+			Progress: fmt.Sprintf("%d/%d", completed, len(issues)),
 			Expanded: false,
 		})
 	}
@@ -123,89 +116,86 @@ func loadConvoys(townBeads string) ([]ConvoyItem, error) {
 	return convoys, nil
 }
 
-// loadTrackedIssues loads issues tracked by a convoy.
-func loadTrackedIssues(townBeads, convoyID string) ([]IssueItem, int, int) {
-	// Validate convoy ID to prevent SQL injection
-	if !convoyIDPattern.MatchString(convoyID) {
-		return nil, 0, 0
+// listConvoys returns the convoys that aren't closed, read from the town
+// database, or from bd when the database can't be queried directly.
+func listConvoys(ctx context.Context, townBeads string) ([]IssueItem, error) {
+	if beadsdb.Available() {
+		if rows, err := beadsdb.Open(townBeads).Convoys(ctx, ""); err == nil {
+			convoys := make([]IssueItem, len(rows))
+			for i, c := range rows {
+				convoys[i] = IssueItem{ID: c.ID, Title: c.Title, Status: c.Status}
+			}
+			return convoys, nil
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), subprocessTimeout)
-	defer cancel()
-
-	dbPath := filepath.Join(townBeads, "beads.db")
-
-	// Query tracked issues from SQLite (ID validated above)
-	query := fmt.Sprintf(`
-		SELECT d.depends_on_id
-		FROM dependencies d
-		WHERE d.issue_id = '%s' AND d.type = 'tracks'
-	`, convoyID)
-
-	cmd := exec.CommandContext(ctx, "sqlite3", "-json", dbPath, query) //nolint:gosec // G204: sqlite3 with controlled query
+	listCmd := exec.CommandContext(ctx, "bd", "list", "--type=convoy", "--json")
+	listCmd.Dir = townBeads
 	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
+	listCmd.Stdout = &stdout
 
-	if err := cmd.Run(); err != nil {
-		return nil, 0, 0
+	if err := listCmd.Run(); err != nil {
+		return nil, fmt.Errorf("listing convoys: %w", err)
 	}
 
-	var deps []struct {
-		DependsOnID string `json:"depends_on_id"`
+	var convoys []IssueItem
+	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+		return nil, fmt.Errorf("parsing convoy list: %w", err)
 	}
-	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
-		return nil, 0, 0
+	return convoys, nil
+}
+
+// loadTrackedIssues loads the issues tracked by each convoy with one
+// query, plus one bd show for cross-rig issues the town database doesn't
+// hold. Issues are sorted open first, then closed.
+func loadTrackedIssues(ctx context.Context, townBeads string, convoyIDs []string) map[string][]IssueItem {
+	tracked, err := beadsdb.Open(townBeads).TrackedIssues(ctx, convoyIDs)
+	if err != nil {
+		return nil
 	}
 
-	// Collect issue IDs, handling external references
-	issueIDs := make([]string, 0, len(deps))
-	for _, dep := range deps {
-		issueID := dep.DependsOnID
-		if strings.HasPrefix(issueID, "external:") {
-			parts := strings.SplitN(issueID, ":", 3)
-			if len(parts) == 3 {
-				issueID = parts[2]
+	var missing []string
+	for _, ts := range tracked {
+		for _, t := range ts {
+			if t.Issue == nil {
+				missing = append(missing, t.ID)
 			}
 		}
-		issueIDs = append(issueIDs, issueID)
 	}
-
-	// Batch fetch all issue details in one call
-	detailsMap := getIssueDetailsBatch(townBeads, issueIDs)
-
-	issues := make([]IssueItem, 0, len(deps))
-	completed := 0
-	for _, id := range issueIDs {
-		if issue, ok := detailsMap[id]; ok {
-			issues = append(issues, issue)
-			if issue.Status == "closed" {
-				completed++
+	external := getIssueDetailsBatch(ctx, townBeads, missing)
+
+	result := make(map[string][]IssueItem, len(tracked))
+	for convoyID, ts := range tracked {
+		issues := make([]IssueItem, 0, len(ts))
+		for _, t := range ts {
+			if t.Issue != nil {
+				issues = append(issues, IssueItem{ID: t.ID, Title: t.Issue.Title, Status: t.Issue.Status})
+			} else if issue, ok := external[t.ID]; ok {
+				issues = append(issues, issue)
 			}
 		}
-	}
 
-	// Sort by status (open first, then closed)
-	sort.Slice(issues, func(i, j int) bool {
-		if issues[i].Status == issues[j].Status {
-			return issues[i].ID < issues[j].ID
-		}
-		return issues[i].Status != "closed" // open comes first
-	})
+		// Sort by status (open first, then closed)
+		sort.Slice(issues, func(i, j int) bool {
+			if issues[i].Status == issues[j].Status {
+				return issues[i].ID < issues[j].ID
+			}
+			return issues[i].Status != "closed" // open comes first
+		})
+		result[convoyID] = issues
+	}
 
-	return issues, completed, len(issues)
+	return result
 }
 
 // getIssueDetailsBatch fetches details for multiple issues in a single bd show call.
 // Returns a map from issue ID to details.
-func getIssueDetailsBatch(townBeads string, issueIDs []string) map[string]IssueItem {
+func getIssueDetailsBatch(ctx context.Context, townBeads string, issueIDs []string) map[string]IssueItem {
 	result := make(map[string]IssueItem)
 	if len(issueIDs) == 0 {
 		return result
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), subprocessTimeout)
-	defer cancel()
-
 	// Build args: bd show id1 id2 id3 ... --json
 	args := append([]string{"show"}, issueIDs...)
 	args = append(args, "--json")
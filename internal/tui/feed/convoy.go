@@ -7,16 +7,14 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
-)
 
-// convoyIDPattern validates convoy IDs to prevent SQL injection
-var convoyIDPattern = regexp.MustCompile(`^hq-[a-zA-Z0-9-]+$`)
+	"github.com/speaker20/whaletown/internal/beadsdb"
+)
 
 // convoySubprocessTimeout is the timeout for bd and sqlite3 calls in the convoy panel.
 // Prevents TUI freezing if these commands hang.
@@ -50,31 +48,44 @@ func FetchConvoys(townRoot string) (*ConvoyState, error) {
 		LastUpdate: time.Now(),
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), convoySubprocessTimeout)
+	defer cancel()
+
 	// Fetch open convoys
-	openConvoys, err := listConvoys(townBeads, "open")
+	openConvoys, err := listConvoys(ctx, townBeads, "open")
 	if err != nil {
 		// Not a fatal error - just return empty state
 		return state, nil
 	}
 
-	for _, c := range openConvoys {
-		// Get detailed status for each convoy
-		convoy := enrichConvoy(townBeads, c)
-		state.InProgress = append(state.InProgress, convoy)
-	}
-
 	// Fetch recently closed convoys (landed in last 24h)
-	closedConvoys, err := listConvoys(townBeads, "closed")
+	var landed []Convoy
+	closedConvoys, err := listConvoys(ctx, townBeads, "closed")
 	if err == nil {
 		cutoff := time.Now().Add(-24 * time.Hour)
 		for _, c := range closedConvoys {
-			convoy := enrichConvoy(townBeads, c)
-			if !convoy.ClosedAt.IsZero() && convoy.ClosedAt.After(cutoff) {
-				state.Landed = append(state.Landed, convoy)
+			if !c.ClosedAt.IsZero() && c.ClosedAt.After(cutoff) {
+				landed = append(landed, c)
 			}
 		}
 	}
 
+	// Count tracked issues for every convoy shown in one query
+	ids := make([]string, 0, len(openConvoys)+len(landed))
+	for _, c := range openConvoys {
+		ids = append(ids, c.ID)
+	}
+	for _, c := range landed {
+		ids = append(ids, c.ID)
+	}
+	tracked := getTrackedIssueStatus(ctx, townBeads, ids)
+	for _, c := range openConvoys {
+		state.InProgress = append(state.InProgress, countTracked(c, tracked[c.ID]))
+	}
+	for _, c := range landed {
+		state.Landed = append(state.Landed, countTracked(c, tracked[c.ID]))
+	}
+
 	// Sort: in-progress by created (oldest first), landed by closed (newest first)
 	sort.Slice(state.InProgress, func(i, j int) bool {
 		return state.InProgress[i].CreatedAt.Before(state.InProgress[j].CreatedAt)
@@ -86,13 +97,20 @@ func FetchConvoys(townRoot string) (*ConvoyState, error) {
 	return state, nil
 }
 
-// listConvoys returns convoys with the given status
-func listConvoys(beadsDir, status string) ([]convoyListItem, error) {
-	listArgs := []string{"list", "--type=convoy", "--status=" + status, "--json"}
-
-	ctx, cancel := context.WithTimeout(context.Background(), convoySubprocessTimeout)
-	defer cancel()
+// listConvoys returns convoys with the given status, read from the town
+// database, or from bd when the database can't be queried directly.
+func listConvoys(ctx context.Context, beadsDir, status string) ([]Convoy, error) {
+	if beadsdb.Available() {
+		if rows, err := beadsdb.Open(beadsDir).Convoys(ctx, status); err == nil {
+			convoys := make([]Convoy, len(rows))
+			for i, c := range rows {
+				convoys[i] = Convoy{ID: c.ID, Title: c.Title, Status: c.Status, CreatedAt: c.CreatedAt, ClosedAt: c.ClosedAt}
+			}
+			return convoys, nil
+		}
+	}
 
+	listArgs := []string{"list", "--type=convoy", "--status=" + status, "--json"}
 	cmd := exec.CommandContext(ctx, "bd", listArgs...) //nolint:gosec // G204: args are constructed internally
 	cmd.Dir = beadsDir
 	var stdout bytes.Buffer
@@ -102,51 +120,38 @@ func listConvoys(beadsDir, status string) ([]convoyListItem, error) {
 		return nil, err
 	}
 
-	var items []convoyListItem
+	var items []struct {
+		ID        string `json:"id"`
+		Title     string `json:"title"`
+		Status    string `json:"status"`
+		CreatedAt string `json:"created_at"`
+		ClosedAt  string `json:"closed_at,omitempty"`
+	}
 	if err := json.Unmarshal(stdout.Bytes(), &items); err != nil {
 		return nil, err
 	}
 
-	return items, nil
-}
-
-type convoyListItem struct {
-	ID        string `json:"id"`
-	Title     string `json:"title"`
-	Status    string `json:"status"`
-	CreatedAt string `json:"created_at"`
-	ClosedAt  string `json:"closed_at,omitempty"`
-}
-
-// enrichConvoy adds tracked issue counts to a convoy
-func enrichConvoy(beadsDir string, item convoyListItem) Convoy {
-	convoy := Convoy{
-		ID:     item.ID,
-		Title:  item.Title,
-		Status: item.Status,
-	}
-
-	// Parse timestamps
-	if t, err := time.Parse(time.RFC3339, item.CreatedAt); err == nil {
-		convoy.CreatedAt = t
-	} else if t, err := time.Parse("2006-01-02 15:04", item.CreatedAt); err == nil {
-		convoy.CreatedAt = t
-	}
-	if t, err := time.Parse(time.RFC3339, item.ClosedAt); err == nil {
-		convoy.ClosedAt = t
-	} else if t, err := time.Parse("2006-01-02 15:04", item.ClosedAt); err == nil {
-		convoy.ClosedAt = t
+	convoys := make([]Convoy, len(items))
+	for i, item := range items {
+		convoys[i] = Convoy{
+			ID:        item.ID,
+			Title:     item.Title,
+			Status:    item.Status,
+			CreatedAt: beadsdb.ParseTime(item.CreatedAt),
+			ClosedAt:  beadsdb.ParseTime(item.ClosedAt),
+		}
 	}
+	return convoys, nil
+}
 
-	// Get tracked issues and their status
-	tracked := getTrackedIssueStatus(beadsDir, item.ID)
+// countTracked fills in a convoy's tracked issue counts.
+func countTracked(convoy Convoy, tracked []trackedStatus) Convoy {
 	convoy.Total = len(tracked)
 	for _, t := range tracked {
 		if t.Status == "closed" {
 			convoy.Completed++
 		}
 	}
-
 	return convoy
 }
 
@@ -155,77 +160,68 @@ type trackedStatus struct {
 	Status string
 }
 
-// getTrackedIssueStatus queries tracked issues and their status
-func getTrackedIssueStatus(beadsDir, convoyID string) []trackedStatus {
-	// Validate convoyID to prevent SQL injection
-	if !convoyIDPattern.MatchString(convoyID) {
-		return nil
-	}
-
-	dbPath := filepath.Join(beadsDir, "beads.db")
-
-	ctx, cancel := context.WithTimeout(context.Background(), convoySubprocessTimeout)
-	defer cancel()
-
-	// Query tracked dependencies from SQLite
-	// convoyID is validated above to match ^hq-[a-zA-Z0-9-]+$
-	cmd := exec.CommandContext(ctx, "sqlite3", "-json", dbPath, //nolint:gosec // G204: convoyID is validated against strict pattern
-		fmt.Sprintf(`SELECT depends_on_id FROM dependencies WHERE issue_id = '%s' AND type = 'tracks'`, convoyID))
-
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	if err := cmd.Run(); err != nil {
+// getTrackedIssueStatus returns the tracked issues of each convoy with
+// their status. Issues the town database doesn't hold are looked up with
+// a single bd show.
+func getTrackedIssueStatus(ctx context.Context, beadsDir string, convoyIDs []string) map[string][]trackedStatus {
+	tracked, err := beadsdb.Open(beadsDir).TrackedIssues(ctx, convoyIDs)
+	if err != nil {
 		return nil
 	}
 
-	var deps []struct {
-		DependsOnID string `json:"depends_on_id"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
-		return nil
+	var missing []string
+	for _, ts := range tracked {
+		for _, t := range ts {
+			if t.Issue == nil {
+				missing = append(missing, t.ID)
+			}
+		}
 	}
-
-	var tracked []trackedStatus
-	for _, dep := range deps {
-		issueID := dep.DependsOnID
-
-		// Handle external reference format: external:rig:issue-id
-		if strings.HasPrefix(issueID, "external:") {
-			parts := strings.SplitN(issueID, ":", 3)
-			if len(parts) == 3 {
-				issueID = parts[2]
+	external := getIssueStatuses(ctx, missing)
+
+	result := make(map[string][]trackedStatus, len(tracked))
+	for convoyID, ts := range tracked {
+		for _, t := range ts {
+			status := "unknown"
+			if t.Issue != nil {
+				status = t.Issue.Status
+			} else if s, ok := external[t.ID]; ok {
+				status = s
 			}
+			result[convoyID] = append(result[convoyID], trackedStatus{ID: t.ID, Status: status})
 		}
-
-		// Get issue status
-		status := getIssueStatus(issueID)
-		tracked = append(tracked, trackedStatus{ID: issueID, Status: status})
 	}
-
-	return tracked
+	return result
 }
 
-// getIssueStatus fetches just the status of an issue
-func getIssueStatus(issueID string) string {
-	ctx, cancel := context.WithTimeout(context.Background(), convoySubprocessTimeout)
-	defer cancel()
+// getIssueStatuses fetches just the status of each issue in one bd show.
+func getIssueStatuses(ctx context.Context, issueIDs []string) map[string]string {
+	result := make(map[string]string, len(issueIDs))
+	if len(issueIDs) == 0 {
+		return result
+	}
 
-	cmd := exec.CommandContext(ctx, "bd", "show", issueID, "--json")
+	args := append([]string{"show"}, issueIDs...)
+	args = append(args, "--json")
+	cmd := exec.CommandContext(ctx, "bd", args...) //nolint:gosec // G204: bd is a trusted internal tool
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
 	if err := cmd.Run(); err != nil {
-		return "unknown"
+		return result
 	}
 
 	var issues []struct {
+		ID     string `json:"id"`
 		Status string `json:"status"`
 	}
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil || len(issues) == 0 {
-		return "unknown"
+	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
+		return result
 	}
-
-	return issues[0].Status
+	for _, issue := range issues {
+		result[issue.ID] = issue.Status
+	}
+	return result
 }
 
 // Convoy panel styles
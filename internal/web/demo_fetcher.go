@@ -85,7 +85,7 @@ func (f *DemoConvoyFetcher) FetchWhaleTrades() ([]WhaleTradeRow, error) {
 	// If we have API key, also try to fetch historical/recent from REST
 	if apiKey != "" {
 		if apiTrades, err := f.solanaTracker.FetchRecentTrades(); err == nil {
-			// Append API trades (ignoring duplicates ideally, but for now simple append)
+			// API trades are deduplicated by the tracker's trade store
 			trades = append(trades, apiTrades...)
 		}
 	}
@@ -197,9 +197,8 @@ func (f *DemoConvoyFetcher) FetchAgentStatuses() ([]AgentStatusRow, error) {
 	researcherNext := 5*time.Minute - (elapsed % (5 * time.Minute))
 	copytradeNext := 30*time.Second - (elapsed % (30 * time.Second))
 
-	// Count trades and wallets
-	trades, _ := f.solanaTracker.FetchRecentTrades()
-	tradeCount := len(trades)
+	// Count trades ingested so far (FetchWhaleTrades does the polling)
+	tradeCount := len(f.solanaTracker.Trades())
 
 	walletCount := len(f.solanaTracker.GetTrackedWallets())
 
@@ -309,6 +308,10 @@ func (f *DemoConvoyFetcher) FetchTrackedWallets() ([]TrackedWalletRow, error) {
 			WinRate:    fmt.Sprintf("%.0f%%", w.WinRate*100),
 			Trades:     w.Trades,
 			Platform:   w.Platform,
+			ClusterID:  w.ClusterID,
+		}
+		if w.ClusterID != "" {
+			rows[i].Cluster = fmt.Sprintf("%s (+%d)", w.ClusterID, w.ClusterSize-1)
 		}
 	}
 	return rows, nil
@@ -2,6 +2,7 @@ package web
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"github.com/speaker20/whaletown/internal/activity"
+	"github.com/speaker20/whaletown/internal/beads"
+	"github.com/speaker20/whaletown/internal/beadsdb"
 	"github.com/speaker20/whaletown/internal/workspace"
 )
 
@@ -32,27 +35,17 @@ func NewLiveConvoyFetcher() (*LiveConvoyFetcher, error) {
 
 // FetchConvoys fetches all open convoys with their activity data.
 func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
-	// List all open convoy-type issues
-	listArgs := []string{"list", "--type=convoy", "--status=open", "--json"}
-	listCmd := exec.Command("bd", listArgs...)
-	listCmd.Dir = f.townBeads
-
-	var stdout bytes.Buffer
-	listCmd.Stdout = &stdout
-
-	if err := listCmd.Run(); err != nil {
-		return nil, fmt.Errorf("listing convoys: %w", err)
+	convoys, err := f.listConvoys()
+	if err != nil {
+		return nil, err
 	}
 
-	var convoys []struct {
-		ID        string `json:"id"`
-		Title     string `json:"title"`
-		Status    string `json:"status"`
-		CreatedAt string `json:"created_at"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
-		return nil, fmt.Errorf("parsing convoy list: %w", err)
+	// Tracked issues for every convoy in one query
+	ids := make([]string, len(convoys))
+	for i, c := range convoys {
+		ids[i] = c.ID
 	}
+	trackedByConvoy := f.getTrackedIssues(ids)
 
 	// Build convoy rows with activity data
 	rows := make([]ConvoyRow, 0, len(convoys))
@@ -63,16 +56,24 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 			Status: c.Status,
 		}
 
-		// Get tracked issues for progress and activity calculation
-		tracked := f.getTrackedIssues(c.ID)
+		fields := beads.ParseConvoyFields(c.Description)
+		if !fields.DueAt.IsZero() {
+			row.Due = fields.DueAt.Local().Format("Jan 2 15:04")
+			row.Overdue = fields.IsOverdue(c.Status, time.Now())
+		}
+
+		// Tracked issues for progress and activity calculation
+		tracked := trackedByConvoy[c.ID]
 		row.Total = len(tracked)
 
 		var mostRecentActivity time.Time
 		var mostRecentUpdated time.Time
 		var hasAssignee bool
+		var closedAt []time.Time
 		for _, t := range tracked {
 			if t.Status == "closed" {
 				row.Completed++
+				closedAt = append(closedAt, t.ClosedAt)
 			}
 			// Track most recent activity from workers
 			if t.LastActivity.After(mostRecentActivity) {
@@ -88,6 +89,9 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 		}
 
 		row.Progress = fmt.Sprintf("%d/%d", row.Completed, row.Total)
+		if !c.CreatedAt.IsZero() {
+			row.BurnDown = beads.ConvoyBurnDown(c.CreatedAt, row.Total, closedAt)
+		}
 
 		// Calculate activity info from most recent worker activity
 		if !mostRecentActivity.IsZero() {
@@ -139,6 +143,48 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 	return rows, nil
 }
 
+// listConvoys returns the open convoys, read from the town database, or
+// from bd when the database can't be queried directly.
+func (f *LiveConvoyFetcher) listConvoys() ([]*beadsdb.Issue, error) {
+	if beadsdb.Available() {
+		if convoys, err := beadsdb.Open(f.townBeads).Convoys(context.Background(), "open"); err == nil {
+			return convoys, nil
+		}
+	}
+
+	listCmd := exec.Command("bd", "list", "--type=convoy", "--status=open", "--json")
+	listCmd.Dir = f.townBeads
+
+	var stdout bytes.Buffer
+	listCmd.Stdout = &stdout
+
+	if err := listCmd.Run(); err != nil {
+		return nil, fmt.Errorf("listing convoys: %w", err)
+	}
+
+	var listed []struct {
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Status      string `json:"status"`
+		CreatedAt   string `json:"created_at"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &listed); err != nil {
+		return nil, fmt.Errorf("parsing convoy list: %w", err)
+	}
+	convoys := make([]*beadsdb.Issue, len(listed))
+	for i, c := range listed {
+		convoys[i] = &beadsdb.Issue{
+			ID:          c.ID,
+			Title:       c.Title,
+			Status:      c.Status,
+			Description: c.Description,
+			CreatedAt:   beadsdb.ParseTime(c.CreatedAt),
+		}
+	}
+	return convoys, nil
+}
+
 // trackedIssueInfo holds info about an issue being tracked by a convoy.
 type trackedIssueInfo struct {
 	ID           string
@@ -147,71 +193,68 @@ type trackedIssueInfo struct {
 	Assignee     string
 	LastActivity time.Time
 	UpdatedAt    time.Time // Fallback for activity when no assignee
+	ClosedAt     time.Time // For the burn-down
 }
 
-// getTrackedIssues fetches tracked issues for a convoy.
-func (f *LiveConvoyFetcher) getTrackedIssues(convoyID string) []trackedIssueInfo {
-	dbPath := filepath.Join(f.townBeads, "beads.db")
-
-	// Query tracked dependencies from SQLite
-	safeConvoyID := strings.ReplaceAll(convoyID, "'", "''")
-	// #nosec G204 -- sqlite3 path is from trusted config, convoyID is escaped
-	queryCmd := exec.Command("sqlite3", "-json", dbPath,
-		fmt.Sprintf(`SELECT depends_on_id, type FROM dependencies WHERE issue_id = '%s' AND type = 'tracks'`, safeConvoyID))
-
-	var stdout bytes.Buffer
-	queryCmd.Stdout = &stdout
-	if err := queryCmd.Run(); err != nil {
-		return nil
-	}
-
-	var deps []struct {
-		DependsOnID string `json:"depends_on_id"`
-		Type        string `json:"type"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
+// getTrackedIssues fetches the tracked issues of each convoy. Issues in
+// the town database come back with the query; cross-rig issues that
+// aren't are looked up with a single bd show.
+func (f *LiveConvoyFetcher) getTrackedIssues(convoyIDs []string) map[string][]trackedIssueInfo {
+	tracked, err := beadsdb.Open(f.townBeads).TrackedIssues(context.Background(), convoyIDs)
+	if err != nil {
 		return nil
 	}
 
-	// Collect issue IDs (normalize external refs)
-	issueIDs := make([]string, 0, len(deps))
-	for _, dep := range deps {
-		issueID := dep.DependsOnID
-		if strings.HasPrefix(issueID, "external:") {
-			parts := strings.SplitN(issueID, ":", 3)
-			if len(parts) == 3 {
-				issueID = parts[2]
+	details := make(map[string]*issueDetail)
+	var missing []string
+	for _, ts := range tracked {
+		for _, t := range ts {
+			if t.Issue == nil {
+				missing = append(missing, t.ID)
+				continue
+			}
+			details[t.ID] = &issueDetail{
+				ID:        t.ID,
+				Title:     t.Issue.Title,
+				Status:    t.Issue.Status,
+				Assignee:  t.Issue.Assignee,
+				UpdatedAt: t.Issue.UpdatedAt,
+				ClosedAt:  t.Issue.ClosedAt,
 			}
 		}
-		issueIDs = append(issueIDs, issueID)
 	}
-
-	// Batch fetch issue details
-	details := f.getIssueDetailsBatch(issueIDs)
+	for id, d := range f.getIssueDetailsBatch(missing) {
+		details[id] = d
+	}
 
 	// Get worker activity from tmux sessions based on assignees
 	workers := f.getWorkersFromAssignees(details)
 
 	// Build result
-	result := make([]trackedIssueInfo, 0, len(issueIDs))
-	for _, id := range issueIDs {
-		info := trackedIssueInfo{ID: id}
-
-		if d, ok := details[id]; ok {
-			info.Title = d.Title
-			info.Status = d.Status
-			info.Assignee = d.Assignee
-			info.UpdatedAt = d.UpdatedAt
-		} else {
-			info.Title = "(external)"
-			info.Status = "unknown"
-		}
+	result := make(map[string][]trackedIssueInfo, len(tracked))
+	for convoyID, ts := range tracked {
+		infos := make([]trackedIssueInfo, 0, len(ts))
+		for _, t := range ts {
+			info := trackedIssueInfo{ID: t.ID}
+
+			if d, ok := details[t.ID]; ok {
+				info.Title = d.Title
+				info.Status = d.Status
+				info.Assignee = d.Assignee
+				info.UpdatedAt = d.UpdatedAt
+				info.ClosedAt = d.ClosedAt
+			} else {
+				info.Title = "(external)"
+				info.Status = "unknown"
+			}
 
-		if w, ok := workers[id]; ok && w.LastActivity != nil {
-			info.LastActivity = *w.LastActivity
-		}
+			if w, ok := workers[t.ID]; ok && w.LastActivity != nil {
+				info.LastActivity = *w.LastActivity
+			}
 
-		result = append(result, info)
+			infos = append(infos, info)
+		}
+		result[convoyID] = infos
 	}
 
 	return result
@@ -224,6 +267,7 @@ type issueDetail struct {
 	Status    string
 	Assignee  string
 	UpdatedAt time.Time
+	ClosedAt  time.Time
 }
 
 // getIssueDetailsBatch fetches details for multiple issues.
@@ -251,6 +295,7 @@ func (f *LiveConvoyFetcher) getIssueDetailsBatch(issueIDs []string) map[string]*
 		Status    string `json:"status"`
 		Assignee  string `json:"assignee"`
 		UpdatedAt string `json:"updated_at"`
+		ClosedAt  string `json:"closed_at"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
 		return result
@@ -269,6 +314,11 @@ func (f *LiveConvoyFetcher) getIssueDetailsBatch(issueIDs []string) map[string]*
 				detail.UpdatedAt = t
 			}
 		}
+		if issue.ClosedAt != "" {
+			if t, err := time.Parse(time.RFC3339, issue.ClosedAt); err == nil {
+				detail.ClosedAt = t
+			}
+		}
 		result[issue.ID] = detail
 	}
 
@@ -3,11 +3,14 @@ package web
 
 import (
 	"embed"
+	"fmt"
 	"html/template"
 	"io/fs"
 	"strings"
+	"time"
 
 	"github.com/speaker20/whaletown/internal/activity"
+	"github.com/speaker20/whaletown/internal/beads"
 )
 
 //go:embed templates/*.html
@@ -48,6 +51,8 @@ type TrackedWalletRow struct {
 	WinRate    string // "52%"
 	Trades     int
 	Platform   string
+	ClusterID  string // Empty if the wallet isn't in a cluster
+	Cluster    string // "cl-5fWkLJ (+2)": ID and number of wallets folded in
 }
 
 // WhaleTradeRow represents a whale's trade in the dashboard.
@@ -95,6 +100,9 @@ type ConvoyRow struct {
 	Total         int
 	LastActivity  activity.Info
 	TrackedIssues []TrackedIssue
+	Due           string                // Formatted deadline, empty if none
+	Overdue       bool                  // Open and past its deadline
+	BurnDown      []beads.BurnDownPoint // Completed/remaining after each close
 }
 
 // TrackedIssue represents an issue tracked by a convoy.
@@ -113,6 +121,7 @@ func LoadTemplates() (*template.Template, error) {
 		"statusClass":     statusClass,
 		"workStatusClass": workStatusClass,
 		"progressPercent": progressPercent,
+		"burnDownPoints":  burnDownPoints,
 		"title":           strings.Title, //nolint:staticcheck // Simple title case is fine here
 	}
 
@@ -182,3 +191,41 @@ func progressPercent(completed, total int) int {
 	}
 	return (completed * 100) / total
 }
+
+// burnDownPoints returns SVG polyline points for a burn-down sparkline in a
+// 100x20 viewBox: remaining issues over time, ending at the current moment.
+func burnDownPoints(points []beads.BurnDownPoint, total int) string {
+	return burnDownPointsAt(points, total, time.Now())
+}
+
+func burnDownPointsAt(points []beads.BurnDownPoint, total int, now time.Time) string {
+	if len(points) == 0 || total == 0 {
+		return ""
+	}
+	start := points[0].At
+	span := now.Sub(start)
+	if last := points[len(points)-1].At; last.Sub(start) > span {
+		span = last.Sub(start)
+	}
+
+	x := func(t time.Time) float64 {
+		if span <= 0 {
+			return 0
+		}
+		return float64(t.Sub(start)) / float64(span) * 100
+	}
+	y := func(remaining int) float64 {
+		return 20 - float64(remaining)/float64(total)*20
+	}
+
+	// Steps: remaining holds until the next close
+	var coords []string
+	prev := points[0].Remaining
+	coords = append(coords, fmt.Sprintf("0,%.1f", y(prev)))
+	for _, p := range points[1:] {
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", x(p.At), y(prev)), fmt.Sprintf("%.1f,%.1f", x(p.At), y(p.Remaining)))
+		prev = p.Remaining
+	}
+	coords = append(coords, fmt.Sprintf("100,%.1f", y(prev)))
+	return strings.Join(coords, " ")
+}
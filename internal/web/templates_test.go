@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/speaker20/whaletown/internal/activity"
+	"github.com/speaker20/whaletown/internal/beads"
 )
 
 func TestConvoyTemplate_RendersConvoyList(t *testing.T) {
@@ -236,3 +237,84 @@ func TestConvoyTemplate_EmptyState(t *testing.T) {
 		t.Error("Template should show empty state message when no convoys")
 	}
 }
+
+func TestConvoyTemplate_TrackedWalletClusters(t *testing.T) {
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	data := ConvoyData{
+		TrackedWallets: []TrackedWalletRow{
+			{Alias: "Leader", Score: 90, ClusterID: "cl-5fWkLJ", Cluster: "cl-5fWkLJ (+2)"},
+			{Alias: "Loner", Score: 80},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "convoy.html", data); err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<th>Cluster</th>") {
+		t.Error("Template should have a Cluster column")
+	}
+	// html/template escapes "+"
+	if !strings.Contains(output, "cl-5fWkLJ (&#43;2)") {
+		t.Error("Template should show the cluster ID and folded wallet count")
+	}
+}
+
+func TestConvoyTemplate_DeadlineAndBurnDown(t *testing.T) {
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	created := time.Now().Add(-48 * time.Hour)
+	data := ConvoyData{
+		Convoys: []ConvoyRow{
+			{
+				ID:        "hq-cv-late",
+				Title:     "Sprint work",
+				Status:    "open",
+				Progress:  "1/2",
+				Completed: 1,
+				Total:     2,
+				Due:       "Jan 15 23:59",
+				Overdue:   true,
+				BurnDown:  beads.ConvoyBurnDown(created, 2, []time.Time{created.Add(24 * time.Hour)}),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "convoy.html", data); err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+	output := buf.String()
+
+	if !strings.Contains(output, "convoy-overdue") || !strings.Contains(output, "due Jan 15 23:59") {
+		t.Error("Template should show the overdue deadline")
+	}
+	if !strings.Contains(output, `<svg class="burn-down"`) || !strings.Contains(output, "<polyline points=") {
+		t.Error("Template should render the burn-down sparkline")
+	}
+}
+
+func TestBurnDownPoints(t *testing.T) {
+	created := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	points := beads.ConvoyBurnDown(created, 4, []time.Time{created.Add(24 * time.Hour)})
+
+	// Halfway through the span, one of four issues closes
+	got := burnDownPointsAt(points, 4, created.Add(48*time.Hour))
+	want := "0,0.0 50.0,0.0 50.0,5.0 100,5.0"
+	if got != want {
+		t.Errorf("burnDownPointsAt = %q, want %q", got, want)
+	}
+
+	if got := burnDownPointsAt(nil, 4, created); got != "" {
+		t.Errorf("no points = %q, want empty", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/speaker20/whaletown/internal/agent"
+	"github.com/speaker20/whaletown/internal/backend"
 	"github.com/speaker20/whaletown/internal/beads"
 	"github.com/speaker20/whaletown/internal/claude"
 	"github.com/speaker20/whaletown/internal/config"
@@ -108,7 +109,7 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 		return err
 	}
 
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot())
 	sessionID := m.SessionName()
 
 	if foreground {
@@ -292,7 +293,7 @@ func (m *Manager) Stop() error {
 	}
 
 	// Check if tmux session exists
-	t := tmux.NewTmux()
+	t := backend.New(m.townRoot())
 	sessionID := m.SessionName()
 	sessionRunning, _ := t.HasSession(sessionID)
 
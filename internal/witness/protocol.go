@@ -6,6 +6,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/speaker20/whaletown/internal/mail"
 )
 
 // Protocol message patterns for Witness inbox routing.
@@ -46,50 +48,62 @@ const (
 	ProtoUnknown           ProtocolType = "unknown"
 )
 
+// Payload types of the witness's protocol messages, as carried in the
+// payload line of a message body (see mail.Envelope). MERGED and
+// MERGE_FAILED payloads are written by the protocol package; the fields
+// below share their JSON names.
+const (
+	PayloadPolecatDone = "POLECAT_DONE"
+	PayloadHelp        = "HELP"
+	PayloadMerged      = "MERGED"
+	PayloadMergeFailed = "MERGE_FAILED"
+	PayloadSwarmStart  = "SWARM_START"
+)
+
 // PolecatDonePayload contains parsed data from a POLECAT_DONE message.
 type PolecatDonePayload struct {
-	PolecatName string
-	Exit        string // COMPLETED, ESCALATED, DEFERRED, PHASE_COMPLETE
-	IssueID     string
-	MRID        string
-	Branch      string
-	Gate        string // Gate ID when Exit is PHASE_COMPLETE
+	PolecatName string `json:"polecat"`
+	Exit        string `json:"exit"` // COMPLETED, ESCALATED, DEFERRED, PHASE_COMPLETE
+	IssueID     string `json:"issue,omitempty"`
+	MRID        string `json:"mr,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+	Gate        string `json:"gate,omitempty"` // Gate ID when Exit is PHASE_COMPLETE
 }
 
 // HelpPayload contains parsed data from a HELP message.
 type HelpPayload struct {
-	Topic       string
-	Agent       string
-	IssueID     string
-	Problem     string
-	Tried       string
-	RequestedAt time.Time
+	Topic       string    `json:"topic"`
+	Agent       string    `json:"agent,omitempty"`
+	IssueID     string    `json:"issue,omitempty"`
+	Problem     string    `json:"problem,omitempty"`
+	Tried       string    `json:"tried,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
 }
 
 // MergedPayload contains parsed data from a MERGED message.
 type MergedPayload struct {
-	PolecatName string
-	Branch      string
-	IssueID     string
-	MergedAt    time.Time
+	PolecatName string    `json:"polecat"`
+	Branch      string    `json:"branch"`
+	IssueID     string    `json:"issue"`
+	MergedAt    time.Time `json:"merged_at"`
 }
 
 // MergeFailedPayload contains parsed data from a MERGE_FAILED message.
 type MergeFailedPayload struct {
-	PolecatName string
-	Branch      string
-	IssueID     string
-	FailureType string // "build", "test", "lint", etc.
-	Error       string
-	FailedAt    time.Time
+	PolecatName string    `json:"polecat"`
+	Branch      string    `json:"branch"`
+	IssueID     string    `json:"issue"`
+	FailureType string    `json:"failure_type"` // "build", "test", "lint", etc.
+	Error       string    `json:"error"`
+	FailedAt    time.Time `json:"failed_at"`
 }
 
 // SwarmStartPayload contains parsed data from a SWARM_START message.
 type SwarmStartPayload struct {
-	SwarmID   string
-	BeadIDs   []string
-	Total     int
-	StartedAt time.Time
+	SwarmID   string    `json:"swarm_id"`
+	BeadIDs   []string  `json:"beads,omitempty"`
+	Total     int       `json:"total"`
+	StartedAt time.Time `json:"started_at"`
 }
 
 // ClassifyMessage determines the protocol type from a message subject.
@@ -114,9 +128,104 @@ func ClassifyMessage(subject string) ProtocolType {
 	}
 }
 
-// ParsePolecatDone extracts payload from a POLECAT_DONE message.
+// ClassifyMail determines the protocol type of a message from its payload
+// line, falling back to the subject for mail without one.
+func ClassifyMail(msg *mail.Message) ProtocolType {
+	if env, err := mail.ReadEnvelope(msg.Body); err == nil && env != nil {
+		switch env.Type {
+		case PayloadPolecatDone:
+			return ProtoPolecatDone
+		case PayloadHelp:
+			return ProtoHelp
+		case PayloadMerged:
+			return ProtoMerged
+		case PayloadMergeFailed:
+			return ProtoMergeFailed
+		case PayloadSwarmStart:
+			return ProtoSwarmStart
+		}
+	}
+	return ClassifyMessage(msg.Subject)
+}
+
+// readPayload decodes the payload line of body into v. It reports false
+// for bodies without a payload line, which are parsed as legacy text.
+func readPayload(body, want string, v interface{}) (bool, error) {
+	env, err := mail.ReadEnvelope(body)
+	if err != nil || env == nil {
+		return false, err
+	}
+	if env.Type != want {
+		return false, fmt.Errorf("payload is %s, want %s", env.Type, want)
+	}
+	return true, env.Decode(v)
+}
+
+// subjectName returns the name a protocol subject pattern captures.
+func subjectName(pattern *regexp.Regexp, subject string) string {
+	if matches := pattern.FindStringSubmatch(subject); len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+// EncodePolecatDone returns the subject and body of a POLECAT_DONE
+// message: "Key: value" text for people and older witnesses, and the
+// payload line.
+func EncodePolecatDone(p *PolecatDonePayload) (subject, body string, err error) {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Exit: %s", p.Exit))
+	if p.IssueID != "" {
+		lines = append(lines, fmt.Sprintf("Issue: %s", p.IssueID))
+	}
+	if p.MRID != "" {
+		lines = append(lines, fmt.Sprintf("MR: %s", p.MRID))
+	}
+	if p.Gate != "" {
+		lines = append(lines, fmt.Sprintf("Gate: %s", p.Gate))
+	}
+	lines = append(lines, fmt.Sprintf("Branch: %s", p.Branch))
+	body, err = mail.AttachPayload(strings.Join(lines, "\n"), PayloadPolecatDone, p)
+	return fmt.Sprintf("POLECAT_DONE %s", p.PolecatName), body, err
+}
+
+// EncodeHelp returns the subject and body of a HELP message.
+func EncodeHelp(p *HelpPayload) (subject, body string, err error) {
+	if p.RequestedAt.IsZero() {
+		p.RequestedAt = time.Now()
+	}
+	var lines []string
+	for _, f := range []struct{ key, value string }{
+		{"Agent", p.Agent}, {"Issue", p.IssueID}, {"Problem", p.Problem}, {"Tried", p.Tried},
+	} {
+		if f.value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", f.key, f.value))
+		}
+	}
+	body, err = mail.AttachPayload(strings.Join(lines, "\n"), PayloadHelp, p)
+	return fmt.Sprintf("HELP: %s", p.Topic), body, err
+}
+
+// EncodeSwarmStart returns the subject and body of a SWARM_START message.
+func EncodeSwarmStart(p *SwarmStartPayload) (subject, body string, err error) {
+	if p.StartedAt.IsZero() {
+		p.StartedAt = time.Now()
+	}
+	if p.Total == 0 {
+		p.Total = len(p.BeadIDs)
+	}
+	text := fmt.Sprintf("SwarmID: %s\nTotal: %d", p.SwarmID, p.Total)
+	if len(p.BeadIDs) > 0 {
+		text += fmt.Sprintf("\nBeads: %s", strings.Join(p.BeadIDs, ", "))
+	}
+	body, err = mail.AttachPayload(text, PayloadSwarmStart, p)
+	return fmt.Sprintf("SWARM_START %s", p.SwarmID), body, err
+}
+
+// ParsePolecatDone extracts payload from a POLECAT_DONE message, from the
+// payload line when the body has one.
 // Subject format: POLECAT_DONE <polecat-name>
-// Body format:
+// Legacy body format:
 //
 //	Exit: COMPLETED|ESCALATED|DEFERRED|PHASE_COMPLETE
 //	Issue: <issue-id>
@@ -124,14 +233,24 @@ func ClassifyMessage(subject string) ProtocolType {
 //	Gate: <gate-id>
 //	Branch: <branch>
 func ParsePolecatDone(subject, body string) (*PolecatDonePayload, error) {
+	payload := &PolecatDonePayload{}
+	if ok, err := readPayload(body, PayloadPolecatDone, payload); err != nil {
+		return nil, fmt.Errorf("POLECAT_DONE: %w", err)
+	} else if ok {
+		if payload.PolecatName == "" {
+			payload.PolecatName = subjectName(PatternPolecatDone, subject)
+		}
+		if payload.PolecatName == "" {
+			return nil, fmt.Errorf("POLECAT_DONE payload has no polecat")
+		}
+		return payload, nil
+	}
+
 	matches := PatternPolecatDone.FindStringSubmatch(subject)
 	if len(matches) < 2 {
 		return nil, fmt.Errorf("invalid POLECAT_DONE subject: %s", subject)
 	}
-
-	payload := &PolecatDonePayload{
-		PolecatName: matches[1],
-	}
+	payload.PolecatName = matches[1]
 
 	// Parse body for structured fields
 	for _, line := range strings.Split(body, "\n") {
@@ -152,24 +271,35 @@ func ParsePolecatDone(subject, body string) (*PolecatDonePayload, error) {
 	return payload, nil
 }
 
-// ParseHelp extracts payload from a HELP message.
+// ParseHelp extracts payload from a HELP message, from the payload line
+// when the body has one.
 // Subject format: HELP: <topic>
-// Body format:
+// Legacy body format:
 //
 //	Agent: <agent-id>
 //	Issue: <issue-id>
 //	Problem: <description>
 //	Tried: <what was attempted>
 func ParseHelp(subject, body string) (*HelpPayload, error) {
+	payload := &HelpPayload{}
+	if ok, err := readPayload(body, PayloadHelp, payload); err != nil {
+		return nil, fmt.Errorf("HELP: %w", err)
+	} else if ok {
+		if payload.Topic == "" {
+			payload.Topic = subjectName(PatternHelp, subject)
+		}
+		if payload.RequestedAt.IsZero() {
+			payload.RequestedAt = time.Now()
+		}
+		return payload, nil
+	}
+
 	matches := PatternHelp.FindStringSubmatch(subject)
 	if len(matches) < 2 {
 		return nil, fmt.Errorf("invalid HELP subject: %s", subject)
 	}
-
-	payload := &HelpPayload{
-		Topic:       matches[1],
-		RequestedAt: time.Now(),
-	}
+	payload.Topic = matches[1]
+	payload.RequestedAt = time.Now()
 
 	// Parse body for structured fields
 	for _, line := range strings.Split(body, "\n") {
@@ -188,22 +318,33 @@ func ParseHelp(subject, body string) (*HelpPayload, error) {
 	return payload, nil
 }
 
-// ParseMerged extracts payload from a MERGED message.
+// ParseMerged extracts payload from a MERGED message, from the payload
+// line when the body has one.
 // Subject format: MERGED <polecat-name>
-// Body format:
+// Legacy body format:
 //
 //	Branch: <branch>
 //	Issue: <issue-id>
 //	Merged-At: <timestamp>
 func ParseMerged(subject, body string) (*MergedPayload, error) {
+	payload := &MergedPayload{}
+	if ok, err := readPayload(body, PayloadMerged, payload); err != nil {
+		return nil, fmt.Errorf("MERGED: %w", err)
+	} else if ok {
+		if payload.PolecatName == "" {
+			payload.PolecatName = subjectName(PatternMerged, subject)
+		}
+		if payload.PolecatName == "" {
+			return nil, fmt.Errorf("MERGED payload has no polecat")
+		}
+		return payload, nil
+	}
+
 	matches := PatternMerged.FindStringSubmatch(subject)
 	if len(matches) < 2 {
 		return nil, fmt.Errorf("invalid MERGED subject: %s", subject)
 	}
-
-	payload := &MergedPayload{
-		PolecatName: matches[1],
-	}
+	payload.PolecatName = matches[1]
 
 	// Parse body for structured fields
 	for _, line := range strings.Split(body, "\n") {
@@ -223,24 +364,38 @@ func ParseMerged(subject, body string) (*MergedPayload, error) {
 	return payload, nil
 }
 
-// ParseMergeFailed extracts payload from a MERGE_FAILED message.
+// ParseMergeFailed extracts payload from a MERGE_FAILED message, from the
+// payload line when the body has one.
 // Subject format: MERGE_FAILED <polecat-name>
-// Body format:
+// Legacy body format:
 //
 //	Branch: <branch>
 //	Issue: <issue-id>
 //	FailureType: <type>
 //	Error: <error-message>
 func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
+	payload := &MergeFailedPayload{}
+	if ok, err := readPayload(body, PayloadMergeFailed, payload); err != nil {
+		return nil, fmt.Errorf("MERGE_FAILED: %w", err)
+	} else if ok {
+		if payload.PolecatName == "" {
+			payload.PolecatName = subjectName(PatternMergeFailed, subject)
+		}
+		if payload.PolecatName == "" {
+			return nil, fmt.Errorf("MERGE_FAILED payload has no polecat")
+		}
+		if payload.FailedAt.IsZero() {
+			payload.FailedAt = time.Now()
+		}
+		return payload, nil
+	}
+
 	matches := PatternMergeFailed.FindStringSubmatch(subject)
 	if len(matches) < 2 {
 		return nil, fmt.Errorf("invalid MERGE_FAILED subject: %s", subject)
 	}
-
-	payload := &MergeFailedPayload{
-		PolecatName: matches[1],
-		FailedAt:    time.Now(),
-	}
+	payload.PolecatName = matches[1]
+	payload.FailedAt = time.Now()
 
 	// Parse body for structured fields
 	for _, line := range strings.Split(body, "\n") {
@@ -250,8 +405,9 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 			payload.Branch = strings.TrimSpace(strings.TrimPrefix(line, "Branch:"))
 		case strings.HasPrefix(line, "Issue:"):
 			payload.IssueID = strings.TrimSpace(strings.TrimPrefix(line, "Issue:"))
-		case strings.HasPrefix(line, "FailureType:"):
-			payload.FailureType = strings.TrimSpace(strings.TrimPrefix(line, "FailureType:"))
+		case strings.HasPrefix(line, "FailureType:"), strings.HasPrefix(line, "Failure-Type:"):
+			_, v, _ := strings.Cut(line, ":")
+			payload.FailureType = strings.TrimSpace(v)
 		case strings.HasPrefix(line, "Error:"):
 			payload.Error = strings.TrimSpace(strings.TrimPrefix(line, "Error:"))
 		}
@@ -260,15 +416,24 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 	return payload, nil
 }
 
-// ParseSwarmStart extracts payload from a SWARM_START message.
-// Body format is JSON: {"swarm_id": "batch-123", "beads": ["bd-a", "bd-b"]}
+// ParseSwarmStart extracts payload from a SWARM_START message, from the
+// payload line when the body has one.
+// Legacy body format:
+//
+//	SwarmID: <swarm-id>
+//	Total: <count>
 func ParseSwarmStart(body string) (*SwarmStartPayload, error) {
-	payload := &SwarmStartPayload{
-		StartedAt: time.Now(),
+	payload := &SwarmStartPayload{}
+	if ok, err := readPayload(body, PayloadSwarmStart, payload); err != nil {
+		return nil, fmt.Errorf("SWARM_START: %w", err)
+	} else if ok {
+		if payload.StartedAt.IsZero() {
+			payload.StartedAt = time.Now()
+		}
+		return payload, nil
 	}
+	payload.StartedAt = time.Now()
 
-	// Parse the JSON-like body (simplified parsing for key-value extraction)
-	// Full JSON parsing would require encoding/json import
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "SwarmID:") || strings.HasPrefix(line, "swarm_id:") {
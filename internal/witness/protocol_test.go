@@ -1,7 +1,10 @@
 package witness
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/speaker20/whaletown/internal/mail"
 )
 
 func TestClassifyMessage(t *testing.T) {
@@ -309,3 +312,83 @@ func TestAssessHelpRequest_BuildIssues(t *testing.T) {
 		t.Error("Should be able to help with build issues")
 	}
 }
+
+func TestEncodePolecatDone_RoundTrip(t *testing.T) {
+	in := &PolecatDonePayload{
+		PolecatName: "nux",
+		Exit:        "PHASE_COMPLETE",
+		IssueID:     "wt-abc",
+		Gate:        "wt-gate",
+		Branch:      "polecat/nux",
+	}
+	subject, body, err := EncodePolecatDone(in)
+	if err != nil {
+		t.Fatalf("EncodePolecatDone() error = %v", err)
+	}
+	if subject != "POLECAT_DONE nux" {
+		t.Errorf("subject = %q", subject)
+	}
+	if !strings.Contains(body, "Exit: PHASE_COMPLETE") {
+		t.Errorf("body missing human-readable text: %s", body)
+	}
+
+	got, err := ParsePolecatDone(subject, body)
+	if err != nil {
+		t.Fatalf("ParsePolecatDone() error = %v", err)
+	}
+	if *got != *in {
+		t.Errorf("ParsePolecatDone() = %+v, want %+v", got, in)
+	}
+	if ClassifyMail(&mail.Message{Subject: "forwarded", Body: body}) != ProtoPolecatDone {
+		t.Error("ClassifyMail should classify by payload type")
+	}
+}
+
+func TestParseMergeFailed_Payload(t *testing.T) {
+	// As written by protocol.NewMergeFailedMessage
+	body, err := mail.AttachPayload("Branch: polecat/nux\nFailure-Type: tests\n", PayloadMergeFailed, map[string]string{
+		"branch":       "polecat/nux",
+		"issue":        "wt-abc",
+		"polecat":      "nux",
+		"failure_type": "tests",
+		"error":        "FAIL: TestX\nBranch: wrong",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := ParseMergeFailed("MERGE_FAILED nux", body)
+	if err != nil {
+		t.Fatalf("ParseMergeFailed() error = %v", err)
+	}
+	if payload.Branch != "polecat/nux" || payload.FailureType != "tests" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.Error != "FAIL: TestX\nBranch: wrong" {
+		t.Errorf("Error = %q", payload.Error)
+	}
+}
+
+func TestParseMergeFailed_LegacyFailureTypeHeader(t *testing.T) {
+	payload, err := ParseMergeFailed("MERGE_FAILED nux", "Branch: b\nFailure-Type: build\nError: boom")
+	if err != nil {
+		t.Fatalf("ParseMergeFailed() error = %v", err)
+	}
+	if payload.FailureType != "build" {
+		t.Errorf("FailureType = %q, want %q", payload.FailureType, "build")
+	}
+}
+
+func TestParseHelp_WrongPayloadType(t *testing.T) {
+	_, body, err := EncodeSwarmStart(&SwarmStartPayload{SwarmID: "swarm-1", BeadIDs: []string{"wt-a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseHelp("HELP: stuck", body); err == nil {
+		t.Error("ParseHelp() of a SWARM_START payload: want error")
+	}
+	swarm, err := ParseSwarmStart(body)
+	if err != nil || swarm.SwarmID != "swarm-1" || swarm.Total != 1 {
+		t.Errorf("ParseSwarmStart() = %+v, %v", swarm, err)
+	}
+}